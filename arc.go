@@ -0,0 +1,256 @@
+package geom
+
+import "math"
+
+// Arc2 is part of the edge of a circle. The arc runs counter clockwise from the Start angle to
+// the End angle, both given in radians measured from the x axis. When End is less than Start
+// the arc passes through angle zero, and when End is a whole turn or more beyond Start the arc
+// is a full circle.
+type Arc2 struct {
+	Centre Point2
+	Radius float32
+	Start  float32
+	End    float32
+}
+
+// Sweep returns the angle in radians covered by the arc, in the range [0, 2π].
+func (a Arc2) Sweep() float32 {
+	d := float64(a.End) - float64(a.Start)
+	if d >= 2*math.Pi-1e-6 {
+		return 2 * pi
+	}
+	s := math.Mod(d, 2*math.Pi)
+	if s < 0 {
+		s += 2 * math.Pi
+	}
+	return float32(s)
+}
+
+// Length returns the length of the arc.
+func (a Arc2) Length() float32 {
+	return a.Radius * a.Sweep()
+}
+
+// ContainsAngle reports whether the direction given by angle, in radians, lies within the arc.
+func (a Arc2) ContainsAngle(angle float32) bool {
+	d := math.Mod(float64(angle)-float64(a.Start), 2*math.Pi)
+	if d < 0 {
+		d += 2 * math.Pi
+	}
+	return float32(d) <= a.Sweep()
+}
+
+// PointAt returns the point on the circle at the angle in radians. The point is not
+// restricted to the arc.
+func (a Arc2) PointAt(angle float32) Point2 {
+	s, c := sincos(angle)
+	return Point2{a.Centre[0] + a.Radius*c, a.Centre[1] + a.Radius*s}
+}
+
+// StartPoint returns the point at the start of the arc.
+func (a Arc2) StartPoint() Point2 {
+	return a.PointAt(a.Start)
+}
+
+// EndPoint returns the point at the end of the arc.
+func (a Arc2) EndPoint() Point2 {
+	return a.PointAt(a.Start + a.Sweep())
+}
+
+// Bounds returns the smallest Rect that contains the arc.
+func (a Arc2) Bounds() Rect {
+	pmin := a.StartPoint()
+	pmax := pmin
+	extend := func(p Point2) {
+		pmin = Point2{min(pmin[0], p[0]), min(pmin[1], p[1])}
+		pmax = Point2{max(pmax[0], p[0]), max(pmax[1], p[1])}
+	}
+	extend(a.EndPoint())
+
+	// The arc reaches the edge of the circle's bounds wherever it crosses an axis
+	extremes := [4]Point2{
+		{a.Centre[0] + a.Radius, a.Centre[1]},
+		{a.Centre[0], a.Centre[1] + a.Radius},
+		{a.Centre[0] - a.Radius, a.Centre[1]},
+		{a.Centre[0], a.Centre[1] - a.Radius},
+	}
+	for i, p := range extremes {
+		if a.ContainsAngle(float32(i) * pi / 2) {
+			extend(p)
+		}
+	}
+	return RectFromCorners(pmin, pmax)
+}
+
+// ClosestPoint returns the point on the arc that is closest to p.
+func (a Arc2) ClosestPoint(p Point2) Point2 {
+	d := p.Sub(a.Centre)
+	if d.LenSqr() == 0 {
+		return a.StartPoint()
+	}
+	if a.ContainsAngle(atan2(d[1], d[0])) {
+		return a.Centre.Add(d.Normalize().Mul(a.Radius))
+	}
+	sp, ep := a.StartPoint(), a.EndPoint()
+	if p.Sub(sp).LenSqr() <= p.Sub(ep).LenSqr() {
+		return sp
+	}
+	return ep
+}
+
+// IntersectLine2 returns the points where the line segment l crosses the arc, ordered by
+// distance from the start of l.
+func (a Arc2) IntersectLine2(l Line2) []Point2 {
+	d := l.End.Sub(l.Start)
+	f := l.Start.Sub(a.Centre)
+	qa := d.Dot(d)
+	if qa == 0 {
+		return nil
+	}
+	qb := f.Dot(d)
+	qc := f.Dot(f) - a.Radius*a.Radius
+	disc := qb*qb - qa*qc
+	if disc < 0 {
+		return nil
+	}
+	s := sqrt(disc)
+
+	var res []Point2
+	for i, t := range [2]float32{(-qb - s) / qa, (-qb + s) / qa} {
+		if t < 0 || t > 1 || (i == 1 && s == 0) {
+			continue
+		}
+		p := l.Start.Add(d.Mul(t))
+		if a.containsPointOnCircle(p) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// IntersectCircle returns the points where the edge of the circle c crosses the arc.
+func (a Arc2) IntersectCircle(c Circle) []Point2 {
+	d := c.Centre.Sub(a.Centre)
+	dist := d.Len()
+	if dist == 0 || dist > a.Radius+c.Radius || dist < abs(a.Radius-c.Radius) {
+		return nil
+	}
+
+	// Distance from the arc's centre to the chord joining the crossing points
+	along := (dist*dist + a.Radius*a.Radius - c.Radius*c.Radius) / (2 * dist)
+	h := sqrt(max(a.Radius*a.Radius-along*along, 0))
+	u := d.Mul(1 / dist)
+	mid := a.Centre.Add(u.Mul(along))
+	perp := Vec2{-u[1], u[0]}
+
+	var res []Point2
+	for i, p := range [2]Point2{mid.Add(perp.Mul(h)), mid.Sub(perp.Mul(h))} {
+		if i == 1 && h == 0 {
+			break
+		}
+		if a.containsPointOnCircle(p) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// containsPointOnCircle reports whether p, which lies on the arc's circle, lies within the arc.
+func (a Arc2) containsPointOnCircle(p Point2) bool {
+	d := p.Sub(a.Centre)
+	return a.ContainsAngle(atan2(d[1], d[0]))
+}
+
+// Sector2 is the region of a circle between two radii, shaped like a slice of pie. The sector
+// runs counter clockwise from the Start angle to the End angle, both given in radians measured
+// from the x axis. A sector is a natural shape for cone of vision and melee swing checks.
+type Sector2 struct {
+	Centre Point2
+	Radius float32
+	Start  float32
+	End    float32
+}
+
+// SectorFromDirection returns a sector centred on the direction dir that spreads by halfAngle
+// radians to each side.
+func SectorFromDirection(centre Point2, dir Vec2, radius, halfAngle float32) Sector2 {
+	a := atan2(dir[1], dir[0])
+	return Sector2{Centre: centre, Radius: radius, Start: a - halfAngle, End: a + halfAngle}
+}
+
+// Arc returns the curved edge of the sector.
+func (s Sector2) Arc() Arc2 {
+	return Arc2{Centre: s.Centre, Radius: s.Radius, Start: s.Start, End: s.End}
+}
+
+// Sweep returns the angle in radians covered by the sector, in the range [0, 2π].
+func (s Sector2) Sweep() float32 {
+	return s.Arc().Sweep()
+}
+
+// Area returns the area of the sector.
+func (s Sector2) Area() float32 {
+	return s.Sweep() * s.Radius * s.Radius / 2
+}
+
+// PointAt returns the point on the sector's circle at the angle in radians.
+func (s Sector2) PointAt(angle float32) Point2 {
+	return s.Arc().PointAt(angle)
+}
+
+// Bounds returns the smallest Rect that contains the sector.
+func (s Sector2) Bounds() Rect {
+	b := s.Arc().Bounds()
+	pmin := Point2{min(b.Position[0]-b.Size[0], s.Centre[0]), min(b.Position[1]-b.Size[1], s.Centre[1])}
+	pmax := Point2{max(b.Position[0]+b.Size[0], s.Centre[0]), max(b.Position[1]+b.Size[1], s.Centre[1])}
+	return RectFromCorners(pmin, pmax)
+}
+
+// ContainsPoint2 reports whether the point lies within the sector.
+func (s Sector2) ContainsPoint2(p Point2) bool {
+	d := p.Sub(s.Centre)
+	dd := d.LenSqr()
+	if dd > s.Radius*s.Radius {
+		return false
+	}
+	return dd == 0 || s.Arc().ContainsAngle(atan2(d[1], d[0]))
+}
+
+// ClosestPoint returns the point in the sector that is closest to p, which is p itself when
+// it lies inside the sector.
+func (s Sector2) ClosestPoint(p Point2) Point2 {
+	if s.ContainsPoint2(p) {
+		return p
+	}
+	arc := s.Arc()
+	best := arc.ClosestPoint(p)
+	bestDist := p.Sub(best).LenSqr()
+	for _, edge := range [2]Line2{{s.Centre, arc.StartPoint()}, {s.Centre, arc.EndPoint()}} {
+		c := edge.ClosestPoint(p)
+		if d := p.Sub(c).LenSqr(); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// IntersectsCircle reports whether the circle overlaps the sector.
+func (s Sector2) IntersectsCircle(c Circle) bool {
+	return s.ClosestPoint(c.Centre).Sub(c.Centre).LenSqr() <= c.Radius*c.Radius
+}
+
+// IntersectsLine2 reports whether any part of the line segment l lies within the sector.
+func (s Sector2) IntersectsLine2(l Line2) bool {
+	if s.ContainsPoint2(l.Start) || s.ContainsPoint2(l.End) {
+		return true
+	}
+	arc := s.Arc()
+	if len(arc.IntersectLine2(l)) > 0 {
+		return true
+	}
+	if _, _, ok := intersectSegments2(l.Start, l.End, s.Centre, arc.StartPoint()); ok {
+		return true
+	}
+	_, _, ok := intersectSegments2(l.Start, l.End, s.Centre, arc.EndPoint())
+	return ok
+}
@@ -0,0 +1,99 @@
+package geom
+
+import "testing"
+
+func TestArc2(t *testing.T) {
+	near := func(a, b Point2) bool { return a.Sub(b).Len() < 1e-4 }
+
+	// Upper half of the unit circle centred on (1, 1)
+	a := Arc2{Centre: Point2{1, 1}, Radius: 1, Start: 0, End: pi}
+
+	if got := a.Sweep(); abs(got-pi) > 1e-6 {
+		t.Errorf("Sweep: got %v, wanted π", got)
+	}
+	if got := (Arc2{Start: 3 * pi / 2, End: pi / 2}).Sweep(); abs(got-pi) > 1e-5 {
+		t.Errorf("Sweep through zero: got %v, wanted π", got)
+	}
+	if got := (Arc2{Start: 1, End: 1 + 2*pi}).Sweep(); got != 2*pi {
+		t.Errorf("Sweep full circle: got %v, wanted 2π", got)
+	}
+
+	b := a.Bounds()
+	if !near(b.TopLeft(), Point2{0, 1}) || !near(b.Position.Add(b.Size), Point2{2, 2}) {
+		t.Errorf("Bounds: got %v", b)
+	}
+
+	if got := a.ClosestPoint(Point2{1, 5}); !near(got, Point2{1, 2}) {
+		t.Errorf("ClosestPoint above: got %v, wanted (1,2)", got)
+	}
+	if got := a.ClosestPoint(Point2{1.5, -3}); !near(got, Point2{2, 1}) {
+		t.Errorf("ClosestPoint below: got %v, wanted the start point", got)
+	}
+
+	// A vertical segment through the centre crosses the full circle twice but the arc once
+	pts := a.IntersectLine2(Line2{Start: Point2{1, -1}, End: Point2{1, 3}})
+	if len(pts) != 1 || !near(pts[0], Point2{1, 2}) {
+		t.Errorf("IntersectLine2: got %v, wanted [(1,2)]", pts)
+	}
+	if pts := a.IntersectLine2(Line2{Start: Point2{1, 1}, End: Point2{1.5, 1.5}}); len(pts) != 0 {
+		t.Errorf("IntersectLine2 short segment: got %v, wanted none", pts)
+	}
+
+	pts = a.IntersectCircle(Circle{Centre: Point2{2, 1}, Radius: 1})
+	want := Point2{1.5, 1 + sqrt(3)/2}
+	if len(pts) != 1 || !near(pts[0], want) {
+		t.Errorf("IntersectCircle: got %v, wanted [%v]", pts, want)
+	}
+}
+
+func TestSector2(t *testing.T) {
+	// A 90° cone of vision looking along +x
+	s := SectorFromDirection(Point2{0, 0}, Vec2{1, 0}, 10, pi/4)
+
+	testCases := []struct {
+		name string
+		p    Point2
+		want bool
+	}{
+		{name: "centre", p: Point2{0, 0}, want: true},
+		{name: "ahead", p: Point2{5, 0}, want: true},
+		{name: "edge of view", p: Point2{5, 4.9}, want: true},
+		{name: "outside view", p: Point2{5, 5.1}, want: false},
+		{name: "behind", p: Point2{-1, 0}, want: false},
+		{name: "too far", p: Point2{10.1, 0}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.ContainsPoint2(tc.p); got != tc.want {
+				t.Errorf("ContainsPoint2(%v): got %v, wanted %v", tc.p, got, tc.want)
+			}
+		})
+	}
+
+	if got := s.Area(); abs(got-25*pi) > 1e-3 {
+		t.Errorf("Area: got %v, wanted 25π", got)
+	}
+
+	b := s.Bounds()
+	h := 10 * sqrt(2) / 2
+	if b.TopLeft().Sub(Point2{0, -h}).Len() > 1e-4 || b.Position.Add(b.Size).Sub(Point2{10, h}).Len() > 1e-4 {
+		t.Errorf("Bounds: got %v", b)
+	}
+
+	if !s.IntersectsCircle(Circle{Centre: Point2{3, 4}, Radius: 1}) {
+		t.Errorf("IntersectsCircle: circle overlapping the edge should hit")
+	}
+	if s.IntersectsCircle(Circle{Centre: Point2{-2, 0}, Radius: 1}) {
+		t.Errorf("IntersectsCircle: circle behind should miss")
+	}
+	if !s.IntersectsCircle(Circle{Centre: Point2{11, 0}, Radius: 1.5}) {
+		t.Errorf("IntersectsCircle: circle overlapping the arc should hit")
+	}
+
+	if !s.IntersectsLine2(Line2{Start: Point2{5, -20}, End: Point2{5, 20}}) {
+		t.Errorf("IntersectsLine2: segment crossing the sector should hit")
+	}
+	if s.IntersectsLine2(Line2{Start: Point2{-5, -20}, End: Point2{-5, 20}}) {
+		t.Errorf("IntersectsLine2: segment behind should miss")
+	}
+}
@@ -0,0 +1,79 @@
+package geom
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// batchBlock is the number of pairs claimed at a time by each worker in the parallel batch
+// functions. Claiming small blocks keeps the workers busy when some tests take longer than
+// others.
+const batchBlock = 256
+
+// IntersectPairs tests whether the boxes in each pair overlap using IntersectsBox3. The pair
+// indices refer to elements of boxes and the result for pairs[i] is written to out[i]. out must
+// be at least as long as pairs.
+func IntersectPairs(boxes []Box3, pairs []Pair, out []bool) {
+	CheckPairs(pairs, out, func(a, b int) bool {
+		return IntersectsBox3(boxes[a], boxes[b])
+	})
+}
+
+// IntersectPairsParallel is like IntersectPairs but divides the pairs between a pool of
+// workers goroutines. A workers value of zero or less uses one goroutine per processor. The
+// results are the same as IntersectPairs, in the same order.
+func IntersectPairsParallel(boxes []Box3, pairs []Pair, out []bool, workers int) {
+	CheckPairsParallel(pairs, out, workers, func(a, b int) bool {
+		return IntersectsBox3(boxes[a], boxes[b])
+	})
+}
+
+// CheckPairs calls test with the indices in each pair, writing the result for pairs[i] to
+// out[i]. out must be at least as long as pairs.
+func CheckPairs(pairs []Pair, out []bool, test func(a, b int) bool) {
+	out = out[:len(pairs)]
+	for i, p := range pairs {
+		out[i] = test(p.A, p.B)
+	}
+}
+
+// CheckPairsParallel is like CheckPairs but divides the pairs between a pool of workers
+// goroutines, so test must be safe to call concurrently. A workers value of zero or less uses
+// one goroutine per processor. Each result is written to the element of out that matches its
+// pair so the output does not depend on how the work was scheduled. It returns once every pair
+// has been tested.
+func CheckPairsParallel(pairs []Pair, out []bool, workers int, test func(a, b int) bool) {
+	out = out[:len(pairs)]
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if blocks := (len(pairs) + batchBlock - 1) / batchBlock; workers > blocks {
+		workers = blocks
+	}
+	if workers < 2 {
+		CheckPairs(pairs, out, test)
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(next.Add(batchBlock)) - batchBlock
+				if start >= len(pairs) {
+					return
+				}
+				end := start + batchBlock
+				if end > len(pairs) {
+					end = len(pairs)
+				}
+				CheckPairs(pairs[start:end], out[start:end], test)
+			}
+		}()
+	}
+	wg.Wait()
+}
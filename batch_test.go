@@ -0,0 +1,104 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func randomBoxes(r *rand.Rand, n int) []Box3 {
+	boxes := make([]Box3, n)
+	for i := range boxes {
+		pos := RandomPointInAABB(r, AABB{Size: Vec3{20, 20, 20}})
+		size := Vec3{0.5 + r.Float32()*2, 0.5 + r.Float32()*2, 0.5 + r.Float32()*2}
+		if i%2 == 0 {
+			boxes[i] = &AABB{Position: pos, Size: size}
+		} else {
+			o := NewOBB(pos, size, mgl32.QuatRotate(r.Float32()*2*pi, RandomUnitVec3(r)))
+			boxes[i] = &o
+		}
+	}
+	return boxes
+}
+
+func allPairs(n int) []Pair {
+	var pairs []Pair
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			pairs = append(pairs, Pair{A: a, B: b})
+		}
+	}
+	return pairs
+}
+
+func TestIntersectPairs(t *testing.T) {
+	boxes := randomBoxes(rand.New(rand.NewSource(1)), 120)
+	pairs := allPairs(len(boxes))
+
+	want := make([]bool, len(pairs))
+	hits := 0
+	for i, p := range pairs {
+		want[i] = IntersectsBox3(boxes[p.A], boxes[p.B])
+		if want[i] {
+			hits++
+		}
+	}
+	if hits == 0 {
+		t.Fatalf("test data has no overlapping boxes")
+	}
+
+	got := make([]bool, len(pairs))
+	IntersectPairs(boxes, pairs, got)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("IntersectPairs: pair %v got %v, wanted %v", pairs[i], got[i], want[i])
+		}
+	}
+
+	for _, workers := range []int{0, 1, 3, 64} {
+		got := make([]bool, len(pairs))
+		IntersectPairsParallel(boxes, pairs, got, workers)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("IntersectPairsParallel with %d workers: pair %v got %v, wanted %v", workers, pairs[i], got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestCheckPairsParallel(t *testing.T) {
+	pairs := allPairs(200)
+	out := make([]bool, len(pairs))
+	CheckPairsParallel(pairs, out, 4, func(a, b int) bool { return (a+b)%3 == 0 })
+	for i, p := range pairs {
+		if want := (p.A+p.B)%3 == 0; out[i] != want {
+			t.Fatalf("pair %v: got %v, wanted %v", p, out[i], want)
+		}
+	}
+
+	// Nothing to do
+	CheckPairsParallel(nil, nil, 4, func(a, b int) bool {
+		t.Errorf("test called with no pairs")
+		return false
+	})
+}
+
+func BenchmarkIntersectPairs(b *testing.B) {
+	boxes := randomBoxes(rand.New(rand.NewSource(1)), 200)
+	pairs := allPairs(len(boxes))
+	out := make([]bool, len(pairs))
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			IntersectPairs(boxes, pairs, out)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			IntersectPairsParallel(boxes, pairs, out, 0)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package geom
+
+import "sort"
+
+// Bezier2 is a cubic Bézier curve in 2 dimensions. The curve starts at P0, ends at P3 and is
+// pulled toward the control points P1 and P2.
+type Bezier2 struct {
+	P0, P1, P2, P3 Point2
+}
+
+// bezierMaxDepth limits the number of times a curve will be subdivided when searching for
+// intersections.
+const bezierMaxDepth = 24
+
+// Point returns the point on the curve at t, which should be in the range [0,1].
+func (b Bezier2) Point(t float32) Point2 {
+	u := 1 - t
+	b0 := u * u * u
+	b1 := 3 * u * u * t
+	b2 := 3 * u * t * t
+	b3 := t * t * t
+
+	return Point2{
+		b0*b.P0[0] + b1*b.P1[0] + b2*b.P2[0] + b3*b.P3[0],
+		b0*b.P0[1] + b1*b.P1[1] + b2*b.P2[1] + b3*b.P3[1],
+	}
+}
+
+// Split divides the curve at t using de Casteljau's algorithm, returning the curves
+// before and after t.
+func (b Bezier2) Split(t float32) (Bezier2, Bezier2) {
+	p01 := LerpVec2(b.P0, b.P1, t)
+	p12 := LerpVec2(b.P1, b.P2, t)
+	p23 := LerpVec2(b.P2, b.P3, t)
+	p012 := LerpVec2(p01, p12, t)
+	p123 := LerpVec2(p12, p23, t)
+	p := LerpVec2(p012, p123, t)
+
+	return Bezier2{P0: b.P0, P1: p01, P2: p012, P3: p},
+		Bezier2{P0: p, P1: p123, P2: p23, P3: b.P3}
+}
+
+// Bounds returns a Rect that contains the curve. The Rect bounds the control points so
+// it may be larger than the tightest possible bounds.
+func (b Bezier2) Bounds() Rect {
+	pmin := Point2{
+		min(min(b.P0[0], b.P1[0]), min(b.P2[0], b.P3[0])),
+		min(min(b.P0[1], b.P1[1]), min(b.P2[1], b.P3[1])),
+	}
+	pmax := Point2{
+		max(max(b.P0[0], b.P1[0]), max(b.P2[0], b.P3[0])),
+		max(max(b.P0[1], b.P1[1]), max(b.P2[1], b.P3[1])),
+	}
+	return RectFromCorners(pmin, pmax)
+}
+
+// flat reports whether both control points lie within tol of the line through the end points.
+func (b Bezier2) flat(tol float32) bool {
+	d := b.P3.Sub(b.P0)
+	l := d.Len()
+	if l <= tol {
+		return b.P1.Sub(b.P0).Len() <= tol && b.P2.Sub(b.P0).Len() <= tol
+	}
+	d1 := abs(Cross2(d, b.P1.Sub(b.P0))) / l
+	d2 := abs(Cross2(d, b.P2.Sub(b.P0))) / l
+	return d1 <= tol && d2 <= tol
+}
+
+// tolerance returns the distance used to decide when a curve is flat enough to be treated
+// as a line segment.
+func (b Bezier2) tolerance() float32 {
+	r := b.Bounds()
+	return max(max(r.Size[0], r.Size[1])*1e-4, epsilon32)
+}
+
+// CurveIntersection2 describes a point where a curve crosses a line, ray or another curve.
+type CurveIntersection2 struct {
+	Point Point2
+	T     float32 // the parameter along the curve
+	U     float32 // the parameter along the other curve or line, or the distance along a ray
+}
+
+// IntersectLine2 returns the points where the curve crosses the line segment l, ordered by
+// the parameter along the curve. U holds the parameter along l in the range [0,1].
+func (b Bezier2) IntersectLine2(l Line2) []CurveIntersection2 {
+	tol := b.tolerance()
+	lbounds := RectFromCorners(l.Start, l.End)
+
+	var res []CurveIntersection2
+	var recurse func(c Bezier2, t0, t1 float32, depth int)
+	recurse = func(c Bezier2, t0, t1 float32, depth int) {
+		if !c.Bounds().Inflate(tol).IntersectsRect(lbounds) {
+			return
+		}
+		if depth >= bezierMaxDepth || c.flat(tol) {
+			s, u, ok := intersectSegments2(c.P0, c.P3, l.Start, l.End)
+			if ok {
+				res = appendCurveIntersection2(res, CurveIntersection2{
+					Point: l.Start.Add(l.End.Sub(l.Start).Mul(u)),
+					T:     t0 + s*(t1-t0),
+					U:     u,
+				}, tol)
+			}
+			return
+		}
+		left, right := c.Split(0.5)
+		tm := (t0 + t1) / 2
+		recurse(left, t0, tm, depth+1)
+		recurse(right, tm, t1, depth+1)
+	}
+	recurse(b, 0, 1, 0)
+
+	return res
+}
+
+// IntersectRay2 returns the points where the ray crosses the curve, ordered by the parameter
+// along the curve. U holds the distance along the ray.
+func (b Bezier2) IntersectRay2(r Ray2) []CurveIntersection2 {
+	// Replace the ray by a segment long enough to pass beyond the far side of the curve
+	bounds := b.Bounds()
+	reach := r.Origin.Sub(bounds.Position).Len() + bounds.Size.Len() + 1
+	l := Line2{Start: r.Origin, End: r.Point(reach)}
+
+	res := b.IntersectLine2(l)
+	for i := range res {
+		res[i].U *= reach
+	}
+	return res
+}
+
+// IntersectBezier2 returns the points where the curve crosses the curve c, ordered by
+// the parameter along b. U holds the parameter along c.
+func (b Bezier2) IntersectBezier2(c Bezier2) []CurveIntersection2 {
+	tol := min(b.tolerance(), c.tolerance())
+
+	var res []CurveIntersection2
+	var recurse func(b Bezier2, bt0, bt1 float32, c Bezier2, ct0, ct1 float32, depth int)
+	recurse = func(b Bezier2, bt0, bt1 float32, c Bezier2, ct0, ct1 float32, depth int) {
+		bb := b.Bounds()
+		cb := c.Bounds()
+		if !bb.Inflate(tol).IntersectsRect(cb) {
+			return
+		}
+
+		bflat := depth >= bezierMaxDepth || b.flat(tol)
+		cflat := depth >= bezierMaxDepth || c.flat(tol)
+		if bflat && cflat {
+			s, u, ok := intersectSegments2(b.P0, b.P3, c.P0, c.P3)
+			if ok {
+				res = appendCurveIntersection2(res, CurveIntersection2{
+					Point: b.P0.Add(b.P3.Sub(b.P0).Mul(s)),
+					T:     bt0 + s*(bt1-bt0),
+					U:     ct0 + u*(ct1-ct0),
+				}, tol)
+			}
+			return
+		}
+
+		// Subdivide the curve that is least flat, preferring the larger one
+		if cflat || (!bflat && bb.Size.Len() >= cb.Size.Len()) {
+			left, right := b.Split(0.5)
+			tm := (bt0 + bt1) / 2
+			recurse(left, bt0, tm, c, ct0, ct1, depth+1)
+			recurse(right, tm, bt1, c, ct0, ct1, depth+1)
+			return
+		}
+
+		left, right := c.Split(0.5)
+		tm := (ct0 + ct1) / 2
+		recurse(b, bt0, bt1, left, ct0, tm, depth+1)
+		recurse(b, bt0, bt1, right, tm, ct1, depth+1)
+	}
+	recurse(b, 0, 1, c, 0, 1, 0)
+
+	sort.Slice(res, func(i, j int) bool { return res[i].T < res[j].T })
+	return res
+}
+
+// appendCurveIntersection2 appends ci to res unless an intersection at the same point has
+// already been found. Neighbouring subdivisions can both report an intersection that
+// lies on their shared end point.
+func appendCurveIntersection2(res []CurveIntersection2, ci CurveIntersection2, tol float32) []CurveIntersection2 {
+	for i := range res {
+		if res[i].Point.Sub(ci.Point).Len() <= tol*4 {
+			return res
+		}
+	}
+	return append(res, ci)
+}
+
+// intersectSegments2 finds the intersection of the segments a0-a1 and b0-b1, returning the
+// parameters along each segment.
+func intersectSegments2(a0, a1, b0, b1 Point2) (float32, float32, bool) {
+	da := a1.Sub(a0)
+	db := b1.Sub(b0)
+	denom := Cross2(da, db)
+	if denom == 0 {
+		// Parallel or degenerate segments
+		return 0, 0, false
+	}
+
+	ab := b0.Sub(a0)
+	s := Cross2(ab, db) / denom
+	u := Cross2(ab, da) / denom
+
+	const slack = 1e-6
+	if s < -slack || s > 1+slack || u < -slack || u > 1+slack {
+		return 0, 0, false
+	}
+	return Clamp(s, 0, 1), Clamp(u, 0, 1), true
+}
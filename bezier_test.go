@@ -0,0 +1,101 @@
+package geom
+
+import (
+	"testing"
+)
+
+// arcBezier2 is a curve that rises from (0,0) to a peak of y=0.75 at x=1 and falls back to (2,0)
+var arcBezier2 = Bezier2{
+	P0: Point2{0, 0},
+	P1: Point2{0, 1},
+	P2: Point2{2, 1},
+	P3: Point2{2, 0},
+}
+
+func TestBezier2IntersectLine2(t *testing.T) {
+	testCases := []struct {
+		name string
+		l    Line2
+		want []Point2
+	}{
+		{
+			name: "horizontal-crossing-twice",
+			l:    Line2{Start: Point2{-1, 0.5}, End: Point2{3, 0.5}},
+			want: []Point2{{0.2302, 0.5}, {1.7698, 0.5}},
+		},
+		{
+			name: "vertical-through-peak",
+			l:    Line2{Start: Point2{1, -1}, End: Point2{1, 2}},
+			want: []Point2{{1, 0.75}},
+		},
+		{
+			name: "above-curve",
+			l:    Line2{Start: Point2{-1, 1}, End: Point2{3, 1}},
+			want: nil,
+		},
+		{
+			name: "too-short",
+			l:    Line2{Start: Point2{0.9, -1}, End: Point2{0.9, 0.5}},
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := arcBezier2.IntersectLine2(tc.l)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d intersections, wanted %d: %+v", len(got), len(tc.want), got)
+			}
+			for i := range got {
+				if !got[i].Point.ApproxEqualThreshold(tc.want[i], 1e-3) {
+					t.Errorf("intersection %d: got %v, wanted %v", i, got[i].Point, tc.want[i])
+				}
+				if !got[i].Point.ApproxEqualThreshold(arcBezier2.Point(got[i].T), 1e-3) {
+					t.Errorf("intersection %d: parameter %v does not map to point %v", i, got[i].T, got[i].Point)
+				}
+			}
+		})
+	}
+}
+
+func TestBezier2IntersectRay2(t *testing.T) {
+	r := Ray2{Origin: Point2{1, -1}, Direction: Vec2{0, 1}}
+	got := arcBezier2.IntersectRay2(r)
+	if len(got) != 1 {
+		t.Fatalf("got %d intersections, wanted 1", len(got))
+	}
+	if !cmp(got[0].U, 1.75) {
+		t.Errorf("got distance %v, wanted 1.75", got[0].U)
+	}
+
+	r.Direction = Vec2{0, -1}
+	if got := arcBezier2.IntersectRay2(r); len(got) != 0 {
+		t.Errorf("got %d intersections for ray pointing away, wanted 0", len(got))
+	}
+}
+
+func TestBezier2IntersectBezier2(t *testing.T) {
+	// the same arc flipped upside down and raised so it crosses arcBezier2 twice
+	c := Bezier2{
+		P0: Point2{0, 1},
+		P1: Point2{0, 0},
+		P2: Point2{2, 0},
+		P3: Point2{2, 1},
+	}
+
+	got := arcBezier2.IntersectBezier2(c)
+	if len(got) != 2 {
+		t.Fatalf("got %d intersections, wanted 2: %+v", len(got), got)
+	}
+	for i := range got {
+		if !cmp(got[i].Point[1], 0.5) {
+			t.Errorf("intersection %d: got %v, wanted y=0.5", i, got[i].Point)
+		}
+		if !got[i].Point.ApproxEqualThreshold(c.Point(got[i].U), 1e-3) {
+			t.Errorf("intersection %d: parameter %v does not map to point %v", i, got[i].U, got[i].Point)
+		}
+	}
+	if got[0].T > got[1].T {
+		t.Errorf("intersections not ordered along curve")
+	}
+}
@@ -0,0 +1,237 @@
+package geom
+
+import "sort"
+
+// Pair identifies two objects whose bounds overlap. A is always less than B.
+type Pair struct {
+	A, B int
+}
+
+// makePair returns the pair of a and b in canonical order.
+func makePair(a, b int) Pair {
+	if a > b {
+		a, b = b, a
+	}
+	return Pair{A: a, B: b}
+}
+
+// SweepAndPrune is a broadphase that finds overlapping boxes by sorting them along one axis
+// and sweeping over the sorted intervals. The sort order is kept between updates so when
+// objects move a little each frame re-sorting is close to linear.
+//
+// It works best when objects are spread out along the sort axis. Use SelectAxis to choose the
+// axis along which the boxes are most widely distributed.
+type SweepAndPrune struct {
+	axis    int
+	entries []sapEntry  // ordered by the minimum of each box on the axis once sorted
+	index   map[int]int // maps ids to their position in entries
+	sorted  bool
+}
+
+type sapEntry struct {
+	id  int
+	box AABB
+	min float32 // minimum of the box along the sort axis
+}
+
+// NewSweepAndPrune returns an empty broadphase that sorts boxes along the given axis, which
+// must be 0, 1 or 2 for the x, y or z axis.
+func NewSweepAndPrune(axis int) *SweepAndPrune {
+	return &SweepAndPrune{
+		axis:   axis,
+		index:  make(map[int]int),
+		sorted: true,
+	}
+}
+
+// Len returns the number of boxes in the broadphase.
+func (s *SweepAndPrune) Len() int {
+	return len(s.entries)
+}
+
+// Axis returns the axis that boxes are sorted along.
+func (s *SweepAndPrune) Axis() int {
+	return s.axis
+}
+
+// Set adds the box with the given id or replaces the box if the id is already present.
+func (s *SweepAndPrune) Set(id int, box AABB) {
+	e := sapEntry{id: id, box: box, min: box.Position[s.axis] - box.Size[s.axis]}
+	if i, ok := s.index[id]; ok {
+		s.entries[i] = e
+	} else {
+		s.index[id] = len(s.entries)
+		s.entries = append(s.entries, e)
+	}
+	s.sorted = false
+}
+
+// Remove removes the box with the given id. It does nothing if the id is not present.
+func (s *SweepAndPrune) Remove(id int) {
+	i, ok := s.index[id]
+	if !ok {
+		return
+	}
+	delete(s.index, id)
+
+	// Preserve the order of the remaining entries so the next sort stays cheap
+	copy(s.entries[i:], s.entries[i+1:])
+	s.entries = s.entries[:len(s.entries)-1]
+	for j := i; j < len(s.entries); j++ {
+		s.index[s.entries[j].id] = j
+	}
+}
+
+// SelectAxis changes the sort axis to the one along which the centres of the boxes vary the
+// most, which minimises the number of intervals that overlap during the sweep.
+func (s *SweepAndPrune) SelectAxis() {
+	if len(s.entries) == 0 {
+		return
+	}
+
+	var sum, sumSq Vec3
+	for i := range s.entries {
+		p := s.entries[i].box.Position
+		sum = sum.Add(p)
+		sumSq = sumSq.Add(Vec3{p[0] * p[0], p[1] * p[1], p[2] * p[2]})
+	}
+	n := float32(len(s.entries))
+	axis := 0
+	var best float32 = -1
+	for i := 0; i < 3; i++ {
+		variance := sumSq[i]/n - (sum[i]/n)*(sum[i]/n)
+		if variance > best {
+			best = variance
+			axis = i
+		}
+	}
+
+	if axis == s.axis {
+		return
+	}
+	s.axis = axis
+	for i := range s.entries {
+		s.entries[i].min = s.entries[i].box.Position[axis] - s.entries[i].box.Size[axis]
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].min < s.entries[j].min })
+	s.reindex()
+	s.sorted = true
+}
+
+// sort restores the order of the entries. Insertion sort is used since the entries are
+// expected to be nearly sorted already.
+func (s *SweepAndPrune) sort() {
+	if s.sorted {
+		return
+	}
+	for i := 1; i < len(s.entries); i++ {
+		e := s.entries[i]
+		j := i - 1
+		for j >= 0 && s.entries[j].min > e.min {
+			s.entries[j+1] = s.entries[j]
+			j--
+		}
+		s.entries[j+1] = e
+	}
+	s.reindex()
+	s.sorted = true
+}
+
+func (s *SweepAndPrune) reindex() {
+	for i := range s.entries {
+		s.index[s.entries[i].id] = i
+	}
+}
+
+// Pairs appends every pair of overlapping boxes to dst and returns the extended slice.
+func (s *SweepAndPrune) Pairs(dst []Pair) []Pair {
+	s.sort()
+
+	for i := range s.entries {
+		a := &s.entries[i]
+		amax := a.box.Position[s.axis] + a.box.Size[s.axis]
+		for j := i + 1; j < len(s.entries); j++ {
+			b := &s.entries[j]
+			if b.min > amax {
+				// No later box can overlap a along the sort axis
+				break
+			}
+			if a.box.IntersectsAABB(&b.box) {
+				dst = append(dst, makePair(a.id, b.id))
+			}
+		}
+	}
+	return dst
+}
+
+// PairEvents describes how the set of overlapping pairs changed between two updates of a
+// PairTracker.
+type PairEvents struct {
+	Began  []Pair // pairs that started overlapping
+	Stayed []Pair // pairs that were already overlapping and still are
+	Ended  []Pair // pairs that stopped overlapping
+}
+
+// PairTracker turns the overlapping pairs reported by a broadphase each frame into events
+// that mark when each pair begins and ends overlapping.
+type PairTracker struct {
+	active map[Pair]bool // value records whether the pair was seen in the current update
+	events PairEvents
+}
+
+// NewPairTracker returns a tracker with no active pairs.
+func NewPairTracker() *PairTracker {
+	return &PairTracker{active: make(map[Pair]bool)}
+}
+
+// Update replaces the set of overlapping pairs and reports how it changed since the previous
+// update. The order of A and B within each pair is ignored. Began and Stayed follow the order
+// of pairs and Ended is sorted by A and then B, so the events are the same from run to run. The
+// returned slices are reused by the next call to Update.
+func (t *PairTracker) Update(pairs []Pair) PairEvents {
+	t.events.Began = t.events.Began[:0]
+	t.events.Stayed = t.events.Stayed[:0]
+	t.events.Ended = t.events.Ended[:0]
+
+	for _, p := range pairs {
+		p = makePair(p.A, p.B)
+		seen, ok := t.active[p]
+		switch {
+		case !ok:
+			t.events.Began = append(t.events.Began, p)
+		case !seen:
+			t.events.Stayed = append(t.events.Stayed, p)
+		default:
+			// Duplicate in this update
+			continue
+		}
+		t.active[p] = true
+	}
+
+	for p, seen := range t.active {
+		if !seen {
+			t.events.Ended = append(t.events.Ended, p)
+			delete(t.active, p)
+			continue
+		}
+		t.active[p] = false
+	}
+	// Map iteration order is random
+	sort.Slice(t.events.Ended, func(i, j int) bool {
+		a, b := t.events.Ended[i], t.events.Ended[j]
+		return a.A < b.A || (a.A == b.A && a.B < b.B)
+	})
+
+	return t.events
+}
+
+// Overlapping reports whether the pair of ids was overlapping at the last update.
+func (t *PairTracker) Overlapping(a, b int) bool {
+	_, ok := t.active[makePair(a, b)]
+	return ok
+}
+
+// Len returns the number of pairs that were overlapping at the last update.
+func (t *PairTracker) Len() int {
+	return len(t.active)
+}
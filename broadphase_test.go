@@ -0,0 +1,191 @@
+package geom
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// bruteForcePairs returns the overlapping pairs by testing every box against every other.
+func bruteForcePairs(boxes map[int]AABB) []Pair {
+	var pairs []Pair
+	for a, ba := range boxes {
+		for b, bb := range boxes {
+			if a < b && ba.IntersectsAABB(&bb) {
+				pairs = append(pairs, Pair{A: a, B: b})
+			}
+		}
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+func sortPairs(pairs []Pair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+}
+
+func equalPairs(a, b []Pair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSweepAndPrune(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomBox := func() AABB {
+		return AABB{
+			Position: Point3{r.Float32() * 100, r.Float32() * 20, r.Float32() * 20},
+			Size:     Vec3{r.Float32()*3 + 0.5, r.Float32()*3 + 0.5, r.Float32()*3 + 0.5},
+		}
+	}
+
+	s := NewSweepAndPrune(0)
+	boxes := make(map[int]AABB)
+	for id := 0; id < 200; id++ {
+		boxes[id] = randomBox()
+		s.Set(id, boxes[id])
+	}
+
+	check := func(step string) {
+		t.Helper()
+		got := s.Pairs(nil)
+		sortPairs(got)
+		want := bruteForcePairs(boxes)
+		if !equalPairs(got, want) {
+			t.Fatalf("%s: got %d pairs, wanted %d", step, len(got), len(want))
+		}
+	}
+	check("initial")
+
+	// Move every box a little, as in a typical frame
+	for frame := 0; frame < 5; frame++ {
+		for id, b := range boxes {
+			b.Position = b.Position.Add(Vec3{r.Float32() - 0.5, r.Float32() - 0.5, r.Float32() - 0.5})
+			boxes[id] = b
+			s.Set(id, b)
+		}
+		check("move")
+	}
+
+	for id := 0; id < 200; id += 3 {
+		delete(boxes, id)
+		s.Remove(id)
+	}
+	if s.Len() != len(boxes) {
+		t.Fatalf("Len: got %d, wanted %d", s.Len(), len(boxes))
+	}
+	check("remove")
+
+	s.SelectAxis()
+	if s.Axis() != 0 {
+		t.Errorf("SelectAxis: got %d, wanted %d", s.Axis(), 0)
+	}
+	check("select axis")
+}
+
+func BenchmarkSweepAndPrune(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	s := NewSweepAndPrune(0)
+	boxes := make([]AABB, 1000)
+	for i := range boxes {
+		boxes[i] = AABB{
+			Position: Point3{r.Float32() * 1000, r.Float32() * 100, r.Float32() * 100},
+			Size:     Vec3{1, 1, 1},
+		}
+		s.Set(i, boxes[i])
+	}
+
+	var pairs []Pair
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range boxes {
+			boxes[j].Position[0] += r.Float32() - 0.5
+			s.Set(j, boxes[j])
+		}
+		pairs = s.Pairs(pairs[:0])
+	}
+}
+
+func TestPairTracker(t *testing.T) {
+	pt := NewPairTracker()
+
+	testCases := []struct {
+		pairs  []Pair
+		began  []Pair
+		stayed []Pair
+		ended  []Pair
+	}{
+		{
+			pairs: []Pair{{1, 2}, {3, 4}},
+			began: []Pair{{1, 2}, {3, 4}},
+		},
+		{
+			pairs:  []Pair{{2, 1}, {3, 4}, {5, 6}, {5, 6}},
+			began:  []Pair{{5, 6}},
+			stayed: []Pair{{1, 2}, {3, 4}},
+		},
+		{
+			pairs:  []Pair{{5, 6}},
+			stayed: []Pair{{5, 6}},
+			ended:  []Pair{{1, 2}, {3, 4}},
+		},
+		{
+			ended: []Pair{{5, 6}},
+		},
+	}
+
+	for i, tc := range testCases {
+		ev := pt.Update(tc.pairs)
+		sortPairs(ev.Began)
+		sortPairs(ev.Stayed)
+		if !equalPairs(ev.Began, tc.began) {
+			t.Errorf("update %d: began got %v, wanted %v", i, ev.Began, tc.began)
+		}
+		if !equalPairs(ev.Stayed, tc.stayed) {
+			t.Errorf("update %d: stayed got %v, wanted %v", i, ev.Stayed, tc.stayed)
+		}
+		if !equalPairs(ev.Ended, tc.ended) {
+			t.Errorf("update %d: ended got %v, wanted %v", i, ev.Ended, tc.ended)
+		}
+		if want := len(tc.began) + len(tc.stayed); pt.Len() != want {
+			t.Errorf("update %d: Len got %d, wanted %d", i, pt.Len(), want)
+		}
+	}
+
+	pt.Update([]Pair{{7, 8}})
+	if !pt.Overlapping(8, 7) || pt.Overlapping(7, 9) {
+		t.Errorf("Overlapping: got %v and %v, wanted true and false", pt.Overlapping(8, 7), pt.Overlapping(7, 9))
+	}
+}
+
+func TestPairTrackerEndedOrder(t *testing.T) {
+	var pairs, want []Pair
+	for i := 0; i < 50; i++ {
+		// Named in reverse so that sorting is needed
+		pairs = append(pairs, Pair{100 - i, 50 - i})
+	}
+	for i := 49; i >= 0; i-- {
+		want = append(want, Pair{50 - i, 100 - i})
+	}
+
+	for run := 0; run < 10; run++ {
+		pt := NewPairTracker()
+		pt.Update(pairs)
+		ev := pt.Update(nil)
+		if !equalPairs(ev.Ended, want) {
+			t.Fatalf("run %d: ended got %v, wanted %v", run, ev.Ended, want)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package geom
+
+// BVHOptions controls how a bounding volume hierarchy is built.
+type BVHOptions struct {
+	// MaxLeafSize is the largest number of items stored in a leaf. Zero uses a default of 4.
+	MaxLeafSize int
+
+	// Bins is the number of intervals each axis is divided into when choosing where to split
+	// a node. Zero uses a default of 12.
+	Bins int
+}
+
+// BVH is a bounding volume hierarchy over a set of items, which is a tree of boxes where each
+// box contains the boxes of its children. It answers overlap and ray queries without testing
+// every item.
+type BVH struct {
+	items []Bounded
+	nodes []bvhNode // parents always come before their children
+	order []int     // item indices, grouped so that each leaf refers to a contiguous run
+
+	buildCost float32 // the value of Cost when the hierarchy was built
+}
+
+type bvhNode struct {
+	min, max Point3
+	left     int // index of the first child for an interior node or the first entry in order for a leaf
+	right    int // index of the second child for an interior node
+	count    int // number of items in a leaf, zero for an interior node
+}
+
+func (n *bvhNode) leaf() bool { return n.count > 0 }
+
+func (n *bvhNode) bounds() AABB { return AABBFromCorners(n.min, n.max) }
+
+// BuildBVH builds a hierarchy over the items. Nodes holding more than the maximum leaf size
+// are split where the surface area heuristic estimates that queries will be cheapest. Queries
+// report items by their index in the slice, which is retained by the hierarchy.
+func BuildBVH(items []Bounded, opts BVHOptions) *BVH {
+	if opts.MaxLeafSize <= 0 {
+		opts.MaxLeafSize = 4
+	}
+	if opts.Bins <= 0 {
+		opts.Bins = 12
+	}
+
+	b := &BVH{
+		items: items,
+		order: make([]int, len(items)),
+	}
+	if len(items) == 0 {
+		return b
+	}
+
+	boxes := make([]bvhBox, len(items))
+	for i, it := range items {
+		boxes[i] = newBVHBox(it.Bounds())
+		b.order[i] = i
+	}
+
+	bld := bvhBuilder{
+		bvh:        b,
+		boxes:      boxes,
+		opts:       opts,
+		bins:       make([]bvhBin, opts.Bins),
+		rightCost:  make([]float32, opts.Bins),
+		rightCount: make([]int, opts.Bins),
+	}
+	b.nodes = make([]bvhNode, 0, 2*len(items)/opts.MaxLeafSize+1)
+	bld.build(0, len(items))
+	b.buildCost = b.Cost()
+	return b
+}
+
+// bvhBox is an item's bounds stored as corners along with its centroid.
+type bvhBox struct {
+	min, max, centroid Point3
+}
+
+func newBVHBox(a AABB) bvhBox {
+	return bvhBox{min: a.Position.Sub(a.Size), max: a.Position.Add(a.Size), centroid: a.Position}
+}
+
+type bvhBin struct {
+	min, max Point3
+	count    int
+}
+
+type bvhBuilder struct {
+	bvh        *BVH
+	boxes      []bvhBox
+	opts       BVHOptions
+	bins       []bvhBin
+	rightCost  []float32 // cost of the items in the bins after each split position
+	rightCount []int     // number of items in the bins after each split position
+}
+
+// build adds the node covering order[start:end] and its descendants, returning its index.
+func (bld *bvhBuilder) build(start, end int) int {
+	b := bld.bvh
+	idx := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{})
+
+	nmin, nmax := emptyBounds()
+	cmin, cmax := emptyBounds()
+	for _, i := range b.order[start:end] {
+		box := &bld.boxes[i]
+		nmin, nmax = extendBounds(nmin, nmax, box.min, box.max)
+		cmin, cmax = extendBounds(cmin, cmax, box.centroid, box.centroid)
+	}
+	b.nodes[idx].min, b.nodes[idx].max = nmin, nmax
+
+	if end-start <= bld.opts.MaxLeafSize {
+		b.nodes[idx].left, b.nodes[idx].count = start, end-start
+		return idx
+	}
+
+	mid := bld.split(start, end, cmin, cmax)
+	left := bld.build(start, mid)
+	right := bld.build(mid, end)
+	b.nodes[idx].left, b.nodes[idx].right = left, right
+	return idx
+}
+
+// split partitions order[start:end] at the position with the lowest cost estimated by the
+// binned surface area heuristic, returning the index that divides the two halves.
+func (bld *bvhBuilder) split(start, end int, cmin, cmax Point3) int {
+	order := bld.bvh.order
+	bins := bld.bins
+	nb := len(bins)
+
+	bestCost := float32(maxFloat32)
+	bestAxis, bestBin := -1, 0
+
+	for axis := 0; axis < 3; axis++ {
+		extent := cmax[axis] - cmin[axis]
+		if extent <= 0 {
+			continue
+		}
+		for i := range bins {
+			bins[i].min, bins[i].max = emptyBounds()
+			bins[i].count = 0
+		}
+		scale := float32(nb) / extent
+		for _, i := range order[start:end] {
+			box := &bld.boxes[i]
+			bi := bvhBinIndex(box.centroid[axis], cmin[axis], scale, nb)
+			bins[bi].min, bins[bi].max = extendBounds(bins[bi].min, bins[bi].max, box.min, box.max)
+			bins[bi].count++
+		}
+
+		// Sweep from the right to find the cost of everything after each split, then from the
+		// left to add the cost of everything before it. The parent's area is the same for every
+		// candidate so it is left out.
+		rmin, rmax := emptyBounds()
+		rcount := 0
+		for i := nb - 1; i > 0; i-- {
+			rmin, rmax = extendBounds(rmin, rmax, bins[i].min, bins[i].max)
+			rcount += bins[i].count
+			bld.rightCost[i] = surfaceArea(rmin, rmax) * float32(rcount)
+			bld.rightCount[i] = rcount
+		}
+		lmin, lmax := emptyBounds()
+		lcount := 0
+		for i := 0; i < nb-1; i++ {
+			lmin, lmax = extendBounds(lmin, lmax, bins[i].min, bins[i].max)
+			lcount += bins[i].count
+			if lcount == 0 || bld.rightCount[i+1] == 0 {
+				continue
+			}
+			cost := surfaceArea(lmin, lmax)*float32(lcount) + bld.rightCost[i+1]
+			if cost < bestCost {
+				bestCost, bestAxis, bestBin = cost, axis, i
+			}
+		}
+	}
+
+	if bestAxis < 0 {
+		// Every centroid is in the same place so no split can separate them. Divide the run in
+		// two so that leaves stay small.
+		return start + (end-start)/2
+	}
+
+	scale := float32(nb) / (cmax[bestAxis] - cmin[bestAxis])
+	i, j := start, end-1
+	for i <= j {
+		if bvhBinIndex(bld.boxes[order[i]].centroid[bestAxis], cmin[bestAxis], scale, nb) <= bestBin {
+			i++
+		} else {
+			order[i], order[j] = order[j], order[i]
+			j--
+		}
+	}
+	return i
+}
+
+func bvhBinIndex(v, lo, scale float32, n int) int {
+	bi := int((v - lo) * scale)
+	if bi >= n {
+		bi = n - 1
+	}
+	if bi < 0 {
+		bi = 0
+	}
+	return bi
+}
+
+func emptyBounds() (Point3, Point3) {
+	return Point3{maxFloat32, maxFloat32, maxFloat32}, Point3{-maxFloat32, -maxFloat32, -maxFloat32}
+}
+
+func extendBounds(amin, amax, bmin, bmax Point3) (Point3, Point3) {
+	return Point3{min(amin[0], bmin[0]), min(amin[1], bmin[1]), min(amin[2], bmin[2])},
+		Point3{max(amax[0], bmax[0]), max(amax[1], bmax[1]), max(amax[2], bmax[2])}
+}
+
+// surfaceArea returns half the surface area of the box with the given corners, which is all
+// the surface area heuristic needs to compare boxes.
+func surfaceArea(pmin, pmax Point3) float32 {
+	d := pmax.Sub(pmin)
+	if d[0] < 0 {
+		return 0
+	}
+	return d[0]*d[1] + d[1]*d[2] + d[2]*d[0]
+}
+
+// bvhTraversalCost is the cost of visiting an interior node relative to testing an item, used
+// when estimating the cost of queries.
+const bvhTraversalCost = 1
+
+// Refit updates the bounds of every node from the current bounds of the items without changing
+// the structure of the hierarchy. It is much faster than building a new hierarchy when items
+// move or deform, but the hierarchy becomes less efficient as items move away from the
+// positions it was built for. Use Degradation to decide when to build it again.
+func (b *BVH) Refit() {
+	b.computeBounds(func(it int) (Point3, Point3) {
+		box := b.items[it].Bounds()
+		return box.Position.Sub(box.Size), box.Position.Add(box.Size)
+	})
+}
+
+// computeBounds sets the bounds of every node from the bounds of the items, working up from
+// the leaves.
+func (b *BVH) computeBounds(itemBounds func(item int) (Point3, Point3)) {
+	for i := len(b.nodes) - 1; i >= 0; i-- {
+		n := &b.nodes[i]
+		n.min, n.max = emptyBounds()
+		if n.leaf() {
+			for _, it := range b.order[n.left : n.left+n.count] {
+				imin, imax := itemBounds(it)
+				n.min, n.max = extendBounds(n.min, n.max, imin, imax)
+			}
+			continue
+		}
+		l, r := &b.nodes[n.left], &b.nodes[n.right]
+		n.min, n.max = extendBounds(l.min, l.max, r.min, r.max)
+	}
+}
+
+// Cost estimates the expected cost of a query that passes through the hierarchy's bounds using
+// the surface area heuristic. It is the number of node visits and item tests, each weighted by
+// the probability that a random ray through the root's bounds passes through the node.
+func (b *BVH) Cost() float32 {
+	if len(b.nodes) == 0 {
+		return 0
+	}
+	root := surfaceArea(b.nodes[0].min, b.nodes[0].max)
+	if root == 0 {
+		return float32(b.nodes[0].count)
+	}
+	var cost float32
+	for i := range b.nodes {
+		n := &b.nodes[i]
+		area := surfaceArea(n.min, n.max) / root
+		if n.leaf() {
+			cost += area * float32(n.count)
+		} else {
+			cost += area * bvhTraversalCost
+		}
+	}
+	return cost
+}
+
+// Degradation returns the ratio of the current Cost to the cost when the hierarchy was built.
+// It is 1 for a new hierarchy and grows as refitting stretches the nodes. Rebuilding once it
+// passes a threshold such as 1.5 keeps queries fast.
+func (b *BVH) Degradation() float32 {
+	if b.buildCost == 0 {
+		return 1
+	}
+	return b.Cost() / b.buildCost
+}
+
+// Len returns the number of items in the hierarchy.
+func (b *BVH) Len() int {
+	return len(b.items)
+}
+
+// Item returns the item with the given index.
+func (b *BVH) Item(i int) Bounded {
+	return b.items[i]
+}
+
+// Bounds returns the box that contains every item in the hierarchy.
+func (b *BVH) Bounds() AABB {
+	if len(b.nodes) == 0 {
+		return AABB{}
+	}
+	return b.nodes[0].bounds()
+}
+
+// Query walks the hierarchy, calling descend with the bounds of each node to decide whether to
+// visit its children, and calling fn with the index of each item in the leaves that are
+// reached. The walk stops early if fn returns false. Items are passed to fn without testing
+// their own bounds against descend.
+func (b *BVH) Query(descend func(bounds AABB) bool, fn func(item int) bool) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	var buf [64]int
+	stack := append(buf[:0], 0)
+	for len(stack) > 0 {
+		n := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if !descend(n.bounds()) {
+			continue
+		}
+		if n.leaf() {
+			for _, it := range b.order[n.left : n.left+n.count] {
+				if !fn(it) {
+					return
+				}
+			}
+			continue
+		}
+		stack = append(stack, n.right, n.left)
+	}
+}
+
+// QueryAABB calls fn with the index of each item whose bounds overlap the box. The query stops
+// early if fn returns false.
+func (b *BVH) QueryAABB(box *AABB, fn func(item int) bool) {
+	qmin, qmax := box.Position.Sub(box.Size), box.Position.Add(box.Size)
+	overlaps := func(amin, amax Point3) bool {
+		return amin[0] <= qmax[0] && amax[0] >= qmin[0] &&
+			amin[1] <= qmax[1] && amax[1] >= qmin[1] &&
+			amin[2] <= qmax[2] && amax[2] >= qmin[2]
+	}
+	b.walk(overlaps, fn)
+}
+
+// QueryPoint calls fn with the index of each item whose bounds contain the point. The query
+// stops early if fn returns false.
+func (b *BVH) QueryPoint(p Point3, fn func(item int) bool) {
+	contains := func(amin, amax Point3) bool {
+		return amin[0] <= p[0] && p[0] <= amax[0] &&
+			amin[1] <= p[1] && p[1] <= amax[1] &&
+			amin[2] <= p[2] && p[2] <= amax[2]
+	}
+	b.walk(contains, fn)
+}
+
+// walk is like Query but works on box corners and also tests each item's bounds.
+func (b *BVH) walk(test func(amin, amax Point3) bool, fn func(item int) bool) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	var buf [64]int
+	stack := append(buf[:0], 0)
+	for len(stack) > 0 {
+		n := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if !test(n.min, n.max) {
+			continue
+		}
+		if !n.leaf() {
+			stack = append(stack, n.right, n.left)
+			continue
+		}
+		for _, it := range b.order[n.left : n.left+n.count] {
+			box := b.items[it].Bounds()
+			if test(box.Position.Sub(box.Size), box.Position.Add(box.Size)) && !fn(it) {
+				return
+			}
+		}
+	}
+}
+
+// Raycast returns the nearest item hit by the ray along with the index of the item. Items are
+// tested by calling hit with their index. When hit is nil, items that implement Raycastable
+// are raycast directly and any others are hit where the ray meets their bounds.
+func (b *BVH) Raycast(ray Ray3, hit func(item int) (RaycastResult, bool)) (RaycastResult, int, bool) {
+	p := ray.Prepare()
+	return b.RaycastPrepared(&p, hit)
+}
+
+// RaycastPrepared is like Raycast but takes a prepared ray.
+func (b *BVH) RaycastPrepared(ray *PreparedRay3, hit func(item int) (RaycastResult, bool)) (RaycastResult, int, bool) {
+	var best RaycastResult
+	best.Fail = RaycastFailOutsideBounds
+	bestItem := -1
+	if len(b.nodes) == 0 {
+		return best, bestItem, false
+	}
+	if hit == nil {
+		hit = func(it int) (RaycastResult, bool) {
+			return b.raycastItem(ray, it)
+		}
+	}
+
+	limit := float32(maxFloat32)
+
+	type entry struct {
+		node int
+		t    float32
+	}
+	var buf [64]entry
+	stack := buf[:0]
+	if t, ok := ray.distanceToBox(b.nodes[0].min, b.nodes[0].max, limit); ok {
+		stack = append(stack, entry{0, t})
+	}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.t > limit {
+			continue
+		}
+		n := &b.nodes[e.node]
+		if n.leaf() {
+			for _, it := range b.order[n.left : n.left+n.count] {
+				res, ok := hit(it)
+				if ok && res.Distance <= limit {
+					best, bestItem, limit = res, it, res.Distance
+				}
+			}
+			continue
+		}
+
+		// Visit the nearer child first so that hits in it can prune the further one
+		tl, okl := ray.distanceToBox(b.nodes[n.left].min, b.nodes[n.left].max, limit)
+		tr, okr := ray.distanceToBox(b.nodes[n.right].min, b.nodes[n.right].max, limit)
+		switch {
+		case okl && okr:
+			if tl <= tr {
+				stack = append(stack, entry{n.right, tr}, entry{n.left, tl})
+			} else {
+				stack = append(stack, entry{n.left, tl}, entry{n.right, tr})
+			}
+		case okl:
+			stack = append(stack, entry{n.left, tl})
+		case okr:
+			stack = append(stack, entry{n.right, tr})
+		}
+	}
+
+	return best, bestItem, bestItem >= 0
+}
+
+// raycastItem raycasts the item directly if it implements Raycastable or otherwise against its
+// bounds. The item is recorded as the shape that was hit unless it reports a part of itself.
+func (b *BVH) raycastItem(ray *PreparedRay3, it int) (RaycastResult, bool) {
+	var res RaycastResult
+	var ok bool
+	switch item := b.items[it].(type) {
+	case *AABB:
+		res, ok = item.RaycastPrepared(ray)
+	case Raycastable:
+		res, ok = item.Raycast(ray.Ray3)
+	default:
+		bounds := item.Bounds()
+		res, ok = bounds.RaycastPrepared(ray)
+	}
+	if ok && res.Shape == nil {
+		res.Shape = b.items[it]
+	}
+	return res, ok
+}
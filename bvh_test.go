@@ -0,0 +1,199 @@
+package geom
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomSpheres(r *rand.Rand, n int) []Bounded {
+	items := make([]Bounded, n)
+	for i := range items {
+		items[i] = &Sphere{
+			Position: RandomPointInAABB(r, AABB{Size: Vec3{50, 50, 50}}),
+			Radius:   0.5 + r.Float32()*2,
+		}
+	}
+	return items
+}
+
+func TestBVHQueryAABB(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	items := randomSpheres(r, 500)
+	b := BuildBVH(items, BVHOptions{})
+
+	if b.Len() != len(items) {
+		t.Fatalf("Len: got %d, wanted %d", b.Len(), len(items))
+	}
+
+	for q := 0; q < 50; q++ {
+		box := AABB{Position: RandomPointInAABB(r, AABB{Size: Vec3{50, 50, 50}}), Size: Vec3{5, 8, 3}}
+
+		var got []int
+		b.QueryAABB(&box, func(item int) bool {
+			got = append(got, item)
+			return true
+		})
+		sort.Ints(got)
+
+		var want []int
+		for i, it := range items {
+			bounds := it.Bounds()
+			if bounds.IntersectsAABB(&box) {
+				want = append(want, i)
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("query %v: got %v, wanted %v", box, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("query %v: got %v, wanted %v", box, got, want)
+			}
+		}
+	}
+}
+
+func TestBVHQueryPoint(t *testing.T) {
+	items := []Bounded{
+		&AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}},
+		&AABB{Position: Point3{1, 0, 0}, Size: Vec3{1, 1, 1}},
+		&AABB{Position: Point3{5, 0, 0}, Size: Vec3{1, 1, 1}},
+	}
+	b := BuildBVH(items, BVHOptions{MaxLeafSize: 1})
+
+	var got []int
+	b.QueryPoint(Point3{0.5, 0, 0}, func(item int) bool {
+		got = append(got, item)
+		return true
+	})
+	sort.Ints(got)
+	if len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("got %v, wanted [0 1]", got)
+	}
+
+	// Returning false stops the query
+	calls := 0
+	b.QueryPoint(Point3{0.5, 0, 0}, func(item int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("got %d calls after stopping, wanted 1", calls)
+	}
+}
+
+func TestBVHRaycast(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	items := randomSpheres(r, 300)
+	b := BuildBVH(items, BVHOptions{})
+
+	for q := 0; q < 100; q++ {
+		ray := Ray3{
+			Origin:    RandomPointOnSphere(r, Sphere{Radius: 100}),
+			Direction: RandomPointInAABB(r, AABB{Size: Vec3{20, 20, 20}}),
+		}
+		ray.Direction = ray.Direction.Sub(ray.Origin).Normalize()
+
+		got, item, ok := b.Raycast(ray, nil)
+
+		want := float32(maxFloat32)
+		wantItem := -1
+		for i, it := range items {
+			if res, hit := it.(Raycastable).Raycast(ray); hit && res.Distance < want {
+				want, wantItem = res.Distance, i
+			}
+		}
+		if ok != (wantItem >= 0) || item != wantItem {
+			t.Fatalf("ray %v: got item %d, %v, wanted %d", ray, item, ok, wantItem)
+		}
+		if ok && got.Distance != want {
+			t.Fatalf("ray %v: got distance %v, wanted %v", ray, got.Distance, want)
+		}
+	}
+}
+
+func TestBVHDegenerate(t *testing.T) {
+	b := BuildBVH(nil, BVHOptions{})
+	if _, _, ok := b.Raycast(Ray3{Direction: Vec3{1, 0, 0}}, nil); ok {
+		t.Errorf("empty hierarchy should not be hit")
+	}
+	b.QueryPoint(Point3{}, func(int) bool {
+		t.Errorf("empty hierarchy should not report items")
+		return true
+	})
+
+	// Items that share a centroid still produce small leaves
+	items := make([]Bounded, 100)
+	for i := range items {
+		items[i] = &Sphere{Radius: float32(i + 1)}
+	}
+	b = BuildBVH(items, BVHOptions{MaxLeafSize: 2})
+	for _, n := range b.nodes {
+		if n.count > 2 {
+			t.Fatalf("got leaf with %d items, wanted at most 2", n.count)
+		}
+	}
+	count := 0
+	b.QueryPoint(Point3{}, func(int) bool {
+		count++
+		return true
+	})
+	if count != len(items) {
+		t.Errorf("got %d items, wanted %d", count, len(items))
+	}
+}
+
+func BenchmarkBuildBVH(b *testing.B) {
+	items := randomSpheres(rand.New(rand.NewSource(1)), 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildBVH(items, BVHOptions{})
+	}
+}
+
+func TestBVHRefit(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	items := randomSpheres(r, 400)
+	b := BuildBVH(items, BVHOptions{})
+
+	if got := b.Degradation(); got != 1 {
+		t.Errorf("Degradation after build: got %v, wanted 1", got)
+	}
+
+	// Nudging every item keeps the hierarchy close to its original quality
+	for _, it := range items {
+		s := it.(*Sphere)
+		s.Position = s.Position.Add(Vec3{0.1, -0.1, 0.05})
+	}
+	b.Refit()
+	checkBVH(t, b, 4)
+	if got := b.Degradation(); got > 1.1 {
+		t.Errorf("Degradation after small moves: got %v, wanted close to 1", got)
+	}
+
+	// Scattering the items makes every node span most of the space
+	for _, it := range items {
+		it.(*Sphere).Position = RandomPointInAABB(r, AABB{Size: Vec3{50, 50, 50}})
+	}
+	b.Refit()
+	checkBVH(t, b, 4)
+	if got := b.Degradation(); got < 2 {
+		t.Errorf("Degradation after scattering: got %v, wanted at least 2", got)
+	}
+
+	p := items[7].(*Sphere).Position
+	found := false
+	b.QueryPoint(p, func(item int) bool {
+		found = found || item == 7
+		return true
+	})
+	if !found {
+		t.Errorf("QueryPoint after Refit did not report the moved item")
+	}
+
+	if got := BuildBVH(items, BVHOptions{}).Cost(); got >= b.Cost() {
+		t.Errorf("Cost of rebuilt hierarchy: got %v, wanted less than refitted %v", got, b.Cost())
+	}
+}
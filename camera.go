@@ -0,0 +1,225 @@
+package geom
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Projection is the kind of projection used by a camera.
+type Projection int
+
+const (
+	// Perspective projection makes distant objects appear smaller. This is the default.
+	Perspective Projection = iota
+
+	// Orthographic projection preserves the size of objects regardless of distance.
+	Orthographic
+)
+
+func (p Projection) String() string {
+	switch p {
+	case Perspective:
+		return "perspective"
+	case Orthographic:
+		return "orthographic"
+	default:
+		return "unknown"
+	}
+}
+
+// Camera describes a viewpoint and the projection used to render the scene from it. The camera
+// looks along the Front of its transform with the Top of the transform pointing up the screen.
+type Camera struct {
+	Transform  Transform
+	Projection Projection
+	FOV        float32 // vertical field of view in radians, used by perspective projection
+	OrthoSize  float32 // half of the height of the view volume, used by orthographic projection
+	Aspect     float32 // width of the view divided by its height
+	Near       float32 // distance to the near clipping plane
+	Far        float32 // distance to the far clipping plane
+}
+
+// NewCamera returns a perspective camera at the origin with a 60 degree field of view.
+func NewCamera() Camera {
+	return Camera{
+		Transform: NewTransform(),
+		FOV:       pi / 3,
+		OrthoSize: 1,
+		Aspect:    1,
+		Near:      0.1,
+		Far:       1000,
+	}
+}
+
+// ViewMatrix returns the matrix that converts points from world space into the camera's view
+// space. View space follows the OpenGL convention where the camera looks along negative Z.
+func (c *Camera) ViewMatrix() Mat4 {
+	return ViewMatrix(&c.Transform)
+}
+
+// ViewMatrix returns the view matrix of a camera placed by tx, which converts points from world
+// space into the camera's view space. It is the inverse of the model matrix of tx ignoring its
+// scale. View space follows the OpenGL convention where the camera looks along negative Z, so
+// for a transform using the ForwardPositiveZ convention the view is also turned about Y to
+// face its Front.
+func ViewMatrix(tx *Transform) Mat4 {
+	inv := tx.orientation.Conjugate()
+	if tx.convention == ForwardPositiveZ {
+		// A half turn about Y
+		inv = Quat{V: Vec3{0, 1, 0}}.Mul(inv)
+	}
+	m := inv.Mat4()
+	pos := inv.Rotate(tx.position.Mul(-1))
+	m[12], m[13], m[14] = pos[0], pos[1], pos[2]
+	return m
+}
+
+// ProjectionMatrix returns the matrix that converts points from view space into clip space.
+func (c *Camera) ProjectionMatrix() Mat4 {
+	if c.Projection == Orthographic {
+		h := c.OrthoSize
+		w := h * c.Aspect
+		return mgl32.Ortho(-w, w, -h, h, c.Near, c.Far)
+	}
+	return mgl32.Perspective(c.FOV, c.Aspect, c.Near, c.Far)
+}
+
+// ViewProjectionMatrix returns the matrix that converts points from world space into clip
+// space.
+func (c *Camera) ViewProjectionMatrix() Mat4 {
+	return c.ProjectionMatrix().Mul4(c.ViewMatrix())
+}
+
+// Frustum returns the volume visible to the camera in world space.
+func (c *Camera) Frustum() Frustum {
+	return FrustumFromMatrix(c.ViewProjectionMatrix())
+}
+
+// ScreenPointToRay returns the ray in world space that passes through the screen position x,
+// y within the viewport. Screen positions are measured in pixels with y increasing down the
+// screen. The ray starts on the near clipping plane.
+func (c *Camera) ScreenPointToRay(x, y float32, viewport Recti) Ray3 {
+	vp := c.ViewProjectionMatrix()
+	near, _ := Unproject(Point3{x, y, 0}, vp, viewport)
+	far, _ := Unproject(Point3{x, y, 1}, vp, viewport)
+	return Ray3FromPoints(near, far)
+}
+
+// Project converts p into screen space using the combined model, view and projection matrix
+// mvp. The x and y components of the result are measured in pixels within the viewport with
+// y increasing down the screen. The z component holds the depth, ranging from 0 on the near
+// plane to 1 on the far plane. It returns false if p is behind the viewer, in which case the
+// screen position is meaningless.
+func Project(p Point3, mvp Mat4, viewport Recti) (Point3, bool) {
+	clip := mvp.Mul4x1(p.Vec4(1))
+	if clip[3] <= 0 {
+		return Point3{}, false
+	}
+	ndc := clip.Vec3().Mul(1 / clip[3])
+
+	tl := viewport.TopLeft()
+	return Point3{
+		float32(tl[0]) + (ndc[0]+1)/2*float32(viewport.Width()),
+		float32(tl[1]) + (1-ndc[1])/2*float32(viewport.Height()),
+		(ndc[2] + 1) / 2,
+	}, true
+}
+
+// Unproject converts the screen space point s back into world space, reversing Project. It
+// returns false if mvp cannot be inverted.
+func Unproject(s Point3, mvp Mat4, viewport Recti) (Point3, bool) {
+	if mvp.Det() == 0 {
+		return Point3{}, false
+	}
+
+	tl := viewport.TopLeft()
+	ndc := Vec4{
+		2*(s[0]-float32(tl[0]))/float32(viewport.Width()) - 1,
+		1 - 2*(s[1]-float32(tl[1]))/float32(viewport.Height()),
+		2*s[2] - 1,
+		1,
+	}
+
+	p := mvp.Inv().Mul4x1(ndc)
+	if p[3] == 0 {
+		return Point3{}, false
+	}
+	return p.Vec3().Mul(1 / p[3]), true
+}
+
+// ProjectAABB returns the screen space rectangle that bounds the box a after projecting it
+// with mvp. It returns false if the box is entirely behind the viewer. A box that crosses the
+// plane of the viewer cannot be bounded reliably so it is given the bounds of the whole
+// viewport. The result is not clipped to the viewport.
+func ProjectAABB(a *AABB, mvp Mat4, viewport Recti) (Rect, bool) {
+	pmin := Point2{maxFloat32, maxFloat32}
+	pmax := Point2{-maxFloat32, -maxFloat32}
+
+	behind := 0
+	corners := a.cornerArray()
+	for _, c := range corners {
+		s, ok := Project(c, mvp, viewport)
+		if !ok {
+			behind++
+			continue
+		}
+		pmin = Point2{min(pmin[0], s[0]), min(pmin[1], s[1])}
+		pmax = Point2{max(pmax[0], s[0]), max(pmax[1], s[1])}
+	}
+
+	switch behind {
+	case 0:
+		return RectFromCorners(pmin, pmax), true
+	case len(corners):
+		return Rect{}, false
+	default:
+		tl, br := viewport.TopLeft(), viewport.BottomRight()
+		return RectFromCorners(Point2{float32(tl[0]), float32(tl[1])}, Point2{float32(br[0]), float32(br[1])}), true
+	}
+}
+
+// Orbit returns the transform of a camera that orbits centre at the given distance and looks
+// toward it. yaw turns the camera about the Y axis and pitch tilts it about its own left axis,
+// both in radians. With both zero the camera sits on the negative Z side of centre looking
+// along positive Z, and a positive pitch raises the camera so that it looks down. The
+// transform uses the ForwardPositiveZ convention.
+func Orbit(centre Point3, yaw, pitch, distance float32) Transform {
+	q := mgl32.QuatRotate(yaw, Y3).Mul(mgl32.QuatRotate(pitch, X3))
+	t := NewTransform()
+	t.SetOrientation(q)
+	t.SetPosition(centre.Sub(q.Rotate(Z3).Mul(distance)))
+	return t
+}
+
+// ArcballRotation returns the rotation produced by dragging the pointer from the screen
+// position from to the screen position to, following Shoemake's arcball. Screen positions
+// are measured in pixels within the viewport with y increasing down the screen. The drag turns
+// a sphere that fills the shorter side of the viewport, so a drag from the centre to the edge
+// of the sphere is a quarter turn. Positions outside the sphere turn it about the view axis.
+//
+// The rotation is in view space, where x points right, y points up and z points out of the
+// screen toward the viewer. Use Camera.ArcballRotation for the rotation in world space.
+func ArcballRotation(from, to Vec2, viewport Recti) Quat {
+	a, b := arcballPoint(from, viewport), arcballPoint(to, viewport)
+	return mgl32.QuatBetweenVectors(a, b).Normalize()
+}
+
+// arcballPoint returns the point on the unit arcball sphere under the screen position p.
+func arcballPoint(p Vec2, viewport Recti) Vec3 {
+	radius := float32(mini(viewport.Width(), viewport.Height())) / 2
+	if radius <= 0 {
+		return Z3
+	}
+	x := (p[0] - float32(viewport.Position[0])) / radius
+	y := (float32(viewport.Position[1]) - p[1]) / radius
+	if d := x*x + y*y; d < 1 {
+		return Vec3{x, y, sqrt(1 - d)}
+	}
+	return Vec3{x, y, 0}.Normalize()
+}
+
+// ArcballRotation returns the world space rotation produced by dragging the pointer from the
+// screen position from to the screen position to, as seen by the camera. It is the rotation
+// returned by the ArcballRotation function expressed in world space, ready to be applied to an
+// object with Transform.Rotate.
+func (c *Camera) ArcballRotation(from, to Vec2, viewport Recti) Quat {
+	view := mgl32.Mat4ToQuat(c.ViewMatrix()).Normalize()
+	return view.Conjugate().Mul(ArcballRotation(from, to, viewport)).Mul(view).Normalize()
+}
@@ -0,0 +1,373 @@
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCameraScreenPointToRay(t *testing.T) {
+	c := NewCamera()
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	viewport := RectiFromCorners(Point2i{0, 0}, Point2i{800, 800})
+
+	tan := float32(math.Tan(pi / 6))
+
+	testCases := []struct {
+		name string
+		x, y float32
+		dir  Vec3
+	}{
+		{name: "centre", x: 400, y: 400, dir: Vec3{0, 0, 1}},
+		{name: "top left", x: 0, y: 0, dir: Vec3{tan, tan, 1}.Normalize()},
+		{name: "bottom right", x: 800, y: 800, dir: Vec3{-tan, -tan, 1}.Normalize()},
+		{name: "top centre", x: 400, y: 0, dir: Vec3{0, tan, 1}.Normalize()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := c.ScreenPointToRay(tc.x, tc.y, viewport)
+			if !nearVec3(r.Direction, tc.dir, 1e-4) {
+				t.Errorf("got direction %v, wanted %v", r.Direction, tc.dir)
+			}
+			// The ray starts on the near plane
+			if d := r.Origin.Sub(c.Transform.Pos()).Dot(c.Transform.Front()); abs(d-c.Near) > 1e-4 {
+				t.Errorf("got origin %v at depth %v, wanted depth %v", r.Origin, d, c.Near)
+			}
+		})
+	}
+}
+
+func TestCameraOrthographic(t *testing.T) {
+	c := NewCamera()
+	c.Projection = Orthographic
+	c.OrthoSize = 5
+	c.Aspect = 2
+	viewport := RectiFromCorners(Point2i{0, 0}, Point2i{400, 200})
+
+	r := c.ScreenPointToRay(0, 0, viewport)
+	if want := (Vec3{0, 0, 1}); !nearVec3(r.Direction, want, 1e-5) {
+		t.Errorf("got direction %v, wanted %v", r.Direction, want)
+	}
+	if want := (Point3{10, 5, c.Near}); !nearVec3(r.Origin, want, 1e-4) {
+		t.Errorf("got origin %v, wanted %v", r.Origin, want)
+	}
+}
+
+func TestFrustum(t *testing.T) {
+	c := NewCamera()
+	c.Far = 100
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	f := c.Frustum()
+
+	points := []struct {
+		p    Point3
+		want bool
+	}{
+		{p: Point3{0, 0, 0}, want: true},
+		{p: Point3{0, 0, -20}, want: false},
+		{p: Point3{0, 0, 95}, want: false},
+		{p: Point3{4, 4, 0}, want: true},
+		{p: Point3{7, 0, 0}, want: false},
+		{p: Point3{0, -7, 0}, want: false},
+	}
+	for _, tc := range points {
+		if got := f.ContainsPoint3(tc.p); got != tc.want {
+			t.Errorf("ContainsPoint3(%v): got %v, wanted %v", tc.p, got, tc.want)
+		}
+	}
+
+	spheres := []struct {
+		s    Sphere
+		want bool
+	}{
+		{s: Sphere{Position: Point3{0, 0, 0}, Radius: 1}, want: true},
+		{s: Sphere{Position: Point3{7, 0, 0}, Radius: 2}, want: true},
+		{s: Sphere{Position: Point3{9, 0, 0}, Radius: 1}, want: false},
+		{s: Sphere{Position: Point3{0, 0, -15}, Radius: 1}, want: false},
+	}
+	for _, tc := range spheres {
+		if got := f.IntersectsSphere(&tc.s); got != tc.want {
+			t.Errorf("IntersectsSphere(%v): got %v, wanted %v", tc.s, got, tc.want)
+		}
+	}
+
+	boxes := []struct {
+		a    AABB
+		want bool
+	}{
+		{a: AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}}, want: true},
+		{a: AABB{Position: Point3{7, 0, 0}, Size: Vec3{2, 2, 2}}, want: true},
+		{a: AABB{Position: Point3{9, 0, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		{a: AABB{Position: Point3{0, 0, 200}, Size: Vec3{50, 50, 50}}, want: false},
+	}
+	for _, tc := range boxes {
+		if got := f.IntersectsAABB(&tc.a); got != tc.want {
+			t.Errorf("IntersectsAABB(%v): got %v, wanted %v", tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestProjectUnproject(t *testing.T) {
+	c := NewCamera()
+	c.Aspect = 2
+	c.Transform.SetPosition(Vec3{1, 2, -10})
+	c.Transform.SetAngleAbout(Y3, 0.3)
+	vp := c.ViewProjectionMatrix()
+	viewport := RectiFromCorners(Point2i{100, 50}, Point2i{900, 450})
+
+	points := []Point3{
+		{0, 0, 0},
+		{3, -1, 5},
+		{-2, 4, 20},
+	}
+
+	for _, p := range points {
+		s, ok := Project(p, vp, viewport)
+		if !ok {
+			t.Fatalf("Project(%v): point should be in front of camera", p)
+		}
+		back, ok := Unproject(s, vp, viewport)
+		if !ok || !nearVec3(back, p, 1e-2) {
+			t.Errorf("Unproject(Project(%v)): got %v", p, back)
+		}
+
+		// The ray through the projected point passes through the original point
+		r := c.ScreenPointToRay(s[0], s[1], viewport)
+		if d := DistanceSquared3(r.ClosestPoint(p), p); d > 1e-3 {
+			t.Errorf("ray through %v misses %v by %v", s, p, sqrt(d))
+		}
+	}
+
+	centre, _ := Project(c.Transform.Pos().Add(c.Transform.Front().Mul(5)), vp, viewport)
+	if abs(centre[0]-500) > 1e-3 || abs(centre[1]-250) > 1e-3 {
+		t.Errorf("point ahead of camera: got %v, wanted centre of viewport", centre)
+	}
+
+	if _, ok := Project(c.Transform.Pos().Sub(c.Transform.Front()), vp, viewport); ok {
+		t.Errorf("point behind camera should not project")
+	}
+}
+
+func TestProjectAABB(t *testing.T) {
+	c := NewCamera()
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	vp := c.ViewProjectionMatrix()
+	viewport := RectiFromCorners(Point2i{0, 0}, Point2i{800, 800})
+
+	a := AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}}
+	r, ok := ProjectAABB(&a, vp, viewport)
+	if !ok {
+		t.Fatalf("box in front of camera should project")
+	}
+	if r.Position.Sub(Point2{400, 400}).Len() > 1e-3 {
+		t.Errorf("got centre %v, wanted %v", r.Position, Point2{400, 400})
+	}
+	// The nearest face is 9 units away and half of the view height at that distance is 9*tan(30)
+	want := 400 / (9 * float32(math.Tan(pi/6)))
+	if abs(r.Size[0]-want) > 1e-2 || abs(r.Size[1]-want) > 1e-2 {
+		t.Errorf("got half size %v, wanted %v", r.Size, want)
+	}
+
+	behind := AABB{Position: Point3{0, 0, -20}, Size: Vec3{1, 1, 1}}
+	if _, ok := ProjectAABB(&behind, vp, viewport); ok {
+		t.Errorf("box behind camera should not project")
+	}
+
+	straddle := AABB{Position: Point3{0, 0, -10}, Size: Vec3{1, 1, 1}}
+	if r, ok := ProjectAABB(&straddle, vp, viewport); !ok || r.Size != (Vec2{400, 400}) {
+		t.Errorf("box around camera: got %v, %v, wanted whole viewport", r, ok)
+	}
+}
+
+func TestFrustumCorners(t *testing.T) {
+	c := NewCamera()
+	c.Near = 1
+	c.Far = 11
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	f := c.Frustum()
+
+	tan := float32(math.Tan(pi / 6))
+	corners := f.Corners()
+	want := [8]Point3{
+		{tan, -tan, -9}, {-tan, -tan, -9}, {-tan, tan, -9}, {tan, tan, -9},
+		{11 * tan, -11 * tan, 1}, {-11 * tan, -11 * tan, 1}, {-11 * tan, 11 * tan, 1}, {11 * tan, 11 * tan, 1},
+	}
+	for i := range corners {
+		if !nearVec3(corners[i], want[i], 1e-3) {
+			t.Errorf("corner %d: got %v, wanted %v", i, corners[i], want[i])
+		}
+	}
+
+	b := f.Bounds()
+	if wantb := AABBFromCorners(Point3{-11 * tan, -11 * tan, -9}, Point3{11 * tan, 11 * tan, 1}); !nearVec3(b.Position, wantb.Position, 1e-3) || !nearVec3(b.Size, wantb.Size, 1e-3) {
+		t.Errorf("Bounds: got %v, wanted %v", b, wantb)
+	}
+
+	ob := f.OrientedBounds()
+	if !nearVec3(ob.Position, Point3{0, 0, -4}, 1e-3) || !nearVec3(ob.Size, Vec3{11 * tan, 11 * tan, 5}, 1e-3) {
+		t.Errorf("OrientedBounds: got %v", ob)
+	}
+
+	// Slice from 2 to 4 units beyond the near plane
+	s := f.Split(2, 4)
+	sc := s.Corners()
+	if want := (Point3{3 * tan, -3 * tan, -7}); !nearVec3(sc[0], want, 1e-3) {
+		t.Errorf("split near corner: got %v, wanted %v", sc[0], want)
+	}
+	if want := (Point3{5 * tan, 5 * tan, -5}); !nearVec3(sc[7], want, 1e-3) {
+		t.Errorf("split far corner: got %v, wanted %v", sc[7], want)
+	}
+	if s.ContainsPoint3(Point3{0, 0, -8}) || !s.ContainsPoint3(Point3{0, 0, -6}) || s.ContainsPoint3(Point3{0, 0, -4}) {
+		t.Errorf("split contains wrong points")
+	}
+}
+
+func TestFrustumOrientedBounds(t *testing.T) {
+	// A camera looking diagonally down has oriented bounds that fit closer than its AABB
+	c := NewCamera()
+	c.Near = 1
+	c.Far = 20
+	c.Transform.SetPosition(Vec3{3, 10, -4})
+	c.Transform.SetOrientation(LookAtQuat(Point3{3, 10, -4}, Point3{10, 0, 5}, Y3))
+	f := c.Frustum()
+
+	ob := f.OrientedBounds()
+	for i, p := range f.Corners() {
+		if local := ob.Orientation.Inverse().Rotate(p.Sub(ob.Position)); abs(local[0]) > ob.Size[0]+1e-3 || abs(local[1]) > ob.Size[1]+1e-3 || abs(local[2]) > ob.Size[2]+1e-3 {
+			t.Errorf("corner %d at %v is outside %v", i, p, ob)
+		}
+	}
+	if front := ob.Orientation.Rotate(Z3); abs(abs(front.Dot(f.Planes[FrustumNear].Normal))-1) > 1e-4 {
+		t.Errorf("box is not aligned with the view direction: %v", front)
+	}
+	b := f.Bounds()
+	if ob.Volume() >= b.Volume() {
+		t.Errorf("oriented bounds volume %v is not less than AABB volume %v", ob.Volume(), b.Volume())
+	}
+}
+
+func TestOrbit(t *testing.T) {
+	centre := Point3{1, 2, 3}
+	testCases := []struct {
+		name       string
+		yaw, pitch float32
+		pos        Point3
+	}{
+		{name: "default", pos: Point3{1, 2, -7}},
+		{name: "yaw", yaw: pi / 2, pos: Point3{-9, 2, 3}},
+		{name: "pitch", pitch: pi / 2, pos: Point3{1, 12, 3}},
+		{name: "both", yaw: pi, pitch: pi / 4, pos: Point3{1, 2 + 10/sqrt(2), 3 + 10/sqrt(2)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCamera()
+			c.Transform = Orbit(centre, tc.yaw, tc.pitch, 10)
+			if got := c.Transform.Pos(); !nearVec3(got, tc.pos, 1e-4) {
+				t.Errorf("got position %v, wanted %v", got, tc.pos)
+			}
+			// The centre is in the middle of the screen
+			viewport := RectiFromCorners(Point2i{0, 0}, Point2i{200, 100})
+			if s, ok := Project(centre, c.ViewProjectionMatrix(), viewport); !ok || abs(s[0]-100) > 1e-3 || abs(s[1]-50) > 1e-3 {
+				t.Errorf("centre projects to %v, wanted the middle of the screen", s)
+			}
+			// The camera does not roll
+			if r := c.Transform.Right(); abs(r[1]) > 1e-5 {
+				t.Errorf("got right vector %v, wanted it horizontal", r)
+			}
+		})
+	}
+}
+
+func TestArcballRotation(t *testing.T) {
+	viewport := RectiFromCorners(Point2i{0, 0}, Point2i{400, 200})
+	testCases := []struct {
+		name     string
+		from, to Vec2
+		want     Quat
+	}{
+		{name: "none", from: Vec2{250, 80}, to: Vec2{250, 80}, want: mgl32.QuatIdent()},
+		{name: "right", from: Vec2{200, 100}, to: Vec2{300, 100}, want: mgl32.QuatRotate(pi/2, Y3)},
+		{name: "up", from: Vec2{200, 100}, to: Vec2{200, 50}, want: mgl32.QuatRotate(pi/6, X3.Mul(-1))},
+		{name: "around the rim", from: Vec2{350, 100}, to: Vec2{200, 0}, want: mgl32.QuatRotate(pi/2, Z3)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ArcballRotation(tc.from, tc.to, viewport); !got.OrientationEqualThreshold(tc.want, 1e-4) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+
+	// Seen from a camera looking along positive Z, a drag to the right turns the front of an
+	// object toward the right of the screen, which is negative X
+	c := NewCamera()
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	q := c.ArcballRotation(Vec2{200, 100}, Vec2{300, 100}, viewport)
+	if got := q.Rotate(Vec3{0, 0, -1}); !nearVec3(got, Vec3{-1, 0, 0}, 1e-4) {
+		t.Errorf("camera: rotated the front to %v, wanted %v", got, Vec3{-1, 0, 0})
+	}
+}
+
+func TestViewMatrix(t *testing.T) {
+	for _, conv := range []AxisConvention{ForwardPositiveZ, ForwardNegativeZ} {
+		t.Run(conv.String(), func(t *testing.T) {
+			tx := NewTransform()
+			tx.SetConvention(conv)
+			tx.SetPosition(Vec3{3, -1, 4})
+			tx.SetOrientation(mgl32.QuatRotate(0.7, Vec3{1, 2, -1}.Normalize()))
+			tx.SetScaleUniform(3)
+
+			eye := tx.Pos()
+			want := mgl32.LookAtV(eye, eye.Add(tx.Front()), tx.Top())
+			if got := ViewMatrix(&tx); !got.ApproxEqualThreshold(want, 1e-5) {
+				t.Errorf("got %v, wanted %v", got, want)
+			}
+
+			// The camera position maps to the origin and its front to negative Z
+			v := ViewMatrix(&tx)
+			if got := v.Mul4x1(eye.Vec4(1)).Vec3(); !nearVec3(got, Vec3{}, 1e-5) {
+				t.Errorf("eye maps to %v, wanted the origin", got)
+			}
+			if got := v.Mul4x1(tx.Front().Vec4(0)).Vec3(); !nearVec3(got, Vec3{0, 0, -1}, 1e-5) {
+				t.Errorf("front maps to %v, wanted %v", got, Vec3{0, 0, -1})
+			}
+		})
+	}
+}
+
+func TestLookAtQuat(t *testing.T) {
+	testCases := []struct {
+		name            string
+		eye, target, up Vec3
+		front           Vec3
+	}{
+		{name: "along z", eye: Vec3{0, 0, 0}, target: Vec3{0, 0, 5}, up: Y3, front: Z3},
+		{name: "diagonal", eye: Vec3{1, 1, 1}, target: Vec3{4, 5, 1}, up: Y3, front: Vec3{3, 4, 0}.Normalize()},
+		{name: "up parallel", eye: Vec3{0, 0, 0}, target: Vec3{0, -3, 0}, up: Y3, front: Vec3{0, -1, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := LookAtQuat(tc.eye, tc.target, tc.up)
+			if got := q.Rotate(Z3); !nearVec3(got, tc.front, 1e-5) {
+				t.Errorf("got front %v, wanted %v", got, tc.front)
+			}
+			top := q.Rotate(Y3)
+			if abs(top.Dot(tc.front)) > 1e-5 {
+				t.Errorf("top %v is not perpendicular to front %v", top, tc.front)
+			}
+			// The top is toward up unless up is parallel to the view direction
+			if abs(tc.up.Dot(tc.front)) < 0.99 && (top.Dot(tc.up) <= 0 || abs(top.Cross(tc.front).Dot(tc.up)) > 1e-5) {
+				t.Errorf("top %v is not in the plane of front and up", top)
+			}
+		})
+	}
+
+	if q := LookAtQuat(Vec3{1, 2, 3}, Vec3{1, 2, 3}, Y3); q != mgl32.QuatIdent() {
+		t.Errorf("same point: got %v, wanted the identity", q)
+	}
+}
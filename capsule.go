@@ -0,0 +1,162 @@
+package geom
+
+var _ Projecter = (*Capsule)(nil)
+
+// Capsule is a line segment that has been expanded by a radius in all directions, forming a
+// cylinder with hemispherical caps.
+type Capsule struct {
+	Start  Point3 // centre of the first cap
+	End    Point3 // centre of the second cap
+	Radius float32
+}
+
+// Line returns the line segment that runs through the centre of the capsule.
+func (c *Capsule) Line() Line3 {
+	return Line3{Start: c.Start, End: c.End}
+}
+
+// Bounds returns the smallest AABB that contains the capsule.
+func (c *Capsule) Bounds() AABB {
+	r := Vec3{c.Radius, c.Radius, c.Radius}
+	pmin := Point3{min(c.Start[0], c.End[0]), min(c.Start[1], c.End[1]), min(c.Start[2], c.End[2])}
+	pmax := Point3{max(c.Start[0], c.End[0]), max(c.Start[1], c.End[1]), max(c.Start[2], c.End[2])}
+	return AABBFromCorners(pmin.Sub(r), pmax.Add(r))
+}
+
+// support returns the point of the capsule that lies furthest in the direction d.
+func (c *Capsule) support(d Vec3) Point3 {
+	end := c.Start
+	if d.Dot(c.End) > d.Dot(c.Start) {
+		end = c.End
+	}
+	l := d.Len()
+	if l == 0 {
+		return end
+	}
+	return end.Add(d.Mul(c.Radius / l))
+}
+
+// Center returns the point midway between the centres of the caps.
+func (c *Capsule) Center() Point3 {
+	return c.Start.Add(c.End).Mul(0.5)
+}
+
+// Volume returns the volume of the capsule.
+func (c *Capsule) Volume() float32 {
+	h := c.End.Sub(c.Start).Len()
+	return pi * c.Radius * c.Radius * (h + 4*c.Radius/3)
+}
+
+// SurfaceArea returns the area of the capsule's surface.
+func (c *Capsule) SurfaceArea() float32 {
+	h := c.End.Sub(c.Start).Len()
+	return 2 * pi * c.Radius * (h + 2*c.Radius)
+}
+
+// ClosestPoint returns the point on the surface of the capsule that is closest to point
+func (c *Capsule) ClosestPoint(point Point3) Point3 {
+	l := c.Line()
+	centre := l.ClosestPoint(point)
+	s := Sphere{Position: centre, Radius: c.Radius}
+	return s.ClosestPoint(point)
+}
+
+// ContainsPoint3 reports whether the point lies in the capsule.
+func (c *Capsule) ContainsPoint3(point Point3) bool {
+	l := c.Line()
+	return DistanceSquared3(l.ClosestPoint(point), point) < c.Radius*c.Radius
+}
+
+// ProjectOntoAxis returns the interval covered by the capsule when projected onto the axis.
+func (c *Capsule) ProjectOntoAxis(axis Vec3) Interval {
+	a, b := c.Start.Dot(axis), c.End.Dot(axis)
+	r := c.Radius * axis.Len()
+	return Interval{Min: min(a, b) - r, Max: max(a, b) + r}
+}
+
+// Raycast tests whether the ray hits the surface of the capsule. A ray that starts inside the
+// capsule hits the surface on its way out.
+func (c *Capsule) Raycast(ray Ray3) (RaycastResult, bool) {
+	return c.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options.
+func (c *Capsule) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	var res RaycastResult
+
+	ab := c.End.Sub(c.Start)
+	o := ray.Origin.Sub(c.Start)
+	v := ray.Direction
+	rr := c.Radius * c.Radius
+	ll := ab.Dot(ab)
+
+	// The capsule is convex so the ray enters and leaves it at the first and last crossings
+	best := float32(maxFloat32)
+	enter, exit := float32(maxFloat32), float32(-maxFloat32)
+	consider := func(t float32) {
+		enter, exit = min(enter, t), max(exit, t)
+		if t >= 0 && t < best {
+			best = t
+		}
+	}
+
+	// The side satisfies |p - (p·ab/|ab|²) ab|² = r² between the ends of the segment
+	if ll > 0 {
+		vp := v.Sub(ab.Mul(v.Dot(ab) / ll))
+		op := o.Sub(ab.Mul(o.Dot(ab) / ll))
+		qa := vp.Dot(vp)
+		qb := op.Dot(vp)
+		qc := op.Dot(op) - rr
+		if disc := qb*qb - qa*qc; qa > epsilon32 && disc >= 0 {
+			s := sqrt(disc)
+			for _, t := range [2]float32{(-qb - s) / qa, (-qb + s) / qa} {
+				if y := o.Add(v.Mul(t)).Dot(ab); y >= 0 && y <= ll {
+					consider(t)
+				}
+			}
+		}
+	}
+
+	// The caps are the halves of the end spheres that lie beyond the segment
+	for _, e := range [2]struct {
+		centre Vec3
+		sign   float32
+	}{{Vec3{}, -1}, {ab, 1}} {
+		oc := o.Sub(e.centre)
+		b := oc.Dot(v)
+		if disc := b*b - (oc.Dot(oc) - rr); disc >= 0 {
+			s := sqrt(disc)
+			for _, t := range [2]float32{-b - s, -b + s} {
+				if oc.Add(v.Mul(t)).Dot(ab)*e.sign >= 0 {
+					consider(t)
+				}
+			}
+		}
+	}
+
+	if best == maxFloat32 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+	if opts.Exit {
+		best = exit
+	}
+
+	res.Distance = best
+	res.Enter, res.Exit = enter, exit
+	res.Point = ray.Point(best)
+	l := c.Line()
+	res.Normal = res.Point.Sub(l.ClosestPoint(res.Point)).Normalize()
+	return res, true
+}
+
+// Transformed returns the capsule that results from applying the transform to c, which is
+// assumed to be in the transform's local space. The radius is scaled by the largest scale
+// component, producing a capsule that contains the transformed shape.
+func (c *Capsule) Transformed(tx *Transform) Capsule {
+	return Capsule{
+		Start:  tx.TransformPoint(c.Start),
+		End:    tx.TransformPoint(c.End),
+		Radius: c.Radius * maxScale(tx.Scale()),
+	}
+}
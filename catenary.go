@@ -0,0 +1,146 @@
+package geom
+
+import "math"
+
+// Catenary is the curve taken by a uniform, flexible cable of a given length hanging between
+// two anchors, such as a rope or a power line. The cable hangs in the vertical plane through
+// the anchors, sagging against the up direction.
+//
+// A cable that is no longer than the distance between its anchors is pulled taut into a
+// straight line. A slack cable between anchors that are directly above one another hangs
+// straight down from both and folds at its lowest point.
+type Catenary struct {
+	A, B Point3 // the anchors
+
+	up     Vec3 // unit vector pointing away from gravity
+	horiz  Vec3 // unit vector pointing horizontally from A towards B
+	length float64
+
+	// In the vertical plane the cable follows y = a cosh(u) - a cosh(u1) with x = a (u - u1),
+	// measured from A. A zero a means the cable is straight, either taut or hanging vertically.
+	a    float64
+	u1   float64
+	dx   float64 // horizontal distance from A to B
+	dy   float64 // height of B above A
+	taut bool
+}
+
+// NewCatenary returns the catenary of a cable with the given length hanging from a to b. The
+// direction up points away from gravity and need not be normalised.
+func NewCatenary(a, b Point3, length float32, up Vec3) *Catenary {
+	c := &Catenary{
+		A:  a,
+		B:  b,
+		up: up.Normalize(),
+	}
+	d := b.Sub(a)
+	c.dy = float64(d.Dot(c.up))
+	h := d.Sub(c.up.Mul(float32(c.dy)))
+	dx := float64(h.Len())
+	if dx > 0 {
+		c.horiz = h.Mul(float32(1 / dx))
+	}
+	c.dx = dx
+
+	chord := float64(d.Len())
+	c.length = math.Max(float64(length), chord)
+	c.taut = c.length <= chord
+	if c.taut || dx <= 1e-6*c.length {
+		return c
+	}
+
+	// The parameter satisfies sqrt(L² - dy²) = 2a sinh(dx / 2a). With z = dx / 2a this is
+	// sinh(z)/z = r for some r > 1, whose solution is found by bisection
+	r := math.Sqrt(c.length*c.length-c.dy*c.dy) / dx
+	lo, hi := 0.0, 1.0
+	for math.Sinh(hi)/hi < r {
+		lo, hi = hi, hi*2
+	}
+	for i := 0; i < 100 && hi-lo > 1e-15*hi; i++ {
+		mid := (lo + hi) / 2
+		if math.Sinh(mid)/mid < r {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	z := (lo + hi) / 2
+	c.a = dx / (2 * z)
+
+	// The mean of u at the two anchors is atanh(dy/L) and they differ by dx/a
+	c.u1 = math.Atanh(c.dy/c.length) - z
+	return c
+}
+
+// Length returns the length of the cable.
+func (c *Catenary) Length() float32 {
+	return float32(c.length)
+}
+
+// PointAt returns the point a fraction t of the way along the cable from A, measured by
+// length. The fraction is clamped to the range 0 to 1.
+func (c *Catenary) PointAt(t float32) Point3 {
+	if t <= 0 {
+		return c.A
+	} else if t >= 1 {
+		return c.B
+	}
+	s := float64(t) * c.length
+
+	if c.a == 0 {
+		if c.taut {
+			return c.A.Add(c.B.Sub(c.A).Mul(t))
+		}
+		// Hanging vertically, down from A to the fold and then up to B
+		drop := (c.length - c.dy) / 2
+		if s <= drop {
+			return c.A.Sub(c.up.Mul(float32(s)))
+		}
+		return c.B.Sub(c.up.Mul(float32(c.length - s)))
+	}
+
+	u := math.Asinh(s/c.a + math.Sinh(c.u1))
+	return c.point(u)
+}
+
+// point returns the point on the curve at parameter u.
+func (c *Catenary) point(u float64) Point3 {
+	x := c.a * (u - c.u1)
+	y := c.a * (math.Cosh(u) - math.Cosh(c.u1))
+	return c.A.Add(c.horiz.Mul(float32(x))).Add(c.up.Mul(float32(y)))
+}
+
+// LowestPoint returns the lowest point of the cable. This is one of the anchors if the cable
+// does not dip below the lower of them.
+func (c *Catenary) LowestPoint() Point3 {
+	if c.a != 0 {
+		if u2 := c.u1 + c.dx/c.a; c.u1 < 0 && u2 > 0 {
+			return c.point(0)
+		}
+	} else if !c.taut {
+		return c.A.Sub(c.up.Mul(float32((c.length - c.dy) / 2)))
+	}
+	if c.dy < 0 {
+		return c.B
+	}
+	return c.A
+}
+
+// Sample returns n+1 points that divide the cable into n pieces of equal length, starting at
+// A and ending at B. At least one piece is always returned.
+func (c *Catenary) Sample(n int) []Point3 {
+	if n < 1 {
+		n = 1
+	}
+	pts := make([]Point3, n+1)
+	for i := range pts {
+		pts[i] = c.PointAt(float32(i) / float32(n))
+	}
+	pts[n] = c.B
+	return pts
+}
+
+// Flatten returns a path through n+1 points spaced evenly along the cable, as given by Sample.
+func (c *Catenary) Flatten(n int) *Path3 {
+	return NewPath3(c.Sample(n))
+}
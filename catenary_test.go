@@ -0,0 +1,88 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCatenary(t *testing.T) {
+	up := Vec3{0, 1, 0}
+	testCases := []struct {
+		name   string
+		a, b   Point3
+		length float32
+		lowest Point3
+	}{
+		{
+			name:   "level",
+			a:      Point3{-1, 0, 0},
+			b:      Point3{1, 0, 0},
+			length: float32(2 * math.Sinh(1)),
+			lowest: Point3{0, float32(1 - math.Cosh(1)), 0},
+		},
+		{
+			name:   "uneven",
+			a:      Point3{0, 0, 0},
+			b:      Point3{6, 2, 8},
+			length: 14,
+		},
+		{
+			name:   "steep",
+			a:      Point3{0, 0, 0},
+			b:      Point3{1, 10, 0},
+			length: 10.1,
+			lowest: Point3{0, 0, 0},
+		},
+		{
+			name:   "taut",
+			a:      Point3{0, 0, 0},
+			b:      Point3{3, 0, 4},
+			length: 2,
+			lowest: Point3{0, 0, 0},
+		},
+		{
+			name:   "vertical",
+			a:      Point3{0, 10, 0},
+			b:      Point3{0, 6, 0},
+			length: 8,
+			lowest: Point3{0, 4, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCatenary(tc.a, tc.b, tc.length, up)
+			want := max(tc.length, tc.b.Sub(tc.a).Len())
+			if c.Length() != want {
+				t.Errorf("got length %v, wanted %v", c.Length(), want)
+			}
+
+			pts := c.Sample(200)
+			if !nearVec3(pts[0], tc.a, 1e-5) || !nearVec3(pts[len(pts)-1], tc.b, 1e-5) {
+				t.Errorf("got ends %v and %v, wanted %v and %v", pts[0], pts[len(pts)-1], tc.a, tc.b)
+			}
+
+			// The samples are evenly spaced along the cable and the lowest point is no higher
+			// than any of them
+			step := want / 200
+			low := c.LowestPoint()
+			for i := 1; i < len(pts); i++ {
+				if d := pts[i].Sub(pts[i-1]).Len(); abs(d-step) > 1e-3*step {
+					t.Fatalf("sample %d is %v from the previous one, wanted %v", i, d, step)
+				}
+				if pts[i].Y() < low.Y()-1e-5 {
+					t.Fatalf("sample %d at %v is below the lowest point %v", i, pts[i], low)
+				}
+			}
+			if tc.lowest != (Point3{}) && !nearVec3(low, tc.lowest, 1e-5) {
+				t.Errorf("got lowest point %v, wanted %v", low, tc.lowest)
+			}
+
+			// The path cuts across the bends so it is a little shorter than the cable
+			p := c.Flatten(50)
+			if len(p.Points) != 51 || p.length > want*(1+1e-5) || p.length < 0.98*want {
+				t.Errorf("got path of %d points and length %v", len(p.Points), p.length)
+			}
+		})
+	}
+}
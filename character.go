@@ -0,0 +1,224 @@
+package geom
+
+import "math"
+
+// CharacterController moves an upright capsule through a mesh of triangles. The capsule slides
+// along the surfaces it hits, climbs ledges up to StepHeight, walks up slopes up to MaxSlope and
+// treats steeper surfaces as walls. The position of a character is the lowest point of its
+// capsule, at its feet.
+//
+// Every triangle of the mesh whose bounds meet the path of the capsule is tested, which suits
+// collision meshes of modest size.
+type CharacterController struct {
+	Radius float32 // the radius of the capsule
+	Height float32 // the total height of the capsule including its caps, at least 2*Radius
+
+	// Up is the direction the character stands along. Y3 is used when it is zero.
+	Up Vec3
+
+	// StepHeight is the height of the tallest ledge the character can step onto while moving.
+	StepHeight float32
+
+	// MaxSlope is the steepest slope the character can walk on, as an angle in radians from the
+	// horizontal. A quarter of pi is used when it is zero.
+	MaxSlope float32
+
+	// SkinWidth is the gap the capsule keeps from surfaces so that it does not start the next
+	// move in contact with them. A hundredth of the radius is used when it is zero.
+	SkinWidth float32
+
+	// MaxIterations limits the number of surfaces the character slides along in one move. 4 is
+	// used when it is zero.
+	MaxIterations int
+}
+
+// CharacterMove is the result of moving a character.
+type CharacterMove struct {
+	Position     Point3 // the new position of the character's feet
+	Grounded     bool   // whether the character stands on a walkable surface
+	GroundNormal Vec3   // the normal of the surface the character stands on when grounded
+	Collided     bool   // whether any surface blocked or deflected the move
+}
+
+// Capsule returns the capsule of a character whose feet are at pos.
+func (c *CharacterController) Capsule(pos Point3) Capsule {
+	up := c.up()
+	return Capsule{
+		Start:  pos.Add(up.Mul(c.Radius)),
+		End:    pos.Add(up.Mul(max(c.Height-c.Radius, c.Radius))),
+		Radius: c.Radius,
+	}
+}
+
+// Move moves the character with its feet at pos by disp through the mesh, which is treated as
+// two sided. A character that starts overlapping the mesh is first pushed out of it.
+func (c *CharacterController) Move(m *TriMesh, pos Point3, disp Vec3) CharacterMove {
+	up := c.up()
+	pos, pushed := c.depenetrate(m, pos)
+
+	res, collided := c.slide(m, pos, disp)
+	if vertical := disp.Dot(up); c.StepHeight > 0 && vertical <= 0 {
+		// Try again from StepHeight higher, then settle back down. Stepping is kept when it
+		// ends on walkable ground and gets further than moving directly.
+		horizontal := disp.Sub(up.Mul(vertical))
+		if horizontal.LenSqr() > 0 && collided {
+			raised, _, _ := c.sweep(m, pos, up.Mul(c.StepHeight))
+			rise := raised.Sub(pos).Dot(up)
+			moved, _ := c.slide(m, raised, horizontal)
+			stepped, n, hit := c.sweep(m, moved, up.Mul(vertical-rise))
+			if hit && c.walkable(n) && stepped.Sub(pos).Dot(horizontal) > res.Sub(pos).Dot(horizontal)+c.skin() {
+				res = stepped
+			}
+		}
+	}
+
+	mv := CharacterMove{Position: res, Collided: collided || pushed}
+	if _, n, hit := c.sweep(m, res, up.Mul(-2*c.skin())); hit && c.walkable(n) {
+		mv.Grounded, mv.GroundNormal = true, n
+	}
+	return mv
+}
+
+func (c *CharacterController) up() Vec3 {
+	if c.Up == (Vec3{}) {
+		return Y3
+	}
+	return c.Up.Normalize()
+}
+
+func (c *CharacterController) skin() float32 {
+	if c.SkinWidth == 0 {
+		return c.Radius / 100
+	}
+	return c.SkinWidth
+}
+
+// walkable reports whether a surface with normal n is shallow enough to stand on.
+func (c *CharacterController) walkable(n Vec3) bool {
+	slope := c.MaxSlope
+	if slope == 0 {
+		slope = pi / 4
+	}
+	return n.Dot(c.up()) >= float32(math.Cos(float64(slope)))-epsilon32
+}
+
+// slide moves the character from pos by disp, sliding along the surfaces it hits, and reports
+// whether it hit any.
+func (c *CharacterController) slide(m *TriMesh, pos Point3, disp Vec3) (Point3, bool) {
+	up := c.up()
+	iterations := c.MaxIterations
+	if iterations == 0 {
+		iterations = 4
+	}
+	collided := false
+	for i := 0; i < iterations && disp.LenSqr() > c.skin()*c.skin()*1e-4; i++ {
+		next, n, hit := c.sweep(m, pos, disp)
+		if !hit {
+			return next, collided
+		}
+		collided = true
+		disp = disp.Sub(next.Sub(pos))
+		pos = next
+
+		vertical := up.Mul(disp.Dot(up))
+		horizontal := disp.Sub(vertical)
+		switch {
+		case c.walkable(n):
+			// Walk along the ground without sliding down it
+			horizontal = Slide(horizontal, n)
+			if vertical.Dot(up) < 0 {
+				vertical = Vec3{}
+			}
+		case n.Dot(up) > 0:
+			// A steep slope blocks the character like a wall but still lets it slide down
+			if wall := n.Sub(up.Mul(n.Dot(up))); wall.LenSqr() > 0 {
+				horizontal = Slide(horizontal, wall.Normalize())
+			}
+			vertical = Slide(vertical, n)
+		default:
+			horizontal = Slide(horizontal, n)
+			vertical = Slide(vertical, n)
+		}
+		disp = horizontal.Add(vertical)
+	}
+	return pos, collided
+}
+
+// sweep moves the character from pos by disp until it hits the mesh, stopping the skin width
+// away from the surface. It returns the new position and, if a surface was hit, the normal of
+// the contact pointing towards the character.
+func (c *CharacterController) sweep(m *TriMesh, pos Point3, disp Vec3) (Point3, Vec3, bool) {
+	if disp == (Vec3{}) {
+		return pos, Vec3{}, false
+	}
+	capsule, moved := c.Capsule(pos), c.Capsule(pos.Add(disp))
+	start, end := capsule.Bounds(), moved.Bounds()
+	swept := AABBFromCorners(extendBounds(start.Min(), start.Max(), end.Min(), end.Max()))
+
+	best := float32(1)
+	var contact Point3
+	hit := false
+	for i := 0; i < m.Len(); i++ {
+		tri := m.Tri(i)
+		if !tri.IntersectsAABB(&swept) {
+			continue
+		}
+		if toi, pt, ok := TOICapsuleTri3(capsule, disp, tri); ok && toi <= best {
+			best, contact, hit = toi, pt, true
+		}
+	}
+	if !hit {
+		return pos.Add(disp), Vec3{}, false
+	}
+
+	at := c.Capsule(pos.Add(disp.Mul(best)))
+	axis := at.Line()
+	n := axis.ClosestPoint(contact).Sub(contact).Normalize()
+	// Stand off from the surface along its normal so that the gap is the same whichever way
+	// the character approached it
+	return pos.Add(disp.Mul(best)).Add(n.Mul(c.skin())), n, true
+}
+
+// depenetrate pushes the character at pos out of any triangles its capsule overlaps and
+// reports whether it had to move.
+func (c *CharacterController) depenetrate(m *TriMesh, pos Point3) (Point3, bool) {
+	iterations := c.MaxIterations
+	if iterations == 0 {
+		iterations = 4
+	}
+	pushed := false
+	for i := 0; i < iterations; i++ {
+		capsule := c.Capsule(pos)
+		bounds := capsule.Bounds()
+		moved := false
+		for k := 0; k < m.Len(); k++ {
+			tri := m.Tri(k)
+			if !tri.IntersectsAABB(&bounds) {
+				continue
+			}
+			seg, tp := closestPointsLine3Tri3(capsule.Line(), tri)
+			d := seg.Sub(tp)
+			dl := d.Len()
+			if dl >= c.Radius {
+				continue
+			}
+			n := d.Mul(1 / dl)
+			if dl == 0 {
+				// The axis passes through the triangle so push towards the side the middle of
+				// the capsule is on
+				n = tri.Normal()
+				if capsule.Center().Sub(tri.A).Dot(n) < 0 {
+					n = n.Mul(-1)
+				}
+			}
+			pos = pos.Add(n.Mul(c.Radius + c.skin() - dl))
+			capsule = c.Capsule(pos)
+			bounds = capsule.Bounds()
+			moved, pushed = true, true
+		}
+		if !moved {
+			break
+		}
+	}
+	return pos, pushed
+}
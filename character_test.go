@@ -0,0 +1,202 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// characterWorld returns a mesh made of a floor at y=0 and the given boxes.
+func characterWorld(boxes ...AABB) *TriMesh {
+	m := &TriMesh{
+		Positions: []Point3{{-20, 0, -20}, {20, 0, -20}, {20, 0, 20}, {-20, 0, 20}},
+		Indices:   []uint32{0, 2, 1, 0, 3, 2},
+	}
+	for _, b := range boxes {
+		bm := boxMesh(b)
+		for _, i := range bm.Indices {
+			m.Indices = append(m.Indices, i+uint32(len(m.Positions)))
+		}
+		m.Positions = append(m.Positions, bm.Positions...)
+	}
+	return m
+}
+
+// rampWorld returns a floor at y=0 with a ramp rising along +x from x=1 at the given angle.
+func rampWorld(angle float32) *TriMesh {
+	h := 10 * float32(math.Tan(float64(angle)))
+	m := characterWorld()
+	n := uint32(len(m.Positions))
+	m.Positions = append(m.Positions, Point3{1, 0, -20}, Point3{11, h, -20}, Point3{11, h, 20}, Point3{1, 0, 20})
+	m.Indices = append(m.Indices, n, n+2, n+1, n, n+3, n+2)
+	return m
+}
+
+func TestCharacterControllerMove(t *testing.T) {
+	cc := CharacterController{Radius: 0.5, Height: 2, StepHeight: 0.4, SkinWidth: 0.01}
+
+	testCases := []struct {
+		name     string
+		world    *TriMesh
+		pos      Point3
+		disp     Vec3
+		check    func(p Point3) bool
+		want     string
+		grounded bool
+		collided bool
+	}{
+		{
+			name:     "falling onto the floor",
+			world:    characterWorld(),
+			pos:      Point3{0, 3, 0},
+			disp:     Vec3{0, -10, 0},
+			check:    func(p Point3) bool { return abs(p[1]-0.01) < 1e-3 && p[0] == 0 && p[2] == 0 },
+			want:     "resting on the floor",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "walking on the floor",
+			world:    characterWorld(),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{2, -0.1, 0},
+			check:    func(p Point3) bool { return abs(p[0]-2) < 1e-3 && abs(p[1]-0.01) < 1e-3 },
+			want:     "two units along the floor",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "blocked by a wall",
+			world:    characterWorld(AABBFromCorners(Point3{2, 0, -5}, Point3{3, 3, 5})),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{5, -0.1, 0},
+			check:    func(p Point3) bool { return abs(p[0]-1.49) < 1e-3 && abs(p[2]) < 1e-3 },
+			want:     "stopped short of the wall",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "sliding along a wall",
+			world:    characterWorld(AABBFromCorners(Point3{2, 0, -5}, Point3{3, 3, 5})),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{3, -0.1, 3},
+			check:    func(p Point3) bool { return abs(p[0]-1.49) < 1e-3 && abs(p[2]-3) < 1e-2 },
+			want:     "stopped in x but carried on in z",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "stepping onto a ledge",
+			world:    characterWorld(AABBFromCorners(Point3{1, 0, -5}, Point3{5, 0.3, 5})),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{2, -0.1, 0},
+			check:    func(p Point3) bool { return abs(p[0]-2) < 1e-2 && abs(p[1]-0.31) < 1e-2 },
+			want:     "on top of the ledge",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "ledge too tall to step onto",
+			world:    characterWorld(AABBFromCorners(Point3{1, 0, -5}, Point3{5, 0.6, 5})),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{2, -0.1, 0},
+			check:    func(p Point3) bool { return abs(p[0]-0.49) < 1e-3 && abs(p[1]-0.01) < 1e-3 },
+			want:     "stopped short of the ledge",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "walking up a shallow ramp",
+			world:    rampWorld(pi / 6),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{4, -0.1, 0},
+			check:    func(p Point3) bool { return p[0] > 3 && p[1] > 1 },
+			want:     "part way up the ramp",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "blocked by a steep ramp",
+			world:    rampWorld(pi / 3),
+			pos:      Point3{0, 0.01, 0},
+			disp:     Vec3{4, -0.1, 0},
+			check:    func(p Point3) bool { return p[0] < 1 && p[1] < 0.4 },
+			want:     "at the foot of the ramp",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:     "pushed out of the floor",
+			world:    characterWorld(),
+			pos:      Point3{0, -0.3, 0},
+			check:    func(p Point3) bool { return p[1] >= 0 && p[1] < 0.02 },
+			want:     "on the floor",
+			grounded: true,
+			collided: true,
+		},
+		{
+			name:  "in the air",
+			world: characterWorld(),
+			pos:   Point3{0, 5, 0},
+			disp:  Vec3{1, -1, 0},
+			check: func(p Point3) bool { return p == (Point3{1, 4, 0}) },
+			want:  "moved freely",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cc.Move(tc.world, tc.pos, tc.disp)
+			if !tc.check(got.Position) {
+				t.Errorf("got position %v, wanted %s", got.Position, tc.want)
+			}
+			if got.Grounded != tc.grounded {
+				t.Errorf("got grounded %v, wanted %v", got.Grounded, tc.grounded)
+			}
+			if got.Collided != tc.collided {
+				t.Errorf("got collided %v, wanted %v", got.Collided, tc.collided)
+			}
+			if got.Grounded && got.GroundNormal.Dot(Y3) < 0.8 {
+				t.Errorf("got ground normal %v", got.GroundNormal)
+			}
+		})
+	}
+}
+
+func TestCharacterControllerSteepRampSlide(t *testing.T) {
+	// Falling onto a steep ramp slides the character down it rather than letting it stand
+	cc := CharacterController{Radius: 0.5, Height: 2}
+	m := rampWorld(pi / 3)
+	pos := Point3{6, 12, 0}
+	for i := 0; i < 200; i++ {
+		mv := cc.Move(m, pos, Vec3{0, -0.2, 0})
+		pos = mv.Position
+	}
+	if pos[0] > 1.5 || pos[1] > 0.1 {
+		t.Errorf("got position %v, wanted the foot of the ramp", pos)
+	}
+}
+
+func TestCharacterControllerStaysOutside(t *testing.T) {
+	// Wandering at random among boxes, ramps and ledges never leaves the capsule inside the mesh
+	cc := CharacterController{Radius: 0.4, Height: 1.8, StepHeight: 0.3}
+	m := characterWorld(
+		AABBFromCorners(Point3{2, 0, -3}, Point3{3, 2, 3}),
+		AABBFromCorners(Point3{-4, 0, -1}, Point3{-1, 0.25, 1}),
+		AABBFromCorners(Point3{-2, 0, 3}, Point3{0, 1, 4}),
+		AABBFromCorners(Point3{-6, 0, -6}, Point3{6, 3, -5}),
+	)
+	rng := rand.New(rand.NewSource(1))
+	pos := Point3{0, 0.5, 0}
+	for i := 0; i < 2000; i++ {
+		disp := Vec3{rng.Float32() - 0.5, -0.2, rng.Float32() - 0.5}
+		pos = cc.Move(m, pos, disp).Position
+		c := cc.Capsule(pos)
+		for k := 0; k < m.Len(); k++ {
+			a, b := closestPointsLine3Tri3(c.Line(), m.Tri(k))
+			if d := a.Sub(b).Len(); d < c.Radius-1e-3 {
+				t.Fatalf("step %d: capsule at %v is %v from triangle %d, less than its radius", i, pos, d, k)
+			}
+		}
+	}
+}
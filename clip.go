@@ -0,0 +1,171 @@
+package geom
+
+// Outcodes used by the Cohen-Sutherland algorithm to record which side of a rectangle a
+// point lies on.
+const (
+	outLeft = 1 << iota
+	outRight
+	outBottom
+	outTop
+)
+
+// outcode returns the Cohen-Sutherland outcode for p relative to the bounds pmin and pmax.
+func outcode(p, pmin, pmax Point2) int {
+	code := 0
+	if p[0] < pmin[0] {
+		code |= outLeft
+	} else if p[0] > pmax[0] {
+		code |= outRight
+	}
+	if p[1] < pmin[1] {
+		code |= outBottom
+	} else if p[1] > pmax[1] {
+		code |= outTop
+	}
+	return code
+}
+
+// ClipSegmentToRect clips the line segment from a to b so that it lies within r using the
+// Cohen-Sutherland algorithm. It returns the clipped end points and true if any part of the
+// segment lies within r, or false if the segment is entirely outside r.
+func ClipSegmentToRect(a, b Point2, r Rect) (Point2, Point2, bool) {
+	pmin, pmax := r.Min(), r.Max()
+	ca, cb := outcode(a, pmin, pmax), outcode(b, pmin, pmax)
+
+	for {
+		if ca|cb == 0 {
+			// Both points inside
+			return a, b, true
+		}
+		if ca&cb != 0 {
+			// Both points share an outside region so the segment cannot cross the rect
+			return Point2{}, Point2{}, false
+		}
+
+		// Move the point that is outside onto the edge it lies beyond
+		code := ca
+		if code == 0 {
+			code = cb
+		}
+
+		var p Point2
+		d := b.Sub(a)
+		switch {
+		case code&outTop != 0:
+			p = Point2{a[0] + d[0]*(pmax[1]-a[1])/d[1], pmax[1]}
+		case code&outBottom != 0:
+			p = Point2{a[0] + d[0]*(pmin[1]-a[1])/d[1], pmin[1]}
+		case code&outRight != 0:
+			p = Point2{pmax[0], a[1] + d[1]*(pmax[0]-a[0])/d[0]}
+		default:
+			p = Point2{pmin[0], a[1] + d[1]*(pmin[0]-a[0])/d[0]}
+		}
+
+		if code == ca {
+			a = p
+			ca = outcode(a, pmin, pmax)
+		} else {
+			b = p
+			cb = outcode(b, pmin, pmax)
+		}
+	}
+}
+
+// ClipSegmentToAABB clips the line segment l so that it lies within a using the
+// Liang-Barsky algorithm. It returns the clipped segment and true if any part of the segment
+// lies within a, or false if the segment is entirely outside a.
+func ClipSegmentToAABB(l Line3, a AABB) (Line3, bool) {
+	pmin, pmax := a.Min(), a.Max()
+	d := l.End.Sub(l.Start)
+
+	t0, t1 := float32(0), float32(1)
+	for i := 0; i < 3; i++ {
+		// Each axis contributes two boundaries of the form p*t <= q
+		for _, pq := range [2][2]float32{
+			{-d[i], l.Start[i] - pmin[i]},
+			{d[i], pmax[i] - l.Start[i]},
+		} {
+			p, q := pq[0], pq[1]
+			if p == 0 {
+				// Parallel to the boundary
+				if q < 0 {
+					return Line3{}, false
+				}
+				continue
+			}
+			t := q / p
+			if p < 0 {
+				if t > t1 {
+					return Line3{}, false
+				}
+				t0 = max(t0, t)
+			} else {
+				if t < t0 {
+					return Line3{}, false
+				}
+				t1 = min(t1, t)
+			}
+		}
+	}
+
+	return Line3{
+		Start: l.Start.Add(d.Mul(t0)),
+		End:   l.Start.Add(d.Mul(t1)),
+	}, true
+}
+
+// ClipTri3ToPlane clips the triangle so that it lies on the side of the plane that the normal
+// faces. It returns no triangles when the triangle is entirely behind the plane, the triangle
+// itself when it is entirely in front and otherwise one or two triangles covering the part in
+// front. The winding of the triangle is preserved.
+func ClipTri3ToPlane(t Tri3, p Plane3) []Tri3 {
+	// A plane cuts at most one corner off a triangle, leaving four
+	var buf [4]Point3
+	return fanTri3(clipPolygon3ToPlane([]Point3{t.A, t.B, t.C}, p, buf[:0]), nil)
+}
+
+// ClipTri3 clips the triangle so that it lies within the frustum. It returns the triangles
+// covering the part of the triangle inside the frustum, which is empty when the triangle lies
+// entirely outside. The winding of the triangle is preserved.
+func (f *Frustum) ClipTri3(t Tri3) []Tri3 {
+	// Clipping a triangle by six planes leaves at most nine corners
+	var bufA, bufB [9]Point3
+	poly := append(bufA[:0], t.A, t.B, t.C)
+	out := bufB[:0]
+	for i := range f.Planes {
+		out = clipPolygon3ToPlane(poly, f.Planes[i], out[:0])
+		poly, out = out, poly
+		if len(poly) < 3 {
+			return nil
+		}
+	}
+	return fanTri3(poly, nil)
+}
+
+// clipPolygon3ToPlane appends to out the corners of the part of the convex polygon that lies in
+// front of the plane, using the Sutherland-Hodgman algorithm.
+func clipPolygon3ToPlane(poly []Point3, p Plane3, out []Point3) []Point3 {
+	n := len(poly)
+	for i := 0; i < n; i++ {
+		cur, next := poly[i], poly[(i+1)%n]
+		dc := SignedDistancePointPlane3(cur, &p)
+		dn := SignedDistancePointPlane3(next, &p)
+		if dc >= 0 {
+			out = append(out, cur)
+		}
+		if (dc > 0 && dn < 0) || (dc < 0 && dn > 0) {
+			// The edge crosses the plane. An end on the plane is kept as a corner already, so
+			// only a strict change of side adds one
+			out = append(out, cur.Add(next.Sub(cur).Mul(dc/(dc-dn))))
+		}
+	}
+	return out
+}
+
+// fanTri3 appends to tris a fan of triangles covering the convex polygon.
+func fanTri3(poly []Point3, tris []Tri3) []Tri3 {
+	for i := 2; i < len(poly); i++ {
+		tris = append(tris, Tri3{A: poly[0], B: poly[i-1], C: poly[i]})
+	}
+	return tris
+}
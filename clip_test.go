@@ -0,0 +1,186 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestClipSegmentToRect(t *testing.T) {
+	r := RectFromCorners(Point2{0, 0}, Point2{10, 10})
+
+	testCases := []struct {
+		name   string
+		a, b   Point2
+		ok     bool
+		wa, wb Point2
+	}{
+		{name: "inside", a: Point2{1, 1}, b: Point2{9, 9}, ok: true, wa: Point2{1, 1}, wb: Point2{9, 9}},
+		{name: "through", a: Point2{-5, 5}, b: Point2{15, 5}, ok: true, wa: Point2{0, 5}, wb: Point2{10, 5}},
+		{name: "diagonal", a: Point2{-5, -5}, b: Point2{15, 15}, ok: true, wa: Point2{0, 0}, wb: Point2{10, 10}},
+		{name: "one end inside", a: Point2{5, 5}, b: Point2{5, 20}, ok: true, wa: Point2{5, 5}, wb: Point2{5, 10}},
+		{name: "corner cut", a: Point2{-2, 8}, b: Point2{4, 14}, ok: true, wa: Point2{0, 10}, wb: Point2{0, 10}},
+		{name: "same side", a: Point2{-5, 1}, b: Point2{-1, 9}, ok: false},
+		{name: "miss across corner", a: Point2{-5, 8}, b: Point2{5, 18}, ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ga, gb, ok := ClipSegmentToRect(tc.a, tc.b, r)
+			if ok != tc.ok {
+				t.Fatalf("got ok=%v, wanted %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if ga.Sub(tc.wa).Len() > 1e-5 || gb.Sub(tc.wb).Len() > 1e-5 {
+				t.Errorf("got %v-%v, wanted %v-%v", ga, gb, tc.wa, tc.wb)
+			}
+		})
+	}
+}
+
+func TestClipSegmentToAABB(t *testing.T) {
+	a := AABBFromCorners(Point3{0, 0, 0}, Point3{10, 10, 10})
+
+	testCases := []struct {
+		name string
+		l    Line3
+		ok   bool
+		want Line3
+	}{
+		{
+			name: "inside",
+			l:    Line3{Start: Point3{1, 2, 3}, End: Point3{4, 5, 6}},
+			ok:   true,
+			want: Line3{Start: Point3{1, 2, 3}, End: Point3{4, 5, 6}},
+		},
+		{
+			name: "through",
+			l:    Line3{Start: Point3{5, 5, -10}, End: Point3{5, 5, 20}},
+			ok:   true,
+			want: Line3{Start: Point3{5, 5, 0}, End: Point3{5, 5, 10}},
+		},
+		{
+			name: "diagonal",
+			l:    Line3{Start: Point3{-5, -5, -5}, End: Point3{5, 5, 5}},
+			ok:   true,
+			want: Line3{Start: Point3{0, 0, 0}, End: Point3{5, 5, 5}},
+		},
+		{
+			name: "parallel outside",
+			l:    Line3{Start: Point3{-1, 0, 0}, End: Point3{-1, 10, 10}},
+			ok:   false,
+		},
+		{
+			name: "miss",
+			l:    Line3{Start: Point3{-5, 5, 5}, End: Point3{5, 20, 5}},
+			ok:   false,
+		},
+		{
+			name: "short of box",
+			l:    Line3{Start: Point3{-5, 5, 5}, End: Point3{-1, 5, 5}},
+			ok:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ClipSegmentToAABB(tc.l, a)
+			if ok != tc.ok {
+				t.Fatalf("got ok=%v, wanted %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if !nearVec3(got.Start, tc.want.Start, 1e-5) || !nearVec3(got.End, tc.want.End, 1e-5) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClipTri3ToPlane(t *testing.T) {
+	tri := Tri3{Point3{0, 0, 0}, Point3{2, 0, 0}, Point3{0, 2, 0}}
+
+	testCases := []struct {
+		name  string
+		plane Plane3
+		count int
+		area  float32
+	}{
+		{name: "all in front", plane: Plane3{Normal: Vec3{1, 0, 0}, Distance: -1}, count: 1, area: 2},
+		{name: "all behind", plane: Plane3{Normal: Vec3{1, 0, 0}, Distance: 3}, count: 0},
+		{name: "one corner cut off", plane: Plane3{Normal: Vec3{-1, 0, 0}, Distance: -1}, count: 2, area: 1.5},
+		{name: "one corner kept", plane: Plane3{Normal: Vec3{1, 0, 0}, Distance: 1}, count: 1, area: 0.5},
+		{name: "corner on plane", plane: Plane3{Normal: Vec3{1, -1, 0}.Normalize(), Distance: 0}, count: 1, area: 1},
+		{name: "corner on plane kept", plane: Plane3{Normal: Vec3{-1, 1, 0}.Normalize(), Distance: 0}, count: 1, area: 1},
+		{name: "edge on plane", plane: Plane3{Normal: Vec3{0, 1, 0}, Distance: 0}, count: 1, area: 2},
+		{name: "in the plane", plane: Plane3{Normal: Vec3{0, 0, 1}, Distance: 0}, count: 1, area: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ClipTri3ToPlane(tri, tc.plane)
+			if len(got) != tc.count {
+				t.Fatalf("got %d triangles, wanted %d", len(got), tc.count)
+			}
+			var area float32
+			for _, c := range got {
+				area += c.Area()
+				if n := c.Normal(); !nearVec3(n, tri.Normal(), 1e-6) {
+					t.Errorf("winding changed: normal %v", n)
+				}
+				for _, p := range []Point3{c.A, c.B, c.C} {
+					if d := SignedDistancePointPlane3(p, &tc.plane); d < -1e-6 {
+						t.Errorf("corner %v is behind the plane by %v", p, -d)
+					}
+				}
+			}
+			if abs(area-tc.area) > 1e-5 {
+				t.Errorf("got area %v, wanted %v", area, tc.area)
+			}
+		})
+	}
+}
+
+func TestFrustumClipTri3(t *testing.T) {
+	// The frustum of an orthographic projection is the box from -1 to 1 on each axis
+	f := FrustumFromMatrix(mgl32.Ortho(-1, 1, -1, 1, -1, 1))
+	box := AABB{Size: Vec3{1, 1, 1}}
+
+	testCases := []struct {
+		name string
+		tri  Tri3
+		area float32
+	}{
+		{name: "inside", tri: Tri3{Point3{0, 0, 0}, Point3{0.5, 0, 0}, Point3{0, 0.5, 0}}, area: 0.125},
+		{name: "covers a slice", tri: Tri3{Point3{-10, -10, 0.5}, Point3{10, -10, 0.5}, Point3{0, 10, 0.5}}, area: 4},
+		{name: "outside", tri: Tri3{Point3{2, 0, 0}, Point3{3, 0, 0}, Point3{2, 1, 0}}},
+		{name: "diagonal", tri: Tri3{Point3{-5, -5, -5}, Point3{5, 5, 5}, Point3{5, -5, 0}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := f.ClipTri3(tc.tri)
+			var area float32
+			for _, c := range got {
+				area += c.Area()
+				for _, p := range []Point3{c.A, c.B, c.C} {
+					if !box.ContainsPoint3(p) && DistancePointAABB(p, &box) > 1e-5 {
+						t.Errorf("corner %v is outside the frustum", p)
+					}
+				}
+				if c.Area() > 1e-6 && c.Normal().Dot(tc.tri.Normal()) < 0.999 {
+					t.Errorf("winding changed: normal %v", c.Normal())
+				}
+			}
+			if tc.area > 0 && abs(area-tc.area) > 1e-4 {
+				t.Errorf("got area %v, wanted %v", area, tc.area)
+			}
+			if tc.name == "diagonal" && len(got) == 0 {
+				t.Errorf("got no triangles for a triangle through the centre")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package geom
+
+// CompoundChild is a shape within a CompoundShape, placed by a transform from the shape's
+// local space into the compound's space.
+type CompoundChild struct {
+	Shape     Shape3
+	Transform Transform
+}
+
+// CompoundShape is the union of a number of child shapes. Complex colliders can be built from
+// several primitives, each positioned by its own transform.
+type CompoundShape struct {
+	Children []CompoundChild
+}
+
+// Add appends a child shape placed by the transform tx.
+func (c *CompoundShape) Add(s Shape3, tx Transform) {
+	c.Children = append(c.Children, CompoundChild{Shape: s, Transform: tx})
+}
+
+// ContainsPoint3 reports whether the point lies within any of the child shapes.
+func (c *CompoundShape) ContainsPoint3(pt Point3) bool {
+	for i := range c.Children {
+		ch := &c.Children[i]
+		if ch.Shape.ContainsPoint3(ch.Transform.InverseTransformPoint(pt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Raycast tests whether the ray hits any of the child shapes, returning the nearest hit.
+func (c *CompoundShape) Raycast(ray Ray3) (RaycastResult, bool) {
+	res, _, ok := c.RaycastChild(ray)
+	return res, ok
+}
+
+// RaycastChild tests whether the ray hits any of the child shapes, returning the nearest hit
+// and the index of the child that was hit. The Index of the result is the one reported by the
+// child, such as the triangle of a mesh, and Shape is the child's shape unless the child
+// reported a more specific one.
+func (c *CompoundShape) RaycastChild(ray Ray3) (RaycastResult, int, bool) {
+	var best RaycastResult
+	best.Fail = RaycastFailOutsideBounds
+	bestIndex := -1
+
+	for i := range c.Children {
+		ch := &c.Children[i]
+		local := ray.InverseTransformed(&ch.Transform)
+		res, ok := ch.Shape.Raycast(local)
+		if !ok {
+			continue
+		}
+
+		// Distances along the local ray are scaled so measure again in the compound's space
+		measure := func(d float32) float32 {
+			return ch.Transform.TransformPoint(local.Point(d)).Sub(ray.Origin).Dot(ray.Direction)
+		}
+		dist := measure(res.Distance)
+		if bestIndex >= 0 && dist >= best.Distance {
+			continue
+		}
+
+		// Normals are transformed by the inverse transpose so they stay perpendicular to the
+		// surface under a non-uniform scale
+		scale := ch.Transform.Scale()
+		n := Vec3{res.Normal[0] / scale[0], res.Normal[1] / scale[1], res.Normal[2] / scale[2]}
+
+		shape := res.Shape
+		if shape == nil {
+			shape = ch.Shape
+		}
+		best = RaycastResult{
+			Point:       ch.Transform.TransformPoint(res.Point),
+			Normal:      ch.Transform.Orientation().Rotate(n).Normalize(),
+			Distance:    dist,
+			Enter:       measure(res.Enter),
+			Exit:        measure(res.Exit),
+			Barycentric: res.Barycentric,
+			Index:       res.Index,
+			Shape:       shape,
+		}
+		bestIndex = i
+	}
+
+	return best, bestIndex, bestIndex >= 0
+}
+
+// Bounds returns an AABB that contains all of the child shapes. Each child's bounds are
+// transformed so the result may be larger than the tightest possible bounds for rotated
+// children.
+func (c *CompoundShape) Bounds() AABB {
+	if len(c.Children) == 0 {
+		return AABB{}
+	}
+	pts := make([]Point3, 0, len(c.Children)*8)
+	for i := range c.Children {
+		ch := &c.Children[i]
+		b := ch.Shape.Bounds()
+		corners := b.cornerArray()
+		for _, p := range corners {
+			pts = append(pts, ch.Transform.TransformPoint(p))
+		}
+	}
+	return AABBFromPoints(pts)
+}
+
+// ProjectOntoAxis projects every child shape onto the axis and returns the interval that
+// covers them all. Children that do not implement Projecter are represented by their bounds.
+func (c *CompoundShape) ProjectOntoAxis(axis Vec3) Interval {
+	var in Interval
+	for i := range c.Children {
+		ch := &c.Children[i]
+
+		// Projecting the transformed shape onto the axis is the same as projecting the local
+		// shape onto the axis carried back through the transform, then offsetting
+		scale := ch.Transform.Scale()
+		local := ch.Transform.Orientation().Inverse().Rotate(axis)
+		local = Vec3{local[0] * scale[0], local[1] * scale[1], local[2] * scale[2]}
+		offset := axis.Dot(ch.Transform.Pos())
+
+		var ci Interval
+		if p, ok := ch.Shape.(Projecter); ok {
+			ci = p.ProjectOntoAxis(local)
+		} else {
+			b := ch.Shape.Bounds()
+			ci = b.ProjectOntoAxis(local)
+		}
+		ci.Min += offset
+		ci.Max += offset
+
+		if i == 0 {
+			in = ci
+			continue
+		}
+		in.Min = min(in.Min, ci.Min)
+		in.Max = max(in.Max, ci.Max)
+	}
+	return in
+}
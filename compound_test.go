@@ -0,0 +1,142 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestShapeBounds(t *testing.T) {
+	nearAABB := func(a, b AABB) bool {
+		return nearVec3(a.Position, b.Position, 1e-4) && nearVec3(a.Size, b.Size, 1e-4)
+	}
+	s2 := sqrt(2)
+
+	testCases := []struct {
+		name  string
+		shape Bounded
+		want  AABB
+	}{
+		{
+			name:  "aabb",
+			shape: &AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}},
+			want:  AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}},
+		},
+		{
+			name:  "sphere",
+			shape: &Sphere{Position: Point3{1, 2, 3}, Radius: 2},
+			want:  AABB{Position: Point3{1, 2, 3}, Size: Vec3{2, 2, 2}},
+		},
+		{
+			name:  "obb",
+			shape: &OBB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}, Orientation: mgl32.QuatRotate(pi/4, Z3)},
+			want:  AABB{Position: Point3{0, 0, 0}, Size: Vec3{s2, s2, 1}},
+		},
+		{
+			name:  "capsule",
+			shape: &Capsule{Start: Point3{0, 0, 0}, End: Point3{0, 4, 0}, Radius: 1},
+			want:  AABB{Position: Point3{0, 2, 0}, Size: Vec3{1, 3, 1}},
+		},
+		{
+			name:  "ellipsoid",
+			shape: &Ellipsoid{Position: Point3{1, 0, 0}, Radii: Vec3{3, 2, 1}, Orientation: mgl32.QuatRotate(pi/2, Z3)},
+			want:  AABB{Position: Point3{1, 0, 0}, Size: Vec3{2, 3, 1}},
+		},
+		{
+			name:  "narrow cone",
+			shape: &Cone{Apex: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}, Angle: pi / 4, Range: 2},
+			want:  AABBFromCorners(Point3{0, -s2, -s2}, Point3{2, s2, s2}),
+		},
+		{
+			name:  "wide cone",
+			shape: &Cone{Apex: Point3{0, 0, 0}, Direction: Vec3{0, 0, 1}, Angle: 3 * pi / 4, Range: 1},
+			want:  AABBFromCorners(Point3{-1, -1, -s2 / 2}, Point3{1, 1, 1}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.shape.Bounds(); !nearAABB(got, tc.want) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func testCompoundShape() *CompoundShape {
+	var c CompoundShape
+
+	// A unit box at the origin and a sphere stretched along x sitting to its right
+	c.Add(&AABB{Size: Vec3{1, 1, 1}}, NewTransform())
+
+	tx := NewTransform()
+	tx.SetPosition(Vec3{5, 0, 0})
+	tx.SetScale(Vec3{2, 1, 1})
+	c.Add(&Sphere{Radius: 1}, tx)
+	return &c
+}
+
+func TestCompoundShapeContainsPoint3(t *testing.T) {
+	c := testCompoundShape()
+
+	testCases := []struct {
+		p    Point3
+		want bool
+	}{
+		{p: Point3{0.5, 0.5, 0.5}, want: true},
+		{p: Point3{6.5, 0, 0}, want: true},
+		{p: Point3{5, 0.9, 0}, want: true},
+		{p: Point3{5, 1.1, 0}, want: false},
+		{p: Point3{2, 0, 0}, want: false},
+	}
+	for _, tc := range testCases {
+		if got := c.ContainsPoint3(tc.p); got != tc.want {
+			t.Errorf("ContainsPoint3(%v): got %v, wanted %v", tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestCompoundShapeRaycast(t *testing.T) {
+	c := testCompoundShape()
+
+	res, idx, ok := c.RaycastChild(Ray3{Origin: Point3{20, 0, 0}, Direction: Vec3{-1, 0, 0}})
+	if !ok || idx != 1 {
+		t.Fatalf("got child %d, %v, wanted the sphere", idx, ok)
+	}
+	if abs(res.Distance-13) > 1e-4 || !nearVec3(res.Point, Point3{7, 0, 0}, 1e-4) || !nearVec3(res.Normal, Vec3{1, 0, 0}, 1e-4) {
+		t.Errorf("got %+v, wanted hit at (7,0,0) distance 13", res)
+	}
+
+	res, idx, ok = c.RaycastChild(Ray3{Origin: Point3{-10, 0, 0}, Direction: Vec3{1, 0, 0}})
+	if !ok || idx != 0 || abs(res.Distance-9) > 1e-4 || !nearVec3(res.Point, Point3{-1, 0, 0}, 1e-4) {
+		t.Errorf("got %+v in child %d, wanted the box at distance 9", res, idx)
+	}
+
+	// The normal of the stretched sphere stays perpendicular to its surface
+	p := Point3{5 + 2*sqrt(2)/2, sqrt(2) / 2, 0}
+	res, ok = c.Raycast(Ray3{Origin: p.Add(Vec3{0, 5, 0}), Direction: Vec3{0, -1, 0}})
+	if want := (Vec3{1, 2, 0}).Normalize(); !ok || !nearVec3(res.Normal, want, 1e-4) {
+		t.Errorf("got normal %v, wanted %v", res.Normal, want)
+	}
+
+	if res, ok := c.Raycast(Ray3{Origin: Point3{0, 5, 0}, Direction: Vec3{1, 0, 0}}); ok || res.Fail != RaycastFailOutsideBounds {
+		t.Errorf("got %+v, %v, wanted a miss", res, ok)
+	}
+}
+
+func TestCompoundShapeBounds(t *testing.T) {
+	c := testCompoundShape()
+
+	want := AABBFromCorners(Point3{-1, -1, -1}, Point3{7, 1, 1})
+	if got := c.Bounds(); !nearVec3(got.Position, want.Position, 1e-5) || !nearVec3(got.Size, want.Size, 1e-5) {
+		t.Errorf("Bounds: got %v, wanted %v", got, want)
+	}
+
+	in := c.ProjectOntoAxis(Vec3{1, 0, 0})
+	if abs(in.Min+1) > 1e-5 || abs(in.Max-7) > 1e-5 {
+		t.Errorf("ProjectOntoAxis x: got %v, wanted [-1, 7]", in)
+	}
+	in = c.ProjectOntoAxis(Vec3{0, 1, 0})
+	if abs(in.Min+1) > 1e-5 || abs(in.Max-1) > 1e-5 {
+		t.Errorf("ProjectOntoAxis y: got %v, wanted [-1, 1]", in)
+	}
+}
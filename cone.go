@@ -0,0 +1,203 @@
+package geom
+
+// Cone is a solid cone that spreads from its apex along Direction, which should be a unit
+// vector. Angle is the half angle of the cone in radians, in the range [0, π]. The cone is
+// capped by part of a sphere so that every point within it lies no further than Range from
+// the apex, matching how far a guard or turret can see.
+type Cone struct {
+	Apex      Point3
+	Direction Vec3
+	Angle     float32
+	Range     float32
+}
+
+// ContainsPoint3 reports whether the point lies within the cone.
+func (c *Cone) ContainsPoint3(p Point3) bool {
+	d := p.Sub(c.Apex)
+	dd := d.Dot(d)
+	if dd > c.Range*c.Range {
+		return false
+	}
+	_, cos := sincos(c.Angle)
+	return d.Dot(c.Direction) >= sqrt(dd)*cos
+}
+
+// Bounds returns the smallest AABB that contains the cone.
+func (c *Cone) Bounds() AABB {
+	sin, cos := sincos(c.Angle)
+	pmin, pmax := c.Apex, c.Apex
+
+	// The rim of the spherical cap is a circle around the axis
+	rim := c.Apex.Add(c.Direction.Mul(c.Range * cos))
+	rr := abs(c.Range * sin)
+	for i := 0; i < 3; i++ {
+		ext := rr * sqrt(max(1-c.Direction[i]*c.Direction[i], 0))
+		pmin[i] = min(pmin[i], rim[i]-ext)
+		pmax[i] = max(pmax[i], rim[i]+ext)
+
+		// The cap reaches further than the rim along any axis that lies within the cone
+		if c.Direction[i] >= cos {
+			pmax[i] = max(pmax[i], c.Apex[i]+c.Range)
+		}
+		if -c.Direction[i] >= cos {
+			pmin[i] = min(pmin[i], c.Apex[i]-c.Range)
+		}
+	}
+	return AABBFromCorners(pmin, pmax)
+}
+
+// ClosestPoint returns the point in the cone that is closest to p, which is p itself when it
+// lies inside the cone.
+func (c *Cone) ClosestPoint(p Point3) Point3 {
+	if c.ContainsPoint3(p) {
+		return p
+	}
+
+	// Work in the plane containing the axis and p, where the cone is a circular sector
+	d := p.Sub(c.Apex)
+	x := d.Dot(c.Direction)
+	radial := d.Sub(c.Direction.Mul(x))
+	y := radial.Len()
+	if y > 0 {
+		radial = radial.Mul(1 / y)
+	} else {
+		radial, _ = orthonormalBasis(c.Direction)
+	}
+
+	if atan2(y, x) <= c.Angle {
+		// Beyond the spherical cap
+		return c.Apex.Add(d.Normalize().Mul(c.Range))
+	}
+
+	// Outside the side of the cone, closest to the edge running from the apex to the rim
+	sin, cos := sincos(c.Angle)
+	s := Clamp(x*cos+y*sin, 0, c.Range)
+	return c.Apex.Add(c.Direction.Mul(s * cos)).Add(radial.Mul(s * sin))
+}
+
+// IntersectsSphere reports whether the sphere overlaps the cone.
+func (c *Cone) IntersectsSphere(s *Sphere) bool {
+	return DistanceSquared3(c.ClosestPoint(s.Position), s.Position) <= s.Radius*s.Radius
+}
+
+// IntersectsAABB reports whether the box overlaps the cone. Cones with an Angle over π/2 are
+// not convex and are treated as their convex hull, so a box in the hollow around the apex of
+// such a cone is reported as overlapping it.
+func (c *Cone) IntersectsAABB(a *AABB) bool {
+	return gjkIntersects(c.support, a.support, max(c.Range, 1)*1e-5)
+}
+
+// support returns the point of the cone that lies furthest in the direction d.
+func (c *Cone) support(d Vec3) Point3 {
+	l := d.Len()
+	if l == 0 {
+		return c.Apex
+	}
+	d = d.Mul(1 / l)
+	sin, cos := sincos(c.Angle)
+	dc := d.Dot(c.Direction)
+	if dc >= cos {
+		// Within the cone, so the furthest point is on the spherical cap
+		return c.Apex.Add(d.Mul(c.Range))
+	}
+
+	// Otherwise it is on the rim, on the side facing d, unless the apex is further still
+	radial := d.Sub(c.Direction.Mul(dc))
+	rl := radial.Len()
+	if rl == 0 || cos*dc+sin*rl <= 0 {
+		return c.Apex
+	}
+	return c.Apex.Add(c.Direction.Mul(c.Range * cos)).Add(radial.Mul(c.Range * sin / rl))
+}
+
+// Raycast tests whether the ray hits the surface of the cone. A ray that starts inside the
+// cone hits the surface on its way out. Enter and Exit bound the stretch of the line through
+// the ray that lies inside the cone around the hit. A cone with an Angle over π/2 is not convex
+// and the line may pass through it more than once, in which case other stretches are ignored.
+func (c *Cone) Raycast(ray Ray3) (RaycastResult, bool) {
+	var res RaycastResult
+
+	sin, cos := sincos(c.Angle)
+	o := ray.Origin.Sub(c.Apex)
+	v := ray.Direction
+
+	best := float32(maxFloat32)
+	var normal Vec3
+	// Every crossing of the surface along the whole line, including behind the origin
+	var crossings [4]float32
+	n := 0
+	consider := func(t float32, nt Vec3) {
+		crossings[n] = t
+		n++
+		if t >= 0 && t < best {
+			best, normal = t, nt
+		}
+	}
+
+	// The side of the cone satisfies (p·a)² = cos²θ |p|² on the half where p·a has the sign of cos θ
+	od, vd := o.Dot(c.Direction), v.Dot(c.Direction)
+	cc := cos * cos
+	qa := vd*vd - cc*v.Dot(v)
+	qb := od*vd - cc*o.Dot(v)
+	qc := od*od - cc*o.Dot(o)
+	side := func(t float32) {
+		p := o.Add(v.Mul(t))
+		pd := p.Dot(c.Direction)
+		if pd*cos < 0 || p.Dot(p) > c.Range*c.Range {
+			return
+		}
+		radial := p.Sub(c.Direction.Mul(pd))
+		if l := radial.Len(); l > 0 {
+			consider(t, radial.Mul(cos/l).Sub(c.Direction.Mul(sin)))
+		} else {
+			consider(t, c.Direction.Mul(-1))
+		}
+	}
+	if abs(qa) > epsilon32 {
+		if disc := qb*qb - qa*qc; disc >= 0 {
+			s := sqrt(disc)
+			side((-qb - s) / qa)
+			side((-qb + s) / qa)
+		}
+	} else if qb != 0 {
+		side(-qc / (2 * qb))
+	}
+
+	// The spherical cap
+	b := o.Dot(v)
+	if disc := b*b - (o.Dot(o) - c.Range*c.Range); disc >= 0 {
+		s := sqrt(disc)
+		for _, t := range [2]float32{-b - s, -b + s} {
+			p := o.Add(v.Mul(t))
+			if p.Dot(c.Direction) >= c.Range*cos-epsilon32 {
+				consider(t, p.Normalize())
+			}
+		}
+	}
+
+	if best == maxFloat32 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+
+	// The other end of the stretch is the nearest crossing beyond the hit, or before it when
+	// the ray starts inside. Crossings within tol of the hit are the same point found twice,
+	// such as where the side meets the cap.
+	tol := max(c.Range, 1) * 1e-5
+	res.Enter, res.Exit = best, best
+	inside := c.ContainsPoint3(ray.Origin)
+	for _, t := range crossings[:n] {
+		if inside {
+			if t < best-tol && (res.Enter == best || t > res.Enter) {
+				res.Enter = t
+			}
+		} else if t > best+tol && (res.Exit == best || t < res.Exit) {
+			res.Exit = t
+		}
+	}
+
+	res.Distance = best
+	res.Point = ray.Point(best)
+	res.Normal = normal.Normalize()
+	return res, true
+}
@@ -0,0 +1,196 @@
+package geom
+
+import "testing"
+
+func TestConeContainsPoint3(t *testing.T) {
+	// A guard at the origin looking along +x with a 90° field of view
+	c := Cone{Apex: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}, Angle: pi / 4, Range: 10}
+
+	testCases := []struct {
+		name string
+		p    Point3
+		want bool
+	}{
+		{name: "apex", p: Point3{0, 0, 0}, want: true},
+		{name: "ahead", p: Point3{5, 0, 0}, want: true},
+		{name: "inside edge", p: Point3{5, 0, 4.9}, want: true},
+		{name: "outside edge", p: Point3{5, 0, 5.1}, want: false},
+		{name: "behind", p: Point3{-1, 0, 0}, want: false},
+		{name: "out of range", p: Point3{9, 0, 4.5}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.ContainsPoint3(tc.p); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConeClosestPoint(t *testing.T) {
+	c := Cone{Apex: Point3{1, 1, 1}, Direction: Vec3{0, 1, 0}, Angle: pi / 4, Range: 4}
+
+	testCases := []struct {
+		name string
+		p    Point3
+		want Point3
+	}{
+		{name: "inside", p: Point3{1, 3, 1}, want: Point3{1, 3, 1}},
+		{name: "beyond cap", p: Point3{1, 10, 1}, want: Point3{1, 5, 1}},
+		{name: "beside", p: Point3{3, 1, 1}, want: Point3{2, 2, 1}},
+		{name: "behind", p: Point3{1, -2, 1}, want: Point3{1, 1, 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.ClosestPoint(tc.p); !nearVec3(got, tc.want, 1e-5) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConeIntersects(t *testing.T) {
+	c := Cone{Apex: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}, Angle: pi / 6, Range: 10}
+	sin30, cos30 := sincos(pi / 6)
+	tan30 := sin30 / cos30
+
+	if !c.IntersectsSphere(&Sphere{Position: Point3{5, 3.5, 0}, Radius: 1}) {
+		t.Errorf("IntersectsSphere: sphere overlapping the side should hit")
+	}
+	if c.IntersectsSphere(&Sphere{Position: Point3{5, 5, 0}, Radius: 1}) {
+		t.Errorf("IntersectsSphere: sphere beside the cone should miss")
+	}
+
+	testCases := []struct {
+		name string
+		box  AABB
+		want bool
+	}{
+		{name: "contains apex", box: AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}}, want: true},
+		{name: "on axis", box: AABB{Position: Point3{5, 0, 0}, Size: Vec3{0.5, 0.5, 0.5}}, want: true},
+		{name: "behind", box: AABB{Position: Point3{-5, 0, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		{name: "out of range", box: AABB{Position: Point3{12, 0, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		{name: "beside", box: AABB{Position: Point3{5, 5, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		// A large box whose face cuts into the side of the cone without any of its edges or
+		// corners touching it
+		{name: "face grazes side", box: AABB{Position: Point3{5, 22.5, 0}, Size: Vec3{20, 20, 20}}, want: true},
+		// Boxes that touch the cone at a single point, and the same boxes moved just clear
+		{name: "corner touches side", box: AABB{Position: Point3{4, 5*tan30 + 1, 0}, Size: Vec3{1, 1, 1}}, want: true},
+		{name: "corner clears side", box: AABB{Position: Point3{4, 5*tan30 + 1.001, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		{name: "face touches cap", box: AABB{Position: Point3{11, 0, 0}, Size: Vec3{1, 1, 1}}, want: true},
+		{name: "face clears cap", box: AABB{Position: Point3{11.001, 0, 0}, Size: Vec3{1, 1, 1}}, want: false},
+		{name: "corner touches rim", box: AABB{Position: Point3{10*cos30 + 1, 6, 0}, Size: Vec3{1, 1, 1}}, want: true},
+		{name: "corner clears rim", box: AABB{Position: Point3{10*cos30 + 1, 6.001, 0}, Size: Vec3{1, 1, 1}}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.IntersectsAABB(&tc.box); got != tc.want {
+				t.Errorf("IntersectsAABB: got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+	// The side of the cone crosses the bottom face of a long box at a shallow angle, first so
+	// that a thin wedge of the cone pokes into the box and then so that the rim passes just
+	// below it. Closest points converge slowly between shapes that meet like this.
+	slab := AABB{Position: Point3{0, 1, 0}, Size: Vec3{20, 1, 1}}
+	for _, alpha := range []float32{0.1, 0.01, 0.001} {
+		sin, cos := sincos(alpha - pi/6)
+		dir := Vec3{cos, sin, 0}
+		sa, ca := sincos(alpha)
+		rise := 10 * sa / ca // height of the rim above the apex
+		overlap := Cone{Apex: Point3{0, -rise / 2, 0}, Direction: dir, Angle: pi / 6, Range: 10}
+		if !overlap.IntersectsAABB(&slab) {
+			t.Errorf("IntersectsAABB: cone crossing the face at angle %v should hit", alpha)
+		}
+		below := Cone{Apex: Point3{0, -rise - 0.01, 0}, Direction: dir, Angle: pi / 6, Range: 10}
+		if below.IntersectsAABB(&slab) {
+			t.Errorf("IntersectsAABB: cone just below the face at angle %v should miss", alpha)
+		}
+	}
+}
+
+func TestConeRaycast(t *testing.T) {
+	c := Cone{Apex: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}, Angle: pi / 4, Range: 10}
+	s2 := sqrt(2) / 2
+
+	testCases := []struct {
+		name       string
+		ray        Ray3
+		wantOK     bool
+		wantDist   float32
+		wantNormal Vec3
+		wantEnter  float32
+		wantExit   float32
+	}{
+		{
+			name:       "cap",
+			ray:        Ray3{Origin: Point3{20, 0, 0}, Direction: Vec3{-1, 0, 0}},
+			wantOK:     true,
+			wantDist:   10,
+			wantNormal: Vec3{1, 0, 0},
+			wantEnter:  10,
+			wantExit:   20,
+		},
+		{
+			name:       "cap then side",
+			ray:        Ray3{Origin: Point3{20, 6, 0}, Direction: Vec3{-1, 0, 0}},
+			wantOK:     true,
+			wantDist:   12,
+			wantNormal: Vec3{0.8, 0.6, 0},
+			wantEnter:  12,
+			wantExit:   14,
+		},
+		{
+			name:       "side",
+			ray:        Ray3{Origin: Point3{5, 10, 0}, Direction: Vec3{0, -1, 0}},
+			wantOK:     true,
+			wantDist:   5,
+			wantNormal: Vec3{-s2, s2, 0},
+			wantEnter:  5,
+			wantExit:   15,
+		},
+		{
+			name:       "exit from inside",
+			ray:        Ray3{Origin: Point3{5, 0, 0}, Direction: Vec3{0, 0, 1}},
+			wantOK:     true,
+			wantDist:   5,
+			wantNormal: Vec3{-s2, 0, s2},
+			wantEnter:  -5,
+			wantExit:   5,
+		},
+		{
+			name:   "miss behind",
+			ray:    Ray3{Origin: Point3{-5, 10, 0}, Direction: Vec3{0, -1, 0}},
+			wantOK: false,
+		},
+		{
+			name:   "pointing away",
+			ray:    Ray3{Origin: Point3{5, 10, 0}, Direction: Vec3{0, 1, 0}},
+			wantOK: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := c.Raycast(tc.ray)
+			if ok != tc.wantOK {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.wantOK)
+			}
+			if !ok {
+				if res.Fail != RaycastFailOutsideBounds {
+					t.Errorf("got fail %v, wanted RaycastFailOutsideBounds", res.Fail)
+				}
+				return
+			}
+			if abs(res.Distance-tc.wantDist) > 1e-4 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.wantDist)
+			}
+			if !nearVec3(res.Normal, tc.wantNormal, 1e-4) {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.wantNormal)
+			}
+			// Rays through the apex meet the side at a double root, which loses precision
+			if abs(res.Enter-tc.wantEnter) > 1e-2 || abs(res.Exit-tc.wantExit) > 1e-2 {
+				t.Errorf("got enter %v and exit %v, wanted %v and %v", res.Enter, res.Exit, tc.wantEnter, tc.wantExit)
+			}
+		})
+	}
+}
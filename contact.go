@@ -0,0 +1,143 @@
+package geom
+
+// ContactPoint is a point of contact between two objects along with the impulses a solver has
+// accumulated for it. Carrying the impulses over from one frame to the next lets the solver
+// start from the previous solution, which is what keeps stacks of objects stable.
+type ContactPoint struct {
+	Position Point3  // point of contact in world space
+	Normal   Vec3    // contact normal, pointing from the first object of the pair to the second
+	Depth    float32 // penetration depth
+
+	// Feature identifies the features of the two objects that are in contact, such as a
+	// vertex against a face. Points with the same non-zero feature in consecutive frames are
+	// taken to be the same contact. Zero means the feature is unknown.
+	Feature uint64
+
+	NormalImpulse  float32 // accumulated impulse along the normal
+	TangentImpulse Vec2    // accumulated friction impulse along two tangent directions
+}
+
+// ContactCache keeps the contact points of each pair of objects from one frame to the next so
+// that accumulated impulses can be used to warm start a solver.
+//
+// Each frame the narrowphase passes the new contacts for a pair to Update, which matches them
+// with the points cached from the previous frame and carries over their impulses. After the
+// solver has run, the impulses it writes into the returned points are kept for the next frame.
+// Prune then forgets the pairs that were not updated.
+type ContactCache struct {
+	pairs     map[Pair]*contactEntry
+	tolerance float32
+}
+
+type contactEntry struct {
+	points []ContactPoint
+	seen   bool // whether the pair was updated since the last prune
+}
+
+// NewContactCache returns an empty cache. Contact points without a feature are matched with
+// the nearest cached point within tolerance of their position.
+func NewContactCache(tolerance float32) *ContactCache {
+	return &ContactCache{
+		pairs:     make(map[Pair]*contactEntry),
+		tolerance: tolerance,
+	}
+}
+
+// Update replaces the contact points of the pair of objects a and b and returns the cached
+// copy of them. Each point takes the impulses of the point from the previous update that it
+// matches, either by feature or, when it has no feature, by position. Unmatched points keep
+// the impulses they were given. Each previous point is matched at most once.
+//
+// The order of a and b is ignored, so the normals of a pair should always be reported in the
+// same sense. The returned slice is owned by the cache and remains valid until the next update
+// of the pair, so a solver may store accumulated impulses in it directly.
+func (c *ContactCache) Update(a, b int, contacts []ContactPoint) []ContactPoint {
+	p := makePair(a, b)
+	e, ok := c.pairs[p]
+	if !ok {
+		e = &contactEntry{}
+		c.pairs[p] = e
+	}
+	e.seen = true
+
+	old := e.points
+	points := make([]ContactPoint, len(contacts))
+	copy(points, contacts)
+	used := make([]bool, len(old))
+
+	// Features are matched first so that a point with no feature cannot take a previous
+	// point that has a better match
+	for i := range points {
+		if points[i].Feature == 0 {
+			continue
+		}
+		for j := range old {
+			if !used[j] && old[j].Feature == points[i].Feature {
+				points[i].NormalImpulse = old[j].NormalImpulse
+				points[i].TangentImpulse = old[j].TangentImpulse
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	tolSq := c.tolerance * c.tolerance
+	for i := range points {
+		if points[i].Feature != 0 {
+			continue
+		}
+		best := -1
+		bestDist := tolSq
+		for j := range old {
+			if used[j] {
+				continue
+			}
+			if d := old[j].Position.Sub(points[i].Position).LenSqr(); d <= bestDist {
+				best, bestDist = j, d
+			}
+		}
+		if best >= 0 {
+			points[i].NormalImpulse = old[best].NormalImpulse
+			points[i].TangentImpulse = old[best].TangentImpulse
+			used[best] = true
+		}
+	}
+
+	e.points = points
+	return points
+}
+
+// Contacts returns the cached contact points of the pair of objects a and b, or nil if there
+// are none. The order of a and b is ignored.
+func (c *ContactCache) Contacts(a, b int) []ContactPoint {
+	if e, ok := c.pairs[makePair(a, b)]; ok {
+		return e.points
+	}
+	return nil
+}
+
+// Remove forgets the contact points of the pair of objects a and b.
+func (c *ContactCache) Remove(a, b int) {
+	delete(c.pairs, makePair(a, b))
+}
+
+// Prune forgets the pairs that have not been updated since the previous prune and returns how
+// many were removed. It is normally called once a frame after every pair in contact has been
+// updated.
+func (c *ContactCache) Prune() int {
+	n := 0
+	for p, e := range c.pairs {
+		if !e.seen {
+			delete(c.pairs, p)
+			n++
+			continue
+		}
+		e.seen = false
+	}
+	return n
+}
+
+// Len returns the number of pairs in the cache.
+func (c *ContactCache) Len() int {
+	return len(c.pairs)
+}
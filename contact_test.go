@@ -0,0 +1,55 @@
+package geom
+
+import "testing"
+
+func TestContactCache(t *testing.T) {
+	c := NewContactCache(0.1)
+	up := Vec3{0, 1, 0}
+
+	pts := c.Update(1, 2, []ContactPoint{
+		{Position: Point3{0, 0, 0}, Normal: up, Feature: 7},
+		{Position: Point3{1, 0, 0}, Normal: up},
+		{Position: Point3{2, 0, 0}, Normal: up},
+	})
+	for i := range pts {
+		if pts[i].NormalImpulse != 0 {
+			t.Fatalf("point %d starts with impulse %v", i, pts[i].NormalImpulse)
+		}
+		// The solver stores its impulses in the cached points
+		pts[i].NormalImpulse = float32(i + 1)
+		pts[i].TangentImpulse = Vec2{float32(i + 1), 0}
+	}
+
+	// The same contacts a frame later, having moved a little and been reported in a different
+	// order for the reversed pair
+	pts = c.Update(2, 1, []ContactPoint{
+		{Position: Point3{2.05, 0, 0}, Normal: up},
+		{Position: Point3{5, 0, 0}, Normal: up, Feature: 7},
+		{Position: Point3{1.5, 0, 0}, Normal: up},
+		{Position: Point3{0.95, 0, 0.05}, Normal: up},
+	})
+	want := []float32{3, 1, 0, 2}
+	for i := range pts {
+		if pts[i].NormalImpulse != want[i] || pts[i].TangentImpulse[0] != want[i] {
+			t.Errorf("point %d: got impulses %v and %v, wanted %v", i, pts[i].NormalImpulse, pts[i].TangentImpulse, want[i])
+		}
+	}
+	if got := c.Contacts(1, 2); len(got) != 4 || &got[0] != &pts[0] {
+		t.Errorf("Contacts: got %v", got)
+	}
+
+	// A pair that is not updated is pruned
+	c.Update(3, 4, []ContactPoint{{Position: Point3{9, 9, 9}}})
+	if n := c.Prune(); n != 0 || c.Len() != 2 {
+		t.Fatalf("first prune: removed %d leaving %d, wanted 0 leaving 2", n, c.Len())
+	}
+	c.Update(1, 2, pts)
+	if n := c.Prune(); n != 1 || c.Len() != 1 || c.Contacts(3, 4) != nil {
+		t.Fatalf("second prune: removed %d leaving %d, wanted 1 leaving 1", n, c.Len())
+	}
+
+	c.Remove(2, 1)
+	if c.Len() != 0 {
+		t.Errorf("got %d pairs after remove, wanted 0", c.Len())
+	}
+}
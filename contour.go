@@ -0,0 +1,143 @@
+package geom
+
+// ContourOptions controls how Contour traces the boundaries of a grid.
+type ContourOptions struct {
+	// Level is the value at the boundary. Regions where the grid holds values greater than
+	// Level are inside the contours.
+	Level float32
+
+	// Tolerance, when greater than zero, simplifies the contours by removing points so that
+	// none is further than Tolerance from the simplified contour. Without simplification each
+	// contour has a point on every cell edge it crosses.
+	Tolerance float32
+}
+
+// Contour traces the boundaries of the regions of the grid whose values are greater than
+// opts.Level using marching squares. Values are taken to be sampled at the centres of the cells
+// and the boundary is placed by interpolating between neighbouring samples. Regions that reach
+// the edge of the grid are closed along its outer edge. The boundary of each polygon is counter
+// clockwise and the boundaries of its holes are clockwise.
+func Contour(g *Grid2[float32], opts ContourOptions) []Polygon2 {
+	return traceContours(g.Dims, g.Origin, g.CellSize, func(x, y int) float32 { return g.At(x, y) }, opts)
+}
+
+// ContourOccupancy traces the boundaries of the regions of set cells in the grid, cutting
+// across the corners of the cells to give a smoother outline than the cell edges. Diagonal
+// steps in the outline are only removed by simplification, so a tolerance of around half a
+// cell gives straight edges along walls built from cells. The boundary of each polygon is
+// counter clockwise and the boundaries of its holes are clockwise.
+func ContourOccupancy(g *Grid2[bool], tolerance float32) []Polygon2 {
+	value := func(x, y int) float32 {
+		if g.At(x, y) {
+			return 1
+		}
+		return 0
+	}
+	return traceContours(g.Dims, g.Origin, g.CellSize, value, ContourOptions{Level: 0.5, Tolerance: tolerance})
+}
+
+// traceContours runs marching squares over the squares joining the centres of neighbouring
+// cells. The grid is surrounded by a ring of samples that are outside every region so that the
+// contours are closed.
+func traceContours(dims [2]int, origin Point2, cellSize float32, value func(x, y int) float32, opts ContourOptions) []Polygon2 {
+	nx, ny := dims[0], dims[1]
+	// Samples are numbered including the surrounding ring, from -1 to n along each axis
+	sx := nx + 2
+	sample := func(x, y int) (float32, bool) {
+		if x < 0 || y < 0 || x >= nx || y >= ny {
+			return 0, false
+		}
+		return value(x, y), true
+	}
+	centre := func(x, y int) Point2 {
+		return Point2{origin[0] + (float32(x)+0.5)*cellSize, origin[1] + (float32(y)+0.5)*cellSize}
+	}
+
+	// Each crossing of the boundary lies on the edge between two samples, which is identified
+	// by the lower sample and whether the edge runs along x or y. next links each crossing to
+	// the following crossing along its contour.
+	edgeID := func(x, y, axis int) int { return 2*((x+1)+sx*(y+1)) + axis }
+	next := make([]int32, 2*sx*(ny+2))
+	for i := range next {
+		next[i] = -1
+	}
+	points := make([]Point2, len(next))
+
+	var corners [4][2]int
+	var vals [4]float32
+	var real, inside [4]bool
+	var crossings [4]int
+	for y := -1; y < ny; y++ {
+		for x := -1; x < nx; x++ {
+			// Corners and edges counter clockwise from the lower left
+			corners = [4][2]int{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+			edges := [4]int{edgeID(x, y, 0), edgeID(x+1, y, 1), edgeID(x, y+1, 0), edgeID(x, y, 1)}
+			n := 0
+			for k, c := range corners {
+				vals[k], real[k] = sample(c[0], c[1])
+				inside[k] = real[k] && vals[k] > opts.Level
+				if inside[k] {
+					n++
+				}
+			}
+			if n == 0 || n == 4 {
+				continue
+			}
+
+			nc := 0
+			for k := 0; k < 4; k++ {
+				j := (k + 1) % 4
+				if inside[k] == inside[j] {
+					continue
+				}
+				crossings[nc] = k
+				nc++
+
+				// Place the crossing between the samples, or on the edge of the grid when one
+				// of them is outside it
+				a, b := corners[k], corners[j]
+				t := float32(0.5)
+				if real[k] && real[j] {
+					t = (opts.Level - vals[k]) / (vals[j] - vals[k])
+				}
+				pa, pb := centre(a[0], a[1]), centre(b[0], b[1])
+				points[edges[k]] = pa.Add(pb.Sub(pa).Mul(t))
+			}
+
+			// Each crossing leaving the region joins the next crossing entering it, keeping
+			// the inside on the left. At a saddle the centre decides whether the inside
+			// corners are joined.
+			step := 1
+			if nc == 4 && (vals[0]+vals[1]+vals[2]+vals[3])/4 <= opts.Level {
+				step = nc - 1
+			}
+			for i := 0; i < nc; i++ {
+				k := crossings[i]
+				if inside[k] {
+					next[edges[k]] = int32(edges[crossings[(i+step)%nc]])
+				}
+			}
+		}
+	}
+
+	var rings [][]Point2
+	for start := range next {
+		if next[start] < 0 {
+			continue
+		}
+		var ring []Point2
+		for e := start; next[e] >= 0; {
+			ring = append(ring, points[e])
+			n := int(next[e])
+			next[e] = -1
+			e = n
+		}
+		if opts.Tolerance > 0 {
+			ring = simplifyRing2(ring, opts.Tolerance)
+		}
+		if len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return polygonsFromRings(rings)
+}
@@ -0,0 +1,124 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+// occupancyGrid2 returns a grid with cells set where rows hold '#', with the first row at
+// y = 0.
+func occupancyGrid2(rows ...string) *Grid2[bool] {
+	g := NewGrid2[bool](Point2{}, 1, len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			g.Set(x, y, row[x] == '#')
+		}
+	}
+	return g
+}
+
+func TestContourOccupancy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		g         *Grid2[bool]
+		tolerance float32
+		areas     []float32 // of each polygon, including its holes
+		holes     int
+		points    int // total points in the outer boundaries, if not zero
+	}{
+		{
+			name:   "single cell",
+			g:      occupancyGrid2("#"),
+			areas:  []float32{0.5},
+			points: 4,
+		},
+		{
+			name:      "block",
+			g:         occupancyGrid2("....", ".###", ".###"),
+			tolerance: 0.01,
+			areas:     []float32{6 - 4*0.125},
+			points:    8,
+		},
+		{
+			name:  "two blobs",
+			g:     occupancyGrid2("##..", "....", "..##"),
+			areas: []float32{2 - 4*0.125, 2 - 4*0.125},
+		},
+		{
+			name:  "ring",
+			g:     occupancyGrid2("###", "#.#", "###"),
+			areas: []float32{9 - 4*0.125 - 0.5},
+			holes: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ContourOccupancy(tc.g, tc.tolerance)
+			if len(got) != len(tc.areas) {
+				t.Fatalf("got %d polygons, wanted %d: %v", len(got), len(tc.areas), got)
+			}
+			holes, points := 0, 0
+			for i := range got {
+				if ringSignedArea(got[i].Points) <= 0 {
+					t.Errorf("polygon %d is not counter clockwise", i)
+				}
+				for _, h := range got[i].Holes {
+					if ringSignedArea(h) >= 0 {
+						t.Errorf("hole in polygon %d is not clockwise", i)
+					}
+				}
+				if a := got[i].Area(); abs(a-tc.areas[i]) > 1e-5 {
+					t.Errorf("polygon %d: got area %v, wanted %v", i, a, tc.areas[i])
+				}
+				holes += len(got[i].Holes)
+				points += len(got[i].Points)
+			}
+			if holes != tc.holes {
+				t.Errorf("got %d holes, wanted %d", holes, tc.holes)
+			}
+			if tc.points != 0 && points != tc.points {
+				t.Errorf("got %d points, wanted %d: %v", points, tc.points, got)
+			}
+		})
+	}
+}
+
+func TestContour(t *testing.T) {
+	// A disc of radius 5 sampled as the distance inside its edge
+	const radius = 5
+	g := NewGrid2[float32](Point2{-8, -8}, 0.5, 32, 32)
+	for y := 0; y < g.Dims[1]; y++ {
+		for x := 0; x < g.Dims[0]; x++ {
+			g.Set(x, y, radius-g.CellCentre(x, y).Len())
+		}
+	}
+
+	got := Contour(g, ContourOptions{})
+	if len(got) != 1 || len(got[0].Holes) != 0 {
+		t.Fatalf("got %v, wanted one polygon", got)
+	}
+	for _, p := range got[0].Points {
+		if d := p.Len(); abs(d-radius) > 0.02 {
+			t.Fatalf("point %v is %v from the centre, wanted %v", p, d, radius)
+		}
+	}
+	if a, want := got[0].Area(), float32(math.Pi*radius*radius); abs(a-want) > 0.01*want {
+		t.Errorf("got area %v, wanted %v", a, want)
+	}
+
+	// Simplification keeps the shape with fewer points
+	simple := Contour(g, ContourOptions{Tolerance: 0.05})
+	if len(simple) != 1 || len(simple[0].Points) >= len(got[0].Points) {
+		t.Fatalf("simplified: got %v", simple)
+	}
+	if a, want := simple[0].Area(), got[0].Area(); abs(a-want) > 0.02*want {
+		t.Errorf("simplified: got area %v, wanted %v", a, want)
+	}
+
+	// A higher level shrinks the disc
+	inner := Contour(g, ContourOptions{Level: 2})
+	if len(inner) != 1 || abs(inner[0].Points[0].Len()-3) > 0.02 {
+		t.Errorf("level 2: got %v", inner)
+	}
+}
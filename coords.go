@@ -0,0 +1,201 @@
+package geom
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// UpAxis is the axis that points up in a coordinate system.
+type UpAxis int
+
+const (
+	// YUp means the Y axis points up. This is the convention used by this package.
+	YUp UpAxis = iota
+
+	// ZUp means the Z axis points up.
+	ZUp
+)
+
+func (a UpAxis) String() string {
+	switch a {
+	case YUp:
+		return "y-up"
+	case ZUp:
+		return "z-up"
+	default:
+		return "unknown"
+	}
+}
+
+// Handedness describes whether a coordinate system is right or left handed.
+type Handedness int
+
+const (
+	// RightHanded means the Z axis is the cross product of the X and Y axes. This is the
+	// convention used by this package.
+	RightHanded Handedness = iota
+
+	// LeftHanded means the Z axis points opposite to the cross product of the X and Y axes.
+	LeftHanded
+)
+
+func (h Handedness) String() string {
+	switch h {
+	case RightHanded:
+		return "right-handed"
+	case LeftHanded:
+		return "left-handed"
+	default:
+		return "unknown"
+	}
+}
+
+// CoordinateSystem describes the axis conventions of a source of geometry. The zero value is
+// the Y up, right handed system used by this package.
+type CoordinateSystem struct {
+	Up         UpAxis
+	Handedness Handedness
+}
+
+var (
+	// YUpRightHanded is the coordinate system used by this package, OpenGL and glTF.
+	YUpRightHanded = CoordinateSystem{Up: YUp, Handedness: RightHanded}
+
+	// ZUpRightHanded is the coordinate system used by Blender and 3ds Max.
+	ZUpRightHanded = CoordinateSystem{Up: ZUp, Handedness: RightHanded}
+
+	// YUpLeftHanded is the coordinate system used by Unity and Direct3D.
+	YUpLeftHanded = CoordinateSystem{Up: YUp, Handedness: LeftHanded}
+
+	// ZUpLeftHanded is the coordinate system used by Unreal Engine.
+	ZUpLeftHanded = CoordinateSystem{Up: ZUp, Handedness: LeftHanded}
+)
+
+func (s CoordinateSystem) String() string {
+	return s.Up.String() + " " + s.Handedness.String()
+}
+
+// toYUpRightHanded returns the matrix that converts coordinates in s into the Y up, right
+// handed system. The X axis is shared by every system. Z up systems have their Y and Z axes
+// exchanged and left handed systems have their remaining horizontal axis reversed.
+func (s CoordinateSystem) toYUpRightHanded() Mat3 {
+	switch {
+	case s.Up == ZUp && s.Handedness == LeftHanded:
+		return mgl32.Mat3FromRows(Vec3{1, 0, 0}, Vec3{0, 0, 1}, Vec3{0, 1, 0})
+	case s.Up == ZUp:
+		return mgl32.Mat3FromRows(Vec3{1, 0, 0}, Vec3{0, 0, 1}, Vec3{0, -1, 0})
+	case s.Handedness == LeftHanded:
+		return mgl32.Mat3FromRows(Vec3{1, 0, 0}, Vec3{0, 1, 0}, Vec3{0, 0, -1})
+	default:
+		return mgl32.Ident3()
+	}
+}
+
+// CoordinateConversion converts geometry from one coordinate system to another. The axes of
+// the two systems differ only by a permutation and a change of sign, so conversions are exact.
+// When the handedness changes the conversion mirrors the geometry, and shapes made of
+// triangles have their winding reversed so that their front faces still face outwards.
+type CoordinateConversion struct {
+	m Mat3 // converts source coordinates to destination coordinates
+}
+
+// NewCoordinateConversion returns the conversion from coordinates in from to coordinates in to.
+func NewCoordinateConversion(from, to CoordinateSystem) CoordinateConversion {
+	// The matrices are signed permutations, so each inverse is a transpose
+	return CoordinateConversion{m: to.toYUpRightHanded().Transpose().Mul3(from.toYUpRightHanded())}
+}
+
+// Mirrors reports whether the conversion changes handedness.
+func (c CoordinateConversion) Mirrors() bool {
+	return c.m.Det() < 0
+}
+
+// Matrix returns the conversion as a transformation matrix.
+func (c CoordinateConversion) Matrix() Mat4 {
+	return c.m.Mat4()
+}
+
+// Inverse returns the conversion back to the source coordinate system.
+func (c CoordinateConversion) Inverse() CoordinateConversion {
+	return CoordinateConversion{m: c.m.Transpose()}
+}
+
+// Vec3 converts a point or direction.
+func (c CoordinateConversion) Vec3(v Vec3) Vec3 {
+	return c.m.Mul3x1(v)
+}
+
+// size converts a vector of extents along each axis, which are permuted but keep their sign.
+func (c CoordinateConversion) size(v Vec3) Vec3 {
+	var r Vec3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i] += abs(c.m.At(i, j)) * v[j]
+		}
+	}
+	return r
+}
+
+// Quat converts a rotation. The axis of the rotation is converted and, when the conversion
+// mirrors, the sense of the rotation is reversed so that it moves converted points to the
+// converted positions.
+func (c CoordinateConversion) Quat(q Quat) Quat {
+	v := c.m.Mul3x1(q.V)
+	if c.Mirrors() {
+		v = v.Mul(-1)
+	}
+	return Quat{W: q.W, V: v}
+}
+
+// Mat4 converts a transformation matrix so that it acts on converted coordinates in the same
+// way that m acts on the source coordinates.
+func (c CoordinateConversion) Mat4(m Mat4) Mat4 {
+	return c.m.Mat4().Mul4(m).Mul4(c.m.Transpose().Mat4())
+}
+
+// Transform converts a transform, keeping its axis convention. The scale along each local
+// axis moves to the converted axis.
+func (c CoordinateConversion) Transform(t *Transform) Transform {
+	r := NewTransform()
+	r.SetConvention(t.Convention())
+	r.SetPosition(c.Vec3(t.Pos()))
+	r.SetOrientation(c.Quat(t.Orientation()))
+	r.SetScale(c.size(t.Scale()))
+	return r
+}
+
+// AABB converts an axis aligned box.
+func (c CoordinateConversion) AABB(a AABB) AABB {
+	return AABB{Position: c.Vec3(a.Position), Size: c.size(a.Size)}
+}
+
+// OBB converts an oriented box.
+func (c CoordinateConversion) OBB(o *OBB) OBB {
+	return NewOBB(c.Vec3(o.Position), c.size(o.Size), c.Quat(o.Orientation))
+}
+
+// Sphere converts a sphere.
+func (c CoordinateConversion) Sphere(s Sphere) Sphere {
+	return Sphere{Position: c.Vec3(s.Position), Radius: s.Radius}
+}
+
+// Plane3 converts a plane.
+func (c CoordinateConversion) Plane3(p Plane3) Plane3 {
+	return Plane3{Normal: c.Vec3(p.Normal), Distance: p.Distance}
+}
+
+// Ray3 converts a ray.
+func (c CoordinateConversion) Ray3(r Ray3) Ray3 {
+	return Ray3{Origin: c.Vec3(r.Origin), Direction: c.Vec3(r.Direction)}
+}
+
+// Tri3 converts a triangle, reversing its winding if the conversion mirrors.
+func (c CoordinateConversion) Tri3(t Tri3) Tri3 {
+	if c.Mirrors() {
+		return Tri3{A: c.Vec3(t.A), B: c.Vec3(t.C), C: c.Vec3(t.B)}
+	}
+	return Tri3{A: c.Vec3(t.A), B: c.Vec3(t.B), C: c.Vec3(t.C)}
+}
+
+// TriMesh converts a mesh in place, reversing the winding of its triangles if the conversion
+// mirrors.
+func (c CoordinateConversion) TriMesh(m *TriMesh) {
+	m.TransformMat4(c.Matrix())
+}
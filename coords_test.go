@@ -0,0 +1,91 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCoordinateConversion(t *testing.T) {
+	systems := []CoordinateSystem{YUpRightHanded, ZUpRightHanded, YUpLeftHanded, ZUpLeftHanded}
+
+	// The up direction of each system and a point expressed in it, along with that point in
+	// this package's coordinates
+	ups := map[CoordinateSystem]Vec3{
+		YUpRightHanded: {0, 1, 0},
+		ZUpRightHanded: {0, 0, 1},
+		YUpLeftHanded:  {0, 1, 0},
+		ZUpLeftHanded:  {0, 0, 1},
+	}
+	points := map[CoordinateSystem]Vec3{
+		YUpRightHanded: {1, 2, 3},
+		ZUpRightHanded: {1, -3, 2},
+		YUpLeftHanded:  {1, 2, -3},
+		ZUpLeftHanded:  {1, 3, 2},
+	}
+
+	q := mgl32.QuatRotate(0.8, Vec3{1, -2, 3}.Normalize())
+	tx := NewTransform()
+	tx.SetPosition(Vec3{4, 5, 6})
+	tx.SetOrientation(q)
+	tx.SetScale(Vec3{1, 2, 3})
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{1, 0, 0}, C: Point3{0, 1, 1}}
+	obb := NewOBB(Point3{1, 2, 3}, Vec3{1, 2, 0.5}, q)
+
+	for _, from := range systems {
+		for _, to := range systems {
+			t.Run(from.String()+" to "+to.String(), func(t *testing.T) {
+				c := NewCoordinateConversion(from, to)
+				if c.Mirrors() != (from.Handedness != to.Handedness) {
+					t.Errorf("got Mirrors %v", c.Mirrors())
+				}
+				if got := c.Vec3(ups[from]); got != ups[to] {
+					t.Errorf("up converts to %v, wanted %v", got, ups[to])
+				}
+				if got := c.Vec3(points[from]); got != points[to] {
+					t.Errorf("point converts to %v, wanted %v", got, points[to])
+				}
+				if got := c.Inverse().Vec3(c.Vec3(points[from])); got != points[from] {
+					t.Errorf("inverse gives %v, wanted %v", got, points[from])
+				}
+
+				// Rotations and transforms act on converted points as the originals act on
+				// the source points
+				if got, want := c.Quat(q).Mat4(), c.Mat4(q.Mat4()); !got.ApproxEqualThreshold(want, 1e-5) {
+					t.Errorf("quat converts to %v, wanted %v", got, want)
+				}
+				ct := c.Transform(&tx)
+				if got, want := ct.Matrix(), c.Mat4(tx.Matrix()); !got.ApproxEqualThreshold(want, 1e-4) {
+					t.Errorf("transform converts to %v, wanted %v", got, want)
+				}
+
+				// The converted triangle faces the converted direction
+				ctri := c.Tri3(tri)
+				if got, want := ctri.Normal(), c.Vec3(tri.Normal()); !nearVec3(got, want, 1e-6) {
+					t.Errorf("triangle normal converts to %v, wanted %v", got, want)
+				}
+
+				// Every converted corner of the box is a corner of the converted box
+				cobb := c.OBB(&obb)
+				for _, p := range obb.Corners() {
+					cp := c.Vec3(p)
+					found := false
+					for _, q := range cobb.Corners() {
+						found = found || nearVec3(cp, q, 1e-5)
+					}
+					if !found {
+						t.Fatalf("corner %v converts to %v, which is not a corner of %v", p, cp, cobb)
+					}
+				}
+				box := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}}
+				cbox := c.AABB(box)
+				if got, want := cbox.Max().Sub(cbox.Min()), c.size(box.Max().Sub(box.Min())); got != want {
+					t.Errorf("box extent converts to %v, wanted %v", got, want)
+				}
+				if !cbox.ContainsPoint3(c.Vec3(box.Min())) || !cbox.ContainsPoint3(c.Vec3(box.Max())) {
+					t.Errorf("converted box %v does not contain the converted corners", cbox)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package geom
+
+// Distance3 returns the distance between two points.
+func Distance3(a, b Point3) float32 {
+	return sqrt(DistanceSquared3(a, b))
+}
+
+// DistanceSquaredPointLine3 returns the squared distance between p and the closest point on
+// the line segment l.
+func DistanceSquaredPointLine3(p Point3, l *Line3) float32 {
+	return DistanceSquared3(p, l.ClosestPoint(p))
+}
+
+// DistancePointLine3 returns the distance between p and the closest point on the line
+// segment l.
+func DistancePointLine3(p Point3, l *Line3) float32 {
+	return sqrt(DistanceSquaredPointLine3(p, l))
+}
+
+// DistanceSquaredPointAABB returns the squared distance between p and the closest point in
+// the box. The distance is zero when p is inside the box.
+func DistanceSquaredPointAABB(p Point3, a *AABB) float32 {
+	var d float32
+	pmin, pmax := a.Min(), a.Max()
+	for i := 0; i < 3; i++ {
+		if p[i] < pmin[i] {
+			d += (pmin[i] - p[i]) * (pmin[i] - p[i])
+		} else if p[i] > pmax[i] {
+			d += (p[i] - pmax[i]) * (p[i] - pmax[i])
+		}
+	}
+	return d
+}
+
+// DistancePointAABB returns the distance between p and the closest point in the box. The
+// distance is zero when p is inside the box.
+func DistancePointAABB(p Point3, a *AABB) float32 {
+	return sqrt(DistanceSquaredPointAABB(p, a))
+}
+
+// DistanceSquaredPointOBB returns the squared distance between p and the closest point in
+// the box. The distance is zero when p is inside the box.
+func DistanceSquaredPointOBB(p Point3, o *OBB) float32 {
+	return DistanceSquared3(p, o.ClosestPoint(p))
+}
+
+// DistancePointOBB returns the distance between p and the closest point in the box. The
+// distance is zero when p is inside the box.
+func DistancePointOBB(p Point3, o *OBB) float32 {
+	return sqrt(DistanceSquaredPointOBB(p, o))
+}
+
+// DistanceSquaredPointTri3 returns the squared distance between p and the closest point on
+// the triangle.
+func DistanceSquaredPointTri3(p Point3, t Tri3) float32 {
+	return DistanceSquared3(p, t.ClosestPoint(p))
+}
+
+// DistancePointTri3 returns the distance between p and the closest point on the triangle.
+func DistancePointTri3(p Point3, t Tri3) float32 {
+	return sqrt(DistanceSquaredPointTri3(p, t))
+}
+
+// SignedDistancePointPlane3 returns the distance between p and the plane. The distance is
+// positive when p is on the side the plane's normal faces and negative when it is behind.
+func SignedDistancePointPlane3(p Point3, pl *Plane3) float32 {
+	return pl.Normal.Dot(p) - pl.Distance
+}
+
+// DistanceSquaredPointPlane3 returns the squared distance between p and the plane.
+func DistanceSquaredPointPlane3(p Point3, pl *Plane3) float32 {
+	d := SignedDistancePointPlane3(p, pl)
+	return d * d
+}
+
+// DistancePointPlane3 returns the distance between p and the plane.
+func DistancePointPlane3(p Point3, pl *Plane3) float32 {
+	return abs(SignedDistancePointPlane3(p, pl))
+}
+
+// DistanceSquaredPointCapsule returns the squared distance between p and the surface of the
+// capsule. The distance is zero when p is inside the capsule.
+func DistanceSquaredPointCapsule(p Point3, c *Capsule) float32 {
+	d := DistancePointCapsule(p, c)
+	return d * d
+}
+
+// DistancePointCapsule returns the distance between p and the surface of the capsule. The
+// distance is zero when p is inside the capsule.
+func DistancePointCapsule(p Point3, c *Capsule) float32 {
+	l := c.Line()
+	return max(0, DistancePointLine3(p, &l)-c.Radius)
+}
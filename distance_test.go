@@ -0,0 +1,75 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestDistancePoint(t *testing.T) {
+	aabb := AABBFromCorners(Point3{0, 0, 0}, Point3{2, 2, 2})
+	obb := NewOBB(Point3{0, 0, 0}, Vec3{1, 1, 1}, mgl32.QuatRotate(pi/4, Z3))
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{4, 0, 0}, C: Point3{0, 4, 0}}
+	line := Line3{Start: Point3{0, 0, 0}, End: Point3{10, 0, 0}}
+	plane := Plane3{Normal: Vec3{0, 1, 0}, Distance: 2}
+	capsule := Capsule{Start: Point3{0, 0, 0}, End: Point3{0, 10, 0}, Radius: 1}
+
+	testCases := []struct {
+		name string
+		fn   func() float32
+		want float32
+	}{
+		{name: "line middle", fn: func() float32 { return DistancePointLine3(Point3{5, 3, 4}, &line) }, want: 5},
+		{name: "line beyond end", fn: func() float32 { return DistancePointLine3(Point3{13, 4, 0}, &line) }, want: 5},
+		{name: "line squared", fn: func() float32 { return DistanceSquaredPointLine3(Point3{5, 3, 4}, &line) }, want: 25},
+		{name: "aabb inside", fn: func() float32 { return DistancePointAABB(Point3{1, 1, 1}, &aabb) }, want: 0},
+		{name: "aabb face", fn: func() float32 { return DistancePointAABB(Point3{1, 5, 1}, &aabb) }, want: 3},
+		{name: "aabb corner", fn: func() float32 { return DistanceSquaredPointAABB(Point3{3, 3, 3}, &aabb) }, want: 3},
+		{name: "obb inside", fn: func() float32 { return DistancePointOBB(Point3{0, 1.2, 0}, &obb) }, want: 0},
+		{name: "obb corner", fn: func() float32 { return DistancePointOBB(Point3{0, 3, 0}, &obb) }, want: 3 - sqrt(2)},
+		{name: "obb face", fn: func() float32 { return DistancePointOBB(Point3{2, 2, 0}, &obb) }, want: sqrt(8) - 1},
+		{name: "tri face", fn: func() float32 { return DistancePointTri3(Point3{1, 1, 3}, tri) }, want: 3},
+		{name: "tri vertex", fn: func() float32 { return DistancePointTri3(Point3{-3, -4, 0}, tri) }, want: 5},
+		{name: "tri edge", fn: func() float32 { return DistancePointTri3(Point3{3, 3, 0}, tri) }, want: sqrt(2)},
+		{name: "tri squared", fn: func() float32 { return DistanceSquaredPointTri3(Point3{2, -1, 1}, tri) }, want: 2},
+		{name: "plane above", fn: func() float32 { return SignedDistancePointPlane3(Point3{7, 5, 7}, &plane) }, want: 3},
+		{name: "plane below", fn: func() float32 { return SignedDistancePointPlane3(Point3{7, -1, 7}, &plane) }, want: -3},
+		{name: "plane unsigned", fn: func() float32 { return DistancePointPlane3(Point3{7, -1, 7}, &plane) }, want: 3},
+		{name: "capsule inside", fn: func() float32 { return DistancePointCapsule(Point3{0.5, 5, 0}, &capsule) }, want: 0},
+		{name: "capsule side", fn: func() float32 { return DistancePointCapsule(Point3{4, 5, 0}, &capsule) }, want: 3},
+		{name: "capsule cap", fn: func() float32 { return DistanceSquaredPointCapsule(Point3{0, 13, 0}, &capsule) }, want: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.fn(); abs(got-tc.want) > 1e-5 {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTri3ClosestPoint(t *testing.T) {
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{4, 0, 0}, C: Point3{0, 4, 0}}
+
+	testCases := []struct {
+		p    Point3
+		want Point3
+	}{
+		{p: Point3{1, 1, 5}, want: Point3{1, 1, 0}},
+		{p: Point3{-1, -1, 0}, want: Point3{0, 0, 0}},
+		{p: Point3{6, -1, 0}, want: Point3{4, 0, 0}},
+		{p: Point3{-1, 6, 0}, want: Point3{0, 4, 0}},
+		{p: Point3{2, -3, 1}, want: Point3{2, 0, 0}},
+		{p: Point3{-3, 2, 1}, want: Point3{0, 2, 0}},
+		{p: Point3{3, 3, 0}, want: Point3{2, 2, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if got := tri.ClosestPoint(tc.p); !nearVec3(got, tc.want, 1e-5) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Ellipse2 is an ellipse in 2 dimensions. Radii holds the distance from the centre to the edge
+// along the ellipse's local x and y axes, which are rotated anticlockwise by Rotation radians.
+type Ellipse2 struct {
+	Centre   Point2
+	Radii    Vec2
+	Rotation float32
+}
+
+// toLocal converts p into the ellipse's local space, where it is centred on the origin and
+// aligned with the axes.
+func (e *Ellipse2) toLocal(p Point2) Vec2 {
+	return RotateVec2(p.Sub(e.Centre), -e.Rotation)
+}
+
+// toWorld converts p from the ellipse's local space into world space.
+func (e *Ellipse2) toWorld(p Vec2) Point2 {
+	return RotateVec2(p, e.Rotation).Add(e.Centre)
+}
+
+// ContainsPoint2 reports whether the point lies within the ellipse.
+func (e *Ellipse2) ContainsPoint2(p Point2) bool {
+	l := e.toLocal(p)
+	x, y := l[0]/e.Radii[0], l[1]/e.Radii[1]
+	return x*x+y*y <= 1
+}
+
+// Normal returns the outward facing normal of the ellipse at the point p, which should lie on
+// its edge.
+func (e *Ellipse2) Normal(p Point2) Vec2 {
+	l := e.toLocal(p)
+	n := Vec2{l[0] / (e.Radii[0] * e.Radii[0]), l[1] / (e.Radii[1] * e.Radii[1])}
+	return RotateVec2(n, e.Rotation).Normalize()
+}
+
+// ClosestPoint returns the point in the ellipse that is closest to p, which is p itself when
+// it lies inside the ellipse. The point on the edge is found iteratively.
+func (e *Ellipse2) ClosestPoint(p Point2) Point2 {
+	if e.ContainsPoint2(p) {
+		return p
+	}
+	l := e.toLocal(p)
+	c := closestPointEllipse([]float64{float64(e.Radii[0]), float64(e.Radii[1])}, []float64{float64(l[0]), float64(l[1])})
+	return e.toWorld(Vec2{float32(c[0]), float32(c[1])})
+}
+
+// IntersectRay2 returns the distance along the ray to the edge of the ellipse. A ray that
+// starts inside the ellipse hits the edge on its way out. It returns false if the ray misses.
+func (e *Ellipse2) IntersectRay2(r Ray2) (float32, bool) {
+	o := e.toLocal(r.Origin)
+	d := RotateVec2(r.Direction, -e.Rotation)
+
+	// Scale the ellipse to a unit circle, which leaves distances along the ray unchanged
+	o = Vec2{o[0] / e.Radii[0], o[1] / e.Radii[1]}
+	d = Vec2{d[0] / e.Radii[0], d[1] / e.Radii[1]}
+	return intersectUnitSphere(o.Dot(o), o.Dot(d), d.Dot(d))
+}
+
+// Ellipsoid is a sphere stretched along its axes. Radii holds the distance from the centre to
+// the surface along each of the ellipsoid's local axes, which are rotated by Orientation.
+type Ellipsoid struct {
+	Position    Point3
+	Radii       Vec3
+	Orientation Quat
+}
+
+// NewEllipsoid returns an ellipsoid with no rotation.
+func NewEllipsoid(pos Point3, radii Vec3) Ellipsoid {
+	return Ellipsoid{Position: pos, Radii: radii, Orientation: mgl32.QuatIdent()}
+}
+
+func (e *Ellipsoid) toLocal(p Point3) Vec3 {
+	return e.Orientation.Inverse().Rotate(p.Sub(e.Position))
+}
+
+func (e *Ellipsoid) toWorld(p Vec3) Point3 {
+	return e.Orientation.Rotate(p).Add(e.Position)
+}
+
+// support returns the point of the ellipsoid that lies furthest in the direction d.
+func (e *Ellipsoid) support(d Vec3) Point3 {
+	l := e.Orientation.Inverse().Rotate(d)
+	s := Vec3{l[0] * e.Radii[0], l[1] * e.Radii[1], l[2] * e.Radii[2]}
+	sl := s.Len()
+	if sl == 0 {
+		return e.Position
+	}
+	return e.toWorld(Vec3{s[0] * e.Radii[0] / sl, s[1] * e.Radii[1] / sl, s[2] * e.Radii[2] / sl})
+}
+
+// Bounds returns the smallest AABB that contains the ellipsoid.
+func (e *Ellipsoid) Bounds() AABB {
+	m := e.Orientation.Mat4().Mat3()
+	var size Vec3
+	for i := 0; i < 3; i++ {
+		var sum float32
+		for j := 0; j < 3; j++ {
+			v := m.At(i, j) * e.Radii[j]
+			sum += v * v
+		}
+		size[i] = sqrt(sum)
+	}
+	return AABB{Position: e.Position, Size: size}
+}
+
+// ContainsPoint3 reports whether the point lies within the ellipsoid.
+func (e *Ellipsoid) ContainsPoint3(p Point3) bool {
+	l := e.toLocal(p)
+	x, y, z := l[0]/e.Radii[0], l[1]/e.Radii[1], l[2]/e.Radii[2]
+	return x*x+y*y+z*z <= 1
+}
+
+// Normal returns the outward facing normal of the ellipsoid at the point p, which should lie on
+// its surface.
+func (e *Ellipsoid) Normal(p Point3) Vec3 {
+	l := e.toLocal(p)
+	n := Vec3{
+		l[0] / (e.Radii[0] * e.Radii[0]),
+		l[1] / (e.Radii[1] * e.Radii[1]),
+		l[2] / (e.Radii[2] * e.Radii[2]),
+	}
+	return e.Orientation.Rotate(n).Normalize()
+}
+
+// ClosestPoint returns the point in the ellipsoid that is closest to p, which is p itself when
+// it lies inside the ellipsoid. The point on the surface is found iteratively.
+func (e *Ellipsoid) ClosestPoint(p Point3) Point3 {
+	if e.ContainsPoint3(p) {
+		return p
+	}
+	l := e.toLocal(p)
+	c := closestPointEllipse(
+		[]float64{float64(e.Radii[0]), float64(e.Radii[1]), float64(e.Radii[2])},
+		[]float64{float64(l[0]), float64(l[1]), float64(l[2])},
+	)
+	return e.toWorld(Vec3{float32(c[0]), float32(c[1]), float32(c[2])})
+}
+
+// Raycast tests whether the ray hits the surface of the ellipsoid. A ray that starts inside
+// the ellipsoid hits the surface on its way out.
+func (e *Ellipsoid) Raycast(ray Ray3) (RaycastResult, bool) {
+	return e.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options.
+func (e *Ellipsoid) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	var res RaycastResult
+
+	inv := e.Orientation.Inverse()
+	o := inv.Rotate(ray.Origin.Sub(e.Position))
+	d := inv.Rotate(ray.Direction)
+
+	// Scale the ellipsoid to a unit sphere, which leaves distances along the ray unchanged
+	o = Vec3{o[0] / e.Radii[0], o[1] / e.Radii[1], o[2] / e.Radii[2]}
+	d = Vec3{d[0] / e.Radii[0], d[1] / e.Radii[1], d[2] / e.Radii[2]}
+
+	t0, t1, ok := unitSphereRoots(o.Dot(o), o.Dot(d), d.Dot(d))
+	if !ok || t1 < 0 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+
+	t := t0
+	if t < 0 || opts.Exit {
+		// The ray starts inside or the exit is wanted
+		t = t1
+	}
+	res.Distance = t
+	res.Enter, res.Exit = t0, t1
+	res.Point = ray.Point(t)
+	res.Normal = e.Normal(res.Point)
+	return res, true
+}
+
+// unitSphereRoots solves |o + t*d|² = 1 for both values of t, in increasing order, given
+// oo = o·o, od = o·d and dd = d·d.
+func unitSphereRoots(oo, od, dd float32) (float32, float32, bool) {
+	if dd == 0 {
+		return 0, 0, false
+	}
+	disc := od*od - dd*(oo-1)
+	if disc < 0 {
+		return 0, 0, false
+	}
+	s := sqrt(disc)
+	return (-od - s) / dd, (-od + s) / dd, true
+}
+
+// intersectUnitSphere solves |o + t*d|² = 1 for the smallest t that is not negative, given
+// oo = o·o, od = o·d and dd = d·d.
+func intersectUnitSphere(oo, od, dd float32) (float32, bool) {
+	t0, t1, ok := unitSphereRoots(oo, od, dd)
+	if !ok || t1 < 0 {
+		return 0, false
+	}
+	if t0 < 0 {
+		// The ray starts inside
+		return t1, true
+	}
+	return t0, true
+}
+
+// closestPointEllipse returns the point on the surface of an axis aligned ellipse or
+// ellipsoid centred on the origin with the given radii that is closest to p, which must lie
+// outside it.
+func closestPointEllipse(radii, p []float64) []float64 {
+	// The closest point q satisfies q[i] = r[i]²·p[i]/(t+r[i]²) for the root t > 0 of
+	// F(t) = Σ(r[i]·p[i]/(t+r[i]²))² - 1, which decreases monotonically. See Eberly, Distance
+	// from a Point to an Ellipse, an Ellipsoid, or a Hyperellipsoid.
+	f := func(t float64) float64 {
+		var sum float64
+		for i := range radii {
+			v := radii[i] * p[i] / (t + radii[i]*radii[i])
+			sum += v * v
+		}
+		return sum - 1
+	}
+
+	var hi float64
+	for i := range radii {
+		hi += radii[i] * radii[i] * p[i] * p[i]
+	}
+	lo, hi := 0.0, math.Sqrt(hi)
+	for it := 0; it < 100 && hi-lo > 1e-12*hi; it++ {
+		mid := (lo + hi) / 2
+		if f(mid) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	t := (lo + hi) / 2
+	q := make([]float64, len(p))
+	for i := range p {
+		r2 := radii[i] * radii[i]
+		q[i] = r2 * p[i] / (t + r2)
+	}
+	return q
+}
@@ -0,0 +1,76 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestEllipse2(t *testing.T) {
+	e := Ellipse2{Centre: Point2{1, 1}, Radii: Vec2{4, 2}, Rotation: pi / 2}
+
+	// After rotation the long axis is vertical
+	if !e.ContainsPoint2(Point2{1, 4.5}) || e.ContainsPoint2(Point2{4.5, 1}) {
+		t.Errorf("ContainsPoint2: rotation not applied")
+	}
+
+	if got := e.ClosestPoint(Point2{1, 1.5}); got != (Point2{1, 1.5}) {
+		t.Errorf("ClosestPoint inside: got %v, wanted the point itself", got)
+	}
+	if got, want := e.ClosestPoint(Point2{5, 1}), (Point2{3, 1}); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("ClosestPoint on minor axis: got %v, wanted %v", got, want)
+	}
+
+	// A point off the axes: the closest point lies on the edge and the offset is along the normal
+	p := Point2{5, 6}
+	c := e.ClosestPoint(p)
+	l := e.toLocal(c)
+	if v := l[0]*l[0]/16 + l[1]*l[1]/4; abs(v-1) > 1e-4 {
+		t.Errorf("ClosestPoint %v is not on the edge", c)
+	}
+	if n, d := e.Normal(c), p.Sub(c).Normalize(); n.Sub(d).Len() > 1e-3 {
+		t.Errorf("offset %v is not along normal %v", d, n)
+	}
+
+	dist, ok := e.IntersectRay2(Ray2{Origin: Point2{10, 1}, Direction: Vec2{-1, 0}})
+	if !ok || abs(dist-7) > 1e-5 {
+		t.Errorf("IntersectRay2: got %v, %v, wanted 7, true", dist, ok)
+	}
+	dist, ok = e.IntersectRay2(Ray2{Origin: Point2{1, 1}, Direction: Vec2{0, 1}})
+	if !ok || abs(dist-4) > 1e-5 {
+		t.Errorf("IntersectRay2 from inside: got %v, %v, wanted 4, true", dist, ok)
+	}
+	if _, ok := e.IntersectRay2(Ray2{Origin: Point2{10, 10}, Direction: Vec2{1, 0}}); ok {
+		t.Errorf("IntersectRay2: ray pointing away should miss")
+	}
+}
+
+func TestEllipsoid(t *testing.T) {
+	e := Ellipsoid{Position: Point3{0, 0, 0}, Radii: Vec3{3, 2, 1}, Orientation: mgl32.QuatRotate(pi/2, Z3)}
+
+	if !e.ContainsPoint3(Point3{0, 2.9, 0}) || e.ContainsPoint3(Point3{2.9, 0, 0}) {
+		t.Errorf("ContainsPoint3: rotation not applied")
+	}
+
+	res, ok := e.Raycast(Ray3{Origin: Point3{0, 10, 0}, Direction: Vec3{0, -1, 0}})
+	if !ok || abs(res.Distance-7) > 1e-5 || !nearVec3(res.Normal, Vec3{0, 1, 0}, 1e-5) {
+		t.Errorf("Raycast: got %v, %v, wanted distance 7 with normal +y", res, ok)
+	}
+	if _, ok := e.Raycast(Ray3{Origin: Point3{0, 10, 1.5}, Direction: Vec3{0, -1, 0}}); ok {
+		t.Errorf("Raycast: ray above ellipsoid should miss")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		p := RandomPointOnSphere(r, Sphere{Radius: 5})
+		c := e.ClosestPoint(p)
+		l := e.toLocal(c)
+		if v := l[0]*l[0]/9 + l[1]*l[1]/4 + l[2]*l[2]; abs(v-1) > 1e-3 {
+			t.Fatalf("ClosestPoint(%v) = %v is not on the surface", p, c)
+		}
+		if n, d := e.Normal(c), p.Sub(c).Normalize(); !nearVec3(n, d, 1e-3) {
+			t.Fatalf("ClosestPoint(%v): offset %v is not along normal %v", p, d, n)
+		}
+	}
+}
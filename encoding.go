@@ -0,0 +1,75 @@
+package geom
+
+import "math"
+
+// OctEncode encodes a unit vector into two 16 bit values using an octahedral mapping. The
+// vector is projected onto an octahedron which is then unfolded onto a square, giving an
+// almost uniform distribution of precision across all directions. n should be normalized.
+func OctEncode(n Vec3) [2]uint16 {
+	l1 := abs(n[0]) + abs(n[1]) + abs(n[2])
+	if l1 == 0 {
+		return [2]uint16{octQuantize(0), octQuantize(0)}
+	}
+	x, y := n[0]/l1, n[1]/l1
+	if n[2] < 0 {
+		// Fold the lower hemisphere over the diagonals
+		x, y = (1-abs(y))*octSign(x), (1-abs(x))*octSign(y)
+	}
+	return [2]uint16{octQuantize(x), octQuantize(y)}
+}
+
+// OctDecode decodes a unit vector that was encoded using OctEncode.
+func OctDecode(e [2]uint16) Vec3 {
+	x := octDequantize(e[0])
+	y := octDequantize(e[1])
+	z := 1 - abs(x) - abs(y)
+	if z < 0 {
+		x, y = (1-abs(y))*octSign(x), (1-abs(x))*octSign(y)
+	}
+	return Vec3{x, y, z}.Normalize()
+}
+
+// octSign returns -1 if v is negative and 1 otherwise.
+func octSign(v float32) float32 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// octQuantize maps v in the range [-1,1] to the full range of a uint16.
+func octQuantize(v float32) uint16 {
+	return uint16(math.Round(float64((Clamp(v, -1, 1)*0.5 + 0.5) * math.MaxUint16)))
+}
+
+// octDequantize maps a uint16 to the range [-1,1].
+func octDequantize(q uint16) float32 {
+	return float32(q)/math.MaxUint16*2 - 1
+}
+
+// QuantizePoint3 packs a point into three 16 bit values giving its position relative to the
+// bounds of a. Points outside a are clamped to its surface. The maximum error is half of the
+// size of a divided by 65535 along each axis.
+func QuantizePoint3(p Point3, a AABB) [3]uint16 {
+	pmin := a.Min()
+	var q [3]uint16
+	for i := 0; i < 3; i++ {
+		extent := a.Size[i] * 2
+		if extent <= 0 {
+			continue
+		}
+		t := Clamp((p[i]-pmin[i])/extent, 0, 1)
+		q[i] = uint16(math.Round(float64(t * math.MaxUint16)))
+	}
+	return q
+}
+
+// DequantizePoint3 unpacks a point that was packed using QuantizePoint3 with the same bounds.
+func DequantizePoint3(q [3]uint16, a AABB) Point3 {
+	pmin := a.Min()
+	var p Point3
+	for i := 0; i < 3; i++ {
+		p[i] = pmin[i] + float32(q[i])/math.MaxUint16*a.Size[i]*2
+	}
+	return p
+}
@@ -0,0 +1,55 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOctEncode(t *testing.T) {
+	testCases := []Vec3{
+		{1, 0, 0},
+		{-1, 0, 0},
+		{0, 1, 0},
+		{0, -1, 0},
+		{0, 0, 1},
+		{0, 0, -1},
+		Vec3{1, 1, 1}.Normalize(),
+		Vec3{-1, 2, -3}.Normalize(),
+		Vec3{0.3, -0.1, -0.9}.Normalize(),
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		testCases = append(testCases, Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1}.Normalize())
+	}
+
+	for _, tc := range testCases {
+		got := OctDecode(OctEncode(tc))
+		if !nearVec3(got, tc, 1e-4) {
+			t.Errorf("OctDecode(OctEncode(%v)): got %v", tc, got)
+		}
+	}
+}
+
+func TestQuantizePoint3(t *testing.T) {
+	a := AABBFromCorners(Point3{-10, 0, 5}, Point3{10, 4, 6})
+
+	testCases := []struct {
+		p    Point3
+		want Point3
+	}{
+		{p: Point3{-10, 0, 5}, want: Point3{-10, 0, 5}},
+		{p: Point3{10, 4, 6}, want: Point3{10, 4, 6}},
+		{p: Point3{1.2345, 3.21, 5.5}, want: Point3{1.2345, 3.21, 5.5}},
+		{p: Point3{20, -1, 5.5}, want: Point3{10, 0, 5.5}}, // clamped
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			got := DequantizePoint3(QuantizePoint3(tc.p, a), a)
+			if !nearVec3(got, tc.want, 2e-4) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
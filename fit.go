@@ -0,0 +1,504 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+)
+
+// FitPlane returns the plane that best fits the points in the least squares sense, minimising
+// the sum of the squared distances from each point to the plane. It also returns the root mean
+// square distance of the points from the plane. The direction of the plane's normal is
+// arbitrary. At least three points that are not collinear are needed for a meaningful result.
+func FitPlane(points []Point3) (Plane3, float32) {
+	if len(points) == 0 {
+		return Plane3{}, 0
+	}
+	centroid, cov := covariance3(points)
+
+	// The normal is the direction in which the points vary least
+	vals, vecs := symEigen3(cov)
+	smallest := 0
+	for i := 1; i < 3; i++ {
+		if vals[i] < vals[smallest] {
+			smallest = i
+		}
+	}
+
+	n := Vec3{float32(vecs[0][smallest]), float32(vecs[1][smallest]), float32(vecs[2][smallest])}.Normalize()
+	rms := float32(math.Sqrt(math.Max(0, vals[smallest]) / float64(len(points))))
+	return Plane3{Normal: n, Distance: n.Dot(centroid)}, rms
+}
+
+// covariance3 returns the centroid of the points and their scatter matrix about the centroid.
+// The matrix is not divided by the number of points.
+func covariance3(points []Point3) (Point3, [3][3]float64) {
+	var mean [3]float64
+	for _, p := range points {
+		for i := 0; i < 3; i++ {
+			mean[i] += float64(p[i])
+		}
+	}
+	n := float64(len(points))
+	for i := range mean {
+		mean[i] /= n
+	}
+
+	var cov [3][3]float64
+	for _, p := range points {
+		d := [3]float64{float64(p[0]) - mean[0], float64(p[1]) - mean[1], float64(p[2]) - mean[2]}
+		for i := 0; i < 3; i++ {
+			for j := i; j < 3; j++ {
+				cov[i][j] += d[i] * d[j]
+			}
+		}
+	}
+	cov[1][0], cov[2][0], cov[2][1] = cov[0][1], cov[0][2], cov[1][2]
+
+	return Point3{float32(mean[0]), float32(mean[1]), float32(mean[2])}, cov
+}
+
+// symEigen3 returns the eigenvalues and eigenvectors of the symmetric matrix a using the
+// cyclic Jacobi method. The eigenvector for vals[i] is held in column i of vecs.
+func symEigen3(a [3][3]float64) (vals [3]float64, vecs [3][3]float64) {
+	vecs = [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		off := a[0][1]*a[0][1] + a[0][2]*a[0][2] + a[1][2]*a[1][2]
+		if off < 1e-30 {
+			break
+		}
+
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				// Choose the rotation that zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < 3; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < 3; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := vecs[k][p], vecs[k][q]
+					vecs[k][p] = c*vkp - s*vkq
+					vecs[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	return [3]float64{a[0][0], a[1][1], a[2][2]}, vecs
+}
+
+// EstimateRigidTransform returns the rotation and translation that best map each point in src
+// onto the point at the same index in dst, minimising the sum of the squared distances between
+// them. It uses the closed form solution of Horn, "Closed-form solution of absolute orientation
+// using unit quaternions", 1987, so the result is always a proper rotation without reflection.
+// At least three points that are not collinear are needed for the rotation to be unique. It
+// panics if src and dst have different lengths.
+func EstimateRigidTransform(src, dst []Point3) Transform {
+	return estimateTransform(src, dst, false)
+}
+
+// EstimateSimilarityTransform is like EstimateRigidTransform but also finds the uniform scale,
+// applied before the rotation, that best maps src onto dst.
+func EstimateSimilarityTransform(src, dst []Point3) Transform {
+	return estimateTransform(src, dst, true)
+}
+
+// estimateTransform returns the transform that best maps src onto dst, with a uniform scale if
+// scaled is true.
+func estimateTransform(src, dst []Point3, scaled bool) Transform {
+	if len(src) != len(dst) {
+		panic("geom: src and dst must have the same number of points")
+	}
+	t := NewTransform()
+	if len(src) == 0 {
+		return t
+	}
+
+	var cs, cd [3]float64
+	for i := range src {
+		for j := 0; j < 3; j++ {
+			cs[j] += float64(src[i][j])
+			cd[j] += float64(dst[i][j])
+		}
+	}
+	n := float64(len(src))
+	for j := 0; j < 3; j++ {
+		cs[j] /= n
+		cd[j] /= n
+	}
+
+	// Cross covariance of the centred points and the spread of src about its centroid
+	var m [3][3]float64
+	var ss float64
+	for i := range src {
+		a := [3]float64{float64(src[i][0]) - cs[0], float64(src[i][1]) - cs[1], float64(src[i][2]) - cs[2]}
+		b := [3]float64{float64(dst[i][0]) - cd[0], float64(dst[i][1]) - cd[1], float64(dst[i][2]) - cd[2]}
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[j][k] += a[j] * b[k]
+			}
+			ss += a[j] * a[j]
+		}
+	}
+
+	// The rotation is the eigenvector of Horn's matrix with the largest eigenvalue, which is
+	// also the sum of the dot products of the rotated src points with the dst points
+	sxx, sxy, sxz := m[0][0], m[0][1], m[0][2]
+	syx, syy, syz := m[1][0], m[1][1], m[1][2]
+	szx, szy, szz := m[2][0], m[2][1], m[2][2]
+	vals, vecs := symEigen4([4][4]float64{
+		{sxx + syy + szz, syz - szy, szx - sxz, sxy - syx},
+		{syz - szy, sxx - syy - szz, sxy + syx, szx + sxz},
+		{szx - sxz, sxy + syx, -sxx + syy - szz, syz + szy},
+		{sxy - syx, szx + sxz, syz + szy, -sxx - syy + szz},
+	})
+	largest := 0
+	for i := 1; i < 4; i++ {
+		if vals[i] > vals[largest] {
+			largest = i
+		}
+	}
+	q := Quat{
+		W: float32(vecs[0][largest]),
+		V: Vec3{float32(vecs[1][largest]), float32(vecs[2][largest]), float32(vecs[3][largest])},
+	}.Normalize()
+
+	scale := 1.0
+	if scaled && ss > 0 {
+		scale = vals[largest] / ss
+	}
+
+	centroid := q.Rotate(Vec3{float32(cs[0]), float32(cs[1]), float32(cs[2])}).Mul(float32(scale))
+	t.SetOrientation(q)
+	t.SetScaleUniform(float32(scale))
+	t.SetPosition(Vec3{float32(cd[0]), float32(cd[1]), float32(cd[2])}.Sub(centroid))
+	return t
+}
+
+// symEigen4 returns the eigenvalues and eigenvectors of the symmetric matrix a using the
+// cyclic Jacobi method. The eigenvector for vals[i] is held in column i of vecs.
+func symEigen4(a [4][4]float64) (vals [4]float64, vecs [4][4]float64) {
+	for i := 0; i < 4; i++ {
+		vecs[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		var off, diag float64
+		for p := 0; p < 4; p++ {
+			diag += a[p][p] * a[p][p]
+			for q := p + 1; q < 4; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if off <= 1e-30*diag || off < 1e-300 {
+			break
+		}
+
+		for p := 0; p < 3; p++ {
+			for q := p + 1; q < 4; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				// Choose the rotation that zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < 4; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < 4; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < 4; k++ {
+					vkp, vkq := vecs[k][p], vecs[k][q]
+					vecs[k][p] = c*vkp - s*vkq
+					vecs[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	return [4]float64{a[0][0], a[1][1], a[2][2], a[3][3]}, vecs
+}
+
+// FitPlaneRANSAC finds the plane supported by the most points using random sample consensus,
+// which ignores outliers that would distort a least squares fit. A point supports a plane when
+// it lies within threshold of it. The search tests the given number of planes through three
+// randomly chosen points, then refits the best plane to its supporting points using FitPlane.
+// It returns the plane and the indexes of the supporting points, or false if no plane could be
+// found.
+func FitPlaneRANSAC(r *rand.Rand, points []Point3, threshold float32, iterations int) (Plane3, []int, bool) {
+	if len(points) < 3 {
+		return Plane3{}, nil, false
+	}
+
+	var best Plane3
+	bestCount := 0
+	for it := 0; it < iterations; it++ {
+		i, j, k := ransacSample3(r, len(points))
+		t := Tri3{A: points[i], B: points[j], C: points[k]}
+		if t.IsDegenerate() {
+			continue
+		}
+		pl := Plane3FromTri3(t)
+
+		count := 0
+		for _, p := range points {
+			if DistancePointPlane3(p, &pl) <= threshold {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = pl, count
+		}
+	}
+	if bestCount == 0 {
+		return Plane3{}, nil, false
+	}
+
+	inliers := planeInliers(points, best, threshold, nil)
+	subset := make([]Point3, len(inliers))
+	for i, idx := range inliers {
+		subset[i] = points[idx]
+	}
+	if refined, _ := FitPlane(subset); refined.Normal != (Vec3{}) {
+		best = refined
+		inliers = planeInliers(points, best, threshold, inliers[:0])
+	}
+
+	return best, inliers, true
+}
+
+func planeInliers(points []Point3, pl Plane3, threshold float32, dst []int) []int {
+	for i, p := range points {
+		if DistancePointPlane3(p, &pl) <= threshold {
+			dst = append(dst, i)
+		}
+	}
+	return dst
+}
+
+// FitSphereRANSAC finds the sphere supported by the most points using random sample
+// consensus. A point supports a sphere when it lies within threshold of its surface. The search
+// tests the given number of spheres through four randomly chosen points. It returns the sphere
+// and the indexes of the supporting points, or false if no sphere could be found.
+func FitSphereRANSAC(r *rand.Rand, points []Point3, threshold float32, iterations int) (Sphere, []int, bool) {
+	if len(points) < 4 {
+		return Sphere{}, nil, false
+	}
+
+	var best Sphere
+	bestCount := 0
+	for it := 0; it < iterations; it++ {
+		i, j, k := ransacSample3(r, len(points))
+		l := r.Intn(len(points))
+		if l == i || l == j || l == k {
+			continue
+		}
+		s, ok := sphereFrom4(points[i], points[j], points[k], points[l])
+		if !ok {
+			continue
+		}
+
+		count := 0
+		for _, p := range points {
+			if abs(Distance3(p, s.Position)-s.Radius) <= threshold {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = s, count
+		}
+	}
+	if bestCount == 0 {
+		return Sphere{}, nil, false
+	}
+
+	var inliers []int
+	for i, p := range points {
+		if abs(Distance3(p, best.Position)-best.Radius) <= threshold {
+			inliers = append(inliers, i)
+		}
+	}
+	return best, inliers, true
+}
+
+// ransacSample3 returns three distinct random indexes less than n, which must be at least 3.
+func ransacSample3(r *rand.Rand, n int) (int, int, int) {
+	i := r.Intn(n)
+	j := r.Intn(n - 1)
+	if j >= i {
+		j++
+	}
+	lo, hi := i, j
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	k := r.Intn(n - 2)
+	if k >= lo {
+		k++
+	}
+	if k >= hi {
+		k++
+	}
+	return i, j, k
+}
+
+// sphereFrom4 returns the sphere whose surface passes through all four points. It returns
+// false if the points lie in a plane.
+func sphereFrom4(p0, p1, p2, p3 Point3) (Sphere, bool) {
+	// The centre c satisfies 2(pi-p0)·c = |pi|²-|p0|² for each of the other points
+	var m [3][3]float64
+	var b [3]float64
+	for row, p := range [3]Point3{p1, p2, p3} {
+		for col := 0; col < 3; col++ {
+			m[row][col] = 2 * (float64(p[col]) - float64(p0[col]))
+		}
+		b[row] = float64(p.Dot(p)) - float64(p0.Dot(p0))
+	}
+
+	det := det3(m)
+	if math.Abs(det) < 1e-12 {
+		return Sphere{}, false
+	}
+
+	// Cramer's rule
+	var c Point3
+	for col := 0; col < 3; col++ {
+		mc := m
+		for row := 0; row < 3; row++ {
+			mc[row][col] = b[row]
+		}
+		c[col] = float32(det3(mc) / det)
+	}
+	return Sphere{Position: c, Radius: Distance3(c, p0)}, true
+}
+
+func det3(m [3][3]float64) float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// FitLine2 returns the line that best fits the points using total least squares, minimising
+// the sum of the squared perpendicular distances from each point to the line. The returned
+// segment spans the projections of the points onto the line. It also returns the root mean
+// square distance of the points from the line.
+func FitLine2(points []Point2) (Line2, float32) {
+	if len(points) == 0 {
+		return Line2{}, 0
+	}
+
+	var mx, my float64
+	for _, p := range points {
+		mx += float64(p[0])
+		my += float64(p[1])
+	}
+	n := float64(len(points))
+	mx, my = mx/n, my/n
+
+	var sxx, sxy, syy float64
+	for _, p := range points {
+		dx, dy := float64(p[0])-mx, float64(p[1])-my
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+
+	// The line runs along the eigenvector of the largest eigenvalue of the scatter matrix
+	angle := math.Atan2(2*sxy, sxx-syy) / 2
+	dir := Vec2{float32(math.Cos(angle)), float32(math.Sin(angle))}
+	smallest := (sxx+syy)/2 - math.Hypot((sxx-syy)/2, sxy)
+
+	centre := Point2{float32(mx), float32(my)}
+	tmin, tmax := float32(math.Inf(1)), float32(math.Inf(-1))
+	for _, p := range points {
+		t := p.Sub(centre).Dot(dir)
+		tmin = min(tmin, t)
+		tmax = max(tmax, t)
+	}
+
+	line := Line2{Start: centre.Add(dir.Mul(tmin)), End: centre.Add(dir.Mul(tmax))}
+	return line, float32(math.Sqrt(math.Max(0, smallest) / n))
+}
+
+// FitCircle returns the circle that best fits the points using the algebraic method of Kåsa,
+// which is fast and accurate when the points cover a reasonable portion of the circle. It also
+// returns the root mean square distance of the points from the circle. At least three points
+// that are not collinear are needed for a meaningful result.
+func FitCircle(points []Point2) (Circle, float32) {
+	if len(points) == 0 {
+		return Circle{}, 0
+	}
+
+	var mx, my float64
+	for _, p := range points {
+		mx += float64(p[0])
+		my += float64(p[1])
+	}
+	n := float64(len(points))
+	mx, my = mx/n, my/n
+
+	// Fit u²+v² = a*u + b*v + c to the points centred on their mean, where the centre of the
+	// circle is (a/2, b/2). Centring decouples c from the other terms.
+	var suu, suv, svv, suz, svz, sz float64
+	for _, p := range points {
+		u, v := float64(p[0])-mx, float64(p[1])-my
+		z := u*u + v*v
+		suu += u * u
+		suv += u * v
+		svv += v * v
+		suz += u * z
+		svz += v * z
+		sz += z
+	}
+
+	det := suu*svv - suv*suv
+	if math.Abs(det) < 1e-12 {
+		return Circle{}, 0
+	}
+	a := (suz*svv - svz*suv) / det
+	b := (svz*suu - suz*suv) / det
+	c := sz / n
+
+	cu, cv := a/2, b/2
+	c2 := Circle{
+		Centre: Point2{float32(mx + cu), float32(my + cv)},
+		Radius: float32(math.Sqrt(c + cu*cu + cv*cv)),
+	}
+
+	var sumSq float64
+	for _, p := range points {
+		d := float64(p.Sub(c2.Centre).Len() - c2.Radius)
+		sumSq += d * d
+	}
+	return c2, float32(math.Sqrt(sumSq / n))
+}
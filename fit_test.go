@@ -0,0 +1,244 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFitPlane(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	normal := Vec3{1, 2, 3}.Normalize()
+	u, v := orthonormalBasis(normal)
+	origin := Point3{4, -2, 7}
+
+	var pts []Point3
+	for i := 0; i < 200; i++ {
+		p := origin.Add(u.Mul(r.Float32()*20 - 10)).Add(v.Mul(r.Float32()*20 - 10))
+		pts = append(pts, p)
+	}
+
+	plane, rms := FitPlane(pts)
+	if abs(abs(plane.Normal.Dot(normal))-1) > 1e-4 {
+		t.Errorf("got normal %v, wanted %v", plane.Normal, normal)
+	}
+	if rms > 1e-4 {
+		t.Errorf("got rms %v for exact plane, wanted near zero", rms)
+	}
+	if !plane.ContainsPoint3(origin) {
+		t.Errorf("plane %v should contain %v", plane, origin)
+	}
+
+	// Alternate points either side of the plane
+	for i := range pts {
+		off := float32(0.5)
+		if i%2 == 1 {
+			off = -0.5
+		}
+		pts[i] = pts[i].Add(normal.Mul(off))
+	}
+	_, rms = FitPlane(pts)
+	if abs(rms-0.5) > 1e-3 {
+		t.Errorf("got rms %v for noisy plane, wanted %v", rms, 0.5)
+	}
+}
+
+func TestSymEigen3(t *testing.T) {
+	a := [3][3]float64{{4, 1, 2}, {1, 3, 0}, {2, 0, 5}}
+	vals, vecs := symEigen3(a)
+
+	for i := 0; i < 3; i++ {
+		// Check a*v = lambda*v for each eigenpair
+		for row := 0; row < 3; row++ {
+			var av float64
+			for k := 0; k < 3; k++ {
+				av += a[row][k] * vecs[k][i]
+			}
+			if d := av - vals[i]*vecs[row][i]; d > 1e-9 || d < -1e-9 {
+				t.Errorf("eigenpair %d: residual %v in row %d", i, d, row)
+			}
+		}
+	}
+	if sum := vals[0] + vals[1] + vals[2]; sum < 12-1e-9 || sum > 12+1e-9 {
+		t.Errorf("eigenvalues %v should sum to the trace 12", vals)
+	}
+}
+
+func TestSymEigen4(t *testing.T) {
+	a := [4][4]float64{{4, 1, 2, -1}, {1, 3, 0, 2}, {2, 0, 5, 1}, {-1, 2, 1, -2}}
+	vals, vecs := symEigen4(a)
+
+	for i := 0; i < 4; i++ {
+		for row := 0; row < 4; row++ {
+			var av float64
+			for k := 0; k < 4; k++ {
+				av += a[row][k] * vecs[k][i]
+			}
+			if d := av - vals[i]*vecs[row][i]; d > 1e-9 || d < -1e-9 {
+				t.Errorf("eigenpair %d: residual %v in row %d", i, d, row)
+			}
+		}
+	}
+}
+
+func TestEstimateRigidTransform(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	src := make([]Point3, 30)
+	for i := range src {
+		src[i] = Point3{r.Float32()*10 - 5, r.Float32()*10 - 5, r.Float32()*10 - 5}
+	}
+
+	testCases := []struct {
+		name   string
+		q      Quat
+		scale  float32
+		offset Vec3
+	}{
+		{name: "identity", q: mgl32.QuatIdent(), scale: 1},
+		{name: "translation", q: mgl32.QuatIdent(), scale: 1, offset: Vec3{3, -4, 5}},
+		{name: "half turn", q: mgl32.QuatRotate(math.Pi, Vec3{1, 1, 0}.Normalize()), scale: 1, offset: Vec3{1, 2, 3}},
+		{name: "general", q: mgl32.QuatRotate(1.2, Vec3{-2, 1, 3}.Normalize()), scale: 1, offset: Vec3{-7, 0, 2}},
+		{name: "scaled", q: mgl32.QuatRotate(-0.4, Vec3{0, 1, 1}.Normalize()), scale: 2.5, offset: Vec3{0, 10, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := make([]Point3, len(src))
+			for i, p := range src {
+				dst[i] = tc.q.Rotate(p.Mul(tc.scale)).Add(tc.offset)
+			}
+
+			tx := EstimateSimilarityTransform(src, dst)
+			if tc.scale == 1 {
+				tx = EstimateRigidTransform(src, dst)
+			}
+			if s := tx.Scale(); abs(s[0]-tc.scale) > 1e-4 {
+				t.Errorf("got scale %v, wanted %v", s, tc.scale)
+			}
+			for i := range src {
+				if got := tx.TransformPoint(src[i]); !nearVec3(got, dst[i], 1e-3) {
+					t.Fatalf("point %d: got %v, wanted %v", i, got, dst[i])
+				}
+			}
+		})
+	}
+
+	// A mirror image cannot be matched by a rotation, but the result is still a rotation
+	dst := make([]Point3, len(src))
+	for i, p := range src {
+		dst[i] = Point3{-p[0], p[1], p[2]}
+	}
+	tx := EstimateRigidTransform(src, dst)
+	if d := tx.Matrix().Mat3().Det(); abs(d-1) > 1e-4 {
+		t.Errorf("mirrored: got determinant %v, wanted 1", d)
+	}
+
+	// A single point gives a translation
+	tx = EstimateRigidTransform([]Point3{{1, 2, 3}}, []Point3{{4, 4, 4}})
+	if got := tx.TransformPoint(Point3{0, 0, 0}); !nearVec3(got, Point3{3, 2, 1}, 1e-6) {
+		t.Errorf("single point: got %v, wanted a translation by (3, 2, 1)", got)
+	}
+}
+
+func TestFitPlaneRANSAC(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// Points on the plane y=2 with some noise, plus outliers scattered through space
+	var pts []Point3
+	for i := 0; i < 100; i++ {
+		pts = append(pts, Point3{r.Float32()*10 - 5, 2 + (r.Float32()-0.5)*0.02, r.Float32()*10 - 5})
+	}
+	for i := 0; i < 50; i++ {
+		pts = append(pts, Point3{r.Float32()*10 - 5, r.Float32()*10 - 5, r.Float32()*10 - 5})
+	}
+
+	pl, inliers, ok := FitPlaneRANSAC(r, pts, 0.05, 100)
+	if !ok {
+		t.Fatalf("no plane found")
+	}
+	if abs(abs(pl.Normal[1])-1) > 1e-2 || abs(abs(pl.Distance)-2) > 1e-2 {
+		t.Errorf("got plane %v, wanted y=2", pl)
+	}
+	if len(inliers) < 100 || len(inliers) > 110 {
+		t.Errorf("got %d inliers, wanted around 100", len(inliers))
+	}
+	for _, idx := range inliers {
+		if abs(pts[idx][1]-2) > 0.06 {
+			t.Errorf("point %v should not be an inlier", pts[idx])
+		}
+	}
+
+	if _, _, ok := FitPlaneRANSAC(r, pts[:2], 0.05, 100); ok {
+		t.Errorf("two points should not produce a plane")
+	}
+}
+
+func TestFitSphereRANSAC(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	want := Sphere{Position: Point3{1, 2, 3}, Radius: 4}
+
+	var pts []Point3
+	for i := 0; i < 100; i++ {
+		pts = append(pts, RandomPointOnSphere(r, want))
+	}
+	for i := 0; i < 30; i++ {
+		pts = append(pts, RandomPointInSphere(r, Sphere{Position: want.Position, Radius: 3}))
+	}
+
+	got, inliers, ok := FitSphereRANSAC(r, pts, 0.01, 200)
+	if !ok {
+		t.Fatalf("no sphere found")
+	}
+	if !nearVec3(got.Position, want.Position, 1e-2) || abs(got.Radius-want.Radius) > 1e-2 {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+	if len(inliers) < 100 {
+		t.Errorf("got %d inliers, wanted at least 100", len(inliers))
+	}
+}
+
+func TestFitLine2(t *testing.T) {
+	pts := []Point2{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {-1, 0}}
+	l, rms := FitLine2(pts)
+	if rms > 1e-5 {
+		t.Errorf("got rms %v for exact line, wanted near zero", rms)
+	}
+	ends := []Point2{l.Start, l.End}
+	if ends[0][0] > ends[1][0] {
+		ends[0], ends[1] = ends[1], ends[0]
+	}
+	if ends[0].Sub(Point2{-1, 0}).Len() > 1e-5 || ends[1].Sub(Point2{3, 4}).Len() > 1e-5 {
+		t.Errorf("got line %v, wanted from %v to %v", l, Point2{-1, 0}, Point2{3, 4})
+	}
+
+	// A vertical line with pairs of points either side
+	_, rms = FitLine2([]Point2{{0.1, 0}, {-0.1, 0}, {0.1, 3}, {-0.1, 3}})
+	if abs(rms-0.1) > 1e-5 {
+		t.Errorf("got rms %v for noisy line, wanted %v", rms, 0.1)
+	}
+}
+
+func TestFitCircle(t *testing.T) {
+	want := Circle{Centre: Point2{3, -2}, Radius: 5}
+
+	var pts []Point2
+	for i := 0; i < 12; i++ {
+		// Only use half of the circle
+		s, c := sincos(float32(i) * pi / 12)
+		pts = append(pts, want.Centre.Add(Vec2{c, s}.Mul(want.Radius)))
+	}
+
+	got, rms := FitCircle(pts)
+	if got.Centre.Sub(want.Centre).Len() > 1e-3 || abs(got.Radius-want.Radius) > 1e-3 {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+	if rms > 1e-3 {
+		t.Errorf("got rms %v for exact circle, wanted near zero", rms)
+	}
+
+	if got, _ := FitCircle([]Point2{{0, 0}, {1, 1}, {2, 2}}); got != (Circle{}) {
+		t.Errorf("collinear points should not produce a circle")
+	}
+}
@@ -0,0 +1,504 @@
+package geom
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Fixed is a signed Q16.16 fixed-point number with 16 integer bits and 16 fractional bits.
+// Arithmetic on Fixed values uses only integer operations so it gives identical results on
+// every platform, which lockstep simulations need and float32 cannot guarantee. Values range
+// from about -32768 to 32768 with a resolution of 1/65536. The methods saturate at FixedMax or
+// FixedMin on overflow, whereas the built in + and - operators wrap around.
+type Fixed int32
+
+const (
+	fixedShift = 16
+
+	// FixedOne is the Fixed value 1.
+	FixedOne Fixed = 1 << fixedShift
+
+	// FixedMax and FixedMin are the largest and smallest Fixed values.
+	FixedMax Fixed = 1<<31 - 1
+	FixedMin Fixed = -1 << 31
+)
+
+// FixedFromInt returns the Fixed value of v, saturating at FixedMax or FixedMin.
+func FixedFromInt(v int) Fixed {
+	if v > FixedMax.Int() {
+		return FixedMax
+	}
+	if v < FixedMin.Int() {
+		return FixedMin
+	}
+	return Fixed(v << fixedShift)
+}
+
+// FixedFromFloat returns the Fixed value nearest to v, saturating at FixedMax or FixedMin. NaN
+// converts to zero. Conversion from floating point should only be used when loading data, never
+// inside a simulation step.
+func FixedFromFloat(v float32) Fixed {
+	f := float64(v) * float64(FixedOne)
+	switch {
+	case f != f:
+		return 0
+	case f >= float64(FixedMax):
+		return FixedMax
+	case f <= float64(FixedMin):
+		return FixedMin
+	case f < 0:
+		return Fixed(f - 0.5)
+	}
+	return Fixed(f + 0.5)
+}
+
+// Float32 returns the value as a float32, for rendering or display.
+func (f Fixed) Float32() float32 {
+	return float32(f) / float32(FixedOne)
+}
+
+// Int returns the integer part of the value, rounding toward negative infinity.
+func (f Fixed) Int() int {
+	return int(f >> fixedShift)
+}
+
+// Add returns the sum of f and g, saturating at FixedMax or FixedMin.
+func (f Fixed) Add(g Fixed) Fixed {
+	return fixedSaturate(int64(f) + int64(g))
+}
+
+// Sub returns the difference of f and g, saturating at FixedMax or FixedMin.
+func (f Fixed) Sub(g Fixed) Fixed {
+	return fixedSaturate(int64(f) - int64(g))
+}
+
+// Mul returns f multiplied by g, saturating at FixedMax or FixedMin.
+func (f Fixed) Mul(g Fixed) Fixed {
+	return fixedSaturate((int64(f) * int64(g)) >> fixedShift)
+}
+
+// Div returns f divided by g. Division by zero saturates to FixedMax or FixedMin according to
+// the sign of f.
+func (f Fixed) Div(g Fixed) Fixed {
+	if g == 0 {
+		if f < 0 {
+			return FixedMin
+		}
+		return FixedMax
+	}
+	return fixedSaturate((int64(f) << fixedShift) / int64(g))
+}
+
+// Abs returns the absolute value of f. The absolute value of FixedMin saturates to FixedMax.
+func (f Fixed) Abs() Fixed {
+	if f < 0 {
+		return fixedSaturate(-int64(f))
+	}
+	return f
+}
+
+// Sqrt returns the square root of f, or zero if f is negative.
+func (f Fixed) Sqrt() Fixed {
+	if f <= 0 {
+		return 0
+	}
+	return Fixed(isqrt64(uint64(f) << fixedShift))
+}
+
+// fixedSaturate clamps v to the range of a Fixed.
+func fixedSaturate(v int64) Fixed {
+	if v > int64(FixedMax) {
+		return FixedMax
+	}
+	if v < int64(FixedMin) {
+		return FixedMin
+	}
+	return Fixed(v)
+}
+
+// isqrt64 returns the largest integer whose square is no more than v.
+func isqrt64(v uint64) uint64 {
+	if v == 0 {
+		return 0
+	}
+	// Start from a power of two above the root and apply Newton's method, which decreases
+	// monotonically to the floor of the root
+	x := uint64(1) << ((bits.Len64(v) + 1) / 2)
+	for {
+		y := (x + v/x) / 2
+		if y >= x {
+			return x
+		}
+		x = y
+	}
+}
+
+// fixedWide is a signed 128 bit integer in two's complement. Sums of several products of Fixed
+// values, such as dot products, need more than 64 bits before they are reduced back to a Fixed.
+type fixedWide struct {
+	hi, lo uint64
+}
+
+// fixedMul returns the product of a and b, which may each use up to 63 bits.
+func fixedMul(a, b int64) fixedWide {
+	neg := (a < 0) != (b < 0)
+	hi, lo := bits.Mul64(uint64(abs64(a)), uint64(abs64(b)))
+	w := fixedWide{hi, lo}
+	if neg {
+		return w.neg()
+	}
+	return w
+}
+
+func (w fixedWide) neg() fixedWide {
+	lo, borrow := bits.Sub64(0, w.lo, 0)
+	hi, _ := bits.Sub64(0, w.hi, borrow)
+	return fixedWide{hi, lo}
+}
+
+func (w fixedWide) add(u fixedWide) fixedWide {
+	lo, carry := bits.Add64(w.lo, u.lo, 0)
+	hi, _ := bits.Add64(w.hi, u.hi, carry)
+	return fixedWide{hi, lo}
+}
+
+func (w fixedWide) sub(u fixedWide) fixedWide {
+	return w.add(u.neg())
+}
+
+func (w fixedWide) isNeg() bool {
+	return int64(w.hi) < 0
+}
+
+// less reports whether w is less than u.
+func (w fixedWide) less(u fixedWide) bool {
+	if w.hi != u.hi {
+		return int64(w.hi) < int64(u.hi)
+	}
+	return w.lo < u.lo
+}
+
+// fixed reduces a value with 32 fractional bits to a Fixed, rounding toward negative infinity
+// and saturating at FixedMax or FixedMin.
+func (w fixedWide) fixed() Fixed {
+	hi := int64(w.hi) >> fixedShift
+	lo := w.lo>>fixedShift | w.hi<<(64-fixedShift)
+	if hi != int64(lo)>>63 {
+		// Too large for 64 bits, let alone 32
+		if hi < 0 {
+			return FixedMin
+		}
+		return FixedMax
+	}
+	return fixedSaturate(int64(lo))
+}
+
+// sqrt returns the square root of a value with 32 fractional bits, saturating at FixedMax, or
+// zero if the value is negative.
+func (w fixedWide) sqrt() Fixed {
+	if w.isNeg() {
+		return 0
+	}
+	if w.hi != 0 {
+		// The root is at least 1<<32, which is beyond FixedMax
+		return FixedMax
+	}
+	return fixedSaturate(int64(isqrt64(w.lo)))
+}
+
+// int64 returns the value, saturating at the limits of an int64.
+func (w fixedWide) int64() int64 {
+	if w.hi != uint64(int64(w.lo)>>63) {
+		if w.isNeg() {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	return int64(w.lo)
+}
+
+// FixedVec2 is a 2 dimensional vector with Fixed components.
+type FixedVec2 [2]Fixed
+
+// FixedVec2FromVec2 converts v to fixed point.
+func FixedVec2FromVec2(v Vec2) FixedVec2 {
+	return FixedVec2{FixedFromFloat(v[0]), FixedFromFloat(v[1])}
+}
+
+// Vec2 converts the vector to floating point.
+func (v FixedVec2) Vec2() Vec2 {
+	return Vec2{v[0].Float32(), v[1].Float32()}
+}
+
+// Add returns the sum of v and u, saturating each component.
+func (v FixedVec2) Add(u FixedVec2) FixedVec2 {
+	return FixedVec2{v[0].Add(u[0]), v[1].Add(u[1])}
+}
+
+// Sub returns the difference of v and u, saturating each component.
+func (v FixedVec2) Sub(u FixedVec2) FixedVec2 {
+	return FixedVec2{v[0].Sub(u[0]), v[1].Sub(u[1])}
+}
+
+// Mul returns v scaled by s.
+func (v FixedVec2) Mul(s Fixed) FixedVec2 {
+	return FixedVec2{v[0].Mul(s), v[1].Mul(s)}
+}
+
+// Dot returns the dot product of v and u. The products are summed before rounding.
+func (v FixedVec2) Dot(u FixedVec2) Fixed {
+	return fixedMul(int64(v[0]), int64(u[0])).add(fixedMul(int64(v[1]), int64(u[1]))).fixed()
+}
+
+// Cross returns the z component of the cross product of v and u extended into 3 dimensions.
+func (v FixedVec2) Cross(u FixedVec2) Fixed {
+	return fixedMul(int64(v[0]), int64(u[1])).sub(fixedMul(int64(v[1]), int64(u[0]))).fixed()
+}
+
+// LenSquared returns the squared length of the vector.
+func (v FixedVec2) LenSquared() Fixed {
+	return v.Dot(v)
+}
+
+// Len returns the length of the vector.
+func (v FixedVec2) Len() Fixed {
+	return fixedMul(int64(v[0]), int64(v[0])).add(fixedMul(int64(v[1]), int64(v[1]))).sqrt()
+}
+
+// FixedVec3 is a 3 dimensional vector with Fixed components.
+type FixedVec3 [3]Fixed
+
+// FixedVec3FromVec3 converts v to fixed point.
+func FixedVec3FromVec3(v Vec3) FixedVec3 {
+	return FixedVec3{FixedFromFloat(v[0]), FixedFromFloat(v[1]), FixedFromFloat(v[2])}
+}
+
+// Vec3 converts the vector to floating point.
+func (v FixedVec3) Vec3() Vec3 {
+	return Vec3{v[0].Float32(), v[1].Float32(), v[2].Float32()}
+}
+
+// Add returns the sum of v and u, saturating each component.
+func (v FixedVec3) Add(u FixedVec3) FixedVec3 {
+	return FixedVec3{v[0].Add(u[0]), v[1].Add(u[1]), v[2].Add(u[2])}
+}
+
+// Sub returns the difference of v and u, saturating each component.
+func (v FixedVec3) Sub(u FixedVec3) FixedVec3 {
+	return FixedVec3{v[0].Sub(u[0]), v[1].Sub(u[1]), v[2].Sub(u[2])}
+}
+
+// Mul returns v scaled by s.
+func (v FixedVec3) Mul(s Fixed) FixedVec3 {
+	return FixedVec3{v[0].Mul(s), v[1].Mul(s), v[2].Mul(s)}
+}
+
+// Dot returns the dot product of v and u. The products are summed before rounding.
+func (v FixedVec3) Dot(u FixedVec3) Fixed {
+	return v.dotWide(u).fixed()
+}
+
+// dotWide returns the dot product of v and u with 32 fractional bits.
+func (v FixedVec3) dotWide(u FixedVec3) fixedWide {
+	return fixedDot3(
+		[3]int64{int64(v[0]), int64(v[1]), int64(v[2])},
+		[3]int64{int64(u[0]), int64(u[1]), int64(u[2])},
+	)
+}
+
+// fixedDot3 returns the dot product of two vectors of raw Fixed values that have been widened,
+// for example by subtracting coordinates without saturation.
+func fixedDot3(a, b [3]int64) fixedWide {
+	return fixedMul(a[0], b[0]).add(fixedMul(a[1], b[1])).add(fixedMul(a[2], b[2]))
+}
+
+// fixedDelta3 returns p minus q without saturating.
+func fixedDelta3(p, q FixedVec3) [3]int64 {
+	return [3]int64{int64(p[0]) - int64(q[0]), int64(p[1]) - int64(q[1]), int64(p[2]) - int64(q[2])}
+}
+
+// Cross returns the cross product of v and u.
+func (v FixedVec3) Cross(u FixedVec3) FixedVec3 {
+	return FixedVec3{
+		fixedMul(int64(v[1]), int64(u[2])).sub(fixedMul(int64(v[2]), int64(u[1]))).fixed(),
+		fixedMul(int64(v[2]), int64(u[0])).sub(fixedMul(int64(v[0]), int64(u[2]))).fixed(),
+		fixedMul(int64(v[0]), int64(u[1])).sub(fixedMul(int64(v[1]), int64(u[0]))).fixed(),
+	}
+}
+
+// LenSquared returns the squared length of the vector.
+func (v FixedVec3) LenSquared() Fixed {
+	return v.Dot(v)
+}
+
+// Len returns the length of the vector.
+func (v FixedVec3) Len() Fixed {
+	return v.dotWide(v).sqrt()
+}
+
+// Normalize returns the vector scaled to unit length, or the zero vector if v has no length.
+func (v FixedVec3) Normalize() FixedVec3 {
+	l := v.Len()
+	if l == 0 {
+		return FixedVec3{}
+	}
+	return FixedVec3{v[0].Div(l), v[1].Div(l), v[2].Div(l)}
+}
+
+// FixedAABB is an axis-aligned bounding box with Fixed coordinates.
+type FixedAABB struct {
+	Position FixedVec3 // centre of the box
+	Size     FixedVec3 // HALF SIZE, i.e. the size in each direction
+}
+
+// Min returns the minimum corner of the box.
+func (a *FixedAABB) Min() FixedVec3 {
+	return a.Position.Sub(a.Size)
+}
+
+// Max returns the maximum corner of the box.
+func (a *FixedAABB) Max() FixedVec3 {
+	return a.Position.Add(a.Size)
+}
+
+// ContainsPoint3 reports whether the point lies in the box.
+func (a *FixedAABB) ContainsPoint3(p FixedVec3) bool {
+	for i := 0; i < 3; i++ {
+		if abs64(int64(p[i])-int64(a.Position[i])) > int64(a.Size[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB reports whether the boxes overlap.
+func (a *FixedAABB) IntersectsAABB(b *FixedAABB) bool {
+	// Widen to 64 bits so that distant or large boxes cannot overflow
+	for i := 0; i < 3; i++ {
+		if abs64(int64(a.Position[i])-int64(b.Position[i])) > int64(a.Size[i])+int64(b.Size[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ClosestPoint returns the point in the box that is closest to p.
+func (a *FixedAABB) ClosestPoint(p FixedVec3) FixedVec3 {
+	amin, amax := a.Min(), a.Max()
+	for i := 0; i < 3; i++ {
+		if p[i] < amin[i] {
+			p[i] = amin[i]
+		} else if p[i] > amax[i] {
+			p[i] = amax[i]
+		}
+	}
+	return p
+}
+
+// IntersectsSphere reports whether the sphere overlaps the box.
+func (a *FixedAABB) IntersectsSphere(s *FixedSphere) bool {
+	d := fixedDelta3(a.ClosestPoint(s.Position), s.Position)
+	r := int64(s.Radius)
+	return !fixedMul(r, r).less(fixedDot3(d, d))
+}
+
+// FixedSphere is a sphere with Fixed coordinates.
+type FixedSphere struct {
+	Position FixedVec3
+	Radius   Fixed
+}
+
+// ContainsPoint3 reports whether the point lies in the sphere.
+func (s *FixedSphere) ContainsPoint3(p FixedVec3) bool {
+	d := fixedDelta3(p, s.Position)
+	r := int64(s.Radius)
+	return !fixedMul(r, r).less(fixedDot3(d, d))
+}
+
+// IntersectsSphere reports whether the spheres overlap.
+func (s *FixedSphere) IntersectsSphere(s2 *FixedSphere) bool {
+	d := fixedDelta3(s.Position, s2.Position)
+	r := int64(s.Radius) + int64(s2.Radius)
+	return !fixedMul(r, r).less(fixedDot3(d, d))
+}
+
+// FixedRay3 is a ray with Fixed coordinates. The direction should have unit length so that
+// distances along the ray are in world units.
+type FixedRay3 struct {
+	Origin    FixedVec3
+	Direction FixedVec3
+}
+
+// Point returns the point at distance d along the ray.
+func (r *FixedRay3) Point(d Fixed) FixedVec3 {
+	return r.Origin.Add(r.Direction.Mul(d))
+}
+
+// RaycastAABB returns the distance along the ray to where it enters the box, or to where it
+// leaves when the ray starts inside. It reports false if the ray misses the box or the box is
+// behind the ray.
+func (r *FixedRay3) RaycastAABB(a *FixedAABB) (Fixed, bool) {
+	amin, amax := a.Min(), a.Max()
+	tmin, tmax := FixedMin, FixedMax
+	for i := 0; i < 3; i++ {
+		if r.Direction[i] == 0 {
+			// Parallel to the slab so the ray must start within it
+			if r.Origin[i] < amin[i] || r.Origin[i] > amax[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1 := amin[i].Sub(r.Origin[i]).Div(r.Direction[i])
+		t2 := amax[i].Sub(r.Origin[i]).Div(r.Direction[i])
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tmin {
+			tmin = t1
+		}
+		if t2 < tmax {
+			tmax = t2
+		}
+	}
+	if tmin > tmax || tmax < 0 {
+		return 0, false
+	}
+	if tmin < 0 {
+		return tmax, true
+	}
+	return tmin, true
+}
+
+// RaycastSphere returns the distance along the ray to where it enters the sphere, or to where
+// it leaves when the ray starts inside. It reports false if the ray misses the sphere or the
+// sphere is behind the ray.
+func (r *FixedRay3) RaycastSphere(s *FixedSphere) (Fixed, bool) {
+	e := fixedDelta3(s.Position, r.Origin)
+	dir := [3]int64{int64(r.Direction[0]), int64(r.Direction[1]), int64(r.Direction[2])}
+	a := fixedDot3(e, dir).int64() >> fixedShift
+	ee := fixedDot3(e, e)
+	rr := fixedMul(int64(s.Radius), int64(s.Radius))
+
+	// Work with 32 fractional bits throughout, reducing a to 16 so that a² matches
+	disc := rr.sub(ee).add(fixedMul(a, a))
+	if disc.isNeg() {
+		return 0, false
+	}
+	f := int64(disc.sqrt())
+	t := a - f
+	if ee.less(rr) {
+		// The ray starts inside the sphere
+		t = a + f
+	}
+	if t < 0 {
+		return 0, false
+	}
+	return fixedSaturate(t), true
+}
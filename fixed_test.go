@@ -0,0 +1,195 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFixedArithmetic(t *testing.T) {
+	f := FixedFromFloat
+
+	testCases := []struct {
+		name string
+		got  Fixed
+		want Fixed
+	}{
+		{name: "from int", got: FixedFromInt(-3), want: -3 * FixedOne},
+		{name: "from float", got: f(1.5), want: FixedOne + FixedOne/2},
+		{name: "from negative float", got: f(-0.25), want: -FixedOne / 4},
+		{name: "from int saturates", got: FixedFromInt(40000), want: FixedMax},
+		{name: "from float saturates", got: f(1e9), want: FixedMax},
+		{name: "from negative float saturates", got: f(-40000), want: FixedMin},
+		{name: "from nan", got: f(float32(math.NaN())), want: 0},
+		{name: "add", got: f(1.5).Add(f(-2.5)), want: f(-1)},
+		{name: "add saturates", got: FixedFromInt(30000).Add(FixedFromInt(30000)), want: FixedMax},
+		{name: "sub saturates", got: FixedFromInt(-30000).Sub(FixedFromInt(30000)), want: FixedMin},
+		{name: "mul", got: f(1.5).Mul(f(-2.5)), want: f(-3.75)},
+		{name: "mul saturates", got: FixedFromInt(30000).Mul(FixedFromInt(30000)), want: FixedMax},
+		{name: "div", got: f(7).Div(f(2)), want: f(3.5)},
+		{name: "div by zero", got: f(-1).Div(0), want: FixedMin},
+		{name: "sqrt", got: f(2.25).Sqrt(), want: f(1.5)},
+		{name: "sqrt negative", got: f(-4).Sqrt(), want: 0},
+		{name: "abs", got: f(-2).Abs(), want: f(2)},
+		{name: "abs saturates", got: FixedMin.Abs(), want: FixedMax},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %v (%v), wanted %v (%v)", tc.got, tc.got.Float32(), tc.want, tc.want.Float32())
+			}
+		})
+	}
+
+	if got := f(-1.5).Int(); got != -2 {
+		t.Errorf("Int: got %d, wanted -2", got)
+	}
+	if got := f(0.1).Float32(); abs(got-0.1) > 1.0/65536 {
+		t.Errorf("Float32: got %v, wanted 0.1", got)
+	}
+}
+
+func TestFixedVectors(t *testing.T) {
+	a := FixedVec3FromVec3(Vec3{1, 2, 3})
+	b := FixedVec3FromVec3(Vec3{-2, 0.5, 4})
+
+	if got := a.Dot(b); got != FixedFromInt(11) {
+		t.Errorf("Dot: got %v, wanted 11", got.Float32())
+	}
+	if got, want := a.Cross(b), FixedVec3FromVec3(Vec3{6.5, -10, 4.5}); got != want {
+		t.Errorf("Cross: got %v, wanted %v", got.Vec3(), want.Vec3())
+	}
+	if got := FixedVec3FromVec3(Vec3{2, 3, 6}).Len(); got != FixedFromInt(7) {
+		t.Errorf("Len: got %v, wanted 7", got.Float32())
+	}
+	if got := FixedVec3FromVec3(Vec3{0, 0, 5}).Normalize(); got != (FixedVec3{0, 0, FixedOne}) {
+		t.Errorf("Normalize: got %v, wanted (0,0,1)", got.Vec3())
+	}
+
+	big := FixedVec3{FixedMax, FixedMin, FixedOne}
+	if got, want := big.Add(FixedVec3{FixedOne, -FixedOne, FixedOne}), (FixedVec3{FixedMax, FixedMin, 2 * FixedOne}); got != want {
+		t.Errorf("Add: got %v, wanted %v", got, want)
+	}
+	if got, want := big.Sub(FixedVec3{-FixedOne, FixedOne, FixedOne}), (FixedVec3{FixedMax, FixedMin, 0}); got != want {
+		t.Errorf("Sub: got %v, wanted %v", got, want)
+	}
+	if got, want := (FixedVec2{FixedMax, FixedMin}).Add(FixedVec2{FixedOne, -FixedOne}), (FixedVec2{FixedMax, FixedMin}); got != want {
+		t.Errorf("FixedVec2 Add: got %v, wanted %v", got, want)
+	}
+	if got, want := (FixedVec2{FixedMax, FixedMin}).Sub(FixedVec2{-FixedOne, FixedOne}), (FixedVec2{FixedMax, FixedMin}); got != want {
+		t.Errorf("FixedVec2 Sub: got %v, wanted %v", got, want)
+	}
+
+	v := FixedVec2FromVec2(Vec2{3, 4})
+	if got := v.Len(); got != FixedFromInt(5) {
+		t.Errorf("FixedVec2 Len: got %v, wanted 5", got.Float32())
+	}
+	if got := v.Cross(FixedVec2{FixedOne, 0}); got != FixedFromInt(-4) {
+		t.Errorf("FixedVec2 Cross: got %v, wanted -4", got.Float32())
+	}
+}
+
+func TestFixedVectorsExtreme(t *testing.T) {
+	// Sums of products near the limits of the range need more than 64 bits
+	far := FixedVec3FromVec3(Vec3{30000, 30000, 30000})
+	if got := far.Len(); got != FixedMax {
+		t.Errorf("Len: got %v, wanted FixedMax", got.Float32())
+	}
+	if got := far.LenSquared(); got != FixedMax {
+		t.Errorf("LenSquared: got %v, wanted FixedMax", got.Float32())
+	}
+	if got := far.Dot(far.Mul(-FixedOne)); got != FixedMin {
+		t.Errorf("Dot: got %v, wanted FixedMin", got.Float32())
+	}
+	if got, want := (FixedVec3{FixedMax, FixedMax, 0}).Cross(FixedVec3{FixedMin, FixedMax, 0}), (FixedVec3{0, 0, FixedMax}); got != want {
+		t.Errorf("Cross: got %v, wanted %v", got, want)
+	}
+	small := FixedVec3FromVec3(Vec3{10000, 10000, 10000})
+	if got, want := small.Len(), FixedFromFloat(17320.508); got.Sub(want).Abs() > FixedOne/100 {
+		t.Errorf("Len: got %v, wanted %v", got.Float32(), want.Float32())
+	}
+
+	minv := FixedVec2{FixedMin, FixedMin}
+	if got := minv.Dot(minv); got != FixedMax {
+		t.Errorf("FixedVec2 Dot: got %v, wanted FixedMax", got.Float32())
+	}
+	if got := minv.Len(); got != FixedMax {
+		t.Errorf("FixedVec2 Len: got %v, wanted FixedMax", got.Float32())
+	}
+	if got := (FixedVec2{FixedMin, FixedMax}).Cross(FixedVec2{FixedMin, FixedMin}); got != FixedMax {
+		t.Errorf("FixedVec2 Cross: got %v, wanted FixedMax", got.Float32())
+	}
+}
+
+func TestFixedShapes(t *testing.T) {
+	v := func(x, y, z float32) FixedVec3 { return FixedVec3FromVec3(Vec3{x, y, z}) }
+
+	box := FixedAABB{Position: v(0, 0, 0), Size: v(1, 2, 3)}
+	if !box.ContainsPoint3(v(1, -2, 3)) || box.ContainsPoint3(v(1.01, 0, 0)) {
+		t.Errorf("FixedAABB.ContainsPoint3: boundary not respected")
+	}
+	other := FixedAABB{Position: v(2, 0, 0), Size: v(1, 1, 1)}
+	if !box.IntersectsAABB(&other) {
+		t.Errorf("FixedAABB.IntersectsAABB: touching boxes should intersect")
+	}
+
+	far := FixedAABB{Position: FixedVec3{FixedMin, 0, 0}, Size: v(1, 1, 1)}
+	wide := FixedAABB{Position: FixedVec3{FixedMax, 0, 0}, Size: FixedVec3{FixedMax, FixedOne, FixedOne}}
+	if far.IntersectsAABB(&wide) || !wide.IntersectsAABB(&box) {
+		t.Errorf("FixedAABB.IntersectsAABB: boxes near the limits of the range overlapped wrongly")
+	}
+
+	s := FixedSphere{Position: v(3, 0, 0), Radius: FixedFromInt(1)}
+	if box.IntersectsSphere(&s) {
+		t.Errorf("FixedAABB.IntersectsSphere: sphere one unit away should miss")
+	}
+	s.Radius = FixedFromFloat(2.5)
+	if !box.IntersectsSphere(&s) || !s.ContainsPoint3(v(1, 0, 0)) {
+		t.Errorf("FixedSphere: larger sphere should overlap the box")
+	}
+
+	ray := FixedRay3{Origin: v(-10, 0, 0), Direction: v(1, 0, 0)}
+	if d, ok := ray.RaycastAABB(&box); !ok || d != FixedFromInt(9) {
+		t.Errorf("RaycastAABB: got %v, %v, wanted 9", d.Float32(), ok)
+	}
+	inside := FixedRay3{Origin: v(0, 0, 0), Direction: v(0, 0, 1)}
+	if d, ok := inside.RaycastAABB(&box); !ok || d != FixedFromInt(3) {
+		t.Errorf("RaycastAABB from inside: got %v, %v, wanted 3", d.Float32(), ok)
+	}
+	miss := FixedRay3{Origin: v(-10, 5, 0), Direction: v(1, 0, 0)}
+	if _, ok := miss.RaycastAABB(&box); ok {
+		t.Errorf("RaycastAABB: parallel ray outside the slab should miss")
+	}
+
+	if d, ok := ray.RaycastSphere(&s); !ok || d != FixedFromFloat(10.5) {
+		t.Errorf("RaycastSphere: got %v, %v, wanted 10.5", d.Float32(), ok)
+	}
+	if d, ok := (&FixedRay3{Origin: v(3, 0, 0), Direction: v(0, 1, 0)}).RaycastSphere(&s); !ok || d != FixedFromFloat(2.5) {
+		t.Errorf("RaycastSphere from inside: got %v, %v, wanted 2.5", d.Float32(), ok)
+	}
+	if _, ok := (&FixedRay3{Origin: v(-10, 5, 0), Direction: v(1, 0, 0)}).RaycastSphere(&s); ok {
+		t.Errorf("RaycastSphere: ray passing above should miss")
+	}
+
+	distant := FixedSphere{Position: v(-20000, -20000, -20000), Radius: FixedFromInt(10)}
+	if distant.ContainsPoint3(v(20000, 20000, 20000)) {
+		t.Errorf("FixedSphere.ContainsPoint3: point on the far side of the range should be outside")
+	}
+	opposite := FixedSphere{Position: v(20000, 20000, 20000), Radius: FixedFromInt(10)}
+	if distant.IntersectsSphere(&opposite) {
+		t.Errorf("FixedSphere.IntersectsSphere: distant spheres should not overlap")
+	}
+	huge := FixedSphere{Position: v(20000, 20000, 20000), Radius: FixedMax}
+	if !huge.ContainsPoint3(v(0, 20000, 20000)) || huge.ContainsPoint3(v(-20000, -20000, -20000)) {
+		t.Errorf("FixedSphere.ContainsPoint3: wrong result for a sphere of maximum radius")
+	}
+	corner := FixedAABB{Position: v(-20000, -20000, -20000), Size: v(1, 1, 1)}
+	if corner.IntersectsSphere(&opposite) {
+		t.Errorf("FixedAABB.IntersectsSphere: distant sphere should miss")
+	}
+	if _, ok := (&FixedRay3{Origin: v(20000, 20000, 20000), Direction: v(1, 0, 0)}).RaycastSphere(&distant); ok {
+		t.Errorf("RaycastSphere: sphere behind a distant ray should miss")
+	}
+	if d, ok := (&FixedRay3{Origin: v(-20000, -20000, 20000), Direction: v(0, 0, -1)}).RaycastSphere(&distant); !ok || d != FixedFromInt(39990) {
+		t.Errorf("RaycastSphere: got %v, %v, wanted 39990", d.Float32(), ok)
+	}
+}
@@ -0,0 +1,174 @@
+package geom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The String methods in this file give compact, human readable descriptions of values for
+// logging and debugging. Components are printed with at most formatPrecision significant digits
+// and internal caches are omitted. The methods use value receivers, even for types whose other
+// methods take pointers, so that both values and pointers print the same way with %v.
+
+// formatPrecision is the number of significant digits printed for each component.
+const formatPrecision = 6
+
+func formatFloat(f float32) string {
+	s := strconv.FormatFloat(float64(f), 'g', formatPrecision, 32)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func formatVec(v ...float32) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatFloat(f))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func formatQuat(q Quat) string {
+	return formatVec(q.W, q.V[0], q.V[1], q.V[2])
+}
+
+// formatFields formats the name of a type followed by pairs of field names and formatted values.
+func formatFields(name string, kv ...string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(kv[i])
+		b.WriteString(": ")
+		b.WriteString(kv[i+1])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (i Interval) String() string {
+	return "[" + formatFloat(i.Min) + ", " + formatFloat(i.Max) + "]"
+}
+
+func (r Ray2) String() string {
+	return formatFields("Ray2", "Origin", formatVec(r.Origin[:]...), "Direction", formatVec(r.Direction[:]...))
+}
+
+func (r Ray3) String() string {
+	return formatFields("Ray3", "Origin", formatVec(r.Origin[:]...), "Direction", formatVec(r.Direction[:]...))
+}
+
+func (l Line2) String() string {
+	return formatFields("Line2", "Start", formatVec(l.Start[:]...), "End", formatVec(l.End[:]...))
+}
+
+func (l Line3) String() string {
+	return formatFields("Line3", "Start", formatVec(l.Start[:]...), "End", formatVec(l.End[:]...))
+}
+
+func (r RaycastResult) String() string {
+	if r.Fail != RaycastFailUnknown {
+		return formatFields("RaycastResult", "Fail", r.Fail.String())
+	}
+	return formatFields("RaycastResult",
+		"Point", formatVec(r.Point[:]...),
+		"Normal", formatVec(r.Normal[:]...),
+		"Distance", formatFloat(r.Distance))
+}
+
+func (r Rect) String() string {
+	return formatFields("Rect", "Position", formatVec(r.Position[:]...), "Size", formatVec(r.Size[:]...))
+}
+
+func (r Recti) String() string {
+	return "Recti{Position: (" + strconv.Itoa(int(r.Position[0])) + ", " + strconv.Itoa(int(r.Position[1])) +
+		"), Size: (" + strconv.Itoa(int(r.Size[0])) + ", " + strconv.Itoa(int(r.Size[1])) + ")}"
+}
+
+func (c Circle) String() string {
+	return formatFields("Circle", "Centre", formatVec(c.Centre[:]...), "Radius", formatFloat(c.Radius))
+}
+
+func (t Tri2) String() string {
+	return formatFields("Tri2", "A", formatVec(t.A[:]...), "B", formatVec(t.B[:]...), "C", formatVec(t.C[:]...))
+}
+
+func (t Tri3) String() string {
+	return formatFields("Tri3", "A", formatVec(t.A[:]...), "B", formatVec(t.B[:]...), "C", formatVec(t.C[:]...))
+}
+
+func (a AABB) String() string {
+	return formatFields("AABB", "Position", formatVec(a.Position[:]...), "Size", formatVec(a.Size[:]...))
+}
+
+func (o OBB) String() string {
+	return formatFields("OBB",
+		"Position", formatVec(o.Position[:]...),
+		"Size", formatVec(o.Size[:]...),
+		"Orientation", formatQuat(o.Orientation))
+}
+
+func (p Plane3) String() string {
+	return formatFields("Plane3", "Normal", formatVec(p.Normal[:]...), "Distance", formatFloat(p.Distance))
+}
+
+func (s Sphere) String() string {
+	return formatFields("Sphere", "Position", formatVec(s.Position[:]...), "Radius", formatFloat(s.Radius))
+}
+
+func (c Capsule) String() string {
+	return formatFields("Capsule",
+		"Start", formatVec(c.Start[:]...),
+		"End", formatVec(c.End[:]...),
+		"Radius", formatFloat(c.Radius))
+}
+
+func (c Cone) String() string {
+	return formatFields("Cone",
+		"Apex", formatVec(c.Apex[:]...),
+		"Direction", formatVec(c.Direction[:]...),
+		"Angle", formatFloat(c.Angle),
+		"Range", formatFloat(c.Range))
+}
+
+func (e Ellipse2) String() string {
+	return formatFields("Ellipse2",
+		"Centre", formatVec(e.Centre[:]...),
+		"Radii", formatVec(e.Radii[:]...),
+		"Rotation", formatFloat(e.Rotation))
+}
+
+func (e Ellipsoid) String() string {
+	return formatFields("Ellipsoid",
+		"Position", formatVec(e.Position[:]...),
+		"Radii", formatVec(e.Radii[:]...),
+		"Orientation", formatQuat(e.Orientation))
+}
+
+func (t Transform) String() string {
+	kv := []string{
+		"Position", formatVec(t.position[:]...),
+		"Scale", formatVec(t.scale[:]...),
+		"Orientation", formatQuat(t.orientation),
+	}
+	if t.convention != ForwardPositiveZ {
+		kv = append(kv, "Convention", t.convention.String())
+	}
+	return formatFields("Transform", kv...)
+}
+
+func (t Transform2) String() string {
+	return formatFields("Transform2",
+		"Position", formatVec(t.position[:]...),
+		"Scale", formatVec(t.scale[:]...),
+		"Rotation", formatFloat(t.rotation))
+}
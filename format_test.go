@@ -0,0 +1,46 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestString(t *testing.T) {
+	negZero := float32(math.Copysign(0, -1))
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 2, 3})
+
+	obb := &OBB{Position: Point3{1, 0, 0}, Size: Vec3{0.5, 0.5, 0.5}, Orientation: mgl32.QuatIdent()}
+	obb.Corners() // fills the cache, which must not be printed
+
+	testCases := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: "aabb", v: AABB{Position: Point3{1, 2, 3}, Size: Vec3{0.5, 0.5, 0.5}}, want: "AABB{Position: (1, 2, 3), Size: (0.5, 0.5, 0.5)}"},
+		{name: "aabb pointer", v: &AABB{Size: Vec3{1, 1, 1}}, want: "AABB{Position: (0, 0, 0), Size: (1, 1, 1)}"},
+		{name: "obb", v: obb, want: "OBB{Position: (1, 0, 0), Size: (0.5, 0.5, 0.5), Orientation: (1, 0, 0, 0)}"},
+		{name: "precision", v: Ray3{Direction: Vec3{1.0 / 3, 2.0 / 3, 2.0 / 3}}, want: "Ray3{Origin: (0, 0, 0), Direction: (0.333333, 0.666667, 0.666667)}"},
+		{name: "negative zero", v: Rect{Position: Point2{negZero, 1}, Size: Vec2{2, 3}}, want: "Rect{Position: (0, 1), Size: (2, 3)}"},
+		{name: "plane", v: Plane3{Normal: Vec3{0, 1, 0}, Distance: -2}, want: "Plane3{Normal: (0, 1, 0), Distance: -2}"},
+		{name: "sphere", v: Sphere{Position: Point3{1, 1, 1}, Radius: 1e7}, want: "Sphere{Position: (1, 1, 1), Radius: 1e+07}"},
+		{name: "interval", v: Interval{Min: -1, Max: 1.5}, want: "[-1, 1.5]"},
+		{name: "recti", v: Recti{Position: Point2i{3, 4}, Size: Vec2i{1, 2}}, want: "Recti{Position: (3, 4), Size: (1, 2)}"},
+		{name: "raycast hit", v: RaycastResult{Point: Point3{1, 0, 0}, Normal: Vec3{1, 0, 0}, Distance: 4}, want: "RaycastResult{Point: (1, 0, 0), Normal: (1, 0, 0), Distance: 4}"},
+		{name: "raycast miss", v: RaycastResult{Fail: RaycastFailOutsideBounds}, want: "RaycastResult{Fail: outside bounds}"},
+		{name: "transform", v: tx, want: "Transform{Position: (1, 2, 3), Scale: (1, 1, 1), Orientation: (1, 0, 0, 0)}"},
+		{name: "transform2", v: NewTransform2(), want: "Transform2{Position: (0, 0), Scale: (1, 1), Rotation: 0}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fmt.Sprintf("%v", tc.v); got != tc.want {
+				t.Errorf("got %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
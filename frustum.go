@@ -0,0 +1,150 @@
+package geom
+
+// Indexes of the planes of a Frustum.
+const (
+	FrustumLeft = iota
+	FrustumRight
+	FrustumBottom
+	FrustumTop
+	FrustumNear
+	FrustumFar
+)
+
+// Frustum is a convex volume bounded by six planes, typically the volume visible to a camera.
+// The normal of each plane faces into the frustum.
+type Frustum struct {
+	Planes [6]Plane3
+}
+
+// FrustumFromMatrix extracts the frustum from a combined view and projection matrix, such as
+// one produced by Camera.ViewProjectionMatrix. The matrix is assumed to use the OpenGL clip
+// space convention where depth ranges from -1 to 1.
+func FrustumFromMatrix(m Mat4) Frustum {
+	// See Gribb & Hartmann, Fast Extraction of Viewing Frustum Planes from the
+	// World-View-Projection Matrix
+	r0, r1, r2, r3 := m.Row(0), m.Row(1), m.Row(2), m.Row(3)
+
+	var f Frustum
+	f.Planes[FrustumLeft] = frustumPlane(r3.Add(r0))
+	f.Planes[FrustumRight] = frustumPlane(r3.Sub(r0))
+	f.Planes[FrustumBottom] = frustumPlane(r3.Add(r1))
+	f.Planes[FrustumTop] = frustumPlane(r3.Sub(r1))
+	f.Planes[FrustumNear] = frustumPlane(r3.Add(r2))
+	f.Planes[FrustumFar] = frustumPlane(r3.Sub(r2))
+	return f
+}
+
+// frustumPlane converts the plane equation ax+by+cz+d >= 0 into a normalized Plane3.
+func frustumPlane(v Vec4) Plane3 {
+	n := v.Vec3()
+	l := n.Len()
+	if l == 0 {
+		return Plane3{}
+	}
+	return Plane3{Normal: n.Mul(1 / l), Distance: -v[3] / l}
+}
+
+// ContainsPoint3 reports whether the point lies within the frustum.
+func (f *Frustum) ContainsPoint3(p Point3) bool {
+	for i := range f.Planes {
+		if f.Planes[i].Normal.Dot(p) < f.Planes[i].Distance {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsSphere reports whether any part of the sphere lies within the frustum. The test is
+// conservative and may report an intersection for a sphere that lies just outside a corner of
+// the frustum.
+func (f *Frustum) IntersectsSphere(s *Sphere) bool {
+	for i := range f.Planes {
+		if f.Planes[i].Normal.Dot(s.Position)-f.Planes[i].Distance < -s.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB reports whether any part of the box lies within the frustum. The test is
+// conservative and may report an intersection for a box that lies just outside a corner of
+// the frustum.
+func (f *Frustum) IntersectsAABB(a *AABB) bool {
+	for i := range f.Planes {
+		n := f.Planes[i].Normal
+		// Distance from the centre to the plane of the corner that is furthest along the normal
+		r := a.Size[0]*abs(n[0]) + a.Size[1]*abs(n[1]) + a.Size[2]*abs(n[2])
+		if n.Dot(a.Position)-f.Planes[i].Distance < -r {
+			return false
+		}
+	}
+	return true
+}
+
+// Corners returns the eight corners of the frustum. The first four are on the near plane and
+// the last four on the far plane, each in the order bottom left, bottom right, top right and
+// top left.
+func (f *Frustum) Corners() [8]Point3 {
+	var c [8]Point3
+	for i, depth := range [2]int{FrustumNear, FrustumFar} {
+		c[i*4+0] = intersectPlanes3(f.Planes[depth], f.Planes[FrustumBottom], f.Planes[FrustumLeft])
+		c[i*4+1] = intersectPlanes3(f.Planes[depth], f.Planes[FrustumBottom], f.Planes[FrustumRight])
+		c[i*4+2] = intersectPlanes3(f.Planes[depth], f.Planes[FrustumTop], f.Planes[FrustumRight])
+		c[i*4+3] = intersectPlanes3(f.Planes[depth], f.Planes[FrustumTop], f.Planes[FrustumLeft])
+	}
+	return c
+}
+
+// Bounds returns the smallest AABB that contains the frustum.
+func (f *Frustum) Bounds() AABB {
+	c := f.Corners()
+	return AABBFromPoints(c[:])
+}
+
+// OrientedBounds returns the smallest box aligned with the view direction and the up direction
+// of the frustum that contains the frustum. For a slice of a camera's frustum it is usually much
+// tighter than Bounds, which suits fitting cascaded shadow maps.
+func (f *Frustum) OrientedBounds() OBB {
+	front := f.Planes[FrustumNear].Normal
+	up := f.Planes[FrustumBottom].Normal.Sub(f.Planes[FrustumTop].Normal)
+	q := lookRotation(front, up, Y3)
+	axes := [3]Vec3{q.Rotate(X3), q.Rotate(Y3), q.Rotate(Z3)}
+
+	c := f.Corners()
+	var centre Point3
+	var size Vec3
+	for i, axis := range axes {
+		lo, hi := float32(maxFloat32), float32(-maxFloat32)
+		for _, p := range c {
+			d := axis.Dot(p)
+			lo, hi = min(lo, d), max(hi, d)
+		}
+		centre = centre.Add(axis.Mul((lo + hi) / 2))
+		size[i] = (hi - lo) / 2
+	}
+	return NewOBB(centre, size, q)
+}
+
+// Split returns the slice of the frustum that lies between the distances near and far,
+// measured from the frustum's near plane along its view direction. Splitting a camera's
+// frustum into consecutive slices produces the volumes covered by cascaded shadow maps.
+func (f *Frustum) Split(near, far float32) Frustum {
+	s := *f
+	n := f.Planes[FrustumNear]
+	s.Planes[FrustumNear] = Plane3{Normal: n.Normal, Distance: n.Distance + near}
+	s.Planes[FrustumFar] = Plane3{Normal: n.Normal.Mul(-1), Distance: -(n.Distance + far)}
+	return s
+}
+
+// intersectPlanes3 returns the point where three planes meet. The planes must not be parallel.
+func intersectPlanes3(a, b, c Plane3) Point3 {
+	bc := b.Normal.Cross(c.Normal)
+	denom := a.Normal.Dot(bc)
+	if denom == 0 {
+		return Point3{}
+	}
+	p := bc.Mul(a.Distance).
+		Add(c.Normal.Cross(a.Normal).Mul(b.Distance)).
+		Add(a.Normal.Cross(b.Normal).Mul(c.Distance))
+	return p.Mul(1 / denom)
+}
@@ -0,0 +1,342 @@
+package geom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GeoJSON support covers the 2D geometry types of RFC 7946 that have a counterpart in this
+// package: a Point is a Point2, a LineString is a Path2 and a Polygon is a Polygon2 with its
+// holes. MultiPoint, MultiLineString and MultiPolygon geometries are read as one feature per
+// part. Coordinates are read and written as they are, without any map projection, and any
+// altitude component is ignored.
+//
+// GeoJSON repeats the first position of each polygon ring at its end and expects outer rings to
+// be counter clockwise and holes clockwise. Rings are closed and oriented when writing; when
+// reading the repeated position is dropped and rings are reoriented to the same convention.
+
+// GeoJSONFeature is a single geometry together with its properties. Exactly one of Point,
+// Path and Polygon is set.
+type GeoJSONFeature struct {
+	Point      *Point2
+	Path       *Path2
+	Polygon    *Polygon2
+	Properties map[string]any
+}
+
+// ReadGeoJSON reads a GeoJSON FeatureCollection, Feature or bare geometry and returns the
+// features it contains. Features without a geometry are skipped.
+func ReadGeoJSON(r io.Reader) ([]GeoJSONFeature, error) {
+	var obj geoJSONObject
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("geom: decode geojson: %w", err)
+	}
+	var fs []GeoJSONFeature
+	if err := obj.appendFeatures(&fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// WriteGeoJSON writes the features as a GeoJSON FeatureCollection.
+func WriteGeoJSON(w io.Writer, features []GeoJSONFeature) error {
+	type feature struct {
+		Type       string          `json:"type"`
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties map[string]any  `json:"properties"`
+	}
+	coll := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: make([]feature, 0, len(features))}
+
+	for i, f := range features {
+		var g []byte
+		var err error
+		switch {
+		case f.Point != nil:
+			g, err = MarshalGeoJSONPoint2(*f.Point)
+		case f.Path != nil:
+			g, err = f.Path.MarshalGeoJSON()
+		case f.Polygon != nil:
+			g, err = f.Polygon.MarshalGeoJSON()
+		default:
+			err = errors.New("no geometry")
+		}
+		if err != nil {
+			return fmt.Errorf("geom: feature %d: %w", i, err)
+		}
+		coll.Features = append(coll.Features, feature{Type: "Feature", Geometry: g, Properties: f.Properties})
+	}
+	return json.NewEncoder(w).Encode(coll)
+}
+
+// MarshalGeoJSONPoint2 returns the GeoJSON Point geometry for p.
+func MarshalGeoJSONPoint2(p Point2) ([]byte, error) {
+	c, err := geoJSONPosition(p)
+	if err != nil {
+		return nil, err
+	}
+	return marshalGeoJSONGeometry("Point", c)
+}
+
+// UnmarshalGeoJSONPoint2 parses a GeoJSON Point geometry.
+func UnmarshalGeoJSONPoint2(data []byte) (Point2, error) {
+	var p Point2
+	err := unmarshalGeoJSONGeometry(data, "Point", func(c json.RawMessage) error {
+		var err error
+		p, err = parseGeoJSONPosition(c)
+		return err
+	})
+	return p, err
+}
+
+// MarshalGeoJSON returns the GeoJSON LineString geometry that follows the path's waypoints.
+func (p *Path2) MarshalGeoJSON() ([]byte, error) {
+	c, err := geoJSONPositions(p.Points)
+	if err != nil {
+		return nil, err
+	}
+	return marshalGeoJSONGeometry("LineString", c)
+}
+
+// UnmarshalGeoJSON sets the path to the waypoints of a GeoJSON LineString geometry.
+func (p *Path2) UnmarshalGeoJSON(data []byte) error {
+	return unmarshalGeoJSONGeometry(data, "LineString", func(c json.RawMessage) error {
+		pts, err := parseGeoJSONLineString(c)
+		if err != nil {
+			return err
+		}
+		*p = *NewPath2(pts)
+		return nil
+	})
+}
+
+// MarshalGeoJSON returns the GeoJSON Polygon geometry for the polygon and its holes.
+func (p *Polygon2) MarshalGeoJSON() ([]byte, error) {
+	rings := make([][][]json.Number, 0, 1+len(p.Holes))
+	for i, pts := range append([][]Point2{p.Points}, p.Holes...) {
+		ring := orientRing(pts, i == 0)
+		if len(ring) > 0 {
+			ring = append(ring, ring[0])
+		}
+		c, err := geoJSONPositions(ring)
+		if err != nil {
+			return nil, err
+		}
+		rings = append(rings, c)
+	}
+	return marshalGeoJSONGeometry("Polygon", rings)
+}
+
+// UnmarshalGeoJSON sets the polygon to a GeoJSON Polygon geometry. The first ring becomes the
+// outer boundary, oriented counter clockwise, and the rest become holes, oriented clockwise.
+func (p *Polygon2) UnmarshalGeoJSON(data []byte) error {
+	return unmarshalGeoJSONGeometry(data, "Polygon", func(c json.RawMessage) error {
+		poly, err := parseGeoJSONPolygon(c)
+		if err != nil {
+			return err
+		}
+		*p = poly
+		return nil
+	})
+}
+
+// orientRing returns a copy of the ring wound counter clockwise when ccw is true and clockwise
+// otherwise.
+func orientRing(pts []Point2, ccw bool) []Point2 {
+	ring := make([]Point2, len(pts), len(pts)+1)
+	copy(ring, pts)
+	if (ringSignedArea(ring) > 0) != ccw {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return ring
+}
+
+// geoJSONObject holds the members of any GeoJSON object that are used when reading.
+type geoJSONObject struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometry    *geoJSONObject    `json:"geometry"`
+	Properties  map[string]any    `json:"properties"`
+	Features    []json.RawMessage `json:"features"`
+}
+
+// appendFeatures appends the features held by the object to fs.
+func (o *geoJSONObject) appendFeatures(fs *[]GeoJSONFeature) error {
+	switch o.Type {
+	case "FeatureCollection":
+		for i, raw := range o.Features {
+			var f geoJSONObject
+			if err := json.Unmarshal(raw, &f); err != nil {
+				return fmt.Errorf("geom: feature %d: %w", i, err)
+			}
+			if f.Type != "Feature" {
+				return fmt.Errorf("geom: feature %d: unexpected type %q", i, f.Type)
+			}
+			if err := f.appendFeatures(fs); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil
+		}
+		return o.Geometry.appendGeometry(fs, o.Properties)
+	}
+	return o.appendGeometry(fs, nil)
+}
+
+// appendGeometry appends a feature with the given properties for each part of the geometry.
+func (o *geoJSONObject) appendGeometry(fs *[]GeoJSONFeature, props map[string]any) error {
+	var parts []json.RawMessage
+	switch o.Type {
+	case "Point", "LineString", "Polygon":
+		parts = []json.RawMessage{o.Coordinates}
+	case "MultiPoint", "MultiLineString", "MultiPolygon":
+		if err := json.Unmarshal(o.Coordinates, &parts); err != nil {
+			return fmt.Errorf("geom: %s coordinates: %w", o.Type, err)
+		}
+	default:
+		return fmt.Errorf("geom: unsupported geojson type %q", o.Type)
+	}
+
+	for _, c := range parts {
+		f := GeoJSONFeature{Properties: props}
+		switch o.Type {
+		case "Point", "MultiPoint":
+			p, err := parseGeoJSONPosition(c)
+			if err != nil {
+				return err
+			}
+			f.Point = &p
+		case "LineString", "MultiLineString":
+			pts, err := parseGeoJSONLineString(c)
+			if err != nil {
+				return err
+			}
+			f.Path = NewPath2(pts)
+		case "Polygon", "MultiPolygon":
+			poly, err := parseGeoJSONPolygon(c)
+			if err != nil {
+				return err
+			}
+			f.Polygon = &poly
+		}
+		*fs = append(*fs, f)
+	}
+	return nil
+}
+
+func marshalGeoJSONGeometry(typ string, coords any) ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string `json:"type"`
+		Coordinates any    `json:"coordinates"`
+	}{typ, coords})
+}
+
+func unmarshalGeoJSONGeometry(data []byte, typ string, parse func(json.RawMessage) error) error {
+	var o geoJSONObject
+	if err := json.Unmarshal(data, &o); err != nil {
+		return fmt.Errorf("geom: decode geojson: %w", err)
+	}
+	if o.Type != typ {
+		return fmt.Errorf("geom: geojson type is %q, wanted %q", o.Type, typ)
+	}
+	return parse(o.Coordinates)
+}
+
+// geoJSONPosition returns the coordinates of p as JSON numbers. Formatting them directly from
+// the float32 values avoids the spurious digits that appear when they are widened to float64.
+func geoJSONPosition(p Point2) ([]json.Number, error) {
+	if !finite(p[:]...) {
+		return nil, fmt.Errorf("geom: position %v is not finite", p)
+	}
+	return []json.Number{
+		json.Number(strconv.FormatFloat(float64(p[0]), 'g', -1, 32)),
+		json.Number(strconv.FormatFloat(float64(p[1]), 'g', -1, 32)),
+	}, nil
+}
+
+func geoJSONPositions(pts []Point2) ([][]json.Number, error) {
+	c := make([][]json.Number, len(pts))
+	for i, p := range pts {
+		var err error
+		if c[i], err = geoJSONPosition(p); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func parseGeoJSONPosition(c json.RawMessage) (Point2, error) {
+	var v []float64
+	if err := json.Unmarshal(c, &v); err != nil {
+		return Point2{}, fmt.Errorf("geom: geojson position: %w", err)
+	}
+	if len(v) < 2 {
+		return Point2{}, fmt.Errorf("geom: geojson position has %d coordinates, wanted at least 2", len(v))
+	}
+	return Point2{float32(v[0]), float32(v[1])}, nil
+}
+
+func parseGeoJSONPositions(c json.RawMessage) ([]Point2, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(c, &raw); err != nil {
+		return nil, fmt.Errorf("geom: geojson positions: %w", err)
+	}
+	pts := make([]Point2, len(raw))
+	for i := range raw {
+		var err error
+		if pts[i], err = parseGeoJSONPosition(raw[i]); err != nil {
+			return nil, err
+		}
+	}
+	return pts, nil
+}
+
+func parseGeoJSONLineString(c json.RawMessage) ([]Point2, error) {
+	pts, err := parseGeoJSONPositions(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(pts) < 2 {
+		return nil, fmt.Errorf("geom: geojson line string has %d positions, wanted at least 2", len(pts))
+	}
+	return pts, nil
+}
+
+func parseGeoJSONPolygon(c json.RawMessage) (Polygon2, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(c, &raw); err != nil {
+		return Polygon2{}, fmt.Errorf("geom: geojson polygon: %w", err)
+	}
+	if len(raw) == 0 {
+		return Polygon2{}, errors.New("geom: geojson polygon has no rings")
+	}
+	var poly Polygon2
+	for i := range raw {
+		pts, err := parseGeoJSONPositions(raw[i])
+		if err != nil {
+			return Polygon2{}, err
+		}
+		if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+		if len(pts) < 3 {
+			return Polygon2{}, fmt.Errorf("geom: geojson polygon ring %d has %d distinct positions, wanted at least 3", i, len(pts))
+		}
+		if i == 0 {
+			poly.Points = orientRing(pts, true)
+		} else {
+			poly.Holes = append(poly.Holes, orientRing(pts, false))
+		}
+	}
+	return poly, nil
+}
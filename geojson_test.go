@@ -0,0 +1,160 @@
+package geom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGeoJSONPoint2(t *testing.T) {
+	data, err := MarshalGeoJSONPoint2(Point2{0.1, -2})
+	if err != nil {
+		t.Fatalf("MarshalGeoJSONPoint2: %v", err)
+	}
+	if got, want := string(data), `{"type":"Point","coordinates":[0.1,-2]}`; got != want {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+
+	p, err := UnmarshalGeoJSONPoint2([]byte(`{"type":"Point","coordinates":[3.5,4,100]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalGeoJSONPoint2: %v", err)
+	}
+	if p != (Point2{3.5, 4}) {
+		t.Errorf("got %v, wanted (3.5, 4)", p)
+	}
+
+	if _, err := UnmarshalGeoJSONPoint2([]byte(`{"type":"LineString","coordinates":[[0,0],[1,1]]}`)); err == nil {
+		t.Errorf("got no error for wrong geometry type")
+	}
+	if _, err := UnmarshalGeoJSONPoint2([]byte(`{"type":"Point","coordinates":[1]}`)); err == nil {
+		t.Errorf("got no error for short position")
+	}
+}
+
+func TestGeoJSONPolygonRoundTrip(t *testing.T) {
+	// Outer ring clockwise and hole counter clockwise, the opposite of the GeoJSON convention
+	poly := Polygon2{
+		Points: []Point2{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+		Holes:  [][]Point2{{{2, 2}, {4, 2}, {4, 4}, {2, 4}}},
+	}
+	data, err := poly.MarshalGeoJSON()
+	if err != nil {
+		t.Fatalf("MarshalGeoJSON: %v", err)
+	}
+	want := `{"type":"Polygon","coordinates":[[[10,0],[10,10],[0,10],[0,0],[10,0]],[[2,4],[4,4],[4,2],[2,2],[2,4]]]}`
+	if string(data) != want {
+		t.Errorf("got %s, wanted %s", data, want)
+	}
+
+	var got Polygon2
+	if err := got.UnmarshalGeoJSON(data); err != nil {
+		t.Fatalf("UnmarshalGeoJSON: %v", err)
+	}
+	if len(got.Points) != 4 || len(got.Holes) != 1 || len(got.Holes[0]) != 4 {
+		t.Fatalf("got %d points and %d holes, wanted 4 points and 1 hole", len(got.Points), len(got.Holes))
+	}
+	if a := ringSignedArea(got.Points); a != 100 {
+		t.Errorf("outer ring signed area: got %v, wanted 100", a)
+	}
+	if a := ringSignedArea(got.Holes[0]); a != -4 {
+		t.Errorf("hole signed area: got %v, wanted -4", a)
+	}
+	if got.Area() != 96 {
+		t.Errorf("Area: got %v, wanted 96", got.Area())
+	}
+	if got.ContainsPoint2(Point2{3, 3}) || !got.ContainsPoint2(Point2{1, 1}) {
+		t.Errorf("ContainsPoint2 does not respect the hole")
+	}
+}
+
+func TestGeoJSONPathRoundTrip(t *testing.T) {
+	p := NewPath2([]Point2{{0, 0}, {3, 4}, {3, 9}})
+	data, err := p.MarshalGeoJSON()
+	if err != nil {
+		t.Fatalf("MarshalGeoJSON: %v", err)
+	}
+	var got Path2
+	if err := got.UnmarshalGeoJSON(data); err != nil {
+		t.Fatalf("UnmarshalGeoJSON: %v", err)
+	}
+	if len(got.Points) != 3 || got.Points[1] != (Point2{3, 4}) {
+		t.Fatalf("got points %v", got.Points)
+	}
+	// The path must be usable after decoding
+	if r := got.PositionAlong(0.5); r.Origin != (Point2{3, 4}) {
+		t.Errorf("PositionAlong(0.5): got %v, wanted (3, 4)", r.Origin)
+	}
+}
+
+func TestReadGeoJSON(t *testing.T) {
+	const doc = `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"name": "spawn"}},
+			{"type": "Feature", "geometry": null, "properties": {"name": "empty"}},
+			{"type": "Feature", "geometry": {"type": "MultiPolygon", "coordinates": [
+				[[[0, 0], [1, 0], [1, 1], [0, 0]]],
+				[[[5, 5], [6, 5], [6, 6], [5, 5]]]
+			]}, "properties": {"name": "rocks"}},
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[0, 0], [0, 5]]}, "properties": null}
+		]
+	}`
+
+	fs, err := ReadGeoJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ReadGeoJSON: %v", err)
+	}
+	if len(fs) != 4 {
+		t.Fatalf("got %d features, wanted 4", len(fs))
+	}
+	if fs[0].Point == nil || *fs[0].Point != (Point2{1, 2}) || fs[0].Properties["name"] != "spawn" {
+		t.Errorf("feature 0: got %+v", fs[0])
+	}
+	for _, f := range fs[1:3] {
+		if f.Polygon == nil || len(f.Polygon.Points) != 3 || f.Properties["name"] != "rocks" {
+			t.Errorf("multipolygon part: got %+v", f)
+		}
+	}
+	if fs[3].Path == nil || len(fs[3].Path.Points) != 2 {
+		t.Errorf("feature 3: got %+v", fs[3])
+	}
+
+	// Writing and reading again gives the same features
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, fs); err != nil {
+		t.Fatalf("WriteGeoJSON: %v", err)
+	}
+	fs2, err := ReadGeoJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadGeoJSON after write: %v", err)
+	}
+	if len(fs2) != len(fs) || *fs2[0].Point != *fs[0].Point || fs2[2].Polygon.Points[0] != fs[2].Polygon.Points[0] {
+		t.Errorf("round trip changed features: got %+v", fs2)
+	}
+}
+
+func TestReadGeoJSONErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  string
+	}{
+		{name: "not json", doc: `{`},
+		{name: "unsupported", doc: `{"type": "GeometryCollection", "geometries": []}`},
+		{name: "short ring", doc: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [0, 0]]]}`},
+		{name: "no rings", doc: `{"type": "Polygon", "coordinates": []}`},
+		{name: "short line", doc: `{"type": "LineString", "coordinates": [[0, 0]]}`},
+		{name: "bad feature", doc: `{"type": "FeatureCollection", "features": [{"type": "Point", "coordinates": [0, 0]}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ReadGeoJSON(strings.NewReader(tc.doc)); err == nil {
+				t.Errorf("got no error")
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, []GeoJSONFeature{{}}); err == nil {
+		t.Errorf("WriteGeoJSON: got no error for a feature without geometry")
+	}
+}
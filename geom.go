@@ -8,6 +8,7 @@ type (
 	Vec2   = mgl32.Vec2
 	Vec3   = mgl32.Vec3
 	Vec4   = mgl32.Vec4
+	Mat3   = mgl32.Mat3
 	Mat4   = mgl32.Mat4
 	Point2 = Vec2
 	Point3 = Vec3
@@ -57,10 +58,28 @@ type Projecter interface {
 	ProjectOntoAxis(axis Vec3) Interval
 }
 
+// Projecter2 is the 2 dimensional equivalent of Projecter.
+type Projecter2 interface {
+	ProjectOntoAxis(axis Vec2) Interval
+}
+
 type Raycastable interface {
 	Raycast(ray Ray3) (RaycastResult, bool)
 }
 
+// Bounded is implemented by shapes that can report an axis-aligned box that contains them.
+type Bounded interface {
+	Bounds() AABB
+}
+
+// Shape3 is a solid 3 dimensional shape that can be tested for containment, raycast and
+// bounded.
+type Shape3 interface {
+	Bounded
+	Raycastable
+	ContainsPoint3(pt Point3) bool
+}
+
 // Box3 is a 3 dimensional cuboid
 type Box3 interface {
 	Projecter
@@ -68,34 +87,55 @@ type Box3 interface {
 	Corners() []Point3
 	Normals() []Vec3
 	ContainsPoint3(pt Point3) bool
-	// TODO
-	// Raycastable
-	Raycast(ray Ray3) (RaycastResult, bool)
+	Raycastable
+}
+
+// Box3Into is implemented by boxes that can write their axes, corners and normals into arrays
+// supplied by the caller rather than allocating slices.
+type Box3Into interface {
+	AxesInto(buf *[3]Vec3)
+	CornersInto(buf *[8]Point3)
+	NormalsInto(buf *[6]Vec3)
 }
 
 // IntersectsBox3 uses the Separating Axis Theorem (SAT) which tests the axes from a, from b and from
 // the cross-products of the axes from the two objects. Two objects only overlap if all axes
 // overlap. See http://www.dyn4j.org/2010/01/sat/
 func IntersectsBox3(a, b Box3) bool {
-	axesa := a.Axes()
-	axesb := b.Axes()
+	axesa := box3Axes(a)
+	axesb := box3Axes(b)
+
+	// Project the corners directly so that each box's corners are only found once
+	ca := box3Corners(a)
+	cb := box3Corners(b)
+	overlaps := func(axis Vec3) bool {
+		i1 := ca.ProjectOntoAxis(axis)
+		return i1.Overlaps(cb.ProjectOntoAxis(axis))
+	}
 
 	for j := 0; j < len(axesb); j++ {
-		if !OverlapOnAxis(a, b, axesb[j]) {
+		if !overlaps(axesb[j]) {
 			// A separating axis was found
 			return false
 		}
 	}
 
 	for i := 0; i < len(axesa); i++ {
-		if !OverlapOnAxis(a, b, axesa[i]) {
+		if !overlaps(axesa[i]) {
 			// A separating axis was found
 			return false
 		}
+	}
 
+	for i := 0; i < len(axesa); i++ {
 		// Check the cross product of this axis with each of b's axes
-		for j := 0; i < len(axesb); i++ {
-			if !OverlapOnAxis(a, b, axesb[j].Cross(axesa[i])) {
+		for j := 0; j < len(axesb); j++ {
+			axis := axesb[j].Cross(axesa[i])
+			if axis.LenSqr() < epsilon32 {
+				// Parallel axes, which have already been tested
+				continue
+			}
+			if !overlaps(axis) {
 				// A separating axis was found
 				return false
 			}
@@ -106,28 +146,162 @@ func IntersectsBox3(a, b Box3) bool {
 	return true
 }
 
+// box3Corners returns the corners of b in structure of arrays form.
+func box3Corners(b Box3) Vec3x8 {
+	switch bt := b.(type) {
+	case *AABB:
+		return bt.cornersSoA()
+	case *OBB:
+		return Vec3x8FromVecs(bt.cornerArray())
+	case Box3Into:
+		var buf [8]Point3
+		bt.CornersInto(&buf)
+		return Vec3x8FromVecs(buf)
+	default:
+		var buf [8]Point3
+		copy(buf[:], b.Corners())
+		return Vec3x8FromVecs(buf)
+	}
+}
+
+// box3Axes returns the axes of b. Known box types are handled directly since passing a buffer
+// through the Box3 interface forces it to be allocated on the heap.
+func box3Axes(b Box3) [3]Vec3 {
+	switch bt := b.(type) {
+	case *AABB:
+		return aabbAxes
+	case *OBB:
+		return bt.axisArray()
+	case Box3Into:
+		var buf [3]Vec3
+		bt.AxesInto(&buf)
+		return buf
+	default:
+		var buf [3]Vec3
+		copy(buf[:], b.Axes())
+		return buf
+	}
+}
+
 // Ray2 is 2 dimensional ray that starts from the origin and projects an infinite distance in the specified direction.
 type Ray2 struct {
 	Origin    Point2
 	Direction Vec2 // The direction of the ray, always normalised
 }
 
+// Ray2FromPoints returns the ray that starts at from and passes through to.
+func Ray2FromPoints(from, to Point2) Ray2 {
+	return Ray2{
+		Origin:    from,
+		Direction: to.Sub(from).Normalize(),
+	}
+}
+
 // Point returns the coordinates of the point at a distance d from the ray's origin.
 func (r *Ray2) Point(d float32) Point2 {
 	return r.Origin.Add(r.Direction.Mul(d))
 }
 
+// ClosestPoint returns the point along the ray that is closest to p
+func (r *Ray2) ClosestPoint(p Point2) Point2 {
+	// Project point onto ray, clamping to the ray's origin
+	t := max(p.Sub(r.Origin).Dot(r.Direction), 0)
+	return r.Origin.Add(r.Direction.Mul(t))
+}
+
+// Inverse returns a ray with the same origin but pointing in the opposite direction.
+func (r *Ray2) Inverse() Ray2 {
+	return Ray2{
+		Origin:    r.Origin,
+		Direction: r.Direction.Mul(-1),
+	}
+}
+
+func (r *Ray2) ApproxEqual(r2 Ray2) bool {
+	return r.Origin.ApproxEqual(r2.Origin) && r.Direction.ApproxEqual(r2.Direction)
+}
+
+func (r *Ray2) ApproxEqualThreshold(r2 Ray2, threshold float32) bool {
+	return r.Origin.ApproxEqualThreshold(r2.Origin, threshold) && r.Direction.ApproxEqualThreshold(r2.Direction, threshold)
+}
+
+// Transformed returns the ray that results from applying the transform to r, which is assumed
+// to be in the transform's local space. The direction of the result is normalized so distances
+// along it are measured in world units.
+func (r *Ray2) Transformed(tx *Transform2) Ray2 {
+	return r.TransformedMat3(tx.Matrix())
+}
+
+// InverseTransformed returns the ray that results from converting r from world space into
+// the transform's local space. The direction of the result is normalized so distances along
+// it are measured in local units.
+func (r *Ray2) InverseTransformed(tx *Transform2) Ray2 {
+	scale := tx.Scale()
+	dir := RotateVec2(r.Direction, -tx.Rotation())
+	return Ray2{
+		Origin:    tx.InverseTransformPoint(r.Origin),
+		Direction: Vec2{dir[0] / scale[0], dir[1] / scale[1]}.Normalize(),
+	}
+}
+
+// TransformedMat3 returns the ray that results from applying the 2D transformation matrix m
+// to r. The origin is transformed as a point and the direction as a vector, which is then
+// normalized.
+func (r *Ray2) TransformedMat3(m Mat3) Ray2 {
+	return Ray2{
+		Origin:    m.Mul3x1(r.Origin.Vec3(1)).Vec2(),
+		Direction: m.Mul3x1(r.Direction.Vec3(0)).Vec2().Normalize(),
+	}
+}
+
 // Ray3 is 3 dimensional ray that starts from the origin and projects an infinite distance in the specified direction.
 type Ray3 struct {
 	Origin    Point3
 	Direction Vec3 // The direction of the ray, always normalised
 }
 
+// Ray3FromPoints returns the ray that starts at from and passes through to.
+func Ray3FromPoints(from, to Point3) Ray3 {
+	return Ray3{
+		Origin:    from,
+		Direction: to.Sub(from).Normalize(),
+	}
+}
+
 // Point returns the coordinates of the point at a distance d from the ray's origin.
 func (r *Ray3) Point(d float32) Point3 {
 	return r.Origin.Add(r.Direction.Mul(d))
 }
 
+// Transformed returns the ray that results from applying the transform to r, which is assumed
+// to be in the transform's local space. The direction of the result is normalized so distances
+// along it are measured in world units.
+func (r *Ray3) Transformed(tx *Transform) Ray3 {
+	return r.TransformedMat4(tx.Matrix())
+}
+
+// InverseTransformed returns the ray that results from converting r from world space into
+// the transform's local space. The direction of the result is normalized so distances along
+// it are measured in local units.
+func (r *Ray3) InverseTransformed(tx *Transform) Ray3 {
+	scale := tx.Scale()
+	dir := tx.Orientation().Inverse().Rotate(r.Direction)
+	return Ray3{
+		Origin:    tx.InverseTransformPoint(r.Origin),
+		Direction: Vec3{dir[0] / scale[0], dir[1] / scale[1], dir[2] / scale[2]}.Normalize(),
+	}
+}
+
+// TransformedMat4 returns the ray that results from applying the transformation matrix m to r.
+// The origin is transformed as a point and the direction as a vector, which is then
+// normalized.
+func (r *Ray3) TransformedMat4(m Mat4) Ray3 {
+	return Ray3{
+		Origin:    m.Mul4x1(r.Origin.Vec4(1)).Vec3(),
+		Direction: m.Mul4x1(r.Direction.Vec4(0)).Vec3().Normalize(),
+	}
+}
+
 // ClosestPoint returns the point along the ray that is closest to p
 func (r *Ray3) ClosestPoint(p Point3) Point3 {
 	// Project point onto ray,
@@ -145,6 +319,65 @@ func (r *Ray3) Inverse() Ray3 {
 	}
 }
 
+// ClosestPointToRay returns the pair of points on r and r2 that are closest to each other,
+// together with their distances along each ray. When the rays are parallel there may be many
+// equally close pairs and the one that includes the origin of r is returned, unless the origin
+// of r lies behind r2, in which case the pair includes the origin of r2 instead.
+func (r *Ray3) ClosestPointToRay(r2 Ray3) (p1, p2 Point3, t1, t2 float32) {
+	t1, t2 = closestParams3(r.Origin, r.Direction, maxFloat32, r2.Origin, r2.Direction, maxFloat32)
+	return r.Point(t1), r2.Point(t2), t1, t2
+}
+
+// ClosestPointToSegment returns the pair of points on r and the line segment l that are
+// closest to each other. t1 is the distance along the ray and t2 is the parameter along the
+// segment in the range [0,1].
+func (r *Ray3) ClosestPointToSegment(l Line3) (p1, p2 Point3, t1, t2 float32) {
+	d := l.End.Sub(l.Start)
+	t1, t2 = closestParams3(r.Origin, r.Direction, maxFloat32, l.Start, d, 1)
+	return r.Point(t1), l.Start.Add(d.Mul(t2)), t1, t2
+}
+
+// closestParams3 finds the parameters of the closest points between the lines p1+s*d1 and
+// p2+t*d2 where s is limited to [0,smax] and t is limited to [0,tmax].
+func closestParams3(p1, d1 Vec3, smax float32, p2, d2 Vec3, tmax float32) (float32, float32) {
+	// See Ericson, Real-Time Collision Detection, 5.1.9
+	r := p1.Sub(p2)
+	a := d1.Dot(d1)
+	e := d2.Dot(d2)
+	f := d2.Dot(r)
+
+	if a <= epsilon32 && e <= epsilon32 {
+		// Both degenerate to points
+		return 0, 0
+	}
+	if a <= epsilon32 {
+		return 0, Clamp(f/e, 0, tmax)
+	}
+
+	c := d1.Dot(r)
+	if e <= epsilon32 {
+		return Clamp(-c/a, 0, smax), 0
+	}
+
+	b := d1.Dot(d2)
+	denom := a*e - b*b
+
+	var s float32
+	if denom != 0 {
+		s = Clamp((b*f-c*e)/denom, 0, smax)
+	}
+
+	t := (b*s + f) / e
+	if t < 0 {
+		t = 0
+		s = Clamp(-c/a, 0, smax)
+	} else if t > tmax {
+		t = tmax
+		s = Clamp((b*tmax-c)/a, 0, smax)
+	}
+	return s, t
+}
+
 func (r *Ray3) ApproxEqual(r2 Ray3) bool {
 	return r.Origin.ApproxEqual(r2.Origin) && r.Direction.ApproxEqual(r2.Direction)
 }
@@ -153,18 +386,76 @@ func (r *Ray3) ApproxEqualThreshold(r2 Ray3, threshold float32) bool {
 	return r.Origin.ApproxEqualThreshold(r2.Origin, threshold) && r.Direction.ApproxEqualThreshold(r2.Direction, threshold)
 }
 
+// Line2 is 2 dimensional straight line that starts at one point and ends at another.
+type Line2 struct {
+	Start Point2
+	End   Point2
+}
+
+// ClosestPoint returns the point on the line that is closest to p
+func (l *Line2) ClosestPoint(p Point2) Point2 {
+	d := l.End.Sub(l.Start)
+	lsq := d.Dot(d)
+	if lsq == 0 {
+		return l.Start
+	}
+
+	// Project point onto line, clamping to the ends of the line
+	t := Clamp(p.Sub(l.Start).Dot(d)/lsq, 0, 1)
+	return l.Start.Add(d.Mul(t))
+}
+
 // Line3 is 3 dimensional straight line that starts at one point and ends at another.
 type Line3 struct {
 	Start Point3
 	End   Point3
 }
 
+// ClosestPoint returns the point on the line that is closest to p
+func (l *Line3) ClosestPoint(p Point3) Point3 {
+	d := l.End.Sub(l.Start)
+	lsq := d.Dot(d)
+	if lsq == 0 {
+		return l.Start
+	}
+
+	// Project point onto line, clamping to the ends of the line
+	t := Clamp(p.Sub(l.Start).Dot(d)/lsq, 0, 1)
+	return l.Start.Add(d.Mul(t))
+}
+
 // RaycastResult is the result of a raycast test.
 type RaycastResult struct {
 	Point    Point3
 	Normal   Vec3
 	Distance float32
 	Fail     RaycastFail
+
+	// Enter and Exit are the distances along the ray at which it enters and leaves a convex
+	// solid such as a box, sphere or capsule. Enter is negative when the ray starts inside the
+	// solid. Both are zero for other shapes.
+	Enter, Exit float32
+
+	// Barycentric holds the weights of the corners A, B and C of the triangle that was hit
+	// that give Point. It is zero when the shape is not made of triangles.
+	Barycentric Vec3
+
+	// Index identifies the part of the shape that was hit, such as the triangle of a mesh. It
+	// is zero for shapes with a single part. A compound shape passes on the Index of the child
+	// that was hit and reports which child it was separately.
+	Index int
+
+	// Shape is the shape that was hit, when it is known. It is set by queries over collections
+	// of shapes, such as a BVH or a compound shape.
+	Shape any
+}
+
+// RaycastResult2 is the result of a 2 dimensional raycast test.
+type RaycastResult2 struct {
+	Point    Point2
+	Normal   Vec2
+	Distance float32
+	Fail     RaycastFail
 }
 
 type RaycastFail int
@@ -189,12 +480,159 @@ func (r RaycastFail) String() string {
 	}
 }
 
+// RaycastOptions changes which surfaces a raycast reports. The zero value gives the behaviour of
+// Raycast. The normal of a result is always the outward normal of the surface that was hit,
+// so it faces away from the ray when the back of a surface is hit.
+type RaycastOptions struct {
+	// TwoSided lets planes and triangles be hit from behind as well as from in front.
+	TwoSided bool
+
+	// Exit reports the point where the ray leaves a solid rather than where it enters, even
+	// when the ray starts outside it.
+	Exit bool
+}
+
+// Thickness returns the length of the part of the ray that lies within the convex solid that
+// was hit, not counting any part behind the ray's origin.
+func (r RaycastResult) Thickness() float32 {
+	return max(r.Exit-max(r.Enter, 0), 0)
+}
+
+// Reflect returns the direction of v after bouncing off a surface with normal n, which must be
+// normalized. It is typically used with the Normal of a RaycastResult.
+func Reflect(v, n Vec3) Vec3 {
+	return v.Sub(n.Mul(2 * v.Dot(n)))
+}
+
+// Refract returns the direction of v after passing through a surface with normal n, which must be
+// normalized. eta is the ratio of the refractive indices on either side of the surface. The zero
+// vector is returned when v undergoes total internal reflection.
+func Refract(v, n Vec3, eta float32) Vec3 {
+	d := n.Dot(v)
+	k := 1 - eta*eta*(1-d*d)
+	if k < 0 {
+		return Vec3{}
+	}
+	return v.Mul(eta).Sub(n.Mul(eta*d + sqrt(k)))
+}
+
+// ProjectOnPlane returns the component of v that lies in the plane with normal n, which must
+// be normalized. It removes the part of v that points into or away from the plane.
+func ProjectOnPlane(v, n Vec3) Vec3 {
+	return v.Sub(n.Mul(v.Dot(n)))
+}
+
+// ProjectOnAxis returns the component of v that lies along axis.
+func ProjectOnAxis(v, axis Vec3) Vec3 {
+	lsq := axis.Dot(axis)
+	if lsq == 0 {
+		return Vec3{}
+	}
+	return axis.Mul(v.Dot(axis) / lsq)
+}
+
+// Slide returns the velocity that remains when an object moving with velocity hits a surface
+// with normal n, which must be normalized. The part of the velocity pointing into the surface is
+// removed so the object slides along it. Velocity moving away from the surface is unchanged.
+func Slide(velocity, n Vec3) Vec3 {
+	d := velocity.Dot(n)
+	if d >= 0 {
+		return velocity
+	}
+	return velocity.Sub(n.Mul(d))
+}
+
+// Bounce returns the velocity of an object after it hits a surface with normal n, which must be
+// normalized. restitution controls how much of the velocity into the surface is returned, from 0
+// for no bounce, which is the same as Slide, to 1 for a perfect reflection. Velocity moving away
+// from the surface is unchanged.
+func Bounce(velocity, n Vec3, restitution float32) Vec3 {
+	d := velocity.Dot(n)
+	if d >= 0 {
+		return velocity
+	}
+	return velocity.Sub(n.Mul((1 + restitution) * d))
+}
+
+// Reflect2 returns the direction of v after bouncing off a surface with normal n, which must be
+// normalized.
+func Reflect2(v, n Vec2) Vec2 {
+	return v.Sub(n.Mul(2 * v.Dot(n)))
+}
+
+// Refract2 returns the direction of v after passing through a surface with normal n, which must be
+// normalized. eta is the ratio of the refractive indices on either side of the surface. The zero
+// vector is returned when v undergoes total internal reflection.
+func Refract2(v, n Vec2, eta float32) Vec2 {
+	d := n.Dot(v)
+	k := 1 - eta*eta*(1-d*d)
+	if k < 0 {
+		return Vec2{}
+	}
+	return v.Mul(eta).Sub(n.Mul(eta*d + sqrt(k)))
+}
+
+// ProjectOnLine2 returns the component of v that lies along a line with normal n, which must be
+// normalized. It is the 2 dimensional equivalent of ProjectOnPlane.
+func ProjectOnLine2(v, n Vec2) Vec2 {
+	return v.Sub(n.Mul(v.Dot(n)))
+}
+
+// ProjectOnAxis2 returns the component of v that lies along axis.
+func ProjectOnAxis2(v, axis Vec2) Vec2 {
+	lsq := axis.Dot(axis)
+	if lsq == 0 {
+		return Vec2{}
+	}
+	return axis.Mul(v.Dot(axis) / lsq)
+}
+
+// Slide2 returns the velocity that remains when an object moving with velocity hits a surface
+// with normal n, which must be normalized. It is the 2 dimensional equivalent of Slide.
+func Slide2(velocity, n Vec2) Vec2 {
+	d := velocity.Dot(n)
+	if d >= 0 {
+		return velocity
+	}
+	return velocity.Sub(n.Mul(d))
+}
+
+// Bounce2 returns the velocity of an object after it hits a surface with normal n, which must be
+// normalized. It is the 2 dimensional equivalent of Bounce.
+func Bounce2(velocity, n Vec2, restitution float32) Vec2 {
+	d := velocity.Dot(n)
+	if d >= 0 {
+		return velocity
+	}
+	return velocity.Sub(n.Mul((1 + restitution) * d))
+}
+
+var _ Projecter2 = (*AABB2)(nil)
+
 // Rect is a 2 dimensional axis-aligned rectangle
 type Rect struct {
 	Position Point2 // Centre of the rectangle
 	Size     Vec2   // HALF SIZE!
 }
 
+// AABB2 is a 2 dimensional axis-aligned bounding box. It is the same type as Rect and is
+// provided so that code handling both 2D and 3D shapes can name them consistently.
+type AABB2 = Rect
+
+// RectFromCorners returns the Rect that spans the two corner points.
+func RectFromCorners(pmin, pmax Point2) Rect {
+	r := Rect{
+		Size: Vec2{
+			abs(pmax[0]-pmin[0]) / 2,
+			abs(pmax[1]-pmin[1]) / 2,
+		},
+	}
+
+	r.Position[0] = min(pmin[0], pmax[0]) + r.Size[0]
+	r.Position[1] = min(pmin[1], pmax[1]) + r.Size[1]
+	return r
+}
+
 // Min returns the minimum point of the Rect
 func (r Rect) Min() Point2 {
 	p1 := r.Position.Add(r.Size)
@@ -240,9 +678,28 @@ func (r Rect) Shrink(v float32) Rect {
 	}
 }
 
+// Inflate grows the rect by d in every direction. The result contains every point within d of
+// the original rect.
+func (r Rect) Inflate(d float32) Rect {
+	return Rect{
+		Position: r.Position,
+		Size:     Vec2{r.Size[0] + d, r.Size[1] + d},
+	}
+}
+
 func (r Rect) Width() float32  { return r.Size[0] * 2 }
 func (r Rect) Height() float32 { return r.Size[1] * 2 }
 
+// Area returns the area of the rectangle.
+func (r Rect) Area() float32 {
+	return 4 * r.Size[0] * r.Size[1]
+}
+
+// Perimeter returns the total length of the rectangle's edges.
+func (r Rect) Perimeter() float32 {
+	return 4 * (r.Size[0] + r.Size[1])
+}
+
 // Contains reports whether p is contained within the bounds of the Rect
 func (r *Rect) ContainsPoint2(pt Point2) bool {
 	min := r.Min()
@@ -263,7 +720,8 @@ func (r Rect) IntersectsRect(r2 Rect) bool {
 }
 
 // MTVRect returns the MTV (Minimum Translation Vector) for an overlapping Rect. The MTV is
-// the vector that should be applied to r2 to ensure it does not overlap r
+// the vector that should be applied to r2 to ensure it does not overlap r. Note that this is
+// the opposite sense to MTVAABB.
 func (r Rect) MTVRect(r2 *Rect) (bool, Vec2) {
 	rMin := r.Min()
 	rMax := r.Max()
@@ -300,7 +758,84 @@ func (r Rect) MTVRect(r2 *Rect) (bool, Vec2) {
 	return true, Vec2{0, -overlap1}
 }
 
-var _ Box3 = (*AABB)(nil)
+// MTVAABB2 returns the MTV (Minimum Translation Vector) for an overlapping AABB2. The MTV is
+// the vector that should be applied to r to ensure it does not overlap b, which is the same
+// sense as MTVAABB.
+func (r Rect) MTVAABB2(b *Rect) (bool, Vec2) {
+	return b.MTVRect(&r)
+}
+
+// ClosestPoint returns the point in the Rect that is closest to p
+func (r Rect) ClosestPoint(p Point2) Point2 {
+	min := r.Min()
+	max := r.Max()
+	return Point2{Clamp(p[0], min[0], max[0]), Clamp(p[1], min[1], max[1])}
+}
+
+// ProjectOntoAxis returns the interval covered by the Rect when projected onto the axis.
+func (r Rect) ProjectOntoAxis(axis Vec2) Interval {
+	c := r.Position.Dot(axis)
+	e := abs(r.Size[0]*axis[0]) + abs(r.Size[1]*axis[1])
+	return Interval{Min: c - e, Max: c + e}
+}
+
+// Raycast2 tests whether the ray intersects the Rect. When the ray starts inside the Rect the
+// result is the point where it leaves. The normal is that of the edge that is hit, pointing
+// out of the Rect.
+func (r Rect) Raycast2(ray Ray2) (RaycastResult2, bool) {
+	var res RaycastResult2
+	rmin := r.Min()
+	rmax := r.Max()
+
+	tmin, tmax := float32(-maxFloat32), float32(maxFloat32)
+	var nmin, nmax Vec2
+	for i := 0; i < 2; i++ {
+		if ray.Direction[i] == 0 {
+			// Parallel to the slab, so the origin must lie within it
+			if ray.Origin[i] < rmin[i] || ray.Origin[i] > rmax[i] {
+				res.Fail = RaycastFailOutsideBounds
+				return res, false
+			}
+			continue
+		}
+		var n Vec2
+		n[i] = -1
+		t0 := (rmin[i] - ray.Origin[i]) / ray.Direction[i]
+		t1 := (rmax[i] - ray.Origin[i]) / ray.Direction[i]
+		n0, n1 := n, n.Mul(-1)
+		if t0 > t1 {
+			t0, t1 = t1, t0
+			n0, n1 = n1, n0
+		}
+		if t0 > tmin {
+			tmin, nmin = t0, n0
+		}
+		if t1 < tmax {
+			tmax, nmax = t1, n1
+		}
+	}
+
+	if tmax < 0 {
+		res.Fail = RaycastFailTargetBehindRayOrigin
+		return res, false
+	}
+	if tmin > tmax {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+
+	res.Distance, res.Normal = tmin, nmin
+	if tmin < 0 {
+		res.Distance, res.Normal = tmax, nmax
+	}
+	res.Point = ray.Point(res.Distance)
+	return res, true
+}
+
+var (
+	_ Box3     = (*AABB)(nil)
+	_ Box3Into = (*AABB)(nil)
+)
 
 var (
 	aabbAxes    = [3]Vec3{X3, Y3, Z3}
@@ -317,8 +852,20 @@ var (
 // AABB is a 3 dimensional axis-aligned bounding box
 type AABB struct {
 	Position Point3
-	Size     Vec3      // HALF SIZE, i.e. the size in each direction
-	corners  [8]Point3 // pre-allocated space to avoid allocations during calls to Corners
+	Size     Vec3 // HALF SIZE, i.e. the size in each direction
+}
+
+// AABBFromPoints returns the smallest AABB that contains all of the points.
+func AABBFromPoints(pts []Point3) AABB {
+	if len(pts) == 0 {
+		return AABB{}
+	}
+	pmin, pmax := pts[0], pts[0]
+	for _, p := range pts[1:] {
+		pmin = Point3{min(pmin[0], p[0]), min(pmin[1], p[1]), min(pmin[2], p[2])}
+		pmax = Point3{max(pmax[0], p[0]), max(pmax[1], p[1]), max(pmax[2], p[2])}
+	}
+	return AABBFromCorners(pmin, pmax)
 }
 
 func AABBFromCorners(pmin, pmax Point3) AABB {
@@ -336,6 +883,26 @@ func AABBFromCorners(pmin, pmax Point3) AABB {
 	return a
 }
 
+// Bounds returns a copy of the AABB.
+func (a *AABB) Bounds() AABB {
+	return *a
+}
+
+// Center returns the point at the centre of the box.
+func (a *AABB) Center() Point3 {
+	return a.Position
+}
+
+// Volume returns the volume of the box.
+func (a *AABB) Volume() float32 {
+	return 8 * a.Size[0] * a.Size[1] * a.Size[2]
+}
+
+// SurfaceArea returns the total area of the box's faces.
+func (a *AABB) SurfaceArea() float32 {
+	return 8 * (a.Size[0]*a.Size[1] + a.Size[1]*a.Size[2] + a.Size[2]*a.Size[0])
+}
+
 // Min returns the minimum point of the AABB
 func (a *AABB) Min() Point3 {
 	p1 := a.Position.Add(a.Size)
@@ -360,30 +927,65 @@ func (a *AABB) Max() Point3 {
 	}
 }
 
-// Corners returns the points at the eight corners of the box.
+// Corners returns the points at the eight corners of the box. A new slice is returned on
+// each call.
 func (a *AABB) Corners() []Point3 {
+	corners := a.cornerArray()
+	return corners[:]
+}
+
+func (a *AABB) cornerArray() [8]Point3 {
+	min := a.Min()
+	max := a.Max()
+
+	return [8]Point3{
+		{min[0], max[1], max[2]},
+		{min[0], max[1], min[2]},
+		{min[0], min[1], max[2]},
+		{min[0], min[1], min[2]},
+		{max[0], max[1], max[2]},
+		{max[0], max[1], min[2]},
+		{max[0], min[1], max[2]},
+		{max[0], min[1], min[2]},
+	}
+}
+
+// cornersSoA returns the corners of the box in the same order as cornerArray, in structure of
+// arrays form.
+func (a *AABB) cornersSoA() Vec3x8 {
 	min := a.Min()
 	max := a.Max()
 
-	a.corners[0] = Point3{min[0], max[1], max[2]}
-	a.corners[0] = Point3{min[0], max[1], min[2]}
-	a.corners[0] = Point3{min[0], min[1], max[2]}
-	a.corners[0] = Point3{min[0], min[1], min[2]}
-	a.corners[0] = Point3{max[0], max[1], max[2]}
-	a.corners[0] = Point3{max[0], max[1], min[2]}
-	a.corners[0] = Point3{max[0], min[1], max[2]}
-	a.corners[0] = Point3{max[0], min[1], min[2]}
-	return a.corners[:]
+	return Vec3x8{
+		X: [8]float32{min[0], min[0], min[0], min[0], max[0], max[0], max[0], max[0]},
+		Y: [8]float32{max[1], max[1], min[1], min[1], max[1], max[1], min[1], min[1]},
+		Z: [8]float32{max[2], min[2], max[2], min[2], max[2], min[2], max[2], min[2]},
+	}
+}
+
+// CornersInto writes the points at the eight corners of the box into buf.
+func (a *AABB) CornersInto(buf *[8]Point3) {
+	*buf = a.cornerArray()
 }
 
 func (a *AABB) Axes() []Vec3 {
 	return aabbAxes[:]
 }
 
+// AxesInto writes the three axes of the box into buf.
+func (a *AABB) AxesInto(buf *[3]Vec3) {
+	*buf = aabbAxes
+}
+
 func (a *AABB) Normals() []Vec3 {
 	return aabbNormals[:]
 }
 
+// NormalsInto writes the normals of the six faces of the box into buf.
+func (a *AABB) NormalsInto(buf *[6]Vec3) {
+	*buf = aabbNormals
+}
+
 // Contains reports whether p is contained within the bounds of the AABB
 func (a *AABB) ContainsPoint3(pt Point3) bool {
 	min := a.Min()
@@ -399,6 +1001,15 @@ func (a *AABB) ContainsPoint3(pt Point3) bool {
 	return true
 }
 
+// Inflate grows the box by d in every direction. The result contains every point within d of
+// the original box.
+func (a *AABB) Inflate(d float32) AABB {
+	return AABB{
+		Position: a.Position,
+		Size:     Vec3{a.Size[0] + d, a.Size[1] + d, a.Size[2] + d},
+	}
+}
+
 // ClosestPoint returns the point in the AABB that is closest to p
 func (a *AABB) ClosestPoint(p Point3) Point3 {
 	min := a.Min()
@@ -438,7 +1049,8 @@ func (a *AABB) IntersectsAABB(b *AABB) bool {
 		(aMin[2] <= bMax[2] && aMax[2] >= bMin[2])
 }
 
-// MTVAABB returns the MTV (Minimum Translation Vector) for an overlapping AABB
+// MTVAABB returns the MTV (Minimum Translation Vector) for an overlapping AABB. The MTV is
+// the vector that should be applied to a to ensure it does not overlap b.
 func (a *AABB) MTVAABB(b *AABB) (bool, Vec3) {
 	aMin := a.Min()
 	aMax := a.Max()
@@ -495,104 +1107,51 @@ func (a *AABB) MTVAABB(b *AABB) (bool, Vec3) {
 }
 
 func (a *AABB) ProjectOntoAxis(axis Vec3) Interval {
-	vertex := a.Corners()
-
-	var in Interval
-	in.Min = axis.Dot(vertex[0])
-	in.Max = in.Min
+	corners := a.cornersSoA()
+	return corners.ProjectOntoAxis(axis)
+}
 
-	for i := 1; i < 8; i++ {
-		projection := axis.Dot(vertex[i])
-		if projection < in.Min {
-			in.Min = projection
-		}
-		if projection > in.Max {
-			in.Max = projection
+// support returns the corner of the box that lies furthest in the direction d.
+func (a *AABB) support(d Vec3) Point3 {
+	p := a.Position
+	for i := 0; i < 3; i++ {
+		if d[i] < 0 {
+			p[i] -= a.Size[i]
+		} else {
+			p[i] += a.Size[i]
 		}
 	}
-
-	return in
+	return p
 }
 
-// Raycast tests whether the ray intersects the AABB
+// Raycast tests whether the ray intersects the AABB. A ray that starts inside the box hits the
+// face it leaves through.
 func (a *AABB) Raycast(ray Ray3) (RaycastResult, bool) {
-	var res RaycastResult
-	amin := a.Min()
-	amax := a.Max()
-
-	// debug("aabb min=", amin, "max=", amax)
-	// debug("ray origin=", ray.Origin, "direction=", ray.Direction)
-	// Any component of direction could be 0!
-	// Address this by using a small number, close to
-	// 0 in case any of directions components are 0
-	t := [6]float32{
-		(amin[0] - ray.Origin[0]) / nonzero(ray.Direction[0]),
-		(amax[0] - ray.Origin[0]) / nonzero(ray.Direction[0]),
-		(amin[1] - ray.Origin[1]) / nonzero(ray.Direction[1]),
-		(amax[1] - ray.Origin[1]) / nonzero(ray.Direction[1]),
-		(amin[2] - ray.Origin[2]) / nonzero(ray.Direction[2]),
-		(amax[2] - ray.Origin[2]) / nonzero(ray.Direction[2]),
-	}
-
-	tmin := max(max(min(t[0], t[1]), min(t[2], t[3])), min(t[4], t[5]))
-	tmax := min(min(max(t[0], t[1]), max(t[2], t[3])), max(t[4], t[5]))
-
-	// if tmax < 0, ray is intersecting AABB
-	// but entire AABB is behind it's origin
-	if tmax < 0 {
-		// debug("tmax=", tmax, " < 0, entire aabb is behind ray's origin")
-		res.Fail = RaycastFailTargetBehindRayOrigin
-		return res, false
-	}
-
-	// if tmin > tmax, ray doesn't intersect AABB
-	if tmin > tmax {
-		// debug("tmin > tmax, ray doesn't intersect AABB")
-		res.Fail = RaycastFailOutsideBounds
-		return res, false
-	}
-
-	res.Distance = tmin
-
-	// If tmin is < 0, tmax is closer
-	if tmin < 0 {
-		res.Distance = tmax
-	}
-
-	res.Point = ray.Point(res.Distance)
-
-	// Find closest side to the ray
-	normals := [6]Vec3{
-		{-1, 0, 0},
-		{1, 0, 0},
-		{0, -1, 0},
-		{0, 1, 0},
-		{0, 0, -1},
-		{0, 0, 1},
-	}
-
-	for i := 0; i < 6; i++ {
-		if cmp(res.Distance, t[i]) {
-			res.Normal = normals[i]
-		}
-	}
+	p := ray.Prepare()
+	return a.raycastPrepared(&p, false)
+}
 
-	return res, true
+// RaycastWith is like Raycast but takes options.
+func (a *AABB) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	p := ray.Prepare()
+	return a.raycastPrepared(&p, opts.Exit)
 }
 
+// OBB returns the box that results from applying the transform to the AABB. The AABB is
+// assumed to be in the transform's local space.
 func (a *AABB) OBB(tx *Transform) OBB {
-	o := OBB{
-		Position:    tx.Pos(),
-		Size:        a.Size,
-		Orientation: tx.Orientation(),
-	}
-
+	size := a.Size
 	scale := tx.Scale()
-	o.Size[0] *= scale[0]
-	o.Size[1] *= scale[1]
-	o.Size[2] *= scale[2]
+	size[0] *= abs(scale[0])
+	size[1] *= abs(scale[1])
+	size[2] *= abs(scale[2])
 
-	return o
+	return NewOBB(tx.TransformPoint(a.Position), size, tx.Orientation())
+}
+
+// maxScale returns the largest magnitude of the scale components.
+func maxScale(scale Vec3) float32 {
+	return max(max(abs(scale[0]), abs(scale[1])), abs(scale[2]))
 }
 
 // Plane3 is a plane in 3 dimensions
@@ -604,19 +1163,25 @@ type Plane3 struct {
 // Raycast tests whether the ray intersects the Plane.
 // See https://www.cs.princeton.edu/courses/archive/fall00/cs426/lectures/raycast/sld017.htm
 func (p *Plane3) Raycast(ray Ray3) (RaycastResult, bool) {
+	return p.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options. A two sided plane is hit by rays from either
+// side.
+func (p *Plane3) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
 	var res RaycastResult
 
 	nd := ray.Direction.Dot(p.Normal)
 	pn := ray.Origin.Dot(p.Normal)
 
-	// if nd is positive, the ray and plane normals
-	// point in the same direction. No intersection.
-	if nd >= 0 {
+	// A ray parallel to the plane never meets it. If nd is positive, the ray and plane
+	// normals point in the same direction so the ray can only hit the back of the plane.
+	if nd == 0 || (nd > 0 && !opts.TwoSided) {
 		res.Fail = RaycastFailPlaneFacesAwayFromRay
 		return res, false
 	}
 
-	t := -(p.Distance + pn) / nd
+	t := (p.Distance - pn) / nd
 
 	// t must be positive
 	if t >= 0.0 {
@@ -662,8 +1227,74 @@ func (v1 Vec2i) Mul2(v Vec2) Vec2i {
 	return Vec2i{int32(float32(v1[0]) * v[0]), int32(float32(v1[1]) * v[1])}
 }
 
-// Add performs element-wise addition between two vectors.
-func (v1 Vec3i) Add(v2 Vec3i) Vec3i {
+// Div performs a scalar integer division of the vector by some constant value, truncating toward zero.
+func (v1 Vec2i) Div(c int32) Vec2i {
+	return Vec2i{v1[0] / c, v1[1] / c}
+}
+
+// Dot returns the dot product of two vectors.
+func (v1 Vec2i) Dot(v2 Vec2i) int32 {
+	return v1[0]*v2[0] + v1[1]*v2[1]
+}
+
+// LenSquared returns the square of the length of the vector.
+func (v1 Vec2i) LenSquared() int32 {
+	return v1.Dot(v1)
+}
+
+// Len returns the length of the vector.
+func (v1 Vec2i) Len() float32 {
+	return sqrt(float32(v1.LenSquared()))
+}
+
+// Neg returns the vector pointing in the opposite direction.
+func (v1 Vec2i) Neg() Vec2i {
+	return Vec2i{-v1[0], -v1[1]}
+}
+
+// Abs returns a vector containing the absolute value of each element.
+func (v1 Vec2i) Abs() Vec2i {
+	return Vec2i{absi(v1[0]), absi(v1[1])}
+}
+
+// Min returns a vector containing the minimum of each element of the two vectors.
+func (v1 Vec2i) Min(v2 Vec2i) Vec2i {
+	return Vec2i{mini(v1[0], v2[0]), mini(v1[1], v2[1])}
+}
+
+// Max returns a vector containing the maximum of each element of the two vectors.
+func (v1 Vec2i) Max(v2 Vec2i) Vec2i {
+	return Vec2i{maxi(v1[0], v2[0]), maxi(v1[1], v2[1])}
+}
+
+// X returns the first element of the vector.
+func (v1 Vec2i) X() int32 { return v1[0] }
+
+// Y returns the second element of the vector.
+func (v1 Vec2i) Y() int32 { return v1[1] }
+
+// ManhattanDistance returns the sum of the absolute differences between the elements of the two
+// vectors, which is the number of orthogonal steps on a grid needed to move between them.
+func (v1 Vec2i) ManhattanDistance(v2 Vec2i) int32 {
+	d := v1.Sub(v2).Abs()
+	return d[0] + d[1]
+}
+
+// ChebyshevDistance returns the largest absolute difference between the elements of the two
+// vectors, which is the number of steps on a grid needed to move between them when diagonal
+// steps are allowed.
+func (v1 Vec2i) ChebyshevDistance(v2 Vec2i) int32 {
+	d := v1.Sub(v2).Abs()
+	return maxi(d[0], d[1])
+}
+
+// Vec2 converts the vector to a floating point vector.
+func (v1 Vec2i) Vec2() Vec2 {
+	return Vec2{float32(v1[0]), float32(v1[1])}
+}
+
+// Add performs element-wise addition between two vectors.
+func (v1 Vec3i) Add(v2 Vec3i) Vec3i {
 	return Vec3i{v1[0] + v2[0], v1[1] + v2[1], v1[2] + v2[2]}
 }
 
@@ -672,11 +1303,138 @@ func (v1 Vec3i) Sub(v2 Vec3i) Vec3i {
 	return Vec3i{v1[0] - v2[0], v1[1] - v2[1], v1[2] - v2[2]}
 }
 
+// Mul performs a scalar multiplication between the vector and some constant value
+func (v1 Vec3i) Mul(c float32) Vec3i {
+	return Vec3i{int32(float32(v1[0]) * c), int32(float32(v1[1]) * c), int32(float32(v1[2]) * c)}
+}
+
+// Mul3 performs an element-wise scalar multiplication between the vector and another vector
+func (v1 Vec3i) Mul3(v Vec3) Vec3i {
+	return Vec3i{int32(float32(v1[0]) * v[0]), int32(float32(v1[1]) * v[1]), int32(float32(v1[2]) * v[2])}
+}
+
+// Div performs a scalar integer division of the vector by some constant value, truncating toward zero.
+func (v1 Vec3i) Div(c int32) Vec3i {
+	return Vec3i{v1[0] / c, v1[1] / c, v1[2] / c}
+}
+
+// Dot returns the dot product of two vectors.
+func (v1 Vec3i) Dot(v2 Vec3i) int32 {
+	return v1[0]*v2[0] + v1[1]*v2[1] + v1[2]*v2[2]
+}
+
+// LenSquared returns the square of the length of the vector.
+func (v1 Vec3i) LenSquared() int32 {
+	return v1.Dot(v1)
+}
+
+// Len returns the length of the vector.
+func (v1 Vec3i) Len() float32 {
+	return sqrt(float32(v1.LenSquared()))
+}
+
+// Neg returns the vector pointing in the opposite direction.
+func (v1 Vec3i) Neg() Vec3i {
+	return Vec3i{-v1[0], -v1[1], -v1[2]}
+}
+
+// Abs returns a vector containing the absolute value of each element.
+func (v1 Vec3i) Abs() Vec3i {
+	return Vec3i{absi(v1[0]), absi(v1[1]), absi(v1[2])}
+}
+
+// Min returns a vector containing the minimum of each element of the two vectors.
+func (v1 Vec3i) Min(v2 Vec3i) Vec3i {
+	return Vec3i{mini(v1[0], v2[0]), mini(v1[1], v2[1]), mini(v1[2], v2[2])}
+}
+
+// Max returns a vector containing the maximum of each element of the two vectors.
+func (v1 Vec3i) Max(v2 Vec3i) Vec3i {
+	return Vec3i{maxi(v1[0], v2[0]), maxi(v1[1], v2[1]), maxi(v1[2], v2[2])}
+}
+
+// X returns the first element of the vector.
+func (v1 Vec3i) X() int32 { return v1[0] }
+
+// Y returns the second element of the vector.
+func (v1 Vec3i) Y() int32 { return v1[1] }
+
+// Z returns the third element of the vector.
+func (v1 Vec3i) Z() int32 { return v1[2] }
+
+// ManhattanDistance returns the sum of the absolute differences between the elements of the two
+// vectors, which is the number of orthogonal steps on a grid needed to move between them.
+func (v1 Vec3i) ManhattanDistance(v2 Vec3i) int32 {
+	d := v1.Sub(v2).Abs()
+	return d[0] + d[1] + d[2]
+}
+
+// ChebyshevDistance returns the largest absolute difference between the elements of the two
+// vectors, which is the number of steps on a grid needed to move between them when diagonal
+// steps are allowed.
+func (v1 Vec3i) ChebyshevDistance(v2 Vec3i) int32 {
+	d := v1.Sub(v2).Abs()
+	return maxi(maxi(d[0], d[1]), d[2])
+}
+
+// Vec3 converts the vector to a floating point vector.
+func (v1 Vec3i) Vec3() Vec3 {
+	return Vec3{float32(v1[0]), float32(v1[1]), float32(v1[2])}
+}
+
+var _ Projecter = (*Sphere)(nil)
+
 type Sphere struct {
 	Position Point3
 	Radius   float32
 }
 
+// Transformed returns the sphere that results from applying the transform to s, which is
+// assumed to be in the transform's local space. A non-uniform scale would turn the sphere into
+// an ellipsoid so the radius is scaled by the largest scale component, producing a sphere that
+// contains the transformed shape.
+func (s *Sphere) Transformed(tx *Transform) Sphere {
+	return Sphere{
+		Position: tx.TransformPoint(s.Position),
+		Radius:   s.Radius * maxScale(tx.Scale()),
+	}
+}
+
+// Inflate grows the sphere's radius by d, giving the sphere that contains every point within d
+// of the original sphere.
+func (s *Sphere) Inflate(d float32) Sphere {
+	return Sphere{Position: s.Position, Radius: s.Radius + d}
+}
+
+// support returns the point of the sphere that lies furthest in the direction d.
+func (s *Sphere) support(d Vec3) Point3 {
+	l := d.Len()
+	if l == 0 {
+		return s.Position
+	}
+	return s.Position.Add(d.Mul(s.Radius / l))
+}
+
+// Bounds returns the smallest AABB that contains the sphere.
+func (s *Sphere) Bounds() AABB {
+	return AABB{Position: s.Position, Size: Vec3{s.Radius, s.Radius, s.Radius}}
+}
+
+// Center returns the point at the centre of the sphere.
+func (s *Sphere) Center() Point3 {
+	return s.Position
+}
+
+// Volume returns the volume of the sphere.
+func (s *Sphere) Volume() float32 {
+	return 4 * pi * s.Radius * s.Radius * s.Radius / 3
+}
+
+// SurfaceArea returns the area of the sphere's surface.
+func (s *Sphere) SurfaceArea() float32 {
+	return 4 * pi * s.Radius * s.Radius
+}
+
 // ClosestPoint returns the point on the sphere that is closest to point
 func (s *Sphere) ClosestPoint(point Point3) Point3 {
 	sphereToPoint := point.Sub(s.Position).Normalize()
@@ -693,8 +1451,21 @@ func (s *Sphere) ContainsPoint3(point Point3) bool {
 	return eMagnitudeSquared < rSquared
 }
 
-// Raycast tests whether the ray intersects the Sphere.
+// ProjectOntoAxis returns the interval covered by the sphere when projected onto the axis.
+func (s *Sphere) ProjectOntoAxis(axis Vec3) Interval {
+	c := s.Position.Dot(axis)
+	r := s.Radius * axis.Len()
+	return Interval{Min: c - r, Max: c + r}
+}
+
+// Raycast tests whether the ray intersects the Sphere. A ray that starts inside the sphere hits
+// the surface on its way out.
 func (s *Sphere) Raycast(ray Ray3) (RaycastResult, bool) {
+	return s.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options.
+func (s *Sphere) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
 	var res RaycastResult
 
 	e := s.Position.Sub(ray.Origin)
@@ -704,20 +1475,23 @@ func (s *Sphere) Raycast(ray Ray3) (RaycastResult, bool) {
 	a := e.Dot(ray.Direction)
 
 	bSquared := eMagnitudeSquared - (a * a)
-	f := sqrt(abs(rSquared - bSquared))
-
-	// Assume normal intersection
-	t := a - f
-
 	if rSquared-bSquared < 0 {
 		// No collision has happened
-
 		res.Fail = RaycastFailOutsideBounds
 		return res, false
-	} else if eMagnitudeSquared < rSquared {
-		// Ray starts inside the sphere
-		// Reverse direction
-		t = a + f
+	}
+	f := sqrt(rSquared - bSquared)
+
+	res.Enter, res.Exit = a-f, a+f
+	if res.Exit < 0 {
+		res.Fail = RaycastFailTargetBehindRayOrigin
+		return res, false
+	}
+
+	// Assume normal intersection, unless the ray starts inside the sphere
+	t := res.Enter
+	if t < 0 || opts.Exit {
+		t = res.Exit
 	}
 
 	res.Distance = t
@@ -803,6 +1577,13 @@ func maxi(a, b int32) int32 {
 	return b
 }
 
+func absi(a int32) int32 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
 func RectiFromCorners(tl, br Point2i) Recti {
 	size := Point2i{(br[0] - tl[0]) / 2, (br[1] - tl[1]) / 2}
 	return Recti{
@@ -811,6 +1592,11 @@ func RectiFromCorners(tl, br Point2i) Recti {
 	}
 }
 
+var (
+	_ Projecter   = Tri3{}
+	_ Raycastable = Tri3{}
+)
+
 // Tri3 is a triangle whose corners are 3 points in 3 dimensions. A,B and C
 // are assume to  be in counter clockwise order.
 type Tri3 struct {
@@ -878,6 +1664,129 @@ func (t Tri3) BarycentricPoint3(pt Point3) Vec3 {
 	return result
 }
 
+// Normal returns the unit normal of the triangle. The normal faces the side from which the
+// corners appear in counter clockwise order. A degenerate triangle has a zero normal.
+func (t Tri3) Normal() Vec3 {
+	n := t.B.Sub(t.A).Cross(t.C.Sub(t.A))
+	l := n.Len()
+	if l == 0 {
+		return Vec3{}
+	}
+	return n.Mul(1 / l)
+}
+
+// Area returns the area of the triangle.
+func (t Tri3) Area() float32 {
+	return t.B.Sub(t.A).Cross(t.C.Sub(t.A)).Len() / 2
+}
+
+// Perimeter returns the total length of the edges of the triangle.
+func (t Tri3) Perimeter() float32 {
+	return t.B.Sub(t.A).Len() + t.C.Sub(t.B).Len() + t.A.Sub(t.C).Len()
+}
+
+// IsDegenerate reports whether the triangle has collapsed to a line or a point, meaning it
+// has no usable normal. The test is relative to the length of the longest edge so it is
+// independent of the scale of the triangle.
+func (t Tri3) IsDegenerate() bool {
+	ab := t.B.Sub(t.A)
+	ac := t.C.Sub(t.A)
+	longest := max(max(ab.LenSqr(), ac.LenSqr()), t.C.Sub(t.B).LenSqr())
+	if longest == 0 {
+		return true
+	}
+	return ab.Cross(ac).Len() <= degenerateTolerance*longest
+}
+
+// CircumSphere returns the smallest sphere whose surface passes through all three corners
+// of the triangle. The centre of the sphere lies in the plane of the triangle. A degenerate
+// triangle has no circumsphere and returns a zero sphere.
+func (t Tri3) CircumSphere() Sphere {
+	a := t.A.Sub(t.C)
+	b := t.B.Sub(t.C)
+	n := a.Cross(b)
+	d := 2 * n.LenSqr()
+	if d == 0 {
+		return Sphere{}
+	}
+
+	offset := b.Mul(a.LenSqr()).Sub(a.Mul(b.LenSqr())).Cross(n).Mul(1 / d)
+	return Sphere{
+		Position: t.C.Add(offset),
+		Radius:   offset.Len(),
+	}
+}
+
+// InCircle returns the centre and radius of the largest circle that fits inside the
+// triangle, which touches each of its edges. The circle lies in the plane of the triangle.
+func (t Tri3) InCircle() (Point3, float32) {
+	a := t.C.Sub(t.B).Len()
+	b := t.A.Sub(t.C).Len()
+	c := t.B.Sub(t.A).Len()
+	p := a + b + c
+	if p == 0 {
+		return t.A, 0
+	}
+	return t.A.Mul(a).Add(t.B.Mul(b)).Add(t.C.Mul(c)).Mul(1 / p), 2 * t.Area() / p
+}
+
+// degenerateTolerance is the smallest ratio of twice a triangle's area to the square of its
+// longest edge for the triangle to be considered non-degenerate.
+const degenerateTolerance = 1e-6
+
+// ClosestPoint returns the point on the triangle that is closest to p
+func (t Tri3) ClosestPoint(p Point3) Point3 {
+	// See Ericson, Real-Time Collision Detection, 5.1.5
+	ab := t.B.Sub(t.A)
+	ac := t.C.Sub(t.A)
+
+	// Vertex region outside A
+	ap := p.Sub(t.A)
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return t.A
+	}
+
+	// Vertex region outside B
+	bp := p.Sub(t.B)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return t.B
+	}
+
+	// Edge region of AB
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return t.A.Add(ab.Mul(d1 / (d1 - d3)))
+	}
+
+	// Vertex region outside C
+	cp := p.Sub(t.C)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return t.C
+	}
+
+	// Edge region of AC
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return t.A.Add(ac.Mul(d2 / (d2 - d6)))
+	}
+
+	// Edge region of BC
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		return t.B.Add(t.C.Sub(t.B).Mul((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+
+	// Inside the face
+	denom := 1 / (va + vb + vc)
+	return t.A.Add(ab.Mul(vb * denom)).Add(ac.Mul(vc * denom))
+}
+
 func (t *Tri3) SortCCW(normal Vec3) {
 	// See https://stackoverflow.com/a/14371081/325180
 	c := t.Centroid()
@@ -904,6 +1813,98 @@ func (t *Tri3) Edges() []Line3 {
 	}
 }
 
+// ProjectOntoAxis returns the interval covered by the triangle when projected onto the axis.
+func (t Tri3) ProjectOntoAxis(axis Vec3) Interval {
+	a, b, c := t.A.Dot(axis), t.B.Dot(axis), t.C.Dot(axis)
+	return Interval{Min: min(a, min(b, c)), Max: max(a, max(b, c))}
+}
+
+// Raycast tests whether the ray hits the front of the triangle, which is the side its corners
+// appear counter clockwise from. It uses the method of Möller and Trumbore.
+func (t Tri3) Raycast(ray Ray3) (RaycastResult, bool) {
+	return t.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options. A two sided triangle is hit by rays from
+// either side.
+func (t Tri3) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	var res RaycastResult
+
+	e1 := t.B.Sub(t.A)
+	e2 := t.C.Sub(t.A)
+	p := ray.Direction.Cross(e2)
+	det := e1.Dot(p)
+
+	// The determinant is the negated dot product of the ray direction with the normal
+	if det == 0 || (det < 0 && !opts.TwoSided) {
+		res.Fail = RaycastFailPlaneFacesAwayFromRay
+		return res, false
+	}
+
+	s := ray.Origin.Sub(t.A)
+	u := s.Dot(p) / det
+	if u < 0 || u > 1 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+	q := s.Cross(e1)
+	v := ray.Direction.Dot(q) / det
+	if v < 0 || u+v > 1 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+
+	d := e2.Dot(q) / det
+	if d < 0 {
+		res.Fail = RaycastFailTargetBehindRayOrigin
+		return res, false
+	}
+
+	res.Distance = d
+	res.Point = ray.Point(d)
+	res.Normal = e1.Cross(e2).Normalize()
+	res.Barycentric = Vec3{1 - u - v, u, v}
+	return res, true
+}
+
+// IntersectsAABB reports whether the triangle touches the box. It uses the separating axis
+// test of Akenine-Möller, checking the three box axes, the triangle normal and the nine cross
+// products of the box axes with the triangle edges.
+func (t Tri3) IntersectsAABB(a *AABB) bool {
+	// Work relative to the centre of the box
+	v0 := t.A.Sub(a.Position)
+	v1 := t.B.Sub(a.Position)
+	v2 := t.C.Sub(a.Position)
+	h := a.Size
+
+	// Box face normals
+	for i := 0; i < 3; i++ {
+		if min(v0[i], min(v1[i], v2[i])) > h[i] || max(v0[i], max(v1[i], v2[i])) < -h[i] {
+			return false
+		}
+	}
+
+	// Cross products of the box axes with the edges
+	edges := [3]Vec3{v1.Sub(v0), v2.Sub(v1), v0.Sub(v2)}
+	for _, e := range edges {
+		for i := 0; i < 3; i++ {
+			var axis Vec3
+			axis[(i+1)%3] = -e[(i+2)%3]
+			axis[(i+2)%3] = e[(i+1)%3]
+			p0, p1, p2 := v0.Dot(axis), v1.Dot(axis), v2.Dot(axis)
+			r := h[0]*abs(axis[0]) + h[1]*abs(axis[1]) + h[2]*abs(axis[2])
+			if min(p0, min(p1, p2)) > r || max(p0, max(p1, p2)) < -r {
+				return false
+			}
+		}
+	}
+
+	// Triangle normal
+	n := edges[0].Cross(edges[1])
+	r := h[0]*abs(n[0]) + h[1]*abs(n[1]) + h[2]*abs(n[2])
+	return abs(n.Dot(v0)) <= r
+}
+
 // Plane3FromTri3 returns the plane that lies on the triangle
 func Plane3FromTri3(t Tri3) Plane3 {
 	var result Plane3
@@ -926,6 +1927,29 @@ func (t Tri2) Centroid() Vec2 {
 	return result
 }
 
+// SignedArea returns the area of the triangle. The area is positive when the corners are in
+// counter clockwise order and negative when they are clockwise.
+func (t Tri2) SignedArea() float32 {
+	return Cross2(t.B.Sub(t.A), t.C.Sub(t.A)) / 2
+}
+
+// Area returns the area of the triangle.
+func (t Tri2) Area() float32 {
+	return abs(t.SignedArea())
+}
+
+// IsCCW reports whether the corners of the triangle are in counter clockwise order.
+func (t Tri2) IsCCW() bool {
+	return t.SignedArea() > 0
+}
+
+// SortCCW reorders the corners of the triangle so they are in counter clockwise order.
+func (t *Tri2) SortCCW() {
+	if t.SignedArea() < 0 {
+		t.B, t.C = t.C, t.B
+	}
+}
+
 func (t Tri2) ContainsPoint2(pt Point2) bool {
 	b := t.BarycentricPoint2(pt)
 
@@ -960,8 +1984,8 @@ func (t Tri2) BarycentricPoint2(pt Point2) Vec3 {
 	}
 }
 
-// CircumCircle returns the circle that circumscribes the triangle
-func (t Tri2) CircumCircle() Circle {
+// Circumcircle returns the circle that passes through all three corners of the triangle.
+func (t Tri2) Circumcircle() Circle {
 	var c Circle
 
 	x1, y1 := t.A[0], t.A[1]
@@ -1007,22 +2031,52 @@ func (t Tri2) CircumCircle() Circle {
 
 	dx := x2 - c.Centre[0]
 	dy := y2 - c.Centre[1]
-	c.Radius = dx*dx + dy*dy
+	c.Radius = sqrt(dx*dx + dy*dy)
 
 	return c
 }
 
+// InCircle returns the largest circle that fits inside the triangle, which touches each of
+// its edges.
+func (t Tri2) InCircle() Circle {
+	a := t.C.Sub(t.B).Len()
+	b := t.A.Sub(t.C).Len()
+	c := t.B.Sub(t.A).Len()
+	p := a + b + c
+	if p == 0 {
+		return Circle{Centre: t.A}
+	}
+
+	// The centre is the average of the corners weighted by the length of the opposite edges
+	return Circle{
+		Centre: t.A.Mul(a).Add(t.B.Mul(b)).Add(t.C.Mul(c)).Mul(1 / p),
+		Radius: 2 * t.Area() / p,
+	}
+}
+
+// Circle is a circle in 2 dimensions.
 type Circle struct {
 	Centre Point2
 	Radius float32
 }
 
+// ContainsPoint2 reports whether the point lies in or on the circle.
 func (c Circle) ContainsPoint2(pt Point2) bool {
 	dx := pt[0] - c.Centre[0]
 	dy := pt[1] - c.Centre[1]
 	distance := dx*dx + dy*dy
 
-	return (distance - c.Radius) <= epsilon32
+	return (distance - c.Radius*c.Radius) <= epsilon32
+}
+
+// Area returns the area of the circle.
+func (c Circle) Area() float32 {
+	return pi * c.Radius * c.Radius
+}
+
+// Perimeter returns the circumference of the circle.
+func (c Circle) Perimeter() float32 {
+	return 2 * pi * c.Radius
 }
 
 func DistanceSquared3(a, b Vec3) float32 {
@@ -1033,15 +2087,102 @@ func DistanceSquared3(a, b Vec3) float32 {
 	return dx*dx + dy*dy + dz*dz
 }
 
-var _ Box3 = (*OBB)(nil)
+var (
+	_ Box3     = (*OBB)(nil)
+	_ Box3Into = (*OBB)(nil)
+)
 
-// An OBB is an oriented bounding box
+// An OBB is an oriented bounding box. A box created with NewOBB and changed using SetPosition,
+// SetSize and SetOrientation caches its axes, normals and corners so that repeated queries
+// do not need to recompute rotations. The fields may still be assigned directly, in which case
+// derived values are computed on every call until the next use of a setter.
 type OBB struct {
 	Position    Point3
 	Size        Vec3 // HALF SIZE, i.e. the size in each direction
 	Orientation mgl32.Quat
-	axes        [3]Vec3   // pre-allocated space to avoid allocations during calls to Axes
-	corners     [8]Point3 // pre-allocated space to avoid allocations during calls to Corners
+	cache       obbCache
+}
+
+// obbCache holds values derived from an OBB along with the field values they were derived from.
+type obbCache struct {
+	valid       bool
+	position    Point3
+	size        Vec3
+	orientation Quat
+	axes        [3]Vec3
+	normals     [6]Vec3
+	corners     [8]Point3
+}
+
+// NewOBB returns an OBB with cached axes, normals and corners.
+func NewOBB(pos Point3, size Vec3, orientation Quat) OBB {
+	o := OBB{
+		Position:    pos,
+		Size:        size,
+		Orientation: orientation.Normalize(),
+	}
+	o.updateCache()
+	return o
+}
+
+// SetPosition sets the position of the box, updating its cached corners.
+func (o *OBB) SetPosition(p Point3) {
+	o.Position = p
+	o.updateCache()
+}
+
+// SetSize sets the half size of the box, updating its cached corners.
+func (o *OBB) SetSize(size Vec3) {
+	o.Size = size
+	o.updateCache()
+}
+
+// SetOrientation sets the orientation of the box, updating its cached axes, normals and corners.
+func (o *OBB) SetOrientation(q Quat) {
+	o.Orientation = q.Normalize()
+	o.updateCache()
+}
+
+func (o *OBB) updateCache() {
+	// Invalidate first so the computations below don't use stale cached values
+	o.cache.valid = false
+
+	o.cache.axes = o.axisArray()
+	o.cache.normals = o.normalArray()
+	o.cache.corners = o.cornerArray()
+
+	o.cache.position = o.Position
+	o.cache.size = o.Size
+	o.cache.orientation = o.Orientation
+	o.cache.valid = true
+}
+
+// axesCached reports whether the cached axes and normals match the box's orientation.
+func (o *OBB) axesCached() bool {
+	return o.cache.valid && o.cache.orientation == o.Orientation
+}
+
+// cornersCached reports whether the cached corners match the box's position, size and orientation.
+func (o *OBB) cornersCached() bool {
+	return o.axesCached() && o.cache.position == o.Position && o.cache.size == o.Size
+}
+
+// Transformed returns the box that results from applying the transform to o, which is
+// assumed to be in the transform's local space. When o is rotated and the transform has a
+// non-uniform scale the size is scaled by the largest scale component, producing a box that
+// contains the transformed shape.
+func (o *OBB) Transformed(tx *Transform) OBB {
+	size := o.Size
+	scale := tx.Scale()
+	if o.Orientation == mgl32.QuatIdent() {
+		size[0] *= abs(scale[0])
+		size[1] *= abs(scale[1])
+		size[2] *= abs(scale[2])
+	} else {
+		size = size.Mul(maxScale(scale))
+	}
+
+	return NewOBB(tx.TransformPoint(o.Position), size, tx.Orientation().Mul(o.Orientation))
 }
 
 // ContainsPoint3 reports whether the point lies within the OBB.
@@ -1052,7 +2193,7 @@ func (o *OBB) ContainsPoint3(pt Point3) bool {
 
 	dir := pt.Sub(o.Position)
 
-	axes := o.Axes()
+	axes := o.axisArray()
 	for i := 0; i < 3; i++ {
 		distance := dir.Dot(axes[i])
 		if distance > o.Size[i] {
@@ -1066,156 +2207,201 @@ func (o *OBB) ContainsPoint3(pt Point3) bool {
 	return true
 }
 
-// Corners returns the points at the eight corners of the box.
-func (o *OBB) Corners() []Point3 {
-	if o.Orientation == mgl32.QuatIdent() {
-		return (&AABB{Position: o.Position, Size: o.Size}).Corners()
+// Inflate grows the box by d in every direction. The result contains every point within d of
+// the original box.
+func (o *OBB) Inflate(d float32) OBB {
+	return NewOBB(o.Position, Vec3{o.Size[0] + d, o.Size[1] + d, o.Size[2] + d}, o.Orientation)
+}
+
+// support returns the corner of the box that lies furthest in the direction d.
+func (o *OBB) support(d Vec3) Point3 {
+	axes := o.axisArray()
+	p := o.Position
+	for i := 0; i < 3; i++ {
+		if d.Dot(axes[i]) < 0 {
+			p = p.Sub(axes[i].Mul(o.Size[i]))
+		} else {
+			p = p.Add(axes[i].Mul(o.Size[i]))
+		}
 	}
-	o.corners[0] = o.Orientation.Rotate(Vec3{o.Position[0] + o.Size[0], o.Position[1] + o.Size[1], o.Position[2] + o.Size[2]})
-	o.corners[1] = o.Orientation.Rotate(Vec3{o.Position[0] + o.Size[0], o.Position[1] + o.Size[1], o.Position[2] - o.Size[2]})
-	o.corners[2] = o.Orientation.Rotate(Vec3{o.Position[0] + o.Size[0], o.Position[1] - o.Size[1], o.Position[2] + o.Size[2]})
-	o.corners[3] = o.Orientation.Rotate(Vec3{o.Position[0] + o.Size[0], o.Position[1] - o.Size[1], o.Position[2] - o.Size[2]})
-	o.corners[4] = o.Orientation.Rotate(Vec3{o.Position[0] - o.Size[0], o.Position[1] + o.Size[1], o.Position[2] + o.Size[2]})
-	o.corners[5] = o.Orientation.Rotate(Vec3{o.Position[0] - o.Size[0], o.Position[1] + o.Size[1], o.Position[2] - o.Size[2]})
-	o.corners[6] = o.Orientation.Rotate(Vec3{o.Position[0] - o.Size[0], o.Position[1] - o.Size[1], o.Position[2] + o.Size[2]})
-	o.corners[7] = o.Orientation.Rotate(Vec3{o.Position[0] - o.Size[0], o.Position[1] - o.Size[1], o.Position[2] - o.Size[2]})
-	return o.corners[:]
+	return p
 }
 
-func (o *OBB) Axes() []Vec3 {
-	if o.Orientation == mgl32.QuatIdent() {
-		return (&AABB{Position: o.Position, Size: o.Size}).Axes()
+// ClosestPoint returns the point in the OBB that is closest to p
+func (o *OBB) ClosestPoint(p Point3) Point3 {
+	dir := p.Sub(o.Position)
+	result := o.Position
+
+	axes := o.axisArray()
+	for i := 0; i < 3; i++ {
+		distance := Clamp(dir.Dot(axes[i]), -o.Size[i], o.Size[i])
+		result = result.Add(axes[i].Mul(distance))
 	}
-	o.axes[0] = o.Orientation.Rotate(X3).Normalize()
-	o.axes[1] = o.Orientation.Rotate(Y3).Normalize()
-	o.axes[2] = o.Orientation.Rotate(Z3).Normalize()
 
-	return o.axes[:]
+	return result
 }
 
-func (o *OBB) Normals() []Vec3 {
-	if o.Orientation == mgl32.QuatIdent() {
-		return (&AABB{Position: o.Position, Size: o.Size}).Normals()
-	}
-	return []Vec3{
-		o.Orientation.Rotate(Vec3{-1, 0, 0}).Normalize(),
-		o.Orientation.Rotate(Vec3{1, 0, 0}).Normalize(),
-		o.Orientation.Rotate(Vec3{0, -1, 0}).Normalize(),
-		o.Orientation.Rotate(Vec3{0, 1, 0}).Normalize(),
-		o.Orientation.Rotate(Vec3{0, 0, -1}).Normalize(),
-		o.Orientation.Rotate(Vec3{0, 0, 1}).Normalize(),
-	}
+// Bounds returns the smallest AABB that contains the box.
+func (o *OBB) Bounds() AABB {
+	corners := o.cornerArray()
+	return AABBFromPoints(corners[:])
 }
 
-func (o *OBB) ProjectOntoAxis(axis Vec3) Interval {
-	if o.Orientation == mgl32.QuatIdent() {
-		return (&AABB{Position: o.Position, Size: o.Size}).ProjectOntoAxis(axis)
-	}
-	vertex := o.Corners()
+// Center returns the point at the centre of the box.
+func (o *OBB) Center() Point3 {
+	return o.Position
+}
 
-	var in Interval
-	in.Min = axis.Dot(vertex[0])
-	in.Max = in.Min
+// Volume returns the volume of the box.
+func (o *OBB) Volume() float32 {
+	return 8 * o.Size[0] * o.Size[1] * o.Size[2]
+}
 
-	for i := 1; i < 8; i++ {
-		projection := axis.Dot(vertex[i])
-		if projection < in.Min {
-			in.Min = projection
-		}
-		if projection > in.Max {
-			in.Max = projection
-		}
-	}
+// SurfaceArea returns the total area of the box's faces.
+func (o *OBB) SurfaceArea() float32 {
+	return 8 * (o.Size[0]*o.Size[1] + o.Size[1]*o.Size[2] + o.Size[2]*o.Size[0])
+}
 
-	return in
+// Corners returns the points at the eight corners of the box. The returned slice refers to the
+// box's cached corners when they are available and must not be modified.
+func (o *OBB) Corners() []Point3 {
+	if o.cornersCached() {
+		return o.cache.corners[:]
+	}
+	corners := o.cornerArray()
+	return corners[:]
 }
 
-func (o *OBB) Raycast(ray Ray3) (RaycastResult, bool) {
-	var res RaycastResult
+// CornersInto writes the points at the eight corners of the box into buf.
+func (o *OBB) CornersInto(buf *[8]Point3) {
+	*buf = o.cornerArray()
+}
 
-	axes := o.Axes()
-	f := [3]float32{
-		axes[0].Dot(ray.Direction),
-		axes[1].Dot(ray.Direction),
-		axes[2].Dot(ray.Direction),
+func (o *OBB) cornerArray() [8]Point3 {
+	if o.cornersCached() {
+		return o.cache.corners
 	}
-
-	p := o.Position.Sub(ray.Origin)
-	e := [3]float32{
-		axes[0].Dot(p),
-		axes[1].Dot(p),
-		axes[2].Dot(p),
+	if o.Orientation == mgl32.QuatIdent() {
+		return (&AABB{Position: o.Position, Size: o.Size}).cornerArray()
 	}
 
-	if cmp(f[0], 0) {
-		if -e[0]-o.Size[0] > 0 || -e[0]+o.Size[0] < 0 {
-			res.Fail = RaycastFailOutsideBounds
-			return res, false
-		}
-		f[0] = nonzero(f[0]) // Avoid div by 0!
-	} else if cmp(f[1], 0) {
-		if -e[1]-o.Size[1] > 0 || -e[1]+o.Size[1] < 0 {
-			res.Fail = RaycastFailOutsideBounds
-			return res, false
-		}
-		f[1] = nonzero(f[1]) // Avoid div by 0!
-	} else if cmp(f[2], 0) {
-		if -e[2]-o.Size[2] > 0 || -e[2]+o.Size[2] < 0 {
-			res.Fail = RaycastFailOutsideBounds
-			return res, false
-		}
-		f[2] = nonzero(f[2]) // Avoid div by 0!
+	// Offsets from the centre to each face along the box's axes
+	axes := o.axisArray()
+	x := axes[0].Mul(o.Size[0])
+	y := axes[1].Mul(o.Size[1])
+	z := axes[2].Mul(o.Size[2])
+
+	return [8]Point3{
+		o.Position.Add(x).Add(y).Add(z),
+		o.Position.Add(x).Add(y).Sub(z),
+		o.Position.Add(x).Sub(y).Add(z),
+		o.Position.Add(x).Sub(y).Sub(z),
+		o.Position.Sub(x).Add(y).Add(z),
+		o.Position.Sub(x).Add(y).Sub(z),
+		o.Position.Sub(x).Sub(y).Add(z),
+		o.Position.Sub(x).Sub(y).Sub(z),
 	}
+}
 
-	t := [6]float32{
-		(e[0] + o.Size[0]) / f[0],
-		(e[0] - o.Size[0]) / f[0],
-		(e[1] + o.Size[1]) / f[1],
-		(e[1] - o.Size[1]) / f[1],
-		(e[2] + o.Size[2]) / f[2],
-		(e[2] - o.Size[2]) / f[2],
+// Axes returns the three axes of the box. The returned slice may be shared and must not be modified.
+func (o *OBB) Axes() []Vec3 {
+	if o.axesCached() {
+		return o.cache.axes[:]
 	}
+	if o.Orientation == mgl32.QuatIdent() {
+		return (&AABB{Position: o.Position, Size: o.Size}).Axes()
+	}
+	axes := o.axisArray()
+	return axes[:]
+}
 
-	tmin := max(max(min(t[0], t[1]), min(t[2], t[3])), min(t[4], t[5]))
-	tmax := min(min(max(t[0], t[1]), max(t[2], t[3])), max(t[4], t[5]))
+// AxesInto writes the three axes of the box into buf.
+func (o *OBB) AxesInto(buf *[3]Vec3) {
+	*buf = o.axisArray()
+}
 
-	// if tmax < 0, ray is intersecting AABB
-	// but entire AABB is behing it's origin
-	if tmax < 0 {
-		res.Fail = RaycastFailTargetBehindRayOrigin
-		return res, false
+func (o *OBB) axisArray() [3]Vec3 {
+	if o.axesCached() {
+		return o.cache.axes
+	}
+	if o.Orientation == mgl32.QuatIdent() {
+		return aabbAxes
+	}
+	return [3]Vec3{
+		o.Orientation.Rotate(X3).Normalize(),
+		o.Orientation.Rotate(Y3).Normalize(),
+		o.Orientation.Rotate(Z3).Normalize(),
 	}
+}
 
-	// if tmin > tmax, ray doesn't intersect AABB
-	if tmin > tmax {
-		res.Fail = RaycastFailOutsideBounds
-		return res, false
+// Normals returns the normals of the six faces of the box. The returned slice may be shared
+// and must not be modified.
+func (o *OBB) Normals() []Vec3 {
+	if o.axesCached() {
+		return o.cache.normals[:]
+	}
+	if o.Orientation == mgl32.QuatIdent() {
+		return (&AABB{Position: o.Position, Size: o.Size}).Normals()
 	}
+	normals := o.normalArray()
+	return normals[:]
+}
 
-	// If tmin is < 0, tmax is closer
-	res.Distance = tmin
+// NormalsInto writes the normals of the six faces of the box into buf.
+func (o *OBB) NormalsInto(buf *[6]Vec3) {
+	*buf = o.normalArray()
+}
 
-	// If tmin is < 0, tmax is closer
-	if tmin < 0 {
-		res.Distance = tmax
+func (o *OBB) normalArray() [6]Vec3 {
+	if o.axesCached() {
+		return o.cache.normals
+	}
+	if o.Orientation == mgl32.QuatIdent() {
+		return aabbNormals
 	}
 
-	res.Point = ray.Point(res.Distance)
+	axes := o.axisArray()
+	return [6]Vec3{
+		axes[0].Mul(-1),
+		axes[0],
+		axes[1].Mul(-1),
+		axes[1],
+		axes[2].Mul(-1),
+		axes[2],
+	}
+}
 
-	// Find closest side to the ray
-	normals := [6]Vec3{
-		axes[0],         // +x
-		axes[0].Mul(-1), // -x
-		axes[1],         // +y
-		axes[1].Mul(-1), // -y
-		axes[2],         // +z
-		axes[2].Mul(-1), // -z
+func (o *OBB) ProjectOntoAxis(axis Vec3) Interval {
+	if o.Orientation == mgl32.QuatIdent() {
+		return (&AABB{Position: o.Position, Size: o.Size}).ProjectOntoAxis(axis)
 	}
+	corners := Vec3x8FromVecs(o.cornerArray())
+	return corners.ProjectOntoAxis(axis)
+}
 
-	for i := 0; i < 6; i++ {
-		if cmp(res.Distance, t[i]) {
-			res.Normal = normals[i].Normalize()
-		}
+// Raycast tests whether the ray intersects the OBB. A ray that starts inside the box hits the
+// face it leaves through.
+func (o *OBB) Raycast(ray Ray3) (RaycastResult, bool) {
+	return o.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options.
+func (o *OBB) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	// Test the ray in the frame of the box, where the box is axis-aligned. The axes are
+	// orthonormal so distances along the ray are unchanged.
+	axes := o.axisArray()
+	p := ray.Origin.Sub(o.Position)
+	local := Ray3{
+		Origin:    Point3{axes[0].Dot(p), axes[1].Dot(p), axes[2].Dot(p)},
+		Direction: Vec3{axes[0].Dot(ray.Direction), axes[1].Dot(ray.Direction), axes[2].Dot(ray.Direction)},
+	}
+	box := AABB{Size: o.Size}
+	res, ok := box.RaycastWith(local, opts)
+	if !ok {
+		return res, false
 	}
+
+	res.Point = ray.Point(res.Distance)
+	res.Normal = axes[0].Mul(res.Normal[0]).Add(axes[1].Mul(res.Normal[1])).Add(axes[2].Mul(res.Normal[2]))
 	return res, true
 }
@@ -1,6 +1,7 @@
 package geom
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -217,6 +218,183 @@ func TestOBBContainsPoint3(t *testing.T) {
 	}
 }
 
+func TestOBBCorners(t *testing.T) {
+	o := OBB{
+		Position:    Point3{10, 0, 0},
+		Size:        Vec3{1, 2, 3},
+		Orientation: mgl32.QuatRotate(pi/2, Z3),
+	}
+
+	// Rotating by 90 degrees about z swaps the x and y extents
+	a := AABB{Position: Point3{10, 0, 0}, Size: Vec3{2, 1, 3}}
+	want := a.Corners()
+
+	got := o.Corners()
+	for _, c := range got {
+		found := false
+		for _, w := range want {
+			if nearVec3(c, w, 1e-5) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("unexpected corner %v", c)
+		}
+	}
+}
+
+func TestOBBCache(t *testing.T) {
+	o := NewOBB(Point3{1, 2, 3}, Vec3{1, 2, 3}, mgl32.QuatRotate(pi/3, Vec3{1, 1, 0}.Normalize()))
+
+	check := func(t *testing.T, o OBB) {
+		t.Helper()
+		uncached := OBB{Position: o.Position, Size: o.Size, Orientation: o.Orientation}
+		for i, want := range uncached.Axes() {
+			if got := o.Axes()[i]; !nearVec3(got, want, 1e-6) {
+				t.Errorf("axis %d: got %v, wanted %v", i, got, want)
+			}
+		}
+		for i, want := range uncached.Normals() {
+			if got := o.Normals()[i]; !nearVec3(got, want, 1e-6) {
+				t.Errorf("normal %d: got %v, wanted %v", i, got, want)
+			}
+		}
+		for i, want := range uncached.Corners() {
+			if got := o.Corners()[i]; !nearVec3(got, want, 1e-5) {
+				t.Errorf("corner %d: got %v, wanted %v", i, got, want)
+			}
+		}
+	}
+
+	check(t, o)
+
+	o.SetPosition(Point3{-4, 0, 1})
+	check(t, o)
+
+	o.SetSize(Vec3{5, 1, 1})
+	check(t, o)
+
+	o.SetOrientation(mgl32.QuatRotate(pi/5, Z3))
+	check(t, o)
+
+	// Assigning a field directly bypasses the stale cache
+	o.Orientation = mgl32.QuatRotate(pi/7, X3)
+	check(t, o)
+}
+
+func TestIntersectsBox3(t *testing.T) {
+	aa1 := AABB{Position: Point3{0, 0, 0}, Size: Vec3{2, 2, 2}}
+	aa2 := AABB{Position: Point3{1, 1, 1}, Size: Vec3{2, 2, 2}}
+	aa3 := AABB{Position: Point3{0, 0, 5}, Size: Vec3{2, 2, 2}}
+	o1 := NewOBB(Point3{1, 1, 1}, Vec3{2, 2, 2}, mgl32.QuatRotate(pi/4, Y3))
+	o2 := NewOBB(Point3{0, 0, 6}, Vec3{2, 2, 2}, mgl32.QuatRotate(pi/4, Y3))
+	// separated only along the cross product of an edge from each box
+	o3 := NewOBB(Point3{3.5, 3.5, 0}, Vec3{1, 1, 1}, mgl32.QuatRotate(pi/4, Z3))
+
+	testCases := []struct {
+		name string
+		a    Box3
+		b    Box3
+		want bool
+	}{
+		{name: "aabb-aabb-intersect", a: &aa1, b: &aa2, want: true},
+		{name: "aabb-aabb-nonintersect", a: &aa1, b: &aa3, want: false},
+		{name: "obb-aligned-aabb-intersect", a: &aaOBB, b: &aa2, want: true},
+		{name: "obb-oriented-aabb-intersect", a: &tiltyOBB, b: &aa2, want: true},
+		{name: "obb-oriented-aabb-nonintersect", a: &tiltyOBB, b: &aa3, want: false},
+		{name: "obb-aligned-obb-oriented-intersect", a: &aaOBB, b: &o1, want: true},
+		{name: "obb-oriented-obb-oriented-nonintersect", a: &tiltyOBB, b: &o2, want: false},
+		{name: "aabb-obb-corner-gap", a: &aa1, b: &o3, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IntersectsBox3(tc.a, tc.b); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			if got := IntersectsBox3(tc.b, tc.a); got != tc.want {
+				t.Errorf("got %v with arguments swapped, wanted %v", got, tc.want)
+			}
+			// Boxes that do not implement Box3Into are tested through their slices
+			if got := IntersectsBox3(sliceBox3{tc.a}, sliceBox3{tc.b}); got != tc.want {
+				t.Errorf("got %v for boxes without Box3Into, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// sliceBox3 hides the Box3Into methods of the box it wraps.
+type sliceBox3 struct{ b Box3 }
+
+func (s sliceBox3) ProjectOntoAxis(axis Vec3) Interval     { return s.b.ProjectOntoAxis(axis) }
+func (s sliceBox3) Axes() []Vec3                           { return s.b.Axes() }
+func (s sliceBox3) Corners() []Point3                      { return s.b.Corners() }
+func (s sliceBox3) Normals() []Vec3                        { return s.b.Normals() }
+func (s sliceBox3) ContainsPoint3(pt Point3) bool          { return s.b.ContainsPoint3(pt) }
+func (s sliceBox3) Raycast(ray Ray3) (RaycastResult, bool) { return s.b.Raycast(ray) }
+
+func TestReflectRefract(t *testing.T) {
+	n := Vec3{0, 1, 0}
+	v := Vec3{1, -1, 0}.Normalize()
+
+	if got, want := Reflect(v, n), (Vec3{1, 1, 0}).Normalize(); !nearVec3(got, want, 1e-6) {
+		t.Errorf("Reflect: got %v, wanted %v", got, want)
+	}
+
+	// eta of 1 leaves the direction unchanged
+	if got := Refract(v, n, 1); !nearVec3(got, v, 1e-6) {
+		t.Errorf("Refract: got %v, wanted %v", got, v)
+	}
+
+	// Snell's law: sin(out) = eta * sin(in)
+	got := Refract(v, n, 0.5)
+	if sinOut, want := got[0], 0.5*v[0]; abs(sinOut-want) > 1e-6 {
+		t.Errorf("Refract: got sine %v, wanted %v", sinOut, want)
+	}
+
+	// total internal reflection
+	if got := Refract(v, n, 2); got != (Vec3{}) {
+		t.Errorf("Refract: got %v, wanted zero vector", got)
+	}
+
+	if got, want := ProjectOnPlane(Vec3{3, 4, 5}, n), (Vec3{3, 0, 5}); got != want {
+		t.Errorf("ProjectOnPlane: got %v, wanted %v", got, want)
+	}
+	if got, want := ProjectOnAxis(Vec3{3, 4, 5}, Vec3{0, 2, 0}), (Vec3{0, 4, 0}); got != want {
+		t.Errorf("ProjectOnAxis: got %v, wanted %v", got, want)
+	}
+	if got, want := Reflect2(Vec2{1, -1}, Vec2{0, 1}), (Vec2{1, 1}); got != want {
+		t.Errorf("Reflect2: got %v, wanted %v", got, want)
+	}
+	if got, want := ProjectOnLine2(Vec2{3, 4}, Vec2{1, 0}), (Vec2{0, 4}); got != want {
+		t.Errorf("ProjectOnLine2: got %v, wanted %v", got, want)
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	// Run with -race to detect shared state modified by read-only methods
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 2, 3})
+	a := AABB{Position: Point3{1, 1, 1}, Size: Vec3{2, 2, 2}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = tx.Matrix()
+				_ = a.Corners()
+				_ = tiltyOBB.Corners()
+				_ = tiltyOBB.Axes()
+				_ = IntersectsBox3(&a, &tiltyOBB)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // Package level variable for assignment, which avoids benchmarks being optimized away
 var bres interface{}
 
@@ -282,6 +460,7 @@ func BenchmarkOBBAxes(b *testing.B) {
 	}{
 		{name: "axis-aligned", o: aaOBB},
 		{name: "tilty", o: tiltyOBB},
+		{name: "tilty-cached", o: NewOBB(tiltyOBB.Position, tiltyOBB.Size, tiltyOBB.Orientation)},
 	}
 
 	for _, tc := range testCases {
@@ -321,6 +500,7 @@ func BenchmarkOBBNormals(b *testing.B) {
 	}{
 		{name: "axis-aligned", o: aaOBB},
 		{name: "tilty", o: tiltyOBB},
+		{name: "tilty-cached", o: NewOBB(tiltyOBB.Position, tiltyOBB.Size, tiltyOBB.Orientation)},
 	}
 
 	for _, tc := range testCases {
@@ -407,3 +587,571 @@ func BenchmarkIntersectsBox3(b *testing.B) {
 		})
 	}
 }
+
+// nearVec3 reports whether each component of a is within tol of the same component of b
+func nearVec3(a, b Vec3, tol float32) bool {
+	return abs(a[0]-b[0]) <= tol && abs(a[1]-b[1]) <= tol && abs(a[2]-b[2]) <= tol
+}
+
+func TestTri3Metrics(t *testing.T) {
+	testCases := []struct {
+		name       string
+		tri        Tri3
+		area       float32
+		perimeter  float32
+		normal     Vec3
+		degenerate bool
+	}{
+		{
+			name:      "right",
+			tri:       Tri3{A: Point3{0, 0, 0}, B: Point3{3, 0, 0}, C: Point3{0, 4, 0}},
+			area:      6,
+			perimeter: 12,
+			normal:    Vec3{0, 0, 1},
+		},
+		{
+			name:      "reversed",
+			tri:       Tri3{A: Point3{0, 0, 0}, B: Point3{0, 4, 0}, C: Point3{3, 0, 0}},
+			area:      6,
+			perimeter: 12,
+			normal:    Vec3{0, 0, -1},
+		},
+		{
+			name:       "collinear",
+			tri:        Tri3{A: Point3{0, 0, 0}, B: Point3{1, 1, 1}, C: Point3{3, 3, 3}},
+			perimeter:  2 * sqrt(27),
+			degenerate: true,
+		},
+		{
+			name:       "point",
+			tri:        Tri3{A: Point3{1, 2, 3}, B: Point3{1, 2, 3}, C: Point3{1, 2, 3}},
+			degenerate: true,
+		},
+		{
+			name:      "tiny",
+			tri:       Tri3{A: Point3{0, 0, 0}, B: Point3{0, 0.001, 0}, C: Point3{0, 0, 0.001}},
+			area:      0.0000005,
+			perimeter: 0.002 + sqrt(0.000002),
+			normal:    Vec3{1, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tri.Area(); abs(got-tc.area) > 1e-6*max(1, tc.area) {
+				t.Errorf("Area: got %v, wanted %v", got, tc.area)
+			}
+			if got := tc.tri.Perimeter(); abs(got-tc.perimeter) > 1e-5 {
+				t.Errorf("Perimeter: got %v, wanted %v", got, tc.perimeter)
+			}
+			if got := tc.tri.IsDegenerate(); got != tc.degenerate {
+				t.Errorf("IsDegenerate: got %v, wanted %v", got, tc.degenerate)
+			}
+			if !tc.degenerate {
+				if got := tc.tri.Normal(); !nearVec3(got, tc.normal, 1e-5) {
+					t.Errorf("Normal: got %v, wanted %v", got, tc.normal)
+				}
+			}
+		})
+	}
+}
+
+func TestTri2Winding(t *testing.T) {
+	ccw := Tri2{A: Point2{0, 0}, B: Point2{4, 0}, C: Point2{0, 3}}
+	cw := Tri2{A: Point2{0, 0}, B: Point2{0, 3}, C: Point2{4, 0}}
+
+	if got := ccw.SignedArea(); got != 6 {
+		t.Errorf("SignedArea(ccw): got %v, wanted %v", got, 6)
+	}
+	if got := cw.SignedArea(); got != -6 {
+		t.Errorf("SignedArea(cw): got %v, wanted %v", got, -6)
+	}
+	if got := cw.Area(); got != 6 {
+		t.Errorf("Area(cw): got %v, wanted %v", got, 6)
+	}
+	if !ccw.IsCCW() || cw.IsCCW() {
+		t.Errorf("IsCCW: got %v and %v, wanted true and false", ccw.IsCCW(), cw.IsCCW())
+	}
+
+	cw.SortCCW()
+	if cw != ccw {
+		t.Errorf("SortCCW: got %v, wanted %v", cw, ccw)
+	}
+}
+
+func TestTri2Circles(t *testing.T) {
+	tri := Tri2{A: Point2{0, 0}, B: Point2{4, 0}, C: Point2{0, 3}}
+
+	cc := tri.Circumcircle()
+	if want := (Point2{2, 1.5}); cc.Centre.Sub(want).Len() > 1e-5 || abs(cc.Radius-2.5) > 1e-5 {
+		t.Errorf("Circumcircle: got %v, wanted centre %v radius 2.5", cc, want)
+	}
+	for _, p := range []Point2{tri.A, tri.B, tri.C, {2, 1}} {
+		if !cc.ContainsPoint2(p) {
+			t.Errorf("Circumcircle should contain %v", p)
+		}
+	}
+	if cc.ContainsPoint2(Point2{4.6, 1.5}) {
+		t.Errorf("Circumcircle should not contain %v", Point2{4.6, 1.5})
+	}
+
+	ic := tri.InCircle()
+	if want := (Point2{1, 1}); ic.Centre.Sub(want).Len() > 1e-5 || abs(ic.Radius-1) > 1e-5 {
+		t.Errorf("InCircle: got %v, wanted centre %v radius 1", ic, want)
+	}
+}
+
+func TestTri3Circles(t *testing.T) {
+	// A 3-4-5 triangle lying in the plane x=1
+	tri := Tri3{A: Point3{1, 0, 0}, B: Point3{1, 4, 0}, C: Point3{1, 0, 3}}
+
+	s := tri.CircumSphere()
+	if want := (Point3{1, 2, 1.5}); !nearVec3(s.Position, want, 1e-5) || abs(s.Radius-2.5) > 1e-5 {
+		t.Errorf("CircumSphere: got %v, wanted centre %v radius 2.5", s, want)
+	}
+
+	centre, radius := tri.InCircle()
+	if want := (Point3{1, 1, 1}); !nearVec3(centre, want, 1e-5) || abs(radius-1) > 1e-5 {
+		t.Errorf("InCircle: got %v %v, wanted centre %v radius 1", centre, radius, want)
+	}
+
+	if got := (Tri3{A: Point3{0, 0, 0}, B: Point3{1, 1, 1}, C: Point3{2, 2, 2}}).CircumSphere(); got != (Sphere{}) {
+		t.Errorf("CircumSphere of degenerate triangle: got %v, wanted zero sphere", got)
+	}
+}
+
+func TestPlane3RaycastOffset(t *testing.T) {
+	// A plane facing positive y that passes through y=5
+	p := Plane3{Normal: Vec3{0, 1, 0}, Distance: 5}
+
+	res, ok := p.Raycast(yInvRay3)
+	if !ok {
+		t.Fatalf("got no intersection, fail=%v", res.Fail)
+	}
+	if want := (Point3{0, 5, 0}); !nearVec3(res.Point, want, 1e-5) {
+		t.Errorf("got point %v, wanted %v", res.Point, want)
+	}
+	if res.Distance != 95 {
+		t.Errorf("got distance %v, wanted %v", res.Distance, 95)
+	}
+	if !p.ContainsPoint3(res.Point) {
+		t.Errorf("plane should contain hit point %v", res.Point)
+	}
+}
+
+func TestRay3ClosestPointToRay(t *testing.T) {
+	testCases := []struct {
+		name   string
+		r1, r2 Ray3
+		p1, p2 Point3
+		t1, t2 float32
+	}{
+		{
+			name: "skew",
+			r1:   Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}},
+			r2:   Ray3{Origin: Point3{5, -3, 2}, Direction: Vec3{0, 1, 0}},
+			p1:   Point3{5, 0, 0},
+			p2:   Point3{5, 0, 2},
+			t1:   5,
+			t2:   3,
+		},
+		{
+			name: "behind origin",
+			r1:   Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}},
+			r2:   Ray3{Origin: Point3{-5, -3, 2}, Direction: Vec3{0, 1, 0}},
+			p1:   Point3{0, 0, 0},
+			p2:   Point3{-5, 0, 2},
+			t1:   0,
+			t2:   3,
+		},
+		{
+			name: "parallel",
+			r1:   Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}},
+			r2:   Ray3{Origin: Point3{3, 1, 0}, Direction: Vec3{1, 0, 0}},
+			p1:   Point3{3, 0, 0},
+			p2:   Point3{3, 1, 0},
+			t1:   3,
+			t2:   0,
+		},
+		{
+			name: "parallel overlapping",
+			r1:   Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{1, 0, 0}},
+			r2:   Ray3{Origin: Point3{-3, 1, 0}, Direction: Vec3{1, 0, 0}},
+			p1:   Point3{0, 0, 0},
+			p2:   Point3{0, 1, 0},
+			t1:   0,
+			t2:   3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p1, p2, t1, t2 := tc.r1.ClosestPointToRay(tc.r2)
+			if !nearVec3(p1, tc.p1, 1e-5) || !nearVec3(p2, tc.p2, 1e-5) {
+				t.Errorf("got points %v, %v, wanted %v, %v", p1, p2, tc.p1, tc.p2)
+			}
+			if abs(t1-tc.t1) > 1e-5 || abs(t2-tc.t2) > 1e-5 {
+				t.Errorf("got params %v, %v, wanted %v, %v", t1, t2, tc.t1, tc.t2)
+			}
+		})
+	}
+}
+
+func TestRay3ClosestPointToSegment(t *testing.T) {
+	r := Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{0, 0, 1}}
+
+	testCases := []struct {
+		name   string
+		l      Line3
+		p1, p2 Point3
+		t1, t2 float32
+	}{
+		{
+			name: "crossing",
+			l:    Line3{Start: Point3{-2, 1, 4}, End: Point3{2, 1, 4}},
+			p1:   Point3{0, 0, 4},
+			p2:   Point3{0, 1, 4},
+			t1:   4,
+			t2:   0.5,
+		},
+		{
+			name: "beyond end",
+			l:    Line3{Start: Point3{1, 0, 3}, End: Point3{5, 0, 3}},
+			p1:   Point3{0, 0, 3},
+			p2:   Point3{1, 0, 3},
+			t1:   3,
+			t2:   0,
+		},
+		{
+			name: "degenerate segment",
+			l:    Line3{Start: Point3{1, 0, 3}, End: Point3{1, 0, 3}},
+			p1:   Point3{0, 0, 3},
+			p2:   Point3{1, 0, 3},
+			t1:   3,
+			t2:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p1, p2, t1, t2 := r.ClosestPointToSegment(tc.l)
+			if !nearVec3(p1, tc.p1, 1e-5) || !nearVec3(p2, tc.p2, 1e-5) {
+				t.Errorf("got points %v, %v, wanted %v, %v", p1, p2, tc.p1, tc.p2)
+			}
+			if abs(t1-tc.t1) > 1e-5 || abs(t2-tc.t2) > 1e-5 {
+				t.Errorf("got params %v, %v, wanted %v, %v", t1, t2, tc.t1, tc.t2)
+			}
+		})
+	}
+}
+
+func TestSlideBounce(t *testing.T) {
+	n := Vec3{0, 1, 0}
+
+	testCases := []struct {
+		name        string
+		v           Vec3
+		restitution float32
+		slide       Vec3
+		bounce      Vec3
+	}{
+		{name: "into surface", v: Vec3{3, -4, 1}, restitution: 0.5, slide: Vec3{3, 0, 1}, bounce: Vec3{3, 2, 1}},
+		{name: "perfect bounce", v: Vec3{3, -4, 1}, restitution: 1, slide: Vec3{3, 0, 1}, bounce: Vec3{3, 4, 1}},
+		{name: "away from surface", v: Vec3{3, 4, 1}, restitution: 1, slide: Vec3{3, 4, 1}, bounce: Vec3{3, 4, 1}},
+		{name: "along surface", v: Vec3{3, 0, 1}, restitution: 1, slide: Vec3{3, 0, 1}, bounce: Vec3{3, 0, 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Slide(tc.v, n); !nearVec3(got, tc.slide, 1e-6) {
+				t.Errorf("Slide: got %v, wanted %v", got, tc.slide)
+			}
+			if got := Bounce(tc.v, n, tc.restitution); !nearVec3(got, tc.bounce, 1e-6) {
+				t.Errorf("Bounce: got %v, wanted %v", got, tc.bounce)
+			}
+
+			v2 := Vec2{tc.v[0], tc.v[1]}
+			n2 := Vec2{n[0], n[1]}
+			if got, want := Slide2(v2, n2), (Vec2{tc.slide[0], tc.slide[1]}); got != want {
+				t.Errorf("Slide2: got %v, wanted %v", got, want)
+			}
+			if got, want := Bounce2(v2, n2, tc.restitution), (Vec2{tc.bounce[0], tc.bounce[1]}); got != want {
+				t.Errorf("Bounce2: got %v, wanted %v", got, want)
+			}
+		})
+	}
+}
+
+func TestInflate(t *testing.T) {
+	a := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}}
+	if got, want := a.Inflate(0.5), (AABB{Position: Point3{1, 2, 3}, Size: Vec3{1.5, 2.5, 3.5}}); got != want {
+		t.Errorf("AABB: got %v, wanted %v", got, want)
+	}
+
+	s := Sphere{Position: Point3{1, 2, 3}, Radius: 2}
+	if got, want := s.Inflate(0.5), (Sphere{Position: Point3{1, 2, 3}, Radius: 2.5}); got != want {
+		t.Errorf("Sphere: got %v, wanted %v", got, want)
+	}
+
+	r := Rect{Position: Point2{1, 2}, Size: Vec2{3, 4}}
+	if got, want := r.Inflate(1), (Rect{Position: Point2{1, 2}, Size: Vec2{4, 5}}); got != want {
+		t.Errorf("Rect: got %v, wanted %v", got, want)
+	}
+	if got := r.Inflate(1).Shrink(1); got != r {
+		t.Errorf("Rect: Shrink should reverse Inflate, got %v", got)
+	}
+
+	o := tiltyOBB
+	inflated := o.Inflate(1)
+	if want := (Vec3{3, 3, 3}); inflated.Size != want || inflated.Orientation != o.Orientation {
+		t.Errorf("OBB: got %v, wanted size %v with same orientation", inflated, want)
+	}
+	// A point just beyond a face of the original box is inside the inflated box
+	p := o.Position.Add(mgl32.QuatRotate(pi/4, Y3).Rotate(Vec3{2.5, 0, 0}))
+	if o.ContainsPoint3(p) || !inflated.ContainsPoint3(p) {
+		t.Errorf("OBB: point %v should only be in the inflated box", p)
+	}
+}
+
+func TestAABB2(t *testing.T) {
+	r := AABB2{Position: Point2{1, 2}, Size: Vec2{2, 1}}
+
+	if got, want := r.ClosestPoint(Point2{5, 0}), (Point2{3, 1}); got != want {
+		t.Errorf("ClosestPoint outside: got %v, wanted %v", got, want)
+	}
+	if got, want := r.ClosestPoint(Point2{0, 2.5}), (Point2{0, 2.5}); got != want {
+		t.Errorf("ClosestPoint inside: got %v, wanted %v", got, want)
+	}
+
+	if got, want := r.ProjectOntoAxis(X2), (Interval{Min: -1, Max: 3}); got != want {
+		t.Errorf("ProjectOntoAxis x: got %v, wanted %v", got, want)
+	}
+	diag := Vec2{1, 1}.Normalize()
+	got := r.ProjectOntoAxis(diag)
+	var want Interval
+	want.Min, want.Max = maxFloat32, -maxFloat32
+	for _, c := range []Point2{r.TopLeft(), r.TopRight(), r.BottomLeft(), r.BottomRight()} {
+		d := c.Dot(diag)
+		want.Min, want.Max = min(want.Min, d), max(want.Max, d)
+	}
+	if abs(got.Min-want.Min) > 1e-5 || abs(got.Max-want.Max) > 1e-5 {
+		t.Errorf("ProjectOntoAxis diagonal: got %v, wanted %v", got, want)
+	}
+}
+
+func TestAABB2MTV(t *testing.T) {
+	a := AABB2{Size: Vec2{1, 1}}
+	testCases := []struct {
+		name string
+		b    AABB2
+		ok   bool
+		mtv  Vec2
+	}{
+		{name: "overlap right", b: AABB2{Position: Point2{1.5, 0.2}, Size: Vec2{1, 1}}, ok: true, mtv: Vec2{-0.5, 0}},
+		{name: "overlap left", b: AABB2{Position: Point2{-1.5, 0.2}, Size: Vec2{1, 1}}, ok: true, mtv: Vec2{0.5, 0}},
+		{name: "overlap above", b: AABB2{Position: Point2{0.2, 1.75}, Size: Vec2{1, 1}}, ok: true, mtv: Vec2{0, -0.25}},
+		{name: "overlap below", b: AABB2{Position: Point2{0.2, -1.75}, Size: Vec2{1, 1}}, ok: true, mtv: Vec2{0, 0.25}},
+		{name: "apart", b: AABB2{Position: Point2{3, 0}, Size: Vec2{1, 1}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, mtv := a.MTVAABB2(&tc.b)
+			if ok != tc.ok || mtv != tc.mtv {
+				t.Fatalf("got %v, %v, wanted %v, %v", ok, mtv, tc.ok, tc.mtv)
+			}
+
+			// Same sense as the 3D version on boxes that overlap deeply in z
+			a3 := AABB{Position: Point3{a.Position[0], a.Position[1], 0}, Size: Vec3{a.Size[0], a.Size[1], 10}}
+			b3 := AABB{Position: Point3{tc.b.Position[0], tc.b.Position[1], 0}, Size: Vec3{tc.b.Size[0], tc.b.Size[1], 10}}
+			if ok3, mtv3 := a3.MTVAABB(&b3); ok3 && mtv != (Vec2{mtv3[0], mtv3[1]}) {
+				t.Errorf("got %v, but MTVAABB gives %v", mtv, mtv3)
+			}
+		})
+	}
+}
+
+func TestRectRaycast2(t *testing.T) {
+	r := Rect{Position: Point2{0, 0}, Size: Vec2{1, 2}}
+	testCases := []struct {
+		name   string
+		ray    Ray2
+		hit    bool
+		fail   RaycastFail
+		dist   float32
+		normal Vec2
+	}{
+		{name: "from-left", ray: Ray2{Origin: Point2{-5, 0}, Direction: X2}, hit: true, dist: 4, normal: Vec2{-1, 0}},
+		{name: "from-right", ray: Ray2{Origin: Point2{5, 1}, Direction: Vec2{-1, 0}}, hit: true, dist: 4, normal: Vec2{1, 0}},
+		{name: "from-below", ray: Ray2{Origin: Point2{0.5, -5}, Direction: Y2}, hit: true, dist: 3, normal: Vec2{0, -1}},
+		{name: "diagonal", ray: Ray2FromPoints(Point2{-3, -1}, Point2{-2, 0}), hit: true, dist: 2 * sqrt(2), normal: Vec2{-1, 0}},
+		{name: "inside", ray: Ray2{Origin: Point2{0, 0}, Direction: Y2}, hit: true, dist: 2, normal: Vec2{0, 1}},
+		{name: "parallel-miss", ray: Ray2{Origin: Point2{-5, 3}, Direction: X2}, fail: RaycastFailOutsideBounds},
+		{name: "miss", ray: Ray2{Origin: Point2{-5, 0}, Direction: Vec2{1, 1}.Normalize()}, fail: RaycastFailOutsideBounds},
+		{name: "behind", ray: Ray2{Origin: Point2{5, 0}, Direction: X2}, fail: RaycastFailTargetBehindRayOrigin},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, hit := r.Raycast2(tc.ray)
+			if hit != tc.hit {
+				t.Fatalf("got hit %v, wanted %v [fail=%v]", hit, tc.hit, res.Fail)
+			}
+			if !hit {
+				if res.Fail != tc.fail {
+					t.Errorf("got fail %v, wanted %v", res.Fail, tc.fail)
+				}
+				return
+			}
+			if abs(res.Distance-tc.dist) > 1e-5 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.dist)
+			}
+			if res.Normal != tc.normal {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.normal)
+			}
+			if !res.Point.ApproxEqualThreshold(tc.ray.Point(tc.dist), 1e-5) {
+				t.Errorf("got point %v, wanted %v", res.Point, tc.ray.Point(tc.dist))
+			}
+		})
+	}
+}
+
+func TestShapeMetrics(t *testing.T) {
+	type metrics interface {
+		Center() Point3
+		Volume() float32
+		SurfaceArea() float32
+	}
+	testCases := []struct {
+		name       string
+		shape      metrics
+		wantCenter Point3
+		wantVolume float32
+		wantArea   float32
+	}{
+		{
+			name:       "aabb",
+			shape:      &AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}},
+			wantCenter: Point3{1, 2, 3},
+			wantVolume: 48,
+			wantArea:   88,
+		},
+		{
+			name:       "obb",
+			shape:      &OBB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}, Orientation: mgl32.QuatRotate(1, Y3)},
+			wantCenter: Point3{1, 2, 3},
+			wantVolume: 48,
+			wantArea:   88,
+		},
+		{
+			name:       "sphere",
+			shape:      &Sphere{Position: Point3{1, 2, 3}, Radius: 2},
+			wantCenter: Point3{1, 2, 3},
+			wantVolume: 32 * pi / 3,
+			wantArea:   16 * pi,
+		},
+		{
+			name:       "capsule",
+			shape:      &Capsule{Start: Point3{0, 0, 0}, End: Point3{0, 4, 0}, Radius: 1},
+			wantCenter: Point3{0, 2, 0},
+			wantVolume: 4*pi + 4*pi/3,
+			wantArea:   8*pi + 4*pi,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.shape.Center(); !nearVec3(got, tc.wantCenter, 1e-6) {
+				t.Errorf("Center: got %v, wanted %v", got, tc.wantCenter)
+			}
+			if got := tc.shape.Volume(); abs(got-tc.wantVolume) > 1e-4 {
+				t.Errorf("Volume: got %v, wanted %v", got, tc.wantVolume)
+			}
+			if got := tc.shape.SurfaceArea(); abs(got-tc.wantArea) > 1e-4 {
+				t.Errorf("SurfaceArea: got %v, wanted %v", got, tc.wantArea)
+			}
+		})
+	}
+
+	r := Rect{Position: Point2{1, 1}, Size: Vec2{2, 3}}
+	if got := r.Area(); got != 24 {
+		t.Errorf("Rect.Area: got %v, wanted 24", got)
+	}
+	if got := r.Perimeter(); got != 20 {
+		t.Errorf("Rect.Perimeter: got %v, wanted 20", got)
+	}
+
+	c := Circle{Radius: 2}
+	if got := c.Area(); abs(got-4*pi) > 1e-5 {
+		t.Errorf("Circle.Area: got %v, wanted 4π", got)
+	}
+	if got := c.Perimeter(); abs(got-4*pi) > 1e-5 {
+		t.Errorf("Circle.Perimeter: got %v, wanted 4π", got)
+	}
+}
+
+func TestProjectOntoAxis(t *testing.T) {
+	testCases := []struct {
+		name  string
+		shape Projecter
+		axis  Vec3
+		want  Interval
+	}{
+		{name: "sphere", shape: &Sphere{Position: Point3{1, 2, 3}, Radius: 2}, axis: Y3, want: Interval{Min: 0, Max: 4}},
+		{name: "sphere-scaled-axis", shape: &Sphere{Position: Point3{1, 2, 3}, Radius: 2}, axis: Vec3{0, 0, 2}, want: Interval{Min: 2, Max: 10}},
+		{name: "capsule-along", shape: &Capsule{Start: Point3{0, 4, 0}, End: Point3{0, 1, 0}, Radius: 1}, axis: Y3, want: Interval{Min: 0, Max: 5}},
+		{name: "capsule-across", shape: &Capsule{Start: Point3{0, 4, 0}, End: Point3{0, 1, 0}, Radius: 1}, axis: X3, want: Interval{Min: -1, Max: 1}},
+		{name: "tri", shape: Tri3{A: Point3{0, 0, 0}, B: Point3{3, 1, 0}, C: Point3{1, -2, 5}}, axis: X3, want: Interval{Min: 0, Max: 3}},
+		{name: "tri-diagonal", shape: Tri3{A: Point3{0, 0, 0}, B: Point3{3, 1, 0}, C: Point3{1, -2, 5}}, axis: Vec3{1, 1, 1}, want: Interval{Min: 0, Max: 4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.shape.ProjectOntoAxis(tc.axis)
+			if abs(got.Min-tc.want.Min) > 1e-5 || abs(got.Max-tc.want.Max) > 1e-5 {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+
+	s := &Sphere{Position: Point3{0, 0, 2}, Radius: 1}
+	tri := Tri3{A: Point3{-1, -1, 0}, B: Point3{1, -1, 0}, C: Point3{0, 1, 0}}
+	if OverlapOnAxis(s, tri, Z3) {
+		t.Errorf("sphere above the triangle should be separated along its normal")
+	}
+	if !OverlapOnAxis(s, tri, X3) {
+		t.Errorf("sphere above the triangle should overlap it along x")
+	}
+}
+
+func TestTri3IntersectsAABB(t *testing.T) {
+	box := &AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}}
+
+	testCases := []struct {
+		name string
+		tri  Tri3
+		want bool
+	}{
+		{name: "inside", tri: Tri3{Point3{-0.5, 0, 0}, Point3{0.5, 0, 0}, Point3{0, 0.5, 0}}, want: true},
+		{name: "encloses box", tri: Tri3{Point3{-10, -10, 0}, Point3{10, -10, 0}, Point3{0, 10, 0}}, want: true},
+		{name: "corner through face", tri: Tri3{Point3{0.5, 0.5, 0.5}, Point3{5, 5, 5}, Point3{5, 0, 5}}, want: true},
+		{name: "touching face", tri: Tri3{Point3{1, -5, -5}, Point3{1, 5, -5}, Point3{1, 0, 5}}, want: true},
+		{name: "beyond face", tri: Tri3{Point3{1.1, -5, -5}, Point3{1.1, 5, -5}, Point3{1.1, 0, 5}}},
+		{name: "plane misses corner", tri: Tri3{Point3{3.5, 0, 0}, Point3{0, 3.5, 0}, Point3{0, 0, 3.5}}},
+		{name: "plane cuts corner", tri: Tri3{Point3{2.5, 0, 0}, Point3{0, 2.5, 0}, Point3{0, 0, 2.5}}, want: true},
+		// Separated only by an edge cross product axis
+		{name: "edge axis", tri: Tri3{Point3{3, -0.5, 0}, Point3{-0.5, 3, 0}, Point3{3, 3, 0}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tri.IntersectsAABB(box); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			// The winding of the triangle must not matter
+			rev := Tri3{tc.tri.A, tc.tri.C, tc.tri.B}
+			if got := rev.IntersectsAABB(box); got != tc.want {
+				t.Errorf("reversed: got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
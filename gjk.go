@@ -0,0 +1,143 @@
+package geom
+
+// gjkIntersects reports whether two convex shapes, described by their support functions, lie
+// within tol of each other. A support function returns the point of the shape that lies
+// furthest in the direction d.
+//
+// This is the Gilbert-Johnson-Keerthi distance algorithm. It searches the Minkowski difference
+// A-B for the point closest to the origin, and stops as soon as it has either a point of the
+// difference within tol of the origin or a support plane of the difference further than tol
+// from it. Both are proof of the answer, so shapes that graze each other are judged by their
+// true separation however slowly the search converges.
+func gjkIntersects(supportA, supportB func(d Vec3) Point3, tol float32) bool {
+	support := func(d Vec3) Vec3 {
+		return supportA(d).Sub(supportB(d.Mul(-1)))
+	}
+
+	var simplex [4]Vec3
+	simplex[0] = support(Vec3{1, 0, 0})
+	n := 1
+	v := simplex[0]
+	for i := 0; i < 64; i++ {
+		vv := v.Dot(v)
+		if vv <= tol*tol {
+			return true
+		}
+		w := support(v.Mul(-1))
+		if -w.Dot(v) < -tol*sqrt(vv) {
+			// The whole difference lies more than tol beyond the plane through the origin
+			// facing -v
+			return false
+		}
+		if vv-v.Dot(w) <= vv*1e-6 {
+			// No further progress is possible, so v is as close as the difference gets
+			return false
+		}
+		simplex[n] = w
+		n++
+		var inside bool
+		v, n, inside = gjkClosest(&simplex, n)
+		if inside {
+			return true
+		}
+		if v.Dot(v) >= vv {
+			// Rounding has stalled the search, so the last distance is the best estimate
+			return false
+		}
+	}
+	// Not proven apart within the iteration limit, which only happens when the shapes are
+	// within a rounding error of tol
+	return true
+}
+
+// gjkClosest finds the point of the simplex closest to the origin and reduces the simplex to
+// the fewest of its points that still contain that point. It reports whether the simplex
+// encloses the origin.
+func gjkClosest(s *[4]Vec3, n int) (Vec3, int, bool) {
+	if n == 4 && tetrahedronContainsOrigin(s[0], s[1], s[2], s[3]) {
+		return Vec3{}, 4, true
+	}
+
+	// Johnson's subalgorithm by brute force: of the subsets whose affine hull contains its
+	// point closest to the origin within the subset, keep the nearest. There are at most 14.
+	best := float32(maxFloat32)
+	var bestV Vec3
+	var bestSet [3]Vec3
+	bestN := 0
+	for mask := 1; mask < 1<<n; mask++ {
+		var set [3]Vec3
+		m := 0
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				if m == 3 {
+					m = 4
+					break
+				}
+				set[m] = s[i]
+				m++
+			}
+		}
+		if m > 3 {
+			continue
+		}
+		v, ok := closestOnAffineHull(set[:m])
+		if !ok {
+			continue
+		}
+		if d := v.Dot(v); d < best {
+			best, bestV, bestSet, bestN = d, v, set, m
+		}
+	}
+	copy(s[:], bestSet[:bestN])
+	return bestV, bestN, false
+}
+
+// closestOnAffineHull returns the point of the affine hull of pts that is closest to the
+// origin. It reports false if that point does not lie strictly within the simplex formed by
+// pts, or the points are degenerate.
+func closestOnAffineHull(pts []Vec3) (Vec3, bool) {
+	switch len(pts) {
+	case 1:
+		return pts[0], true
+	case 2:
+		e := pts[1].Sub(pts[0])
+		ee := e.Dot(e)
+		if ee <= 0 {
+			return Vec3{}, false
+		}
+		t := -pts[0].Dot(e) / ee
+		if t <= 0 || t >= 1 {
+			return Vec3{}, false
+		}
+		return pts[0].Add(e.Mul(t)), true
+	}
+
+	// Project the origin onto the plane along its normal, which stays accurate for thin
+	// triangles far from the origin where solving for the edge weights does not
+	a, b, c := pts[0], pts[1], pts[2]
+	n := b.Sub(a).Cross(c.Sub(a))
+	nn := n.Dot(n)
+	if nn <= 0 {
+		return Vec3{}, false
+	}
+	v := n.Mul(a.Dot(n) / nn)
+	if n.Dot(b.Sub(v).Cross(c.Sub(v))) <= 0 || n.Dot(c.Sub(v).Cross(a.Sub(v))) <= 0 || n.Dot(a.Sub(v).Cross(b.Sub(v))) <= 0 {
+		return Vec3{}, false
+	}
+	return v, true
+}
+
+// tetrahedronContainsOrigin reports whether the origin lies within the tetrahedron abcd. The
+// origin must lie on the same side of each face as the opposite corner.
+func tetrahedronContainsOrigin(a, b, c, d Vec3) bool {
+	sameSide := func(p, q, r, opposite Vec3) bool {
+		n := q.Sub(p).Cross(r.Sub(p))
+		sd := n.Dot(opposite.Sub(p))
+		if sd == 0 {
+			// Flat tetrahedron
+			return false
+		}
+		return n.Dot(p.Mul(-1))*sd >= 0
+	}
+	return sameSide(a, b, c, d) && sameSide(a, c, d, b) && sameSide(a, b, d, c) && sameSide(b, c, d, a)
+}
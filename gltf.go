@@ -0,0 +1,548 @@
+package geom
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The glTF loader extracts triangle geometry for collision and spatial queries from glTF 2.0
+// assets, in either the JSON (.gltf) or binary (.glb) form. Only positions, indices and,
+// optionally, normals are read. Materials, textures, skins and animation are ignored and
+// primitives that are not made of triangles are skipped.
+
+// GLTFOptions controls how glTF assets are read.
+type GLTFOptions struct {
+	// Normals requests that vertex normals are read when a primitive has them.
+	Normals bool
+
+	// ReadFile returns the contents of an external buffer given its URI. It is needed for
+	// assets whose buffers are neither embedded as data URIs nor held in a GLB binary chunk.
+	// LoadGLTF sets it to read files relative to the asset when it is nil, refusing URIs that
+	// are absolute or lead outside the asset's directory.
+	ReadFile func(uri string) ([]byte, error)
+}
+
+// GLTFPrimitive is the geometry of one primitive of a glTF mesh, transformed into the space of
+// the scene by the transforms of the node that instances it and all of that node's ancestors.
+// A mesh instanced by several nodes gives one GLTFPrimitive per instance.
+type GLTFPrimitive struct {
+	Node      string // the name of the node that instances the mesh
+	MeshName  string // the name of the mesh
+	Primitive int    // the index of the primitive within the mesh
+	Mesh      *TriMesh
+}
+
+// LoadGLTF reads the glTF or GLB file at path and returns the triangle geometry of the default
+// scene.
+func LoadGLTF(path string, opts GLTFOptions) ([]GLTFPrimitive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ReadFile == nil {
+		opts.ReadFile = gltfFileReader(filepath.Dir(path))
+	}
+	return ReadGLTF(data, opts)
+}
+
+// gltfFileReader returns a function that reads the files named by relative URIs from dir. URIs
+// are percent decoded and must not be absolute or lead outside dir.
+func gltfFileReader(dir string) func(uri string) ([]byte, error) {
+	return func(uri string) ([]byte, error) {
+		name, err := url.PathUnescape(uri)
+		if err != nil {
+			return nil, fmt.Errorf("geom: gltf uri %q: %w", uri, err)
+		}
+		name = filepath.Clean(filepath.FromSlash(name))
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("geom: gltf uri %q is outside the asset directory", uri)
+		}
+		return os.ReadFile(filepath.Join(dir, name))
+	}
+}
+
+// ReadGLTF parses a glTF or GLB asset and returns the triangle geometry of its default scene.
+// The form of the asset is detected from its content.
+func ReadGLTF(data []byte, opts GLTFOptions) ([]GLTFPrimitive, error) {
+	js, bin := data, []byte(nil)
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		var err error
+		if js, bin, err = splitGLB(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var doc gltfDocument
+	if err := json.Unmarshal(js, &doc); err != nil {
+		return nil, fmt.Errorf("geom: decode gltf: %w", err)
+	}
+	if len(doc.ExtensionsRequired) > 0 {
+		// Required extensions, such as mesh compression, change how geometry is stored
+		return nil, fmt.Errorf("geom: gltf requires unsupported extension %q", doc.ExtensionsRequired[0])
+	}
+
+	l := gltfLoader{doc: &doc, bin: bin, opts: opts}
+	return l.load()
+}
+
+const (
+	glbMagic     = 0x46546c67 // "glTF"
+	glbChunkJSON = 0x4e4f534a // "JSON"
+	glbChunkBIN  = 0x004e4942 // "BIN\x00"
+)
+
+// splitGLB returns the JSON and binary chunks of a GLB container.
+func splitGLB(data []byte) ([]byte, []byte, error) {
+	if len(data) < 12 {
+		return nil, nil, errors.New("geom: glb header is truncated")
+	}
+	if v := binary.LittleEndian.Uint32(data[4:]); v != 2 {
+		return nil, nil, fmt.Errorf("geom: unsupported glb version %d", v)
+	}
+	if n := binary.LittleEndian.Uint32(data[8:]); int(n) < len(data) {
+		data = data[:n]
+	}
+
+	var js, bin []byte
+	for off := 12; off+8 <= len(data); {
+		n := int(binary.LittleEndian.Uint32(data[off:]))
+		typ := binary.LittleEndian.Uint32(data[off+4:])
+		off += 8
+		if n > len(data)-off {
+			return nil, nil, errors.New("geom: glb chunk is truncated")
+		}
+		switch {
+		case typ == glbChunkJSON && js == nil:
+			js = data[off : off+n]
+		case typ == glbChunkBIN && bin == nil:
+			bin = data[off : off+n]
+		}
+		off += n
+	}
+	if js == nil {
+		return nil, nil, errors.New("geom: glb has no json chunk")
+	}
+	return js, bin, nil
+}
+
+// gltfDocument holds the parts of the glTF JSON that are needed to extract geometry.
+type gltfDocument struct {
+	ExtensionsRequired []string `json:"extensionsRequired"`
+	Scene              *int     `json:"scene"`
+	Scenes             []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string       `json:"name"`
+		Children    []int        `json:"children"`
+		Mesh        *int         `json:"mesh"`
+		Matrix      *[16]float32 `json:"matrix"`
+		Translation *[3]float32  `json:"translation"`
+		Rotation    *[4]float32  `json:"rotation"` // x, y, z, w
+		Scale       *[3]float32  `json:"scale"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Accessors []struct {
+		BufferView    *int            `json:"bufferView"`
+		ByteOffset    int             `json:"byteOffset"`
+		ComponentType int             `json:"componentType"`
+		Count         int             `json:"count"`
+		Type          string          `json:"type"`
+		Sparse        json.RawMessage `json:"sparse"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+}
+
+// glTF primitive modes and accessor component types
+const (
+	gltfModeTriangles     = 4
+	gltfModeTriangleStrip = 5
+	gltfModeTriangleFan   = 6
+
+	gltfUnsignedByte  = 5121
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+)
+
+type gltfLoader struct {
+	doc     *gltfDocument
+	bin     []byte
+	opts    GLTFOptions
+	buffers map[int][]byte
+	prims   []GLTFPrimitive
+	onPath  []bool // the nodes between the root and the node being visited
+}
+
+func (l *gltfLoader) load() ([]GLTFPrimitive, error) {
+	// The node hierarchy must be a forest, as the specification requires. Checking this up
+	// front stops a node from being reached along several paths, which could otherwise make
+	// the walk exponential in the depth of the hierarchy.
+	parent := make([]int, len(l.doc.Nodes))
+	for i := range parent {
+		parent[i] = -1
+	}
+	for i, n := range l.doc.Nodes {
+		for _, c := range n.Children {
+			if c < 0 || c >= len(l.doc.Nodes) {
+				return nil, fmt.Errorf("geom: gltf node %d does not exist", c)
+			}
+			if parent[c] >= 0 {
+				return nil, fmt.Errorf("geom: gltf node %d has more than one parent", c)
+			}
+			parent[c] = i
+		}
+	}
+
+	var roots []int
+	switch {
+	case len(l.doc.Scenes) == 0:
+		// Without scenes every node that is not a child of another node is a root
+		for i := range l.doc.Nodes {
+			if parent[i] < 0 {
+				roots = append(roots, i)
+			}
+		}
+	default:
+		scene := 0
+		if l.doc.Scene != nil {
+			scene = *l.doc.Scene
+		}
+		if scene < 0 || scene >= len(l.doc.Scenes) {
+			return nil, fmt.Errorf("geom: gltf scene %d does not exist", scene)
+		}
+		roots = l.doc.Scenes[scene].Nodes
+		listed := make(map[int]bool, len(roots))
+		for _, n := range roots {
+			if n >= 0 && n < len(parent) && parent[n] >= 0 {
+				return nil, fmt.Errorf("geom: gltf scene node %d is not a root node", n)
+			}
+			if listed[n] {
+				return nil, fmt.Errorf("geom: gltf scene lists node %d more than once", n)
+			}
+			listed[n] = true
+		}
+	}
+
+	l.onPath = make([]bool, len(l.doc.Nodes))
+	for _, n := range roots {
+		if err := l.visit(n, mgl32.Ident4(), 0); err != nil {
+			return nil, err
+		}
+	}
+	return l.prims, nil
+}
+
+// gltfMaxDepth limits the depth of the node hierarchy, which is walked recursively.
+const gltfMaxDepth = 256
+
+// gltfMaxZeroCount limits the number of elements in an accessor without a buffer view, whose
+// zero filled data is allocated rather than read from a buffer.
+const gltfMaxZeroCount = 1 << 24
+
+// visit adds the primitives of node n and its descendants, given the transform of its parent.
+func (l *gltfLoader) visit(n int, parent Mat4, depth int) error {
+	if n < 0 || n >= len(l.doc.Nodes) {
+		return fmt.Errorf("geom: gltf node %d does not exist", n)
+	}
+	if l.onPath[n] {
+		return fmt.Errorf("geom: gltf node hierarchy has a cycle through node %d", n)
+	}
+	if depth > gltfMaxDepth {
+		return errors.New("geom: gltf node hierarchy is too deep")
+	}
+	l.onPath[n] = true
+	defer func() { l.onPath[n] = false }()
+	node := &l.doc.Nodes[n]
+
+	local := mgl32.Ident4()
+	if node.Matrix != nil {
+		local = Mat4(*node.Matrix)
+	} else {
+		if t := node.Translation; t != nil {
+			local = local.Mul4(mgl32.Translate3D(t[0], t[1], t[2]))
+		}
+		if r := node.Rotation; r != nil {
+			local = local.Mul4(mgl32.Quat{W: r[3], V: Vec3{r[0], r[1], r[2]}}.Normalize().Mat4())
+		}
+		if s := node.Scale; s != nil {
+			local = local.Mul4(mgl32.Scale3D(s[0], s[1], s[2]))
+		}
+	}
+	world := parent.Mul4(local)
+
+	if node.Mesh != nil {
+		if err := l.addMesh(*node.Mesh, node.Name, world); err != nil {
+			return err
+		}
+	}
+	for _, c := range node.Children {
+		if err := l.visit(c, world, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *gltfLoader) addMesh(m int, nodeName string, world Mat4) error {
+	if m < 0 || m >= len(l.doc.Meshes) {
+		return fmt.Errorf("geom: gltf mesh %d does not exist", m)
+	}
+	mesh := &l.doc.Meshes[m]
+	for pi, prim := range mesh.Primitives {
+		mode := gltfModeTriangles
+		if prim.Mode != nil {
+			mode = *prim.Mode
+		}
+		if mode != gltfModeTriangles && mode != gltfModeTriangleStrip && mode != gltfModeTriangleFan {
+			continue
+		}
+		pos, ok := prim.Attributes["POSITION"]
+		if !ok {
+			continue
+		}
+
+		tm := &TriMesh{}
+		var err error
+		if tm.Positions, err = l.readVec3(pos); err != nil {
+			return fmt.Errorf("geom: gltf mesh %d primitive %d positions: %w", m, pi, err)
+		}
+		if nrm, ok := prim.Attributes["NORMAL"]; ok && l.opts.Normals {
+			if tm.Normals, err = l.readVec3(nrm); err != nil {
+				return fmt.Errorf("geom: gltf mesh %d primitive %d normals: %w", m, pi, err)
+			}
+			if len(tm.Normals) != len(tm.Positions) {
+				return fmt.Errorf("geom: gltf mesh %d primitive %d has %d normals for %d positions", m, pi, len(tm.Normals), len(tm.Positions))
+			}
+		}
+
+		var idx []uint32
+		if prim.Indices != nil {
+			if idx, err = l.readIndices(*prim.Indices); err != nil {
+				return fmt.Errorf("geom: gltf mesh %d primitive %d indices: %w", m, pi, err)
+			}
+			for _, i := range idx {
+				if int(i) >= len(tm.Positions) {
+					return fmt.Errorf("geom: gltf mesh %d primitive %d has index %d out of range", m, pi, i)
+				}
+			}
+		} else {
+			idx = make([]uint32, len(tm.Positions))
+			for i := range idx {
+				idx[i] = uint32(i)
+			}
+		}
+		tm.Indices = gltfTriangles(idx, mode)
+
+		tm.TransformMat4(world)
+		l.prims = append(l.prims, GLTFPrimitive{
+			Node:      nodeName,
+			MeshName:  mesh.Name,
+			Primitive: pi,
+			Mesh:      tm,
+		})
+	}
+	return nil
+}
+
+// gltfTriangles converts indices in the given primitive mode to a list of triangles.
+func gltfTriangles(idx []uint32, mode int) []uint32 {
+	switch mode {
+	case gltfModeTriangleStrip:
+		var tris []uint32
+		for i := 2; i < len(idx); i++ {
+			// Every other triangle in a strip is wound the other way
+			if i%2 == 0 {
+				tris = append(tris, idx[i-2], idx[i-1], idx[i])
+			} else {
+				tris = append(tris, idx[i-1], idx[i-2], idx[i])
+			}
+		}
+		return tris
+	case gltfModeTriangleFan:
+		var tris []uint32
+		for i := 2; i < len(idx); i++ {
+			tris = append(tris, idx[i-1], idx[i], idx[0])
+		}
+		return tris
+	}
+	return idx[:len(idx)/3*3]
+}
+
+// accessorData returns the bytes of an accessor, the size of each element and the stride
+// between elements.
+func (l *gltfLoader) accessorData(a int, typ string, compSize int) ([]byte, int, int, error) {
+	if a < 0 || a >= len(l.doc.Accessors) {
+		return nil, 0, 0, fmt.Errorf("accessor %d does not exist", a)
+	}
+	acc := &l.doc.Accessors[a]
+	if acc.Type != typ {
+		return nil, 0, 0, fmt.Errorf("accessor %d has type %s, wanted %s", a, acc.Type, typ)
+	}
+	if len(acc.Sparse) > 0 {
+		return nil, 0, 0, fmt.Errorf("accessor %d is sparse, which is not supported", a)
+	}
+	comps := 1
+	if typ == "VEC3" {
+		comps = 3
+	}
+	elem := comps * compSize
+	if acc.Count < 0 {
+		return nil, 0, 0, fmt.Errorf("accessor %d has negative count %d", a, acc.Count)
+	}
+	if acc.BufferView == nil || acc.Count == 0 {
+		// An accessor without a buffer view is all zeros. Nothing in the file bounds its count,
+		// so cap it before allocating.
+		if acc.Count > gltfMaxZeroCount {
+			return nil, 0, 0, fmt.Errorf("accessor %d has count %d without a buffer view", a, acc.Count)
+		}
+		return make([]byte, acc.Count*elem), elem, elem, nil
+	}
+
+	v := *acc.BufferView
+	if v < 0 || v >= len(l.doc.BufferViews) {
+		return nil, 0, 0, fmt.Errorf("buffer view %d does not exist", v)
+	}
+	view := &l.doc.BufferViews[v]
+	buf, err := l.buffer(view.Buffer)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > len(buf) || view.ByteLength > len(buf)-view.ByteOffset {
+		return nil, 0, 0, fmt.Errorf("buffer view %d is outside its buffer", v)
+	}
+	data := buf[view.ByteOffset : view.ByteOffset+view.ByteLength]
+
+	stride := elem
+	if view.ByteStride != 0 {
+		stride = view.ByteStride
+	}
+	// Compare element counts rather than byte lengths so a huge count cannot overflow
+	if acc.ByteOffset < 0 || stride < elem || acc.ByteOffset+elem > len(data) || acc.Count-1 > (len(data)-acc.ByteOffset-elem)/stride {
+		return nil, 0, 0, fmt.Errorf("accessor %d is outside its buffer view", a)
+	}
+	return data[acc.ByteOffset:], elem, stride, nil
+}
+
+func (l *gltfLoader) readVec3(a int) ([]Vec3, error) {
+	if a >= 0 && a < len(l.doc.Accessors) && l.doc.Accessors[a].ComponentType != gltfFloat {
+		return nil, fmt.Errorf("accessor %d is not made of floats", a)
+	}
+	data, _, stride, err := l.accessorData(a, "VEC3", 4)
+	if err != nil {
+		return nil, err
+	}
+	vs := make([]Vec3, l.doc.Accessors[a].Count)
+	for i := range vs {
+		e := data[i*stride:]
+		vs[i] = Vec3{
+			math.Float32frombits(binary.LittleEndian.Uint32(e)),
+			math.Float32frombits(binary.LittleEndian.Uint32(e[4:])),
+			math.Float32frombits(binary.LittleEndian.Uint32(e[8:])),
+		}
+	}
+	return vs, nil
+}
+
+func (l *gltfLoader) readIndices(a int) ([]uint32, error) {
+	if a < 0 || a >= len(l.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d does not exist", a)
+	}
+	ct := l.doc.Accessors[a].ComponentType
+	var size int
+	switch ct {
+	case gltfUnsignedByte:
+		size = 1
+	case gltfUnsignedShort:
+		size = 2
+	case gltfUnsignedInt:
+		size = 4
+	default:
+		return nil, fmt.Errorf("accessor %d has unsupported component type %d for indices", a, ct)
+	}
+	data, _, stride, err := l.accessorData(a, "SCALAR", size)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]uint32, l.doc.Accessors[a].Count)
+	for i := range idx {
+		e := data[i*stride:]
+		switch size {
+		case 1:
+			idx[i] = uint32(e[0])
+		case 2:
+			idx[i] = uint32(binary.LittleEndian.Uint16(e))
+		case 4:
+			idx[i] = binary.LittleEndian.Uint32(e)
+		}
+	}
+	return idx, nil
+}
+
+// buffer returns the contents of buffer b, loading it on first use.
+func (l *gltfLoader) buffer(b int) ([]byte, error) {
+	if data, ok := l.buffers[b]; ok {
+		return data, nil
+	}
+	if b < 0 || b >= len(l.doc.Buffers) {
+		return nil, fmt.Errorf("buffer %d does not exist", b)
+	}
+	uri := l.doc.Buffers[b].URI
+
+	var data []byte
+	var err error
+	switch {
+	case uri == "":
+		// Only the first buffer of a GLB may omit its uri, and it refers to the binary chunk
+		if b != 0 || l.bin == nil {
+			return nil, fmt.Errorf("buffer %d has no data", b)
+		}
+		data = l.bin
+	case strings.HasPrefix(uri, "data:"):
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("buffer %d has an unsupported data uri", b)
+		}
+		if data, err = base64.StdEncoding.DecodeString(uri[comma+1:]); err != nil {
+			return nil, fmt.Errorf("buffer %d: %w", b, err)
+		}
+	default:
+		if l.opts.ReadFile == nil {
+			return nil, fmt.Errorf("buffer %d refers to external file %q", b, uri)
+		}
+		if data, err = l.opts.ReadFile(uri); err != nil {
+			return nil, fmt.Errorf("buffer %d: %w", b, err)
+		}
+	}
+	if l.buffers == nil {
+		l.buffers = make(map[int][]byte)
+	}
+	l.buffers[b] = data
+	return data, nil
+}
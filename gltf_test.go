@@ -0,0 +1,379 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testGLTFBuffer returns a buffer holding a unit quad in the XY plane facing +Z: four positions,
+// four normals and six unsigned short indices, with the layout described by testGLTFDoc.
+func testGLTFBuffer() []byte {
+	var buf bytes.Buffer
+	for _, p := range []Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}} {
+		binary.Write(&buf, binary.LittleEndian, p)
+	}
+	for i := 0; i < 4; i++ {
+		binary.Write(&buf, binary.LittleEndian, Vec3{0, 0, 1})
+	}
+	binary.Write(&buf, binary.LittleEndian, []uint16{0, 1, 2, 0, 2, 3})
+	return buf.Bytes()
+}
+
+// testGLTFDoc returns a glTF document using buffer 0 with the given uri, which is omitted when
+// empty. The quad mesh is instanced by a child node that mirrors it in X beneath a translated
+// parent, and by a node with a matrix that scales it by two.
+func testGLTFDoc(uri string) map[string]any {
+	buffer := map[string]any{"byteLength": 108}
+	if uri != "" {
+		buffer["uri"] = uri
+	}
+	return map[string]any{
+		"asset":  map[string]any{"version": "2.0"},
+		"scene":  0,
+		"scenes": []any{map[string]any{"nodes": []int{0, 2}}},
+		"nodes": []any{
+			map[string]any{"name": "parent", "translation": []float32{10, 0, 0}, "children": []int{1}},
+			map[string]any{"name": "mirrored", "mesh": 0, "scale": []float32{-1, 1, 1}},
+			map[string]any{"name": "scaled", "mesh": 0, "matrix": []float32{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 5, 1}},
+		},
+		"meshes": []any{map[string]any{
+			"name": "quad",
+			"primitives": []any{
+				map[string]any{"attributes": map[string]int{"POSITION": 0, "NORMAL": 1}, "indices": 2},
+				map[string]any{"attributes": map[string]int{"POSITION": 0}, "mode": 1}, // lines are skipped
+			},
+		}},
+		"accessors": []any{
+			map[string]any{"bufferView": 0, "componentType": gltfFloat, "count": 4, "type": "VEC3"},
+			map[string]any{"bufferView": 0, "byteOffset": 48, "componentType": gltfFloat, "count": 4, "type": "VEC3"},
+			map[string]any{"bufferView": 1, "componentType": gltfUnsignedShort, "count": 6, "type": "SCALAR"},
+		},
+		"bufferViews": []any{
+			map[string]any{"buffer": 0, "byteOffset": 0, "byteLength": 96},
+			map[string]any{"buffer": 0, "byteOffset": 96, "byteLength": 12},
+		},
+		"buffers": []any{buffer},
+	}
+}
+
+// testGLB packs a glTF document and binary buffer into a GLB container.
+func testGLB(t *testing.T, doc map[string]any, bin []byte) []byte {
+	js, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{glbMagic, 2, uint32(12 + 8 + len(js) + 8 + len(bin))})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	buf.Write(js)
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(bin)), glbChunkBIN})
+	buf.Write(bin)
+	return buf.Bytes()
+}
+
+func checkGLTFQuads(t *testing.T, prims []GLTFPrimitive) {
+	t.Helper()
+	if len(prims) != 2 {
+		t.Fatalf("got %d primitives, wanted 2", len(prims))
+	}
+
+	m := prims[0]
+	if m.Node != "mirrored" || m.MeshName != "quad" || m.Primitive != 0 {
+		t.Errorf("got node %q mesh %q primitive %d", m.Node, m.MeshName, m.Primitive)
+	}
+	want := []Point3{{10, 0, 0}, {9, 0, 0}, {9, 1, 0}, {10, 1, 0}}
+	if !reflect.DeepEqual(m.Mesh.Positions, want) {
+		t.Errorf("mirrored positions: got %v, wanted %v", m.Mesh.Positions, want)
+	}
+	// Mirroring reverses the winding so the quad still faces +Z
+	if m.Mesh.Len() != 2 {
+		t.Fatalf("got %d triangles, wanted 2", m.Mesh.Len())
+	}
+	for i := 0; i < m.Mesh.Len(); i++ {
+		if n := m.Mesh.Tri(i).Normal(); !nearVec3(n, Vec3{0, 0, 1}, 1e-6) {
+			t.Errorf("mirrored triangle %d normal: got %v, wanted (0, 0, 1)", i, n)
+		}
+	}
+	if len(m.Mesh.Normals) != 4 || !nearVec3(m.Mesh.Normals[0], Vec3{0, 0, 1}, 1e-6) {
+		t.Errorf("mirrored normals: got %v", m.Mesh.Normals)
+	}
+
+	s := prims[1]
+	if s.Node != "scaled" {
+		t.Errorf("got node %q, wanted scaled", s.Node)
+	}
+	if b := s.Mesh.Bounds(); b.Min() != (Point3{0, 0, 5}) || b.Max() != (Point3{2, 2, 5}) {
+		t.Errorf("scaled bounds: got %v", b)
+	}
+}
+
+func TestReadGLTF(t *testing.T) {
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(testGLTFBuffer())
+	js, err := json.Marshal(testGLTFDoc(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prims, err := ReadGLTF(js, GLTFOptions{Normals: true})
+	if err != nil {
+		t.Fatalf("ReadGLTF: %v", err)
+	}
+	checkGLTFQuads(t, prims)
+
+	// Normals are only read on request
+	prims, err = ReadGLTF(js, GLTFOptions{})
+	if err != nil {
+		t.Fatalf("ReadGLTF: %v", err)
+	}
+	if prims[0].Mesh.Normals != nil {
+		t.Errorf("got normals without asking for them")
+	}
+}
+
+func TestReadGLB(t *testing.T) {
+	prims, err := ReadGLTF(testGLB(t, testGLTFDoc(""), testGLTFBuffer()), GLTFOptions{Normals: true})
+	if err != nil {
+		t.Fatalf("ReadGLTF: %v", err)
+	}
+	checkGLTFQuads(t, prims)
+}
+
+func TestLoadGLTFExternalBuffer(t *testing.T) {
+	dir := t.TempDir()
+	js, err := json.Marshal(testGLTFDoc("quad.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "quad.gltf"), js, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "quad.bin"), testGLTFBuffer(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prims, err := LoadGLTF(filepath.Join(dir, "quad.gltf"), GLTFOptions{Normals: true})
+	if err != nil {
+		t.Fatalf("LoadGLTF: %v", err)
+	}
+	checkGLTFQuads(t, prims)
+
+	// Without a way to read files the external buffer cannot be loaded
+	if _, err := ReadGLTF(js, GLTFOptions{}); err == nil {
+		t.Errorf("ReadGLTF: got no error for an external buffer")
+	}
+}
+
+func TestLoadGLTFBufferURIs(t *testing.T) {
+	dir := t.TempDir()
+	assets := filepath.Join(dir, "assets")
+	if err := os.Mkdir(assets, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{filepath.Join(dir, "outside.bin"), filepath.Join(assets, "my quad.bin")} {
+		if err := os.WriteFile(name, testGLTFBuffer(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		uri     string
+		wantErr bool
+	}{
+		{uri: "my%20quad.bin"},
+		{uri: "./sub/../my%20quad.bin"},
+		{uri: "../outside.bin", wantErr: true},
+		{uri: "sub/../../outside.bin", wantErr: true},
+		{uri: "%2E%2E/outside.bin", wantErr: true},
+		{uri: filepath.ToSlash(filepath.Join(dir, "outside.bin")), wantErr: true},
+		{uri: "bad%zzescape.bin", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.uri, func(t *testing.T) {
+			js, err := json.Marshal(testGLTFDoc(tc.uri))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(assets, "quad.gltf")
+			if err := os.WriteFile(path, js, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			prims, err := LoadGLTF(path, GLTFOptions{Normals: true})
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadGLTF: %v", err)
+			}
+			checkGLTFQuads(t, prims)
+		})
+	}
+}
+
+func TestReadGLTFErrors(t *testing.T) {
+	bin := testGLTFBuffer()
+	testCases := []struct {
+		name   string
+		modify func(doc map[string]any)
+	}{
+		{
+			name:   "required extension",
+			modify: func(doc map[string]any) { doc["extensionsRequired"] = []string{"KHR_draco_mesh_compression"} },
+		},
+		{
+			name: "accessor out of range",
+			modify: func(doc map[string]any) {
+				doc["accessors"].([]any)[0].(map[string]any)["count"] = 100
+			},
+		},
+		{
+			name: "negative count",
+			modify: func(doc map[string]any) {
+				doc["accessors"].([]any)[0].(map[string]any)["count"] = -1
+			},
+		},
+		{
+			name: "negative count without buffer view",
+			modify: func(doc map[string]any) {
+				acc := doc["accessors"].([]any)[0].(map[string]any)
+				delete(acc, "bufferView")
+				acc["count"] = -1
+			},
+		},
+		{
+			name: "huge count",
+			modify: func(doc map[string]any) {
+				doc["accessors"].([]any)[0].(map[string]any)["count"] = math.MaxInt64
+			},
+		},
+		{
+			name: "huge count without buffer view",
+			modify: func(doc map[string]any) {
+				acc := doc["accessors"].([]any)[0].(map[string]any)
+				delete(acc, "bufferView")
+				acc["count"] = math.MaxInt64 / 4
+			},
+		},
+		{
+			name: "buffer view overflowing its buffer",
+			modify: func(doc map[string]any) {
+				view := doc["bufferViews"].([]any)[0].(map[string]any)
+				view["byteOffset"] = 1 << 62
+				view["byteLength"] = 1 << 62
+			},
+		},
+		{
+			name: "index out of range",
+			modify: func(doc map[string]any) {
+				doc["accessors"].([]any)[2].(map[string]any)["count"] = 6
+				doc["accessors"].([]any)[0].(map[string]any)["count"] = 2
+			},
+		},
+		{
+			name: "cycle",
+			modify: func(doc map[string]any) {
+				doc["nodes"].([]any)[1].(map[string]any)["children"] = []int{0}
+			},
+		},
+		{
+			name: "shared child",
+			modify: func(doc map[string]any) {
+				doc["nodes"].([]any)[2].(map[string]any)["children"] = []int{1}
+			},
+		},
+		{
+			name: "repeated child",
+			modify: func(doc map[string]any) {
+				doc["nodes"].([]any)[0].(map[string]any)["children"] = []int{1, 1}
+			},
+		},
+		{
+			// Each pair of nodes shares the next pair as children, so there are 2^64 paths to
+			// the last pair
+			name: "shared children in every level",
+			modify: func(doc map[string]any) {
+				var nodes []any
+				for i := 0; i < 64; i++ {
+					for k := 0; k < 2; k++ {
+						n := map[string]any{"mesh": 0}
+						if i < 63 {
+							n["children"] = []int{2*i + 2, 2*i + 3}
+						}
+						nodes = append(nodes, n)
+					}
+				}
+				doc["nodes"] = nodes
+				doc["scenes"] = []any{map[string]any{"nodes": []int{0, 1}}}
+			},
+		},
+		{
+			name: "scene node with a parent",
+			modify: func(doc map[string]any) {
+				doc["scenes"] = []any{map[string]any{"nodes": []int{0, 1}}}
+			},
+		},
+		{
+			name: "repeated scene node",
+			modify: func(doc map[string]any) {
+				doc["scenes"] = []any{map[string]any{"nodes": []int{2, 2}}}
+			},
+		},
+		{
+			name:   "missing scene",
+			modify: func(doc map[string]any) { doc["scene"] = 3 },
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := testGLTFDoc("")
+			tc.modify(doc)
+			if _, err := ReadGLTF(testGLB(t, doc, bin), GLTFOptions{}); err == nil {
+				t.Errorf("got no error")
+			}
+		})
+	}
+
+	if _, err := ReadGLTF([]byte("glTF\x01\x00\x00\x00"), GLTFOptions{}); err == nil {
+		t.Errorf("got no error for a truncated glb")
+	}
+	readErr := errors.New("read failed")
+	js, _ := json.Marshal(testGLTFDoc("missing.bin"))
+	if _, err := ReadGLTF(js, GLTFOptions{ReadFile: func(string) ([]byte, error) { return nil, readErr }}); !errors.Is(err, readErr) {
+		t.Errorf("got error %v, wanted %v", err, readErr)
+	}
+}
+
+func TestGLTFTriangles(t *testing.T) {
+	testCases := []struct {
+		name string
+		mode int
+		idx  []uint32
+		want []uint32
+	}{
+		{name: "triangles", mode: gltfModeTriangles, idx: []uint32{0, 1, 2, 3, 4}, want: []uint32{0, 1, 2}},
+		{name: "strip", mode: gltfModeTriangleStrip, idx: []uint32{0, 1, 2, 3, 4}, want: []uint32{0, 1, 2, 2, 1, 3, 2, 3, 4}},
+		{name: "fan", mode: gltfModeTriangleFan, idx: []uint32{0, 1, 2, 3}, want: []uint32{1, 2, 0, 2, 3, 0}},
+		{name: "short strip", mode: gltfModeTriangleStrip, idx: []uint32{0, 1}, want: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := gltfTriangles(tc.idx, tc.mode); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
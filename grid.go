@@ -0,0 +1,175 @@
+package geom
+
+import "math"
+
+// Grid3 is a regular grid of cubic cells in 3 dimensions, each holding a value of type T.
+// Cell (0, 0, 0) has its minimum corner at Origin and cells are stored with x varying fastest,
+// then y, then z.
+type Grid3[T any] struct {
+	Origin   Point3
+	CellSize float32
+	Dims     [3]int
+	Cells    []T
+}
+
+// NewGrid3 returns a grid of nx by ny by nz cells of the given size with its minimum corner at
+// origin. Every cell holds the zero value of T.
+func NewGrid3[T any](origin Point3, cellSize float32, nx, ny, nz int) *Grid3[T] {
+	return &Grid3[T]{
+		Origin:   origin,
+		CellSize: cellSize,
+		Dims:     [3]int{nx, ny, nz},
+		Cells:    make([]T, nx*ny*nz),
+	}
+}
+
+// newGrid3Covering returns a grid whose cells are aligned to multiples of cellSize and which
+// covers the box, with at least one cell along each axis.
+func newGrid3Covering[T any](bmin, bmax Point3, cellSize float32) *Grid3[T] {
+	if cellSize <= 0 {
+		panic("geom: grid cell size must be positive")
+	}
+	var origin Point3
+	var dims [3]int
+	for i := 0; i < 3; i++ {
+		lo := math.Floor(float64(bmin[i] / cellSize))
+		hi := math.Ceil(float64(bmax[i] / cellSize))
+		origin[i] = float32(lo) * cellSize
+		dims[i] = int(hi - lo)
+		if dims[i] < 1 {
+			dims[i] = 1
+		}
+	}
+	return NewGrid3[T](origin, cellSize, dims[0], dims[1], dims[2])
+}
+
+// Len returns the number of cells in the grid.
+func (g *Grid3[T]) Len() int {
+	return len(g.Cells)
+}
+
+// Index returns the position in Cells of the cell at x, y, z.
+func (g *Grid3[T]) Index(x, y, z int) int {
+	return x + g.Dims[0]*(y+g.Dims[1]*z)
+}
+
+// InBounds reports whether x, y, z is a cell of the grid.
+func (g *Grid3[T]) InBounds(x, y, z int) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < g.Dims[0] && y < g.Dims[1] && z < g.Dims[2]
+}
+
+// At returns the value of the cell at x, y, z.
+func (g *Grid3[T]) At(x, y, z int) T {
+	return g.Cells[g.Index(x, y, z)]
+}
+
+// Set sets the value of the cell at x, y, z.
+func (g *Grid3[T]) Set(x, y, z int, v T) {
+	g.Cells[g.Index(x, y, z)] = v
+}
+
+// Cell returns the coordinates of the cell containing p. The coordinates are outside the grid
+// when p is, which can be checked with InBounds.
+func (g *Grid3[T]) Cell(p Point3) (int, int, int) {
+	return int(math.Floor(float64((p[0] - g.Origin[0]) / g.CellSize))),
+		int(math.Floor(float64((p[1] - g.Origin[1]) / g.CellSize))),
+		int(math.Floor(float64((p[2] - g.Origin[2]) / g.CellSize)))
+}
+
+// CellCentre returns the centre of the cell at x, y, z.
+func (g *Grid3[T]) CellCentre(x, y, z int) Point3 {
+	return Point3{
+		g.Origin[0] + (float32(x)+0.5)*g.CellSize,
+		g.Origin[1] + (float32(y)+0.5)*g.CellSize,
+		g.Origin[2] + (float32(z)+0.5)*g.CellSize,
+	}
+}
+
+// CellBounds returns the box occupied by the cell at x, y, z.
+func (g *Grid3[T]) CellBounds(x, y, z int) AABB {
+	h := g.CellSize / 2
+	return AABB{Position: g.CellCentre(x, y, z), Size: Vec3{h, h, h}}
+}
+
+// Bounds returns the box covered by the whole grid.
+func (g *Grid3[T]) Bounds() AABB {
+	return AABBFromCorners(g.Origin, g.Origin.Add(Vec3{
+		float32(g.Dims[0]) * g.CellSize,
+		float32(g.Dims[1]) * g.CellSize,
+		float32(g.Dims[2]) * g.CellSize,
+	}))
+}
+
+// Grid2 is a regular grid of square cells in 2 dimensions, each holding a value of type T.
+// Cell (0, 0) has its minimum corner at Origin and cells are stored with x varying fastest.
+type Grid2[T any] struct {
+	Origin   Point2
+	CellSize float32
+	Dims     [2]int
+	Cells    []T
+}
+
+// NewGrid2 returns a grid of nx by ny cells of the given size with its minimum corner at
+// origin. Every cell holds the zero value of T.
+func NewGrid2[T any](origin Point2, cellSize float32, nx, ny int) *Grid2[T] {
+	return &Grid2[T]{
+		Origin:   origin,
+		CellSize: cellSize,
+		Dims:     [2]int{nx, ny},
+		Cells:    make([]T, nx*ny),
+	}
+}
+
+// Len returns the number of cells in the grid.
+func (g *Grid2[T]) Len() int {
+	return len(g.Cells)
+}
+
+// Index returns the position in Cells of the cell at x, y.
+func (g *Grid2[T]) Index(x, y int) int {
+	return x + g.Dims[0]*y
+}
+
+// InBounds reports whether x, y is a cell of the grid.
+func (g *Grid2[T]) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.Dims[0] && y < g.Dims[1]
+}
+
+// At returns the value of the cell at x, y.
+func (g *Grid2[T]) At(x, y int) T {
+	return g.Cells[g.Index(x, y)]
+}
+
+// Set sets the value of the cell at x, y.
+func (g *Grid2[T]) Set(x, y int, v T) {
+	g.Cells[g.Index(x, y)] = v
+}
+
+// Cell returns the coordinates of the cell containing p. The coordinates are outside the grid
+// when p is, which can be checked with InBounds.
+func (g *Grid2[T]) Cell(p Point2) (int, int) {
+	return int(math.Floor(float64((p[0] - g.Origin[0]) / g.CellSize))),
+		int(math.Floor(float64((p[1] - g.Origin[1]) / g.CellSize)))
+}
+
+// CellCentre returns the centre of the cell at x, y.
+func (g *Grid2[T]) CellCentre(x, y int) Point2 {
+	return Point2{
+		g.Origin[0] + (float32(x)+0.5)*g.CellSize,
+		g.Origin[1] + (float32(y)+0.5)*g.CellSize,
+	}
+}
+
+// CellBounds returns the rectangle occupied by the cell at x, y.
+func (g *Grid2[T]) CellBounds(x, y int) Rect {
+	h := g.CellSize / 2
+	return Rect{Position: g.CellCentre(x, y), Size: Vec2{h, h}}
+}
+
+// Bounds returns the rectangle covered by the whole grid.
+func (g *Grid2[T]) Bounds() Rect {
+	return RectFromCorners(g.Origin, g.Origin.Add(Vec2{
+		float32(g.Dims[0]) * g.CellSize,
+		float32(g.Dims[1]) * g.CellSize,
+	}))
+}
@@ -0,0 +1,209 @@
+package geom
+
+import "math"
+
+// DistanceTransform returns a grid of the same shape as g in which each cell holds the
+// distance from its centre to the centre of the nearest cell that is set in g. Set cells hold
+// zero. Distances are exact Euclidean distances measured in the units of the grid, not in
+// cells. Every cell holds +Inf when no cell of g is set.
+func DistanceTransform(g *Grid2[bool]) *Grid2[float32] {
+	nx, ny := g.Dims[0], g.Dims[1]
+	out := NewGrid2[float32](g.Origin, g.CellSize, nx, ny)
+
+	// Squared distances in cells, using the algorithm of Felzenszwalb and Huttenlocher: a one
+	// dimensional transform down each column and then along each row
+	sq := make([]float64, len(g.Cells))
+	for i, set := range g.Cells {
+		if !set {
+			sq[i] = math.Inf(1)
+		}
+	}
+	n := nx
+	if ny > n {
+		n = ny
+	}
+	f := make([]float64, n)
+	d := make([]float64, n)
+	v := make([]int, n)
+	z := make([]float64, n+1)
+
+	for x := 0; x < nx; x++ {
+		for y := 0; y < ny; y++ {
+			f[y] = sq[x+nx*y]
+		}
+		distanceTransform1D(f[:ny], d[:ny], v, z)
+		for y := 0; y < ny; y++ {
+			sq[x+nx*y] = d[y]
+		}
+	}
+	for y := 0; y < ny; y++ {
+		row := sq[nx*y : nx*(y+1)]
+		copy(f, row)
+		distanceTransform1D(f[:nx], d[:nx], v, z)
+		for x, dd := range d[:nx] {
+			out.Cells[x+nx*y] = float32(math.Sqrt(dd)) * g.CellSize
+		}
+	}
+	return out
+}
+
+// distanceTransform1D sets d[q] to the minimum over p of (q-p)² + f[p], which is the lower
+// envelope of parabolas rooted at each p. Infinite values of f contribute no parabola. v and z
+// are scratch space of at least len(f) and len(f)+1 elements.
+func distanceTransform1D(f, d []float64, v []int, z []float64) {
+	k := -1
+	for q := range f {
+		if math.IsInf(f[q], 1) {
+			continue
+		}
+		fq := f[q] + float64(q*q)
+		for k >= 0 {
+			p := v[k]
+			// Where the parabola from q overtakes the one from p
+			s := (fq - (f[p] + float64(p*p))) / float64(2*(q-p))
+			if s > z[k] {
+				break
+			}
+			k--
+		}
+		k++
+		v[k] = q
+		if k == 0 {
+			z[k] = math.Inf(-1)
+		} else {
+			p := v[k-1]
+			z[k] = (fq - (f[p] + float64(p*p))) / float64(2*(q-p))
+		}
+		z[k+1] = math.Inf(1)
+	}
+
+	if k < 0 {
+		for q := range d {
+			d[q] = math.Inf(1)
+		}
+		return
+	}
+	j := 0
+	for q := range d {
+		for z[j+1] < float64(q) {
+			j++
+		}
+		p := v[j]
+		d[q] = float64((q-p)*(q-p)) + f[p]
+	}
+}
+
+// Connectivity selects which cells of a grid are neighbours.
+type Connectivity int
+
+const (
+	// Connect4 joins each cell to the four cells that share an edge with it.
+	Connect4 Connectivity = 4
+
+	// Connect8 joins each cell to the eight cells that share an edge or a corner with it.
+	Connect8 Connectivity = 8
+)
+
+var gridNeighbours = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+
+// neighbours returns the offsets to the neighbours of a cell.
+func (c Connectivity) neighbours() [][2]int {
+	if c == Connect8 {
+		return gridNeighbours[:]
+	}
+	return gridNeighbours[:4]
+}
+
+// FloodFill sets every cell that is connected to start through cells holding the same value as
+// start to fill, as the paint bucket of an image editor does. It returns the number of cells
+// changed, which is zero when start is outside the grid or already holds fill.
+func FloodFill[T comparable](g *Grid2[T], start Point2i, fill T, conn Connectivity) int {
+	sx, sy := int(start[0]), int(start[1])
+	if !g.InBounds(sx, sy) {
+		return 0
+	}
+	old := g.At(sx, sy)
+	if old == fill {
+		return 0
+	}
+
+	n := 0
+	stack := []int{g.Index(sx, sy)}
+	g.Cells[stack[0]] = fill
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n++
+		x, y := i%g.Dims[0], i/g.Dims[0]
+		for _, o := range conn.neighbours() {
+			nx, ny := x+o[0], y+o[1]
+			if !g.InBounds(nx, ny) {
+				continue
+			}
+			if j := g.Index(nx, ny); g.Cells[j] == old {
+				g.Cells[j] = fill
+				stack = append(stack, j)
+			}
+		}
+	}
+	return n
+}
+
+// GridRegion describes a set of connected cells of a grid that hold the same value.
+type GridRegion struct {
+	// Start is the first cell of the region in the order cells are stored, which can be used
+	// to look up the value held by the region or as the start of a FloodFill.
+	Start Point2i
+
+	// Min and Max are the lowest and highest cell coordinates in the region, inclusive. They
+	// are held as corners because a Recti cannot represent an odd number of cells exactly.
+	Min, Max Point2i
+
+	// Cells is the number of cells in the region.
+	Cells int
+}
+
+// LabelRegions divides the grid into regions of connected cells holding the same value, such
+// as the rooms and walls of a tile map. It returns a grid of the same shape holding the index
+// of the region each cell belongs to, and the regions in the order of their first cell.
+func LabelRegions[T comparable](g *Grid2[T], conn Connectivity) (*Grid2[int32], []GridRegion) {
+	labels := NewGrid2[int32](g.Origin, g.CellSize, g.Dims[0], g.Dims[1])
+	for i := range labels.Cells {
+		labels.Cells[i] = -1
+	}
+
+	var regions []GridRegion
+	var stack []int
+	for i := range g.Cells {
+		if labels.Cells[i] >= 0 {
+			continue
+		}
+		label := int32(len(regions))
+		v := g.Cells[i]
+		p := Point2i{int32(i % g.Dims[0]), int32(i / g.Dims[0])}
+		r := GridRegion{Start: p, Min: p, Max: p}
+
+		labels.Cells[i] = label
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			r.Cells++
+			x, y := j%g.Dims[0], j/g.Dims[0]
+			r.Min = Point2i{mini(r.Min[0], int32(x)), mini(r.Min[1], int32(y))}
+			r.Max = Point2i{maxi(r.Max[0], int32(x)), maxi(r.Max[1], int32(y))}
+			for _, o := range conn.neighbours() {
+				nx, ny := x+o[0], y+o[1]
+				if !g.InBounds(nx, ny) {
+					continue
+				}
+				if k := g.Index(nx, ny); labels.Cells[k] < 0 && g.Cells[k] == v {
+					labels.Cells[k] = label
+					stack = append(stack, k)
+				}
+			}
+		}
+		regions = append(regions, r)
+	}
+	return labels, regions
+}
@@ -0,0 +1,187 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGrid2(t *testing.T) {
+	g := NewGrid2[int](Point2{-1, 0}, 0.5, 4, 3)
+	if g.Len() != 12 {
+		t.Fatalf("got %d cells, wanted 12", g.Len())
+	}
+	g.Set(3, 2, 7)
+	if g.Cells[len(g.Cells)-1] != 7 || g.At(3, 2) != 7 {
+		t.Errorf("Set did not store the last cell")
+	}
+	if x, y := g.Cell(Point2{0.9, 1.2}); x != 3 || y != 2 {
+		t.Errorf("Cell: got %d,%d, wanted 3,2", x, y)
+	}
+	if x, y := g.Cell(Point2{-1.1, 0}); g.InBounds(x, y) {
+		t.Errorf("Cell outside grid reported in bounds: %d,%d", x, y)
+	}
+	if c := g.CellCentre(0, 0); c != (Point2{-0.75, 0.25}) {
+		t.Errorf("CellCentre: got %v", c)
+	}
+	if b := g.Bounds(); b.Min() != (Point2{-1, 0}) || b.Max() != (Point2{1, 1.5}) {
+		t.Errorf("Bounds: got %v", b)
+	}
+}
+
+func TestDistanceTransform(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		g := NewGrid2[bool](Point2{}, 2, 5, 4)
+		g.Set(1, 1, true)
+		d := DistanceTransform(g)
+		testCases := []struct {
+			x, y int
+			want float32
+		}{
+			{1, 1, 0},
+			{2, 1, 2},
+			{0, 0, 2 * math.Sqrt2},
+			{4, 3, 2 * float32(math.Sqrt(13))},
+		}
+		for _, tc := range testCases {
+			if got := d.At(tc.x, tc.y); abs(got-tc.want) > 1e-5 {
+				t.Errorf("%d,%d: got %v, wanted %v", tc.x, tc.y, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		d := DistanceTransform(NewGrid2[bool](Point2{}, 1, 3, 3))
+		for i, v := range d.Cells {
+			if !math.IsInf(float64(v), 1) {
+				t.Fatalf("cell %d: got %v, wanted +Inf", i, v)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		g := NewGrid2[bool](Point2{}, 1, 37, 23)
+		var set [][2]int
+		for y := 0; y < g.Dims[1]; y++ {
+			for x := 0; x < g.Dims[0]; x++ {
+				if r.Intn(40) == 0 {
+					g.Set(x, y, true)
+					set = append(set, [2]int{x, y})
+				}
+			}
+		}
+		d := DistanceTransform(g)
+		for y := 0; y < g.Dims[1]; y++ {
+			for x := 0; x < g.Dims[0]; x++ {
+				want := math.Inf(1)
+				for _, s := range set {
+					dx, dy := float64(x-s[0]), float64(y-s[1])
+					want = math.Min(want, math.Sqrt(dx*dx+dy*dy))
+				}
+				if got := d.At(x, y); math.Abs(float64(got)-want) > 1e-5 {
+					t.Fatalf("%d,%d: got %v, wanted %v", x, y, got, want)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkDistanceTransform(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	g := NewGrid2[bool](Point2{}, 1, 512, 512)
+	for i := range g.Cells {
+		g.Cells[i] = r.Intn(100) == 0
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DistanceTransform(g)
+	}
+}
+
+// parseGrid2 returns a grid holding the characters of rows, with the first row at y = 0.
+func parseGrid2(rows ...string) *Grid2[byte] {
+	g := NewGrid2[byte](Point2{}, 1, len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			g.Set(x, y, row[x])
+		}
+	}
+	return g
+}
+
+func TestFloodFill(t *testing.T) {
+	rows := []string{
+		"..#..",
+		"..#..",
+		"###..",
+		"...#.",
+	}
+	testCases := []struct {
+		name  string
+		start Point2i
+		fill  byte
+		conn  Connectivity
+		want  int
+	}{
+		{name: "enclosed corner", start: Point2i{0, 0}, fill: 'o', conn: Connect4, want: 4},
+		{name: "open side", start: Point2i{4, 0}, fill: 'o', conn: Connect4, want: 7},
+		{name: "diagonal walls", start: Point2i{2, 0}, fill: 'o', conn: Connect8, want: 6},
+		{name: "same value", start: Point2i{0, 0}, fill: '.', conn: Connect4, want: 0},
+		{name: "outside", start: Point2i{5, 0}, fill: 'o', conn: Connect4, want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := parseGrid2(rows...)
+			if got := FloodFill(g, tc.start, tc.fill, tc.conn); got != tc.want {
+				t.Errorf("got %d cells, wanted %d", got, tc.want)
+			}
+			n := 0
+			for _, c := range g.Cells {
+				if c == tc.fill {
+					n++
+				}
+			}
+			if tc.want > 0 && n != tc.want {
+				t.Errorf("grid holds %d filled cells, wanted %d", n, tc.want)
+			}
+		})
+	}
+}
+
+func TestLabelRegions(t *testing.T) {
+	g := parseGrid2(
+		"..#..",
+		"..#..",
+		"###..",
+		"...#.",
+	)
+
+	labels, regions := LabelRegions(g, Connect4)
+	want := []GridRegion{
+		{Start: Point2i{0, 0}, Min: Point2i{0, 0}, Max: Point2i{1, 1}, Cells: 4},
+		{Start: Point2i{2, 0}, Min: Point2i{0, 0}, Max: Point2i{2, 2}, Cells: 5},
+		{Start: Point2i{3, 0}, Min: Point2i{3, 0}, Max: Point2i{4, 3}, Cells: 7},
+		{Start: Point2i{0, 3}, Min: Point2i{0, 3}, Max: Point2i{2, 3}, Cells: 3},
+		{Start: Point2i{3, 3}, Min: Point2i{3, 3}, Max: Point2i{3, 3}, Cells: 1},
+	}
+	if len(regions) != len(want) {
+		t.Fatalf("got %d regions, wanted %d: %v", len(regions), len(want), regions)
+	}
+	for i := range want {
+		if regions[i] != want[i] {
+			t.Errorf("region %d: got %+v, wanted %+v", i, regions[i], want[i])
+		}
+	}
+	if l := labels.At(4, 3); l != 2 {
+		t.Errorf("label of 4,3: got %d, wanted 2", l)
+	}
+
+	// Diagonal neighbours join the walls, and join the bottom row to the right side
+	_, regions = LabelRegions(g, Connect8)
+	if len(regions) != 3 || regions[1].Cells != 6 || regions[2].Cells != 10 {
+		t.Errorf("Connect8: got %v", regions)
+	}
+}
@@ -0,0 +1,255 @@
+package geom
+
+import (
+	"math"
+)
+
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+// hashMix folds v into the hash h.
+func hashMix(h, v uint64) uint64 {
+	// Finalizer from splitmix64 spreads every bit of v before combining
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return (h ^ v) * hashPrime
+}
+
+// hashFloat returns the canonical bits of f after rounding it to the nearest multiple of step,
+// or of f itself when step is zero. Negative zero has the bits of zero and every NaN has the same
+// bits, so values that compare equal hash equally.
+func hashFloat(f, step float32) uint64 {
+	v := float64(f)
+	if step > 0 {
+		v = math.Round(v / float64(step))
+	}
+	switch {
+	case v != v:
+		return 0x7ff8000000000001
+	case v == 0:
+		// Folds negative zero into zero
+		return 0
+	}
+	return math.Float64bits(v)
+}
+
+// hashFloats folds the canonical bits of each value, quantized to step, into the hash h.
+func hashFloats(h uint64, step float32, v ...float32) uint64 {
+	for _, f := range v {
+		h = hashMix(h, hashFloat(f, step))
+	}
+	return h
+}
+
+// HashPoint2 returns a hash of the point, quantized to step. See HashPoint3.
+func HashPoint2(p Point2, step float32) uint64 {
+	return hashFloats(hashOffset, step, p[:]...)
+}
+
+// HashPoint3 returns a hash of the point, quantized to step. It is suitable for keying maps
+// used to deduplicate vertices.
+//
+// When step is greater than zero each component is rounded to the nearest multiple of step
+// before hashing, so points that differ by less than the rounding hash the same. Points that
+// straddle the midpoint between two multiples still hash differently however close they are, so
+// a lookup that must find every neighbour within step should also probe the adjacent cells. A
+// step of zero hashes the exact values. The other hash functions quantize in the same way.
+func HashPoint3(p Point3, step float32) uint64 {
+	return hashFloats(hashOffset, step, p[:]...)
+}
+
+// compareFloat orders a and b, treating negative zero as equal to zero and placing NaN before
+// every other value.
+func compareFloat(a, b float32) int {
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ComparePoint2 returns -1, 0 or +1 depending on whether a sorts before, equal to or after b.
+// Points are ordered by x, then by y. The order is total: negative zero equals zero and NaN
+// sorts before every number.
+func ComparePoint2(a, b Point2) int {
+	if c := compareFloat(a[0], b[0]); c != 0 {
+		return c
+	}
+	return compareFloat(a[1], b[1])
+}
+
+// ComparePoint3 returns -1, 0 or +1 depending on whether a sorts before, equal to or after b.
+// Points are ordered by x, then by y, then by z. The order is total: negative zero equals zero
+// and NaN sorts before every number.
+func ComparePoint3(a, b Point3) int {
+	for i := 0; i < 3; i++ {
+		if c := compareFloat(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// quantizePoints appends the canonical bits of every component of each point, after quantizing
+// to step, to dst. Shapes whose hash must not depend on the order their points are named in
+// choose that order from these bits rather than the raw values, so that shapes which quantize
+// equally also hash equally.
+func quantizePoints(dst []uint64, step float32, pts ...Vec3) []uint64 {
+	for _, p := range pts {
+		for _, f := range p {
+			dst = append(dst, hashFloat(f, step))
+		}
+	}
+	return dst
+}
+
+// minRotation returns the offset into keys of the rotation of its n equally sized groups that
+// sorts first.
+func minRotation(keys []uint64, n int) int {
+	size := len(keys) / n
+	best := 0
+	for r := size; r < len(keys); r += size {
+		for i := range keys {
+			a, b := keys[(r+i)%len(keys)], keys[(best+i)%len(keys)]
+			if a != b {
+				if a < b {
+					best = r
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// hashRotated folds keys into h starting from the offset start and wrapping around.
+func hashRotated(h uint64, keys []uint64, start int) uint64 {
+	for i := range keys {
+		h = hashMix(h, keys[(start+i)%len(keys)])
+	}
+	return h
+}
+
+// Each shape mixes in a distinct tag first so that shapes of different types built from the
+// same numbers do not collide.
+const (
+	hashTagRect uint64 = iota + 1
+	hashTagCircle
+	hashTagTri2
+	hashTagAABB
+	hashTagOBB
+	hashTagSphere
+	hashTagCapsule
+	hashTagTri3
+)
+
+// Hash64 returns a hash of the rectangle, quantized to step.
+func (r Rect) Hash64(step float32) uint64 {
+	h := hashMix(hashOffset, hashTagRect)
+	h = hashFloats(h, step, r.Position[:]...)
+	return hashFloats(h, step, r.Size[:]...)
+}
+
+// Hash64 returns a hash of the circle, quantized to step.
+func (c Circle) Hash64(step float32) uint64 {
+	h := hashMix(hashOffset, hashTagCircle)
+	h = hashFloats(h, step, c.Centre[:]...)
+	return hashFloats(h, step, c.Radius)
+}
+
+// Hash64 returns a hash of the triangle, quantized to step. Triangles with the same corners in
+// the same winding hash equally whichever corner is named first.
+func (t Tri2) Hash64(step float32) uint64 {
+	// Start from the rotation of the quantized corners that sorts first
+	var keys [6]uint64
+	for i, p := range [...]Point2{t.A, t.B, t.C} {
+		keys[2*i], keys[2*i+1] = hashFloat(p[0], step), hashFloat(p[1], step)
+	}
+	h := hashMix(hashOffset, hashTagTri2)
+	return hashRotated(h, keys[:], minRotation(keys[:], 3))
+}
+
+// Hash64 returns a hash of the box, quantized to step.
+func (a *AABB) Hash64(step float32) uint64 {
+	h := hashMix(hashOffset, hashTagAABB)
+	h = hashFloats(h, step, a.Position[:]...)
+	return hashFloats(h, step, a.Size[:]...)
+}
+
+// Hash64 returns a hash of the box, quantized to step. The orientation is quantized so that
+// rotations which move the corners of the box by less than about step hash the same. The
+// orientations q and -q describe the same rotation and hash equally.
+func (o *OBB) Hash64(step float32) uint64 {
+	q := [4]float32{o.Orientation.W, o.Orientation.V[0], o.Orientation.V[1], o.Orientation.V[2]}
+	var qstep float32
+	if step > 0 {
+		// A change of d in the components rotates the box by about 2d, which moves its corners
+		// by 2d times their distance from the centre
+		if r := o.Size.Len(); r > 0 {
+			qstep = step / (2 * r)
+		} else {
+			// A box with no size looks the same in every orientation
+			q = [4]float32{1, 0, 0, 0}
+		}
+	}
+
+	// Choose between q and -q by the sign of the first quantized component that is not zero
+	for _, f := range q {
+		if qstep > 0 {
+			f = float32(math.Round(float64(f / qstep)))
+		}
+		if f != 0 {
+			if f < 0 {
+				q = [4]float32{-q[0], -q[1], -q[2], -q[3]}
+			}
+			break
+		}
+	}
+
+	h := hashMix(hashOffset, hashTagOBB)
+	h = hashFloats(h, step, o.Position[:]...)
+	h = hashFloats(h, step, o.Size[:]...)
+	return hashFloats(h, qstep, q[:]...)
+}
+
+// Hash64 returns a hash of the sphere, quantized to step.
+func (s *Sphere) Hash64(step float32) uint64 {
+	h := hashMix(hashOffset, hashTagSphere)
+	h = hashFloats(h, step, s.Position[:]...)
+	return hashFloats(h, step, s.Radius)
+}
+
+// Hash64 returns a hash of the capsule, quantized to step. Swapping the ends of a capsule does
+// not change its hash.
+func (c *Capsule) Hash64(step float32) uint64 {
+	// Start from the quantized end that sorts first
+	var buf [6]uint64
+	keys := quantizePoints(buf[:0], step, c.Start, c.End)
+	h := hashMix(hashOffset, hashTagCapsule)
+	h = hashRotated(h, keys, minRotation(keys, 2))
+	return hashFloats(h, step, c.Radius)
+}
+
+// Hash64 returns a hash of the triangle, quantized to step. Triangles with the same corners in
+// the same winding hash equally whichever corner is named first.
+func (t Tri3) Hash64(step float32) uint64 {
+	// Start from the rotation of the quantized corners that sorts first
+	var buf [9]uint64
+	keys := quantizePoints(buf[:0], step, t.A, t.B, t.C)
+	h := hashMix(hashOffset, hashTagTri3)
+	return hashRotated(h, keys, minRotation(keys, 3))
+}
@@ -0,0 +1,233 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestHashPoint3(t *testing.T) {
+	nan := float32(math.NaN())
+	negZero := float32(math.Copysign(0, -1))
+
+	testCases := []struct {
+		name  string
+		a, b  Point3
+		step  float32
+		equal bool
+	}{
+		{name: "same", a: Point3{1, 2, 3}, b: Point3{1, 2, 3}, equal: true},
+		{name: "different", a: Point3{1, 2, 3}, b: Point3{1, 2, 3.0001}},
+		{name: "swapped", a: Point3{1, 2, 3}, b: Point3{2, 1, 3}},
+		{name: "negative zero", a: Point3{0, negZero, 0}, b: Point3{0, 0, 0}, equal: true},
+		{name: "nan", a: Point3{nan, 0, 0}, b: Point3{float32(math.Float32frombits(0x7fc00123)), 0, 0}, equal: true},
+		{name: "quantized", a: Point3{1, 2, 3}, b: Point3{1.004, 1.996, 3}, step: 0.01, equal: true},
+		{name: "quantized apart", a: Point3{1, 2, 3}, b: Point3{1.02, 2, 3}, step: 0.01},
+		{name: "quantized negative zero", a: Point3{-0.001, 0, 0}, b: Point3{0.001, 0, 0}, step: 0.01, equal: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ha, hb := HashPoint3(tc.a, tc.step), HashPoint3(tc.b, tc.step)
+			if (ha == hb) != tc.equal {
+				t.Errorf("got hashes %x and %x, wanted equal=%v", ha, hb, tc.equal)
+			}
+		})
+	}
+}
+
+func TestHashPointDistribution(t *testing.T) {
+	// Every point of a small integer grid should hash differently
+	seen := make(map[uint64]Point3)
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 32; y++ {
+			for z := 0; z < 32; z++ {
+				p := Point3{float32(x), float32(y), float32(z)}
+				h := HashPoint3(p, 0)
+				if q, ok := seen[h]; ok {
+					t.Fatalf("%v and %v have the same hash %x", p, q, h)
+				}
+				seen[h] = p
+			}
+		}
+	}
+	if HashPoint2(Point2{1, 2}, 0) == HashPoint2(Point2{2, 1}, 0) {
+		t.Errorf("HashPoint2 is symmetric in its components")
+	}
+}
+
+func TestComparePoint3(t *testing.T) {
+	nan := float32(math.NaN())
+	negZero := float32(math.Copysign(0, -1))
+
+	testCases := []struct {
+		name string
+		a, b Point3
+		want int
+	}{
+		{name: "equal", a: Point3{1, 2, 3}, b: Point3{1, 2, 3}, want: 0},
+		{name: "x first", a: Point3{0, 9, 9}, b: Point3{1, 0, 0}, want: -1},
+		{name: "then y", a: Point3{1, 2, 0}, b: Point3{1, 1, 9}, want: 1},
+		{name: "then z", a: Point3{1, 1, 1}, b: Point3{1, 1, 2}, want: -1},
+		{name: "negative zero", a: Point3{negZero, 0, 0}, b: Point3{0, 0, 0}, want: 0},
+		{name: "nan first", a: Point3{nan, 0, 0}, b: Point3{float32(math.Inf(-1)), 0, 0}, want: -1},
+		{name: "nan equal", a: Point3{nan, 1, 0}, b: Point3{nan, 1, 0}, want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ComparePoint3(tc.a, tc.b); got != tc.want {
+				t.Errorf("got %d, wanted %d", got, tc.want)
+			}
+			if got := ComparePoint3(tc.b, tc.a); got != -tc.want {
+				t.Errorf("reversed: got %d, wanted %d", got, -tc.want)
+			}
+			a2, b2 := Point2{tc.a[0], tc.a[1]}, Point2{tc.b[0], tc.b[1]}
+			if want := ComparePoint3(Point3{a2[0], a2[1]}, Point3{b2[0], b2[1]}); ComparePoint2(a2, b2) != want {
+				t.Errorf("ComparePoint2: got %d, wanted %d", ComparePoint2(a2, b2), want)
+			}
+		})
+	}
+}
+
+func TestComparePoint3Sort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pts := make([]Point3, 200)
+	for i := range pts {
+		// A coarse grid so that many points share components
+		pts[i] = Point3{float32(r.Intn(4)), float32(r.Intn(4)), float32(r.Intn(4))}
+	}
+	pts[0][1] = float32(math.NaN())
+	sort.Slice(pts, func(i, j int) bool { return ComparePoint3(pts[i], pts[j]) < 0 })
+	for i := 1; i < len(pts); i++ {
+		if ComparePoint3(pts[i-1], pts[i]) > 0 {
+			t.Fatalf("points %d and %d are out of order: %v, %v", i-1, i, pts[i-1], pts[i])
+		}
+	}
+}
+
+func TestShapeHash64(t *testing.T) {
+	q := mgl32.QuatRotate(0.7, Vec3{1, 2, 3}.Normalize())
+	tri := Tri3{Point3{0, 0, 0}, Point3{1, 0, 0}, Point3{0, 1, 0}}
+
+	testCases := []struct {
+		name  string
+		a, b  uint64
+		equal bool
+	}{
+		{
+			name:  "aabb",
+			a:     (&AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 1, 1}}).Hash64(0),
+			b:     (&AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 1, 1}}).Hash64(0),
+			equal: true,
+		},
+		{
+			name:  "aabb quantized",
+			a:     (&AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 1, 1}}).Hash64(0.1),
+			b:     (&AABB{Position: Point3{1.01, 2, 3}, Size: Vec3{1, 0.99, 1}}).Hash64(0.1),
+			equal: true,
+		},
+		{
+			name: "aabb and sphere",
+			a:    (&AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 0, 0}}).Hash64(0),
+			b:    (&Sphere{Position: Point3{1, 2, 3}, Radius: 1}).Hash64(0),
+		},
+		{
+			name:  "obb negated orientation",
+			a:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: q}).Hash64(0),
+			b:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: q.Scale(-1)}).Hash64(0),
+			equal: true,
+		},
+		{
+			name: "obb different orientation",
+			a:    (&OBB{Size: Vec3{1, 2, 3}, Orientation: q}).Hash64(0),
+			b:    (&OBB{Size: Vec3{1, 2, 3}, Orientation: q.Inverse()}).Hash64(0),
+		},
+		{
+			name:  "obb orientation quantized",
+			a:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.QuatRotate(0.3, Y3)}).Hash64(0.5),
+			b:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.QuatRotate(0.301, Y3)}).Hash64(0.5),
+			equal: true,
+		},
+		{
+			name: "obb orientation beyond step",
+			a:    (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.QuatRotate(0.3, Y3)}).Hash64(0.5),
+			b:    (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.QuatRotate(0.6, Y3)}).Hash64(0.5),
+		},
+		{
+			name:  "obb half turn either way quantized",
+			a:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.Quat{W: 0.01, V: Vec3{0, 1, 0}}}).Hash64(0.5),
+			b:     (&OBB{Size: Vec3{1, 2, 3}, Orientation: mgl32.Quat{W: 0.01, V: Vec3{0, -1, 0}}}).Hash64(0.5),
+			equal: true,
+		},
+		{
+			name:  "obb with no size",
+			a:     (&OBB{Position: Point3{1, 2, 3}, Orientation: q}).Hash64(0.5),
+			b:     (&OBB{Position: Point3{1, 2, 3}, Orientation: mgl32.QuatIdent()}).Hash64(0.5),
+			equal: true,
+		},
+		{
+			name:  "capsule swapped ends",
+			a:     (&Capsule{Start: Point3{0, 0, 0}, End: Point3{0, 5, 0}, Radius: 1}).Hash64(0),
+			b:     (&Capsule{Start: Point3{0, 5, 0}, End: Point3{0, 0, 0}, Radius: 1}).Hash64(0),
+			equal: true,
+		},
+		{
+			name:  "capsule ends ordered after quantizing",
+			a:     (&Capsule{Start: Point3{0.1, 0, 0}, End: Point3{-0.1, 5, 0}, Radius: 1}).Hash64(1),
+			b:     (&Capsule{Start: Point3{-0.1, 0, 0}, End: Point3{0.1, 5, 0}, Radius: 1}).Hash64(1),
+			equal: true,
+		},
+		{
+			name:  "tri3 rotated",
+			a:     tri.Hash64(0),
+			b:     Tri3{tri.C, tri.A, tri.B}.Hash64(0),
+			equal: true,
+		},
+		{
+			name: "tri3 reversed",
+			a:    tri.Hash64(0),
+			b:    Tri3{tri.A, tri.C, tri.B}.Hash64(0),
+		},
+		{
+			name:  "tri3 corners ordered after quantizing",
+			a:     Tri3{Point3{0.1, 0, 0}, Point3{-0.1, 0, 5}, Point3{0, 5, 0}}.Hash64(1),
+			b:     Tri3{Point3{-0.1, 0, 0}, Point3{0.1, 0, 5}, Point3{0, 5, 0}}.Hash64(1),
+			equal: true,
+		},
+		{
+			name:  "tri2 rotated",
+			a:     Tri2{Point2{0, 0}, Point2{1, 0}, Point2{0, 1}}.Hash64(0),
+			b:     Tri2{Point2{1, 0}, Point2{0, 1}, Point2{0, 0}}.Hash64(0),
+			equal: true,
+		},
+		{
+			name:  "tri2 corners ordered after quantizing",
+			a:     Tri2{Point2{0.1, 0}, Point2{-0.1, 5}, Point2{5, 0}}.Hash64(1),
+			b:     Tri2{Point2{-0.1, 0}, Point2{0.1, 5}, Point2{5, 0}}.Hash64(1),
+			equal: true,
+		},
+		{
+			name:  "circle",
+			a:     Circle{Centre: Point2{1, 1}, Radius: 2}.Hash64(0),
+			b:     Circle{Centre: Point2{1, 1}, Radius: 2}.Hash64(0),
+			equal: true,
+		},
+		{
+			name: "rect and circle",
+			a:    Rect{Position: Point2{1, 1}, Size: Vec2{2, 0}}.Hash64(0),
+			b:    Circle{Centre: Point2{1, 1}, Radius: 2}.Hash64(0),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if (tc.a == tc.b) != tc.equal {
+				t.Errorf("got hashes %x and %x, wanted equal=%v", tc.a, tc.b, tc.equal)
+			}
+		})
+	}
+}
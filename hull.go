@@ -0,0 +1,293 @@
+package geom
+
+import "math"
+
+// ConvexHull3 returns the smallest convex polyhedron that contains all of the points, as a
+// closed mesh whose triangles wind counter clockwise when seen from outside. The mesh holds only
+// the points that are corners of the hull. Points that lie on the faces or edges of the hull are
+// omitted.
+//
+// Points that all lie in one plane give a flat hull whose polygon is covered by triangles
+// facing both ways. Points that all lie on one line give a mesh with no triangles whose
+// positions are the two ends of the line.
+func ConvexHull3(pts []Point3) *TriMesh {
+	// Quickhull: start from a tetrahedron of extreme points and repeatedly extend the hull to
+	// take in the point furthest outside one of its faces
+	h := &hull3{pts: make([][3]float64, len(pts)), edges: make(map[[2]int]int)}
+	extent := 1.0
+	for i, p := range pts {
+		h.pts[i] = [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+		for _, c := range p {
+			extent = math.Max(extent, math.Abs(float64(c)))
+		}
+	}
+	// The points are float32 so differences smaller than this are rounding noise
+	h.eps = 1e-6 * extent
+
+	simplex, dims := h.simplex()
+	switch dims {
+	case 0:
+		if len(pts) == 0 {
+			return &TriMesh{}
+		}
+		return &TriMesh{Positions: []Point3{pts[0]}}
+	case 1:
+		return &TriMesh{Positions: []Point3{pts[simplex[0]], pts[simplex[1]]}}
+	case 2:
+		return flatHull3(pts, simplex)
+	}
+
+	h.start(simplex)
+	for i := 0; i < len(h.faces); i++ {
+		if !h.faces[i].dead && len(h.faces[i].outside) > 0 {
+			h.extend(i)
+		}
+	}
+	return h.mesh(pts)
+}
+
+// hull3 is the state of a quickhull construction.
+type hull3 struct {
+	pts   [][3]float64
+	eps   float64
+	faces []hullFace
+	edges map[[2]int]int // the live face to the left of each directed edge
+}
+
+// hullFace is a triangle of a hull under construction with its outward plane and the points
+// that lie outside it.
+type hullFace struct {
+	v       [3]int
+	n       [3]float64 // unit normal
+	d       float64    // distance of the plane from the origin along n
+	outside []int
+	dead    bool
+}
+
+func (h *hull3) dist(f *hullFace, p int) float64 {
+	return dot64(f.n, h.pts[p]) - f.d
+}
+
+// simplex finds up to four extreme points and reports how many dimensions they span.
+func (h *hull3) simplex() ([4]int, int) {
+	var s [4]int
+	if len(h.pts) == 0 {
+		return s, 0
+	}
+
+	// The pair of axis extremes that are furthest apart
+	var ext [6]int
+	for i, p := range h.pts {
+		for k := 0; k < 3; k++ {
+			if p[k] < h.pts[ext[2*k]][k] {
+				ext[2*k] = i
+			}
+			if p[k] > h.pts[ext[2*k+1]][k] {
+				ext[2*k+1] = i
+			}
+		}
+	}
+	best := -1.0
+	for _, i := range ext {
+		for _, j := range ext {
+			if d := h.lenSq(sub64(h.pts[i], h.pts[j])); d > best {
+				best, s[0], s[1] = d, i, j
+			}
+		}
+	}
+	if math.Sqrt(best) <= h.eps {
+		return s, 0
+	}
+
+	// The point furthest from the line through them
+	dir := sub64(h.pts[s[1]], h.pts[s[0]])
+	best = 0
+	for i, p := range h.pts {
+		if d := h.lenSq(cross64(dir, sub64(p, h.pts[s[0]]))); d > best {
+			best, s[2] = d, i
+		}
+	}
+	if math.Sqrt(best/h.lenSq(dir)) <= h.eps {
+		return s, 1
+	}
+
+	// The point furthest from the plane through all three
+	n := cross64(dir, sub64(h.pts[s[2]], h.pts[s[0]]))
+	nl := math.Sqrt(h.lenSq(n))
+	best = 0
+	for i, p := range h.pts {
+		if d := math.Abs(dot64(n, sub64(p, h.pts[s[0]]))) / nl; d > best {
+			best, s[3] = d, i
+		}
+	}
+	if best <= h.eps {
+		return s, 2
+	}
+	return s, 3
+}
+
+func (h *hull3) lenSq(v [3]float64) float64 {
+	return dot64(v, v)
+}
+
+// start builds the faces of the tetrahedron s and shares the points out among them.
+func (h *hull3) start(s [4]int) {
+	a, b, c, d := s[0], s[1], s[2], s[3]
+	// Wind the base so that the fourth point is behind it
+	n := cross64(sub64(h.pts[b], h.pts[a]), sub64(h.pts[c], h.pts[a]))
+	if dot64(n, sub64(h.pts[d], h.pts[a])) > 0 {
+		b, c = c, b
+	}
+	for _, v := range [][3]int{{a, b, c}, {a, d, b}, {b, d, c}, {c, d, a}} {
+		h.addFace(v)
+	}
+	all := make([]int, 0, len(h.pts))
+	for i := range h.pts {
+		if i != a && i != b && i != c && i != d {
+			all = append(all, i)
+		}
+	}
+	h.assign(all, 0)
+}
+
+// addFace adds the triangle v to the hull.
+func (h *hull3) addFace(v [3]int) {
+	n := cross64(sub64(h.pts[v[1]], h.pts[v[0]]), sub64(h.pts[v[2]], h.pts[v[0]]))
+	if l := math.Sqrt(h.lenSq(n)); l > 0 {
+		n = [3]float64{n[0] / l, n[1] / l, n[2] / l}
+	}
+	fi := len(h.faces)
+	h.faces = append(h.faces, hullFace{v: v, n: n, d: dot64(n, h.pts[v[0]])})
+	for k := 0; k < 3; k++ {
+		h.edges[[2]int{v[k], v[(k+1)%3]}] = fi
+	}
+}
+
+// assign gives each point to the first face from index first onwards that it lies outside.
+// Points inside every face are inside the hull and are dropped.
+func (h *hull3) assign(pts []int, first int) {
+	for _, p := range pts {
+		for fi := first; fi < len(h.faces); fi++ {
+			f := &h.faces[fi]
+			if !f.dead && h.dist(f, p) > h.eps {
+				f.outside = append(f.outside, p)
+				break
+			}
+		}
+	}
+}
+
+// extend grows the hull to include the point furthest outside face fi.
+func (h *hull3) extend(fi int) {
+	f := &h.faces[fi]
+	eye, far := -1, 0.0
+	for _, p := range f.outside {
+		if d := h.dist(f, p); d > far {
+			eye, far = p, d
+		}
+	}
+
+	// The faces the eye point can see form a connected patch. The edges around the patch, where
+	// a visible face meets a hidden one, form the horizon. Faces whose plane passes through the
+	// eye count as visible so that they are replaced along with the rest, which drops corners
+	// that would otherwise be left in the middle of a flat face.
+	visible := map[int]bool{fi: true}
+	patch := []int{fi}
+	stack := []int{fi}
+	var horizon [][2]int
+	var orphans []int
+	for len(stack) > 0 {
+		vi := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		v := h.faces[vi].v
+		for k := 0; k < 3; k++ {
+			e := [2]int{v[k], v[(k+1)%3]}
+			ni := h.edges[[2]int{e[1], e[0]}]
+			if visible[ni] {
+				continue
+			}
+			if h.dist(&h.faces[ni], eye) >= -h.eps {
+				visible[ni] = true
+				patch = append(patch, ni)
+				stack = append(stack, ni)
+				continue
+			}
+			horizon = append(horizon, e)
+		}
+	}
+
+	for _, vi := range patch {
+		vf := &h.faces[vi]
+		vf.dead = true
+		for _, p := range vf.outside {
+			if p != eye {
+				orphans = append(orphans, p)
+			}
+		}
+		vf.outside = nil
+		for k := 0; k < 3; k++ {
+			e := [2]int{vf.v[k], vf.v[(k+1)%3]}
+			if h.edges[e] == vi {
+				delete(h.edges, e)
+			}
+		}
+	}
+
+	// Join the horizon to the eye point. The horizon edges keep the winding of the faces they
+	// came from, so the new faces face outward.
+	first := len(h.faces)
+	for _, e := range horizon {
+		h.addFace([3]int{e[0], e[1], eye})
+	}
+	h.assign(orphans, first)
+}
+
+// mesh returns the live faces as a mesh of the corner points.
+func (h *hull3) mesh(pts []Point3) *TriMesh {
+	m := &TriMesh{}
+	index := make(map[int]uint32)
+	for _, f := range h.faces {
+		if f.dead {
+			continue
+		}
+		for _, v := range f.v {
+			i, ok := index[v]
+			if !ok {
+				i = uint32(len(m.Positions))
+				index[v] = i
+				m.Positions = append(m.Positions, pts[v])
+			}
+			m.Indices = append(m.Indices, i)
+		}
+	}
+	return m
+}
+
+// flatHull3 returns the hull of points that lie in the plane through the first three points of
+// the simplex, covered by triangles facing both ways.
+func flatHull3(pts []Point3, s [4]int) *TriMesh {
+	a := pts[s[0]]
+	n := pts[s[1]].Sub(a).Cross(pts[s[2]].Sub(a)).Normalize()
+	u := pts[s[1]].Sub(a).Normalize()
+	v := n.Cross(u)
+
+	flat := make([]Point2, len(pts))
+	orig := make(map[Point2]Point3, len(pts))
+	for i, p := range pts {
+		d := p.Sub(a)
+		flat[i] = Point2{d.Dot(u), d.Dot(v)}
+		orig[flat[i]] = p
+	}
+	poly := ConvexHull2(flat)
+
+	m := &TriMesh{Positions: make([]Point3, len(poly.Points))}
+	for i, p := range poly.Points {
+		m.Positions[i] = orig[p]
+	}
+	// The polygon is counter clockwise about n
+	for i := 2; i < len(poly.Points); i++ {
+		j, k := uint32(i-1), uint32(i)
+		m.Indices = append(m.Indices, 0, j, k, 0, k, j)
+	}
+	return m
+}
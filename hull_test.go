@@ -0,0 +1,150 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// boxCorners returns the eight corners of a cube.
+func boxCorners(c Point3, half float32) []Point3 {
+	var pts []Point3
+	for _, x := range []float32{-half, half} {
+		for _, y := range []float32{-half, half} {
+			for _, z := range []float32{-half, half} {
+				pts = append(pts, c.Add(Vec3{x, y, z}))
+			}
+		}
+	}
+	return pts
+}
+
+// meshVolume returns the volume enclosed by a closed mesh whose triangles face outward.
+func meshVolume(m *TriMesh) float32 {
+	var v float32
+	for i := 0; i < m.Len(); i++ {
+		t := m.Tri(i)
+		v += t.A.Dot(t.B.Cross(t.C)) / 6
+	}
+	return v
+}
+
+// convexMeshContains reports whether p is behind every triangle of a convex mesh.
+func convexMeshContains(m *TriMesh, p Point3) bool {
+	for i := 0; i < m.Len(); i++ {
+		t := m.Tri(i)
+		if t.Normal().Dot(p.Sub(t.A)) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkClosedMesh reports an error unless every edge of the mesh is shared by exactly two
+// triangles that use it in opposite directions.
+func checkClosedMesh(t *testing.T, m *TriMesh) {
+	t.Helper()
+	edges := map[[2]uint32]int{}
+	for i := 0; i < m.Len(); i++ {
+		for k := 0; k < 3; k++ {
+			edges[[2]uint32{m.Indices[3*i+k], m.Indices[3*i+(k+1)%3]}]++
+		}
+	}
+	for e, n := range edges {
+		if n != 1 || edges[[2]uint32{e[1], e[0]}] != 1 {
+			t.Errorf("edge %v is used %d times and its reverse %d times", e, n, edges[[2]uint32{e[1], e[0]}])
+		}
+	}
+}
+
+func TestConvexHull3(t *testing.T) {
+	// A cube with points inside it and on its faces and edges
+	pts := boxCorners(Point3{1, 2, 3}, 1)
+	pts = append(pts, Point3{1, 2, 3}, Point3{1.5, 2, 3}, Point3{2, 2, 3}, Point3{2, 3, 3}, Point3{1, 1, 4})
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		pts = append(pts, RandomPointInAABB(r, AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 1, 1}}))
+	}
+	r.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+
+	m := ConvexHull3(pts)
+	if len(m.Positions) != 8 || m.Len() != 12 {
+		t.Fatalf("got %d corners and %d triangles, wanted 8 and 12", len(m.Positions), m.Len())
+	}
+	checkClosedMesh(t, m)
+	if v := meshVolume(m); abs(v-8) > 1e-4 {
+		t.Errorf("got volume %v, wanted 8", v)
+	}
+	for _, p := range pts {
+		if !convexMeshContains(m, p) {
+			t.Errorf("point %v is outside the hull", p)
+		}
+	}
+}
+
+func TestConvexHull3Lattice(t *testing.T) {
+	// Many points share each face, edge and corner of the hull
+	var pts []Point3
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			for z := 0; z < 5; z++ {
+				p := Point3{float32(x), float32(y), float32(z)}
+				pts = append(pts, p, p)
+			}
+		}
+	}
+	rand.New(rand.NewSource(3)).Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+
+	m := ConvexHull3(pts)
+	if len(m.Positions) != 8 {
+		t.Errorf("got %d corners, wanted 8", len(m.Positions))
+	}
+	checkClosedMesh(t, m)
+	if v := meshVolume(m); abs(v-64) > 1e-3 {
+		t.Errorf("got volume %v, wanted 64", v)
+	}
+}
+
+func TestConvexHull3Random(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 20; iter++ {
+		pts := make([]Point3, 10+r.Intn(500))
+		for i := range pts {
+			pts[i] = RandomPointInSphere(r, Sphere{Position: Point3{5, -3, 2}, Radius: 4})
+		}
+		m := ConvexHull3(pts)
+		checkClosedMesh(t, m)
+		if v := meshVolume(m); v <= 0 {
+			t.Errorf("iteration %d: got volume %v", iter, v)
+		}
+		for _, p := range pts {
+			if !convexMeshContains(m, p) {
+				t.Errorf("iteration %d: point %v is outside the hull", iter, p)
+			}
+		}
+		// Every corner of a convex hull is in front of or on the plane of every triangle
+		for _, p := range m.Positions {
+			if !convexMeshContains(m, p) {
+				t.Errorf("iteration %d: hull is not convex at %v", iter, p)
+			}
+		}
+	}
+}
+
+func TestConvexHull3Degenerate(t *testing.T) {
+	flat := []Point3{{0, 0, 1}, {2, 0, 1}, {2, 2, 1}, {0, 2, 1}, {1, 1, 1}, {1, 0, 1}}
+	m := ConvexHull3(flat)
+	if len(m.Positions) != 4 || m.Len() != 4 {
+		t.Errorf("flat: got %d corners and %d triangles, wanted 4 and 4", len(m.Positions), m.Len())
+	}
+
+	line := ConvexHull3([]Point3{{0, 0, 0}, {1, 1, 1}, {3, 3, 3}, {2, 2, 2}})
+	if len(line.Positions) != 2 || line.Len() != 0 {
+		t.Errorf("line: got %v", line)
+	}
+	if p := ConvexHull3([]Point3{{1, 2, 3}, {1, 2, 3}}); len(p.Positions) != 1 || p.Len() != 0 {
+		t.Errorf("point: got %v", p)
+	}
+	if e := ConvexHull3(nil); len(e.Positions) != 0 || e.Len() != 0 {
+		t.Errorf("empty: got %v", e)
+	}
+}
@@ -0,0 +1,227 @@
+package geom
+
+// kdTree indexes a slice of points for nearest neighbour and radius queries. The tree is
+// implicit: the node for the range idx[lo:hi] is the point at idx[mid], where mid is halfway
+// along the range, and it splits the rest of the range on axis[mid]. Points before mid are no
+// greater than it on that axis and points after it are no less.
+type kdTree struct {
+	idx  []int32
+	axis []uint8
+}
+
+// kdLeafSize is the size of the ranges that are scanned directly rather than split further.
+const kdLeafSize = 8
+
+// buildKDTree returns a tree over pts.
+func buildKDTree(pts []Point3) *kdTree {
+	t := &kdTree{
+		idx:  make([]int32, len(pts)),
+		axis: make([]uint8, len(pts)),
+	}
+	for i := range t.idx {
+		t.idx[i] = int32(i)
+	}
+	t.build(pts, 0, len(pts))
+	return t
+}
+
+// Len returns the number of points in the tree, which may be nil.
+func (t *kdTree) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.idx)
+}
+
+func (t *kdTree) build(pts []Point3, lo, hi int) {
+	if hi-lo <= kdLeafSize {
+		return
+	}
+
+	// Split on the axis along which the points are most spread out
+	bmin, bmax := emptyBounds()
+	for _, i := range t.idx[lo:hi] {
+		bmin, bmax = extendBounds(bmin, bmax, pts[i], pts[i])
+	}
+	ext := bmax.Sub(bmin)
+	axis := 0
+	if ext[1] > ext[axis] {
+		axis = 1
+	}
+	if ext[2] > ext[axis] {
+		axis = 2
+	}
+
+	mid := (lo + hi) / 2
+	t.selectNth(pts, lo, hi, mid, axis)
+	t.axis[mid] = uint8(axis)
+	t.build(pts, lo, mid)
+	t.build(pts, mid+1, hi)
+}
+
+// selectNth reorders idx[lo:hi] so that the point at idx[n] is the one that would be there if
+// the range were sorted on axis, with no greater points before it and no lesser points after.
+func (t *kdTree) selectNth(pts []Point3, lo, hi, n, axis int) {
+	idx := t.idx
+	hi--
+	for hi > lo {
+		// Partition around the median of the first, middle and last values
+		m := (lo + hi) / 2
+		a, b, c := pts[idx[lo]][axis], pts[idx[m]][axis], pts[idx[hi]][axis]
+		pivot := max(min(a, b), min(max(a, b), c))
+
+		i, j := lo, hi
+		for i <= j {
+			for pts[idx[i]][axis] < pivot {
+				i++
+			}
+			for pts[idx[j]][axis] > pivot {
+				j--
+			}
+			if i <= j {
+				idx[i], idx[j] = idx[j], idx[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
+
+// nearest returns the point closest to p that is nearer than bestDist, preferring the lowest
+// index among equally close points, or best if there is none.
+func (t *kdTree) nearest(pts []Point3, p Point3, best int, bestDist float32) (int, float32) {
+	if t == nil {
+		return best, bestDist
+	}
+	var visit func(lo, hi int)
+	visit = func(lo, hi int) {
+		if hi-lo <= kdLeafSize {
+			for _, i := range t.idx[lo:hi] {
+				if d := DistanceSquared3(p, pts[i]); d < bestDist || (d == bestDist && int(i) < best) {
+					best, bestDist = int(i), d
+				}
+			}
+			return
+		}
+		mid := (lo + hi) / 2
+		i := t.idx[mid]
+		if d := DistanceSquared3(p, pts[i]); d < bestDist || (d == bestDist && int(i) < best) {
+			best, bestDist = int(i), d
+		}
+		diff := p[t.axis[mid]] - pts[i][t.axis[mid]]
+		near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+		if diff > 0 {
+			near, far = far, near
+		}
+		visit(near[0], near[1])
+		if diff*diff <= bestDist {
+			visit(far[0], far[1])
+		}
+	}
+	visit(0, len(t.idx))
+	return best, bestDist
+}
+
+// withinRadius appends the indexes of the points within r2, the squared radius, of p to dst.
+func (t *kdTree) withinRadius(dst []int, pts []Point3, p Point3, r2 float32) []int {
+	if t == nil {
+		return dst
+	}
+	var visit func(lo, hi int)
+	visit = func(lo, hi int) {
+		if hi-lo <= kdLeafSize {
+			for _, i := range t.idx[lo:hi] {
+				if DistanceSquared3(p, pts[i]) <= r2 {
+					dst = append(dst, int(i))
+				}
+			}
+			return
+		}
+		mid := (lo + hi) / 2
+		i := t.idx[mid]
+		if DistanceSquared3(p, pts[i]) <= r2 {
+			dst = append(dst, int(i))
+		}
+		diff := p[t.axis[mid]] - pts[i][t.axis[mid]]
+		if diff <= 0 || diff*diff <= r2 {
+			visit(lo, mid)
+		}
+		if diff >= 0 || diff*diff <= r2 {
+			visit(mid+1, hi)
+		}
+	}
+	visit(0, len(t.idx))
+	return dst
+}
+
+// knearest adds the points of the tree to the k nearest found so far in h.
+func (t *kdTree) knearest(pts []Point3, p Point3, h *kNearest) {
+	if t == nil {
+		return
+	}
+	var visit func(lo, hi int)
+	visit = func(lo, hi int) {
+		if hi-lo <= kdLeafSize {
+			for _, i := range t.idx[lo:hi] {
+				h.add(int(i), DistanceSquared3(p, pts[i]))
+			}
+			return
+		}
+		mid := (lo + hi) / 2
+		i := t.idx[mid]
+		h.add(int(i), DistanceSquared3(p, pts[i]))
+		diff := p[t.axis[mid]] - pts[i][t.axis[mid]]
+		near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+		if diff > 0 {
+			near, far = far, near
+		}
+		visit(near[0], near[1])
+		if diff*diff <= h.limit() {
+			visit(far[0], far[1])
+		}
+	}
+	visit(0, len(t.idx))
+}
+
+// kNearest collects the k nearest points seen so far, ordered by distance and then by index.
+type kNearest struct {
+	k     int
+	items []int
+	dists []float32
+}
+
+// limit returns the squared distance a point must be within to be one of the k nearest.
+func (h *kNearest) limit() float32 {
+	if len(h.items) < h.k {
+		return maxFloat32
+	}
+	return h.dists[len(h.dists)-1]
+}
+
+func (h *kNearest) add(i int, d float32) {
+	n := len(h.items)
+	if n == h.k && (d > h.dists[n-1] || (d == h.dists[n-1] && i > h.items[n-1])) {
+		return
+	}
+	if n < h.k {
+		h.items = append(h.items, 0)
+		h.dists = append(h.dists, 0)
+	} else {
+		n--
+	}
+	// Insert in order, dropping the furthest if the list was full
+	j := n
+	for j > 0 && (h.dists[j-1] > d || (h.dists[j-1] == d && h.items[j-1] > i)) {
+		h.items[j], h.dists[j] = h.items[j-1], h.dists[j-1]
+		j--
+	}
+	h.items[j], h.dists[j] = i, d
+}
@@ -0,0 +1,131 @@
+package geom
+
+// Common anchor and pivot points for layout functions, given as fractions of a rect's width and
+// height measured from its top left corner.
+var (
+	AnchorTopLeft     = Vec2{0, 0}
+	AnchorTop         = Vec2{0.5, 0}
+	AnchorTopRight    = Vec2{1, 0}
+	AnchorLeft        = Vec2{0, 0.5}
+	AnchorCentre      = Vec2{0.5, 0.5}
+	AnchorRight       = Vec2{1, 0.5}
+	AnchorBottomLeft  = Vec2{0, 1}
+	AnchorBottom      = Vec2{0.5, 1}
+	AnchorBottomRight = Vec2{1, 1}
+)
+
+// Margins holds the space to leave on each side of a rect.
+type Margins struct {
+	Left, Top, Right, Bottom float32
+}
+
+// UniformMargins returns margins of m on every side.
+func UniformMargins(m float32) Margins {
+	return Margins{Left: m, Top: m, Right: m, Bottom: m}
+}
+
+// FitMode controls how FitRect scales a child to fit within its parent.
+type FitMode int
+
+const (
+	// FitNone keeps the child at its original size.
+	FitNone FitMode = iota
+
+	// FitContain scales the child uniformly so it is as large as possible while lying
+	// entirely within the parent.
+	FitContain
+
+	// FitCover scales the child uniformly so it is as small as possible while covering the
+	// whole of the parent.
+	FitCover
+
+	// FitStretch scales the width and height of the child independently to match the
+	// parent exactly.
+	FitStretch
+)
+
+func (m FitMode) String() string {
+	switch m {
+	case FitNone:
+		return "none"
+	case FitContain:
+		return "contain"
+	case FitCover:
+		return "cover"
+	case FitStretch:
+		return "stretch"
+	default:
+		return "unknown"
+	}
+}
+
+// PointAt returns the point within the rect at the given fraction of its width and height,
+// measured from its top left corner.
+func (r Rect) PointAt(anchor Vec2) Point2 {
+	return Point2{
+		r.Position[0] + (anchor[0]*2-1)*r.Size[0],
+		r.Position[1] + (anchor[1]*2-1)*r.Size[1],
+	}
+}
+
+// Inset returns the rect that remains after removing the margins from each side of r. Sides
+// that cross over are collapsed to a zero width or height.
+func (r Rect) Inset(m Margins) Rect {
+	tl := r.TopLeft()
+	br := r.BottomRight()
+	tl = Point2{tl[0] + m.Left, tl[1] + m.Top}
+	br = Point2{max(tl[0], br[0]-m.Right), max(tl[1], br[1]-m.Bottom)}
+	return RectFromCorners(tl, br)
+}
+
+// AlignRect returns a rect with the given width and height placed so that its pivot point
+// lies on the anchor point of parent, moved by offset. Anchor and pivot are fractions of the
+// width and height measured from the top left corner, such as AnchorCentre.
+func AlignRect(parent Rect, size Vec2, anchor, pivot, offset Vec2) Rect {
+	at := parent.PointAt(anchor).Add(offset)
+	half := size.Mul(0.5)
+	return Rect{
+		Position: Point2{
+			at[0] + (0.5-pivot[0])*size[0],
+			at[1] + (0.5-pivot[1])*size[1],
+		},
+		Size: half,
+	}
+}
+
+// AnchorRect returns the rect that stretches between two anchor points of parent, inset by
+// the margins. When anchorMin and anchorMax are equal the result has zero size before the
+// margins are applied, so margins act as offsets from the anchor.
+func AnchorRect(parent Rect, anchorMin, anchorMax Vec2, m Margins) Rect {
+	tl := parent.PointAt(anchorMin)
+	br := parent.PointAt(anchorMax)
+	return RectFromCorners(tl, br).Inset(m)
+}
+
+// FitRect scales a child of the given width and height according to mode and aligns it within
+// parent. align is the fraction of the spare width and height to place before the child, so
+// AnchorCentre centres it and AnchorTopLeft places it in the top left corner.
+func FitRect(parent Rect, size Vec2, mode FitMode, align Vec2) Rect {
+	pw, ph := parent.Width(), parent.Height()
+
+	switch mode {
+	case FitContain, FitCover:
+		if size[0] > 0 && size[1] > 0 {
+			sx, sy := pw/size[0], ph/size[1]
+			s := min(sx, sy)
+			if mode == FitCover {
+				s = max(sx, sy)
+			}
+			size = size.Mul(s)
+		}
+	case FitStretch:
+		size = Vec2{pw, ph}
+	}
+
+	tl := parent.TopLeft()
+	pos := Point2{
+		tl[0] + (pw-size[0])*align[0],
+		tl[1] + (ph-size[1])*align[1],
+	}
+	return RectFromCorners(pos, pos.Add(size))
+}
@@ -0,0 +1,73 @@
+package geom
+
+import "testing"
+
+func TestAlignRect(t *testing.T) {
+	parent := RectFromCorners(Point2{0, 0}, Point2{100, 50})
+
+	testCases := []struct {
+		name   string
+		anchor Vec2
+		pivot  Vec2
+		offset Vec2
+		want   Rect
+	}{
+		{name: "centre", anchor: AnchorCentre, pivot: AnchorCentre, want: RectFromCorners(Point2{45, 20}, Point2{55, 30})},
+		{name: "top left", anchor: AnchorTopLeft, pivot: AnchorTopLeft, want: RectFromCorners(Point2{0, 0}, Point2{10, 10})},
+		{name: "bottom right", anchor: AnchorBottomRight, pivot: AnchorBottomRight, want: RectFromCorners(Point2{90, 40}, Point2{100, 50})},
+		{name: "outside right", anchor: AnchorRight, pivot: AnchorLeft, offset: Vec2{5, 0}, want: RectFromCorners(Point2{105, 20}, Point2{115, 30})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := AlignRect(parent, Vec2{10, 10}, tc.anchor, tc.pivot, tc.offset); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAnchorRect(t *testing.T) {
+	parent := RectFromCorners(Point2{0, 0}, Point2{100, 50})
+
+	// Stretch across the top with a margin
+	got := AnchorRect(parent, AnchorTopLeft, AnchorTopRight, Margins{Left: 5, Right: 5, Bottom: -10})
+	if want := RectFromCorners(Point2{5, 0}, Point2{95, 10}); got != want {
+		t.Errorf("top bar: got %v, wanted %v", got, want)
+	}
+
+	got = AnchorRect(parent, AnchorTopLeft, AnchorBottomRight, UniformMargins(10))
+	if want := RectFromCorners(Point2{10, 10}, Point2{90, 40}); got != want {
+		t.Errorf("inset: got %v, wanted %v", got, want)
+	}
+
+	got = parent.Inset(UniformMargins(40))
+	if got.Height() != 0 {
+		t.Errorf("over inset: got %v, wanted zero height", got)
+	}
+}
+
+func TestFitRect(t *testing.T) {
+	parent := RectFromCorners(Point2{0, 0}, Point2{100, 50})
+	size := Vec2{20, 20}
+
+	testCases := []struct {
+		mode  FitMode
+		align Vec2
+		want  Rect
+	}{
+		{mode: FitNone, align: AnchorCentre, want: RectFromCorners(Point2{40, 15}, Point2{60, 35})},
+		{mode: FitContain, align: AnchorCentre, want: RectFromCorners(Point2{25, 0}, Point2{75, 50})},
+		{mode: FitContain, align: AnchorTopLeft, want: RectFromCorners(Point2{0, 0}, Point2{50, 50})},
+		{mode: FitCover, align: AnchorCentre, want: RectFromCorners(Point2{0, -25}, Point2{100, 75})},
+		{mode: FitStretch, align: AnchorCentre, want: parent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode.String(), func(t *testing.T) {
+			if got := FitRect(parent, size, tc.mode, tc.align); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
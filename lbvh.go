@@ -0,0 +1,254 @@
+package geom
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// parallelBuildMin is the minimum number of items that will be handled by each goroutine when
+// building a linear bounding volume hierarchy.
+const parallelBuildMin = 4096
+
+// BuildLBVH builds a linear bounding volume hierarchy over the items. Items are sorted along a
+// Morton curve through the centres of their bounds and the tree is derived from the sorted
+// codes, following Karras, Maximizing Parallelism in the Construction of BVHs, Octrees, and
+// k-d Trees. It builds much faster than BuildBVH for large inputs, with large inputs divided
+// between multiple goroutines, at the cost of trees that are slower to query. The Bins option
+// is ignored.
+func BuildLBVH(items []Bounded, opts BVHOptions) *BVH {
+	if opts.MaxLeafSize <= 0 {
+		opts.MaxLeafSize = 4
+	}
+
+	n := len(items)
+	b := &BVH{
+		items: items,
+		order: make([]int, n),
+	}
+	if n == 0 {
+		return b
+	}
+
+	boxes := make([]bvhBox, n)
+	parallelFor(n, parallelBuildMin, func(start, end int) {
+		for i := start; i < end; i++ {
+			boxes[i] = newBVHBox(items[i].Bounds())
+		}
+	})
+
+	cmin, cmax := emptyBounds()
+	for i := range boxes {
+		cmin, cmax = extendBounds(cmin, cmax, boxes[i].centroid, boxes[i].centroid)
+	}
+	extent := cmax.Sub(cmin)
+	var scale Vec3
+	for i := 0; i < 3; i++ {
+		if extent[i] > 0 {
+			scale[i] = mortonMax / extent[i]
+		}
+	}
+
+	codes := make([]uint64, n)
+	parallelFor(n, parallelBuildMin, func(start, end int) {
+		for i := start; i < end; i++ {
+			c := boxes[i].centroid.Sub(cmin)
+			codes[i] = morton3(uint32(c[0]*scale[0]), uint32(c[1]*scale[1]), uint32(c[2]*scale[2]))
+			b.order[i] = i
+		}
+	})
+	radixSortCodes(codes, b.order)
+
+	// Build the binary radix tree over the sorted codes. Internal node i has children that are
+	// either internal nodes or, when flagged, leaves that refer to a single sorted item.
+	type radixNode struct {
+		first, last int // the range of sorted items covered by the node
+		left, right int
+		leftLeaf    bool
+		rightLeaf   bool
+	}
+	internal := make([]radixNode, n-1)
+	prefix := func(i, j int) int {
+		// The length of the common prefix of the codes at i and j, with the sorted position
+		// breaking ties between equal codes
+		if j < 0 || j >= n {
+			return -1
+		}
+		if codes[i] == codes[j] {
+			return 64 + bits.LeadingZeros64(uint64(i^j))
+		}
+		return bits.LeadingZeros64(codes[i] ^ codes[j])
+	}
+	parallelFor(n-1, parallelBuildMin, func(start, end int) {
+		for i := start; i < end; i++ {
+			// Find the direction of the range covered by the node
+			d := 1
+			if prefix(i, i+1) < prefix(i, i-1) {
+				d = -1
+			}
+
+			// Find the other end of the range
+			pmin := prefix(i, i-d)
+			lmax := 2
+			for prefix(i, i+lmax*d) > pmin {
+				lmax *= 2
+			}
+			l := 0
+			for t := lmax / 2; t >= 1; t /= 2 {
+				if prefix(i, i+(l+t)*d) > pmin {
+					l += t
+				}
+			}
+			j := i + l*d
+
+			// Find where the codes in the range split
+			pnode := prefix(i, j)
+			s := 0
+			for t := (l + 1) / 2; ; t = (t + 1) / 2 {
+				if prefix(i, i+(s+t)*d) > pnode {
+					s += t
+				}
+				if t == 1 {
+					break
+				}
+			}
+			split := i + s*d
+			if d < 0 {
+				split--
+			}
+
+			first, last := i, j
+			if d < 0 {
+				first, last = j, i
+			}
+			internal[i] = radixNode{
+				first:     first,
+				last:      last,
+				left:      split,
+				right:     split + 1,
+				leftLeaf:  first == split,
+				rightLeaf: last == split+1,
+			}
+		}
+	})
+
+	// Lay the tree out so that parents come before children, collapsing small subtrees into
+	// leaves
+	b.nodes = make([]bvhNode, 0, 2*n/opts.MaxLeafSize+1)
+	var layout func(first, last, node int, leaf bool) int
+	layout = func(first, last, node int, leaf bool) int {
+		idx := len(b.nodes)
+		b.nodes = append(b.nodes, bvhNode{})
+		if leaf || last-first+1 <= opts.MaxLeafSize {
+			b.nodes[idx].left, b.nodes[idx].count = first, last-first+1
+			return idx
+		}
+		rn := &internal[node]
+		lfirst, llast := rn.left, rn.left
+		if !rn.leftLeaf {
+			lfirst, llast = internal[rn.left].first, internal[rn.left].last
+		}
+		rfirst, rlast := rn.right, rn.right
+		if !rn.rightLeaf {
+			rfirst, rlast = internal[rn.right].first, internal[rn.right].last
+		}
+		left := layout(lfirst, llast, rn.left, rn.leftLeaf)
+		right := layout(rfirst, rlast, rn.right, rn.rightLeaf)
+		b.nodes[idx].left, b.nodes[idx].right = left, right
+		return idx
+	}
+	layout(0, n-1, 0, n == 1)
+
+	b.computeBounds(func(it int) (Point3, Point3) {
+		return boxes[it].min, boxes[it].max
+	})
+	b.buildCost = b.Cost()
+	return b
+}
+
+// mortonMax is the largest coordinate that can be encoded in each axis of a Morton code.
+const mortonMax = 1<<21 - 1
+
+// morton3 interleaves the lower 21 bits of x, y and z into a 63 bit Morton code.
+func morton3(x, y, z uint32) uint64 {
+	return spreadBits3(x) | spreadBits3(y)<<1 | spreadBits3(z)<<2
+}
+
+// spreadBits3 spreads the lower 21 bits of v so that there are two zero bits between each.
+func spreadBits3(v uint32) uint64 {
+	x := uint64(v) & 0x1fffff
+	x = (x | x<<32) & 0x1f00000000ffff
+	x = (x | x<<16) & 0x1f0000ff0000ff
+	x = (x | x<<8) & 0x100f00f00f00f00f
+	x = (x | x<<4) & 0x10c30c30c30c30c3
+	x = (x | x<<2) & 0x1249249249249249
+	return x
+}
+
+// radixSortCodes sorts codes into ascending order, applying the same reordering to order.
+// Equal codes keep their relative order.
+func radixSortCodes(codes []uint64, order []int) {
+	n := len(codes)
+	tmpCodes := make([]uint64, n)
+	tmpOrder := make([]int, n)
+	src, dst := codes, tmpCodes
+	srcOrder, dstOrder := order, tmpOrder
+
+	// Morton codes use the lower 63 bits so 8 passes of 8 bits cover them
+	for shift := uint(0); shift < 64; shift += 8 {
+		var counts [256]int
+		for _, c := range src {
+			counts[(c>>shift)&0xff]++
+		}
+		if counts[(src[0]>>shift)&0xff] == n {
+			// Every code has the same digit so this pass would not change anything
+			continue
+		}
+		total := 0
+		for i, c := range counts {
+			counts[i] = total
+			total += c
+		}
+		for i, c := range src {
+			d := (c >> shift) & 0xff
+			dst[counts[d]] = c
+			dstOrder[counts[d]] = srcOrder[i]
+			counts[d]++
+		}
+		src, dst = dst, src
+		srcOrder, dstOrder = dstOrder, srcOrder
+	}
+	if &src[0] != &codes[0] {
+		copy(codes, src)
+		copy(order, srcOrder)
+	}
+}
+
+// parallelFor calls fn over consecutive ranges that together cover [0, n), dividing them
+// between goroutines when each can be given at least minChunk elements. It returns once every
+// call has finished.
+func parallelFor(n, minChunk int, fn func(start, end int)) {
+	workers := n / minChunk
+	if procs := runtime.GOMAXPROCS(0); workers > procs {
+		workers = procs
+	}
+	if workers < 2 {
+		fn(0, n)
+		return
+	}
+
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,143 @@
+package geom
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkBVH verifies that every item is reached exactly once and that each node's bounds
+// contain those of its children and items.
+func checkBVH(t *testing.T, b *BVH, maxLeaf int) {
+	t.Helper()
+	contains := func(n *bvhNode, bmin, bmax Point3) bool {
+		for i := 0; i < 3; i++ {
+			if bmin[i] < n.min[i] || bmax[i] > n.max[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	seen := make([]int, b.Len())
+	for i := range b.nodes {
+		n := &b.nodes[i]
+		if n.leaf() {
+			if n.count > maxLeaf {
+				t.Fatalf("node %d: got leaf with %d items, wanted at most %d", i, n.count, maxLeaf)
+			}
+			for _, it := range b.order[n.left : n.left+n.count] {
+				seen[it]++
+				box := newBVHBox(b.items[it].Bounds())
+				if !contains(n, box.min, box.max) {
+					t.Fatalf("node %d does not contain item %d", i, it)
+				}
+			}
+			continue
+		}
+		if n.left <= i || n.right <= i {
+			t.Fatalf("node %d: children %d and %d come before their parent", i, n.left, n.right)
+		}
+		for _, c := range []int{n.left, n.right} {
+			if !contains(n, b.nodes[c].min, b.nodes[c].max) {
+				t.Fatalf("node %d does not contain child %d", i, c)
+			}
+		}
+	}
+	for i, c := range seen {
+		if c != 1 {
+			t.Fatalf("item %d appears %d times, wanted once", i, c)
+		}
+	}
+}
+
+func TestBuildLBVH(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	testCases := []struct {
+		name  string
+		items []Bounded
+	}{
+		{name: "single", items: randomSpheres(r, 1)},
+		{name: "small", items: randomSpheres(r, 37)},
+		{name: "parallel", items: randomSpheres(r, 20000)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := BuildLBVH(tc.items, BVHOptions{MaxLeafSize: 3})
+			checkBVH(t, b, 3)
+
+			for q := 0; q < 20; q++ {
+				box := AABB{Position: RandomPointInAABB(r, AABB{Size: Vec3{50, 50, 50}}), Size: Vec3{6, 6, 6}}
+				var got []int
+				b.QueryAABB(&box, func(item int) bool {
+					got = append(got, item)
+					return true
+				})
+				var want []int
+				for i, it := range tc.items {
+					bounds := it.Bounds()
+					if bounds.IntersectsAABB(&box) {
+						want = append(want, i)
+					}
+				}
+				sort.Ints(got)
+				if len(got) != len(want) {
+					t.Fatalf("query %v: got %d items, wanted %d", box, len(got), len(want))
+				}
+				for i := range got {
+					if got[i] != want[i] {
+						t.Fatalf("query %v: got %v, wanted %v", box, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildLBVHDuplicates(t *testing.T) {
+	// Items with identical centres share a Morton code
+	items := make([]Bounded, 50)
+	for i := range items {
+		items[i] = &Sphere{Position: Point3{float32(i % 3), 0, 0}, Radius: 1}
+	}
+	b := BuildLBVH(items, BVHOptions{MaxLeafSize: 1})
+	checkBVH(t, b, 1)
+
+	count := 0
+	b.QueryPoint(Point3{0, 0, 0}, func(int) bool {
+		count++
+		return true
+	})
+	if want := 34; count != want {
+		t.Errorf("got %d items, wanted %d", count, want)
+	}
+}
+
+func TestMorton3(t *testing.T) {
+	testCases := []struct {
+		x, y, z uint32
+		want    uint64
+	}{
+		{x: 0, y: 0, z: 0, want: 0},
+		{x: 1, y: 0, z: 0, want: 1},
+		{x: 0, y: 1, z: 0, want: 2},
+		{x: 0, y: 0, z: 1, want: 4},
+		{x: 3, y: 0, z: 0, want: 9},
+		{x: mortonMax, y: mortonMax, z: mortonMax, want: 1<<63 - 1},
+	}
+	for _, tc := range testCases {
+		if got := morton3(tc.x, tc.y, tc.z); got != tc.want {
+			t.Errorf("morton3(%d, %d, %d): got %x, wanted %x", tc.x, tc.y, tc.z, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkBuildLBVH(b *testing.B) {
+	items := randomSpheres(rand.New(rand.NewSource(1)), 100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildLBVH(items, BVHOptions{})
+	}
+}
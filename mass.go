@@ -0,0 +1,226 @@
+package geom
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// MassProperties describes how mass is distributed within a solid body.
+type MassProperties struct {
+	Mass    float32
+	Center  Point3 // centre of mass
+	Inertia Mat3   // inertia tensor about the centre of mass, in world axes
+}
+
+// InertiaTensor returns the inertia tensor of a solid sphere with the given mass about its
+// centre.
+func (s *Sphere) InertiaTensor(mass float32) Mat3 {
+	i := 0.4 * mass * s.Radius * s.Radius
+	return mgl32.Diag3(Vec3{i, i, i})
+}
+
+// MassProperties returns the mass properties of a solid sphere with the given mass.
+func (s *Sphere) MassProperties(mass float32) MassProperties {
+	return MassProperties{Mass: mass, Center: s.Position, Inertia: s.InertiaTensor(mass)}
+}
+
+// InertiaTensor returns the inertia tensor of a solid box with the given mass about its centre.
+func (a *AABB) InertiaTensor(mass float32) Mat3 {
+	return boxInertia(a.Size, mass)
+}
+
+// MassProperties returns the mass properties of a solid box with the given mass.
+func (a *AABB) MassProperties(mass float32) MassProperties {
+	return MassProperties{Mass: mass, Center: a.Position, Inertia: a.InertiaTensor(mass)}
+}
+
+// InertiaTensor returns the inertia tensor of a solid box with the given mass about its centre,
+// expressed in world axes.
+func (o *OBB) InertiaTensor(mass float32) Mat3 {
+	r := o.Orientation.Mat4().Mat3()
+	return r.Mul3(boxInertia(o.Size, mass)).Mul3(r.Transpose())
+}
+
+// MassProperties returns the mass properties of a solid box with the given mass.
+func (o *OBB) MassProperties(mass float32) MassProperties {
+	return MassProperties{Mass: mass, Center: o.Position, Inertia: o.InertiaTensor(mass)}
+}
+
+// boxInertia returns the inertia tensor of a solid box with half size h in its local axes.
+func boxInertia(h Vec3, mass float32) Mat3 {
+	x2, y2, z2 := h[0]*h[0], h[1]*h[1], h[2]*h[2]
+	return mgl32.Diag3(Vec3{y2 + z2, x2 + z2, x2 + y2}.Mul(mass / 3))
+}
+
+// InertiaTensor returns the inertia tensor of a solid capsule with the given mass about its
+// centre, expressed in world axes.
+func (c *Capsule) InertiaTensor(mass float32) Mat3 {
+	axis := c.End.Sub(c.Start)
+	h := axis.Len()
+	if h == 0 {
+		s := Sphere{Radius: c.Radius}
+		return s.InertiaTensor(mass)
+	}
+	u := axis.Mul(1 / h)
+	r := c.Radius
+	r2 := r * r
+
+	// Split the mass between the cylinder and the two hemispherical caps by volume
+	vcyl := pi * r2 * h
+	vcaps := 4.0 / 3.0 * pi * r2 * r
+	mcyl := mass * vcyl / (vcyl + vcaps)
+	mcaps := mass - mcyl
+
+	along := mcyl*r2/2 + mcaps*2*r2/5
+	across := mcyl*(h*h/12+r2/4) + mcaps*(2*r2/5+h*h/4+3*h*r/8)
+
+	return axialInertia(u, along, across)
+}
+
+// MassProperties returns the mass properties of a solid capsule with the given mass.
+func (c *Capsule) MassProperties(mass float32) MassProperties {
+	return MassProperties{
+		Mass:    mass,
+		Center:  c.Start.Add(c.End).Mul(0.5),
+		Inertia: c.InertiaTensor(mass),
+	}
+}
+
+// CylinderInertiaTensor returns the inertia tensor of a solid cylinder with the given mass about
+// its centre, expressed in world axes. The cylinder has flat ends centred on start and end.
+// There is no cylinder shape in the package, so the cylinder is described by its axis and
+// radius as for a Capsule.
+func CylinderInertiaTensor(start, end Point3, radius, mass float32) Mat3 {
+	axis := end.Sub(start)
+	h := axis.Len()
+	r2 := radius * radius
+	// A cylinder of no length is a disc with no axis to orient it, so choose one
+	u := Y3
+	if h > 0 {
+		u = axis.Mul(1 / h)
+	}
+	return axialInertia(u, mass*r2/2, mass*(h*h/12+r2/4))
+}
+
+// CylinderMassProperties returns the mass properties of a solid cylinder with the given mass,
+// described as for CylinderInertiaTensor.
+func CylinderMassProperties(start, end Point3, radius, mass float32) MassProperties {
+	return MassProperties{
+		Mass:    mass,
+		Center:  start.Add(end).Mul(0.5),
+		Inertia: CylinderInertiaTensor(start, end, radius, mass),
+	}
+}
+
+// axialInertia returns the inertia tensor of a body that is symmetric about the unit axis u,
+// with the given moments of inertia about the axis and about any line across it through the
+// centre. The tensor can be built this way without a rotation.
+func axialInertia(u Vec3, along, across float32) Mat3 {
+	return mgl32.Ident3().Mul(across).Add(outer3(u, u).Mul(along - across))
+}
+
+// InertiaTensor returns the inertia tensor of the solid enclosed by the mesh, with the given
+// mass spread evenly through it, about its centre of mass. The mesh must be closed with its
+// triangles facing outward.
+func (m *TriMesh) InertiaTensor(mass float32) Mat3 {
+	return m.MassProperties(mass).Inertia
+}
+
+// MassProperties returns the mass properties of the solid enclosed by the mesh, with the given
+// mass spread evenly through it. The mesh must be closed with its triangles facing outward. A
+// mesh that encloses no volume has no inertia and its centre is the mean of its positions.
+func (m *TriMesh) MassProperties(mass float32) MassProperties {
+	res := MassProperties{Mass: mass}
+	if len(m.Positions) == 0 {
+		return res
+	}
+
+	// Sum over the tetrahedra joining each triangle to a reference point. A tetrahedron's
+	// signed volume cancels the parts of others that lie outside the mesh, so the sums give the
+	// volume, first moment and covariance of the solid. Working relative to a point on the mesh
+	// rather than the origin keeps precision for meshes far from the origin.
+	ref := m.Positions[0]
+	var vol float64
+	var first [3]float64
+	var cov [3][3]float64
+	for i := 0; i < m.Len(); i++ {
+		t := m.Tri(i)
+		a, b, c := t.A.Sub(ref), t.B.Sub(ref), t.C.Sub(ref)
+		pa := [3]float64{float64(a[0]), float64(a[1]), float64(a[2])}
+		pb := [3]float64{float64(b[0]), float64(b[1]), float64(b[2])}
+		pc := [3]float64{float64(c[0]), float64(c[1]), float64(c[2])}
+		det := dot64(pa, cross64(pb, pc))
+		vol += det / 6
+		var sum [3]float64
+		for j := 0; j < 3; j++ {
+			sum[j] = pa[j] + pb[j] + pc[j]
+			first[j] += det / 24 * sum[j]
+		}
+		// The covariance of a tetrahedron with a corner at the reference point
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				cov[j][k] += det / 120 * (pa[j]*pa[k] + pb[j]*pb[k] + pc[j]*pc[k] + sum[j]*sum[k])
+			}
+		}
+	}
+
+	if vol == 0 {
+		var mean Vec3
+		for _, p := range m.Positions {
+			mean = mean.Add(p)
+		}
+		res.Center = mean.Mul(1 / float32(len(m.Positions)))
+		return res
+	}
+
+	var centre [3]float64
+	for j := 0; j < 3; j++ {
+		centre[j] = first[j] / vol
+	}
+	res.Center = ref.Add(Vec3{float32(centre[0]), float32(centre[1]), float32(centre[2])})
+
+	// Move the covariance to the centre of mass, then convert it to an inertia tensor
+	for j := 0; j < 3; j++ {
+		for k := 0; k < 3; k++ {
+			cov[j][k] -= vol * centre[j] * centre[k]
+		}
+	}
+	trace := cov[0][0] + cov[1][1] + cov[2][2]
+	scale := float64(mass) / vol
+	for j := 0; j < 3; j++ {
+		for k := 0; k < 3; k++ {
+			v := -cov[j][k]
+			if j == k {
+				v += trace
+			}
+			// Mat3 is stored in column major order
+			res.Inertia[k*3+j] = float32(v * scale)
+		}
+	}
+	return res
+}
+
+// CombineMassProperties returns the mass properties of a compound body made from the given
+// parts. The inertia of each part is moved to the combined centre of mass using the parallel
+// axis theorem.
+func CombineMassProperties(parts []MassProperties) MassProperties {
+	var res MassProperties
+	var weighted Vec3
+	for _, p := range parts {
+		res.Mass += p.Mass
+		weighted = weighted.Add(p.Center.Mul(p.Mass))
+	}
+	if res.Mass == 0 {
+		return res
+	}
+	res.Center = weighted.Mul(1 / res.Mass)
+
+	for _, p := range parts {
+		d := p.Center.Sub(res.Center)
+		shift := mgl32.Ident3().Mul(d.Dot(d)).Sub(outer3(d, d)).Mul(p.Mass)
+		res.Inertia = res.Inertia.Add(p.Inertia).Add(shift)
+	}
+	return res
+}
+
+// outer3 returns the outer product of a and b.
+func outer3(a, b Vec3) Mat3 {
+	return mgl32.Mat3FromCols(a.Mul(b[0]), a.Mul(b[1]), a.Mul(b[2]))
+}
@@ -0,0 +1,163 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func nearMat3(a, b Mat3, tol float32) bool {
+	for i := range a {
+		if abs(a[i]-b[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+func TestInertiaTensor(t *testing.T) {
+	// An upright capsule of radius 1 and length 2 between the cap centres
+	capY := Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}
+	capX := Capsule{Start: Point3{5, 0, 0}, End: Point3{7, 0, 0}, Radius: 1}
+	obb := NewOBB(Point3{0, 0, 0}, Vec3{1, 2, 3}, mgl32.QuatRotate(pi/2, Z3))
+
+	// Expected capsule values: cylinder mass 0.6, caps mass 0.4
+	capAlong := float32(0.6*0.5 + 0.4*0.4)
+	capAcross := float32(0.6*(4.0/12+0.25) + 0.4*(0.4+1+0.75))
+
+	testCases := []struct {
+		name string
+		got  Mat3
+		want Mat3
+	}{
+		{
+			name: "sphere",
+			got:  (&Sphere{Radius: 2}).InertiaTensor(5),
+			want: mgl32.Diag3(Vec3{8, 8, 8}),
+		},
+		{
+			name: "aabb",
+			got:  (&AABB{Size: Vec3{1, 2, 3}}).InertiaTensor(3),
+			want: mgl32.Diag3(Vec3{13, 10, 5}),
+		},
+		{
+			name: "obb rotated onto y",
+			got:  obb.InertiaTensor(3),
+			want: mgl32.Diag3(Vec3{10, 13, 5}),
+		},
+		{
+			name: "capsule along y",
+			got:  capY.InertiaTensor(1),
+			want: mgl32.Diag3(Vec3{capAcross, capAlong, capAcross}),
+		},
+		{
+			name: "capsule along x",
+			got:  capX.InertiaTensor(1),
+			want: mgl32.Diag3(Vec3{capAlong, capAcross, capAcross}),
+		},
+		{
+			name: "cylinder along z",
+			got:  CylinderInertiaTensor(Point3{1, 1, -2}, Point3{1, 1, 2}, 1, 6),
+			want: mgl32.Diag3(Vec3{9.5, 9.5, 3}),
+		},
+		{
+			name: "cylinder along x",
+			got:  CylinderInertiaTensor(Point3{2, 0, 0}, Point3{-2, 0, 0}, 1, 6),
+			want: mgl32.Diag3(Vec3{3, 9.5, 9.5}),
+		},
+		{
+			name: "box mesh",
+			got:  boxMesh(AABB{Position: Point3{100, -50, 20}, Size: Vec3{1, 2, 3}}).InertiaTensor(3),
+			want: mgl32.Diag3(Vec3{13, 10, 5}),
+		},
+		{
+			name: "rotated box hull",
+			got:  ConvexHull3(obbCorners(obb)).InertiaTensor(3),
+			want: mgl32.Diag3(Vec3{10, 13, 5}),
+		},
+		{
+			name: "degenerate capsule",
+			got:  (&Capsule{Radius: 2}).InertiaTensor(5),
+			want: mgl32.Diag3(Vec3{8, 8, 8}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !nearMat3(tc.got, tc.want, 1e-4) {
+				t.Errorf("got %v, wanted %v", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCombineMassProperties(t *testing.T) {
+	a := Sphere{Position: Point3{-1, 0, 0}, Radius: 1}
+	b := Sphere{Position: Point3{3, 0, 0}, Radius: 1}
+
+	got := CombineMassProperties([]MassProperties{a.MassProperties(3), b.MassProperties(1)})
+	if got.Mass != 4 {
+		t.Errorf("got mass %v, wanted %v", got.Mass, 4)
+	}
+	if want := (Point3{0, 0, 0}); !nearVec3(got.Center, want, 1e-6) {
+		t.Errorf("got centre %v, wanted %v", got.Center, want)
+	}
+
+	// Each sphere contributes 0.4*m about its own centre plus m*d*d about the y and z axes
+	want := mgl32.Diag3(Vec3{1.6, 1.6 + 3 + 9, 1.6 + 3 + 9})
+	if !nearMat3(got.Inertia, want, 1e-4) {
+		t.Errorf("got inertia %v, wanted %v", got.Inertia, want)
+	}
+
+	if got := CombineMassProperties(nil); got != (MassProperties{}) {
+		t.Errorf("got %v for no parts, wanted zero", got)
+	}
+}
+
+func obbCorners(o OBB) []Point3 {
+	r := o.Orientation.Mat4().Mat3()
+	var pts []Point3
+	for _, c := range boxCorners(Point3{}, 1) {
+		pts = append(pts, o.Position.Add(r.Mul3x1(Vec3{c[0] * o.Size[0], c[1] * o.Size[1], c[2] * o.Size[2]})))
+	}
+	return pts
+}
+
+func TestTriMeshMassProperties(t *testing.T) {
+	box := AABB{Position: Point3{100, -50, 20}, Size: Vec3{1, 2, 3}}
+	got := boxMesh(box).MassProperties(3)
+	if got.Mass != 3 || !nearVec3(got.Center, box.Position, 1e-4) {
+		t.Errorf("got mass %v and centre %v, wanted 3 and %v", got.Mass, got.Center, box.Position)
+	}
+
+	// An L shape made of two boxes has products of inertia that a single box does not
+	l := &TriMesh{
+		Positions: []Point3{
+			{0, 0, 0}, {2, 0, 0}, {2, 1, 0}, {1, 1, 0}, {1, 2, 0}, {0, 2, 0},
+			{0, 0, 1}, {2, 0, 1}, {2, 1, 1}, {1, 1, 1}, {1, 2, 1}, {0, 2, 1},
+		},
+	}
+	outline := []uint32{0, 1, 2, 3, 4, 5}
+	// Top and bottom as fans from corner 0, then the sides as quads
+	for i := 1; i+1 < len(outline); i++ {
+		l.Indices = append(l.Indices, 0, outline[i+1], outline[i], 6, 6+outline[i], 6+outline[i+1])
+	}
+	for i, a := range outline {
+		b := outline[(i+1)%len(outline)]
+		l.Indices = append(l.Indices, a, b, b+6, a, b+6, a+6)
+	}
+	parts := []MassProperties{
+		(&AABB{Position: Point3{1, 0.5, 0.5}, Size: Vec3{1, 0.5, 0.5}}).MassProperties(2),
+		(&AABB{Position: Point3{0.5, 1.5, 0.5}, Size: Vec3{0.5, 0.5, 0.5}}).MassProperties(1),
+	}
+	want := CombineMassProperties(parts)
+	got = l.MassProperties(3)
+	if !nearVec3(got.Center, want.Center, 1e-5) || !nearMat3(got.Inertia, want.Inertia, 1e-5) {
+		t.Errorf("L shape: got %v, wanted %v", got, want)
+	}
+
+	flat := &TriMesh{Positions: []Point3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}, Indices: []uint32{0, 1, 2}}
+	if got := flat.MassProperties(1); got.Inertia != (Mat3{}) || !nearVec3(got.Center, Point3{2.0 / 3, 2.0 / 3, 0}, 1e-6) {
+		t.Errorf("flat mesh: got %v", got)
+	}
+}
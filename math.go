@@ -25,12 +25,52 @@ func Degrees(radians float32) float32 {
 	return radians * 180 / pi
 }
 
+// WrapAngle returns the angle in radians equivalent to a that lies in the range (-π, π].
+func WrapAngle(a float32) float32 {
+	// Work with the float32 value of π so that ±π map to the same angle
+	const pi32 = float32(pi)
+	w := float32(math.Remainder(float64(a), float64(2*pi32)))
+	if w <= -pi32 {
+		return pi32
+	}
+	return w
+}
+
+// AngleDelta returns the shortest signed angle in radians that rotates a onto b. The result is
+// in the range (-π, π] and is positive when the shortest rotation is counter clockwise.
+func AngleDelta(a, b float32) float32 {
+	return WrapAngle(b - a)
+}
+
+// SignedAngle2 returns the angle in radians that rotates the direction of a onto the direction of b.
+// The result is in the range [-π, π] and is positive when the rotation is counter clockwise.
+func SignedAngle2(a, b Vec2) float32 {
+	return atan2(Cross2(a, b), a.Dot(b))
+}
+
+// AngleBetween3 returns the unsigned angle in radians between the directions of a and b, in the
+// range [0, π].
+func AngleBetween3(a, b Vec3) float32 {
+	return atan2(a.Cross(b).Len(), a.Dot(b))
+}
+
 // sqrt returns the positive square root of v
 // TODO: replace with https://github.com/rkusa/gm
 func sqrt(v float32) float32 {
 	return float32(math.Sqrt(float64(v)))
 }
 
+// atan2 returns the arc tangent of y/x, using the signs of the two to determine the quadrant
+func atan2(y, x float32) float32 {
+	return float32(math.Atan2(float64(y), float64(x)))
+}
+
+// sincos returns the sine and cosine of a
+func sincos(a float32) (float32, float32) {
+	s, c := math.Sincos(float64(a))
+	return float32(s), float32(c)
+}
+
 // pow2 returns the next highest power of 2 or the number unchanged if it is already a power of 2.
 // From https://graphics.stanford.edu/~seander/bithacks.html#RoundUpPowerOf2
 func Pow2(v uint32) uint32 {
@@ -93,6 +133,167 @@ func Clamp(v, l, u float32) float32 {
 	return v
 }
 
+// Lerp linearly interpolates between a and b. t is not clamped so values outside the range [0,1]
+// extrapolate beyond a and b.
+func Lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// LerpVec2 linearly interpolates between a and b. t is not clamped.
+func LerpVec2(a, b Vec2, t float32) Vec2 {
+	return Vec2{Lerp(a[0], b[0], t), Lerp(a[1], b[1], t)}
+}
+
+// LerpVec3 linearly interpolates between a and b. t is not clamped.
+func LerpVec3(a, b Vec3, t float32) Vec3 {
+	return Vec3{Lerp(a[0], b[0], t), Lerp(a[1], b[1], t), Lerp(a[2], b[2], t)}
+}
+
+// InverseLerp returns the parameter t such that Lerp(a, b, t) == v. It returns 0 when a and b
+// are equal.
+func InverseLerp(a, b, v float32) float32 {
+	if a == b {
+		return 0
+	}
+	return (v - a) / (b - a)
+}
+
+// InverseLerpVec2 returns the parameter of the point on the line through a and b that is closest to v.
+// It returns 0 when a and b are equal.
+func InverseLerpVec2(a, b, v Vec2) float32 {
+	ab := b.Sub(a)
+	lsq := ab.Dot(ab)
+	if lsq == 0 {
+		return 0
+	}
+	return v.Sub(a).Dot(ab) / lsq
+}
+
+// InverseLerpVec3 returns the parameter of the point on the line through a and b that is closest to v.
+// It returns 0 when a and b are equal.
+func InverseLerpVec3(a, b, v Vec3) float32 {
+	ab := b.Sub(a)
+	lsq := ab.Dot(ab)
+	if lsq == 0 {
+		return 0
+	}
+	return v.Sub(a).Dot(ab) / lsq
+}
+
+// Remap maps v from the range [inMin, inMax] to the range [outMin, outMax]. v is not clamped.
+func Remap(v, inMin, inMax, outMin, outMax float32) float32 {
+	return Lerp(outMin, outMax, InverseLerp(inMin, inMax, v))
+}
+
+// SmoothStep returns 0 when x <= edge0, 1 when x >= edge1 and smoothly interpolates between
+// them using a cubic Hermite curve otherwise.
+func SmoothStep(edge0, edge1, x float32) float32 {
+	t := Clamp(InverseLerp(edge0, edge1, x), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+// MoveToward moves current toward target by at most maxDelta without overshooting.
+func MoveToward(current, target, maxDelta float32) float32 {
+	if abs(target-current) <= maxDelta {
+		return target
+	}
+	return current + copysign(maxDelta, target-current)
+}
+
+// MoveTowardVec2 moves current in a straight line toward target by at most maxDelta without overshooting.
+func MoveTowardVec2(current, target Vec2, maxDelta float32) Vec2 {
+	d := target.Sub(current)
+	l := d.Len()
+	if l <= maxDelta || l == 0 {
+		return target
+	}
+	return current.Add(d.Mul(maxDelta / l))
+}
+
+// MoveTowardVec3 moves current in a straight line toward target by at most maxDelta without overshooting.
+func MoveTowardVec3(current, target Vec3, maxDelta float32) Vec3 {
+	d := target.Sub(current)
+	l := d.Len()
+	if l <= maxDelta || l == 0 {
+		return target
+	}
+	return current.Add(d.Mul(maxDelta / l))
+}
+
+// smoothDampFactors returns the spring constant and decay for a critically damped spring that
+// reaches its target in approximately smoothTime.
+// See Game Programming Gems 4, chapter 1.10.
+func smoothDampFactors(smoothTime, dt float32) (omega, decay float32) {
+	smoothTime = max(smoothTime, 1e-4)
+	omega = 2 / smoothTime
+	x := omega * dt
+	decay = 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	return omega, decay
+}
+
+// SmoothDamp gradually moves current toward target using a critically damped spring that takes
+// approximately smoothTime to arrive. velocity holds the rate of change and is updated on each call;
+// it should start at zero and be passed unchanged between calls. dt is the time elapsed since the
+// previous call.
+func SmoothDamp(current, target float32, velocity *float32, smoothTime, dt float32) float32 {
+	if dt <= 0 {
+		return current
+	}
+	omega, decay := smoothDampFactors(smoothTime, dt)
+
+	change := current - target
+	temp := (*velocity + omega*change) * dt
+	*velocity = (*velocity - omega*temp) * decay
+	res := target + (change+temp)*decay
+
+	// Prevent overshooting
+	if (target-current > 0) == (res > target) {
+		res = target
+		*velocity = 0
+	}
+	return res
+}
+
+// SmoothDampVec2 is the Vec2 equivalent of SmoothDamp.
+func SmoothDampVec2(current, target Vec2, velocity *Vec2, smoothTime, dt float32) Vec2 {
+	if dt <= 0 {
+		return current
+	}
+	omega, decay := smoothDampFactors(smoothTime, dt)
+
+	change := current.Sub(target)
+	temp := velocity.Add(change.Mul(omega)).Mul(dt)
+	*velocity = velocity.Sub(temp.Mul(omega)).Mul(decay)
+	res := target.Add(change.Add(temp).Mul(decay))
+
+	// Prevent overshooting
+	if target.Sub(current).Dot(res.Sub(target)) > 0 {
+		res = target
+		*velocity = Vec2{}
+	}
+	return res
+}
+
+// SmoothDampVec3 is the Vec3 equivalent of SmoothDamp.
+func SmoothDampVec3(current, target Vec3, velocity *Vec3, smoothTime, dt float32) Vec3 {
+	if dt <= 0 {
+		return current
+	}
+	omega, decay := smoothDampFactors(smoothTime, dt)
+
+	change := current.Sub(target)
+	temp := velocity.Add(change.Mul(omega)).Mul(dt)
+	*velocity = velocity.Sub(temp.Mul(omega)).Mul(decay)
+	res := target.Add(change.Add(temp).Mul(decay))
+
+	// Prevent overshooting
+	if target.Sub(current).Dot(res.Sub(target)) > 0 {
+		res = target
+		*velocity = Vec3{}
+	}
+	return res
+}
+
 // Signbit32 returns true if x is negative or negative zero.
 func Signbit32(x float32) bool {
 	return math.Float32bits(x)&(1<<31) != 0
@@ -0,0 +1,137 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestWrapAngle(t *testing.T) {
+	testCases := []struct {
+		a    float32
+		want float32
+	}{
+		{a: 0, want: 0},
+		{a: pi, want: pi},
+		{a: -pi, want: pi},
+		{a: 3 * pi / 2, want: -pi / 2},
+		{a: -3 * pi / 2, want: pi / 2},
+		{a: 5 * pi, want: pi},
+		{a: 4*pi + 0.5, want: 0.5},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if got := WrapAngle(tc.a); abs(got-tc.want) > 1e-5 {
+				t.Errorf("WrapAngle(%v): got %v, wanted %v", tc.a, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAngleDelta(t *testing.T) {
+	testCases := []struct {
+		a, b float32
+		want float32
+	}{
+		{a: 0, b: pi / 2, want: pi / 2},
+		{a: pi / 2, b: 0, want: -pi / 2},
+		{a: Radians(170), b: Radians(-170), want: Radians(20)},
+		{a: Radians(-170), b: Radians(170), want: Radians(-20)},
+		{a: Radians(10), b: Radians(370), want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if got := AngleDelta(tc.a, tc.b); abs(got-tc.want) > 1e-5 {
+				t.Errorf("AngleDelta(%v, %v): got %v, wanted %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVectorAngles(t *testing.T) {
+	if got, want := SignedAngle2(Vec2{1, 0}, Vec2{0, 1}), float32(pi/2); abs(got-want) > 1e-6 {
+		t.Errorf("SignedAngle2: got %v, wanted %v", got, want)
+	}
+	if got, want := SignedAngle2(Vec2{0, 1}, Vec2{1, 0}), float32(-pi/2); abs(got-want) > 1e-6 {
+		t.Errorf("SignedAngle2: got %v, wanted %v", got, want)
+	}
+	if got, want := AngleBetween3(X3, Vec3{1, 1, 0}), float32(pi/4); abs(got-want) > 1e-6 {
+		t.Errorf("AngleBetween3: got %v, wanted %v", got, want)
+	}
+	if got, want := AngleBetween3(X3, Vec3{-2, 0, 0}), float32(pi); abs(got-want) > 1e-6 {
+		t.Errorf("AngleBetween3: got %v, wanted %v", got, want)
+	}
+}
+
+func TestVec2Helpers(t *testing.T) {
+	got := RotateVec2(Vec2{1, 0}, pi/2)
+	if abs(got[0]) > 1e-6 || abs(got[1]-1) > 1e-6 {
+		t.Errorf("RotateVec2: got %v, wanted %v", got, Vec2{0, 1})
+	}
+
+	if got, want := Perp(Vec2{2, 3}), (Vec2{-3, 2}); got != want {
+		t.Errorf("Perp: got %v, wanted %v", got, want)
+	}
+
+	if got := Cross2(Vec2{1, 0}, Vec2{0, 2}); got != 2 {
+		t.Errorf("Cross2: got %v, wanted %v", got, 2)
+	}
+	if got := Cross2(Vec2{0, 2}, Vec2{1, 0}); got != -2 {
+		t.Errorf("Cross2: got %v, wanted %v", got, -2)
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	if got := Lerp(2, 4, 0.25); got != 2.5 {
+		t.Errorf("Lerp: got %v, wanted %v", got, 2.5)
+	}
+	if got := InverseLerp(2, 4, 2.5); got != 0.25 {
+		t.Errorf("InverseLerp: got %v, wanted %v", got, 0.25)
+	}
+	if got := InverseLerpVec3(Vec3{}, Vec3{4, 0, 0}, Vec3{1, 5, 0}); got != 0.25 {
+		t.Errorf("InverseLerpVec3: got %v, wanted %v", got, 0.25)
+	}
+	if got := Remap(5, 0, 10, 100, 200); got != 150 {
+		t.Errorf("Remap: got %v, wanted %v", got, 150)
+	}
+	if got := SmoothStep(0, 1, 0.5); got != 0.5 {
+		t.Errorf("SmoothStep: got %v, wanted %v", got, 0.5)
+	}
+	if got := SmoothStep(0, 1, 2); got != 1 {
+		t.Errorf("SmoothStep: got %v, wanted %v", got, 1)
+	}
+	if got := MoveToward(1, -1, 0.5); got != 0.5 {
+		t.Errorf("MoveToward: got %v, wanted %v", got, 0.5)
+	}
+	if got := MoveToward(1, 1.2, 0.5); got != 1.2 {
+		t.Errorf("MoveToward: got %v, wanted %v", got, 1.2)
+	}
+	if got, want := MoveTowardVec3(Vec3{}, Vec3{0, 10, 0}, 2), (Vec3{0, 2, 0}); got != want {
+		t.Errorf("MoveTowardVec3: got %v, wanted %v", got, want)
+	}
+}
+
+func TestSmoothDamp(t *testing.T) {
+	var velocity float32
+	v := float32(0)
+	prev := v
+	for i := 0; i < 120; i++ {
+		v = SmoothDamp(v, 10, &velocity, 0.5, 1.0/60)
+		if v < prev || v > 10 {
+			t.Fatalf("step %d: got %v, expected monotonic approach to 10 without overshoot", i, v)
+		}
+		prev = v
+	}
+	if abs(v-10) > 0.1 {
+		t.Errorf("got %v after 2s, wanted close to 10", v)
+	}
+
+	var vel3 Vec3
+	p := Vec3{}
+	for i := 0; i < 120; i++ {
+		p = SmoothDampVec3(p, Vec3{10, 0, -10}, &vel3, 0.5, 1.0/60)
+	}
+	if !nearVec3(p, Vec3{10, 0, -10}, 0.1) {
+		t.Errorf("got %v after 2s, wanted close to %v", p, Vec3{10, 0, -10})
+	}
+}
@@ -0,0 +1,72 @@
+package geom
+
+// TriMesh is an indexed mesh of triangles. Each consecutive group of three indices names the
+// corners of one triangle, which are in counter clockwise order when seen from the front.
+type TriMesh struct {
+	Positions []Point3
+	Normals   []Vec3 // optional, one per position when present
+	Indices   []uint32
+}
+
+// Len returns the number of triangles in the mesh.
+func (m *TriMesh) Len() int {
+	return len(m.Indices) / 3
+}
+
+// Tri returns the triangle with index i.
+func (m *TriMesh) Tri(i int) Tri3 {
+	return Tri3{
+		A: m.Positions[m.Indices[3*i]],
+		B: m.Positions[m.Indices[3*i+1]],
+		C: m.Positions[m.Indices[3*i+2]],
+	}
+}
+
+// Bounds returns the smallest AABB that contains all of the positions in the mesh.
+func (m *TriMesh) Bounds() AABB {
+	return AABBFromPoints(m.Positions)
+}
+
+// Raycast tests whether the ray hits the front of any triangle in the mesh, returning the
+// nearest hit. The Index of the result is the index of the triangle that was hit. Every
+// triangle is tested.
+func (m *TriMesh) Raycast(ray Ray3) (RaycastResult, bool) {
+	return m.RaycastWith(ray, RaycastOptions{})
+}
+
+// RaycastWith is like Raycast but takes options. The triangles of a two sided mesh are hit by
+// rays from either side.
+func (m *TriMesh) RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool) {
+	var best RaycastResult
+	best.Fail = RaycastFailOutsideBounds
+	found := false
+	for i := 0; i < m.Len(); i++ {
+		res, ok := m.Tri(i).RaycastWith(ray, opts)
+		if ok && (!found || res.Distance < best.Distance) {
+			best, found = res, true
+			best.Index = i
+		}
+	}
+	return best, found
+}
+
+// TransformMat4 applies the transformation matrix to the mesh in place. Normals are transformed
+// by the inverse transpose of the matrix so that they stay perpendicular to the surface. A
+// matrix that mirrors the mesh reverses the winding of each triangle so that the front faces
+// still face outwards.
+func (m *TriMesh) TransformMat4(mat Mat4) {
+	for i, p := range m.Positions {
+		m.Positions[i] = mat.Mul4x1(p.Vec4(1)).Vec3()
+	}
+	if len(m.Normals) > 0 {
+		nm := mat.Mat3().Inv().Transpose()
+		for i, n := range m.Normals {
+			m.Normals[i] = nm.Mul3x1(n).Normalize()
+		}
+	}
+	if mat.Mat3().Det() < 0 {
+		for i := 0; i+2 < len(m.Indices); i += 3 {
+			m.Indices[i+1], m.Indices[i+2] = m.Indices[i+2], m.Indices[i+1]
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// boxMesh returns a closed mesh of the surface of the box with outward facing triangles.
+func boxMesh(a AABB) *TriMesh {
+	pmin, pmax := a.Min(), a.Max()
+	m := &TriMesh{}
+	for i := 0; i < 8; i++ {
+		p := pmin
+		for j := 0; j < 3; j++ {
+			if i&(1<<j) != 0 {
+				p[j] = pmax[j]
+			}
+		}
+		m.Positions = append(m.Positions, p)
+	}
+	// Each face as four corners in counter clockwise order seen from outside
+	faces := [6][4]uint32{
+		{0, 2, 3, 1}, // -z
+		{4, 5, 7, 6}, // +z
+		{0, 1, 5, 4}, // -y
+		{2, 6, 7, 3}, // +y
+		{0, 4, 6, 2}, // -x
+		{1, 3, 7, 5}, // +x
+	}
+	for _, f := range faces {
+		m.Indices = append(m.Indices, f[0], f[1], f[2], f[0], f[2], f[3])
+	}
+	return m
+}
+
+func TestTriMesh(t *testing.T) {
+	box := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 1, 1}}
+	m := boxMesh(box)
+	if m.Len() != 12 {
+		t.Fatalf("got %d triangles, wanted 12", m.Len())
+	}
+	if b := m.Bounds(); b != box {
+		t.Errorf("Bounds: got %v, wanted %v", b, box)
+	}
+	for i := 0; i < m.Len(); i++ {
+		tri := m.Tri(i)
+		if out := tri.Centroid().Sub(box.Position); tri.Normal().Dot(out) <= 0 {
+			t.Errorf("triangle %d faces inwards", i)
+		}
+	}
+
+	// Mirroring keeps the triangles facing outwards and transforms normals
+	m.Normals = make([]Vec3, len(m.Positions))
+	for i := range m.Normals {
+		m.Normals[i] = Vec3{1, 0, 0}
+	}
+	m.TransformMat4(mgl32.Scale3D(-2, 1, 1))
+	centre := Point3{-2, 2, 3}
+	for i := 0; i < m.Len(); i++ {
+		tri := m.Tri(i)
+		if out := tri.Centroid().Sub(centre); tri.Normal().Dot(out) <= 0 {
+			t.Errorf("mirrored triangle %d faces inwards", i)
+		}
+	}
+	if !nearVec3(m.Normals[0], Vec3{-1, 0, 0}, 1e-6) {
+		t.Errorf("mirrored normal: got %v, wanted (-1, 0, 0)", m.Normals[0])
+	}
+}
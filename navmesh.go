@@ -0,0 +1,685 @@
+package geom
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+)
+
+// NavMesh is a triangulation of the walkable space inside a rectangle, around a set of
+// obstacles, for finding paths. Its triangles wind counter clockwise.
+type NavMesh struct {
+	Points    []Point2
+	Triangles [][3]int
+
+	// Neighbors holds the triangles across the edges of each triangle. Neighbors[t][i] is the
+	// triangle sharing the edge from Triangles[t][i] to Triangles[t][(i+1)%3], or -1 where the
+	// edge borders the rectangle or an obstacle.
+	Neighbors [][3]int
+}
+
+// NewNavMesh returns a navigation mesh for the space inside bounds that is outside all of the
+// obstacles. The mesh is a constrained Delaunay triangulation: every edge of the rectangle and
+// of the obstacles is an edge of the mesh, and the other edges are chosen to avoid long thin
+// triangles. The holes of an obstacle are walkable. Obstacles may touch each other and the
+// edges of the rectangle, but they must lie inside it and their edges must not cross.
+func NewNavMesh(bounds Rect, obstacles []Polygon2) (*NavMesh, error) {
+	if bounds.Size[0] <= 0 || bounds.Size[1] <= 0 {
+		return nil, errors.New("geom: navmesh bounds are empty")
+	}
+	lo, hi := bounds.Min(), bounds.Max()
+	c := newCDT([4]vec2d{
+		vec2dFromPoint(lo),
+		{float64(hi[0]), float64(lo[1])},
+		vec2dFromPoint(hi),
+		{float64(lo[0]), float64(hi[1])},
+	})
+
+	// Insert every point before any constraint so that point location walks a Delaunay
+	// triangulation, which guarantees that it finishes
+	var rings [][]int
+	for _, o := range obstacles {
+		for _, r := range append([][]Point2{o.Points}, o.Holes...) {
+			if len(r) < 3 {
+				return nil, errors.New("geom: navmesh obstacle has fewer than three points")
+			}
+			ids := make([]int, len(r))
+			for i, p := range r {
+				if !bounds.ContainsPoint2(p) {
+					return nil, fmt.Errorf("geom: navmesh obstacle point %v is outside the bounds", p)
+				}
+				id, err := c.addPoint(vec2dFromPoint(p))
+				if err != nil {
+					return nil, err
+				}
+				ids[i] = id
+			}
+			rings = append(rings, ids)
+		}
+	}
+	for _, r := range rings {
+		for i, a := range r {
+			if b := r[(i+1)%len(r)]; a != b {
+				if err := c.addConstraint(a, b); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	c.delaunay()
+	return c.navMesh(obstacles), nil
+}
+
+// Locate returns the triangle that contains p, or false if p is not in the walkable space. It
+// tests every triangle in turn.
+func (m *NavMesh) Locate(p Point2) (int, bool) {
+	q := vec2dFromPoint(p)
+	for t, tri := range m.Triangles {
+		inside := true
+		for i := 0; i < 3; i++ {
+			a, b := vec2dFromPoint(m.Points[tri[i]]), vec2dFromPoint(m.Points[tri[(i+1)%3]])
+			if b.sub(a).cross(q.sub(a)) < 0 {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// FindPath returns a path through the walkable space from one point to another, or false if
+// either point is not walkable or no path joins them. The path crosses the triangles chosen by
+// an A* search between their centroids and is then pulled tight around the corners of the
+// obstacles, so it is short but not guaranteed to be the shortest.
+func (m *NavMesh) FindPath(from, to Point2) (*Path2, bool) {
+	start, ok := m.Locate(from)
+	if !ok {
+		return nil, false
+	}
+	goal, ok := m.Locate(to)
+	if !ok {
+		return nil, false
+	}
+	tris, ok := m.search(start, goal, from, to)
+	if !ok {
+		return nil, false
+	}
+	return NewPath2(m.funnel(tris, from, to)), true
+}
+
+// search returns the triangles from start to goal found by an A* search.
+func (m *NavMesh) search(start, goal int, from, to Point2) ([]int, bool) {
+	pos := func(t int) Point2 {
+		if t == start {
+			return from
+		}
+		tri := m.Triangles[t]
+		return m.Points[tri[0]].Add(m.Points[tri[1]]).Add(m.Points[tri[2]]).Mul(1.0 / 3)
+	}
+	cost := make(map[int]float32, len(m.Triangles))
+	prev := make(map[int]int, len(m.Triangles))
+	cost[start] = 0
+	q := &navQueue{{tri: start, f: from.Sub(to).Len()}}
+	for q.Len() > 0 {
+		n := heap.Pop(q).(navNode)
+		if n.tri == goal {
+			tris := []int{goal}
+			for t := goal; t != start; {
+				t = prev[t]
+				tris = append(tris, t)
+			}
+			for i, j := 0, len(tris)-1; i < j; i, j = i+1, j-1 {
+				tris[i], tris[j] = tris[j], tris[i]
+			}
+			return tris, true
+		}
+		if n.f > cost[n.tri]+pos(n.tri).Sub(to).Len() {
+			// A stale entry for a triangle since reached more cheaply
+			continue
+		}
+		for _, u := range m.Neighbors[n.tri] {
+			if u < 0 {
+				continue
+			}
+			g := cost[n.tri] + pos(n.tri).Sub(pos(u)).Len()
+			if old, ok := cost[u]; ok && old <= g {
+				continue
+			}
+			cost[u] = g
+			prev[u] = n.tri
+			heap.Push(q, navNode{tri: u, f: g + pos(u).Sub(to).Len()})
+		}
+	}
+	return nil, false
+}
+
+// funnel returns the shortest path from one point to another through a sequence of triangles,
+// using the simple stupid funnel algorithm.
+func (m *NavMesh) funnel(tris []int, from, to Point2) []Point2 {
+	// The portals are the edges crossed between triangles, seen from the direction of travel
+	portals := [][2]Point2{{from, from}}
+	for k := 0; k+1 < len(tris); k++ {
+		t := m.Triangles[tris[k]]
+		for i, u := range m.Neighbors[tris[k]] {
+			if u == tris[k+1] {
+				portals = append(portals, [2]Point2{m.Points[t[(i+1)%3]], m.Points[t[i]]})
+				break
+			}
+		}
+	}
+	portals = append(portals, [2]Point2{to, to})
+
+	// side is positive when c is to the right of the line from a to b
+	side := func(a, b, c Point2) float32 { return Cross2(c.Sub(a), b.Sub(a)) }
+	pts := []Point2{from}
+	add := func(p Point2) {
+		if p != pts[len(pts)-1] {
+			pts = append(pts, p)
+		}
+	}
+	apex, left, right := from, from, from
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	for i := 1; i < len(portals); i++ {
+		l, r := portals[i][0], portals[i][1]
+
+		if side(apex, right, r) <= 0 {
+			if apex == right || side(apex, left, r) > 0 {
+				// Tighten the funnel
+				right, rightIndex = r, i
+			} else {
+				// The right side crosses the left, so the left point is a corner of the path
+				add(left)
+				apex, apexIndex = left, leftIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+
+		if side(apex, left, l) >= 0 {
+			if apex == left || side(apex, right, l) < 0 {
+				left, leftIndex = l, i
+			} else {
+				add(right)
+				apex, apexIndex = right, rightIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+	add(to)
+	if len(pts) == 1 {
+		// A path needs two points even when it goes nowhere
+		pts = append(pts, to)
+	}
+	return pts
+}
+
+type navNode struct {
+	tri int
+	f   float32 // cost so far plus the estimate of the cost to the goal
+}
+
+type navQueue []navNode
+
+func (q navQueue) Len() int           { return len(q) }
+func (q navQueue) Less(i, j int) bool { return q[i].f < q[j].f }
+func (q navQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *navQueue) Push(x any)        { *q = append(*q, x.(navNode)) }
+func (q *navQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// vec2d is a 2 dimensional vector with float64 components used where float32 loses too much
+// precision.
+type vec2d [2]float64
+
+func (a vec2d) add(b vec2d) vec2d     { return vec2d{a[0] + b[0], a[1] + b[1]} }
+func (a vec2d) sub(b vec2d) vec2d     { return vec2d{a[0] - b[0], a[1] - b[1]} }
+func (a vec2d) mul(s float64) vec2d   { return vec2d{a[0] * s, a[1] * s} }
+func (a vec2d) dot(b vec2d) float64   { return a[0]*b[0] + a[1]*b[1] }
+func (a vec2d) cross(b vec2d) float64 { return a[0]*b[1] - a[1]*b[0] }
+func (a vec2d) point() Point2         { return Point2{float32(a[0]), float32(a[1])} }
+func vec2dFromPoint(p Point2) vec2d   { return vec2d{float64(p[0]), float64(p[1])} }
+
+// cdt is a constrained Delaunay triangulation under construction. Its triangles wind counter
+// clockwise and nbr[t][i] is the triangle across the edge from tri[t][i] to tri[t][(i+1)%3],
+// or -1 on the outer boundary.
+type cdt struct {
+	pts   []vec2d
+	tri   [][3]int
+	nbr   [][3]int
+	vtri  []int           // a triangle using each point
+	fixed map[[2]int]bool // constrained edges, in both directions
+	last  int             // the triangle where the last point was found
+}
+
+// newCDT returns a triangulation of the rectangle with the given corners in counter clockwise
+// order.
+func newCDT(corners [4]vec2d) *cdt {
+	return &cdt{
+		pts:   corners[:],
+		tri:   [][3]int{{0, 1, 2}, {0, 2, 3}},
+		nbr:   [][3]int{{-1, -1, 1}, {0, -1, -1}},
+		vtri:  []int{0, 0, 0, 1},
+		fixed: make(map[[2]int]bool),
+	}
+}
+
+// orient returns twice the signed area of the triangle of points a, b and c, which is positive
+// when they are counter clockwise. The points come from float32 values so the result is exact
+// for coordinates of similar magnitude.
+func (c *cdt) orient(a, b, p int) float64 {
+	return c.pts[b].sub(c.pts[a]).cross(c.pts[p].sub(c.pts[a]))
+}
+
+// inCircle reports whether d lies inside the circle through the counter clockwise triangle
+// a, b, c.
+func (c *cdt) inCircle(a, b, cc, d int) bool {
+	pd := c.pts[d]
+	ad, bd, cd := c.pts[a].sub(pd), c.pts[b].sub(pd), c.pts[cc].sub(pd)
+	return ad.dot(ad)*bd.cross(cd)+bd.dot(bd)*cd.cross(ad)+cd.dot(cd)*ad.cross(bd) > 0
+}
+
+// addPoint inserts p and returns its index, or the index of an existing point at p.
+func (c *cdt) addPoint(p vec2d) (int, error) {
+	t := c.last
+	for steps := 0; ; steps++ {
+		if steps > 3*len(c.tri) {
+			return 0, errors.New("geom: navmesh point location failed")
+		}
+		v := c.tri[t]
+		next := -1
+		for i := 0; i < 3; i++ {
+			a, b := c.pts[v[i]], c.pts[v[(i+1)%3]]
+			if b.sub(a).cross(p.sub(a)) < 0 {
+				next = c.nbr[t][i]
+				if next < 0 {
+					return 0, fmt.Errorf("geom: navmesh point %v is outside the bounds", p.point())
+				}
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		t = next
+	}
+	c.last = t
+
+	v := c.tri[t]
+	for _, id := range v {
+		if c.pts[id] == p {
+			return id, nil
+		}
+	}
+	id := len(c.pts)
+	c.pts = append(c.pts, p)
+	c.vtri = append(c.vtri, t)
+	for i := 0; i < 3; i++ {
+		a, b := c.pts[v[i]], c.pts[v[(i+1)%3]]
+		if b.sub(a).cross(p.sub(a)) == 0 {
+			c.splitEdge(t, i, id)
+			return id, nil
+		}
+	}
+	c.splitTriangle(t, id)
+	return id, nil
+}
+
+// splitTriangle joins point p, inside triangle t, to the corners of t.
+func (c *cdt) splitTriangle(t, p int) {
+	v, n := c.tri[t], c.nbr[t]
+	a, b, cc := v[0], v[1], v[2]
+	t1, t2 := len(c.tri), len(c.tri)+1
+	c.tri[t], c.nbr[t] = [3]int{a, b, p}, [3]int{n[0], t1, t2}
+	c.tri = append(c.tri, [3]int{b, cc, p}, [3]int{cc, a, p})
+	c.nbr = append(c.nbr, [3]int{n[1], t2, t}, [3]int{n[2], t, t1})
+	c.replaceNeighbor(n[1], t, t1)
+	c.replaceNeighbor(n[2], t, t2)
+	c.vtri[a], c.vtri[b], c.vtri[cc], c.vtri[p] = t, t1, t2, t
+	c.legalize([][2]int{{t, 0}, {t1, 0}, {t2, 0}})
+}
+
+// splitEdge joins point p, on edge i of triangle t, to the opposite corners of t and of the
+// triangle on the other side of the edge.
+func (c *cdt) splitEdge(t, i, p int) {
+	v, n := c.tri[t], c.nbr[t]
+	a, b, cc := v[i], v[(i+1)%3], v[(i+2)%3]
+	u := n[i]
+	t1 := len(c.tri)
+	c.tri = append(c.tri, [3]int{a, p, cc})
+	c.nbr = append(c.nbr, [3]int{-1, t, n[(i+2)%3]})
+	c.tri[t], c.nbr[t] = [3]int{p, b, cc}, [3]int{u, n[(i+1)%3], t1}
+	c.replaceNeighbor(n[(i+2)%3], t, t1)
+	c.vtri[a], c.vtri[b], c.vtri[cc], c.vtri[p] = t1, t, t, t
+	edges := [][2]int{{t, 1}, {t1, 2}}
+
+	if u >= 0 {
+		j := c.index(u, b)
+		d := c.tri[u][(j+2)%3]
+		nad, ndb := c.nbr[u][(j+1)%3], c.nbr[u][(j+2)%3]
+		u1 := len(c.tri)
+		c.tri = append(c.tri, [3]int{p, a, d})
+		c.nbr = append(c.nbr, [3]int{t1, nad, u})
+		c.tri[u], c.nbr[u] = [3]int{b, p, d}, [3]int{t, u1, ndb}
+		c.nbr[t1][0] = u1
+		c.replaceNeighbor(nad, u, u1)
+		c.vtri[d] = u
+		edges = append(edges, [2]int{u, 2}, [2]int{u1, 1})
+	}
+	if c.fixed[[2]int{a, b}] {
+		delete(c.fixed, [2]int{a, b})
+		delete(c.fixed, [2]int{b, a})
+		c.fix(a, p)
+		c.fix(p, b)
+	}
+	c.legalize(edges)
+}
+
+// index returns the position of point p in triangle t.
+func (c *cdt) index(t, p int) int {
+	for i, v := range c.tri[t] {
+		if v == p {
+			return i
+		}
+	}
+	panic("geom: navmesh triangle does not contain point")
+}
+
+// replaceNeighbor changes the neighbor of t that is old to new.
+func (c *cdt) replaceNeighbor(t, old, new int) {
+	if t < 0 {
+		return
+	}
+	for i, n := range c.nbr[t] {
+		if n == old {
+			c.nbr[t][i] = new
+			return
+		}
+	}
+}
+
+func (c *cdt) fix(a, b int) {
+	c.fixed[[2]int{a, b}] = true
+	c.fixed[[2]int{b, a}] = true
+}
+
+// flippable reports whether edge i of triangle t can be flipped, returning the neighbor across
+// it and the position of the edge's start point in that neighbor.
+func (c *cdt) flippable(t, i int) (int, int, bool) {
+	v := c.tri[t]
+	a, b, cc := v[i], v[(i+1)%3], v[(i+2)%3]
+	u := c.nbr[t][i]
+	if u < 0 || c.fixed[[2]int{a, b}] {
+		return 0, 0, false
+	}
+	j := c.index(u, b)
+	d := c.tri[u][(j+2)%3]
+	// The two triangles must form a convex quadrilateral
+	return u, j, c.orient(cc, a, d) > 0 && c.orient(d, b, cc) > 0
+}
+
+// flip replaces edge i of triangle t, shared with triangle u where the edge starts at position
+// j, by the other diagonal of the quadrilateral they form.
+func (c *cdt) flip(t, i, u, j int) {
+	v := c.tri[t]
+	a, b, cc := v[i], v[(i+1)%3], v[(i+2)%3]
+	d := c.tri[u][(j+2)%3]
+	nbc, nca := c.nbr[t][(i+1)%3], c.nbr[t][(i+2)%3]
+	nad, ndb := c.nbr[u][(j+1)%3], c.nbr[u][(j+2)%3]
+	c.tri[t], c.nbr[t] = [3]int{cc, a, d}, [3]int{nca, nad, u}
+	c.tri[u], c.nbr[u] = [3]int{d, b, cc}, [3]int{ndb, nbc, t}
+	c.replaceNeighbor(nad, u, t)
+	c.replaceNeighbor(nbc, t, u)
+	c.vtri[a], c.vtri[b], c.vtri[cc], c.vtri[d] = t, u, t, u
+}
+
+// legalize flips the given edges, and the edges around them in turn, until none of them has a
+// point inside the circumcircle of the triangle on its other side.
+func (c *cdt) legalize(edges [][2]int) {
+	for len(edges) > 0 {
+		e := edges[len(edges)-1]
+		edges = edges[:len(edges)-1]
+		t, i := e[0], e[1]
+		u, j, ok := c.flippable(t, i)
+		if !ok {
+			continue
+		}
+		v := c.tri[t]
+		if !c.inCircle(v[0], v[1], v[2], c.tri[u][(j+2)%3]) {
+			continue
+		}
+		c.flip(t, i, u, j)
+		edges = append(edges, [2]int{t, 0}, [2]int{t, 1}, [2]int{u, 0}, [2]int{u, 1})
+	}
+}
+
+// delaunay restores the Delaunay condition to every unconstrained edge.
+func (c *cdt) delaunay() {
+	edges := make([][2]int, 0, 3*len(c.tri))
+	for t := range c.tri {
+		edges = append(edges, [2]int{t, 0}, [2]int{t, 1}, [2]int{t, 2})
+	}
+	c.legalize(edges)
+}
+
+// around returns the triangles that use point p.
+func (c *cdt) around(p int) []int {
+	t0 := c.vtri[p]
+	ts := []int{t0}
+	// Turn counter clockwise about p, then clockwise if that reached the boundary
+	for t := c.nbr[t0][(c.index(t0, p)+2)%3]; t != t0; t = c.nbr[t][(c.index(t, p)+2)%3] {
+		if t < 0 {
+			for t = c.nbr[t0][c.index(t0, p)]; t >= 0; t = c.nbr[t][c.index(t, p)] {
+				ts = append(ts, t)
+			}
+			break
+		}
+		ts = append(ts, t)
+	}
+	return ts
+}
+
+// findEdge returns the triangle holding the edge from a to b and the position of a in it.
+func (c *cdt) findEdge(a, b int) (int, int, bool) {
+	for _, t := range c.around(a) {
+		i := c.index(t, a)
+		if c.tri[t][(i+1)%3] == b {
+			return t, i, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (c *cdt) hasEdge(a, b int) bool {
+	_, _, ok := c.findEdge(a, b)
+	if !ok {
+		_, _, ok = c.findEdge(b, a)
+	}
+	return ok
+}
+
+// addConstraint makes the segment from a to b an edge of the triangulation, splitting it at
+// any points that lie on it.
+func (c *cdt) addConstraint(a, b int) error {
+	for a != b {
+		if c.hasEdge(a, b) {
+			c.fix(a, b)
+			return nil
+		}
+		end, crossing, err := c.crossing(a, b)
+		if err != nil {
+			return err
+		}
+		if err := c.clear(a, end, crossing); err != nil {
+			return err
+		}
+		c.fix(a, end)
+		a = end
+	}
+	return nil
+}
+
+// crossing returns the edges that the segment from a to b crosses, in order, stopping early at
+// any point that lies on the segment. It returns the point where it stopped.
+func (c *cdt) crossing(a, b int) (int, [][2]int, error) {
+	// Find the triangle around a that the segment leaves through, with x to the right of the
+	// segment and y to the left
+	t, i := -1, 0
+	for _, u := range c.around(a) {
+		k := c.index(u, a)
+		x, y := c.tri[u][(k+1)%3], c.tri[u][(k+2)%3]
+		for _, p := range [2]int{x, y} {
+			if c.orient(a, b, p) == 0 && c.pts[p].sub(c.pts[a]).dot(c.pts[b].sub(c.pts[a])) > 0 {
+				return p, nil, nil
+			}
+		}
+		if c.orient(a, b, x) < 0 && c.orient(a, b, y) > 0 {
+			t, i = u, (k+1)%3
+			break
+		}
+	}
+	if t < 0 {
+		return 0, nil, errors.New("geom: navmesh could not follow an obstacle edge")
+	}
+
+	var edges [][2]int
+	for {
+		x, y := c.tri[t][i], c.tri[t][(i+1)%3]
+		if c.fixed[[2]int{x, y}] {
+			return 0, nil, fmt.Errorf("geom: navmesh obstacle edges cross near %v", c.pts[x].point())
+		}
+		edges = append(edges, [2]int{x, y})
+		u := c.nbr[t][i]
+		if u < 0 {
+			return 0, nil, errors.New("geom: navmesh could not follow an obstacle edge")
+		}
+		j := c.index(u, y)
+		z := c.tri[u][(j+2)%3]
+		switch s := c.orient(a, b, z); {
+		case z == b || s == 0:
+			return z, edges, nil
+		case s > 0:
+			t, i = u, (j+1)%3
+		default:
+			t, i = u, (j+2)%3
+		}
+	}
+}
+
+// clear flips the edges crossed by the segment from a to b until none remain, using the
+// method of Sloan.
+func (c *cdt) clear(a, b int, edges [][2]int) error {
+	for n := 0; len(edges) > 0; n++ {
+		if n > 100*len(c.tri) {
+			return errors.New("geom: navmesh could not insert an obstacle edge")
+		}
+		e := edges[0]
+		edges = edges[1:]
+		t, i, ok := c.findEdge(e[0], e[1])
+		if !ok {
+			t, i, _ = c.findEdge(e[1], e[0])
+		}
+		u, j, ok := c.flippable(t, i)
+		if !ok {
+			// Another flip must change the quadrilateral first
+			edges = append(edges, e)
+			continue
+		}
+		c.flip(t, i, u, j)
+		// The new edge joins the two points opposite the old one
+		p, q := c.tri[t][0], c.tri[t][2]
+		if c.orient(a, b, p)*c.orient(a, b, q) < 0 {
+			edges = append(edges, [2]int{p, q})
+		}
+	}
+	return nil
+}
+
+// navMesh returns the triangles outside the obstacles as a NavMesh.
+func (c *cdt) navMesh(obstacles []Polygon2) *NavMesh {
+	// The constrained edges divide the triangles into regions that are either all inside an
+	// obstacle or all outside, so test one triangle of each region
+	walkable := make([]bool, len(c.tri))
+	seen := make([]bool, len(c.tri))
+	for t0 := range c.tri {
+		if seen[t0] {
+			continue
+		}
+		region := []int{t0}
+		seen[t0] = true
+		best, bestArea := t0, 0.0
+		for k := 0; k < len(region); k++ {
+			t := region[k]
+			v := c.tri[t]
+			if area := c.orient(v[0], v[1], v[2]); area > bestArea {
+				best, bestArea = t, area
+			}
+			for i, u := range c.nbr[t] {
+				if u >= 0 && !seen[u] && !c.fixed[[2]int{v[i], v[(i+1)%3]}] {
+					seen[u] = true
+					region = append(region, u)
+				}
+			}
+		}
+		v := c.tri[best]
+		centre := c.pts[v[0]].add(c.pts[v[1]]).add(c.pts[v[2]]).mul(1.0 / 3).point()
+		inside := false
+		for k := range obstacles {
+			if obstacles[k].ContainsPoint2(centre) {
+				inside = true
+				break
+			}
+		}
+		for _, t := range region {
+			walkable[t] = !inside
+		}
+	}
+
+	m := &NavMesh{}
+	triIndex := make([]int, len(c.tri))
+	ptIndex := make([]int, len(c.pts))
+	for i := range ptIndex {
+		ptIndex[i] = -1
+	}
+	for t, v := range c.tri {
+		triIndex[t] = -1
+		if !walkable[t] {
+			continue
+		}
+		triIndex[t] = len(m.Triangles)
+		var tri [3]int
+		for i, p := range v {
+			if ptIndex[p] < 0 {
+				ptIndex[p] = len(m.Points)
+				m.Points = append(m.Points, c.pts[p].point())
+			}
+			tri[i] = ptIndex[p]
+		}
+		m.Triangles = append(m.Triangles, tri)
+	}
+	for t, n := range c.nbr {
+		if triIndex[t] < 0 {
+			continue
+		}
+		var nbr [3]int
+		for i, u := range n {
+			nbr[i] = -1
+			if u >= 0 && triIndex[u] >= 0 {
+				nbr[i] = triIndex[u]
+			}
+		}
+		m.Neighbors = append(m.Neighbors, nbr)
+	}
+	return m
+}
@@ -0,0 +1,219 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func squarePolygon(c Point2, half float32) Polygon2 {
+	return Polygon2{Points: []Point2{
+		c.Add(Vec2{-half, -half}), c.Add(Vec2{half, -half}), c.Add(Vec2{half, half}), c.Add(Vec2{-half, half}),
+	}}
+}
+
+// checkNavMesh checks that the triangles of the mesh wind counter clockwise, that neighbors
+// agree about the edges they share and that the walkable area is as expected.
+func checkNavMesh(t *testing.T, m *NavMesh, area float32) {
+	t.Helper()
+	var total float32
+	for i, tri := range m.Triangles {
+		a, b, c := m.Points[tri[0]], m.Points[tri[1]], m.Points[tri[2]]
+		ta := Cross2(b.Sub(a), c.Sub(a)) / 2
+		if ta <= 0 {
+			t.Errorf("triangle %d has area %v", i, ta)
+		}
+		total += ta
+		for k, u := range m.Neighbors[i] {
+			if u < 0 {
+				continue
+			}
+			p, q := tri[k], tri[(k+1)%3]
+			found := false
+			for j, w := range m.Neighbors[u] {
+				if w == i && m.Triangles[u][j] == q && m.Triangles[u][(j+1)%3] == p {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("triangle %d edge %d: neighbor %d does not share it", i, k, u)
+			}
+		}
+	}
+	if abs(total-area) > 1e-3*area {
+		t.Errorf("got walkable area %v, wanted %v", total, area)
+	}
+}
+
+// checkDelaunay reports an error for any edge between neighbors that is not locally Delaunay,
+// other than the edges of the obstacles.
+func checkDelaunay(t *testing.T, m *NavMesh, obstacles []Polygon2) {
+	t.Helper()
+	fixed := map[[2]Point2]bool{}
+	for _, o := range obstacles {
+		for i, p := range o.Points {
+			q := o.Points[(i+1)%len(o.Points)]
+			fixed[[2]Point2{p, q}], fixed[[2]Point2{q, p}] = true, true
+		}
+	}
+	for i, tri := range m.Triangles {
+		for k, u := range m.Neighbors[i] {
+			if u < 0 || fixed[[2]Point2{m.Points[tri[k]], m.Points[tri[(k+1)%3]]}] {
+				continue
+			}
+			ut := m.Triangles[u]
+			for _, d := range ut {
+				if d == tri[0] || d == tri[1] || d == tri[2] {
+					continue
+				}
+				p := vec2dFromPoint(m.Points[d])
+				ad := vec2dFromPoint(m.Points[tri[0]]).sub(p)
+				bd := vec2dFromPoint(m.Points[tri[1]]).sub(p)
+				cd := vec2dFromPoint(m.Points[tri[2]]).sub(p)
+				det := ad.dot(ad)*bd.cross(cd) + bd.dot(bd)*cd.cross(ad) + cd.dot(cd)*ad.cross(bd)
+				scale := math.Max(ad.dot(ad), math.Max(bd.dot(bd), cd.dot(cd)))
+				if det > 1e-9*scale*scale {
+					t.Errorf("edge %d of triangle %d is not Delaunay", k, i)
+				}
+			}
+		}
+	}
+}
+
+func TestNewNavMesh(t *testing.T) {
+	bounds := Rect{Position: Point2{5, 5}, Size: Vec2{5, 5}}
+	ring := Polygon2{
+		Points: []Point2{{1, 1}, {4, 1}, {4, 4}, {1, 4}},
+		Holes:  [][]Point2{{{2, 2}, {2, 3}, {3, 3}, {3, 2}}},
+	}
+	testCases := []struct {
+		name      string
+		obstacles []Polygon2
+		area      float32
+		open      []Point2
+		blocked   []Point2
+	}{
+		{name: "empty", area: 100},
+		{name: "square", obstacles: []Polygon2{squarePolygon(Point2{5, 5}, 1)}, area: 96, open: []Point2{{5, 3}}, blocked: []Point2{{5, 5}}},
+		{name: "touching bounds", obstacles: []Polygon2{{Points: []Point2{{0, 4}, {3, 4}, {3, 6}, {0, 6}}}}, area: 94},
+		{name: "touching each other", obstacles: []Polygon2{squarePolygon(Point2{3, 3}, 1), squarePolygon(Point2{5, 3}, 1)}, area: 92},
+		{name: "sharing part of an edge", obstacles: []Polygon2{squarePolygon(Point2{2, 2}, 1), squarePolygon(Point2{4, 3}, 1)}, area: 92, blocked: []Point2{{3, 2.5}}},
+		{name: "hole", obstacles: []Polygon2{ring}, area: 92, open: []Point2{{2.5, 2.5}}, blocked: []Point2{{1.5, 2.5}}},
+		{
+			name: "concave",
+			obstacles: []Polygon2{{Points: []Point2{
+				{2, 2}, {8, 2}, {8, 8}, {7, 8}, {7, 3}, {3, 3}, {3, 8}, {2, 8},
+			}}},
+			area:    84,
+			open:    []Point2{{5, 5}},
+			blocked: []Point2{{2.5, 5}, {5, 2.5}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewNavMesh(bounds, tc.obstacles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkNavMesh(t, m, tc.area)
+			checkDelaunay(t, m, tc.obstacles)
+			for _, p := range tc.open {
+				if _, ok := m.Locate(p); !ok {
+					t.Errorf("point %v is not walkable", p)
+				}
+			}
+			for _, p := range tc.blocked {
+				if _, ok := m.Locate(p); ok {
+					t.Errorf("point %v is walkable", p)
+				}
+			}
+		})
+	}
+}
+
+func TestNewNavMeshRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bounds := Rect{Position: Point2{50, 50}, Size: Vec2{50, 50}}
+	for iter := 0; iter < 20; iter++ {
+		// Squares on a grid so that they never overlap
+		var obstacles []Polygon2
+		area := float32(10000)
+		for x := 0; x < 10; x++ {
+			for y := 0; y < 10; y++ {
+				if r.Intn(3) != 0 {
+					continue
+				}
+				half := 1 + 3*r.Float32()
+				c := Point2{float32(x)*10 + 5, float32(y)*10 + 5}
+				obstacles = append(obstacles, squarePolygon(c, half))
+				area -= 4 * half * half
+			}
+		}
+		m, err := NewNavMesh(bounds, obstacles)
+		if err != nil {
+			t.Fatalf("iteration %d: %v", iter, err)
+		}
+		checkNavMesh(t, m, area)
+		checkDelaunay(t, m, obstacles)
+	}
+}
+
+func TestNewNavMeshErrors(t *testing.T) {
+	bounds := Rect{Position: Point2{5, 5}, Size: Vec2{5, 5}}
+	testCases := []struct {
+		name      string
+		bounds    Rect
+		obstacles []Polygon2
+	}{
+		{name: "empty bounds", bounds: Rect{Position: Point2{5, 5}}},
+		{name: "outside", bounds: bounds, obstacles: []Polygon2{squarePolygon(Point2{10, 5}, 1)}},
+		{name: "too few points", bounds: bounds, obstacles: []Polygon2{{Points: []Point2{{1, 1}, {2, 2}}}}},
+		{name: "crossing", bounds: bounds, obstacles: []Polygon2{squarePolygon(Point2{4, 4}, 2), squarePolygon(Point2{6, 6}, 2)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewNavMesh(tc.bounds, tc.obstacles); err == nil {
+				t.Errorf("got no error")
+			}
+		})
+	}
+}
+
+func TestNavMeshFindPath(t *testing.T) {
+	bounds := Rect{Position: Point2{5, 5}, Size: Vec2{5, 5}}
+	block := squarePolygon(Point2{5, 5}, 1)
+	m, err := NewNavMesh(bounds, []Polygon2{block})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := m.FindPath(Point2{1, 5}, Point2{9, 5})
+	if !ok {
+		t.Fatalf("got no path")
+	}
+	// Around two corners of the block
+	if len(p.Points) != 4 {
+		t.Errorf("got points %v, wanted four", p.Points)
+	}
+	if want := 2*(Point2{3, 1}).Len() + 2; abs(p.length-want) > 1e-4 {
+		t.Errorf("got length %v, wanted %v", p.length, want)
+	}
+
+	p, ok = m.FindPath(Point2{1, 1}, Point2{9, 2})
+	if !ok || len(p.Points) != 2 {
+		t.Errorf("clear line of sight: got %v, %v", p, ok)
+	}
+	if _, ok := m.FindPath(Point2{1, 1}, Point2{5, 5}); ok {
+		t.Errorf("got a path into the obstacle")
+	}
+
+	// A wall across the whole space leaves no way through
+	wall := Polygon2{Points: []Point2{{4, 0}, {6, 0}, {6, 10}, {4, 10}}}
+	m, err = NewNavMesh(bounds, []Polygon2{wall})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.FindPath(Point2{1, 5}, Point2{9, 5}); ok {
+		t.Errorf("got a path through a wall")
+	}
+}
@@ -0,0 +1,180 @@
+package geom
+
+import "sort"
+
+// PackedRect is the placement of a rectangle by a RectPacker.
+type PackedRect struct {
+	Bin      int     // index of the bin the rectangle was placed in
+	Position Point2i // top left corner of the rectangle within the bin
+	Size     Vec2i   // width and height of the rectangle as placed, after any rotation
+	Rotated  bool    // whether the rectangle was rotated by 90 degrees to fit
+}
+
+// Rect returns the area covered by the placed rectangle within its bin.
+func (p PackedRect) Rect() Rect {
+	return RectFromCorners(p.Position.Vec2(), p.Position.Add(p.Size).Vec2())
+}
+
+// RectPacker places rectangles into fixed size bins, such as the pages of a texture atlas,
+// using the MaxRects algorithm with the best short side fit heuristic. A new bin is started
+// whenever a rectangle does not fit into any existing bin.
+type RectPacker struct {
+	width, height int32
+	allowRotate   bool
+	bins          [][]packRect // free areas of each bin
+}
+
+// packRect is a rectangle stored by its top left corner and full size.
+type packRect struct {
+	x, y, w, h int32
+}
+
+func (r packRect) contains(o packRect) bool {
+	return o.x >= r.x && o.y >= r.y && o.x+o.w <= r.x+r.w && o.y+o.h <= r.y+r.h
+}
+
+func (r packRect) intersects(o packRect) bool {
+	return o.x < r.x+r.w && o.x+o.w > r.x && o.y < r.y+r.h && o.y+o.h > r.y
+}
+
+// NewRectPacker returns a packer that places rectangles into bins of the given width and
+// height. When allowRotate is true rectangles may be turned by 90 degrees to fit better.
+func NewRectPacker(width, height int32, allowRotate bool) *RectPacker {
+	return &RectPacker{width: width, height: height, allowRotate: allowRotate}
+}
+
+// Bins returns the number of bins that have been started.
+func (p *RectPacker) Bins() int {
+	return len(p.bins)
+}
+
+// Insert places a rectangle with the given width and height. It returns false if the rectangle
+// is too large to fit in an empty bin.
+func (p *RectPacker) Insert(size Vec2i) (PackedRect, bool) {
+	if size[0] <= 0 || size[1] <= 0 {
+		return PackedRect{}, false
+	}
+	fits := size[0] <= p.width && size[1] <= p.height
+	if p.allowRotate {
+		fits = fits || (size[1] <= p.width && size[0] <= p.height)
+	}
+	if !fits {
+		return PackedRect{}, false
+	}
+
+	for bin := range p.bins {
+		if pr, ok := p.insertInto(bin, size); ok {
+			return pr, true
+		}
+	}
+
+	p.bins = append(p.bins, []packRect{{w: p.width, h: p.height}})
+	return p.insertInto(len(p.bins)-1, size)
+}
+
+// insertInto places a rectangle in the given bin if there is room.
+func (p *RectPacker) insertInto(bin int, size Vec2i) (PackedRect, bool) {
+	free := p.bins[bin]
+
+	var best packRect
+	bestShort, bestLong := int32(-1), int32(-1)
+	rotated := false
+
+	try := func(w, h int32, rot bool) {
+		for _, f := range free {
+			if w > f.w || h > f.h {
+				continue
+			}
+			short, long := f.w-w, f.h-h
+			if short > long {
+				short, long = long, short
+			}
+			if bestShort < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+				best = packRect{x: f.x, y: f.y, w: w, h: h}
+				bestShort, bestLong = short, long
+				rotated = rot
+			}
+		}
+	}
+	try(size[0], size[1], false)
+	if p.allowRotate && size[0] != size[1] {
+		try(size[1], size[0], true)
+	}
+	if bestShort < 0 {
+		return PackedRect{}, false
+	}
+
+	p.bins[bin] = splitFree(free, best)
+	return PackedRect{
+		Bin:      bin,
+		Position: Point2i{best.x, best.y},
+		Size:     Vec2i{best.w, best.h},
+		Rotated:  rotated,
+	}, true
+}
+
+// splitFree removes the area used by placed from the free rectangles, replacing each free
+// rectangle it overlaps by the maximal rectangles that remain around it.
+func splitFree(free []packRect, placed packRect) []packRect {
+	res := make([]packRect, 0, len(free)+4)
+	for _, f := range free {
+		if !f.intersects(placed) {
+			res = append(res, f)
+			continue
+		}
+		if placed.x > f.x {
+			res = append(res, packRect{x: f.x, y: f.y, w: placed.x - f.x, h: f.h})
+		}
+		if placed.x+placed.w < f.x+f.w {
+			res = append(res, packRect{x: placed.x + placed.w, y: f.y, w: f.x + f.w - placed.x - placed.w, h: f.h})
+		}
+		if placed.y > f.y {
+			res = append(res, packRect{x: f.x, y: f.y, w: f.w, h: placed.y - f.y})
+		}
+		if placed.y+placed.h < f.y+f.h {
+			res = append(res, packRect{x: f.x, y: placed.y + placed.h, w: f.w, h: f.y + f.h - placed.y - placed.h})
+		}
+	}
+
+	// Drop free rectangles that lie within another
+	pruned := res[:0]
+	for i, r := range res {
+		redundant := false
+		for j, o := range res {
+			if i != j && o.contains(r) && (r != o || j < i) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			pruned = append(pruned, r)
+		}
+	}
+	return pruned
+}
+
+// PackRects places rectangles of the given sizes into as few bins of the given width and
+// height as it can. Larger rectangles are placed first, which generally gives a tighter
+// packing than inserting them in order. The placements are returned in the same order as
+// sizes. It returns false if any rectangle is too large to fit in an empty bin.
+func PackRects(width, height int32, sizes []Vec2i, allowRotate bool) ([]PackedRect, bool) {
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := sizes[order[i]], sizes[order[j]]
+		return maxi(a[0], a[1]) > maxi(b[0], b[1])
+	})
+
+	p := NewRectPacker(width, height, allowRotate)
+	res := make([]PackedRect, len(sizes))
+	for _, i := range order {
+		pr, ok := p.Insert(sizes[i])
+		if !ok {
+			return nil, false
+		}
+		res[i] = pr
+	}
+	return res, true
+}
@@ -0,0 +1,86 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkPacking verifies that every placement lies within its bin and that no two placements
+// in the same bin overlap.
+func checkPacking(t *testing.T, width, height int32, sizes []Vec2i, placed []PackedRect) {
+	t.Helper()
+	for i, p := range placed {
+		if p.Position[0] < 0 || p.Position[1] < 0 || p.Position[0]+p.Size[0] > width || p.Position[1]+p.Size[1] > height {
+			t.Fatalf("rect %d at %v size %v is outside the bin", i, p.Position, p.Size)
+		}
+		want := sizes[i]
+		if p.Rotated {
+			want = Vec2i{want[1], want[0]}
+		}
+		if p.Size != want {
+			t.Fatalf("rect %d: got size %v, wanted %v", i, p.Size, want)
+		}
+		a := packRect{x: p.Position[0], y: p.Position[1], w: p.Size[0], h: p.Size[1]}
+		for j := i + 1; j < len(placed); j++ {
+			q := placed[j]
+			b := packRect{x: q.Position[0], y: q.Position[1], w: q.Size[0], h: q.Size[1]}
+			if p.Bin == q.Bin && a.intersects(b) {
+				t.Fatalf("rects %d and %d overlap", i, j)
+			}
+		}
+	}
+}
+
+func TestPackRects(t *testing.T) {
+	// Four quarters exactly fill the bin
+	sizes := []Vec2i{{32, 32}, {32, 32}, {32, 32}, {32, 32}}
+	placed, ok := PackRects(64, 64, sizes, false)
+	if !ok {
+		t.Fatalf("packing failed")
+	}
+	checkPacking(t, 64, 64, sizes, placed)
+	for i, p := range placed {
+		if p.Bin != 0 {
+			t.Errorf("rect %d: got bin %d, wanted 0", i, p.Bin)
+		}
+	}
+
+	// A tall rect only fits when rotated
+	sizes = []Vec2i{{10, 100}}
+	if _, ok := PackRects(100, 50, sizes, false); ok {
+		t.Errorf("tall rect should not fit without rotation")
+	}
+	placed, ok = PackRects(100, 50, sizes, true)
+	if !ok || !placed[0].Rotated {
+		t.Errorf("tall rect should fit when rotated, got %v, %v", placed, ok)
+	}
+
+	// Random sizes spill over into multiple bins
+	r := rand.New(rand.NewSource(1))
+	sizes = make([]Vec2i, 200)
+	for i := range sizes {
+		sizes[i] = Vec2i{r.Int31n(60) + 1, r.Int31n(60) + 1}
+	}
+	placed, ok = PackRects(256, 256, sizes, true)
+	if !ok {
+		t.Fatalf("packing failed")
+	}
+	checkPacking(t, 256, 256, sizes, placed)
+}
+
+func TestRectPackerInsert(t *testing.T) {
+	p := NewRectPacker(64, 64, false)
+	if _, ok := p.Insert(Vec2i{65, 1}); ok {
+		t.Errorf("oversized rect should not fit")
+	}
+
+	a, _ := p.Insert(Vec2i{64, 40})
+	b, _ := p.Insert(Vec2i{64, 24})
+	c, _ := p.Insert(Vec2i{1, 1})
+	if a.Bin != 0 || b.Bin != 0 || c.Bin != 1 || p.Bins() != 2 {
+		t.Errorf("got bins %d, %d, %d of %d, wanted 0, 0, 1 of 2", a.Bin, b.Bin, c.Bin, p.Bins())
+	}
+	if want := RectFromCorners(Point2{0, 40}, Point2{64, 64}); b.Rect() != want {
+		t.Errorf("got rect %v, wanted %v", b.Rect(), want)
+	}
+}
@@ -0,0 +1,445 @@
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// Parabola3 is the path of a projectile that leaves Origin with Velocity and is accelerated by
+// Gravity, ignoring drag. At time t it is at Origin + Velocity*t + Gravity*t²/2.
+//
+// The intersection methods return a RaycastResult in the same way as Raycast, except that
+// Distance holds the time at which the path meets the shape rather than a distance. Only
+// times of zero or more are considered.
+type Parabola3 struct {
+	Origin   Point3
+	Velocity Vec3
+	Gravity  Vec3
+}
+
+// PointAt returns the position at time t.
+func (p Parabola3) PointAt(t float32) Point3 {
+	return p.Origin.Add(p.Velocity.Mul(t)).Add(p.Gravity.Mul(t * t / 2))
+}
+
+// VelocityAt returns the velocity at time t.
+func (p Parabola3) VelocityAt(t float32) Vec3 {
+	return p.Velocity.Add(p.Gravity.Mul(t))
+}
+
+// IntersectPlane3 returns where the path first crosses the plane, from either side. The normal
+// of the result faces the side the path comes from.
+func (p Parabola3) IntersectPlane3(pl *Plane3) (RaycastResult, bool) {
+	var res RaycastResult
+	n := pl.Normal
+	d0 := SignedDistancePointPlane3(p.Origin, pl)
+	roots := polyRoots([]float64{float64(d0), float64(n.Dot(p.Velocity)), float64(n.Dot(p.Gravity)) / 2}, 0, math.Inf(1))
+	if len(roots) == 0 {
+		res.Fail = RaycastFailOutsideBounds
+		if n.Dot(p.Velocity) == 0 && n.Dot(p.Gravity) == 0 {
+			// The path runs parallel to the plane
+			res.Fail = RaycastFailPlaneFacesAwayFromRay
+		}
+		return res, false
+	}
+	t := float32(roots[0])
+	res.Distance = t
+	res.Point = p.PointAt(t)
+	res.Normal = n
+	if d0 < 0 || (d0 == 0 && n.Dot(p.Velocity) > 0) {
+		res.Normal = n.Mul(-1)
+	}
+	return res, true
+}
+
+// IntersectSphere returns where the path first enters the sphere. A path that starts inside
+// the sphere meets it at time zero.
+func (p Parabola3) IntersectSphere(s *Sphere) (RaycastResult, bool) {
+	var res RaycastResult
+	// |m + v t + g t²/2|² - r² as a quartic in t
+	m, v, g := p.Origin.Sub(s.Position), p.Velocity, p.Gravity.Mul(0.5)
+	c := []float64{
+		float64(m.Dot(m) - s.Radius*s.Radius),
+		float64(2 * m.Dot(v)),
+		float64(v.Dot(v) + 2*m.Dot(g)),
+		float64(2 * v.Dot(g)),
+		float64(g.Dot(g)),
+	}
+
+	t := float32(-1)
+	if c[0] <= 0 {
+		t = 0
+	} else if roots := polyRoots(c, 0, polyRootBound(c)); len(roots) > 0 {
+		t = float32(roots[0])
+	}
+	if t < 0 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+	res.Distance = t
+	res.Point = p.PointAt(t)
+	if n := res.Point.Sub(s.Position); n.LenSqr() > 0 {
+		res.Normal = n.Normalize()
+	}
+	return res, true
+}
+
+// IntersectAABB returns where the path first enters the box. A path that starts inside the box
+// meets it at time zero with a zero normal.
+func (p Parabola3) IntersectAABB(a *AABB) (RaycastResult, bool) {
+	var res RaycastResult
+	if a.ContainsPoint3(p.Origin) {
+		res.Point = p.Origin
+		return res, true
+	}
+
+	// The path can only enter the box where it crosses the plane of one of its faces
+	bmin, bmax := a.Min(), a.Max()
+	type crossing struct {
+		t      float32
+		axis   int
+		normal float32
+	}
+	var crossings []crossing
+	for i := 0; i < 3; i++ {
+		for _, face := range [2]float32{bmin[i], bmax[i]} {
+			c := []float64{float64(p.Origin[i] - face), float64(p.Velocity[i]), float64(p.Gravity[i]) / 2}
+			for _, r := range polyRoots(c, 0, math.Inf(1)) {
+				normal := float32(-1)
+				if face == bmax[i] {
+					normal = 1
+				}
+				crossings = append(crossings, crossing{t: float32(r), axis: i, normal: normal})
+			}
+		}
+	}
+	sort.Slice(crossings, func(i, j int) bool { return crossings[i].t < crossings[j].t })
+
+	for _, c := range crossings {
+		pt := p.PointAt(c.t)
+		inside := true
+		for j := 0; j < 3; j++ {
+			if j == c.axis {
+				continue
+			}
+			tol := epsilon32 * max(1, abs(pt[j]))
+			if pt[j] < bmin[j]-tol || pt[j] > bmax[j]+tol {
+				inside = false
+				break
+			}
+		}
+		// The path must be moving into the box through the face
+		if !inside || p.VelocityAt(c.t)[c.axis]*c.normal > 0 {
+			continue
+		}
+		res.Distance = c.t
+		res.Point = pt
+		res.Normal[c.axis] = c.normal
+		return res, true
+	}
+	res.Fail = RaycastFailOutsideBounds
+	return res, false
+}
+
+// IntersectGrid2 returns where the path first meets the terrain described by the heightfield g.
+// The grid lies in the XZ plane, with its x axis along X and its y axis along Z, and each cell
+// holds the height in Y of the terrain at the centre of the cell. Heights are interpolated
+// bilinearly between the centres and the outer half of each edge cell is flat, so the terrain
+// covers the whole of g.Bounds(). Everything beneath the terrain is solid: a path that starts
+// below it meets it at time zero with a zero normal, and a path that flies into the grid below
+// it meets the side of the grid.
+//
+// The path is followed in steps of at most a cell and each crossing found is refined by
+// bisection, so a ridge much narrower than a cell that the path only clips may be missed.
+func (p Parabola3) IntersectGrid2(g *Grid2[float32]) (RaycastResult, bool) {
+	var res RaycastResult
+	if len(g.Cells) == 0 {
+		res.Fail = RaycastFailOutsideBounds
+		return res, false
+	}
+	b := g.Bounds()
+	bmin, bmax := b.Min(), b.Max()
+
+	// above returns the height of the path above the terrain at time t
+	above := func(t float32) float32 {
+		pt := p.PointAt(t)
+		h, _ := gridHeight(g, Point2{pt[0], pt[2]})
+		return pt[1] - h
+	}
+	hit := func(t float32) (RaycastResult, bool) {
+		res.Distance = t
+		res.Point = p.PointAt(t)
+		_, grad := gridHeight(g, Point2{res.Point[0], res.Point[2]})
+		res.Normal = Vec3{-grad[0], 1, -grad[1]}.Normalize()
+		return res, true
+	}
+
+	half := g.CellSize / 2
+	gl := p.Gravity.Len()
+	for _, span := range p.spansOverRect(bmin, bmax) {
+		t0, t1 := span[0], span[1]
+		if above(t0) <= 0 {
+			res.Distance = t0
+			res.Point = p.PointAt(t0)
+			if t0 > 0 {
+				res.Normal = rectSideNormal(Point2{res.Point[0], res.Point[2]}, bmin, bmax)
+			}
+			return res, true
+		}
+
+		if math.IsInf(float64(t1), 1) {
+			// The path only stays over the grid for ever if it moves straight up and down, so
+			// the terrain beneath it has a single height
+			h := p.PointAt(t0)[1] - above(t0)
+			roots := polyRoots([]float64{float64(p.Origin[1] - h), float64(p.Velocity[1]), float64(p.Gravity[1]) / 2}, float64(t0), math.Inf(1))
+			if len(roots) > 0 {
+				return hit(float32(roots[0]))
+			}
+			continue
+		}
+
+		// March along the path no more than a cell at a time, which is half a cell from its
+		// speed and half a cell from its acceleration
+		t := t0
+		for t < t1 {
+			dt := t1 - t
+			if s := p.VelocityAt(t).Len(); s > 0 {
+				dt = min(dt, half/s)
+			}
+			if gl > 0 {
+				dt = min(dt, sqrt(2*half/gl))
+			}
+			next := t + dt
+			if next <= t {
+				break
+			}
+			if above(next) <= 0 {
+				lo, hi := t, next
+				for i := 0; i < 32; i++ {
+					mid := lo + (hi-lo)/2
+					if mid <= lo || mid >= hi {
+						break
+					}
+					if above(mid) > 0 {
+						lo = mid
+					} else {
+						hi = mid
+					}
+				}
+				return hit(hi)
+			}
+			t = next
+		}
+	}
+	res.Fail = RaycastFailOutsideBounds
+	return res, false
+}
+
+// spansOverRect returns the intervals of time, from zero on, during which the path lies over
+// the rectangle from lo to hi in the XZ plane. The last interval ends at infinity if the path
+// never leaves the rectangle.
+func (p Parabola3) spansOverRect(lo, hi Point2) [][2]float32 {
+	times := []float64{0}
+	for i, axis := range [2]int{0, 2} {
+		for _, edge := range [2]float32{lo[i], hi[i]} {
+			c := []float64{float64(p.Origin[axis] - edge), float64(p.Velocity[axis]), float64(p.Gravity[axis]) / 2}
+			times = append(times, polyRoots(c, 0, math.Inf(1))...)
+		}
+	}
+	sort.Float64s(times)
+	times = append(times, math.Inf(1))
+
+	var spans [][2]float32
+	for i := 0; i+1 < len(times); i++ {
+		t0, t1 := times[i], times[i+1]
+		if t1 <= t0 {
+			continue
+		}
+		mid := t0 + 1
+		if !math.IsInf(t1, 1) {
+			mid = t0 + (t1-t0)/2
+		}
+		pt := p.PointAt(float32(mid))
+		if pt[0] < lo[0] || pt[0] > hi[0] || pt[2] < lo[1] || pt[2] > hi[1] {
+			continue
+		}
+		if n := len(spans); n > 0 && float64(spans[n-1][1]) == t0 {
+			spans[n-1][1] = float32(t1)
+			continue
+		}
+		spans = append(spans, [2]float32{float32(t0), float32(t1)})
+	}
+	return spans
+}
+
+// rectSideNormal returns the outward normal in the XZ plane of the side of the rectangle from lo
+// to hi that p is nearest.
+func rectSideNormal(p, lo, hi Point2) Vec3 {
+	best := abs(p[0] - lo[0])
+	n := Vec3{-1, 0, 0}
+	for _, side := range [...]struct {
+		d float32
+		n Vec3
+	}{
+		{abs(p[0] - hi[0]), Vec3{1, 0, 0}},
+		{abs(p[1] - lo[1]), Vec3{0, 0, -1}},
+		{abs(p[1] - hi[1]), Vec3{0, 0, 1}},
+	} {
+		if side.d < best {
+			best, n = side.d, side.n
+		}
+	}
+	return n
+}
+
+// gridHeight returns the height held by the heightfield g at p, interpolated bilinearly between
+// the centres of its cells, and the gradient of the height. Beyond the outermost centres the
+// height is constant.
+func gridHeight(g *Grid2[float32], p Point2) (float32, Vec2) {
+	var idx [2]int
+	var frac [2]float32
+	var sloped [2]bool
+	for i := 0; i < 2; i++ {
+		u := (p[i]-g.Origin[i])/g.CellSize - 0.5
+		n := g.Dims[i]
+		switch {
+		case n == 1 || u <= 0:
+		case u >= float32(n-1):
+			idx[i], frac[i] = n-2, 1
+		default:
+			idx[i] = int(u)
+			if idx[i] > n-2 {
+				idx[i] = n - 2
+			}
+			frac[i] = u - float32(idx[i])
+			sloped[i] = true
+		}
+	}
+	x0, y0 := idx[0], idx[1]
+	x1, y1 := x0, y0
+	if g.Dims[0] > 1 {
+		x1++
+	}
+	if g.Dims[1] > 1 {
+		y1++
+	}
+	h00, h10 := g.At(x0, y0), g.At(x1, y0)
+	h01, h11 := g.At(x0, y1), g.At(x1, y1)
+
+	fx, fy := frac[0], frac[1]
+	h0 := h00 + (h10-h00)*fx
+	h1 := h01 + (h11-h01)*fx
+	var grad Vec2
+	if sloped[0] {
+		grad[0] = ((h10-h00)*(1-fy) + (h11-h01)*fy) / g.CellSize
+	}
+	if sloped[1] {
+		grad[1] = (h1 - h0) / g.CellSize
+	}
+	return h0 + (h1-h0)*fy, grad
+}
+
+// polyRoots returns the real roots in [lo, hi] of the polynomial whose coefficient of t^i is
+// c[i], in increasing order. Roots where the polynomial touches zero without changing sign may
+// be missed.
+func polyRoots(c []float64, lo, hi float64) []float64 {
+	// Drop leading coefficients that are zero
+	n := len(c) - 1
+	for n >= 0 && c[n] == 0 {
+		n--
+	}
+	c = c[:n+1]
+
+	var roots []float64
+	add := func(r float64) {
+		if r >= lo && r <= hi {
+			roots = append(roots, r)
+		}
+	}
+	switch n {
+	case -1, 0:
+		return nil
+	case 1:
+		add(-c[0] / c[1])
+		return roots
+	case 2:
+		a, b, cc := c[2], c[1], c[0]
+		disc := b*b - 4*a*cc
+		if disc < 0 {
+			return nil
+		}
+		// Avoid cancellation by computing the larger root first
+		q := -0.5 * (b + math.Copysign(math.Sqrt(disc), b))
+		r0, r1 := q/a, cc/q
+		if q == 0 {
+			r0, r1 = 0, 0
+		}
+		if r0 > r1 {
+			r0, r1 = r1, r0
+		}
+		add(r0)
+		if r1 != r0 {
+			add(r1)
+		}
+		return roots
+	}
+
+	// The polynomial is monotonic between the roots of its derivative, so each interval holds
+	// at most one root, found by bisection
+	if math.IsInf(hi, 1) {
+		hi = math.Max(lo, polyRootBound(c))
+	}
+	deriv := make([]float64, n)
+	for i := 1; i <= n; i++ {
+		deriv[i-1] = float64(i) * c[i]
+	}
+	bounds := append(append([]float64{lo}, polyRoots(deriv, lo, hi)...), hi)
+	for i := 0; i+1 < len(bounds); i++ {
+		a, b := bounds[i], bounds[i+1]
+		fa, fb := polyEval(c, a), polyEval(c, b)
+		if fa == 0 {
+			if len(roots) == 0 || roots[len(roots)-1] != a {
+				roots = append(roots, a)
+			}
+			continue
+		}
+		if fb == 0 || (fa < 0) == (fb < 0) {
+			continue
+		}
+		for k := 0; k < 100 && b-a > 1e-12*math.Max(1, math.Abs(a)); k++ {
+			mid := (a + b) / 2
+			if fm := polyEval(c, mid); (fm < 0) == (fa < 0) {
+				a, fa = mid, fm
+			} else {
+				b = mid
+			}
+		}
+		roots = append(roots, (a+b)/2)
+	}
+	if f := polyEval(c, hi); f == 0 && (len(roots) == 0 || roots[len(roots)-1] != hi) {
+		roots = append(roots, hi)
+	}
+	return roots
+}
+
+// polyEval evaluates the polynomial whose coefficient of t^i is c[i] at t.
+func polyEval(c []float64, t float64) float64 {
+	var v float64
+	for i := len(c) - 1; i >= 0; i-- {
+		v = v*t + c[i]
+	}
+	return v
+}
+
+// polyRootBound returns a bound on the magnitude of the real roots of the polynomial.
+func polyRootBound(c []float64) float64 {
+	n := len(c) - 1
+	for n > 0 && c[n] == 0 {
+		n--
+	}
+	b := 0.0
+	for i := 0; i < n; i++ {
+		b = math.Max(b, math.Abs(c[i]/c[n]))
+	}
+	return 1 + b
+}
@@ -0,0 +1,234 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParabola3(t *testing.T) {
+	p := Parabola3{Origin: Point3{0, 2, 0}, Velocity: Vec3{3, 4, 0}, Gravity: Vec3{0, -2, 0}}
+	if got := p.PointAt(2); !nearVec3(got, Point3{6, 6, 0}, 1e-6) {
+		t.Errorf("PointAt: got %v", got)
+	}
+	if got := p.VelocityAt(2); !nearVec3(got, Vec3{3, 0, 0}, 1e-6) {
+		t.Errorf("VelocityAt: got %v", got)
+	}
+
+	// y = 2 + 4t - t² meets the ground at t = 2 + sqrt(6)
+	ground := Plane3{Normal: Vec3{0, 1, 0}}
+	landing := 2 + float32(math.Sqrt(6))
+
+	// terrain returns a heightfield along the path from x0 to x1, with the height of each cell
+	// given by its x
+	terrain := func(x0, x1 float32, height func(x float32) float32) *Grid2[float32] {
+		g := NewGrid2[float32](Point2{x0, -2}, 0.5, int((x1-x0)/0.5), 8)
+		for y := 0; y < g.Dims[1]; y++ {
+			for x := 0; x < g.Dims[0]; x++ {
+				g.Set(x, y, height(g.CellCentre(x, y)[0]))
+			}
+		}
+		return g
+	}
+	flat := func(h float32) func(float32) float32 { return func(float32) float32 { return h } }
+
+	testCases := []struct {
+		name   string
+		hit    func() (RaycastResult, bool)
+		want   bool
+		time   float32
+		normal Vec3
+		fail   RaycastFail
+	}{
+		{
+			name:   "ground",
+			hit:    func() (RaycastResult, bool) { return p.IntersectPlane3(&ground) },
+			want:   true,
+			time:   landing,
+			normal: Vec3{0, 1, 0},
+		},
+		{
+			name: "ceiling above apex",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectPlane3(&Plane3{Normal: Vec3{0, -1, 0}, Distance: -7})
+			},
+			fail: RaycastFailOutsideBounds,
+		},
+		{
+			name: "parallel plane",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectPlane3(&Plane3{Normal: Vec3{0, 0, 1}, Distance: 1})
+			},
+			fail: RaycastFailPlaneFacesAwayFromRay,
+		},
+		{
+			name: "ceiling below apex",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectPlane3(&Plane3{Normal: Vec3{0, -1, 0}, Distance: -5})
+			},
+			want:   true,
+			time:   1,
+			normal: Vec3{0, -1, 0},
+		},
+		{
+			name: "wall",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectAABB(&AABB{Position: Point3{10, 0, 0}, Size: Vec3{1, 10, 1}})
+			},
+			want:   true,
+			time:   3,
+			normal: Vec3{-1, 0, 0},
+		},
+		{
+			name: "lands on box",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectAABB(&AABB{Position: Point3{12, 0, 0}, Size: Vec3{2, 1, 1}})
+			},
+			want:   true,
+			time:   2 + float32(math.Sqrt(5)),
+			normal: Vec3{0, 1, 0},
+		},
+		{
+			name: "over box",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectAABB(&AABB{Position: Point3{6, 0, 0}, Size: Vec3{1, 1, 1}})
+			},
+		},
+		{
+			name: "under arc box",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectAABB(&AABB{Position: Point3{6, 8, 0}, Size: Vec3{1, 1, 1}})
+			},
+		},
+		{
+			name: "starts inside box",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectAABB(&AABB{Position: Point3{0, 2, 0}, Size: Vec3{1, 1, 1}})
+			},
+			want: true,
+		},
+		{
+			name: "sphere above apex",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectSphere(&Sphere{Position: Point3{4.5, 7, 0}, Radius: 2.5})
+			},
+			want:   true,
+			time:   1,
+			normal: Vec3{-0.6, -0.8, 0},
+		},
+		{
+			name: "sphere missed",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectSphere(&Sphere{Position: Point3{6, 8, 0}, Radius: 1.5})
+			},
+		},
+		{
+			name:   "flat terrain",
+			hit:    func() (RaycastResult, bool) { return p.IntersectGrid2(terrain(-2, 20, flat(0))) },
+			want:   true,
+			time:   landing,
+			normal: Vec3{0, 1, 0},
+		},
+		{
+			// y = 2 + 4t - t² meets y = x/4 = 3t/4 at t = (3.25 + sqrt(18.5625))/2
+			name: "sloping terrain",
+			hit: func() (RaycastResult, bool) {
+				return p.IntersectGrid2(terrain(-2, 20, func(x float32) float32 { return x / 4 }))
+			},
+			want:   true,
+			time:   (3.25 + float32(math.Sqrt(18.5625))) / 2,
+			normal: Vec3{-0.25, 1, 0}.Normalize(),
+		},
+		{
+			name: "terrain left behind",
+			hit:  func() (RaycastResult, bool) { return p.IntersectGrid2(terrain(-2, 5, flat(0))) },
+		},
+		{
+			name: "starts below terrain",
+			hit:  func() (RaycastResult, bool) { return p.IntersectGrid2(terrain(-2, 20, flat(3))) },
+			want: true,
+		},
+		{
+			name:   "enters side of terrain",
+			hit:    func() (RaycastResult, bool) { return p.IntersectGrid2(terrain(14, 20, flat(5))) },
+			want:   true,
+			time:   14.0 / 3,
+			normal: Vec3{-1, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.hit()
+			if ok != tc.want {
+				t.Fatalf("got hit %v, wanted %v: %v", ok, tc.want, res)
+			}
+			if !ok {
+				if tc.fail != RaycastFailUnknown && res.Fail != tc.fail {
+					t.Errorf("got fail %v, wanted %v", res.Fail, tc.fail)
+				}
+				return
+			}
+			if abs(res.Distance-tc.time) > 1e-4 {
+				t.Errorf("got time %v, wanted %v", res.Distance, tc.time)
+			}
+			if !nearVec3(res.Point, p.PointAt(res.Distance), 1e-5) {
+				t.Errorf("point %v is not on the path", res.Point)
+			}
+			if !nearVec3(res.Normal, tc.normal, 1e-4) {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.normal)
+			}
+		})
+	}
+}
+
+func TestParabola3IntersectGrid2(t *testing.T) {
+	g := NewGrid2[float32](Point2{0, 0}, 1, 2, 2)
+	for i := range g.Cells {
+		g.Cells[i] = 0.5
+	}
+	// y = 1 + 5t - t² falls back to 0.5 at t = (5 + sqrt(27))/2 without leaving the grid
+	p := Parabola3{Origin: Point3{1, 1, 1}, Velocity: Vec3{0, 5, 0}, Gravity: Vec3{0, -2, 0}}
+	res, ok := p.IntersectGrid2(g)
+	if want := (5 + float32(math.Sqrt(27))) / 2; !ok || abs(res.Distance-want) > 1e-4 {
+		t.Errorf("got time %v, %v, wanted %v", res.Distance, ok, want)
+	}
+
+	// A path that narrowly clears a ridge across the grid and lands beyond it
+	ridge := NewGrid2[float32](Point2{0, -1}, 0.25, 40, 8)
+	for y := 0; y < ridge.Dims[1]; y++ {
+		ridge.Set(20, y, 3)
+	}
+	p = Parabola3{Origin: Point3{0, 0.1, 0}, Velocity: Vec3{2, 4, 0}, Gravity: Vec3{0, -2, 0}}
+	res, ok = p.IntersectGrid2(ridge)
+	if !ok || res.Point[0] < 5.25 {
+		t.Errorf("got hit %v at %v, wanted a landing beyond the ridge", ok, res.Point)
+	}
+}
+
+func TestPolyRoots(t *testing.T) {
+	testCases := []struct {
+		name string
+		c    []float64
+		want []float64
+	}{
+		{name: "linear", c: []float64{-2, 1}, want: []float64{2}},
+		{name: "quadratic", c: []float64{6, -5, 1}, want: []float64{2, 3}},
+		{name: "no real roots", c: []float64{1, 0, 1}},
+		{name: "cubic", c: []float64{-6, 11, -6, 1}, want: []float64{1, 2, 3}},
+		{name: "quartic", c: []float64{24, -50, 35, -10, 1}, want: []float64{1, 2, 3, 4}},
+		{name: "leading zeros", c: []float64{-6, 11, -6, 1, 0, 0}, want: []float64{1, 2, 3}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := polyRoots(tc.c, 0, 10)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, wanted %v", got, tc.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tc.want[i]) > 1e-9 {
+					t.Errorf("got %v, wanted %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
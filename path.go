@@ -53,3 +53,57 @@ func (p *Path2) PositionAlong(d float32) Ray2 {
 		Direction: p.dirs[len(p.dirs)-1],
 	}
 }
+
+type Path3 struct {
+	Points []Point3 // waypoints
+	dirs   []Vec3
+	dists  []float32
+	length float32
+}
+
+func NewPath3(pts []Point3) *Path3 {
+	p := &Path3{
+		Points: pts,
+		dirs:   make([]Vec3, len(pts)-1),
+		dists:  make([]float32, len(pts)-1),
+	}
+
+	for i := 0; i < len(pts)-1; i++ {
+		p.dirs[i] = pts[i+1].Sub(pts[i])
+		p.dists[i] = p.dirs[i].Len()
+		p.length += p.dists[i]
+		p.dirs[i] = p.dirs[i].Normalize()
+	}
+
+	return p
+}
+
+func (p *Path3) PositionAlong(d float32) Ray3 {
+	if d <= 0 {
+		return Ray3{
+			Origin:    p.Points[0],
+			Direction: p.dirs[0],
+		}
+	} else if d >= 1.0 {
+		return Ray3{
+			Origin:    p.Points[len(p.Points)-1],
+			Direction: p.dirs[len(p.dirs)-1],
+		}
+	}
+
+	l := d * p.length
+	for i := 0; i < len(p.dists); i++ {
+		if l <= p.dists[i] {
+			return Ray3{
+				Origin:    p.Points[i].Add(p.dirs[i].Mul(l)),
+				Direction: p.dirs[i],
+			}
+		}
+		l -= p.dists[i]
+	}
+
+	return Ray3{
+		Origin:    p.Points[len(p.Points)-1],
+		Direction: p.dirs[len(p.dirs)-1],
+	}
+}
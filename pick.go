@@ -0,0 +1,39 @@
+package geom
+
+import "math"
+
+// PickSegment reports whether the ray passes within tolerance of the line segment l and
+// returns the distance along the ray to the point where it passes closest to the segment. Only
+// the part of the segment in front of the ray's origin can be picked.
+func PickSegment(ray Ray3, l Line3, tolerance float32) (float32, bool) {
+	p1, p2, t1, _ := ray.ClosestPointToSegment(l)
+	if DistanceSquared3(p1, p2) > tolerance*tolerance {
+		return 0, false
+	}
+	return t1, true
+}
+
+// PickSegment reports whether the line segment l passes within the given number of pixels of
+// the screen position x, y within the viewport, and returns the distance along the ray from
+// ScreenPointToRay to the point where it passes closest to the segment. The tolerance is
+// converted to world units at the depth of the segment, so distant segments are as easy to
+// pick as near ones.
+func (c *Camera) PickSegment(x, y float32, l Line3, pixels float32, viewport Recti) (float32, bool) {
+	if viewport.Height() <= 0 {
+		return 0, false
+	}
+	ray := c.ScreenPointToRay(x, y, viewport)
+	p1, p2, t1, _ := ray.ClosestPointToSegment(l)
+
+	// The height of the view volume at the depth of the segment spans the viewport height
+	height := 2 * c.OrthoSize
+	if c.Projection == Perspective {
+		depth := p2.Sub(c.Transform.Pos()).Dot(c.Transform.Front())
+		height = 2 * depth * float32(math.Tan(float64(c.FOV)/2))
+	}
+	tolerance := pixels * height / float32(viewport.Height())
+	if tolerance < 0 || DistanceSquared3(p1, p2) > tolerance*tolerance {
+		return 0, false
+	}
+	return t1, true
+}
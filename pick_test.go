@@ -0,0 +1,61 @@
+package geom
+
+import "testing"
+
+func TestPickSegment(t *testing.T) {
+	ray := Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{0, 0, 1}}
+	testCases := []struct {
+		name string
+		l    Line3
+		hit  bool
+		dist float32
+	}{
+		{name: "crossing", l: Line3{Start: Point3{-1, 0, 5}, End: Point3{1, 0, 5}}, hit: true, dist: 5},
+		{name: "near miss", l: Line3{Start: Point3{-1, 0.05, 5}, End: Point3{1, 0.05, 5}}, hit: true, dist: 5},
+		{name: "miss", l: Line3{Start: Point3{-1, 0.2, 5}, End: Point3{1, 0.2, 5}}},
+		{name: "beyond the end", l: Line3{Start: Point3{0.05, 0, 3}, End: Point3{1, 0, 3}}, hit: true, dist: 3},
+		{name: "past the end", l: Line3{Start: Point3{0.5, 0, 3}, End: Point3{1, 0, 3}}},
+		{name: "behind", l: Line3{Start: Point3{-1, 0, -5}, End: Point3{1, 0, -5}}},
+		{name: "along", l: Line3{Start: Point3{0, 0.05, 2}, End: Point3{0, 0.05, 8}}, hit: true, dist: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, ok := PickSegment(ray, tc.l, 0.1)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v", ok, tc.hit)
+			}
+			if ok && abs(d-tc.dist) > 1e-5 {
+				t.Errorf("got distance %v, wanted %v", d, tc.dist)
+			}
+		})
+	}
+}
+
+func TestCameraPickSegment(t *testing.T) {
+	c := NewCamera()
+	c.Transform.SetPosition(Vec3{0, 0, -10})
+	viewport := RectiFromCorners(Point2i{0, 0}, Point2i{800, 800})
+
+	// A vertical segment through the middle of the screen at two depths. Each pixel at depth
+	// z covers 2z tan(30°)/800 world units.
+	for _, z := range []float32{1, 100} {
+		l := Line3{Start: Point3{0, -1, z}, End: Point3{0, 1, z}}
+		if _, ok := c.PickSegment(404, 400, l, 5, viewport); !ok {
+			t.Errorf("depth %v: 4 pixels away should be picked", z)
+		}
+		if _, ok := c.PickSegment(406, 400, l, 5, viewport); ok {
+			t.Errorf("depth %v: 6 pixels away should not be picked", z)
+		}
+	}
+
+	c.Projection = Orthographic
+	c.OrthoSize = 4
+	l := Line3{Start: Point3{0, -1, 20}, End: Point3{0, 1, 20}}
+	if d, ok := c.PickSegment(404, 400, l, 5, viewport); !ok || abs(d-(30-c.Near)) > 1e-3 {
+		t.Errorf("orthographic: got %v, %v, wanted hit at %v", d, ok, 30-c.Near)
+	}
+	if _, ok := c.PickSegment(406, 400, l, 5, viewport); ok {
+		t.Errorf("orthographic: 6 pixels away should not be picked")
+	}
+}
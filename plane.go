@@ -0,0 +1,76 @@
+package geom
+
+// PlaneSide describes where a shape lies relative to a plane.
+type PlaneSide int
+
+const (
+	// PlaneSpanning means the shape crosses or touches the plane.
+	PlaneSpanning PlaneSide = iota
+
+	// PlaneFront means the shape lies entirely on the side of the plane its normal faces.
+	PlaneFront
+
+	// PlaneBack means the shape lies entirely behind the plane.
+	PlaneBack
+)
+
+func (s PlaneSide) String() string {
+	switch s {
+	case PlaneSpanning:
+		return "spanning"
+	case PlaneFront:
+		return "front"
+	case PlaneBack:
+		return "back"
+	default:
+		return "unknown"
+	}
+}
+
+// classifyPlane classifies a shape whose centre is dist in front of a plane and which extends
+// r either side of its centre along the normal.
+func classifyPlane(dist, r float32) PlaneSide {
+	if dist > r {
+		return PlaneFront
+	}
+	if dist < -r {
+		return PlaneBack
+	}
+	return PlaneSpanning
+}
+
+// IntersectsPlane reports whether the sphere crosses or touches the plane.
+func (s *Sphere) IntersectsPlane(p *Plane3) bool {
+	return s.ClassifyAgainstPlane(p) == PlaneSpanning
+}
+
+// ClassifyAgainstPlane reports which side of the plane the sphere lies on.
+func (s *Sphere) ClassifyAgainstPlane(p *Plane3) PlaneSide {
+	return classifyPlane(SignedDistancePointPlane3(s.Position, p), s.Radius)
+}
+
+// IntersectsPlane reports whether the box crosses or touches the plane.
+func (a *AABB) IntersectsPlane(p *Plane3) bool {
+	return a.ClassifyAgainstPlane(p) == PlaneSpanning
+}
+
+// ClassifyAgainstPlane reports which side of the plane the box lies on.
+func (a *AABB) ClassifyAgainstPlane(p *Plane3) PlaneSide {
+	n := p.Normal
+	// Distance from the centre to the plane of the corner that is furthest along the normal
+	r := a.Size[0]*abs(n[0]) + a.Size[1]*abs(n[1]) + a.Size[2]*abs(n[2])
+	return classifyPlane(SignedDistancePointPlane3(a.Position, p), r)
+}
+
+// IntersectsPlane reports whether the box crosses or touches the plane.
+func (o *OBB) IntersectsPlane(p *Plane3) bool {
+	return o.ClassifyAgainstPlane(p) == PlaneSpanning
+}
+
+// ClassifyAgainstPlane reports which side of the plane the box lies on.
+func (o *OBB) ClassifyAgainstPlane(p *Plane3) PlaneSide {
+	axes := o.axisArray()
+	n := p.Normal
+	r := o.Size[0]*abs(axes[0].Dot(n)) + o.Size[1]*abs(axes[1].Dot(n)) + o.Size[2]*abs(axes[2].Dot(n))
+	return classifyPlane(SignedDistancePointPlane3(o.Position, p), r)
+}
@@ -0,0 +1,92 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestClassifyAgainstPlane(t *testing.T) {
+	// The plane y = 1 facing up
+	up := Plane3{Normal: Vec3{0, 1, 0}, Distance: 1}
+	// The plane through the origin facing towards +x and +y
+	diag := Plane3{Normal: Vec3{1, 1, 0}.Normalize(), Distance: 0}
+
+	turn := mgl32.QuatRotate(mgl32.DegToRad(45), Vec3{0, 0, 1})
+
+	testCases := []struct {
+		name   string
+		plane  Plane3
+		sphere Sphere
+		aabb   AABB
+		obb    OBB
+		want   PlaneSide
+	}{
+		{
+			name:   "front",
+			plane:  up,
+			sphere: Sphere{Position: Point3{0, 3, 0}, Radius: 1},
+			aabb:   AABB{Position: Point3{0, 3, 0}, Size: Vec3{5, 1, 5}},
+			obb:    NewOBB(Point3{0, 3, 0}, Vec3{1, 1, 1}, turn),
+			want:   PlaneFront,
+		},
+		{
+			name:   "back",
+			plane:  up,
+			sphere: Sphere{Position: Point3{0, -1, 0}, Radius: 1.5},
+			aabb:   AABB{Position: Point3{0, -1, 0}, Size: Vec3{5, 1.5, 5}},
+			obb:    NewOBB(Point3{0, -1, 0}, Vec3{1, 1, 1}, turn),
+			want:   PlaneBack,
+		},
+		{
+			name:   "spanning",
+			plane:  up,
+			sphere: Sphere{Position: Point3{0, 1.5, 0}, Radius: 1},
+			aabb:   AABB{Position: Point3{0, 1.5, 0}, Size: Vec3{1, 1, 1}},
+			obb:    NewOBB(Point3{0, 2.2, 0}, Vec3{1, 1, 1}, turn),
+			want:   PlaneSpanning,
+		},
+		{
+			name:   "touching",
+			plane:  up,
+			sphere: Sphere{Position: Point3{0, 2, 0}, Radius: 1},
+			aabb:   AABB{Position: Point3{0, 2, 0}, Size: Vec3{1, 1, 1}},
+			obb:    NewOBB(Point3{0, 2, 0}, Vec3{1, 1, 1}, Quat{W: 1}),
+			want:   PlaneSpanning,
+		},
+		{
+			name:   "diagonal plane front",
+			plane:  diag,
+			sphere: Sphere{Position: Point3{2, 2, 0}, Radius: 2},
+			aabb:   AABB{Position: Point3{2, 2, 0}, Size: Vec3{1.4, 1.4, 9}},
+			obb:    NewOBB(Point3{2, 2, 0}, Vec3{2.5, 2.5, 1}, turn),
+			want:   PlaneFront,
+		},
+		{
+			name:   "diagonal plane spanning",
+			plane:  diag,
+			sphere: Sphere{Position: Point3{1, 1, 0}, Radius: 2},
+			aabb:   AABB{Position: Point3{1, 1, 0}, Size: Vec3{1.1, 1.1, 1}},
+			obb:    NewOBB(Point3{2, 2, 0}, Vec3{3, 1, 1}, turn),
+			want:   PlaneSpanning,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.sphere.ClassifyAgainstPlane(&tc.plane); got != tc.want {
+				t.Errorf("Sphere: got %v, wanted %v", got, tc.want)
+			}
+			if got := tc.aabb.ClassifyAgainstPlane(&tc.plane); got != tc.want {
+				t.Errorf("AABB: got %v, wanted %v", got, tc.want)
+			}
+			if got := tc.obb.ClassifyAgainstPlane(&tc.plane); got != tc.want {
+				t.Errorf("OBB: got %v, wanted %v", got, tc.want)
+			}
+			want := tc.want == PlaneSpanning
+			if tc.sphere.IntersectsPlane(&tc.plane) != want || tc.aabb.IntersectsPlane(&tc.plane) != want || tc.obb.IntersectsPlane(&tc.plane) != want {
+				t.Errorf("IntersectsPlane disagrees with ClassifyAgainstPlane")
+			}
+		})
+	}
+}
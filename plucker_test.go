@@ -0,0 +1,90 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPluckerSide(t *testing.T) {
+	z := PluckerFromPoints(Point3{0, 0, 0}, Point3{0, 0, 1})
+	testCases := []struct {
+		name string
+		q    Plucker
+		sign int
+	}{
+		{name: "right hand", q: PluckerFromPoints(Point3{1, 0, 0}, Point3{1, 1, 0}), sign: 1},
+		{name: "left hand", q: PluckerFromPoints(Point3{1, 1, 0}, Point3{1, 0, 0}), sign: -1},
+		{name: "other side", q: PluckerFromPoints(Point3{-1, 0, 5}, Point3{-1, -1, 5}), sign: 1},
+		{name: "meeting", q: PluckerFromPoints(Point3{1, 0, 3}, Point3{-1, 0, 3}), sign: 0},
+		{name: "parallel", q: PluckerFromPoints(Point3{2, 3, 0}, Point3{2, 3, -1}), sign: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := z.Side(tc.q)
+			if (tc.sign > 0 && s <= 0) || (tc.sign < 0 && s >= 0) || (tc.sign == 0 && s != 0) {
+				t.Errorf("got %v, wanted sign %d", s, tc.sign)
+			}
+			if s != tc.q.Side(z) {
+				t.Errorf("side is not symmetric: %v and %v", s, tc.q.Side(z))
+			}
+		})
+	}
+}
+
+func TestPluckerPassesThrough(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomPoint := func() Point3 {
+		return Point3{r.Float32()*4 - 2, r.Float32()*4 - 2, r.Float32()*4 - 2}
+	}
+
+	hits := 0
+	for i := 0; i < 2000; i++ {
+		tri := Tri3{A: randomPoint(), B: randomPoint(), C: randomPoint()}
+		ray := Ray3FromPoints(randomPoint().Mul(3), randomPoint())
+		p := ray.Plucker()
+
+		// Where the line meets the plane of the triangle, if it is inside the triangle
+		n := tri.Normal()
+		dn := n.Dot(ray.Direction)
+		if abs(dn) < 1e-2 {
+			continue
+		}
+		hit := ray.Point(n.Dot(tri.A.Sub(ray.Origin)) / dn)
+		bary := tri.BarycentricPoint3(hit)
+		if min(bary[0], min(bary[1], bary[2])) > -1e-4 && min(bary[0], min(bary[1], bary[2])) < 1e-4 {
+			// Too close to an edge to be sure
+			continue
+		}
+		want := bary[0] >= 0 && bary[1] >= 0 && bary[2] >= 0
+
+		if want {
+			hits++
+		}
+		if got := p.PassesThroughTri3(tri); got != want {
+			t.Fatalf("%v through %v: got %v, wanted %v", ray, tri, got, want)
+		}
+		if got := p.PassesThroughConvexPolygon3([]Point3{tri.C, tri.B, tri.A}); got != want {
+			t.Fatalf("%v through polygon %v: got %v, wanted %v", ray, tri, got, want)
+		}
+	}
+
+	if hits < 100 {
+		t.Fatalf("only %d of the random lines passed through their triangle", hits)
+	}
+
+	// A square and a line in its plane
+	square := []Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	line := Line3{Start: Point3{0.5, 0.5, 2}, End: Point3{0.5, 0.5, 1}}
+	if !line.Plucker().PassesThroughConvexPolygon3(square) {
+		t.Errorf("line should pass through the middle of the square")
+	}
+	edge := Line3{Start: Point3{1, 0.5, 2}, End: Point3{1, 0.5, 1}}
+	if !edge.Plucker().PassesThroughConvexPolygon3(square) {
+		t.Errorf("line touching an edge should pass through the square")
+	}
+	inPlane := PluckerFromPoints(Point3{-1, 0.5, 0}, Point3{2, 0.5, 0})
+	if inPlane.PassesThroughConvexPolygon3(square) || inPlane.PassesThroughTri3(Tri3{A: square[0], B: square[1], C: square[2]}) {
+		t.Errorf("line in the plane should not pass through")
+	}
+}
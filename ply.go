@@ -0,0 +1,402 @@
+package geom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The PLY reader accepts the ASCII and both binary forms of the format. Vertex positions are
+// read from the x, y and z properties of the vertex element and normals from nx, ny and nz.
+// Faces are read from the vertex_indices (or vertex_index) list of the face element; faces with
+// more than three corners are split into a fan of triangles. Other elements and properties,
+// such as colours, are skipped.
+
+// ReadPLY reads a PLY mesh. Normals are included when every vertex has them. A file without a
+// face element gives a mesh with no triangles.
+func ReadPLY(r io.Reader) (*TriMesh, error) {
+	d, err := readPLY(r, true)
+	if err != nil {
+		return nil, err
+	}
+	return &TriMesh{Positions: d.positions, Normals: d.normals, Indices: d.indices}, nil
+}
+
+// ReadPLYPointCloud reads the vertices of a PLY file into a point cloud. Any faces are ignored.
+func ReadPLYPointCloud(r io.Reader) (*PointCloud, error) {
+	d, err := readPLY(r, false)
+	if err != nil {
+		return nil, err
+	}
+	return NewPointCloud(d.positions), nil
+}
+
+type plyFormat int
+
+const (
+	plyASCII plyFormat = iota
+	plyBinaryLE
+	plyBinaryBE
+)
+
+type plyProperty struct {
+	name      string
+	typ       string // the scalar type, or the item type of a list
+	countType string // the type of the length of a list, empty for scalar properties
+}
+
+type plyElement struct {
+	name  string
+	count int
+	props []plyProperty
+}
+
+type plyData struct {
+	positions []Point3
+	normals   []Vec3
+	indices   []uint32
+}
+
+// plySizes gives the size in bytes of each PLY scalar type, under both its old and new name.
+var plySizes = map[string]int{
+	"char": 1, "int8": 1, "uchar": 1, "uint8": 1,
+	"short": 2, "int16": 2, "ushort": 2, "uint16": 2,
+	"int": 4, "int32": 4, "uint": 4, "uint32": 4,
+	"float": 4, "float32": 4, "double": 8, "float64": 8,
+}
+
+func readPLY(r io.Reader, faces bool) (*plyData, error) {
+	br := bufio.NewReader(r)
+	format, elems, err := readPLYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var vr plyValueReader
+	switch format {
+	case plyASCII:
+		sc := bufio.NewScanner(br)
+		sc.Split(bufio.ScanWords)
+		vr = &plyASCIIReader{sc: sc}
+	case plyBinaryLE:
+		vr = &plyBinaryReader{r: br, order: binary.LittleEndian}
+	case plyBinaryBE:
+		vr = &plyBinaryReader{r: br, order: binary.BigEndian}
+	}
+
+	d := &plyData{}
+	sawVertices := false
+	for _, e := range elems {
+		switch {
+		case e.name == "vertex" && !sawVertices:
+			sawVertices = true
+			if err := d.readVertices(vr, e); err != nil {
+				return nil, err
+			}
+		case e.name == "face" && faces:
+			if err := d.readFaces(vr, e); err != nil {
+				return nil, err
+			}
+		default:
+			if size := e.fixedSize(); format != plyASCII && size > 0 {
+				if e.count > math.MaxInt/size {
+					return nil, fmt.Errorf("geom: ply %s element is too large", e.name)
+				}
+				if err := vr.(*plyBinaryReader).skip(e.count * size); err != nil {
+					return nil, fmt.Errorf("geom: ply %s element: %w", e.name, err)
+				}
+				continue
+			}
+			for i := 0; i < e.count; i++ {
+				for _, p := range e.props {
+					if _, err := p.read(vr, nil); err != nil {
+						return nil, fmt.Errorf("geom: ply %s element: %w", e.name, err)
+					}
+				}
+			}
+		}
+	}
+	if !sawVertices {
+		return nil, errors.New("geom: ply has no vertex element")
+	}
+	for _, idx := range d.indices {
+		if int(idx) >= len(d.positions) {
+			return nil, fmt.Errorf("geom: ply face index %d is out of range", idx)
+		}
+	}
+	return d, nil
+}
+
+func readPLYHeader(br *bufio.Reader) (plyFormat, []plyElement, error) {
+	line, err := br.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "ply" {
+		return 0, nil, errors.New("geom: not a ply file")
+	}
+
+	format := plyFormat(-1)
+	var elems []plyElement
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return 0, nil, fmt.Errorf("geom: ply header: %w", err)
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "comment", "obj_info":
+		case "format":
+			if len(f) != 3 || f[2] != "1.0" {
+				return 0, nil, fmt.Errorf("geom: unsupported ply format %q", strings.TrimSpace(line))
+			}
+			switch f[1] {
+			case "ascii":
+				format = plyASCII
+			case "binary_little_endian":
+				format = plyBinaryLE
+			case "binary_big_endian":
+				format = plyBinaryBE
+			default:
+				return 0, nil, fmt.Errorf("geom: unsupported ply format %q", f[1])
+			}
+		case "element":
+			if len(f) != 3 {
+				return 0, nil, fmt.Errorf("geom: malformed ply element %q", strings.TrimSpace(line))
+			}
+			n, err := strconv.Atoi(f[2])
+			if err != nil || n < 0 {
+				return 0, nil, fmt.Errorf("geom: malformed ply element count %q", f[2])
+			}
+			elems = append(elems, plyElement{name: f[1], count: n})
+		case "property":
+			if len(elems) == 0 {
+				return 0, nil, errors.New("geom: ply property before any element")
+			}
+			var p plyProperty
+			switch {
+			case len(f) == 3:
+				p = plyProperty{typ: f[1], name: f[2]}
+			case len(f) == 5 && f[1] == "list":
+				p = plyProperty{countType: f[2], typ: f[3], name: f[4]}
+			default:
+				return 0, nil, fmt.Errorf("geom: malformed ply property %q", strings.TrimSpace(line))
+			}
+			if plySizes[p.typ] == 0 || (p.countType != "" && plySizes[p.countType] == 0) {
+				return 0, nil, fmt.Errorf("geom: unsupported ply property type in %q", strings.TrimSpace(line))
+			}
+			e := &elems[len(elems)-1]
+			e.props = append(e.props, p)
+		case "end_header":
+			if format < 0 {
+				return 0, nil, errors.New("geom: ply header has no format")
+			}
+			return format, elems, nil
+		default:
+			return 0, nil, fmt.Errorf("geom: unexpected ply header line %q", strings.TrimSpace(line))
+		}
+	}
+}
+
+// fixedSize returns the size in bytes of each binary item of the element, or zero when the
+// element contains lists and its items vary in size.
+func (e *plyElement) fixedSize() int {
+	n := 0
+	for _, p := range e.props {
+		if p.countType != "" {
+			return 0
+		}
+		n += plySizes[p.typ]
+	}
+	return n
+}
+
+// read reads one value of the property. Scalars are returned as the only item of the slice,
+// which reuses buf.
+func (p *plyProperty) read(vr plyValueReader, buf []float64) ([]float64, error) {
+	buf = buf[:0]
+	if p.countType == "" {
+		v, err := vr.value(p.typ)
+		if err != nil {
+			return nil, err
+		}
+		return append(buf, v), nil
+	}
+	n, err := vr.value(p.countType)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n != math.Trunc(n) {
+		return nil, fmt.Errorf("invalid list length %v", n)
+	}
+	for i := 0; i < int(n); i++ {
+		v, err := vr.value(p.typ)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, v)
+	}
+	return buf, nil
+}
+
+// plyMaxPrealloc limits the number of vertices allocated before any are read.
+const plyMaxPrealloc = 1 << 20
+
+func (d *plyData) readVertices(vr plyValueReader, e plyElement) error {
+	// Find which property supplies each component of the position and normal
+	pos, nrm := [3]int{-1, -1, -1}, [3]int{-1, -1, -1}
+	for i, p := range e.props {
+		if p.countType != "" {
+			continue
+		}
+		switch p.name {
+		case "x":
+			pos[0] = i
+		case "y":
+			pos[1] = i
+		case "z":
+			pos[2] = i
+		case "nx":
+			nrm[0] = i
+		case "ny":
+			nrm[1] = i
+		case "nz":
+			nrm[2] = i
+		}
+	}
+	if pos[0] < 0 || pos[1] < 0 || pos[2] < 0 {
+		return errors.New("geom: ply vertex element has no x, y and z properties")
+	}
+	hasNormals := nrm[0] >= 0 && nrm[1] >= 0 && nrm[2] >= 0
+
+	// The count comes from the header, so only trust it as far as a modest preallocation and
+	// let a truncated body end the loop below
+	prealloc := e.count
+	if prealloc > plyMaxPrealloc {
+		prealloc = plyMaxPrealloc
+	}
+	d.positions = make([]Point3, 0, prealloc)
+	if hasNormals {
+		d.normals = make([]Vec3, 0, prealloc)
+	}
+	vals := make([]float32, len(e.props))
+	var buf []float64
+	for i := 0; i < e.count; i++ {
+		for j := range e.props {
+			v, err := e.props[j].read(vr, buf)
+			if err != nil {
+				return fmt.Errorf("geom: ply vertex %d: %w", i, err)
+			}
+			if len(v) == 1 {
+				vals[j] = float32(v[0])
+			}
+			buf = v
+		}
+		d.positions = append(d.positions, Point3{vals[pos[0]], vals[pos[1]], vals[pos[2]]})
+		if hasNormals {
+			d.normals = append(d.normals, Vec3{vals[nrm[0]], vals[nrm[1]], vals[nrm[2]]})
+		}
+	}
+	return nil
+}
+
+func (d *plyData) readFaces(vr plyValueReader, e plyElement) error {
+	list := -1
+	for i, p := range e.props {
+		if p.countType != "" && (p.name == "vertex_indices" || p.name == "vertex_index") {
+			list = i
+		}
+	}
+	if list < 0 {
+		return errors.New("geom: ply face element has no vertex_indices property")
+	}
+
+	var buf []float64
+	for i := 0; i < e.count; i++ {
+		for j := range e.props {
+			v, err := e.props[j].read(vr, buf)
+			if err != nil {
+				return fmt.Errorf("geom: ply face %d: %w", i, err)
+			}
+			buf = v
+			if j != list {
+				continue
+			}
+			for k := 2; k < len(v); k++ {
+				for _, f := range [3]float64{v[0], v[k-1], v[k]} {
+					if f < 0 || f > math.MaxUint32 || f != math.Trunc(f) {
+						return fmt.Errorf("geom: ply face %d has invalid index %v", i, f)
+					}
+					d.indices = append(d.indices, uint32(f))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// plyValueReader reads successive values of the body of a PLY file.
+type plyValueReader interface {
+	value(typ string) (float64, error)
+}
+
+type plyASCIIReader struct {
+	sc *bufio.Scanner
+}
+
+func (r *plyASCIIReader) value(typ string) (float64, error) {
+	if !r.sc.Scan() {
+		if err := r.sc.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	return strconv.ParseFloat(r.sc.Text(), 64)
+}
+
+type plyBinaryReader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (r *plyBinaryReader) value(typ string) (float64, error) {
+	b := r.buf[:plySizes[typ]]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	switch typ {
+	case "char", "int8":
+		return float64(int8(b[0])), nil
+	case "uchar", "uint8":
+		return float64(b[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(b))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(b)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(b))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(b)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(b)), nil
+	}
+}
+
+func (r *plyBinaryReader) skip(n int) error {
+	_, err := r.r.Discard(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
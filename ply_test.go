@@ -0,0 +1,139 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPLYASCII = `ply
+format ascii 1.0
+comment a unit square made of one quad and one triangle
+element vertex 5
+property float x
+property float y
+property float z
+property float nx
+property float ny
+property float nz
+property uchar red
+element face 2
+property list uchar int vertex_indices
+element edge 1
+property int vertex1
+property int vertex2
+end_header
+0 0 0 0 0 1 255
+1 0 0 0 0 1 255
+1 1 0 0 0 1 255
+0 1 0 0 0 1 255
+2 0 0 0 0 1 255
+4 0 1 2 3
+3 1 4 2
+0 1
+`
+
+var (
+	testPLYPositions = []Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}}
+	testPLYIndices   = []uint32{0, 1, 2, 0, 2, 3, 1, 4, 2}
+)
+
+// testPLYBinary encodes the same mesh as testPLYASCII in binary form, with an extra element
+// that contains a list before the faces.
+func testPLYBinary(order binary.ByteOrder, name string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("ply\nformat " + name + " 1.0\n" +
+		"element vertex 5\nproperty double x\nproperty double y\nproperty double z\nproperty uchar red\n" +
+		"element material 1\nproperty list uchar float colour\n" +
+		"element face 2\nproperty list uchar uint vertex_indices\n" +
+		"end_header\n")
+	for _, p := range testPLYPositions {
+		binary.Write(&buf, order, [3]float64{float64(p[0]), float64(p[1]), float64(p[2])})
+		buf.WriteByte(255)
+	}
+	buf.WriteByte(3)
+	binary.Write(&buf, order, [3]float32{1, 0.5, 0.25})
+	buf.WriteByte(4)
+	binary.Write(&buf, order, []uint32{0, 1, 2, 3})
+	buf.WriteByte(3)
+	binary.Write(&buf, order, []uint32{1, 4, 2})
+	return buf.Bytes()
+}
+
+func TestReadPLY(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []byte
+		normals bool
+	}{
+		{name: "ascii", data: []byte(testPLYASCII), normals: true},
+		{name: "binary little endian", data: testPLYBinary(binary.LittleEndian, "binary_little_endian")},
+		{name: "binary big endian", data: testPLYBinary(binary.BigEndian, "binary_big_endian")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ReadPLY(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("ReadPLY: %v", err)
+			}
+			if !reflect.DeepEqual(m.Positions, testPLYPositions) {
+				t.Errorf("positions: got %v, wanted %v", m.Positions, testPLYPositions)
+			}
+			if !reflect.DeepEqual(m.Indices, testPLYIndices) {
+				t.Errorf("indices: got %v, wanted %v", m.Indices, testPLYIndices)
+			}
+			if tc.normals {
+				if len(m.Normals) != len(m.Positions) || m.Normals[0] != (Vec3{0, 0, 1}) {
+					t.Errorf("normals: got %v", m.Normals)
+				}
+			} else if m.Normals != nil {
+				t.Errorf("got normals %v, wanted none", m.Normals)
+			}
+
+			pc, err := ReadPLYPointCloud(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("ReadPLYPointCloud: %v", err)
+			}
+			if b := pc.Bounds(); pc.Len() != 5 || b.Max() != (Point3{2, 1, 0}) {
+				t.Errorf("point cloud: got %d points with bounds %v", pc.Len(), b)
+			}
+		})
+	}
+}
+
+func TestReadPLYErrors(t *testing.T) {
+	truncated := testPLYBinary(binary.LittleEndian, "binary_little_endian")
+	truncated = truncated[:len(truncated)-3]
+
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "not ply", data: "obj\n"},
+		{name: "no format", data: "ply\nelement vertex 0\nend_header\n"},
+		{name: "bad format", data: "ply\nformat binary_middle_endian 1.0\nend_header\n"},
+		{name: "bad type", data: "ply\nformat ascii 1.0\nelement vertex 1\nproperty half x\nend_header\n"},
+		{name: "no vertices", data: "ply\nformat ascii 1.0\nend_header\n"},
+		{name: "no position", data: "ply\nformat ascii 1.0\nelement vertex 1\nproperty float u\nend_header\n0\n"},
+		{name: "short body", data: "ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n"},
+		{name: "bad number", data: "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 zero 0\n"},
+		{name: "index out of range", data: strings.Replace(testPLYASCII, "3 1 4 2", "3 1 5 2", 1)},
+		{name: "fractional index", data: strings.Replace(testPLYASCII, "3 1 4 2", "3 1 3.5 2", 1)},
+		{name: "nan index", data: strings.Replace(strings.Replace(testPLYASCII, "list uchar int", "list uchar float", 1), "3 1 4 2", "3 1 nan 2", 1)},
+		{name: "truncated binary", data: string(truncated)},
+		{name: "huge vertex count", data: "ply\nformat ascii 1.0\nelement vertex 9000000000000000000\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n"},
+		{name: "huge binary vertex count", data: "ply\nformat binary_little_endian 1.0\nelement vertex 9000000000000000000\nproperty float x\nproperty float y\nproperty float z\nend_header\n"},
+		{name: "huge face count", data: "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nelement face 9000000000000000000\nproperty list uchar int vertex_indices\nend_header\n0 0 0 1 0 0 0 1 0\n3 0 1 2\n"},
+		{name: "huge skipped element", data: "ply\nformat binary_little_endian 1.0\nelement extra 9000000000000000000\nproperty double a\nelement vertex 0\nproperty float x\nproperty float y\nproperty float z\nend_header\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ReadPLY(strings.NewReader(tc.data)); err == nil {
+				t.Errorf("got no error")
+			}
+		})
+	}
+}
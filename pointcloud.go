@@ -0,0 +1,281 @@
+package geom
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// PointCloud is a collection of points that keeps track of their bounds and centroid and keeps
+// a KD-tree of the points for neighbour queries. The cached values are updated by each method
+// that changes the points so reading them never modifies the cloud.
+type PointCloud struct {
+	points   []Point3
+	bounds   AABB
+	centroid Point3
+	tree     *kdTree // indexes the first tree.Len() points, the rest are searched directly
+}
+
+// NewPointCloud returns a point cloud containing pts. The cloud takes ownership of the slice.
+func NewPointCloud(pts []Point3) *PointCloud {
+	pc := &PointCloud{points: pts}
+	pc.Refresh()
+	return pc
+}
+
+// Len returns the number of points in the cloud.
+func (pc *PointCloud) Len() int {
+	return len(pc.points)
+}
+
+// Points returns the points in the cloud. The slice may be modified in place but Refresh must
+// be called afterwards to update the cloud's bounds and centroid.
+func (pc *PointCloud) Points() []Point3 {
+	return pc.points
+}
+
+// Add appends points to the cloud. The new points are added to the KD-tree by rebuilding it
+// once enough points have been appended since it was last built, so the cost of adding points
+// one at a time stays proportional to the cost of a single build.
+func (pc *PointCloud) Add(pts ...Point3) {
+	pc.points = append(pc.points, pts...)
+	pc.refreshStats()
+	if pending := len(pc.points) - pc.tree.Len(); pending > kdLeafSize && pending > pc.tree.Len()/4 {
+		pc.tree = buildKDTree(pc.points)
+	}
+}
+
+// Refresh recomputes the bounds and centroid of the cloud and rebuilds its KD-tree. It must be
+// called after modifying the slice returned by Points.
+func (pc *PointCloud) Refresh() {
+	pc.refreshStats()
+	pc.tree = buildKDTree(pc.points)
+}
+
+func (pc *PointCloud) refreshStats() {
+	pc.bounds = AABBFromPoints(pc.points)
+	pc.centroid = Point3{}
+	if len(pc.points) == 0 {
+		return
+	}
+
+	// Accumulate in float64 to limit the rounding error for large clouds
+	var sum [3]float64
+	for _, p := range pc.points {
+		sum[0] += float64(p[0])
+		sum[1] += float64(p[1])
+		sum[2] += float64(p[2])
+	}
+	n := float64(len(pc.points))
+	pc.centroid = Point3{float32(sum[0] / n), float32(sum[1] / n), float32(sum[2] / n)}
+}
+
+// Bounds returns the smallest AABB that contains all of the points.
+func (pc *PointCloud) Bounds() AABB {
+	return pc.bounds
+}
+
+// Centroid returns the mean position of the points.
+func (pc *PointCloud) Centroid() Point3 {
+	return pc.centroid
+}
+
+// Transform applies the affine transformation m to every point in the cloud.
+func (pc *PointCloud) Transform(m Mat4) {
+	TransformPointsParallel(m, pc.points, pc.points)
+	pc.Refresh()
+}
+
+// TransformBy converts every point in the cloud from the transform's local space into world
+// space.
+func (pc *PointCloud) TransformBy(tx *Transform) {
+	pc.Transform(tx.Matrix())
+}
+
+// Downsample returns a new cloud with at most one point in each cube of the given size. Points
+// that share a cube are replaced by their centroid. Cubes are aligned to the origin.
+func (pc *PointCloud) Downsample(size float32) *PointCloud {
+	if size <= 0 {
+		pts := make([]Point3, len(pc.points))
+		copy(pts, pc.points)
+		return NewPointCloud(pts)
+	}
+
+	type voxel struct {
+		sum Vec3
+		n   int
+	}
+	index := make(map[Vec3i]int)
+	var voxels []voxel
+
+	inv := 1 / size
+	for _, p := range pc.points {
+		key := Vec3iFromVec3(p.Mul(inv), RoundFloor)
+		i, ok := index[key]
+		if !ok {
+			i = len(voxels)
+			index[key] = i
+			voxels = append(voxels, voxel{})
+		}
+		voxels[i].sum = voxels[i].sum.Add(p)
+		voxels[i].n++
+	}
+
+	pts := make([]Point3, len(voxels))
+	for i, v := range voxels {
+		pts[i] = v.sum.Mul(1 / float32(v.n))
+	}
+	return NewPointCloud(pts)
+}
+
+// Nearest returns the index of the point that is closest to p and its squared distance from
+// p. It returns false if the cloud is empty.
+func (pc *PointCloud) Nearest(p Point3) (int, float32, bool) {
+	if len(pc.points) == 0 {
+		return 0, 0, false
+	}
+	best, bestDist := pc.tree.nearest(pc.points, p, -1, float32(math.Inf(1)))
+	for i := pc.tree.Len(); i < len(pc.points); i++ {
+		if d := DistanceSquared3(p, pc.points[i]); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best, bestDist, true
+}
+
+// KNearest appends the indexes of the k points closest to p to dst, nearest first, and
+// returns the extended slice. Fewer than k indexes are appended if the cloud has fewer points.
+func (pc *PointCloud) KNearest(dst []int, p Point3, k int) []int {
+	if k <= 0 {
+		return dst
+	}
+	h := kNearest{k: k, items: make([]int, 0, k), dists: make([]float32, 0, k)}
+	pc.tree.knearest(pc.points, p, &h)
+	for i := pc.tree.Len(); i < len(pc.points); i++ {
+		h.add(i, DistanceSquared3(p, pc.points[i]))
+	}
+	return append(dst, h.items...)
+}
+
+// WithinRadius appends the indexes of all points within radius r of p to dst in increasing
+// order and returns the extended slice.
+func (pc *PointCloud) WithinRadius(dst []int, p Point3, r float32) []int {
+	r2 := r * r
+	n := len(dst)
+	dst = pc.tree.withinRadius(dst, pc.points, p, r2)
+	sort.Ints(dst[n:])
+	for i := pc.tree.Len(); i < len(pc.points); i++ {
+		if DistanceSquared3(p, pc.points[i]) <= r2 {
+			dst = append(dst, i)
+		}
+	}
+	return dst
+}
+
+// EstimateNormals returns a unit normal for each point in the cloud, estimated from the plane
+// that best fits the point and its k nearest neighbours. The normals are oriented consistently
+// by propagating the orientation along the neighbour graph, following Hoppe et al, "Surface
+// Reconstruction from Unorganized Points", 1992. Propagation visits neighbours with the most
+// nearly parallel normals first, so it follows the surface around sharp folds.
+//
+// Within each group of connected points, orientation starts from the point furthest from the
+// centroid of the cloud, whose normal is chosen to point away from the centroid. For a closed
+// surface this usually orients every normal outwards. Every normal is zero if k is less than
+// 2 or the cloud has fewer than 3 points.
+func (pc *PointCloud) EstimateNormals(k int) []Vec3 {
+	n := len(pc.points)
+	normals := make([]Vec3, n)
+	if k < 2 || n < 3 {
+		return normals
+	}
+	if k > n-1 {
+		k = n - 1
+	}
+
+	// Each neighbourhood includes the point itself
+	nbrs := make([]int, n*(k+1))
+	parallelFor(n, 256, func(start, end int) {
+		local := make([]Point3, k+1)
+		for i := start; i < end; i++ {
+			nb := pc.KNearest(nbrs[i*(k+1):i*(k+1)], pc.points[i], k+1)
+			for j, q := range nb {
+				local[j] = pc.points[q]
+			}
+			pl, _ := FitPlane(local[:len(nb)])
+			normals[i] = pl.Normal
+		}
+	})
+
+	// The neighbour graph is made symmetric so propagation can cross from either end
+	adj := make([][]int32, n)
+	for i := 0; i < n; i++ {
+		for _, j := range nbrs[i*(k+1) : (i+1)*(k+1)] {
+			if j != i {
+				adj[i] = append(adj[i], int32(j))
+				adj[j] = append(adj[j], int32(i))
+			}
+		}
+	}
+
+	// Seed points are taken in order of decreasing distance from the centroid
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return DistanceSquared3(pc.points[order[a]], pc.centroid) > DistanceSquared3(pc.points[order[b]], pc.centroid)
+	})
+
+	// Prim's algorithm builds a maximum spanning tree of normal agreement, flipping each
+	// normal to agree with its parent as it is reached
+	visited := make([]bool, n)
+	var q normalHeap
+	for _, seed := range order {
+		if visited[seed] {
+			continue
+		}
+		if normals[seed].Dot(pc.points[seed].Sub(pc.centroid)) < 0 {
+			normals[seed] = normals[seed].Mul(-1)
+		}
+		heap.Push(&q, normalEdge{to: int32(seed), from: -1})
+		for q.Len() > 0 {
+			e := heap.Pop(&q).(normalEdge)
+			if visited[e.to] {
+				continue
+			}
+			visited[e.to] = true
+			if e.from >= 0 && normals[e.from].Dot(normals[e.to]) < 0 {
+				normals[e.to] = normals[e.to].Mul(-1)
+			}
+			for _, j := range adj[e.to] {
+				if !visited[j] {
+					heap.Push(&q, normalEdge{
+						cost: 1 - abs(normals[e.to].Dot(normals[j])),
+						from: e.to,
+						to:   j,
+					})
+				}
+			}
+		}
+	}
+	return normals
+}
+
+// normalEdge is an edge of the neighbour graph waiting to be crossed during normal orientation.
+type normalEdge struct {
+	cost     float32
+	from, to int32
+}
+
+type normalHeap []normalEdge
+
+func (h normalHeap) Len() int           { return len(h) }
+func (h normalHeap) Less(i, j int) bool { return h[i].cost < h[j].cost }
+func (h normalHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *normalHeap) Push(x any)        { *h = append(*h, x.(normalEdge)) }
+func (h *normalHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
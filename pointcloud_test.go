@@ -0,0 +1,218 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestPointCloud(t *testing.T) {
+	pc := NewPointCloud([]Point3{{0, 0, 0}, {2, 0, 0}, {2, 4, 0}, {0, 4, 6}})
+
+	if want := AABBFromCorners(Point3{0, 0, 0}, Point3{2, 4, 6}); pc.Bounds() != want {
+		t.Errorf("Bounds: got %v, wanted %v", pc.Bounds(), want)
+	}
+	if want := (Point3{1, 2, 1.5}); pc.Centroid() != want {
+		t.Errorf("Centroid: got %v, wanted %v", pc.Centroid(), want)
+	}
+
+	pc.Add(Point3{-2, 0, 0})
+	if want := AABBFromCorners(Point3{-2, 0, 0}, Point3{2, 4, 6}); pc.Bounds() != want {
+		t.Errorf("Bounds after Add: got %v, wanted %v", pc.Bounds(), want)
+	}
+
+	pc.Transform(mgl32.Translate3D(10, 0, 0))
+	if want := AABBFromCorners(Point3{8, 0, 0}, Point3{12, 4, 6}); pc.Bounds() != want {
+		t.Errorf("Bounds after Transform: got %v, wanted %v", pc.Bounds(), want)
+	}
+
+	i, d, ok := pc.Nearest(Point3{12, 3, 0})
+	if !ok || i != 2 || d != 1 {
+		t.Errorf("Nearest: got %d, %v, %v, wanted 2, 1, true", i, d, ok)
+	}
+	if got := pc.WithinRadius(nil, Point3{11, 0, 0}, 1.5); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("WithinRadius: got %v, wanted [0 1]", got)
+	}
+
+	if _, _, ok := NewPointCloud(nil).Nearest(Point3{}); ok {
+		t.Errorf("Nearest in empty cloud should fail")
+	}
+}
+
+func TestPointCloudDownsample(t *testing.T) {
+	pc := NewPointCloud([]Point3{
+		{0.1, 0.1, 0.1},
+		{0.3, 0.5, 0.7},
+		{1.5, 0.5, 0.5},
+		{-0.5, 0.5, 0.5},
+		{-0.7, 0.5, 0.5},
+	})
+
+	got := pc.Downsample(1)
+	want := []Point3{{0.2, 0.3, 0.4}, {1.5, 0.5, 0.5}, {-0.6, 0.5, 0.5}}
+	if got.Len() != len(want) {
+		t.Fatalf("got %d points, wanted %d", got.Len(), len(want))
+	}
+	for i := range want {
+		if !nearVec3(got.Points()[i], want[i], 1e-6) {
+			t.Errorf("point %d: got %v, wanted %v", i, got.Points()[i], want[i])
+		}
+	}
+}
+
+func TestPointCloudNeighbours(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomPoint := func() Point3 {
+		// Snap to a coarse grid so that some points are equally distant from the queries
+		return Point3{float32(r.Intn(40)) / 4, float32(r.Intn(40)) / 4, float32(r.Intn(8)) / 4}
+	}
+
+	// bruteForce returns the indexes of every point ordered by distance from p and then by
+	// index, and their squared distances.
+	bruteForce := func(pc *PointCloud, p Point3) ([]int, []float32) {
+		idx := make([]int, pc.Len())
+		for i := range idx {
+			idx[i] = i
+		}
+		pts := pc.Points()
+		sort.SliceStable(idx, func(i, j int) bool {
+			return DistanceSquared3(p, pts[idx[i]]) < DistanceSquared3(p, pts[idx[j]])
+		})
+		dists := make([]float32, len(idx))
+		for i := range idx {
+			dists[i] = DistanceSquared3(p, pts[idx[i]])
+		}
+		return idx, dists
+	}
+
+	pc := &PointCloud{}
+	check := func(step string) {
+		for q := 0; q < 50; q++ {
+			p := randomPoint()
+			want, dists := bruteForce(pc, p)
+
+			i, d, ok := pc.Nearest(p)
+			if ok != (pc.Len() > 0) || (ok && (i != want[0] || d != dists[0])) {
+				t.Fatalf("%s: Nearest(%v) got %d, %v, wanted %v", step, p, i, d, want)
+			}
+
+			for _, k := range []int{1, 5, 20} {
+				got := pc.KNearest(nil, p, k)
+				n := k
+				if n > pc.Len() {
+					n = pc.Len()
+				}
+				if len(got) != n {
+					t.Fatalf("%s: KNearest(%v, %d) got %d points, wanted %d", step, p, k, len(got), n)
+				}
+				for j := range got {
+					if got[j] != want[j] {
+						t.Fatalf("%s: KNearest(%v, %d) got %v, wanted %v", step, p, k, got, want[:n])
+					}
+				}
+			}
+
+			radius := r.Float32() * 3
+			var inside []int
+			for j := range want {
+				if dists[j] <= radius*radius {
+					inside = append(inside, want[j])
+				}
+			}
+			sort.Ints(inside)
+			got := pc.WithinRadius([]int{-1}, p, radius)
+			if len(got) != len(inside)+1 || got[0] != -1 {
+				t.Fatalf("%s: WithinRadius(%v, %v) got %v, wanted %v", step, p, radius, got, inside)
+			}
+			for j := range inside {
+				if got[j+1] != inside[j] {
+					t.Fatalf("%s: WithinRadius(%v, %v) got %v, wanted %v", step, p, radius, got[1:], inside)
+				}
+			}
+		}
+	}
+
+	check("empty")
+	for i := 0; i < 300; i++ {
+		pc.Add(randomPoint())
+		if i%37 == 0 {
+			check("adding")
+		}
+	}
+	check("added")
+
+	pts := make([]Point3, 1000)
+	for i := range pts {
+		pts[i] = randomPoint()
+	}
+	pc = NewPointCloud(pts)
+	check("built")
+	pc.Add(randomPoint(), randomPoint(), randomPoint())
+	check("appended")
+}
+
+func BenchmarkPointCloudKNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	pts := make([]Point3, 100000)
+	for i := range pts {
+		pts[i] = Point3{r.Float32() * 100, r.Float32() * 100, r.Float32() * 100}
+	}
+	pc := NewPointCloud(pts)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var dst []int
+	for i := 0; i < b.N; i++ {
+		dst = pc.KNearest(dst[:0], pts[i%len(pts)], 16)
+	}
+	b.StopTimer()
+	bres = dst
+}
+
+func TestPointCloudEstimateNormals(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Run("sphere", func(t *testing.T) {
+		// Evenly spread points on a sphere using a Fibonacci spiral
+		const n = 500
+		centre := Point3{3, -2, 1}
+		pts := make([]Point3, n)
+		for i := range pts {
+			z := 1 - 2*(float32(i)+0.5)/n
+			rr := sqrt(1 - z*z)
+			phi := float64(i) * math.Pi * (3 - math.Sqrt(5))
+			pts[i] = centre.Add(Vec3{rr * float32(math.Cos(phi)), rr * float32(math.Sin(phi)), z}.Mul(2))
+		}
+		pc := NewPointCloud(pts)
+		normals := pc.EstimateNormals(10)
+		for i, nm := range normals {
+			want := pts[i].Sub(centre).Normalize()
+			if d := nm.Dot(want); d < 0.98 {
+				t.Fatalf("point %d: got normal %v, wanted close to %v", i, nm, want)
+			}
+		}
+	})
+
+	t.Run("noisy plane", func(t *testing.T) {
+		pts := make([]Point3, 400)
+		for i := range pts {
+			pts[i] = Point3{float32(i%20) + r.Float32()*0.2, float32(i/20) + r.Float32()*0.2, r.Float32() * 0.02}
+		}
+		normals := NewPointCloud(pts).EstimateNormals(8)
+		for i, nm := range normals {
+			if abs(nm.Len()-1) > 1e-4 || nm.Dot(normals[0]) < 0.95 || abs(nm[2]) < 0.95 {
+				t.Fatalf("point %d: got normal %v, wanted parallel to %v and the z axis", i, nm, normals[0])
+			}
+		}
+	})
+
+	t.Run("too few", func(t *testing.T) {
+		normals := NewPointCloud([]Point3{{0, 0, 0}, {1, 0, 0}}).EstimateNormals(5)
+		if len(normals) != 2 || normals[0] != (Vec3{}) || normals[1] != (Vec3{}) {
+			t.Errorf("got %v, wanted two zero normals", normals)
+		}
+	})
+}
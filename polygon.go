@@ -0,0 +1,376 @@
+package geom
+
+import "sort"
+
+// Polygon2 is a closed polygon in 2 dimensions. The last point is joined to the first and
+// should not repeat it. Functions that create polygons return points in counter clockwise
+// order.
+type Polygon2 struct {
+	Points []Point2
+
+	// Holes are the boundaries of regions inside the polygon that are not part of it. They are
+	// closed in the same way as Points and must not overlap each other or the outer boundary.
+	// Holes are taken into account by Area, SignedArea, ContainsPoint2, Inflate and Inset;
+	// other methods only consider the outer boundary.
+	Holes [][]Point2
+}
+
+// SignedArea returns the area of the polygon, less the area of any holes. The area is positive
+// when the points are in counter clockwise order and negative when they are clockwise.
+func (p *Polygon2) SignedArea() float32 {
+	a := ringSignedArea(p.Points)
+	for _, h := range p.Holes {
+		if a < 0 {
+			a += abs(ringSignedArea(h))
+		} else {
+			a -= abs(ringSignedArea(h))
+		}
+	}
+	return a
+}
+
+// ringSignedArea returns the signed area enclosed by a closed ring of points.
+func ringSignedArea(pts []Point2) float32 {
+	var a float32
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		a += Cross2(pts[i], pts[j])
+	}
+	return a / 2
+}
+
+// Area returns the area of the polygon.
+func (p *Polygon2) Area() float32 {
+	return abs(p.SignedArea())
+}
+
+// IsConvex reports whether the polygon is convex. Collinear points are allowed.
+func (p *Polygon2) IsConvex() bool {
+	n := len(p.Points)
+	if n < 3 {
+		return false
+	}
+	var sign float32
+	for i := 0; i < n; i++ {
+		a, b, c := p.Points[i], p.Points[(i+1)%n], p.Points[(i+2)%n]
+		cr := Cross2(b.Sub(a), c.Sub(b))
+		if cr == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cr
+		} else if (cr > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return sign != 0
+}
+
+// ContainsPoint2 reports whether the point lies inside the polygon using the even-odd rule.
+// Points inside a hole are not inside the polygon.
+func (p *Polygon2) ContainsPoint2(pt Point2) bool {
+	if !ringContainsPoint2(p.Points, pt) {
+		return false
+	}
+	for _, h := range p.Holes {
+		if ringContainsPoint2(h, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContainsPoint2 reports whether the point lies inside a closed ring of points using the
+// even-odd rule.
+func ringContainsPoint2(pts []Point2, pt Point2) bool {
+	inside := false
+	n := len(pts)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := pts[i], pts[j]
+		if (a[1] > pt[1]) != (b[1] > pt[1]) {
+			x := a[0] + (pt[1]-a[1])*(b[0]-a[0])/(b[1]-a[1])
+			if pt[0] < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Inflate returns the polygon made by moving each edge outward by d and extending the edges
+// until they meet. The edges of holes move into the holes, shrinking them, and holes that
+// close up are dropped. Repeated points are ignored.
+//
+// The result contains every point within d of the original polygon, and extends further near
+// sharp convex corners, as long as no moved edges cross. That holds for any d when the polygon
+// and its holes are convex. Near concave corners, narrow gaps and holes, edges moved further
+// than the size of the features between them do cross and the result intersects itself; it is
+// not cleaned up. The polygon must be simple.
+func (p *Polygon2) Inflate(d float32) Polygon2 {
+	res := Polygon2{Points: offsetRing2(p.Points, d)}
+	for _, h := range p.Holes {
+		// Moving the edges of a hole away from the polygon moves them into the hole. A hole that
+		// closes up turns inside out, which reverses its winding.
+		oh := offsetRing2(h, -d)
+		if len(oh) >= 3 && ringSignedArea(oh)*ringSignedArea(h) > 0 {
+			res.Holes = append(res.Holes, oh)
+		}
+	}
+	return res
+}
+
+// offsetRing2 returns the closed ring made by moving each edge of pts away from the region it
+// encloses by d, or towards it when d is negative, and extending the edges until they meet.
+// Repeated points are dropped first, and rings with fewer than three distinct points are
+// returned without moving.
+func offsetRing2(pts []Point2, d float32) []Point2 {
+	ring := make([]Point2, 0, len(pts))
+	for _, pt := range pts {
+		if len(ring) == 0 || pt != ring[len(ring)-1] {
+			ring = append(ring, pt)
+		}
+	}
+	for len(ring) > 1 && ring[len(ring)-1] == ring[0] {
+		ring = ring[:len(ring)-1]
+	}
+	n := len(ring)
+	if n < 3 {
+		return ring
+	}
+
+	// The outward normal of each edge depends on the winding
+	side := float32(1)
+	if ringSignedArea(ring) < 0 {
+		side = -1
+	}
+	normal := func(a, b Point2) Vec2 {
+		e := b.Sub(a).Normalize()
+		return Vec2{e[1], -e[0]}.Mul(side)
+	}
+
+	res := make([]Point2, n)
+	for i, cur := range ring {
+		prev := ring[(i+n-1)%n]
+		next := ring[(i+1)%n]
+
+		n0 := normal(prev, cur)
+		n1 := normal(cur, next)
+
+		// Move along the bisector far enough that both offset edges pass through the point
+		bisector := n0.Add(n1)
+		cos := 1 + n0.Dot(n1)
+		if cos < epsilon32 {
+			// The edges double back on each other so there is no meeting point
+			res[i] = cur.Add(n0.Mul(d))
+			continue
+		}
+		res[i] = cur.Add(bisector.Mul(d / cos))
+	}
+	return res
+}
+
+// polygonsFromRings makes polygons from closed rings of points that do not cross each other.
+// Counter clockwise rings are outer boundaries and clockwise rings are holes, which are
+// attached to the smallest outer boundary that contains them. Holes outside every boundary
+// are dropped.
+func polygonsFromRings(rings [][]Point2) []Polygon2 {
+	var res []Polygon2
+	var holes [][]Point2
+	for _, r := range rings {
+		if ringSignedArea(r) > 0 {
+			res = append(res, Polygon2{Points: r})
+		} else {
+			holes = append(holes, r)
+		}
+	}
+	for _, h := range holes {
+		best := -1
+		var bestArea float32
+		for i := range res {
+			if !ringContainsPoint2(res[i].Points, h[0]) {
+				continue
+			}
+			if a := ringSignedArea(res[i].Points); best < 0 || a < bestArea {
+				best, bestArea = i, a
+			}
+		}
+		if best >= 0 {
+			res[best].Holes = append(res[best].Holes, h)
+		}
+	}
+	return res
+}
+
+// simplifyRing2 removes points from a closed ring using the Douglas-Peucker algorithm, so that
+// no removed point is further than tolerance from the result.
+func simplifyRing2(pts []Point2, tolerance float32) []Point2 {
+	if len(pts) < 4 {
+		return pts
+	}
+	// Split the ring at the first point and the point furthest from it
+	far, farDist := 0, float32(-1)
+	for i, p := range pts {
+		if d := p.Sub(pts[0]).LenSqr(); d > farDist {
+			far, farDist = i, d
+		}
+	}
+	first := simplifyPolyline2(pts[:far+1], tolerance)
+	second := simplifyPolyline2(append(append([]Point2(nil), pts[far:]...), pts[0]), tolerance)
+	return append(first, second[1:len(second)-1]...)
+}
+
+// simplifyPolyline2 removes points from an open polyline using the Douglas-Peucker algorithm,
+// keeping the end points, so that no removed point is further than tolerance from the result.
+func simplifyPolyline2(pts []Point2, tolerance float32) []Point2 {
+	if len(pts) < 3 {
+		return append([]Point2(nil), pts...)
+	}
+	keep := make([]bool, len(pts))
+	keep[0], keep[len(pts)-1] = true, true
+	stack := [][2]int{{0, len(pts) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		seg := Line2{Start: pts[span[0]], End: pts[span[1]]}
+		far, farDist := -1, tolerance
+		for i := span[0] + 1; i < span[1]; i++ {
+			if d := pts[i].Sub(seg.ClosestPoint(pts[i])).Len(); d > farDist {
+				far, farDist = i, d
+			}
+		}
+		if far >= 0 {
+			keep[far] = true
+			stack = append(stack, [2]int{span[0], far}, [2]int{far, span[1]})
+		}
+	}
+	var res []Point2
+	for i, k := range keep {
+		if k {
+			res = append(res, pts[i])
+		}
+	}
+	return res
+}
+
+// ConvexHull2 returns the smallest convex polygon that contains all of the points, with its
+// points in counter clockwise order. Points that lie on the edges of the hull are omitted.
+func ConvexHull2(pts []Point2) Polygon2 {
+	// Andrew's monotone chain algorithm
+	sorted := make([]Point2, len(pts))
+	copy(sorted, pts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+	if len(sorted) < 3 {
+		return Polygon2{Points: sorted}
+	}
+
+	hull := make([]Point2, 0, 2*len(sorted))
+	for _, p := range sorted {
+		for len(hull) >= 2 && Cross2(hull[len(hull)-1].Sub(hull[len(hull)-2]), p.Sub(hull[len(hull)-1])) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(sorted) - 2; i >= 0; i-- {
+		p := sorted[i]
+		for len(hull) >= lower && Cross2(hull[len(hull)-1].Sub(hull[len(hull)-2]), p.Sub(hull[len(hull)-1])) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// The last point is the same as the first
+	return Polygon2{Points: hull[:len(hull)-1]}
+}
+
+// MinkowskiSum2 returns the Minkowski sum of two convex polygons, which is the polygon swept
+// out by moving b over every point of a. Both polygons must be convex with their points in
+// counter clockwise order.
+func MinkowskiSum2(a, b Polygon2) Polygon2 {
+	if len(a.Points) == 0 || len(b.Points) == 0 {
+		return Polygon2{}
+	}
+
+	ia, ib := lowestPoint2(a.Points), lowestPoint2(b.Points)
+	na, nb := len(a.Points), len(b.Points)
+
+	// Merge the edges of both polygons in order of their angle, starting from the lowest points
+	res := make([]Point2, 0, na+nb)
+	i, j := 0, 0
+	for i < na || j < nb {
+		pa := a.Points[(ia+i)%na]
+		pb := b.Points[(ib+j)%nb]
+		res = append(res, pa.Add(pb))
+
+		ea := a.Points[(ia+i+1)%na].Sub(pa)
+		eb := b.Points[(ib+j+1)%nb].Sub(pb)
+		cr := Cross2(ea, eb)
+		switch {
+		case j >= nb || (i < na && cr > 0):
+			i++
+		case i >= na || cr < 0:
+			j++
+		default:
+			// Parallel edges are combined into one
+			i++
+			j++
+		}
+	}
+
+	return ConvexHull2(res)
+}
+
+// MinkowskiDifference2 returns the Minkowski difference of two convex polygons, the sum of a
+// and b reflected through the origin. The polygons overlap if and only if the difference
+// contains the origin. Both polygons must be convex with their points in counter clockwise
+// order.
+func MinkowskiDifference2(a, b Polygon2) Polygon2 {
+	neg := Polygon2{Points: make([]Point2, len(b.Points))}
+	for i, p := range b.Points {
+		neg.Points[i] = p.Mul(-1)
+	}
+	return MinkowskiSum2(a, neg)
+}
+
+// lowestPoint2 returns the index of the point with the smallest y, using the smallest x to
+// break ties.
+func lowestPoint2(pts []Point2) int {
+	best := 0
+	for i, p := range pts {
+		if p[1] < pts[best][1] || (p[1] == pts[best][1] && p[0] < pts[best][0]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// MinkowskiSum3 returns the Minkowski sum of the convex hulls of two sets of points, as a
+// closed mesh like that of ConvexHull3.
+func MinkowskiSum3(a, b []Point3) *TriMesh {
+	return minkowski3(a, b, 1)
+}
+
+// MinkowskiDifference3 returns the Minkowski difference of the convex hulls of two sets of
+// points, the sum of a and b reflected through the origin. The hulls overlap if and only if
+// the difference contains the origin.
+func MinkowskiDifference3(a, b []Point3) *TriMesh {
+	return minkowski3(a, b, -1)
+}
+
+func minkowski3(a, b []Point3, sign float32) *TriMesh {
+	// Only the corners of each hull can be corners of the sum, so discard the rest before
+	// forming the pairwise sums
+	ha, hb := ConvexHull3(a), ConvexHull3(b)
+	pts := make([]Point3, 0, len(ha.Positions)*len(hb.Positions))
+	for _, pa := range ha.Positions {
+		for _, pb := range hb.Positions {
+			pts = append(pts, pa.Add(pb.Mul(sign)))
+		}
+	}
+	return ConvexHull3(pts)
+}
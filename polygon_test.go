@@ -0,0 +1,177 @@
+package geom
+
+import "testing"
+
+func equalPolygon2(a, b Polygon2) bool {
+	if !equalRing2(a.Points, b.Points) || len(a.Holes) != len(b.Holes) {
+		return false
+	}
+	for i := range a.Holes {
+		if !equalRing2(a.Holes[i], b.Holes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalRing2(a, b []Point2) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Sub(b[i]).Len() > 1e-5 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConvexHull2(t *testing.T) {
+	pts := []Point2{{0, 0}, {2, 0}, {1, 1}, {2, 2}, {0, 2}, {1, 0}, {1, 2}, {0.5, 1.5}}
+	got := ConvexHull2(pts)
+	want := Polygon2{Points: []Point2{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	if !equalPolygon2(got, want) {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+	if got.SignedArea() != 4 {
+		t.Errorf("got signed area %v, wanted %v", got.SignedArea(), 4)
+	}
+	if !got.IsConvex() {
+		t.Errorf("hull should be convex")
+	}
+}
+
+func TestPolygon2(t *testing.T) {
+	// An L shaped polygon
+	p := Polygon2{Points: []Point2{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}}
+	if got := p.Area(); got != 3 {
+		t.Errorf("Area: got %v, wanted %v", got, 3)
+	}
+	if p.IsConvex() {
+		t.Errorf("IsConvex: L shape should not be convex")
+	}
+	if !p.ContainsPoint2(Point2{0.5, 1.5}) || p.ContainsPoint2(Point2{1.5, 1.5}) {
+		t.Errorf("ContainsPoint2: wrong result for L shape")
+	}
+}
+
+func TestMinkowskiSum2(t *testing.T) {
+	square := Polygon2{Points: []Point2{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}}
+	tri := Polygon2{Points: []Point2{{0, 0}, {2, 0}, {0, 2}}}
+
+	testCases := []struct {
+		name string
+		a, b Polygon2
+		want Polygon2
+	}{
+		{
+			name: "square square",
+			a:    square,
+			b:    square,
+			want: Polygon2{Points: []Point2{{-2, -2}, {2, -2}, {2, 2}, {-2, 2}}},
+		},
+		{
+			name: "triangle square",
+			a:    tri,
+			b:    square,
+			want: Polygon2{Points: []Point2{{-1, -1}, {3, -1}, {3, 1}, {1, 3}, {-1, 3}}},
+		},
+		{
+			name: "square triangle",
+			a:    square,
+			b:    tri,
+			want: Polygon2{Points: []Point2{{-1, -1}, {3, -1}, {3, 1}, {1, 3}, {-1, 3}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MinkowskiSum2(tc.a, tc.b)
+			if !equalPolygon2(got, tc.want) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMinkowskiDifference(t *testing.T) {
+	square := Polygon2{Points: []Point2{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}}
+	near := Polygon2{Points: []Point2{{0.5, 0.5}, {2.5, 0.5}, {2.5, 2.5}, {0.5, 2.5}}}
+	far := Polygon2{Points: []Point2{{3, 0}, {5, 0}, {5, 2}, {3, 2}}}
+
+	if d := MinkowskiDifference2(square, near); !d.ContainsPoint2(Point2{}) {
+		t.Errorf("overlapping squares: difference %v should contain origin", d)
+	}
+	if d := MinkowskiDifference2(square, far); d.ContainsPoint2(Point2{}) {
+		t.Errorf("separate squares: difference %v should not contain origin", d)
+	}
+
+	big := boxCorners(Point3{}, 1)
+	small := boxCorners(Point3{}, 0.5)
+	if sum := MinkowskiSum3(big, small); len(sum.Positions) != 8 || abs(meshVolume(sum)-27) > 1e-4 {
+		t.Errorf("MinkowskiSum3: got %d corners and volume %v, wanted 8 and 27", len(sum.Positions), meshVolume(sum))
+	}
+	if d := MinkowskiDifference3(big, boxCorners(Point3{1, 1, 1}, 0.5)); !convexMeshContains(d, Point3{}) {
+		t.Errorf("overlapping boxes: difference should contain origin")
+	}
+	if d := MinkowskiDifference3(big, boxCorners(Point3{3, 0, 0}, 0.5)); convexMeshContains(d, Point3{}) {
+		t.Errorf("separate boxes: difference should not contain origin")
+	}
+}
+
+func TestPolygon2Inflate(t *testing.T) {
+	testCases := []struct {
+		name string
+		p    Polygon2
+		want Polygon2
+	}{
+		{
+			name: "ccw square",
+			p:    Polygon2{Points: []Point2{{0, 0}, {2, 0}, {2, 2}, {0, 2}}},
+			want: Polygon2{Points: []Point2{{-1, -1}, {3, -1}, {3, 3}, {-1, 3}}},
+		},
+		{
+			name: "cw square",
+			p:    Polygon2{Points: []Point2{{0, 0}, {0, 2}, {2, 2}, {2, 0}}},
+			want: Polygon2{Points: []Point2{{-1, -1}, {-1, 3}, {3, 3}, {3, -1}}},
+		},
+		{
+			name: "l shape",
+			p:    Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 4}, {0, 4}}},
+			want: Polygon2{Points: []Point2{{-1, -1}, {5, -1}, {5, 3}, {3, 3}, {3, 5}, {-1, 5}}},
+		},
+		{
+			name: "repeated points",
+			p:    Polygon2{Points: []Point2{{0, 0}, {0, 0}, {2, 0}, {2, 2}, {2, 2}, {0, 2}, {0, 0}}},
+			want: Polygon2{Points: []Point2{{-1, -1}, {3, -1}, {3, 3}, {-1, 3}}},
+		},
+		{
+			name: "hole",
+			p: Polygon2{
+				Points: []Point2{{0, 0}, {8, 0}, {8, 8}, {0, 8}},
+				Holes:  [][]Point2{{{2, 2}, {2, 6}, {6, 6}, {6, 2}}},
+			},
+			want: Polygon2{
+				Points: []Point2{{-1, -1}, {9, -1}, {9, 9}, {-1, 9}},
+				Holes:  [][]Point2{{{3, 3}, {3, 5}, {5, 5}, {5, 3}}},
+			},
+		},
+		{
+			name: "hole that closes",
+			p: Polygon2{
+				Points: []Point2{{0, 0}, {8, 0}, {8, 8}, {0, 8}},
+				Holes:  [][]Point2{{{2, 2}, {2, 6}, {3, 6}, {3, 2}}},
+			},
+			want: Polygon2{Points: []Point2{{-1, -1}, {9, -1}, {9, 9}, {-1, 9}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.p.Inflate(1)
+			if !equalPolygon2(got, tc.want) {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
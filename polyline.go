@@ -0,0 +1,366 @@
+package geom
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// IntersectPolylines returns the points where the open polyline a meets the open polyline b,
+// in order of increasing x and then y. Points where a crosses itself or b crosses itself are
+// not included. Where segments of the two polylines overlap, the ends of the overlap are
+// returned.
+//
+// The intersections are found with a Bentley-Ottmann sweep rather than by testing every pair
+// of segments, which takes O((n+k) log n) time for n segments meeting at k points.
+func IntersectPolylines(a, b []Point2) []Point2 {
+	var segs []sweepSegment
+	segs = appendPolylineSegments(segs, a, 0, false)
+	segs = appendPolylineSegments(segs, b, 1, false)
+
+	var res []Point2
+	sweepSegments(segs, func(p vec2d, group []*sweepSegment) bool {
+		for _, s := range group[1:] {
+			if s.tag != group[0].tag {
+				res = append(res, p.point())
+				break
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// IsSimple reports whether the boundaries of the polygon do not cross or touch themselves or
+// each other, other than where neighbouring edges of a boundary meet at their shared point.
+// Each boundary must have at least three points with no point repeated.
+func IsSimple(p Polygon2) bool {
+	rings := append([][]Point2{p.Points}, p.Holes...)
+	var segs []sweepSegment
+	for i, r := range rings {
+		if len(r) < 3 {
+			return false
+		}
+		n := len(segs)
+		segs = appendPolylineSegments(segs, r, i, true)
+		if len(segs)-n != len(r) {
+			// A repeated point
+			return false
+		}
+	}
+
+	simple := true
+	sweepSegments(segs, func(p vec2d, group []*sweepSegment) bool {
+		if len(group) == 2 && group[0].tag == group[1].tag {
+			s, t := group[0], group[1]
+			n := len(rings[s.tag])
+			if (s.index+1)%n == t.index || (t.index+1)%n == s.index {
+				// Neighbouring edges may meet at their shared point only
+				if shared, ok := s.sharedEnd(t); ok && shared == p {
+					return true
+				}
+			}
+		}
+		simple = false
+		return false
+	})
+	return simple
+}
+
+// appendPolylineSegments appends the segments joining the points, closing the loop if closed
+// is true. Segments of zero length are skipped.
+func appendPolylineSegments(segs []sweepSegment, pts []Point2, tag int, closed bool) []sweepSegment {
+	n := len(pts) - 1
+	if closed {
+		n = len(pts)
+	}
+	for i := 0; i < n; i++ {
+		p, q := vec2dFromPoint(pts[i]), vec2dFromPoint(pts[(i+1)%len(pts)])
+		if p == q {
+			continue
+		}
+		s := sweepSegment{a: p, b: q, tag: tag, index: i}
+		if sweepLess(q, p) {
+			s.a, s.b = q, p
+		}
+		segs = append(segs, s)
+	}
+	return segs
+}
+
+// sweepSegment is a segment for a Bentley-Ottmann sweep with a before b in sweep order.
+type sweepSegment struct {
+	a, b  vec2d
+	tag   int // the polyline or ring the segment belongs to
+	index int // the position of the segment in its polyline
+}
+
+// yAt returns the y coordinate of the segment at x. Vertical segments return y clamped to
+// their extent.
+func (s *sweepSegment) yAt(x, y float64) float64 {
+	if s.a[0] == s.b[0] {
+		return math.Max(s.a[1], math.Min(y, s.b[1]))
+	}
+	if x == s.a[0] {
+		return s.a[1]
+	}
+	if x == s.b[0] {
+		return s.b[1]
+	}
+	t := (x - s.a[0]) / (s.b[0] - s.a[0])
+	return s.a[1] + t*(s.b[1]-s.a[1])
+}
+
+// slope returns the gradient of the segment, which is infinite for vertical segments.
+func (s *sweepSegment) slope() float64 {
+	if s.a[0] == s.b[0] {
+		return math.Inf(1)
+	}
+	return (s.b[1] - s.a[1]) / (s.b[0] - s.a[0])
+}
+
+// sharedEnd returns an end point the two segments have in common.
+func (s *sweepSegment) sharedEnd(t *sweepSegment) (vec2d, bool) {
+	for _, p := range [2]vec2d{s.a, s.b} {
+		if p == t.a || p == t.b {
+			return p, true
+		}
+	}
+	return vec2d{}, false
+}
+
+// sweepLess orders points by x and then by y.
+func sweepLess(p, q vec2d) bool {
+	return p[0] < q[0] || (p[0] == q[0] && p[1] < q[1])
+}
+
+// sweepEvent is a point where the sweep line stops: the start of seg, or a place where
+// segments may meet when seg is nil.
+type sweepEvent struct {
+	p   vec2d
+	seg *sweepSegment
+}
+
+type sweepQueue []sweepEvent
+
+func (q sweepQueue) Len() int           { return len(q) }
+func (q sweepQueue) Less(i, j int) bool { return sweepLess(q[i].p, q[j].p) }
+func (q sweepQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *sweepQueue) Push(x any)        { *q = append(*q, x.(sweepEvent)) }
+func (q *sweepQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// sweepSegments runs a Bentley-Ottmann sweep over the segments, calling meet with each point
+// where two or more segments meet and the segments that pass through, start or end at it.
+// The sweep stops early if meet returns false.
+func sweepSegments(segs []sweepSegment, meet func(p vec2d, group []*sweepSegment) bool) {
+	var extent float64 = 1
+	q := make(sweepQueue, 0, 2*len(segs))
+	for i := range segs {
+		s := &segs[i]
+		q = append(q, sweepEvent{p: s.a, seg: s}, sweepEvent{p: s.b})
+		extent = math.Max(extent, math.Max(math.Max(math.Abs(s.a[0]), math.Abs(s.a[1])), math.Max(math.Abs(s.b[0]), math.Abs(s.b[1]))))
+	}
+	heap.Init(&q)
+	eps := 1e-9 * extent
+
+	status := sweepStatus{seed: 0x9e3779b97f4a7c15}
+	var group, starts, cont []*sweepSegment
+
+	for q.Len() > 0 {
+		e := heap.Pop(&q).(sweepEvent)
+		p := e.p
+		starts = starts[:0]
+		for {
+			if e.seg != nil {
+				starts = append(starts, e.seg)
+			}
+			if q.Len() == 0 || q[0].p != p {
+				break
+			}
+			e = heap.Pop(&q).(sweepEvent)
+		}
+		group = group[:0]
+
+		// The segments in the status that end at or pass through p are next to each other
+		below, rest := sweepSplit(status.root, func(s *sweepSegment) bool {
+			return s.yAt(p[0], p[1]) >= p[1]-eps
+		})
+		through, above := sweepSplit(rest, func(s *sweepSegment) bool {
+			return s.yAt(p[0], p[1]) > p[1]+eps
+		})
+		group = through.appendTo(group)
+		status.release(through)
+		group = append(group, starts...)
+		if len(group) > 1 && !meet(p, group) {
+			return
+		}
+
+		// Replace the segments through p with those that continue past it, in their order just
+		// after p
+		cont = cont[:0]
+		for _, s := range group {
+			if sweepLess(p, s.b) {
+				cont = append(cont, s)
+			}
+		}
+		sort.SliceStable(cont, func(i, j int) bool { return cont[i].slope() < cont[j].slope() })
+		var middle *sweepNode
+		for _, s := range cont {
+			middle = sweepMerge(middle, status.node(s))
+		}
+		lower, upper := below.last(), above.first()
+		first, last := middle.first(), middle.last()
+		status.root = sweepMerge(sweepMerge(below, middle), above)
+
+		// Look for meetings between the new neighbours after p
+		check := func(s, t *sweepSegment) {
+			if s == nil || t == nil {
+				return
+			}
+			if x, ok := sweepIntersection(s, t); ok && sweepLess(p, x) {
+				heap.Push(&q, sweepEvent{p: x})
+			}
+		}
+		if middle == nil {
+			check(lower, upper)
+		} else {
+			check(lower, first)
+			check(last, upper)
+		}
+	}
+}
+
+// sweepStatus holds the segments crossing the sweep line, ordered from bottom to top. It is a
+// treap so that segments can be found, removed and inserted in O(log n) time.
+type sweepStatus struct {
+	root *sweepNode
+	seed uint64
+	free []*sweepNode // nodes removed from the tree, for reuse
+}
+
+// sweepNode is a node of a sweepStatus. Its segment is above those in its left subtree and
+// below those in its right, and its priority is no less than those of its children.
+type sweepNode struct {
+	seg         *sweepSegment
+	prio        uint64
+	left, right *sweepNode
+}
+
+// node returns a new node for s. The priorities come from an xorshift generator, which keeps
+// the tree balanced in expectation while making every sweep repeatable.
+func (st *sweepStatus) node(s *sweepSegment) *sweepNode {
+	st.seed ^= st.seed << 13
+	st.seed ^= st.seed >> 7
+	st.seed ^= st.seed << 17
+	if len(st.free) == 0 {
+		return &sweepNode{seg: s, prio: st.seed}
+	}
+	n := st.free[len(st.free)-1]
+	st.free = st.free[:len(st.free)-1]
+	*n = sweepNode{seg: s, prio: st.seed}
+	return n
+}
+
+// release keeps the nodes of a tree that has been removed from the status for reuse.
+func (st *sweepStatus) release(n *sweepNode) {
+	if n == nil {
+		return
+	}
+	st.release(n.left)
+	st.release(n.right)
+	st.free = append(st.free, n)
+}
+
+// sweepSplit splits the tree into the nodes before the first one whose segment satisfies f,
+// and the rest. f must be false for a prefix of the segments and true for the remainder.
+func sweepSplit(n *sweepNode, f func(s *sweepSegment) bool) (l, r *sweepNode) {
+	if n == nil {
+		return nil, nil
+	}
+	if f(n.seg) {
+		l, n.left = sweepSplit(n.left, f)
+		return l, n
+	}
+	n.right, r = sweepSplit(n.right, f)
+	return n, r
+}
+
+// sweepMerge joins two trees where every segment in l is below every segment in r.
+func sweepMerge(l, r *sweepNode) *sweepNode {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	if l.prio >= r.prio {
+		l.right = sweepMerge(l.right, r)
+		return l
+	}
+	r.left = sweepMerge(l, r.left)
+	return r
+}
+
+// appendTo appends the segments of the tree to segs in order.
+func (n *sweepNode) appendTo(segs []*sweepSegment) []*sweepSegment {
+	if n == nil {
+		return segs
+	}
+	segs = n.left.appendTo(segs)
+	segs = append(segs, n.seg)
+	return n.right.appendTo(segs)
+}
+
+// first returns the lowest segment in the tree, or nil if it is empty.
+func (n *sweepNode) first() *sweepSegment {
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.seg
+}
+
+// last returns the highest segment in the tree, or nil if it is empty.
+func (n *sweepNode) last() *sweepSegment {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.seg
+}
+
+// sweepIntersection returns the point where two segments cross. Parallel segments do not
+// cross; where they overlap they meet at their end points instead.
+func sweepIntersection(s, t *sweepSegment) (vec2d, bool) {
+	ds, dt := s.b.sub(s.a), t.b.sub(t.a)
+	denom := ds.cross(dt)
+	if denom == 0 {
+		return vec2d{}, false
+	}
+	st := t.a.sub(s.a)
+	u := st.cross(dt) / denom
+	v := st.cross(ds) / denom
+	if u < 0 || u > 1 || v < 0 || v > 1 {
+		return vec2d{}, false
+	}
+	switch {
+	case u == 0:
+		return s.a, true
+	case u == 1:
+		return s.b, true
+	case v == 0:
+		return t.a, true
+	case v == 1:
+		return t.b, true
+	}
+	return s.a.add(ds.mul(u)), true
+}
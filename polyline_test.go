@@ -0,0 +1,187 @@
+package geom
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestIntersectPolylines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b []Point2
+		want []Point2
+	}{
+		{
+			name: "cross",
+			a:    []Point2{{0, 0}, {2, 2}},
+			b:    []Point2{{0, 2}, {2, 0}},
+			want: []Point2{{1, 1}},
+		},
+		{
+			name: "zigzag",
+			a:    []Point2{{0, 0}, {1, 2}, {2, 0}, {3, 2}, {4, 0}},
+			b:    []Point2{{-1, 1}, {5, 1}},
+			want: []Point2{{0.5, 1}, {1.5, 1}, {2.5, 1}, {3.5, 1}},
+		},
+		{
+			name: "touch at vertex",
+			a:    []Point2{{0, 0}, {1, 1}, {2, 0}},
+			b:    []Point2{{0, 1}, {2, 1}},
+			want: []Point2{{1, 1}},
+		},
+		{
+			name: "vertical",
+			a:    []Point2{{1, -1}, {1, 3}},
+			b:    []Point2{{0, 0}, {2, 0}, {2, 2}, {0, 2}},
+			want: []Point2{{1, 0}, {1, 2}},
+		},
+		{
+			name: "overlap",
+			a:    []Point2{{0, 0}, {3, 0}},
+			b:    []Point2{{1, 1}, {1, 0}, {4, 0}},
+			want: []Point2{{1, 0}, {3, 0}},
+		},
+		{
+			name: "self crossing ignored",
+			a:    []Point2{{0, 0}, {2, 2}, {2, 0}, {0, 2}},
+			b:    []Point2{{5, 0}, {5, 2}},
+		},
+		{
+			name: "apart",
+			a:    []Point2{{0, 0}, {1, 0}},
+			b:    []Point2{{0, 1}, {1, 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IntersectPolylines(tc.a, tc.b)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, wanted %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i].Sub(tc.want[i]).Len() > 1e-5 {
+					t.Errorf("got %v, wanted %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIntersectPolylinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	walk := func(n int) []Point2 {
+		pts := make([]Point2, n)
+		for i := range pts {
+			pts[i] = Point2{r.Float32() * 10, r.Float32() * 10}
+		}
+		return pts
+	}
+
+	for iter := 0; iter < 50; iter++ {
+		a, b := walk(20), walk(20)
+		got := IntersectPolylines(a, b)
+
+		// Compare with testing every pair of segments
+		var want []Point2
+		for i := 0; i+1 < len(a); i++ {
+			for j := 0; j+1 < len(b); j++ {
+				if s, _, ok := intersectSegments2(a[i], a[i+1], b[j], b[j+1]); ok {
+					want = append(want, a[i].Add(a[i+1].Sub(a[i]).Mul(s)))
+				}
+			}
+		}
+		sort.Slice(want, func(i, j int) bool {
+			return want[i][0] < want[j][0] || (want[i][0] == want[j][0] && want[i][1] < want[j][1])
+		})
+		if len(got) != len(want) {
+			t.Fatalf("iteration %d: got %d intersections, wanted %d", iter, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].Sub(want[i]).Len() > 1e-4 {
+				t.Fatalf("iteration %d: intersection %d got %v, wanted %v", iter, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestIsSimple(t *testing.T) {
+	square := []Point2{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+	testCases := []struct {
+		name string
+		p    Polygon2
+		want bool
+	}{
+		{name: "square", p: Polygon2{Points: square}, want: true},
+		{name: "triangle", p: Polygon2{Points: []Point2{{0, 0}, {1, 0}, {0, 1}}}, want: true},
+		{name: "l shape", p: Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 4}, {0, 4}}}, want: true},
+		{name: "bow tie", p: Polygon2{Points: []Point2{{0, 0}, {2, 2}, {2, 0}, {0, 2}}}, want: false},
+		{name: "repeated point", p: Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 0}, {0, 4}}}, want: false},
+		{name: "too few points", p: Polygon2{Points: []Point2{{0, 0}, {4, 0}}}, want: false},
+		{name: "vertex on edge", p: Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 4}, {2, 0}, {0, 4}}}, want: false},
+		{name: "spike", p: Polygon2{Points: []Point2{{0, 0}, {4, 0}, {2, 0}, {2, 4}}}, want: false},
+		{name: "vertical edges", p: Polygon2{Points: []Point2{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {2, 2}, {0, 2}}}, want: true},
+		{
+			name: "hole inside",
+			p:    Polygon2{Points: square, Holes: [][]Point2{{{1, 1}, {1, 3}, {3, 3}, {3, 1}}}},
+			want: true,
+		},
+		{
+			name: "hole crossing",
+			p:    Polygon2{Points: square, Holes: [][]Point2{{{1, 1}, {1, 3}, {5, 3}, {5, 1}}}},
+			want: false,
+		},
+		{
+			name: "hole touching",
+			p:    Polygon2{Points: square, Holes: [][]Point2{{{1, 1}, {1, 3}, {4, 2}}}},
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsSimple(tc.p); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func BenchmarkIntersectPolylines(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	a := make([]Point2, 1000)
+	c := make([]Point2, 1000)
+	for i := range a {
+		a[i] = Point2{float32(i), r.Float32()}
+		c[i] = Point2{float32(i) + 0.5, r.Float32()}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IntersectPolylines(a, c)
+	}
+}
+
+func BenchmarkIntersectPolylinesStacked(b *testing.B) {
+	// A comb whose teeth are all crossed by the same vertical lines keeps every tooth in the
+	// sweep status at once
+	var a, c []Point2
+	for i := 0; i < 10000; i++ {
+		y := float32(i)
+		if i%2 == 0 {
+			a = append(a, Point2{0, y}, Point2{100, y})
+		} else {
+			a = append(a, Point2{100, y}, Point2{0, y})
+		}
+	}
+	for i := 0; i < 4; i++ {
+		x := float32(i*25) + 10
+		c = append(c, Point2{x, -1}, Point2{x + 1, 10000})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IntersectPolylines(a, c)
+	}
+}
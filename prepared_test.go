@@ -0,0 +1,103 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAABBRaycastPrepared(t *testing.T) {
+	box := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}}
+
+	testCases := []struct {
+		name       string
+		ray        Ray3
+		wantOK     bool
+		wantFail   RaycastFail
+		wantDist   float32
+		wantNormal Vec3
+	}{
+		{
+			name:       "from -x",
+			ray:        Ray3{Origin: Point3{-5, 2, 3}, Direction: Vec3{1, 0, 0}},
+			wantOK:     true,
+			wantDist:   5,
+			wantNormal: Vec3{-1, 0, 0},
+		},
+		{
+			name:       "from +y",
+			ray:        Ray3{Origin: Point3{1, 10, 3}, Direction: Vec3{0, -1, 0}},
+			wantOK:     true,
+			wantDist:   6,
+			wantNormal: Vec3{0, 1, 0},
+		},
+		{
+			name:       "from inside",
+			ray:        Ray3{Origin: Point3{1, 2, 3}, Direction: Vec3{0, 0, 1}},
+			wantOK:     true,
+			wantDist:   3,
+			wantNormal: Vec3{0, 0, 1},
+		},
+		{
+			name:     "behind",
+			ray:      Ray3{Origin: Point3{5, 2, 3}, Direction: Vec3{1, 0, 0}},
+			wantFail: RaycastFailTargetBehindRayOrigin,
+		},
+		{
+			name:     "miss",
+			ray:      Ray3{Origin: Point3{-5, 10, 3}, Direction: Vec3{1, 0, 0}},
+			wantFail: RaycastFailOutsideBounds,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.ray.Prepare()
+			res, ok := box.RaycastPrepared(&p)
+			if ok != tc.wantOK {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.wantOK)
+			}
+			if !ok {
+				if res.Fail != tc.wantFail {
+					t.Errorf("got fail %v, wanted %v", res.Fail, tc.wantFail)
+				}
+				return
+			}
+			if res.Distance != tc.wantDist || res.Normal != tc.wantNormal {
+				t.Errorf("got distance %v normal %v, wanted %v and %v", res.Distance, res.Normal, tc.wantDist, tc.wantNormal)
+			}
+		})
+	}
+
+	// Finds the same hits as Raycast for arbitrary rays
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		ray := Ray3{Origin: RandomPointInAABB(r, AABB{Size: Vec3{10, 10, 10}}), Direction: RandomUnitVec3(r)}
+		p := ray.Prepare()
+		want, wantOK := box.Raycast(ray)
+		got, ok := box.RaycastPrepared(&p)
+		if ok != wantOK {
+			t.Fatalf("ray %v: got %v %v, wanted %v %v", ray, got, ok, want, wantOK)
+		}
+		if ok && (abs(got.Distance-want.Distance) > 1e-4 || !nearVec3(got.Point, want.Point, 1e-4)) {
+			t.Fatalf("ray %v: got %v, wanted %v", ray, got, want)
+		}
+	}
+}
+
+func BenchmarkAABBRaycastPrepared(b *testing.B) {
+	box := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}}
+	ray := Ray3{Origin: Point3{-5, -4, -3}, Direction: Vec3{1, 1, 1}.Normalize()}
+
+	b.Run("raycast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			box.Raycast(ray)
+		}
+	})
+	b.Run("prepared", func(b *testing.B) {
+		p := ray.Prepare()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			box.RaycastPrepared(&p)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SwingTwist decomposes the rotation q into a twist about axis followed by a swing about an axis
+// perpendicular to it, such that q = swing * twist. axis must be normalized. The twist isolates
+// the part of the rotation about axis, for example the yaw of a camera when axis points up.
+func SwingTwist(q Quat, axis Vec3) (swing, twist Quat) {
+	// Project the rotation axis onto the twist axis
+	p := axis.Mul(q.V.Dot(axis))
+	twist = Quat{W: q.W, V: p}
+
+	if twist.Len() < epsilon32 {
+		// The rotation is a 180 degree swing about an axis perpendicular to the twist axis
+		return q, mgl32.QuatIdent()
+	}
+
+	twist = twist.Normalize()
+	swing = q.Mul(twist.Conjugate())
+	return swing, twist
+}
+
+// SmoothDampQuat gradually rotates current toward target using a critically damped spring that
+// takes approximately smoothTime to arrive, in the same way as SmoothDampVec3. velocity holds
+// the angular velocity as an axis scaled by radians per second and is updated on each call; it
+// should start at zero and be passed unchanged between calls. dt is the time elapsed since the
+// previous call. The rotation always takes the shortest path to the target.
+func SmoothDampQuat(current, target Quat, velocity *Vec3, smoothTime, dt float32) Quat {
+	if dt <= 0 {
+		return current
+	}
+	omega, decay := smoothDampFactors(smoothTime, dt)
+
+	// The spring acts on the rotation vector that takes current to target
+	delta := target.Mul(current.Conjugate())
+	if delta.W < 0 {
+		delta = delta.Scale(-1)
+	}
+	toTarget := quatToRotationVector(delta)
+
+	change := toTarget.Mul(-1)
+	temp := velocity.Add(change.Mul(omega)).Mul(dt)
+	*velocity = velocity.Sub(temp.Mul(omega)).Mul(decay)
+	res := change.Add(temp).Mul(decay)
+
+	// Prevent overshooting
+	if toTarget.Dot(res) > 0 {
+		*velocity = Vec3{}
+		return target
+	}
+	return quatFromRotationVector(res).Mul(target).Normalize()
+}
+
+// quatToRotationVector returns the axis of the unit quaternion q scaled by its angle of
+// rotation in radians.
+func quatToRotationVector(q Quat) Vec3 {
+	s := q.V.Len()
+	if s < epsilon32 {
+		// Near the identity sin(angle/2) is approximately angle/2
+		return q.V.Mul(2)
+	}
+	angle := 2 * float32(math.Atan2(float64(s), float64(q.W)))
+	return q.V.Mul(angle / s)
+}
+
+// quatFromRotationVector returns the rotation about the direction of v by an angle equal to
+// its length in radians.
+func quatFromRotationVector(v Vec3) Quat {
+	angle := v.Len()
+	if angle < epsilon32 {
+		return Quat{W: 1, V: v.Mul(0.5)}.Normalize()
+	}
+	return mgl32.QuatRotate(angle, v.Mul(1/angle))
+}
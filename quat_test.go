@@ -0,0 +1,82 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestSwingTwist(t *testing.T) {
+	yaw := mgl32.QuatRotate(pi/3, Y3)
+	pitch := mgl32.QuatRotate(pi/6, X3)
+
+	testCases := []struct {
+		name  string
+		q     Quat
+		axis  Vec3
+		swing Quat
+		twist Quat
+	}{
+		{name: "pure-twist", q: yaw, axis: Y3, swing: mgl32.QuatIdent(), twist: yaw},
+		{name: "pure-swing", q: pitch, axis: Y3, swing: pitch, twist: mgl32.QuatIdent()},
+		{name: "pitch-after-yaw", q: pitch.Mul(yaw), axis: Y3, swing: pitch, twist: yaw},
+		{name: "half-turn-swing", q: mgl32.QuatRotate(pi, X3), axis: Y3, swing: mgl32.QuatRotate(pi, X3), twist: mgl32.QuatIdent()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			swing, twist := SwingTwist(tc.q, tc.axis)
+			if !swing.OrientationEqualThreshold(tc.swing, 1e-5) {
+				t.Errorf("got swing %v, wanted %v", swing, tc.swing)
+			}
+			if !twist.OrientationEqualThreshold(tc.twist, 1e-5) {
+				t.Errorf("got twist %v, wanted %v", twist, tc.twist)
+			}
+			if !swing.Mul(twist).OrientationEqualThreshold(tc.q, 1e-5) {
+				t.Errorf("swing * twist = %v, wanted %v", swing.Mul(twist), tc.q)
+			}
+		})
+	}
+}
+
+func TestSmoothDampQuat(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target Quat
+	}{
+		{name: "small", target: mgl32.QuatRotate(0.3, Vec3{1, 2, 0}.Normalize())},
+		{name: "large", target: mgl32.QuatRotate(3, Vec3{0, 1, 1}.Normalize())},
+		{name: "negated", target: mgl32.QuatRotate(1, Y3).Scale(-1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var velocity Vec3
+			q := mgl32.QuatIdent()
+			prev := float32(pi)
+			for i := 0; i < 120; i++ {
+				q = SmoothDampQuat(q, tc.target, &velocity, 0.5, 1.0/60)
+				if abs(q.Len()-1) > 1e-4 {
+					t.Fatalf("step %d: got non-unit quaternion %v", i, q)
+				}
+				// The remaining angle shrinks steadily without overshooting
+				angle := quatToRotationVector(tc.target.Mul(q.Conjugate())).Len()
+				if angle > pi {
+					angle = 2*pi - angle
+				}
+				if angle > prev+1e-5 {
+					t.Fatalf("step %d: angle to target grew from %v to %v", i, prev, angle)
+				}
+				prev = angle
+			}
+			if !q.OrientationEqualThreshold(tc.target, 1e-2) {
+				t.Errorf("got %v after 2s, wanted close to %v", q, tc.target)
+			}
+		})
+	}
+
+	var velocity Vec3
+	if got := SmoothDampQuat(mgl32.QuatIdent(), mgl32.QuatRotate(1, X3), &velocity, 0.5, 0); got != mgl32.QuatIdent() || velocity != (Vec3{}) {
+		t.Errorf("zero time step: got %v with velocity %v, wanted no change", got, velocity)
+	}
+}
@@ -0,0 +1,191 @@
+package geom
+
+// RayPacketSize is the largest number of rays in a RayPacket.
+const RayPacketSize = 8
+
+// RayPacket is a group of up to eight rays that are traced together. Coherent rays, such as
+// those through neighbouring pixels or a fan of ambient occlusion rays, tend to visit the same
+// boxes so testing them together shares the work of walking a hierarchy and lets the slab
+// tests run over all of the rays in one loop.
+type RayPacket struct {
+	rays   [RayPacketSize]PreparedRay3
+	n      int
+	origin Vec3x8
+	inv    Vec3x8 // the reciprocal of each component of each direction
+}
+
+// NewRayPacket returns a packet holding the rays. It panics if there are more than
+// RayPacketSize rays.
+func NewRayPacket(rays ...Ray3) *RayPacket {
+	if len(rays) > RayPacketSize {
+		panic("geom: too many rays for a RayPacket")
+	}
+	p := &RayPacket{n: len(rays)}
+	for i := range rays {
+		p.rays[i] = rays[i].Prepare()
+	}
+	for i := 0; i < RayPacketSize; i++ {
+		// Unused slots repeat the first ray and are masked out of every test
+		r := &p.rays[0]
+		if i < len(rays) {
+			r = &p.rays[i]
+		}
+		p.origin.Set(i, r.Origin)
+		p.inv.Set(i, r.InvDirection)
+	}
+	return p
+}
+
+// Len returns the number of rays in the packet.
+func (p *RayPacket) Len() int {
+	return p.n
+}
+
+// Ray returns the ray with index i.
+func (p *RayPacket) Ray(i int) Ray3 {
+	return p.rays[i].Ray3
+}
+
+// mask returns a bit mask with a bit set for each ray in the packet.
+func (p *RayPacket) mask() uint8 {
+	return uint8(1<<p.n - 1)
+}
+
+// IntersectsAABB tests every ray in the packet against the box. It returns the distance along
+// each ray to where it enters the box, or zero if it starts inside, and a mask with bit i set
+// when ray i hits the box.
+func (p *RayPacket) IntersectsAABB(a *AABB) ([RayPacketSize]float32, uint8) {
+	var limit [RayPacketSize]float32
+	for i := range limit {
+		limit[i] = maxFloat32
+	}
+	return p.slabs(a.Position.Sub(a.Size), a.Position.Add(a.Size), &limit, p.mask())
+}
+
+// slabs tests the rays selected by active against the box with the given corners, ignoring
+// hits beyond each ray's limit.
+func (p *RayPacket) slabs(bmin, bmax Point3, limit *[RayPacketSize]float32, active uint8) ([RayPacketSize]float32, uint8) {
+	var tmin, tmax [RayPacketSize]float32
+	for i := 0; i < RayPacketSize; i++ {
+		tmin[i], tmax[i] = 0, limit[i]
+	}
+	slab := func(lo, hi float32, o, inv *[RayPacketSize]float32) {
+		for i := 0; i < RayPacketSize; i++ {
+			t1 := (lo - o[i]) * inv[i]
+			t2 := (hi - o[i]) * inv[i]
+			if t1 != t1 || t2 != t2 {
+				// NaN from a ray running along the plane of a face
+				continue
+			}
+			if t1 > t2 {
+				t1, t2 = t2, t1
+			}
+			if t1 > tmin[i] {
+				tmin[i] = t1
+			}
+			if t2 < tmax[i] {
+				tmax[i] = t2
+			}
+		}
+	}
+	slab(bmin[0], bmax[0], &p.origin.X, &p.inv.X)
+	slab(bmin[1], bmax[1], &p.origin.Y, &p.inv.Y)
+	slab(bmin[2], bmax[2], &p.origin.Z, &p.inv.Z)
+
+	var hit uint8
+	for i := 0; i < RayPacketSize; i++ {
+		if tmin[i] <= tmax[i] {
+			hit |= 1 << i
+		}
+	}
+	return tmin, hit & active
+}
+
+// RaycastPacket traces every ray in the packet through the hierarchy, returning the nearest
+// hit for each ray, the index of the item it hit and a mask with bit i set when ray i hit an
+// item. A node is skipped for the whole packet once no ray that could still find a nearer hit
+// passes through it. Items are tested by calling hit with the index of the ray in the packet and
+// the index of the item. When hit is nil items are tested as they are by Raycast.
+func (b *BVH) RaycastPacket(p *RayPacket, hit func(ray, item int) (RaycastResult, bool)) ([RayPacketSize]RaycastResult, [RayPacketSize]int, uint8) {
+	var best [RayPacketSize]RaycastResult
+	var bestItem [RayPacketSize]int
+	var limit [RayPacketSize]float32
+	for i := range best {
+		best[i].Fail = RaycastFailOutsideBounds
+		bestItem[i] = -1
+		limit[i] = maxFloat32
+	}
+	if len(b.nodes) == 0 || p.n == 0 {
+		return best, bestItem, 0
+	}
+	if hit == nil {
+		hit = func(ray, it int) (RaycastResult, bool) {
+			return b.raycastItem(&p.rays[ray], it)
+		}
+	}
+
+	type entry struct {
+		node int
+		mask uint8
+	}
+
+	// nearest returns the smallest entry distance among the rays in the mask
+	nearest := func(dist *[RayPacketSize]float32, mask uint8) float32 {
+		d := float32(maxFloat32)
+		for i := 0; i < RayPacketSize; i++ {
+			if mask&(1<<i) != 0 && dist[i] < d {
+				d = dist[i]
+			}
+		}
+		return d
+	}
+
+	var buf [64]entry
+	stack := buf[:0]
+	if _, mask := p.slabs(b.nodes[0].min, b.nodes[0].max, &limit, p.mask()); mask != 0 {
+		stack = append(stack, entry{0, mask})
+	}
+	var found uint8
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := &b.nodes[e.node]
+		if !n.leaf() {
+			// Visit the child that the packet reaches first so that hits in it can prune the
+			// other
+			l, r := &b.nodes[n.left], &b.nodes[n.right]
+			dl, ml := p.slabs(l.min, l.max, &limit, e.mask)
+			dr, mr := p.slabs(r.min, r.max, &limit, e.mask)
+			switch {
+			case ml != 0 && mr != 0:
+				if nearest(&dl, ml) <= nearest(&dr, mr) {
+					stack = append(stack, entry{n.right, mr}, entry{n.left, ml})
+				} else {
+					stack = append(stack, entry{n.left, ml}, entry{n.right, mr})
+				}
+			case ml != 0:
+				stack = append(stack, entry{n.left, ml})
+			case mr != 0:
+				stack = append(stack, entry{n.right, mr})
+			}
+			continue
+		}
+
+		// Rays may have found nearer hits since the node was pushed
+		_, mask := p.slabs(n.min, n.max, &limit, e.mask)
+		for _, it := range b.order[n.left : n.left+n.count] {
+			for i := 0; i < p.n; i++ {
+				if mask&(1<<i) == 0 {
+					continue
+				}
+				res, ok := hit(i, it)
+				if ok && res.Distance <= limit[i] {
+					best[i], bestItem[i], limit[i] = res, it, res.Distance
+					found |= 1 << i
+				}
+			}
+		}
+	}
+	return best, bestItem, found
+}
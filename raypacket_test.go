@@ -0,0 +1,88 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRayPacketIntersectsAABB(t *testing.T) {
+	box := AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}}
+	p := NewRayPacket(
+		Ray3{Origin: Point3{-5, 0, 0}, Direction: Vec3{1, 0, 0}},
+		Ray3{Origin: Point3{-5, 2, 0}, Direction: Vec3{1, 0, 0}},
+		Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{0, 1, 0}},
+		Ray3{Origin: Point3{0, 5, 0}, Direction: Vec3{0, 1, 0}},
+		Ray3{Origin: Point3{0, 0, -3}, Direction: Vec3{0, 0, 1}},
+	)
+	if p.Len() != 5 {
+		t.Fatalf("Len: got %d, wanted 5", p.Len())
+	}
+
+	dist, mask := p.IntersectsAABB(&box)
+	if want := uint8(0b10101); mask != want {
+		t.Errorf("mask: got %05b, wanted %05b", mask, want)
+	}
+	for i, want := range map[int]float32{0: 4, 2: 0, 4: 2} {
+		if dist[i] != want {
+			t.Errorf("distance %d: got %v, wanted %v", i, dist[i], want)
+		}
+	}
+}
+
+func TestBVHRaycastPacket(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	items := randomSpheres(r, 300)
+	b := BuildBVH(items, BVHOptions{})
+
+	for q := 0; q < 20; q++ {
+		// A fan of rays from one origin toward nearby targets
+		origin := RandomPointOnSphere(r, Sphere{Radius: 100})
+		target := RandomPointInAABB(r, AABB{Size: Vec3{20, 20, 20}})
+		rays := make([]Ray3, RayPacketSize-q%3)
+		for i := range rays {
+			to := target.Add(RandomPointInAABB(r, AABB{Size: Vec3{5, 5, 5}}))
+			rays[i] = Ray3{Origin: origin, Direction: to.Sub(origin).Normalize()}
+		}
+		p := NewRayPacket(rays...)
+
+		res, got, mask := b.RaycastPacket(p, nil)
+		for i, ray := range rays {
+			want, wantItem, ok := b.Raycast(ray, nil)
+			if ok != (mask&(1<<i) != 0) || got[i] != wantItem {
+				t.Fatalf("ray %d: got item %d, mask %08b, wanted item %d, %v", i, got[i], mask, wantItem, ok)
+			}
+			if ok && res[i].Distance != want.Distance {
+				t.Fatalf("ray %d: got distance %v, wanted %v", i, res[i].Distance, want.Distance)
+			}
+		}
+		if mask>>len(rays) != 0 {
+			t.Fatalf("mask %08b has bits set beyond the %d rays", mask, len(rays))
+		}
+	}
+}
+
+func BenchmarkBVHRaycastPacket(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	bvh := BuildBVH(randomSpheres(r, 10000), BVHOptions{})
+	origin := Point3{0, 0, -100}
+	var rays [RayPacketSize]Ray3
+	for i := range rays {
+		rays[i] = Ray3{Origin: origin, Direction: Point3{float32(i%4) * 0.5, float32(i/4) * 0.5, 0}.Sub(origin).Normalize()}
+	}
+	p := NewRayPacket(rays[:]...)
+
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, ray := range rays {
+				bvh.Raycast(ray, nil)
+			}
+		}
+	})
+	b.Run("packet", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bvh.RaycastPacket(p, nil)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package geom
+
+// RoundedRect is a rectangle whose corners are rounded off with the given radius. The radius
+// is limited to half of the rectangle's shorter side.
+type RoundedRect struct {
+	Rect   Rect
+	Radius float32
+}
+
+// core returns the rect swept by the centre of the corner circles and the effective radius.
+func (r *RoundedRect) core() (Rect, float32) {
+	rad := Clamp(r.Radius, 0, min(r.Rect.Size[0], r.Rect.Size[1]))
+	return r.Rect.Shrink(rad), rad
+}
+
+// SignedDistance returns the distance from p to the edge of the shape. The distance is
+// negative when p is inside the shape.
+func (r *RoundedRect) SignedDistance(p Point2) float32 {
+	core, rad := r.core()
+	return rectSignedDistance(core, p) - rad
+}
+
+// ContainsPoint2 reports whether the point lies within the shape.
+func (r *RoundedRect) ContainsPoint2(p Point2) bool {
+	return r.SignedDistance(p) <= 0
+}
+
+// ClosestPoint returns the point in the shape that is closest to p, which is p itself when it
+// lies inside the shape.
+func (r *RoundedRect) ClosestPoint(p Point2) Point2 {
+	core, rad := r.core()
+	return closestPointRounded(rectClosestPoint(core, p), rad, p)
+}
+
+// IntersectsCircle reports whether any part of the circle overlaps the shape.
+func (r *RoundedRect) IntersectsCircle(c Circle) bool {
+	return r.SignedDistance(c.Centre) <= c.Radius
+}
+
+// IntersectsRect reports whether any part of the rect overlaps the shape.
+func (r *RoundedRect) IntersectsRect(rc Rect) bool {
+	core, rad := r.core()
+	gap := Vec2{
+		max(0, abs(core.Position[0]-rc.Position[0])-core.Size[0]-rc.Size[0]),
+		max(0, abs(core.Position[1]-rc.Position[1])-core.Size[1]-rc.Size[1]),
+	}
+	return gap.Len() <= rad
+}
+
+// Stadium is the 2 dimensional equivalent of a capsule: the set of points within Radius of the
+// line segment from Start to End.
+type Stadium struct {
+	Start, End Point2
+	Radius     float32
+}
+
+// Line returns the line segment at the core of the stadium.
+func (s *Stadium) Line() Line2 {
+	return Line2{Start: s.Start, End: s.End}
+}
+
+// SignedDistance returns the distance from p to the edge of the shape. The distance is
+// negative when p is inside the shape.
+func (s *Stadium) SignedDistance(p Point2) float32 {
+	l := s.Line()
+	return p.Sub(l.ClosestPoint(p)).Len() - s.Radius
+}
+
+// ContainsPoint2 reports whether the point lies within the shape.
+func (s *Stadium) ContainsPoint2(p Point2) bool {
+	return s.SignedDistance(p) <= 0
+}
+
+// ClosestPoint returns the point in the shape that is closest to p, which is p itself when it
+// lies inside the shape.
+func (s *Stadium) ClosestPoint(p Point2) Point2 {
+	l := s.Line()
+	return closestPointRounded(l.ClosestPoint(p), s.Radius, p)
+}
+
+// IntersectsCircle reports whether any part of the circle overlaps the shape.
+func (s *Stadium) IntersectsCircle(c Circle) bool {
+	return s.SignedDistance(c.Centre) <= c.Radius
+}
+
+// IntersectsRect reports whether any part of the rect overlaps the shape.
+func (s *Stadium) IntersectsRect(rc Rect) bool {
+	if _, _, ok := ClipSegmentToRect(s.Start, s.End, rc); ok {
+		return true
+	}
+
+	// The segment lies outside the rect so the closest approach involves an end of the
+	// segment or a corner of the rect
+	l := s.Line()
+	r2 := s.Radius * s.Radius
+	for _, p := range [2]Point2{s.Start, s.End} {
+		if d := p.Sub(rectClosestPoint(rc, p)); d.Dot(d) <= r2 {
+			return true
+		}
+	}
+	for _, c := range [4]Point2{rc.TopLeft(), rc.TopRight(), rc.BottomLeft(), rc.BottomRight()} {
+		if d := c.Sub(l.ClosestPoint(c)); d.Dot(d) <= r2 {
+			return true
+		}
+	}
+	return false
+}
+
+// closestPointRounded returns the point in a shape made by sweeping a circle of radius r over
+// a core shape that is closest to p, given the point on the core that is closest to p.
+func closestPointRounded(core Point2, r float32, p Point2) Point2 {
+	d := p.Sub(core)
+	l := d.Len()
+	if l <= r {
+		return p
+	}
+	return core.Add(d.Mul(r / l))
+}
+
+// rectClosestPoint returns the point in the rect that is closest to p.
+func rectClosestPoint(r Rect, p Point2) Point2 {
+	pmin, pmax := r.Min(), r.Max()
+	return Point2{Clamp(p[0], pmin[0], pmax[0]), Clamp(p[1], pmin[1], pmax[1])}
+}
+
+// rectSignedDistance returns the distance from p to the edge of the rect, which is negative
+// inside the rect.
+func rectSignedDistance(r Rect, p Point2) float32 {
+	q := Vec2{abs(p[0]-r.Position[0]) - r.Size[0], abs(p[1]-r.Position[1]) - r.Size[1]}
+	outside := Vec2{max(q[0], 0), max(q[1], 0)}.Len()
+	inside := min(max(q[0], q[1]), 0)
+	return outside + inside
+}
@@ -0,0 +1,89 @@
+package geom
+
+import "testing"
+
+func TestRoundedRect(t *testing.T) {
+	r := RoundedRect{Rect: RectFromCorners(Point2{0, 0}, Point2{10, 6}), Radius: 2}
+
+	testCases := []struct {
+		name    string
+		p       Point2
+		dist    float32
+		closest Point2
+	}{
+		{name: "centre", p: Point2{5, 3}, dist: -3, closest: Point2{5, 3}},
+		{name: "left edge", p: Point2{-1, 3}, dist: 1, closest: Point2{0, 3}},
+		{name: "corner", p: Point2{-1, -1}, dist: sqrt(18) - 2, closest: Point2{2 - sqrt(2), 2 - sqrt(2)}},
+		{name: "square corner", p: Point2{0.1, 0.1}, dist: sqrt(2*1.9*1.9) - 2, closest: Point2{2 - sqrt(2), 2 - sqrt(2)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.SignedDistance(tc.p); abs(got-tc.dist) > 1e-5 {
+				t.Errorf("SignedDistance: got %v, wanted %v", got, tc.dist)
+			}
+			if got := r.ContainsPoint2(tc.p); got != (tc.dist <= 0) {
+				t.Errorf("ContainsPoint2: got %v, wanted %v", got, tc.dist <= 0)
+			}
+			if got := r.ClosestPoint(tc.p); got.Sub(tc.closest).Len() > 1e-5 {
+				t.Errorf("ClosestPoint: got %v, wanted %v", got, tc.closest)
+			}
+		})
+	}
+
+	if !r.IntersectsCircle(Circle{Centre: Point2{-1, -1}, Radius: 2.3}) || r.IntersectsCircle(Circle{Centre: Point2{-1, -1}, Radius: 2.2}) {
+		t.Errorf("IntersectsCircle: wrong result near corner")
+	}
+	if !r.IntersectsRect(RectFromCorners(Point2{-3, 2}, Point2{0, 4})) {
+		t.Errorf("IntersectsRect: rect touching side should intersect")
+	}
+	if r.IntersectsRect(RectFromCorners(Point2{-3, -3}, Point2{0, 0})) {
+		t.Errorf("IntersectsRect: rect in rounded off corner should not intersect")
+	}
+}
+
+func TestStadium(t *testing.T) {
+	s := Stadium{Start: Point2{0, 0}, End: Point2{10, 0}, Radius: 1}
+
+	testCases := []struct {
+		name    string
+		p       Point2
+		dist    float32
+		closest Point2
+	}{
+		{name: "on core", p: Point2{5, 0}, dist: -1, closest: Point2{5, 0}},
+		{name: "above", p: Point2{5, 3}, dist: 2, closest: Point2{5, 1}},
+		{name: "beyond end", p: Point2{13, 4}, dist: 4, closest: Point2{10.6, 0.8}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.SignedDistance(tc.p); abs(got-tc.dist) > 1e-5 {
+				t.Errorf("SignedDistance: got %v, wanted %v", got, tc.dist)
+			}
+			if got := s.ClosestPoint(tc.p); got.Sub(tc.closest).Len() > 1e-5 {
+				t.Errorf("ClosestPoint: got %v, wanted %v", got, tc.closest)
+			}
+		})
+	}
+
+	rects := []struct {
+		r    Rect
+		want bool
+	}{
+		{r: RectFromCorners(Point2{4, -4}, Point2{6, 4}), want: true},      // crosses the core
+		{r: RectFromCorners(Point2{4, 1.5}, Point2{6, 4}), want: false},    // above
+		{r: RectFromCorners(Point2{4, 0.5}, Point2{6, 4}), want: true},     // overlaps the side
+		{r: RectFromCorners(Point2{10.5, 0.5}, Point2{12, 2}), want: true}, // overlaps the end cap
+		{r: RectFromCorners(Point2{10.8, 0.8}, Point2{12, 2}), want: false},
+	}
+	for _, tc := range rects {
+		if got := s.IntersectsRect(tc.r); got != tc.want {
+			t.Errorf("IntersectsRect(%v): got %v, wanted %v", tc.r, got, tc.want)
+		}
+	}
+
+	if !s.IntersectsCircle(Circle{Centre: Point2{5, 3}, Radius: 2}) || s.IntersectsCircle(Circle{Centre: Point2{5, 3}, Radius: 1.9}) {
+		t.Errorf("IntersectsCircle: wrong result")
+	}
+}
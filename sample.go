@@ -0,0 +1,144 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandomPointInAABB returns a point chosen uniformly from the interior of the box.
+func RandomPointInAABB(r *rand.Rand, a AABB) Point3 {
+	return Point3{
+		a.Position[0] + (r.Float32()*2-1)*a.Size[0],
+		a.Position[1] + (r.Float32()*2-1)*a.Size[1],
+		a.Position[2] + (r.Float32()*2-1)*a.Size[2],
+	}
+}
+
+// RandomPointInRect returns a point chosen uniformly from the interior of the rectangle.
+func RandomPointInRect(r *rand.Rand, rc Rect) Point2 {
+	return Point2{
+		rc.Position[0] + (r.Float32()*2-1)*rc.Size[0],
+		rc.Position[1] + (r.Float32()*2-1)*rc.Size[1],
+	}
+}
+
+// RandomPointInSphere returns a point chosen uniformly from the interior of the sphere.
+func RandomPointInSphere(r *rand.Rand, s Sphere) Point3 {
+	// Volume grows with the cube of the radius so the cube root keeps the density uniform
+	d := float32(math.Cbrt(float64(r.Float32()))) * s.Radius
+	return s.Position.Add(RandomUnitVec3(r).Mul(d))
+}
+
+// RandomPointOnSphere returns a point chosen uniformly from the surface of the sphere.
+func RandomPointOnSphere(r *rand.Rand, s Sphere) Point3 {
+	return s.Position.Add(RandomUnitVec3(r).Mul(s.Radius))
+}
+
+// RandomPointInCircle returns a point chosen uniformly from the interior of the circle.
+func RandomPointInCircle(r *rand.Rand, c Circle) Point2 {
+	d := sqrt(r.Float32()) * c.Radius
+	s, co := sincos(r.Float32() * 2 * pi)
+	return Point2{c.Centre[0] + co*d, c.Centre[1] + s*d}
+}
+
+// RandomPointInTri3 returns a point chosen uniformly from the surface of the triangle.
+func RandomPointInTri3(r *rand.Rand, t Tri3) Point3 {
+	u, v := randomBarycentric(r)
+	return t.A.Add(t.B.Sub(t.A).Mul(u)).Add(t.C.Sub(t.A).Mul(v))
+}
+
+// RandomPointInTri2 returns a point chosen uniformly from the interior of the triangle.
+func RandomPointInTri2(r *rand.Rand, t Tri2) Point2 {
+	u, v := randomBarycentric(r)
+	return t.A.Add(t.B.Sub(t.A).Mul(u)).Add(t.C.Sub(t.A).Mul(v))
+}
+
+// randomBarycentric returns the weights of the second and third vertices of a point chosen
+// uniformly from a triangle.
+func randomBarycentric(r *rand.Rand) (float32, float32) {
+	u, v := r.Float32(), r.Float32()
+	if u+v > 1 {
+		// Reflect the point back into the triangle
+		u, v = 1-u, 1-v
+	}
+	return u, v
+}
+
+// RandomUnitVec3 returns a direction chosen uniformly from all directions.
+func RandomUnitVec3(r *rand.Rand) Vec3 {
+	z := r.Float32()*2 - 1
+	s, c := sincos(r.Float32() * 2 * pi)
+	rxy := sqrt(max(0, 1-z*z))
+	return Vec3{rxy * c, rxy * s, z}
+}
+
+// RandomDirectionInHemisphere returns a direction chosen uniformly from the hemisphere
+// centred on normal, which should be normalized.
+func RandomDirectionInHemisphere(r *rand.Rand, normal Vec3) Vec3 {
+	d := RandomUnitVec3(r)
+	if d.Dot(normal) < 0 {
+		return d.Mul(-1)
+	}
+	return d
+}
+
+// RandomDirectionInCone returns a direction chosen uniformly from the cone around axis with a
+// half angle of angle radians. axis should be normalized.
+func RandomDirectionInCone(r *rand.Rand, axis Vec3, angle float32) Vec3 {
+	// Uniform in the cosine of the angle from the axis gives a uniform density over the
+	// spherical cap
+	cosMax := float32(math.Cos(float64(angle)))
+	z := 1 - r.Float32()*(1-cosMax)
+	s, c := sincos(r.Float32() * 2 * pi)
+	rxy := sqrt(max(0, 1-z*z))
+
+	u, v := orthonormalBasis(axis)
+	return u.Mul(rxy * c).Add(v.Mul(rxy * s)).Add(axis.Mul(z))
+}
+
+// orthonormalBasis returns two unit vectors that are perpendicular to n and to each other. n
+// should be normalized.
+func orthonormalBasis(n Vec3) (Vec3, Vec3) {
+	// Duff et al, Building an Orthonormal Basis, Revisited
+	sign := copysign(1, n[2])
+	a := -1 / (sign + n[2])
+	b := n[0] * n[1] * a
+	return Vec3{1 + sign*n[0]*n[0]*a, sign * b, -sign * n[0]},
+		Vec3{b, sign + n[1]*n[1]*a, -n[1]}
+}
+
+// goldenAngle is the angle in radians that divides a circle in the golden ratio.
+const goldenAngle = 2.39996322972865332
+
+// FibonacciSphere returns n directions spread evenly over the unit sphere using a
+// Fibonacci spiral. Unlike random sampling the directions are deterministic and do not
+// clump together. It returns nil when n is not positive.
+func FibonacciSphere(n int) []Vec3 {
+	if n <= 0 {
+		return nil
+	}
+	dirs := make([]Vec3, n)
+	for i := range dirs {
+		// Offset by half a step so the poles are not sampled twice
+		z := 1 - (float32(i)+0.5)*2/float32(n)
+		rxy := sqrt(max(0, 1-z*z))
+		s, c := sincos(float32(i) * goldenAngle)
+		dirs[i] = Vec3{rxy * c, rxy * s, z}
+	}
+	return dirs
+}
+
+// GoldenSpiralDisk returns n points spread evenly over the unit disk using a golden angle
+// spiral. It returns nil when n is not positive.
+func GoldenSpiralDisk(n int) []Point2 {
+	if n <= 0 {
+		return nil
+	}
+	pts := make([]Point2, n)
+	for i := range pts {
+		d := sqrt((float32(i) + 0.5) / float32(n))
+		s, c := sincos(float32(i) * goldenAngle)
+		pts[i] = Point2{d * c, d * s}
+	}
+	return pts
+}
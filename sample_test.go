@@ -0,0 +1,143 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomPointIn(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const n = 1000
+
+	a := AABBFromCorners(Point3{-1, 2, 3}, Point3{4, 5, 6})
+	s := Sphere{Position: Point3{1, 2, 3}, Radius: 2}
+	c := Circle{Centre: Point2{1, -1}, Radius: 3}
+	rc := RectFromCorners(Point2{-2, -1}, Point2{3, 4})
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{2, 0, 0}, C: Point3{0, 2, 0}}
+
+	for i := 0; i < n; i++ {
+		if p := RandomPointInAABB(r, a); !a.ContainsPoint3(p) {
+			t.Fatalf("RandomPointInAABB: %v not in box", p)
+		}
+		if p := RandomPointInSphere(r, s); p.Sub(s.Position).Len() > s.Radius+1e-5 {
+			t.Fatalf("RandomPointInSphere: %v not in sphere", p)
+		}
+		if p := RandomPointOnSphere(r, s); abs(p.Sub(s.Position).Len()-s.Radius) > 1e-5 {
+			t.Fatalf("RandomPointOnSphere: %v not on sphere", p)
+		}
+		if p := RandomPointInCircle(r, c); p.Sub(c.Centre).Len() > c.Radius+1e-5 {
+			t.Fatalf("RandomPointInCircle: %v not in circle", p)
+		}
+		if p := RandomPointInRect(r, rc); !rc.ContainsPoint2(p) {
+			t.Fatalf("RandomPointInRect: %v not in rect", p)
+		}
+		if p := RandomPointInTri3(r, tri); p[0] < 0 || p[1] < 0 || p[0]+p[1] > 2+1e-5 || p[2] != 0 {
+			t.Fatalf("RandomPointInTri3: %v not in triangle", p)
+		}
+	}
+}
+
+func TestRandomDirection(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const n = 10000
+
+	var sum Vec3
+	for i := 0; i < n; i++ {
+		d := RandomUnitVec3(r)
+		if abs(d.Len()-1) > 1e-5 {
+			t.Fatalf("RandomUnitVec3: %v is not unit length", d)
+		}
+		sum = sum.Add(d)
+	}
+	// Uniformly distributed directions should cancel out
+	if mean := sum.Mul(1.0 / n); mean.Len() > 0.05 {
+		t.Errorf("RandomUnitVec3: mean direction %v, wanted close to zero", mean)
+	}
+
+	normal := Vec3{1, 2, -3}.Normalize()
+	for i := 0; i < n; i++ {
+		if d := RandomDirectionInHemisphere(r, normal); d.Dot(normal) < 0 {
+			t.Fatalf("RandomDirectionInHemisphere: %v is not in hemisphere", d)
+		}
+	}
+
+	const angle = 0.3
+	cosAngle := float32(math.Cos(angle))
+	for _, axis := range []Vec3{{0, 0, 1}, {0, 0, -1}, Vec3{-1, 1, 0.5}.Normalize()} {
+		for i := 0; i < n; i++ {
+			d := RandomDirectionInCone(r, axis, angle)
+			if abs(d.Len()-1) > 1e-4 {
+				t.Fatalf("RandomDirectionInCone: %v is not unit length", d)
+			}
+			if d.Dot(axis) < cosAngle-1e-5 {
+				t.Fatalf("RandomDirectionInCone: %v is outside cone around %v", d, axis)
+			}
+		}
+	}
+}
+
+func TestFibonacciSphere(t *testing.T) {
+	const n = 500
+	dirs := FibonacciSphere(n)
+	if len(dirs) != n {
+		t.Fatalf("got %d directions, wanted %d", len(dirs), n)
+	}
+
+	var sum Vec3
+	for _, d := range dirs {
+		if abs(d.Len()-1) > 1e-5 {
+			t.Fatalf("%v is not unit length", d)
+		}
+		sum = sum.Add(d)
+	}
+	if mean := sum.Mul(1.0 / n); mean.Len() > 0.01 {
+		t.Errorf("mean direction %v, wanted close to zero", mean)
+	}
+
+	// Evenly spread directions should all have a close neighbour but none should coincide
+	for i, d := range dirs {
+		nearest := float32(maxFloat32)
+		for j, e := range dirs {
+			if i != j {
+				nearest = min(nearest, d.Sub(e).Len())
+			}
+		}
+		if nearest < 0.05 || nearest > 0.25 {
+			t.Fatalf("direction %d: nearest neighbour at %v", i, nearest)
+		}
+	}
+	for _, n := range []int{0, -1} {
+		if got := FibonacciSphere(n); got != nil {
+			t.Errorf("FibonacciSphere(%d): got %v, wanted nil", n, got)
+		}
+	}
+}
+
+func TestGoldenSpiralDisk(t *testing.T) {
+	const n = 200
+	pts := GoldenSpiralDisk(n)
+	if len(pts) != n {
+		t.Fatalf("got %d points, wanted %d", len(pts), n)
+	}
+
+	inner := 0
+	for _, p := range pts {
+		l := p.Len()
+		if l > 1 {
+			t.Fatalf("%v is outside the unit disk", p)
+		}
+		if l < sqrt(0.5) {
+			inner++
+		}
+	}
+	// Half the area of the disk lies within radius sqrt(0.5)
+	if inner != n/2 {
+		t.Errorf("got %d points in inner half, wanted %d", inner, n/2)
+	}
+	for _, n := range []int{0, -1} {
+		if got := GoldenSpiralDisk(n); got != nil {
+			t.Errorf("GoldenSpiralDisk(%d): got %v, wanted nil", n, got)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package geom
+
+// SceneObject is a shape held by a Scene.
+type SceneObject struct {
+	Shape Shape3
+
+	// Layers is a bitmask of the collision layers the object belongs to. Queries that select
+	// layers only consider objects belonging to at least one of them.
+	Layers uint32
+}
+
+// SceneQuery selects the objects of a Scene that a query considers.
+type SceneQuery struct {
+	// Layers is a bitmask of the collision layers to consider. Zero considers every object.
+	Layers uint32
+
+	// Filter, when not nil, is called with the index of each object the query reaches and
+	// excludes the object when it returns false. It is only called for objects in the selected
+	// layers.
+	Filter func(obj int) bool
+}
+
+// Scene is a collection of shapes indexed by a bounding volume hierarchy so that queries only
+// test the objects near the ray or region they ask about. Queries report objects by their index in the slice
+// passed to NewScene.
+type Scene struct {
+	objects []SceneObject
+	bvh     *BVH
+}
+
+// NewScene returns a scene holding the objects, which are retained by the scene.
+func NewScene(objects []SceneObject) *Scene {
+	items := make([]Bounded, len(objects))
+	for i := range objects {
+		items[i] = objects[i].Shape
+	}
+	return &Scene{
+		objects: objects,
+		bvh:     BuildBVH(items, BVHOptions{}),
+	}
+}
+
+// Len returns the number of objects in the scene.
+func (s *Scene) Len() int {
+	return len(s.objects)
+}
+
+// Object returns the object with the given index.
+func (s *Scene) Object(i int) SceneObject {
+	return s.objects[i]
+}
+
+// Refit updates the scene's index after its shapes have moved. See BVH.Refit.
+func (s *Scene) Refit() {
+	s.bvh.Refit()
+}
+
+// accepts reports whether the query selects the object.
+func (s *Scene) accepts(q *SceneQuery, obj int) bool {
+	if q.Layers != 0 && s.objects[obj].Layers&q.Layers == 0 {
+		return false
+	}
+	return q.Filter == nil || q.Filter(obj)
+}
+
+// Raycast returns the nearest object selected by the query that is hit by the ray, along with
+// the index of the object.
+func (s *Scene) Raycast(ray Ray3, q SceneQuery) (RaycastResult, int, bool) {
+	p := ray.Prepare()
+	return s.bvh.RaycastPrepared(&p, func(obj int) (RaycastResult, bool) {
+		if !s.accepts(&q, obj) {
+			return RaycastResult{}, false
+		}
+		return s.bvh.raycastItem(&p, obj)
+	})
+}
+
+// LineOfSight reports whether the segment from a to b passes through none of the objects
+// selected by the query. Objects are tested only where their bounds meet the segment and the
+// query stops at the first object that blocks it, so it is cheaper than a Raycast that has to
+// find the nearest hit.
+func (s *Scene) LineOfSight(a, b Point3, q SceneQuery) bool {
+	length := b.Sub(a).Len()
+	if length == 0 {
+		return true
+	}
+	ray := Ray3FromPoints(a, b)
+	p := ray.Prepare()
+	visible := true
+	s.bvh.Query(func(bounds AABB) bool {
+		_, ok := p.distanceToBox(bounds.Min(), bounds.Max(), length)
+		return ok
+	}, func(obj int) bool {
+		if !s.accepts(&q, obj) {
+			return true
+		}
+		if res, ok := s.bvh.raycastItem(&p, obj); ok && res.Distance <= length {
+			visible = false
+		}
+		return visible
+	})
+	return visible
+}
+
+// OverlapSphere returns the indices of the objects selected by the query that overlap the
+// sphere. Objects are tested exactly when they are boxes, spheres, capsules, cones or
+// ellipsoids; other shapes are tested by their bounds.
+func (s *Scene) OverlapSphere(sp *Sphere, q SceneQuery) []int {
+	bounds := sp.Bounds()
+	return s.overlap(&bounds, sp.support, q)
+}
+
+// OverlapAABB returns the indices of the objects selected by the query that overlap the box.
+// Objects are tested as by OverlapSphere.
+func (s *Scene) OverlapAABB(box *AABB, q SceneQuery) []int {
+	return s.overlap(box, box.support, q)
+}
+
+// OverlapOBB returns the indices of the objects selected by the query that overlap the box.
+// Objects are tested as by OverlapSphere.
+func (s *Scene) OverlapOBB(box *OBB, q SceneQuery) []int {
+	bounds := box.Bounds()
+	return s.overlap(&bounds, box.support, q)
+}
+
+// overlap returns the selected objects whose bounds overlap the region's bounds and, when the
+// object is convex, whose shape is within a small tolerance of the region described by its
+// support function.
+func (s *Scene) overlap(bounds *AABB, support func(d Vec3) Point3, q SceneQuery) []int {
+	tol := max(max(bounds.Size[0], bounds.Size[1]), max(bounds.Size[2], 1)) * 1e-5
+	var objs []int
+	s.bvh.QueryAABB(bounds, func(obj int) bool {
+		if !s.accepts(&q, obj) {
+			return true
+		}
+		if objSupport := shapeSupport(s.objects[obj].Shape); objSupport == nil || gjkIntersects(objSupport, support, tol) {
+			objs = append(objs, obj)
+		}
+		return true
+	})
+	return objs
+}
+
+// shapeSupport returns the support function of a convex shape, or nil if the shape is not one
+// of the convex shapes of this package.
+func shapeSupport(s Shape3) func(d Vec3) Point3 {
+	switch s := s.(type) {
+	case *AABB:
+		return s.support
+	case *OBB:
+		return s.support
+	case *Sphere:
+		return s.support
+	case *Capsule:
+		return s.support
+	case *Cone:
+		return s.support
+	case *Ellipsoid:
+		return s.support
+	default:
+		return nil
+	}
+}
+
+// Nearest returns the index of the object selected by the query that is closest to p, along
+// with the closest point of the object, which is p itself when it lies inside the object.
+// Objects further than maxDist are ignored. Shapes without a ClosestPoint method are measured
+// to their bounds.
+func (s *Scene) Nearest(p Point3, maxDist float32, q SceneQuery) (int, Point3, bool) {
+	best, bestObj := maxDist*maxDist, -1
+	var bestPoint Point3
+	s.bvh.Query(func(bounds AABB) bool {
+		return DistanceSquaredPointAABB(p, &bounds) <= best
+	}, func(obj int) bool {
+		if !s.accepts(&q, obj) {
+			return true
+		}
+		cp := shapeClosestPoint(s.objects[obj].Shape, p)
+		if d := cp.Sub(p).LenSqr(); d <= best {
+			best, bestObj, bestPoint = d, obj, cp
+		}
+		return best > 0
+	})
+	return bestObj, bestPoint, bestObj >= 0
+}
+
+// shapeClosestPoint returns the point of the shape closest to p, or the closest point of its
+// bounds if the shape has no ClosestPoint method.
+func shapeClosestPoint(s Shape3, p Point3) Point3 {
+	if s.ContainsPoint3(p) {
+		return p
+	}
+	if cp, ok := s.(interface{ ClosestPoint(Point3) Point3 }); ok {
+		return cp.ClosestPoint(p)
+	}
+	bounds := s.Bounds()
+	return bounds.ClosestPoint(p)
+}
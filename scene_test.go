@@ -0,0 +1,429 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	testLayerStatic = 1 << iota
+	testLayerActor
+)
+
+// testScene returns a scene of a static wall, a sphere in both layers and an actor capsule.
+func testScene() *Scene {
+	return NewScene([]SceneObject{
+		{Shape: &AABB{Position: Point3{5, 0, 0}, Size: Vec3{0.5, 2, 2}}, Layers: testLayerStatic},
+		{Shape: &Sphere{Position: Point3{0, 0, 5}, Radius: 1}, Layers: testLayerStatic | testLayerActor},
+		{Shape: &Capsule{Start: Point3{-5, -1, 0}, End: Point3{-5, 1, 0}, Radius: 0.5}, Layers: testLayerActor},
+	})
+}
+
+func TestSceneRaycast(t *testing.T) {
+	s := testScene()
+
+	testCases := []struct {
+		name string
+		ray  Ray3
+		q    SceneQuery
+		obj  int
+		dist float32
+		hit  bool
+	}{
+		{
+			name: "wall",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: X3},
+			obj:  0,
+			dist: 4.5,
+			hit:  true,
+		},
+		{
+			name: "sphere",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: Z3},
+			obj:  1,
+			dist: 4,
+			hit:  true,
+		},
+		{
+			name: "miss",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: Y3},
+			obj:  -1,
+		},
+		{
+			name: "filtered",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: X3},
+			q:    SceneQuery{Filter: func(obj int) bool { return obj != 0 }},
+			obj:  -1,
+		},
+		{
+			name: "other layer",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: X3},
+			q:    SceneQuery{Layers: testLayerActor},
+			obj:  -1,
+		},
+		{
+			name: "shared layer",
+			ray:  Ray3{Origin: Point3{0, 0, 0}, Direction: Z3},
+			q:    SceneQuery{Layers: testLayerActor},
+			obj:  1,
+			dist: 4,
+			hit:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, obj, hit := s.Raycast(tc.ray, tc.q)
+			if hit != tc.hit || obj != tc.obj {
+				t.Fatalf("got object %d, hit %v, wanted object %d, hit %v", obj, hit, tc.obj, tc.hit)
+			}
+			if hit && abs(res.Distance-tc.dist) > 1e-4 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.dist)
+			}
+		})
+	}
+}
+
+func TestSceneLineOfSight(t *testing.T) {
+	s := testScene()
+
+	testCases := []struct {
+		name string
+		a, b Point3
+		q    SceneQuery
+		want bool
+	}{
+		{
+			name: "clear",
+			a:    Point3{0, 0, 0},
+			b:    Point3{0, 5, 0},
+			want: true,
+		},
+		{
+			name: "blocked by wall",
+			a:    Point3{0, 0, 0},
+			b:    Point3{10, 0, 0},
+			want: false,
+		},
+		{
+			name: "ends short of wall",
+			a:    Point3{0, 0, 0},
+			b:    Point3{4, 0, 0},
+			want: true,
+		},
+		{
+			name: "blocked by capsule",
+			a:    Point3{0, 0, 0},
+			b:    Point3{-10, 0, 0},
+			want: false,
+		},
+		{
+			name: "passes beside sphere",
+			a:    Point3{2, 0, 0},
+			b:    Point3{2, 0, 10},
+			want: true,
+		},
+		{
+			name: "wall filtered out",
+			a:    Point3{0, 0, 0},
+			b:    Point3{10, 0, 0},
+			q:    SceneQuery{Filter: func(obj int) bool { return obj != 0 }},
+			want: true,
+		},
+		{
+			name: "capsule in another layer",
+			a:    Point3{0, 0, 0},
+			b:    Point3{-10, 0, 0},
+			q:    SceneQuery{Layers: testLayerStatic},
+			want: true,
+		},
+		{
+			name: "same point",
+			a:    Point3{5, 0, 0},
+			b:    Point3{5, 0, 0},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.LineOfSight(tc.a, tc.b, tc.q); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSceneRefit(t *testing.T) {
+	wall := &AABB{Position: Point3{5, 0, 0}, Size: Vec3{0.5, 2, 2}}
+	s := NewScene([]SceneObject{{Shape: wall}})
+	if s.LineOfSight(Point3{0, 0, 10}, Point3{10, 0, 10}, SceneQuery{}) != true {
+		t.Fatalf("wanted line of sight before the wall moves")
+	}
+	wall.Position = Point3{5, 0, 10}
+	s.Refit()
+	if s.LineOfSight(Point3{0, 0, 10}, Point3{10, 0, 10}, SceneQuery{}) != false {
+		t.Errorf("wanted the moved wall to block the line of sight")
+	}
+}
+
+func TestSceneQueryFilterAfterLayers(t *testing.T) {
+	s := testScene()
+	var seen []int
+	q := SceneQuery{
+		Layers: testLayerActor,
+		Filter: func(obj int) bool {
+			seen = append(seen, obj)
+			return true
+		},
+	}
+	s.Raycast(Ray3{Origin: Point3{0, 0, 0}, Direction: X3}, q)
+	for _, obj := range seen {
+		if obj == 0 {
+			t.Errorf("filter was called for an object outside the selected layers")
+		}
+	}
+}
+
+func TestSceneOverlap(t *testing.T) {
+	s := testScene()
+
+	testCases := []struct {
+		name  string
+		query func(s *Scene) []int
+		want  []int
+	}{
+		{
+			name: "sphere touching wall and sphere",
+			query: func(s *Scene) []int {
+				return s.OverlapSphere(&Sphere{Position: Point3{2.5, 0, 3}, Radius: 2.5}, SceneQuery{})
+			},
+			want: []int{0, 1},
+		},
+		{
+			name: "sphere inside bounds of capsule but clear of it",
+			query: func(s *Scene) []int {
+				return s.OverlapSphere(&Sphere{Position: Point3{-4.4, 1.4, 0.4}, Radius: 0.1}, SceneQuery{})
+			},
+		},
+		{
+			name: "sphere in layer",
+			query: func(s *Scene) []int {
+				return s.OverlapSphere(&Sphere{Position: Point3{2.5, 0, 3}, Radius: 2.5}, SceneQuery{Layers: testLayerActor})
+			},
+			want: []int{1},
+		},
+		{
+			name:  "box around everything",
+			query: func(s *Scene) []int { return s.OverlapAABB(&AABB{Size: Vec3{10, 10, 10}}, SceneQuery{}) },
+			want:  []int{0, 1, 2},
+		},
+		{
+			name: "box filtered",
+			query: func(s *Scene) []int {
+				return s.OverlapAABB(&AABB{Size: Vec3{10, 10, 10}}, SceneQuery{Filter: func(obj int) bool { return obj != 1 }})
+			},
+			want: []int{0, 2},
+		},
+		{
+			name: "box in corner of sphere bounds",
+			query: func(s *Scene) []int {
+				return s.OverlapAABB(&AABB{Position: Point3{0.9, 0.9, 5.9}, Size: Vec3{0.1, 0.1, 0.1}}, SceneQuery{})
+			},
+		},
+		{
+			name: "rotated box past corner of wall",
+			query: func(s *Scene) []int {
+				box := NewOBB(Point3{3.9, 0, 2.6}, Vec3{1.5, 0.1, 0.1}, mgl32.QuatRotate(-pi/4, Y3))
+				return s.OverlapOBB(&box, SceneQuery{})
+			},
+		},
+		{
+			name: "rotated box along wall",
+			query: func(s *Scene) []int {
+				box := NewOBB(Point3{4, 0, 0}, Vec3{1.5, 0.1, 0.1}, mgl32.QuatRotate(pi/4, Y3))
+				return s.OverlapOBB(&box, SceneQuery{})
+			},
+			want: []int{0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.query(s)
+			sort.Ints(got)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, wanted %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, wanted %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSceneOverlapSphereRandom(t *testing.T) {
+	// Every convex shape overlaps a sphere exactly when its closest point is within the radius
+	type closest interface {
+		Shape3
+		ClosestPoint(p Point3) Point3
+	}
+	r := rand.New(rand.NewSource(1))
+	region := AABB{Size: Vec3{20, 20, 20}}
+	var shapes []closest
+	for i := 0; i < 60; i++ {
+		p := RandomPointInAABB(r, region)
+		size := Vec3{0.5 + r.Float32()*2, 0.5 + r.Float32()*2, 0.5 + r.Float32()*2}
+		rot := mgl32.QuatRotate(r.Float32()*2*pi, RandomUnitVec3(r))
+		switch i % 6 {
+		case 0:
+			shapes = append(shapes, &AABB{Position: p, Size: size})
+		case 1:
+			o := NewOBB(p, size, rot)
+			shapes = append(shapes, &o)
+		case 2:
+			shapes = append(shapes, &Sphere{Position: p, Radius: size[0]})
+		case 3:
+			shapes = append(shapes, &Capsule{Start: p, End: p.Add(RandomUnitVec3(r).Mul(size[1] * 2)), Radius: size[0]})
+		case 4:
+			shapes = append(shapes, &Cone{Apex: p, Direction: RandomUnitVec3(r), Angle: 0.2 + r.Float32(), Range: 3 * size[0]})
+		case 5:
+			e := Ellipsoid{Position: p, Radii: size, Orientation: rot}
+			shapes = append(shapes, &e)
+		}
+	}
+	objects := make([]SceneObject, len(shapes))
+	for i, sh := range shapes {
+		objects[i] = SceneObject{Shape: sh}
+	}
+	s := NewScene(objects)
+
+	for q := 0; q < 300; q++ {
+		sp := Sphere{Position: RandomPointInAABB(r, region), Radius: 0.5 + r.Float32()*4}
+		got := make(map[int]bool)
+		for _, obj := range s.OverlapSphere(&sp, SceneQuery{}) {
+			got[obj] = true
+		}
+		for i, sh := range shapes {
+			var d float32
+			if !sh.ContainsPoint3(sp.Position) {
+				d = sh.ClosestPoint(sp.Position).Sub(sp.Position).Len()
+			}
+			if abs(d-sp.Radius) < 1e-2 {
+				continue
+			}
+			if want := d < sp.Radius; got[i] != want {
+				t.Fatalf("sphere %v and %T %v at distance %v: got overlap %v, wanted %v", sp, sh, sh, d, got[i], want)
+			}
+		}
+	}
+}
+
+func TestSceneNearest(t *testing.T) {
+	s := testScene()
+
+	testCases := []struct {
+		name    string
+		p       Point3
+		maxDist float32
+		q       SceneQuery
+		obj     int
+		point   Point3
+		found   bool
+	}{
+		{
+			name:    "wall",
+			p:       Point3{3, 0, 0},
+			maxDist: 10,
+			obj:     0,
+			point:   Point3{4.5, 0, 0},
+			found:   true,
+		},
+		{
+			name:    "sphere",
+			p:       Point3{0, 0, 2},
+			maxDist: 10,
+			obj:     1,
+			point:   Point3{0, 0, 4},
+			found:   true,
+		},
+		{
+			name:    "inside capsule",
+			p:       Point3{-5, 0.5, 0.2},
+			maxDist: 10,
+			obj:     2,
+			point:   Point3{-5, 0.5, 0.2},
+			found:   true,
+		},
+		{
+			name:    "beyond max distance",
+			p:       Point3{3, 0, 0},
+			maxDist: 1,
+			obj:     -1,
+		},
+		{
+			name:    "nearer object in another layer",
+			p:       Point3{3, 0, 0},
+			maxDist: 10,
+			q:       SceneQuery{Layers: testLayerActor},
+			obj:     1,
+			point:   Point3{0, 0, 5}.Add(Vec3{3, 0, -5}.Normalize()),
+			found:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, pt, found := s.Nearest(tc.p, tc.maxDist, tc.q)
+			if found != tc.found || obj != tc.obj {
+				t.Fatalf("got object %d, found %v, wanted object %d, found %v", obj, found, tc.obj, tc.found)
+			}
+			if found && !nearVec3(pt, tc.point, 1e-4) {
+				t.Errorf("got point %v, wanted %v", pt, tc.point)
+			}
+		})
+	}
+}
+
+func TestSceneNearestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	region := AABB{Size: Vec3{50, 50, 50}}
+	objects := make([]SceneObject, 200)
+	for i := range objects {
+		p := RandomPointInAABB(r, region)
+		if i%2 == 0 {
+			objects[i] = SceneObject{Shape: &Sphere{Position: p, Radius: 0.5 + r.Float32()}, Layers: 1}
+		} else {
+			o := NewOBB(p, Vec3{1, 2, 0.5}, mgl32.QuatRotate(r.Float32()*2*pi, RandomUnitVec3(r)))
+			objects[i] = SceneObject{Shape: &o, Layers: 2}
+		}
+	}
+	s := NewScene(objects)
+
+	for q := 0; q < 200; q++ {
+		p := RandomPointInAABB(r, region)
+		query := SceneQuery{Layers: uint32(1 + q%3)}
+		want, wantDist := -1, float32(maxFloat32)
+		for i, o := range objects {
+			if o.Layers&query.Layers == 0 {
+				continue
+			}
+			if d := shapeClosestPoint(o.Shape, p).Sub(p).Len(); d < wantDist {
+				want, wantDist = i, d
+			}
+		}
+		obj, pt, found := s.Nearest(p, float32(math.Inf(1)), query)
+		if !found {
+			t.Fatalf("query %d: found nothing, wanted object %d", q, want)
+		}
+		if d := pt.Sub(p).Len(); abs(d-wantDist) > 1e-4 {
+			t.Fatalf("query %d: got object %d at %v, wanted object %d at %v", q, obj, d, want, wantDist)
+		}
+	}
+}
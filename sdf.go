@@ -0,0 +1,224 @@
+package geom
+
+import "math"
+
+// SDFOptions controls how BakeSDF samples a mesh.
+type SDFOptions struct {
+	// CellSize is the spacing between samples. It must be greater than zero.
+	CellSize float32
+
+	// NarrowBand is the distance from the surface within which exact distances are computed.
+	// Samples further away are clamped to plus or minus NarrowBand. Defaults to three cells.
+	NarrowBand float32
+
+	// Padding is the number of cells added around the bounds of the mesh. Defaults to enough
+	// cells to hold the narrow band outside the mesh.
+	Padding int
+}
+
+// SDF is a signed distance field sampled on a grid. Each cell of the grid holds the distance
+// from its centre to the nearest point on the surface of a mesh, negative inside the mesh and
+// positive outside.
+type SDF struct {
+	Grid *Grid3[float32]
+	Band float32 // the largest distance held in the grid
+}
+
+// BakeSDF samples the signed distance to the surface of the mesh at the centre of each cell of
+// a grid covering the mesh. The mesh must be closed for the sign to be well defined; the inside
+// is found in the same way as by Voxelize with VoxelizeSolid.
+func BakeSDF(m *TriMesh, opts SDFOptions) *SDF {
+	if opts.CellSize <= 0 {
+		panic("geom: sdf cell size must be positive")
+	}
+	band := opts.NarrowBand
+	if band <= 0 {
+		band = 3 * opts.CellSize
+	}
+	pad := opts.Padding
+	if pad <= 0 {
+		pad = int(math.Ceil(float64(band/opts.CellSize))) + 1
+	}
+
+	b := m.Bounds()
+	margin := Vec3{1, 1, 1}.Mul(float32(pad) * opts.CellSize)
+	g := newGrid3Covering[float32](b.Min().Sub(margin), b.Max().Add(margin), opts.CellSize)
+
+	// Squared distances to the nearest triangle, limited to the band
+	band2 := band * band
+	for i := range g.Cells {
+		g.Cells[i] = band2
+	}
+	nx, ny, nz := g.Dims[0], g.Dims[1], g.Dims[2]
+	parallelFor(nz, 4, func(zstart, zend int) {
+		reach := Vec3{band, band, band}
+		for i := 0; i < m.Len(); i++ {
+			t := m.Tri(i)
+			tb := AABBFromPoints([]Point3{t.A, t.B, t.C})
+			x0, y0, z0 := g.clampedCell(tb.Min().Sub(reach))
+			x1, y1, z1 := g.clampedCell(tb.Max().Add(reach))
+			if z0 < zstart {
+				z0 = zstart
+			}
+			if z1 > zend-1 {
+				z1 = zend - 1
+			}
+			for z := z0; z <= z1; z++ {
+				for y := y0; y <= y1; y++ {
+					row := nx * (y + ny*z)
+					for x := x0; x <= x1; x++ {
+						if d := DistanceSquaredPointTri3(g.CellCentre(x, y, z), t); d < g.Cells[row+x] {
+							g.Cells[row+x] = d
+						}
+					}
+				}
+			}
+		}
+	})
+
+	inside := &Grid3[bool]{Origin: g.Origin, CellSize: g.CellSize, Dims: g.Dims, Cells: make([]bool, len(g.Cells))}
+	if m.Len() > 0 {
+		voxelizeInterior(m, inside)
+	}
+	for i, d := range g.Cells {
+		d = sqrt(d)
+		if inside.Cells[i] {
+			d = -d
+		}
+		g.Cells[i] = d
+	}
+	return &SDF{Grid: g, Band: band}
+}
+
+// Distance returns the signed distance from p to the surface, interpolated between the
+// samples around p. Beyond the narrow band the distance is only known to be at least Band.
+// Points outside the grid take the value at the nearest point on the grid plus their distance
+// from it.
+func (s *SDF) Distance(p Point3) float32 {
+	g := s.Grid
+	b := g.Bounds()
+	q := b.ClosestPoint(p)
+	d := s.sample(q)
+	if q != p {
+		d += p.Sub(q).Len()
+	}
+	return d
+}
+
+// Gradient returns the direction in which the distance increases fastest at p, which is the
+// outward normal of the surface near it, or the zero vector where the field is flat. It is
+// estimated from the differences between interpolated samples half a cell either side of p.
+func (s *SDF) Gradient(p Point3) Vec3 {
+	h := s.Grid.CellSize / 2
+	var grad Vec3
+	for i := 0; i < 3; i++ {
+		var off Vec3
+		off[i] = h
+		grad[i] = s.Distance(p.Add(off)) - s.Distance(p.Sub(off))
+	}
+	if grad.LenSqr() == 0 {
+		return Vec3{}
+	}
+	return grad.Normalize()
+}
+
+// RayMarchOptions controls how RayMarch steps along a ray.
+type RayMarchOptions struct {
+	// MaxSteps is the largest number of steps taken along the ray. Defaults to 128.
+	MaxSteps int
+
+	// Epsilon is how close to the surface the ray must come to hit it. It is also the offset
+	// used to estimate the normal. Defaults to 1e-4.
+	Epsilon float32
+
+	// MaxDistance is how far along the ray to search. Zero means the search is only limited by
+	// MaxSteps.
+	MaxDistance float32
+}
+
+// RayMarch finds where the ray meets the surface of a signed distance function using sphere
+// tracing. sdf must return the signed distance from a point to the surface, negative inside,
+// or an underestimate of it; the Distance method of an SDF may be used. A ray that starts
+// inside hits the surface on its way out. The normal is estimated from the differences in the
+// distance around the point that was hit. The direction of the ray is normalized first, since
+// each step advances the ray by a distance, so the result's Distance is in world units.
+//
+// A ray that goes further than MaxDistance misses with RaycastFailOutsideBounds. One that
+// runs out of steps first, which happens when it grazes the surface, misses with
+// RaycastFailUnknown.
+func RayMarch(ray Ray3, sdf func(Point3) float32, opts RayMarchOptions) (RaycastResult, bool) {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = 128
+	}
+	if opts.Epsilon <= 0 {
+		opts.Epsilon = 1e-4
+	}
+	if opts.MaxDistance <= 0 {
+		opts.MaxDistance = maxFloat32
+	}
+
+	ray.Direction = ray.Direction.Normalize()
+
+	var res RaycastResult
+	var t float32
+	for i := 0; i < opts.MaxSteps; i++ {
+		p := ray.Point(t)
+		d := abs(sdf(p))
+		if d < opts.Epsilon {
+			res.Distance = t
+			res.Point = p
+			res.Normal = sdfNormal(sdf, p, opts.Epsilon)
+			return res, true
+		}
+
+		// No surface is nearer than d so the ray can safely advance that far
+		t += d
+		if t > opts.MaxDistance {
+			res.Fail = RaycastFailOutsideBounds
+			return res, false
+		}
+	}
+	res.Fail = RaycastFailUnknown
+	return res, false
+}
+
+// sdfNormal estimates the gradient of sdf at p from samples at the corners of a tetrahedron
+// of size h around it, which needs one fewer sample than central differences. It returns the
+// zero vector where the field is flat.
+func sdfNormal(sdf func(Point3) float32, p Point3, h float32) Vec3 {
+	var n Vec3
+	for _, k := range [4]Vec3{{1, -1, -1}, {-1, -1, 1}, {-1, 1, -1}, {1, 1, 1}} {
+		n = n.Add(k.Mul(sdf(p.Add(k.Mul(h)))))
+	}
+	if n.LenSqr() == 0 {
+		return Vec3{}
+	}
+	return n.Normalize()
+}
+
+// sample returns the trilinear interpolation of the samples around p, which must lie inside
+// the grid.
+func (s *SDF) sample(p Point3) float32 {
+	g := s.Grid
+	var i0, i1 [3]int
+	var f [3]float32
+	for a := 0; a < 3; a++ {
+		// Samples are held at cell centres
+		u := (p[a]-g.Origin[a])/g.CellSize - 0.5
+		u = Clamp(u, 0, float32(g.Dims[a]-1))
+		fl := float32(math.Floor(float64(u)))
+		i0[a] = int(fl)
+		i1[a] = i0[a] + 1
+		if i1[a] == g.Dims[a] {
+			i1[a] = i0[a]
+		}
+		f[a] = u - fl
+	}
+
+	lerp := func(a, b, t float32) float32 { return a + (b-a)*t }
+	c00 := lerp(g.At(i0[0], i0[1], i0[2]), g.At(i1[0], i0[1], i0[2]), f[0])
+	c10 := lerp(g.At(i0[0], i1[1], i0[2]), g.At(i1[0], i1[1], i0[2]), f[0])
+	c01 := lerp(g.At(i0[0], i0[1], i1[2]), g.At(i1[0], i0[1], i1[2]), f[0])
+	c11 := lerp(g.At(i0[0], i1[1], i1[2]), g.At(i1[0], i1[1], i1[2]), f[0])
+	return lerp(lerp(c00, c10, f[1]), lerp(c01, c11, f[1]), f[2])
+}
@@ -0,0 +1,132 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// boxSignedDistance returns the exact signed distance from p to the surface of the box.
+func boxSignedDistance(a *AABB, p Point3) float32 {
+	var q Vec3
+	for i := 0; i < 3; i++ {
+		q[i] = abs(p[i]-a.Position[i]) - a.Size[i]
+	}
+	outside := Vec3{max(q[0], 0), max(q[1], 0), max(q[2], 0)}.Len()
+	inside := min(max(q[0], max(q[1], q[2])), 0)
+	return outside + inside
+}
+
+func TestBakeSDF(t *testing.T) {
+	box := AABB{Position: Point3{0.1, 0.2, -0.1}, Size: Vec3{2, 1.5, 1}}
+	const cell = 0.25
+	sdf := BakeSDF(boxMesh(box), SDFOptions{CellSize: cell, NarrowBand: 4 * cell})
+
+	// The grid reaches beyond the box by at least the band
+	if gb := sdf.Grid.Bounds(); !gb.ContainsPoint3(box.Max().Add(Vec3{1, 1, 1})) || !gb.ContainsPoint3(box.Min().Sub(Vec3{1, 1, 1})) {
+		t.Errorf("grid bounds %v do not cover the band around %v", gb, box)
+	}
+
+	// Samples are exact within the band and clamped beyond it
+	g := sdf.Grid
+	for z := 0; z < g.Dims[2]; z++ {
+		for y := 0; y < g.Dims[1]; y++ {
+			for x := 0; x < g.Dims[0]; x++ {
+				want := boxSignedDistance(&box, g.CellCentre(x, y, z))
+				want = Clamp(want, -sdf.Band, sdf.Band)
+				if got := g.At(x, y, z); abs(got-want) > 1e-4 {
+					t.Fatalf("sample %d,%d,%d: got %v, wanted %v", x, y, z, got, want)
+				}
+			}
+		}
+	}
+
+	// Interpolated distances are close to exact near the surface
+	r := rand.New(rand.NewSource(1))
+	near := AABB{Position: box.Position, Size: box.Size.Add(Vec3{0.5, 0.5, 0.5})}
+	for i := 0; i < 500; i++ {
+		p := RandomPointInAABB(r, near)
+		want := boxSignedDistance(&box, p)
+		if abs(want) > sdf.Band-cell {
+			continue
+		}
+		if got := sdf.Distance(p); abs(got-want) > cell/2 {
+			t.Errorf("Distance(%v): got %v, wanted %v", p, got, want)
+		}
+	}
+
+	if n := sdf.Gradient(Point3{2.2, 0.2, -0.1}); !nearVec3(n, Vec3{1, 0, 0}, 1e-3) {
+		t.Errorf("Gradient on +x face: got %v, wanted (1, 0, 0)", n)
+	}
+	if n := sdf.Gradient(Point3{0.1, 0.2, -1.2}); !nearVec3(n, Vec3{0, 0, -1}, 1e-3) {
+		t.Errorf("Gradient on -z face: got %v, wanted (0, 0, -1)", n)
+	}
+
+	// Far outside the grid the distance keeps growing
+	far := Point3{20, 0.2, -0.1}
+	if d := sdf.Distance(far); d < 17 {
+		t.Errorf("Distance far outside: got %v, wanted at least 17", d)
+	}
+}
+
+func TestRayMarch(t *testing.T) {
+	sphere := func(p Point3) float32 { return p.Len() - 1 }
+	box := AABB{Size: Vec3{1, 1, 1}}
+	boxSDF := func(p Point3) float32 { return boxSignedDistance(&box, p) }
+
+	testCases := []struct {
+		name   string
+		sdf    func(Point3) float32
+		ray    Ray3
+		opts   RayMarchOptions
+		hit    bool
+		fail   RaycastFail
+		dist   float32
+		normal Vec3
+	}{
+		{name: "sphere", sdf: sphere, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, hit: true, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "sphere-inside", sdf: sphere, ray: Ray3{Direction: Y3}, hit: true, dist: 1, normal: Y3},
+		{name: "sphere-miss", sdf: sphere, ray: Ray3{Origin: Point3{-5, 2, 0}, Direction: X3}, opts: RayMarchOptions{MaxDistance: 20}, fail: RaycastFailOutsideBounds},
+		{name: "sphere-too-far", sdf: sphere, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, opts: RayMarchOptions{MaxDistance: 3}, fail: RaycastFailOutsideBounds},
+		{name: "sphere-too-few-steps", sdf: sphere, ray: Ray3{Origin: Point3{-5, 1.01, 0}, Direction: X3}, opts: RayMarchOptions{MaxSteps: 8}, fail: RaycastFailUnknown},
+		{name: "sphere-long-direction", sdf: sphere, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: Vec3{5, 0, 0}}, hit: true, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "sphere-short-direction", sdf: sphere, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: Vec3{0.1, 0, 0}}, hit: true, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "box", sdf: boxSDF, ray: Ray3{Origin: Point3{0.5, 5, 0.2}, Direction: Vec3{0, -1, 0}}, hit: true, dist: 4, normal: Y3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := RayMarch(tc.ray, tc.sdf, tc.opts)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v [fail=%v]", ok, tc.hit, res.Fail)
+			}
+			if !ok {
+				if res.Fail != tc.fail {
+					t.Errorf("got fail %v, wanted %v", res.Fail, tc.fail)
+				}
+				return
+			}
+			if abs(res.Distance-tc.dist) > 1e-3 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.dist)
+			}
+			if !nearVec3(res.Normal, tc.normal, 1e-3) {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.normal)
+			}
+		})
+	}
+
+	// A baked field gives the same hit as the exact one to within a cell
+	baked := BakeSDF(boxMesh(box), SDFOptions{CellSize: 0.1})
+	ray := Ray3{Origin: Point3{-5, 0.3, -0.2}, Direction: X3}
+	res, ok := RayMarch(ray, baked.Distance, RayMarchOptions{Epsilon: 1e-3})
+	if !ok || abs(res.Distance-4) > 0.1 || !nearVec3(res.Normal, Vec3{-1, 0, 0}, 1e-2) {
+		t.Errorf("baked: got %v, %v, wanted distance 4 with normal (-1, 0, 0)", res, ok)
+	}
+}
+
+func BenchmarkBakeSDF(b *testing.B) {
+	m := boxMesh(AABB{Size: Vec3{2, 2, 2}})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		BakeSDF(m, SDFOptions{CellSize: 0.05})
+	}
+}
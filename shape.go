@@ -0,0 +1,145 @@
+package geom
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	_ Shape3      = (*AABB)(nil)
+	_ Shape3      = (*OBB)(nil)
+	_ Shape3      = (*Sphere)(nil)
+	_ Shape3      = (*Capsule)(nil)
+	_ Shape3      = (*Cone)(nil)
+	_ Shape3      = (*Ellipsoid)(nil)
+	_ Shape3      = (*CompoundShape)(nil)
+	_ Raycastable = (*Plane3)(nil)
+	_ Raycastable = (*TriMesh)(nil)
+)
+
+// ShapeKind identifies the type of a 3 dimensional shape so that collections of mixed shapes
+// can be stored, serialized and dispatched on uniformly. The values are stable and new kinds
+// are only ever added at the end, so they may be written to files.
+type ShapeKind int
+
+const (
+	// ShapeUnknown is the kind of values that are not shapes from this package.
+	ShapeUnknown ShapeKind = iota
+
+	ShapeAABB
+	ShapeOBB
+	ShapeSphere
+	ShapeCapsule
+	ShapeCone
+	ShapeEllipsoid
+	ShapePlane3
+	ShapeTri3
+	ShapeCompound
+	ShapeTriMesh
+)
+
+func (k ShapeKind) String() string {
+	switch k {
+	case ShapeAABB:
+		return "aabb"
+	case ShapeOBB:
+		return "obb"
+	case ShapeSphere:
+		return "sphere"
+	case ShapeCapsule:
+		return "capsule"
+	case ShapeCone:
+		return "cone"
+	case ShapeEllipsoid:
+		return "ellipsoid"
+	case ShapePlane3:
+		return "plane"
+	case ShapeTri3:
+		return "triangle"
+	case ShapeCompound:
+		return "compound"
+	case ShapeTriMesh:
+		return "mesh"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the kind as its name, so that it appears as a string in formats such as
+// JSON.
+func (k ShapeKind) MarshalText() ([]byte, error) {
+	if k.String() == "unknown" {
+		return nil, fmt.Errorf("geom: unknown shape kind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind from the name written by MarshalText.
+func (k *ShapeKind) UnmarshalText(text []byte) error {
+	for kk := ShapeAABB; kk <= ShapeTriMesh; kk++ {
+		if kk.String() == string(text) {
+			*k = kk
+			return nil
+		}
+	}
+	return fmt.Errorf("geom: unknown shape kind %q", text)
+}
+
+// ShapeKindOf returns the kind of the shape s, or ShapeUnknown if s is not a shape from this
+// package. Shapes with pointer receivers are only recognised by pointer.
+func ShapeKindOf(s any) ShapeKind {
+	switch s.(type) {
+	case *AABB:
+		return ShapeAABB
+	case *OBB:
+		return ShapeOBB
+	case *Sphere:
+		return ShapeSphere
+	case *Capsule:
+		return ShapeCapsule
+	case *Cone:
+		return ShapeCone
+	case *Ellipsoid:
+		return ShapeEllipsoid
+	case *Plane3:
+		return ShapePlane3
+	case Tri3, *Tri3:
+		return ShapeTri3
+	case *CompoundShape:
+		return ShapeCompound
+	case *TriMesh:
+		return ShapeTriMesh
+	default:
+		return ShapeUnknown
+	}
+}
+
+// NewShape returns a pointer to a new empty shape of the kind, ready to be decoded into, or
+// nil if the kind is unknown. Shapes with an orientation start with no rotation.
+func NewShape(k ShapeKind) Raycastable {
+	switch k {
+	case ShapeAABB:
+		return &AABB{}
+	case ShapeOBB:
+		return &OBB{Orientation: mgl32.QuatIdent()}
+	case ShapeSphere:
+		return &Sphere{}
+	case ShapeCapsule:
+		return &Capsule{}
+	case ShapeCone:
+		return &Cone{}
+	case ShapeEllipsoid:
+		return &Ellipsoid{Orientation: mgl32.QuatIdent()}
+	case ShapePlane3:
+		return &Plane3{}
+	case ShapeTri3:
+		return &Tri3{}
+	case ShapeCompound:
+		return &CompoundShape{}
+	case ShapeTriMesh:
+		return &TriMesh{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,301 @@
+package geom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestShapeKind(t *testing.T) {
+	for k := ShapeAABB; k <= ShapeTriMesh; k++ {
+		t.Run(k.String(), func(t *testing.T) {
+			s := NewShape(k)
+			if s == nil {
+				t.Fatalf("NewShape returned nil")
+			}
+			if got := ShapeKindOf(s); got != k {
+				t.Errorf("ShapeKindOf(NewShape(%v)) = %v", k, got)
+			}
+
+			data, err := json.Marshal(k)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var got ShapeKind
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+			if got != k {
+				t.Errorf("round trip through %s gave %v", data, got)
+			}
+		})
+	}
+
+	if got := ShapeKindOf(Tri3{}); got != ShapeTri3 {
+		t.Errorf("ShapeKindOf(Tri3{}) = %v, wanted %v", got, ShapeTri3)
+	}
+	if got := ShapeKindOf(Rect{}); got != ShapeUnknown {
+		t.Errorf("ShapeKindOf(Rect{}) = %v, wanted %v", got, ShapeUnknown)
+	}
+	if NewShape(ShapeUnknown) != nil {
+		t.Errorf("NewShape(ShapeUnknown) should be nil")
+	}
+	if _, err := ShapeUnknown.MarshalText(); err == nil {
+		t.Errorf("MarshalText of ShapeUnknown should fail")
+	}
+	var k ShapeKind
+	if err := k.UnmarshalText([]byte("torus")); err == nil {
+		t.Errorf("UnmarshalText of an unknown name should fail")
+	}
+}
+
+func TestShapeRaycast(t *testing.T) {
+	tilt := mgl32.QuatRotate(pi/4, Y3)
+	testCases := []struct {
+		name   string
+		shape  Raycastable
+		ray    Ray3
+		dist   float32
+		normal Vec3
+	}{
+		{name: "aabb-x", shape: &AABB{Size: Vec3{1, 1, 1}}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "aabb-y", shape: &AABB{Size: Vec3{1, 1, 1}}, ray: Ray3{Origin: Point3{0.5, -5, 0.5}, Direction: Y3}, dist: 4, normal: Vec3{0, -1, 0}},
+		{name: "aabb-inside", shape: &AABB{Size: Vec3{1, 2, 1}}, ray: Ray3{Direction: Y3}, dist: 2, normal: Vec3{0, 1, 0}},
+		{name: "obb-x", shape: &OBB{Size: Vec3{1, 1, 1}, Orientation: mgl32.QuatIdent()}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "obb-tilted", shape: &OBB{Size: Vec3{1, 1, 1}, Orientation: tilt}, ray: Ray3{Origin: Point3{-5, 0, -5}, Direction: Vec3{1, 0, 1}.Normalize()}, dist: 5*sqrt(2) - 1, normal: tilt.Rotate(Vec3{0, 0, -1})},
+		{name: "sphere", shape: &Sphere{Radius: 1}, ray: Ray3{Origin: Point3{0, 0, 5}, Direction: Vec3{0, 0, -1}}, dist: 4, normal: Vec3{0, 0, 1}},
+		{name: "capsule-side", shape: &Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}, ray: Ray3{Origin: Point3{-5, 0.5, 0}, Direction: X3}, dist: 4, normal: Vec3{-1, 0, 0}},
+		{name: "capsule-cap", shape: &Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}, ray: Ray3{Origin: Point3{0, 5, 0}, Direction: Vec3{0, -1, 0}}, dist: 3, normal: Vec3{0, 1, 0}},
+		{name: "capsule-inside", shape: &Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}, ray: Ray3{Direction: Vec3{0, -1, 0}}, dist: 2, normal: Vec3{0, -1, 0}},
+		{name: "capsule-point", shape: &Capsule{Radius: 1}, ray: Ray3{Origin: Point3{3, 0, 0}, Direction: Vec3{-1, 0, 0}}, dist: 2, normal: Vec3{1, 0, 0}},
+		{name: "plane", shape: &Plane3{Normal: Y3}, ray: Ray3{Origin: Point3{1, 3, 1}, Direction: Vec3{0, -1, 0}}, dist: 3, normal: Y3},
+		{name: "tri", shape: Tri3{A: Point3{-1, 0, -1}, B: Point3{0, 0, 1}, C: Point3{1, 0, -1}}, ray: Ray3{Origin: Point3{0, 2, 0}, Direction: Vec3{0, -1, 0}}, dist: 2, normal: Y3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.shape.Raycast(tc.ray)
+			if !ok {
+				t.Fatalf("got miss [fail=%v], wanted hit", res.Fail)
+			}
+			if abs(res.Distance-tc.dist) > 1e-5 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.dist)
+			}
+			if !nearVec3(res.Normal, tc.normal, 1e-5) {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.normal)
+			}
+			if !nearVec3(res.Point, tc.ray.Point(tc.dist), 1e-5) {
+				t.Errorf("got point %v, wanted %v", res.Point, tc.ray.Point(tc.dist))
+			}
+		})
+	}
+}
+
+func TestShapeRaycastMiss(t *testing.T) {
+	tri := Tri3{A: Point3{-1, 0, -1}, B: Point3{0, 0, 1}, C: Point3{1, 0, -1}}
+	capsule := &Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}
+	testCases := []struct {
+		name  string
+		shape Raycastable
+		ray   Ray3
+		fail  RaycastFail
+	}{
+		{name: "aabb-edge-parallel", shape: &AABB{Size: Vec3{1, 1, 1}}, ray: Ray3{Origin: Point3{-5, 1.5, 0}, Direction: X3}, fail: RaycastFailOutsideBounds},
+		{name: "obb-behind", shape: &OBB{Size: Vec3{1, 1, 1}, Orientation: mgl32.QuatIdent()}, ray: Ray3{Origin: Point3{5, 0, 0}, Direction: X3}, fail: RaycastFailTargetBehindRayOrigin},
+		{name: "capsule-beside", shape: capsule, ray: Ray3{Origin: Point3{-5, 0, 1.5}, Direction: X3}, fail: RaycastFailOutsideBounds},
+		{name: "capsule-behind", shape: capsule, ray: Ray3{Origin: Point3{5, 0, 0}, Direction: X3}, fail: RaycastFailOutsideBounds},
+		{name: "tri-back", shape: tri, ray: Ray3{Origin: Point3{0, -2, 0}, Direction: Y3}, fail: RaycastFailPlaneFacesAwayFromRay},
+		{name: "tri-outside", shape: tri, ray: Ray3{Origin: Point3{2, 2, 0}, Direction: Vec3{0, -1, 0}}, fail: RaycastFailOutsideBounds},
+		{name: "tri-behind", shape: tri, ray: Ray3{Origin: Point3{0, -2, 0}, Direction: Vec3{0, -1, 0}}, fail: RaycastFailTargetBehindRayOrigin},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.shape.Raycast(tc.ray)
+			if ok {
+				t.Fatalf("got hit %v, wanted miss", res)
+			}
+			if res.Fail != tc.fail {
+				t.Errorf("got fail %v, wanted %v", res.Fail, tc.fail)
+			}
+		})
+	}
+}
+
+func TestRaycastResultEnterExit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		shape       Raycastable
+		ray         Ray3
+		enter, exit float32
+	}{
+		{name: "aabb", shape: &AABB{Size: Vec3{1, 2, 1}}, ray: Ray3{Origin: Point3{0, -5, 0}, Direction: Y3}, enter: 3, exit: 7},
+		{name: "aabb-inside", shape: &AABB{Size: Vec3{1, 2, 1}}, ray: Ray3{Direction: Y3}, enter: -2, exit: 2},
+		{name: "obb", shape: &OBB{Size: Vec3{1, 2, 1}, Orientation: mgl32.QuatRotate(pi/2, Z3)}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, enter: 3, exit: 7},
+		{name: "sphere", shape: &Sphere{Radius: 2}, ray: Ray3{Origin: Point3{0, 0, -5}, Direction: Z3}, enter: 3, exit: 7},
+		{name: "sphere-inside", shape: &Sphere{Radius: 2}, ray: Ray3{Origin: Point3{0, 0, 1}, Direction: Z3}, enter: -3, exit: 1},
+		{name: "capsule", shape: &Capsule{Start: Point3{-1, 0, 0}, End: Point3{1, 0, 0}, Radius: 1}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, enter: 3, exit: 7},
+		{name: "capsule-across", shape: &Capsule{Start: Point3{-1, 0, 0}, End: Point3{1, 0, 0}, Radius: 1}, ray: Ray3{Origin: Point3{0.5, -5, 0}, Direction: Y3}, enter: 4, exit: 6},
+		{name: "ellipsoid", shape: &Ellipsoid{Radii: Vec3{1, 3, 1}, Orientation: mgl32.QuatIdent()}, ray: Ray3{Origin: Point3{0, 5, 0}, Direction: Vec3{0, -1, 0}}, enter: 2, exit: 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.shape.Raycast(tc.ray)
+			if !ok {
+				t.Fatalf("got miss [fail=%v], wanted hit", res.Fail)
+			}
+			if abs(res.Enter-tc.enter) > 1e-5 || abs(res.Exit-tc.exit) > 1e-5 {
+				t.Errorf("got enter %v and exit %v, wanted %v and %v", res.Enter, res.Exit, tc.enter, tc.exit)
+			}
+		})
+	}
+}
+
+func TestRaycastResultParts(t *testing.T) {
+	tri := Tri3{A: Point3{-1, 0, -1}, B: Point3{0, 0, 1}, C: Point3{1, 0, -1}}
+	ray := Ray3{Origin: Point3{0.25, 2, -0.5}, Direction: Vec3{0, -1, 0}}
+	res, ok := tri.Raycast(ray)
+	if !ok {
+		t.Fatalf("Tri3: got miss [fail=%v], wanted hit", res.Fail)
+	}
+	b := res.Barycentric
+	if got := tri.A.Mul(b[0]).Add(tri.B.Mul(b[1])).Add(tri.C.Mul(b[2])); !nearVec3(got, res.Point, 1e-5) || abs(b[0]+b[1]+b[2]-1) > 1e-5 {
+		t.Errorf("Tri3: barycentric %v gives %v, wanted %v", b, got, res.Point)
+	}
+
+	m := boxMesh(AABB{Size: Vec3{1, 1, 1}})
+	res, ok = m.Raycast(Ray3{Origin: Point3{0.2, 0.3, 5}, Direction: Vec3{0, 0, -1}})
+	if !ok {
+		t.Fatalf("TriMesh: got miss [fail=%v], wanted hit", res.Fail)
+	}
+	if res.Distance != 4 || res.Normal != Z3 {
+		t.Errorf("TriMesh: got distance %v and normal %v, wanted 4 and %v", res.Distance, res.Normal, Z3)
+	}
+	if hit := m.Tri(res.Index); !hit.Normal().ApproxEqual(Z3) {
+		t.Errorf("TriMesh: triangle %d does not face +z", res.Index)
+	}
+
+	var c CompoundShape
+	s := &Sphere{Radius: 1}
+	c.Add(&AABB{Size: Vec3{1, 1, 1}}, NewTransform())
+	tx := NewTransform()
+	tx.SetPosition(Vec3{0, 0, -5})
+	tx.SetScale(Vec3{2, 2, 2})
+	c.Add(s, tx)
+	res, child, ok := c.RaycastChild(Ray3{Origin: Point3{0, 0, -10}, Direction: Z3})
+	if !ok {
+		t.Fatalf("CompoundShape: got miss [fail=%v], wanted hit", res.Fail)
+	}
+	if child != 1 || res.Shape != s {
+		t.Errorf("CompoundShape: got child %d and shape %v, wanted child 1", child, res.Shape)
+	}
+	if abs(res.Enter-3) > 1e-5 || abs(res.Exit-7) > 1e-5 {
+		t.Errorf("CompoundShape: got enter %v and exit %v, wanted 3 and 7", res.Enter, res.Exit)
+	}
+
+	mc := &CompoundShape{}
+	mc.Add(solidMesh{m}, NewTransform())
+	res, child, ok = mc.RaycastChild(Ray3{Origin: Point3{0.2, 0.3, 5}, Direction: Vec3{0, 0, -1}})
+	if want, _ := m.Raycast(Ray3{Origin: Point3{0.2, 0.3, 5}, Direction: Vec3{0, 0, -1}}); !ok || child != 0 || res.Index != want.Index {
+		t.Errorf("CompoundShape: got child %d and triangle %d, wanted child 0 and triangle %d", child, res.Index, want.Index)
+	}
+
+	items := []Bounded{&Sphere{Position: Point3{0, 0, 5}, Radius: 1}, s}
+	bvh := BuildBVH(items, BVHOptions{})
+	res, item, ok := bvh.Raycast(Ray3{Origin: Point3{0, 0, 10}, Direction: Vec3{0, 0, -1}}, nil)
+	if !ok || item != 0 || res.Shape != items[0] {
+		t.Errorf("BVH: got item %d with shape %v, wanted item 0", item, res.Shape)
+	}
+}
+
+// solidMesh lets a closed mesh be used as a child of a compound shape.
+type solidMesh struct{ *TriMesh }
+
+func (solidMesh) ContainsPoint3(Point3) bool { return false }
+
+func TestRaycastWith(t *testing.T) {
+	type raycaster interface {
+		RaycastWith(ray Ray3, opts RaycastOptions) (RaycastResult, bool)
+	}
+	twoSided := RaycastOptions{TwoSided: true}
+	exit := RaycastOptions{Exit: true}
+	tri := Tri3{A: Point3{-1, 0, -1}, B: Point3{0, 0, 1}, C: Point3{1, 0, -1}}
+	up := Ray3{Origin: Point3{0, -2, 0}, Direction: Y3}
+
+	testCases := []struct {
+		name   string
+		shape  raycaster
+		ray    Ray3
+		opts   RaycastOptions
+		hit    bool
+		fail   RaycastFail
+		dist   float32
+		normal Vec3
+	}{
+		{name: "plane-back", shape: &Plane3{Normal: Y3}, ray: up, fail: RaycastFailPlaneFacesAwayFromRay},
+		{name: "plane-back-two-sided", shape: &Plane3{Normal: Y3}, ray: up, opts: twoSided, hit: true, dist: 2, normal: Y3},
+		{name: "plane-front-two-sided", shape: &Plane3{Normal: Y3, Distance: -3}, ray: up, opts: twoSided, fail: RaycastFailTargetBehindRayOrigin},
+		{name: "plane-parallel-two-sided", shape: &Plane3{Normal: Y3}, ray: Ray3{Direction: X3}, opts: twoSided, fail: RaycastFailPlaneFacesAwayFromRay},
+		{name: "tri-back", shape: tri, ray: up, fail: RaycastFailPlaneFacesAwayFromRay},
+		{name: "tri-back-two-sided", shape: tri, ray: up, opts: twoSided, hit: true, dist: 2, normal: Y3},
+		{name: "mesh-inside", shape: boxMesh(AABB{Size: Vec3{1, 1, 1}}), ray: Ray3{Direction: X3}, fail: RaycastFailOutsideBounds},
+		{name: "mesh-inside-two-sided", shape: boxMesh(AABB{Size: Vec3{1, 1, 1}}), ray: Ray3{Direction: X3}, opts: twoSided, hit: true, dist: 1, normal: X3},
+		{name: "aabb-exit", shape: &AABB{Size: Vec3{1, 1, 1}}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, opts: exit, hit: true, dist: 6, normal: X3},
+		{name: "obb-exit", shape: &OBB{Size: Vec3{1, 1, 1}, Orientation: mgl32.QuatRotate(pi/2, Y3)}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, opts: exit, hit: true, dist: 6, normal: X3},
+		{name: "sphere-exit", shape: &Sphere{Radius: 1}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, opts: exit, hit: true, dist: 6, normal: X3},
+		{name: "sphere-behind", shape: &Sphere{Radius: 1}, ray: Ray3{Origin: Point3{5, 0, 0}, Direction: X3}, fail: RaycastFailTargetBehindRayOrigin},
+		{name: "sphere-behind-exit", shape: &Sphere{Radius: 1}, ray: Ray3{Origin: Point3{5, 0, 0}, Direction: X3}, opts: exit, fail: RaycastFailTargetBehindRayOrigin},
+		{name: "capsule-exit", shape: &Capsule{Start: Point3{0, -1, 0}, End: Point3{0, 1, 0}, Radius: 1}, ray: Ray3{Origin: Point3{0, 5, 0}, Direction: Vec3{0, -1, 0}}, opts: exit, hit: true, dist: 7, normal: Vec3{0, -1, 0}},
+		{name: "ellipsoid-exit", shape: &Ellipsoid{Radii: Vec3{2, 1, 1}, Orientation: mgl32.QuatIdent()}, ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, opts: exit, hit: true, dist: 7, normal: X3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := tc.shape.RaycastWith(tc.ray, tc.opts)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v [fail=%v]", ok, tc.hit, res.Fail)
+			}
+			if !ok {
+				if res.Fail != tc.fail {
+					t.Errorf("got fail %v, wanted %v", res.Fail, tc.fail)
+				}
+				return
+			}
+			if abs(res.Distance-tc.dist) > 1e-5 {
+				t.Errorf("got distance %v, wanted %v", res.Distance, tc.dist)
+			}
+			if !nearVec3(res.Normal, tc.normal, 1e-5) {
+				t.Errorf("got normal %v, wanted %v", res.Normal, tc.normal)
+			}
+		})
+	}
+}
+
+func TestRaycastResultThickness(t *testing.T) {
+	s := &Sphere{Radius: 2}
+	testCases := []struct {
+		name string
+		ray  Ray3
+		want float32
+	}{
+		{name: "through-centre", ray: Ray3{Origin: Point3{-5, 0, 0}, Direction: X3}, want: 4},
+		{name: "off-centre", ray: Ray3{Origin: Point3{-5, 0, sqrt(3)}, Direction: X3}, want: 2},
+		{name: "inside", ray: Ray3{Origin: Point3{1, 0, 0}, Direction: X3}, want: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := s.Raycast(tc.ray)
+			if !ok {
+				t.Fatalf("got miss [fail=%v], wanted hit", res.Fail)
+			}
+			if got := res.Thickness(); abs(got-tc.want) > 1e-5 {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+	if got := (RaycastResult{Distance: 3}).Thickness(); got != 0 {
+		t.Errorf("surface hit: got %v, wanted 0", got)
+	}
+}
@@ -0,0 +1,419 @@
+package geom
+
+import (
+	"container/heap"
+	"math"
+)
+
+// SimplifyOptions controls how Simplify reduces a mesh. At least one of TargetTriangles and
+// MaxError should be set; when neither is, the mesh is simplified as far as it can be.
+type SimplifyOptions struct {
+	// TargetTriangles stops simplification once the mesh has no more than this many
+	// triangles. Zero means no limit.
+	TargetTriangles int
+
+	// MaxError stops simplification once the cheapest remaining edge collapse has a quadric
+	// error greater than this. The error of a collapse is the sum of the squared distances from
+	// the new vertex to the planes of the original triangles around it, so a value of d*d
+	// roughly limits how far the surface may move to d. Zero means no limit.
+	MaxError float32
+}
+
+// Simplify returns a simplified copy of the mesh made by repeatedly collapsing the edge whose
+// removal changes the shape least, as measured by the quadric error metric of Garland and
+// Heckbert. Collapses that would fold a triangle over or make the mesh non-manifold are not
+// made, and the edges of open boundaries are held in place so that holes and borders keep
+// their shape. Normals are not carried over to the result.
+func Simplify(m *TriMesh, opts SimplifyOptions) *TriMesh {
+	s := newSimplifier(m)
+	s.run(opts)
+	return s.mesh()
+}
+
+// quadric is a symmetric 4x4 matrix stored as its upper triangle: the sum of the outer
+// products of plane equations [a b c d] with a*x + b*y + c*z + d = 0.
+type quadric [10]float64
+
+func planeQuadric(n [3]float64, d, w float64) quadric {
+	a, b, c := n[0], n[1], n[2]
+	return quadric{
+		w * a * a, w * a * b, w * a * c, w * a * d,
+		w * b * b, w * b * c, w * b * d,
+		w * c * c, w * c * d,
+		w * d * d,
+	}
+}
+
+func (q *quadric) add(r *quadric) {
+	for i := range q {
+		q[i] += r[i]
+	}
+}
+
+// eval returns the sum of squared distances from v to the planes in the quadric.
+func (q *quadric) eval(v [3]float64) float64 {
+	x, y, z := v[0], v[1], v[2]
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+// optimum returns the point that minimises the quadric, or false when the quadric does not
+// have a unique minimum, such as for a flat or straight region.
+func (q *quadric) optimum() ([3]float64, bool) {
+	a := [3][3]float64{
+		{q[0], q[1], q[2]},
+		{q[1], q[4], q[5]},
+		{q[2], q[5], q[7]},
+	}
+	b := [3]float64{-q[3], -q[6], -q[8]}
+	det := a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+		a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+		a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+	scale := math.Abs(a[0][0]) + math.Abs(a[1][1]) + math.Abs(a[2][2])
+	if scale == 0 || math.Abs(det) < 1e-9*scale*scale*scale {
+		return [3]float64{}, false
+	}
+
+	// Cramer's rule
+	var v [3]float64
+	for i := 0; i < 3; i++ {
+		m := a
+		for r := 0; r < 3; r++ {
+			m[r][i] = b[r]
+		}
+		v[i] = (m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])) / det
+	}
+	return v, true
+}
+
+// simplifyBoundaryWeight scales the planes added along open edges to hold them in place.
+const simplifyBoundaryWeight = 1000
+
+type simplifier struct {
+	pos     [][3]float64
+	quad    []quadric
+	alive   []bool
+	version []int
+	vfaces  [][]int // faces around each vertex, which may include dead faces
+	mark    []int   // scratch marks for visiting vertices, set to stamp when visited
+	stamp   int
+
+	faces     [][3]int
+	faceAlive []bool
+	live      int
+
+	heap collapseHeap
+}
+
+type collapse struct {
+	cost   float64
+	a, b   int
+	va, vb int // versions of a and b when the collapse was evaluated
+	pos    [3]float64
+}
+
+type collapseHeap []collapse
+
+func (h collapseHeap) Len() int           { return len(h) }
+func (h collapseHeap) Less(i, j int) bool { return h[i].cost < h[j].cost }
+func (h collapseHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *collapseHeap) Push(x any)        { *h = append(*h, x.(collapse)) }
+func (h *collapseHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func newSimplifier(m *TriMesh) *simplifier {
+	nv := len(m.Positions)
+	s := &simplifier{
+		pos:     make([][3]float64, nv),
+		quad:    make([]quadric, nv),
+		alive:   make([]bool, nv),
+		version: make([]int, nv),
+		vfaces:  make([][]int, nv),
+		mark:    make([]int, nv),
+	}
+	for i, p := range m.Positions {
+		s.pos[i] = [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+	}
+
+	edgeFaces := make(map[[2]int]int)
+	for i := 0; i < m.Len(); i++ {
+		f := [3]int{int(m.Indices[3*i]), int(m.Indices[3*i+1]), int(m.Indices[3*i+2])}
+		if f[0] == f[1] || f[1] == f[2] || f[2] == f[0] {
+			continue
+		}
+		n, ok := s.faceNormal(f, -1, [3]float64{})
+		if !ok {
+			continue
+		}
+		fi := len(s.faces)
+		s.faces = append(s.faces, f)
+		s.faceAlive = append(s.faceAlive, true)
+		s.live++
+
+		q := planeQuadric(n, -dot64(n, s.pos[f[0]]), 1)
+		for k, v := range f {
+			s.alive[v] = true
+			s.quad[v].add(&q)
+			s.vfaces[v] = append(s.vfaces[v], fi)
+			edgeFaces[edgeKey(v, f[(k+1)%3])]++
+		}
+	}
+
+	// Hold open edges in place with planes through the edge perpendicular to the face
+	for _, f := range s.faces {
+		for k := 0; k < 3; k++ {
+			a, b := f[k], f[(k+1)%3]
+			if edgeFaces[edgeKey(a, b)] != 1 {
+				continue
+			}
+			n, _ := s.faceNormal(f, -1, [3]float64{})
+			e := sub64(s.pos[b], s.pos[a])
+			p := cross64(e, n)
+			l := math.Sqrt(dot64(p, p))
+			if l == 0 {
+				continue
+			}
+			p = [3]float64{p[0] / l, p[1] / l, p[2] / l}
+			q := planeQuadric(p, -dot64(p, s.pos[a]), simplifyBoundaryWeight)
+			s.quad[a].add(&q)
+			s.quad[b].add(&q)
+		}
+	}
+
+	// Push the edges in the order of the faces so the result does not depend on map order
+	for _, f := range s.faces {
+		for k := 0; k < 3; k++ {
+			e := edgeKey(f[k], f[(k+1)%3])
+			if edgeFaces[e] > 0 {
+				edgeFaces[e] = 0
+				s.push(e[0], e[1])
+			}
+		}
+	}
+	return s
+}
+
+func edgeKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// faceNormal returns the unit normal of face f, with vertex moved placed at p when moved is
+// not -1. It returns false when the face is degenerate.
+func (s *simplifier) faceNormal(f [3]int, moved int, p [3]float64) ([3]float64, bool) {
+	var c [3][3]float64
+	for i, v := range f {
+		c[i] = s.pos[v]
+		if v == moved {
+			c[i] = p
+		}
+	}
+	n := cross64(sub64(c[1], c[0]), sub64(c[2], c[0]))
+	l := math.Sqrt(dot64(n, n))
+	if l == 0 {
+		return n, false
+	}
+	return [3]float64{n[0] / l, n[1] / l, n[2] / l}, true
+}
+
+// push evaluates the collapse of the edge between a and b and adds it to the heap.
+func (s *simplifier) push(a, b int) {
+	q := s.quad[a]
+	q.add(&s.quad[b])
+
+	best, ok := q.optimum()
+	cost := math.Inf(1)
+	if ok {
+		cost = q.eval(best)
+	} else {
+		// Fall back to the best of the midpoint and the ends, preferring the midpoint so that
+		// flat regions shrink evenly
+		mid := [3]float64{
+			(s.pos[a][0] + s.pos[b][0]) / 2,
+			(s.pos[a][1] + s.pos[b][1]) / 2,
+			(s.pos[a][2] + s.pos[b][2]) / 2,
+		}
+		for _, p := range [3][3]float64{mid, s.pos[a], s.pos[b]} {
+			if c := q.eval(p); c < cost {
+				best, cost = p, c
+			}
+		}
+	}
+	heap.Push(&s.heap, collapse{cost: math.Max(cost, 0), a: a, b: b, va: s.version[a], vb: s.version[b], pos: best})
+}
+
+func (s *simplifier) run(opts SimplifyOptions) {
+	for s.heap.Len() > 0 {
+		if opts.TargetTriangles > 0 && s.live <= opts.TargetTriangles {
+			return
+		}
+		c := heap.Pop(&s.heap).(collapse)
+		if !s.alive[c.a] || !s.alive[c.b] || s.version[c.a] != c.va || s.version[c.b] != c.vb {
+			// The neighbourhood has changed since the collapse was evaluated
+			continue
+		}
+		if opts.MaxError > 0 && c.cost > float64(opts.MaxError) {
+			return
+		}
+		s.collapse(c)
+	}
+}
+
+// collapse merges vertex b into vertex a, moving a to the collapse position, if doing so keeps
+// the mesh manifold and does not fold any triangle over.
+func (s *simplifier) collapse(c collapse) {
+	a, b := c.a, c.b
+
+	fa, fb := s.liveFaces(a), s.liveFaces(b)
+
+	// The link condition: the only vertices joined to both a and b must be the third corners
+	// of the faces that share the edge
+	shared := 0
+	s.stamp++
+	for _, fi := range fa {
+		f := s.faces[fi]
+		if f[0] == b || f[1] == b || f[2] == b {
+			shared++
+		}
+		for _, v := range f {
+			s.mark[v] = s.stamp
+		}
+	}
+	common := 0
+	for _, fi := range fb {
+		for _, v := range s.faces[fi] {
+			if v != a && v != b && s.mark[v] == s.stamp {
+				// Mark each common vertex once
+				s.mark[v] = -s.stamp
+				common++
+			}
+		}
+	}
+	if shared == 0 || common != shared {
+		return
+	}
+
+	// Reject the collapse if a surviving face would flip
+	if !s.keepsOrientation(fa, a, b, c.pos) || !s.keepsOrientation(fb, b, a, c.pos) {
+		return
+	}
+
+	// Remove the faces on the edge and move b's other faces to a
+	faces := fa[:0]
+	for _, fi := range fa {
+		f := s.faces[fi]
+		if f[0] == b || f[1] == b || f[2] == b {
+			s.faceAlive[fi] = false
+			s.live--
+			continue
+		}
+		faces = append(faces, fi)
+	}
+	for _, fi := range fb {
+		if !s.faceAlive[fi] {
+			continue
+		}
+		f := &s.faces[fi]
+		for k := range f {
+			if f[k] == b {
+				f[k] = a
+			}
+		}
+		faces = append(faces, fi)
+	}
+	s.vfaces[a] = faces
+	s.vfaces[b] = nil
+	s.alive[b] = false
+	s.pos[a] = c.pos
+	s.quad[a].add(&s.quad[b])
+	s.version[a]++
+
+	// Re-evaluate the edges around a
+	s.stamp++
+	for _, fi := range faces {
+		for _, v := range s.faces[fi] {
+			if v != a && s.mark[v] != s.stamp {
+				s.mark[v] = s.stamp
+				s.push(a, v)
+			}
+		}
+	}
+}
+
+// liveFaces removes dead faces from the list of faces around v and returns it.
+func (s *simplifier) liveFaces(v int) []int {
+	faces := s.vfaces[v][:0]
+	for _, fi := range s.vfaces[v] {
+		if s.faceAlive[fi] {
+			faces = append(faces, fi)
+		}
+	}
+	s.vfaces[v] = faces
+	return faces
+}
+
+// keepsOrientation reports whether moving v to p leaves each of the faces, other than those
+// shared with other, facing close to its original direction.
+func (s *simplifier) keepsOrientation(faces []int, v, other int, p [3]float64) bool {
+	for _, fi := range faces {
+		f := s.faces[fi]
+		if f[0] == other || f[1] == other || f[2] == other {
+			continue
+		}
+		before, _ := s.faceNormal(f, -1, [3]float64{})
+		after, ok := s.faceNormal(f, v, p)
+		if !ok || dot64(before, after) < 0.2 {
+			return false
+		}
+	}
+	return true
+}
+
+// mesh returns the remaining faces as a new mesh, dropping unused vertices.
+func (s *simplifier) mesh() *TriMesh {
+	out := &TriMesh{}
+	remap := make([]int, len(s.pos))
+	for i := range remap {
+		remap[i] = -1
+	}
+	for fi, f := range s.faces {
+		if !s.faceAlive[fi] {
+			continue
+		}
+		for _, v := range f {
+			if remap[v] < 0 {
+				remap[v] = len(out.Positions)
+				p := s.pos[v]
+				out.Positions = append(out.Positions, Point3{float32(p[0]), float32(p[1]), float32(p[2])})
+			}
+			out.Indices = append(out.Indices, uint32(remap[v]))
+		}
+	}
+	return out
+}
+
+func dot64(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func sub64(a, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func cross64(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
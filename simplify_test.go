@@ -0,0 +1,147 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+// subdivideMesh splits each triangle of the mesh into four, sharing the new vertices between
+// neighbouring triangles.
+func subdivideMesh(m *TriMesh) *TriMesh {
+	out := &TriMesh{Positions: append([]Point3(nil), m.Positions...)}
+	mids := make(map[[2]uint32]uint32)
+	mid := func(a, b uint32) uint32 {
+		k := [2]uint32{a, b}
+		if a > b {
+			k = [2]uint32{b, a}
+		}
+		if i, ok := mids[k]; ok {
+			return i
+		}
+		i := uint32(len(out.Positions))
+		out.Positions = append(out.Positions, out.Positions[a].Add(out.Positions[b]).Mul(0.5))
+		mids[k] = i
+		return i
+	}
+	for i := 0; i < m.Len(); i++ {
+		a, b, c := m.Indices[3*i], m.Indices[3*i+1], m.Indices[3*i+2]
+		ab, bc, ca := mid(a, b), mid(b, c), mid(c, a)
+		out.Indices = append(out.Indices, a, ab, ca, ab, b, bc, ca, bc, c, ab, bc, ca)
+	}
+	return out
+}
+
+func meshArea(m *TriMesh) float32 {
+	var area float32
+	for i := 0; i < m.Len(); i++ {
+		area += m.Tri(i).Area()
+	}
+	return area
+}
+
+func TestSimplify(t *testing.T) {
+	box := AABB{Position: Point3{1, -2, 0.5}, Size: Vec3{2, 1, 3}}
+	square := &TriMesh{
+		Positions: []Point3{{0, 0, 0}, {4, 0, 0}, {4, 4, 0}, {0, 4, 0}},
+		Indices:   []uint32{0, 1, 2, 0, 2, 3},
+	}
+
+	testCases := []struct {
+		name   string
+		mesh   *TriMesh
+		opts   SimplifyOptions
+		want   int // the most triangles expected
+		closed bool
+	}{
+		{
+			name:   "box to target",
+			mesh:   subdivideMesh(subdivideMesh(boxMesh(box))),
+			opts:   SimplifyOptions{TargetTriangles: 12},
+			want:   12,
+			closed: true,
+		},
+		{
+			name:   "box to error",
+			mesh:   subdivideMesh(subdivideMesh(boxMesh(box))),
+			opts:   SimplifyOptions{MaxError: 1e-6},
+			want:   12,
+			closed: true,
+		},
+		{
+			name: "open square",
+			mesh: subdivideMesh(subdivideMesh(subdivideMesh(square))),
+			opts: SimplifyOptions{TargetTriangles: 2},
+			want: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Simplify(tc.mesh, tc.opts)
+			if got.Len() > tc.want || got.Len() == 0 {
+				t.Fatalf("got %d triangles, wanted at most %d", got.Len(), tc.want)
+			}
+			if gb, wb := got.Bounds(), tc.mesh.Bounds(); !nearVec3(gb.Min(), wb.Min(), 1e-4) || !nearVec3(gb.Max(), wb.Max(), 1e-4) {
+				t.Errorf("bounds: got %v, wanted %v", gb, wb)
+			}
+			if ga, wa := meshArea(got), meshArea(tc.mesh); math.Abs(float64(ga-wa)) > 1e-3*float64(wa) {
+				t.Errorf("area: got %v, wanted %v", ga, wa)
+			}
+
+			edges := make(map[[2]uint32]int)
+			for i := 0; i < got.Len(); i++ {
+				for k := 0; k < 3; k++ {
+					edges[[2]uint32{got.Indices[3*i+k], got.Indices[3*i+(k+1)%3]}]++
+				}
+			}
+			for e, n := range edges {
+				if n != 1 {
+					t.Fatalf("edge %v used %d times in the same direction", e, n)
+				}
+				if _, ok := edges[[2]uint32{e[1], e[0]}]; tc.closed && !ok {
+					t.Fatalf("edge %v is open", e)
+				}
+			}
+		})
+	}
+}
+
+func TestSimplifyMaxError(t *testing.T) {
+	// A curved surface cannot be simplified without moving it
+	m := &TriMesh{}
+	const n = 16
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			fx, fy := float32(x)/n, float32(y)/n
+			m.Positions = append(m.Positions, Point3{fx, fy, fx * fx})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(x + (n+1)*y)
+			m.Indices = append(m.Indices, i, i+1, i+n+2, i, i+n+2, i+n+1)
+		}
+	}
+
+	fine := Simplify(m, SimplifyOptions{MaxError: 1e-9})
+	coarse := Simplify(m, SimplifyOptions{MaxError: 1e-4})
+	if fine.Len() >= m.Len() || coarse.Len() >= fine.Len() {
+		t.Errorf("got %d triangles at a small error and %d at a large one from %d", fine.Len(), coarse.Len(), m.Len())
+	}
+	for i := 0; i < coarse.Len(); i++ {
+		for _, p := range []Point3{coarse.Tri(i).A, coarse.Tri(i).B, coarse.Tri(i).C} {
+			if d := p[2] - p[0]*p[0]; d > 0.02 || d < -0.02 {
+				t.Fatalf("vertex %v is %v from the surface", p, d)
+			}
+		}
+	}
+}
+
+func BenchmarkSimplify(b *testing.B) {
+	m := subdivideMesh(subdivideMesh(subdivideMesh(subdivideMesh(boxMesh(AABB{Size: Vec3{1, 1, 1}})))))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Simplify(m, SimplifyOptions{TargetTriangles: 100})
+	}
+}
@@ -0,0 +1,342 @@
+package geom
+
+import "math"
+
+// Inset returns the regions left after moving every edge of the polygon, including the edges
+// of its holes, inward by d. Unlike offsetting each vertex along its bisector, the inset is
+// found by following the straight skeleton of the polygon, so edges that shrink to nothing are
+// removed and the polygon splits into separate pieces where opposite sides meet. Pieces that
+// shrink away entirely are omitted, so the result is empty when d is at least the distance from
+// the boundary to the furthest point inside the polygon. The boundary of each piece is counter
+// clockwise and the boundaries of its holes are clockwise.
+//
+// The polygon must be simple and its holes must lie inside it. d must not be negative; use
+// Inflate to grow a polygon. The cost grows with the cube of the number of points in the worst
+// case, which is intended for footprints and outlines rather than large polygons.
+func (p *Polygon2) Inset(d float32) []Polygon2 {
+	if d < 0 {
+		panic("geom: inset distance must not be negative")
+	}
+	s := newSkeleton(p)
+	if s == nil {
+		return nil
+	}
+	s.run(float64(d))
+	return s.polygons()
+}
+
+// StraightSkeleton returns the straight skeleton of the polygon as a list of segments. Each
+// segment is part of the path traced by a corner of the polygon as its edges move inward at
+// the same speed; together they divide the polygon into one region for each edge, which is the
+// plan view of a roof with the same pitch on every side. The polygon must be simple and its
+// holes must lie inside it.
+func (p *Polygon2) StraightSkeleton() []Line2 {
+	s := newSkeleton(p)
+	if s == nil {
+		return nil
+	}
+	s.run(math.Inf(1))
+	return s.arcs
+}
+
+func (a vec2d) near(b vec2d, e float64) bool {
+	d := a.sub(b)
+	return d.dot(d) <= e*e
+}
+
+// skelEdge is the line supporting an edge of the wavefront. At time t the line holds the
+// points x with normal·x = offset + t.
+type skelEdge struct {
+	dir    vec2d
+	normal vec2d // points into the polygon
+	offset float64
+}
+
+// skelVertex is a corner of the wavefront, joining the edge in to the edge out.
+type skelVertex struct {
+	pos        vec2d // position at the current time
+	start      vec2d // where the vertex began moving
+	vel        vec2d
+	in, out    *skelEdge
+	prev, next *skelVertex
+	alive      bool
+}
+
+// skeleton follows the wavefront of a polygon as its edges move inward at unit speed. Each
+// boundary of the polygon is a ring of vertices, oriented with the inside of the polygon on
+// its left.
+type skeleton struct {
+	verts []*skelVertex
+	time  float64
+	eps   float64
+	arcs  []Line2
+}
+
+func newSkeleton(p *Polygon2) *skeleton {
+	s := &skeleton{}
+	var extent float32 = 1
+	for _, q := range p.Points {
+		extent = max(extent, max(abs(q[0]), abs(q[1])))
+	}
+	s.eps = 1e-6 * float64(extent)
+
+	outer := ringSignedArea(p.Points) > 0
+	s.addRing(p.Points, !outer)
+	for _, h := range p.Holes {
+		s.addRing(h, ringSignedArea(h) > 0)
+	}
+	if len(s.verts) == 0 {
+		return nil
+	}
+	return s
+}
+
+// addRing adds a boundary of the polygon to the wavefront, reversing it if needed so that the
+// inside of the polygon lies on its left.
+func (s *skeleton) addRing(pts []Point2, reverse bool) {
+	ring := make([]vec2d, 0, len(pts))
+	for i := range pts {
+		j := i
+		if reverse {
+			j = len(pts) - 1 - i
+		}
+		q := vec2dFromPoint(pts[j])
+		if len(ring) > 0 && ring[len(ring)-1].near(q, s.eps) {
+			continue
+		}
+		ring = append(ring, q)
+	}
+	for len(ring) > 1 && ring[0].near(ring[len(ring)-1], s.eps) {
+		ring = ring[:len(ring)-1]
+	}
+	if len(ring) < 3 {
+		return
+	}
+
+	n := len(ring)
+	edges := make([]*skelEdge, n)
+	for i := range ring {
+		edges[i] = newSkelEdge(ring[i], ring[(i+1)%n], s.time)
+	}
+	vs := make([]*skelVertex, n)
+	for i := range ring {
+		vs[i] = &skelVertex{pos: ring[i], start: ring[i], in: edges[(i+n-1)%n], out: edges[i], alive: true}
+	}
+	for i, v := range vs {
+		v.prev = vs[(i+n-1)%n]
+		v.next = vs[(i+1)%n]
+		s.setVelocity(v)
+	}
+	s.verts = append(s.verts, vs...)
+}
+
+func newSkelEdge(a, b vec2d, t float64) *skelEdge {
+	d := b.sub(a)
+	d = d.mul(1 / math.Sqrt(d.dot(d)))
+	n := vec2d{-d[1], d[0]}
+	return &skelEdge{dir: d, normal: n, offset: n.dot(a) - t}
+}
+
+// setVelocity sets the velocity of a vertex so that it stays on both of its edges as they move.
+func (s *skeleton) setVelocity(v *skelVertex) {
+	n1, n2 := v.in.normal, v.out.normal
+	det := n1.cross(n2)
+	if math.Abs(det) < 1e-12 {
+		// The edges are parallel, so the vertex moves with them
+		v.vel = n1
+		return
+	}
+	// Solve n1·vel = 1 and n2·vel = 1
+	v.vel = vec2d{(n2[1] - n1[1]) / det, (n1[0] - n2[0]) / det}
+}
+
+// reflex reports whether the wavefront turns right at v, away from the inside.
+func (v *skelVertex) reflex() bool {
+	return v.in.dir.cross(v.out.dir) < -1e-9
+}
+
+// skelEvent is a change to the wavefront: either the edge leaving v shrinks to nothing, or the
+// reflex vertex v reaches the edge leaving other and splits the wavefront.
+type skelEvent struct {
+	dt    float64
+	v     *skelVertex
+	other *skelVertex // nil for an edge event
+}
+
+// nextEvent returns the earliest event, or false if the wavefront will not change again.
+func (s *skeleton) nextEvent() (skelEvent, bool) {
+	best := skelEvent{dt: math.Inf(1)}
+	found := false
+	for _, u := range s.verts {
+		if !u.alive {
+			continue
+		}
+
+		// Edge event: the edge from u to its successor shrinks to nothing
+		w := u.next
+		l := w.pos.sub(u.pos).dot(u.out.dir)
+		rate := w.vel.sub(u.vel).dot(u.out.dir)
+		if l <= s.eps || rate < 0 {
+			dt := 0.0
+			if l > s.eps {
+				dt = -l / rate
+			}
+			if dt < best.dt {
+				best, found = skelEvent{dt: dt, v: u}, true
+			}
+		}
+
+		if !u.reflex() {
+			continue
+		}
+
+		// Split event: u reaches an edge that is not next to it
+		for _, a := range s.verts {
+			if !a.alive || a == u || a.next == u || a.out == u.in || a.out == u.out {
+				continue
+			}
+			e := a.out
+			dist := e.normal.dot(u.pos) - (e.offset + s.time)
+			closing := 1 - e.normal.dot(u.vel)
+			if dist < -s.eps || closing <= 1e-9 {
+				continue
+			}
+			dt := math.Max(dist, 0) / closing
+			if dt >= best.dt {
+				continue
+			}
+			pa := a.pos.add(a.vel.mul(dt))
+			pb := a.next.pos.add(a.next.vel.mul(dt))
+			q := u.pos.add(u.vel.mul(dt))
+			along := q.sub(pa).dot(e.dir)
+			if along < -s.eps || along > pb.sub(pa).dot(e.dir)+s.eps {
+				continue
+			}
+			best, found = skelEvent{dt: dt, v: u, other: a}, true
+		}
+	}
+	return best, found
+}
+
+// run moves the wavefront forward until the given time or until it vanishes.
+func (s *skeleton) run(until float64) {
+	// Each event removes a vertex or a reflex corner, so a polygon with n points has at most a
+	// small multiple of n events. The limit guards against cycling on degenerate input.
+	limit := 4*len(s.verts) + 16
+	for i := 0; i < limit; i++ {
+		ev, ok := s.nextEvent()
+		if !ok || s.time+ev.dt > until {
+			if !math.IsInf(until, 1) {
+				s.advance(until - s.time)
+			}
+			break
+		}
+		s.advance(ev.dt)
+		if ev.other == nil {
+			s.edgeEvent(ev.v)
+		} else {
+			s.splitEvent(ev.v, ev.other)
+		}
+		s.removeSlivers()
+	}
+	if math.IsInf(until, 1) {
+		// Record the paths of any vertices left by degenerate input
+		for _, v := range s.verts {
+			if v.alive {
+				s.kill(v, v.pos)
+			}
+		}
+	}
+}
+
+func (s *skeleton) advance(dt float64) {
+	for _, v := range s.verts {
+		if v.alive {
+			v.pos = v.pos.add(v.vel.mul(dt))
+		}
+	}
+	s.time += dt
+}
+
+// kill removes a vertex from the wavefront, recording its path to end.
+func (s *skeleton) kill(v *skelVertex, end vec2d) {
+	v.alive = false
+	if !v.start.near(end, s.eps) {
+		s.arcs = append(s.arcs, Line2{Start: v.start.point(), End: end.point()})
+	}
+}
+
+func (s *skeleton) newVertex(pos vec2d, in, out *skelEdge, prev, next *skelVertex) *skelVertex {
+	v := &skelVertex{pos: pos, start: pos, in: in, out: out, prev: prev, next: next, alive: true}
+	prev.next = v
+	next.prev = v
+	s.setVelocity(v)
+	s.verts = append(s.verts, v)
+	return v
+}
+
+// edgeEvent replaces u and its successor, which have met, with a single vertex.
+func (s *skeleton) edgeEvent(u *skelVertex) {
+	w := u.next
+	m := u.pos.add(w.pos).mul(0.5)
+	s.kill(u, m)
+	s.kill(w, m)
+	if u.prev == w.next {
+		// The last three vertices of a ring meet at a point
+		s.kill(u.prev, m)
+		return
+	}
+	s.newVertex(m, u.in, w.out, u.prev, w.next)
+}
+
+// splitEvent divides the wavefront where the reflex vertex v reaches the edge leaving a. If a
+// is in the same ring as v the ring splits in two, otherwise the two rings join into one.
+func (s *skeleton) splitEvent(v, a *skelVertex) {
+	q := v.pos
+	prev, next, b := v.prev, v.next, a.next
+	s.kill(v, q)
+	s.newVertex(q, v.in, a.out, prev, b)
+	s.newVertex(q, a.out, v.out, a, next)
+}
+
+// removeSlivers removes rings that have fewer than three vertices or no area.
+func (s *skeleton) removeSlivers() {
+	for _, v := range s.verts {
+		if !v.alive {
+			continue
+		}
+		if v.next.next == v || v.next == v {
+			end := v.pos.add(v.next.pos).mul(0.5)
+			s.kill(v.next, end)
+			s.kill(v, end)
+		}
+	}
+}
+
+// polygons returns the current wavefront as polygons.
+func (s *skeleton) polygons() []Polygon2 {
+	seen := make(map[*skelVertex]bool)
+	var rings [][]Point2
+	for _, v := range s.verts {
+		if !v.alive || seen[v] {
+			continue
+		}
+		var ring []Point2
+		for u := v; !seen[u]; u = u.next {
+			seen[u] = true
+			pt := u.pos.point()
+			if len(ring) > 0 && ring[len(ring)-1].Sub(pt).Len() <= float32(s.eps) {
+				continue
+			}
+			ring = append(ring, pt)
+		}
+		if len(ring) > 1 && ring[0].Sub(ring[len(ring)-1]).Len() <= float32(s.eps) {
+			ring = ring[:len(ring)-1]
+		}
+		if len(ring) < 3 || abs(ringSignedArea(ring)) <= float32(s.eps*s.eps) {
+			continue
+		}
+		rings = append(rings, ring)
+	}
+	return polygonsFromRings(rings)
+}
@@ -0,0 +1,133 @@
+package geom
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestPolygon2Inset(t *testing.T) {
+	// Two 4x4 squares joined by a corridor 1 unit wide
+	dumbbell := Polygon2{Points: []Point2{
+		{0, 0}, {4, 0}, {4, 1.5}, {6, 1.5}, {6, 0}, {10, 0},
+		{10, 4}, {6, 4}, {6, 2.5}, {4, 2.5}, {4, 4}, {0, 4},
+	}}
+
+	testCases := []struct {
+		name  string
+		p     Polygon2
+		d     float32
+		areas []float32 // of each piece, smallest first
+		holes int
+	}{
+		{
+			name:  "square",
+			p:     Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 4}, {0, 4}}},
+			d:     1,
+			areas: []float32{4},
+		},
+		{
+			name:  "clockwise square",
+			p:     Polygon2{Points: []Point2{{0, 0}, {0, 4}, {4, 4}, {4, 0}}},
+			d:     1,
+			areas: []float32{4},
+		},
+		{
+			name: "square gone",
+			p:    Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 4}, {0, 4}}},
+			d:    2.5,
+		},
+		{
+			name:  "l shape",
+			p:     Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 4}, {0, 4}}},
+			d:     0.5,
+			areas: []float32{3 + 3 - 1},
+		},
+		{
+			name:  "dumbbell before split",
+			p:     dumbbell,
+			d:     0.25,
+			areas: []float32{3.5*3.5*2 + 2.5*0.5},
+		},
+		{
+			name:  "dumbbell split",
+			p:     dumbbell,
+			d:     1,
+			areas: []float32{4, 4},
+		},
+		{
+			name: "square with hole",
+			p: Polygon2{
+				Points: []Point2{{0, 0}, {8, 0}, {8, 8}, {0, 8}},
+				Holes:  [][]Point2{{{3, 3}, {3, 5}, {5, 5}, {5, 3}}},
+			},
+			d:     1,
+			areas: []float32{6*6 - 4*4},
+			holes: 1,
+		},
+		{
+			name: "hole meets boundary",
+			p: Polygon2{
+				Points: []Point2{{0, 0}, {8, 0}, {8, 8}, {0, 8}},
+				Holes:  [][]Point2{{{2, 3}, {2, 5}, {6, 5}, {6, 3}}},
+			},
+			d:     1.25,
+			areas: []float32{5.5 * 0.5, 5.5 * 0.5},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.p.Inset(tc.d)
+			if len(got) != len(tc.areas) {
+				t.Fatalf("got %d pieces, wanted %d: %v", len(got), len(tc.areas), got)
+			}
+			areas := make([]float32, len(got))
+			holes := 0
+			for i := range got {
+				if a := ringSignedArea(got[i].Points); a <= 0 {
+					t.Errorf("piece %d is not counter clockwise", i)
+				}
+				for _, pt := range got[i].Points {
+					if !tc.p.ContainsPoint2(pt) {
+						t.Errorf("point %v is outside the polygon", pt)
+					}
+				}
+				areas[i] = got[i].Area()
+				holes += len(got[i].Holes)
+			}
+			sort.Slice(areas, func(i, j int) bool { return areas[i] < areas[j] })
+			for i := range areas {
+				if math.Abs(float64(areas[i]-tc.areas[i])) > 1e-3 {
+					t.Errorf("piece %d: got area %v, wanted %v", i, areas[i], tc.areas[i])
+				}
+			}
+			if holes != tc.holes {
+				t.Errorf("got %d holes, wanted %d", holes, tc.holes)
+			}
+		})
+	}
+}
+
+func TestPolygon2StraightSkeleton(t *testing.T) {
+	// A hip roof: each corner rises to the ends of a ridge along the middle
+	p := Polygon2{Points: []Point2{{0, 0}, {4, 0}, {4, 2}, {0, 2}}}
+	arcs := p.StraightSkeleton()
+
+	var total float32
+	for _, a := range arcs {
+		total += a.End.Sub(a.Start).Len()
+	}
+	want := 4*float32(math.Sqrt2) + 2
+	if abs(total-want) > 1e-4 {
+		t.Errorf("got total length %v, wanted %v: %v", total, want, arcs)
+	}
+
+	ends := map[Point2]bool{}
+	for _, a := range arcs {
+		ends[a.End] = true
+	}
+	if !ends[Point2{1, 1}] && !ends[Point2{3, 1}] {
+		t.Errorf("no arc ends at the ridge: %v", arcs)
+	}
+}
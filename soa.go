@@ -0,0 +1,210 @@
+package geom
+
+// Vec3x4 holds four 3 dimensional vectors in structure of arrays form, with each component
+// stored contiguously. Operations work on all four vectors at once in simple loops that the
+// compiler can keep in registers, which is faster than working on one Vec3 at a time when the
+// same calculation is applied to many vectors.
+type Vec3x4 struct {
+	X, Y, Z [4]float32
+}
+
+// Vec3x4FromVecs returns the vectors in structure of arrays form.
+func Vec3x4FromVecs(v [4]Vec3) Vec3x4 {
+	var r Vec3x4
+	for i := range v {
+		r.X[i], r.Y[i], r.Z[i] = v[i][0], v[i][1], v[i][2]
+	}
+	return r
+}
+
+// SplatVec3x4 returns four copies of v.
+func SplatVec3x4(v Vec3) Vec3x4 {
+	return Vec3x4{
+		X: [4]float32{v[0], v[0], v[0], v[0]},
+		Y: [4]float32{v[1], v[1], v[1], v[1]},
+		Z: [4]float32{v[2], v[2], v[2], v[2]},
+	}
+}
+
+// Vec returns the vector at index i.
+func (v *Vec3x4) Vec(i int) Vec3 {
+	return Vec3{v.X[i], v.Y[i], v.Z[i]}
+}
+
+// Set sets the vector at index i.
+func (v *Vec3x4) Set(i int, u Vec3) {
+	v.X[i], v.Y[i], v.Z[i] = u[0], u[1], u[2]
+}
+
+// Add returns the element-wise sum of the vectors in v and u.
+func (v *Vec3x4) Add(u *Vec3x4) Vec3x4 {
+	var r Vec3x4
+	for i := 0; i < 4; i++ {
+		r.X[i] = v.X[i] + u.X[i]
+		r.Y[i] = v.Y[i] + u.Y[i]
+		r.Z[i] = v.Z[i] + u.Z[i]
+	}
+	return r
+}
+
+// Sub returns the element-wise difference of the vectors in v and u.
+func (v *Vec3x4) Sub(u *Vec3x4) Vec3x4 {
+	var r Vec3x4
+	for i := 0; i < 4; i++ {
+		r.X[i] = v.X[i] - u.X[i]
+		r.Y[i] = v.Y[i] - u.Y[i]
+		r.Z[i] = v.Z[i] - u.Z[i]
+	}
+	return r
+}
+
+// Mul returns the vectors in v scaled by s.
+func (v *Vec3x4) Mul(s float32) Vec3x4 {
+	var r Vec3x4
+	for i := 0; i < 4; i++ {
+		r.X[i] = v.X[i] * s
+		r.Y[i] = v.Y[i] * s
+		r.Z[i] = v.Z[i] * s
+	}
+	return r
+}
+
+// Dot returns the dot product of each vector in v with the vector at the same index in u.
+func (v *Vec3x4) Dot(u *Vec3x4) [4]float32 {
+	var r [4]float32
+	for i := 0; i < 4; i++ {
+		r[i] = v.X[i]*u.X[i] + v.Y[i]*u.Y[i] + v.Z[i]*u.Z[i]
+	}
+	return r
+}
+
+// DotVec3 returns the dot product of each vector in v with u.
+func (v *Vec3x4) DotVec3(u Vec3) [4]float32 {
+	var r [4]float32
+	for i := 0; i < 4; i++ {
+		r[i] = v.X[i]*u[0] + v.Y[i]*u[1] + v.Z[i]*u[2]
+	}
+	return r
+}
+
+// Cross returns the cross product of each vector in v with the vector at the same index in u.
+func (v *Vec3x4) Cross(u *Vec3x4) Vec3x4 {
+	var r Vec3x4
+	for i := 0; i < 4; i++ {
+		r.X[i] = v.Y[i]*u.Z[i] - v.Z[i]*u.Y[i]
+		r.Y[i] = v.Z[i]*u.X[i] - v.X[i]*u.Z[i]
+		r.Z[i] = v.X[i]*u.Y[i] - v.Y[i]*u.X[i]
+	}
+	return r
+}
+
+// Vec3x8 holds eight 3 dimensional vectors in structure of arrays form. It matches the eight
+// corners of a box, and is otherwise the same as Vec3x4.
+type Vec3x8 struct {
+	X, Y, Z [8]float32
+}
+
+// Vec3x8FromVecs returns the vectors in structure of arrays form.
+func Vec3x8FromVecs(v [8]Vec3) Vec3x8 {
+	var r Vec3x8
+	for i := range v {
+		r.X[i], r.Y[i], r.Z[i] = v[i][0], v[i][1], v[i][2]
+	}
+	return r
+}
+
+// SplatVec3x8 returns eight copies of v.
+func SplatVec3x8(v Vec3) Vec3x8 {
+	var r Vec3x8
+	for i := 0; i < 8; i++ {
+		r.X[i], r.Y[i], r.Z[i] = v[0], v[1], v[2]
+	}
+	return r
+}
+
+// Vec returns the vector at index i.
+func (v *Vec3x8) Vec(i int) Vec3 {
+	return Vec3{v.X[i], v.Y[i], v.Z[i]}
+}
+
+// Set sets the vector at index i.
+func (v *Vec3x8) Set(i int, u Vec3) {
+	v.X[i], v.Y[i], v.Z[i] = u[0], u[1], u[2]
+}
+
+// Add returns the element-wise sum of the vectors in v and u.
+func (v *Vec3x8) Add(u *Vec3x8) Vec3x8 {
+	var r Vec3x8
+	for i := 0; i < 8; i++ {
+		r.X[i] = v.X[i] + u.X[i]
+		r.Y[i] = v.Y[i] + u.Y[i]
+		r.Z[i] = v.Z[i] + u.Z[i]
+	}
+	return r
+}
+
+// Sub returns the element-wise difference of the vectors in v and u.
+func (v *Vec3x8) Sub(u *Vec3x8) Vec3x8 {
+	var r Vec3x8
+	for i := 0; i < 8; i++ {
+		r.X[i] = v.X[i] - u.X[i]
+		r.Y[i] = v.Y[i] - u.Y[i]
+		r.Z[i] = v.Z[i] - u.Z[i]
+	}
+	return r
+}
+
+// Mul returns the vectors in v scaled by s.
+func (v *Vec3x8) Mul(s float32) Vec3x8 {
+	var r Vec3x8
+	for i := 0; i < 8; i++ {
+		r.X[i] = v.X[i] * s
+		r.Y[i] = v.Y[i] * s
+		r.Z[i] = v.Z[i] * s
+	}
+	return r
+}
+
+// Dot returns the dot product of each vector in v with the vector at the same index in u.
+func (v *Vec3x8) Dot(u *Vec3x8) [8]float32 {
+	var r [8]float32
+	for i := 0; i < 8; i++ {
+		r[i] = v.X[i]*u.X[i] + v.Y[i]*u.Y[i] + v.Z[i]*u.Z[i]
+	}
+	return r
+}
+
+// DotVec3 returns the dot product of each vector in v with u.
+func (v *Vec3x8) DotVec3(u Vec3) [8]float32 {
+	var r [8]float32
+	for i := 0; i < 8; i++ {
+		r[i] = v.X[i]*u[0] + v.Y[i]*u[1] + v.Z[i]*u[2]
+	}
+	return r
+}
+
+// Cross returns the cross product of each vector in v with the vector at the same index in u.
+func (v *Vec3x8) Cross(u *Vec3x8) Vec3x8 {
+	var r Vec3x8
+	for i := 0; i < 8; i++ {
+		r.X[i] = v.Y[i]*u.Z[i] - v.Z[i]*u.Y[i]
+		r.Y[i] = v.Z[i]*u.X[i] - v.X[i]*u.Z[i]
+		r.Z[i] = v.X[i]*u.Y[i] - v.Y[i]*u.X[i]
+	}
+	return r
+}
+
+// ProjectOntoAxis returns the interval covered by the vectors when projected onto the axis.
+func (v *Vec3x8) ProjectOntoAxis(axis Vec3) Interval {
+	d := v.DotVec3(axis)
+	in := Interval{Min: d[0], Max: d[0]}
+	for _, p := range d[1:] {
+		if p < in.Min {
+			in.Min = p
+		}
+		if p > in.Max {
+			in.Max = p
+		}
+	}
+	return in
+}
@@ -0,0 +1,101 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestVec3x4(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var a, b [4]Vec3
+	for i := range a {
+		a[i] = RandomPointInAABB(r, AABB{Size: Vec3{10, 10, 10}})
+		b[i] = RandomPointInAABB(r, AABB{Size: Vec3{10, 10, 10}})
+	}
+	va, vb := Vec3x4FromVecs(a), Vec3x4FromVecs(b)
+
+	sum, diff, scaled, cross := va.Add(&vb), va.Sub(&vb), va.Mul(2), va.Cross(&vb)
+	dot, dotv := va.Dot(&vb), va.DotVec3(b[0])
+	for i := range a {
+		if got := va.Vec(i); got != a[i] {
+			t.Errorf("Vec(%d): got %v, wanted %v", i, got, a[i])
+		}
+		if got, want := sum.Vec(i), a[i].Add(b[i]); got != want {
+			t.Errorf("Add[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := diff.Vec(i), a[i].Sub(b[i]); got != want {
+			t.Errorf("Sub[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := scaled.Vec(i), a[i].Mul(2); got != want {
+			t.Errorf("Mul[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := cross.Vec(i), a[i].Cross(b[i]); !nearVec3(got, want, 1e-4) {
+			t.Errorf("Cross[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := dot[i], a[i].Dot(b[i]); abs(got-want) > 1e-4 {
+			t.Errorf("Dot[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := dotv[i], a[i].Dot(b[0]); abs(got-want) > 1e-4 {
+			t.Errorf("DotVec3[%d]: got %v, wanted %v", i, got, want)
+		}
+	}
+
+	s := SplatVec3x4(Vec3{1, 2, 3})
+	s.Set(2, Vec3{4, 5, 6})
+	if s.Vec(0) != (Vec3{1, 2, 3}) || s.Vec(2) != (Vec3{4, 5, 6}) {
+		t.Errorf("SplatVec3x4 and Set: got %v", s)
+	}
+}
+
+func TestVec3x8(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var a, b [8]Vec3
+	for i := range a {
+		a[i] = RandomPointInAABB(r, AABB{Size: Vec3{10, 10, 10}})
+		b[i] = RandomPointInAABB(r, AABB{Size: Vec3{10, 10, 10}})
+	}
+	va, vb := Vec3x8FromVecs(a), Vec3x8FromVecs(b)
+
+	sum, diff, scaled, cross := va.Add(&vb), va.Sub(&vb), va.Mul(2), va.Cross(&vb)
+	dot := va.Dot(&vb)
+	for i := range a {
+		if got, want := sum.Vec(i), a[i].Add(b[i]); got != want {
+			t.Errorf("Add[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := diff.Vec(i), a[i].Sub(b[i]); got != want {
+			t.Errorf("Sub[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := scaled.Vec(i), a[i].Mul(2); got != want {
+			t.Errorf("Mul[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := cross.Vec(i), a[i].Cross(b[i]); !nearVec3(got, want, 1e-4) {
+			t.Errorf("Cross[%d]: got %v, wanted %v", i, got, want)
+		}
+		if got, want := dot[i], a[i].Dot(b[i]); abs(got-want) > 1e-4 {
+			t.Errorf("Dot[%d]: got %v, wanted %v", i, got, want)
+		}
+	}
+
+	if s := SplatVec3x8(Vec3{1, 2, 3}); s.Vec(7) != (Vec3{1, 2, 3}) {
+		t.Errorf("SplatVec3x8: got %v", s)
+	}
+}
+
+func TestAABBCornersSoA(t *testing.T) {
+	a := AABB{Position: Point3{1, 2, 3}, Size: Vec3{1, 2, 3}}
+	corners := a.cornerArray()
+	soa := a.cornersSoA()
+	for i := range corners {
+		if got := soa.Vec(i); got != corners[i] {
+			t.Errorf("corner %d: got %v, wanted %v", i, got, corners[i])
+		}
+	}
+
+	axis := Vec3{1, -2, 0.5}
+	in := soa.ProjectOntoAxis(axis)
+	for _, c := range corners {
+		if p := c.Dot(axis); p < in.Min || p > in.Max {
+			t.Errorf("corner %v projects to %v outside %v", c, p, in)
+		}
+	}
+}
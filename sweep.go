@@ -0,0 +1,77 @@
+package geom
+
+import "math"
+
+// SweepInterval returns the range of times from t0 to t1 during which interval a, moving with
+// velocity va, overlaps interval b, moving with velocity vb. At time t the intervals have moved
+// by va*t and vb*t. Only times of zero or more are considered, so t0 is zero when the intervals
+// already overlap. Intervals that touch count as overlapping, and intervals that overlap and
+// never separate have an infinite t1. The result is false if the intervals never overlap.
+func SweepInterval(a Interval, va float32, b Interval, vb float32) (t0, t1 float32, ok bool) {
+	// Work in the frame of a, in which b moves with velocity v
+	v := vb - va
+	if v == 0 {
+		if !a.Overlaps(b) {
+			return 0, 0, false
+		}
+		return 0, float32(math.Inf(1)), true
+	}
+
+	// b starts to overlap when its leading end reaches a and stops when its trailing end leaves
+	t0 = (a.Min - b.Max) / v
+	t1 = (a.Max - b.Min) / v
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+	if t1 < 0 {
+		return 0, 0, false
+	}
+	return max(t0, 0), t1, true
+}
+
+// SweepRect returns the earliest time at which rect a, moving by delta, comes into contact with
+// the stationary rect b, and the normal of the face of b that it meets. The rect is at
+// a.Position + delta*t at time t, so a contact during the move has a time between 0 and 1. The
+// result is false if a does not reach b during the move.
+//
+// Rects that only slide along each other's edges or brush past a corner are not in contact,
+// but a rect that touches b and moves into it meets it at time zero. A rect that already
+// overlaps b meets it at time zero with a zero normal.
+func SweepRect(a Rect, delta Vec2, b Rect) (t float32, normal Vec2, ok bool) {
+	aMin, aMax := a.Min(), a.Max()
+	bMin, bMax := b.Min(), b.Max()
+
+	enter, exit := float32(0), float32(math.Inf(1))
+	axis := -1
+	for i := 0; i < 2; i++ {
+		ai := Interval{Min: aMin[i], Max: aMax[i]}
+		bi := Interval{Min: bMin[i], Max: bMax[i]}
+		apart := aMin[i] >= bMax[i] || aMax[i] <= bMin[i]
+		if apart && delta[i] == 0 {
+			// Sliding along an edge or passing it by
+			return 0, Vec2{}, false
+		}
+		t0, t1, ok := SweepInterval(ai, delta[i], bi, 0)
+		if !ok {
+			return 0, Vec2{}, false
+		}
+		// Contact is made across an axis on which the rects start apart, when the last such
+		// axis closes up
+		if apart && (axis < 0 || t0 > enter) {
+			enter, axis = t0, i
+		}
+		exit = min(exit, t1)
+	}
+	if axis < 0 {
+		return 0, Vec2{}, true
+	}
+	if enter >= exit || enter > 1 {
+		return 0, Vec2{}, false
+	}
+	if delta[axis] > 0 {
+		normal[axis] = -1
+	} else {
+		normal[axis] = 1
+	}
+	return enter, normal, true
+}
@@ -0,0 +1,184 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSweepInterval(t *testing.T) {
+	inf := float32(math.Inf(1))
+	testCases := []struct {
+		name   string
+		a      Interval
+		va     float32
+		b      Interval
+		vb     float32
+		ok     bool
+		t0, t1 float32
+	}{
+		{
+			name: "approaching",
+			a:    Interval{Min: 0, Max: 1},
+			va:   1,
+			b:    Interval{Min: 4, Max: 6},
+			vb:   -2,
+			ok:   true,
+			t0:   1,
+			t1:   2,
+		},
+		{
+			name: "chasing",
+			a:    Interval{Min: 0, Max: 1},
+			va:   3,
+			b:    Interval{Min: 2, Max: 3},
+			vb:   1,
+			ok:   true,
+			t0:   0.5,
+			t1:   1.5,
+		},
+		{
+			name: "overlapping and separating",
+			a:    Interval{Min: 0, Max: 2},
+			b:    Interval{Min: 1, Max: 3},
+			vb:   2,
+			ok:   true,
+			t0:   0,
+			t1:   0.5,
+		},
+		{
+			name: "overlapping and still",
+			a:    Interval{Min: 0, Max: 2},
+			va:   1,
+			b:    Interval{Min: 2, Max: 3},
+			vb:   1,
+			ok:   true,
+			t0:   0,
+			t1:   inf,
+		},
+		{
+			name: "apart and still",
+			a:    Interval{Min: 0, Max: 1},
+			b:    Interval{Min: 2, Max: 3},
+		},
+		{
+			name: "receding",
+			a:    Interval{Min: 0, Max: 1},
+			b:    Interval{Min: 2, Max: 3},
+			vb:   1,
+		},
+		{
+			name: "touching at the end",
+			a:    Interval{Min: 0, Max: 1},
+			b:    Interval{Min: -3, Max: -1},
+			vb:   1,
+			ok:   true,
+			t0:   1,
+			t1:   4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t0, t1, ok := SweepInterval(tc.a, tc.va, tc.b, tc.vb)
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if abs(t0-tc.t0) > 1e-6 || (t1 != tc.t1 && abs(t1-tc.t1) > 1e-6) {
+				t.Errorf("got %v to %v, wanted %v to %v", t0, t1, tc.t0, tc.t1)
+			}
+		})
+	}
+}
+
+func TestSweepRect(t *testing.T) {
+	platform := RectFromCorners(Point2{0, 0}, Point2{10, 0.1})
+	testCases := []struct {
+		name   string
+		a      Rect
+		delta  Vec2
+		ok     bool
+		t      float32
+		normal Vec2
+	}{
+		{
+			name:   "falling through thin platform",
+			a:      RectFromCorners(Point2{4, 10.1}, Point2{5, 12.1}),
+			delta:  Vec2{0, -100},
+			ok:     true,
+			t:      0.1,
+			normal: Vec2{0, 1},
+		},
+		{
+			name:   "from the side",
+			a:      RectFromCorners(Point2{-3, -1}, Point2{-1, 1}),
+			delta:  Vec2{4, 0},
+			ok:     true,
+			t:      0.25,
+			normal: Vec2{-1, 0},
+		},
+		{
+			name:   "diagonal onto the top",
+			a:      RectFromCorners(Point2{-2, 2.1}, Point2{-1, 3.1}),
+			delta:  Vec2{4, -4},
+			ok:     true,
+			t:      0.5,
+			normal: Vec2{0, 1},
+		},
+		{
+			name:  "too short",
+			a:     RectFromCorners(Point2{4, 10.1}, Point2{5, 12.1}),
+			delta: Vec2{0, -5},
+		},
+		{
+			name:  "misses",
+			a:     RectFromCorners(Point2{12, 5}, Point2{13, 6}),
+			delta: Vec2{0, -10},
+		},
+		{
+			name:  "falls past the end",
+			a:     RectFromCorners(Point2{-2, 1.1}, Point2{-1, 2.1}),
+			delta: Vec2{2, -8},
+		},
+		{
+			name:  "sliding along the top",
+			a:     RectFromCorners(Point2{2, 0.1}, Point2{3, 1.1}),
+			delta: Vec2{3, 0},
+		},
+		{
+			name:   "standing and pushed down",
+			a:      RectFromCorners(Point2{2, 0.1}, Point2{3, 1.1}),
+			delta:  Vec2{1, -1},
+			ok:     true,
+			t:      0,
+			normal: Vec2{0, 1},
+		},
+		{
+			name:  "standing and jumping",
+			a:     RectFromCorners(Point2{2, 0.1}, Point2{3, 1.1}),
+			delta: Vec2{1, 1},
+		},
+		{
+			name: "overlapping",
+			a:    RectFromCorners(Point2{2, -0.5}, Point2{3, 0.5}),
+			ok:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			toi, n, ok := SweepRect(tc.a, tc.delta, platform)
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if abs(toi-tc.t) > 1e-5 || n != tc.normal {
+				t.Errorf("got time %v with normal %v, wanted %v with %v", toi, n, tc.t, tc.normal)
+			}
+		})
+	}
+}
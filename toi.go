@@ -0,0 +1,232 @@
+package geom
+
+// TOISpherePlane returns the earliest time at which the sphere, moving from its position with
+// velocity vel, touches the plane, and the point of contact. The sphere is at Position +
+// vel*t at time t, so when vel is the displacement over a step a time of at most 1 means the
+// contact happens during the step. The plane is treated as two sided. A sphere that already
+// touches the plane has a time of zero and its contact point is the point on the plane nearest
+// its centre. The result is false if the sphere never reaches the plane.
+func TOISpherePlane(s Sphere, vel Vec3, p Plane3) (float32, Point3, bool) {
+	d := SignedDistancePointPlane3(s.Position, &p)
+	if abs(d) <= s.Radius {
+		return 0, s.Position.Sub(p.Normal.Mul(d)), true
+	}
+
+	vn := p.Normal.Dot(vel)
+	side := float32(1)
+	if d < 0 {
+		side = -1
+	}
+	if vn*side >= 0 {
+		// Moving parallel to the plane or away from it
+		return 0, Point3{}, false
+	}
+	t := (side*s.Radius - d) / vn
+	c := s.Position.Add(vel.Mul(t))
+	return t, c.Sub(p.Normal.Mul(side * s.Radius)), true
+}
+
+// TOISphereTri3 returns the earliest time at which the sphere, moving from its position with
+// velocity vel, touches the triangle, and the point of contact on the triangle. Time is
+// measured as for TOISpherePlane and the triangle is treated as two sided. A sphere that
+// already touches the triangle has a time of zero and its contact point is the point on the
+// triangle nearest its centre. The result is false if the sphere never reaches the triangle.
+func TOISphereTri3(s Sphere, vel Vec3, t Tri3) (float32, Point3, bool) {
+	if DistanceSquaredPointTri3(s.Position, t) <= s.Radius*s.Radius {
+		return 0, t.ClosestPoint(s.Position), true
+	}
+
+	// The sphere first touches the face if the point where it reaches the plane of the
+	// triangle lies inside the triangle
+	if n := t.Normal(); n != (Vec3{}) {
+		plane := Plane3{Normal: n, Distance: n.Dot(t.A)}
+		if toi, pt, ok := TOISpherePlane(s, vel, plane); ok && t.ContainsPoint3(pt) {
+			return toi, pt, true
+		}
+	}
+
+	// Otherwise it first touches an edge or a corner, which is where the path of its centre
+	// reaches a cylinder around the edge or a sphere around the corner
+	best := float32(-1)
+	var point Point3
+	edges := [3][2]Point3{{t.A, t.B}, {t.B, t.C}, {t.C, t.A}}
+	for _, e := range edges {
+		if toi, f, ok := toiRayCylinder(s.Position, vel, e[0], e[1], s.Radius); ok && (best < 0 || toi < best) {
+			best = toi
+			point = e[0].Add(e[1].Sub(e[0]).Mul(f))
+		}
+	}
+	for _, c := range [3]Point3{t.A, t.B, t.C} {
+		if toi, ok := toiRaySphere(s.Position, vel, c, s.Radius); ok && (best < 0 || toi < best) {
+			best, point = toi, c
+		}
+	}
+	if best < 0 {
+		return 0, Point3{}, false
+	}
+	return best, point, true
+}
+
+// TOICapsuleTri3 returns the earliest time at which the capsule, moving from its position with
+// velocity vel, touches the triangle, and the point of contact on the triangle. Time is
+// measured as for TOISpherePlane and the triangle is treated as two sided. A capsule that
+// already touches the triangle has a time of zero and its contact point is the point on the
+// triangle nearest its segment. The result is false if the capsule never reaches the triangle.
+func TOICapsuleTri3(c Capsule, vel Vec3, t Tri3) (float32, Point3, bool) {
+	seg, tp := closestPointsLine3Tri3(c.Line(), t)
+	if DistanceSquared3(seg, tp) <= c.Radius*c.Radius {
+		return 0, tp, true
+	}
+
+	// The capsule first touches the triangle with one of its end spheres, with the side of its
+	// cylinder against a corner of the triangle, or with the side of its cylinder against an
+	// edge of the triangle. Touching the face with the side of the cylinder means touching an
+	// edge or an end sphere at the same time.
+	best := float32(-1)
+	var point Point3
+	consider := func(toi float32, pt Point3) {
+		if best < 0 || toi < best {
+			best, point = toi, pt
+		}
+	}
+	for _, e := range [2]Point3{c.Start, c.End} {
+		if toi, pt, ok := TOISphereTri3(Sphere{Position: e, Radius: c.Radius}, vel, t); ok {
+			consider(toi, pt)
+		}
+	}
+	corners := [3]Point3{t.A, t.B, t.C}
+	for _, v := range corners {
+		// The corner moving backwards reaches the side of the still capsule at the same time
+		if toi, _, ok := toiRayCylinder(v, vel.Mul(-1), c.Start, c.End, c.Radius); ok {
+			consider(toi, v)
+		}
+	}
+	for i, p := range corners {
+		if toi, pt, ok := toiSegmentSegment(c.Start, c.End, vel, p, corners[(i+1)%3], c.Radius); ok {
+			consider(toi, pt)
+		}
+	}
+	if best < 0 {
+		return 0, Point3{}, false
+	}
+	return best, point, true
+}
+
+// toiSegmentSegment returns the earliest time t >= 0 at which the segment from a to b, moved by
+// v*t, comes within r of the segment from p to q with the nearest points strictly inside both
+// segments, and the nearest point on the segment from p to q. The segments must start more
+// than r apart.
+func toiSegmentSegment(a, b Point3, v Vec3, p, q Point3, r float32) (float32, Point3, bool) {
+	d1, d2 := b.Sub(a), q.Sub(p)
+	n := d1.Cross(d2)
+	nl := n.Len()
+	if nl <= epsilon32*d1.Len()*d2.Len() {
+		// Parallel segments first meet at one of their ends
+		return 0, Point3{}, false
+	}
+	n = n.Mul(1 / nl)
+
+	// The lines through the segments stay the same distance apart along n, and their nearest
+	// points differ only along n, so the segments touch when that distance reaches r
+	h := a.Sub(p).Dot(n)
+	side := float32(1)
+	if h < 0 {
+		side = -1
+	}
+	vn := v.Dot(n)
+	if vn*side >= 0 || abs(h) <= r {
+		return 0, Point3{}, false
+	}
+	toi := (side*r - h) / vn
+	s, u := closestParams3(a.Add(v.Mul(toi)), d1, 1, p, d2, 1)
+	if s <= 0 || s >= 1 || u <= 0 || u >= 1 {
+		return 0, Point3{}, false
+	}
+	return toi, p.Add(d2.Mul(u)), true
+}
+
+// closestPointsLine3Tri3 returns the pair of points on the line segment and the triangle that
+// are closest to each other. A segment that passes through the triangle gives the point where
+// it crosses for both.
+func closestPointsLine3Tri3(l Line3, t Tri3) (Point3, Point3) {
+	if n := t.Normal(); n != (Vec3{}) {
+		da, db := l.Start.Sub(t.A).Dot(n), l.End.Sub(t.A).Dot(n)
+		if da*db <= 0 && da != db {
+			x := l.Start.Add(l.End.Sub(l.Start).Mul(da / (da - db)))
+			if t.ContainsPoint3(x) {
+				return x, x
+			}
+		}
+	}
+
+	// Otherwise the nearest points involve an end of the segment or an edge of the triangle
+	bestSeg, bestTri := l.Start, t.ClosestPoint(l.Start)
+	bestDist := DistanceSquared3(bestSeg, bestTri)
+	consider := func(a, b Point3) {
+		if d := DistanceSquared3(a, b); d < bestDist {
+			bestSeg, bestTri, bestDist = a, b, d
+		}
+	}
+	consider(l.End, t.ClosestPoint(l.End))
+	d := l.End.Sub(l.Start)
+	corners := [3]Point3{t.A, t.B, t.C}
+	for i, p := range corners {
+		e := corners[(i+1)%3].Sub(p)
+		s, u := closestParams3(l.Start, d, 1, p, e, 1)
+		consider(l.Start.Add(d.Mul(s)), p.Add(e.Mul(u)))
+	}
+	return bestSeg, bestTri
+}
+
+// toiRaySphere returns the earliest time t >= 0 at which o + v*t is within r of c. The point
+// o must start outside the sphere.
+func toiRaySphere(o Point3, v Vec3, c Point3, r float32) (float32, bool) {
+	m := o.Sub(c)
+	a := v.Dot(v)
+	b := m.Dot(v)
+	cc := m.Dot(m) - r*r
+	if a == 0 || b >= 0 {
+		return 0, false
+	}
+	disc := b*b - a*cc
+	if disc < 0 {
+		return 0, false
+	}
+	return (-b - sqrt(disc)) / a, true
+}
+
+// toiRayCylinder returns the earliest time t >= 0 at which o + v*t is within r of the segment
+// from p to q, provided the nearest point of the segment lies strictly between its ends, and
+// the fraction of the way along the segment of that point. The point o must start outside the
+// cylinder.
+func toiRayCylinder(o Point3, v Vec3, p, q Point3, r float32) (float32, float32, bool) {
+	d := q.Sub(p)
+	m := o.Sub(p)
+	dd := d.Dot(d)
+	if dd == 0 {
+		return 0, 0, false
+	}
+	md, vd := m.Dot(d), v.Dot(d)
+
+	// Solve |(m + v t) x d|² = r² |d|² using the components perpendicular to d
+	a := dd*v.Dot(v) - vd*vd
+	b := dd*m.Dot(v) - md*vd
+	c := dd*(m.Dot(m)-r*r) - md*md
+	if a <= 0 || b >= 0 {
+		// Moving parallel to the edge or away from it
+		return 0, 0, false
+	}
+	disc := b*b - a*c
+	if disc < 0 {
+		return 0, 0, false
+	}
+	t := (-b - sqrt(disc)) / a
+	if t < 0 {
+		return 0, 0, false
+	}
+	f := (md + t*vd) / dd
+	if f <= 0 || f >= 1 {
+		return 0, 0, false
+	}
+	return t, f, true
+}
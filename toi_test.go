@@ -0,0 +1,283 @@
+package geom
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTOISpherePlane(t *testing.T) {
+	floor := Plane3{Normal: Vec3{0, 1, 0}, Distance: 0}
+	testCases := []struct {
+		name   string
+		sphere Sphere
+		vel    Vec3
+		hit    bool
+		time   float32
+		point  Point3
+	}{
+		{
+			name:   "falling",
+			sphere: Sphere{Position: Point3{1, 5, 2}, Radius: 1},
+			vel:    Vec3{0, -2, 0},
+			hit:    true,
+			time:   2,
+			point:  Point3{1, 0, 2},
+		},
+		{
+			name:   "fast through thin floor",
+			sphere: Sphere{Position: Point3{0, 3, 0}, Radius: 0.5},
+			vel:    Vec3{5, -50, 0},
+			hit:    true,
+			time:   0.05,
+			point:  Point3{0.25, 0, 0},
+		},
+		{
+			name:   "from below",
+			sphere: Sphere{Position: Point3{0, -3, 0}, Radius: 1},
+			vel:    Vec3{0, 1, 0},
+			hit:    true,
+			time:   2,
+			point:  Point3{0, 0, 0},
+		},
+		{
+			name:   "touching",
+			sphere: Sphere{Position: Point3{2, 0.5, 0}, Radius: 1},
+			vel:    Vec3{0, 1, 0},
+			hit:    true,
+			time:   0,
+			point:  Point3{2, 0, 0},
+		},
+		{
+			name:   "moving away",
+			sphere: Sphere{Position: Point3{0, 3, 0}, Radius: 1},
+			vel:    Vec3{0, 1, 0},
+		},
+		{
+			name:   "parallel",
+			sphere: Sphere{Position: Point3{0, 3, 0}, Radius: 1},
+			vel:    Vec3{1, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			toi, pt, ok := TOISpherePlane(tc.sphere, tc.vel, floor)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v", ok, tc.hit)
+			}
+			if !ok {
+				return
+			}
+			if abs(toi-tc.time) > 1e-5 || !nearVec3(pt, tc.point, 1e-5) {
+				t.Errorf("got time %v at %v, wanted %v at %v", toi, pt, tc.time, tc.point)
+			}
+		})
+	}
+}
+
+func TestTOISphereTri3(t *testing.T) {
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{4, 0, 0}, C: Point3{0, 0, 4}}
+	testCases := []struct {
+		name   string
+		sphere Sphere
+		vel    Vec3
+		hit    bool
+		time   float32
+		point  Point3
+	}{
+		{
+			name:   "face",
+			sphere: Sphere{Position: Point3{1, 3, 1}, Radius: 1},
+			vel:    Vec3{0, -1, 0},
+			hit:    true,
+			time:   2,
+			point:  Point3{1, 0, 1},
+		},
+		{
+			name:   "face from below",
+			sphere: Sphere{Position: Point3{1, -3, 1}, Radius: 1},
+			vel:    Vec3{0, 4, 0},
+			hit:    true,
+			time:   0.5,
+			point:  Point3{1, 0, 1},
+		},
+		{
+			name:   "edge",
+			sphere: Sphere{Position: Point3{2, 3, -1}, Radius: 1},
+			vel:    Vec3{0, -1, 0},
+			hit:    true,
+			time:   3,
+			point:  Point3{2, 0, 0},
+		},
+		{
+			name:   "corner",
+			sphere: Sphere{Position: Point3{-3, 0, -3}, Radius: 1},
+			vel:    Vec3{1, 0, 1},
+			hit:    true,
+			time:   3 - 1/sqrt(2),
+			point:  Point3{0, 0, 0},
+		},
+		{
+			name:   "touching",
+			sphere: Sphere{Position: Point3{1, 0.5, 1}, Radius: 1},
+			vel:    Vec3{5, 5, 5},
+			hit:    true,
+			time:   0,
+			point:  Point3{1, 0, 1},
+		},
+		{
+			name:   "passes beside",
+			sphere: Sphere{Position: Point3{6, 3, 6}, Radius: 1},
+			vel:    Vec3{0, -1, 0},
+		},
+		{
+			name:   "moving away",
+			sphere: Sphere{Position: Point3{1, 3, 1}, Radius: 1},
+			vel:    Vec3{0, 1, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			toi, pt, ok := TOISphereTri3(tc.sphere, tc.vel, tri)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v", ok, tc.hit)
+			}
+			if !ok {
+				return
+			}
+			if abs(toi-tc.time) > 1e-5 || !nearVec3(pt, tc.point, 1e-5) {
+				t.Errorf("got time %v at %v, wanted %v at %v", toi, pt, tc.time, tc.point)
+			}
+
+			// The sphere touches the triangle at the contact point at the time of impact
+			c := tc.sphere.Position.Add(tc.vel.Mul(toi))
+			if d := sqrt(DistanceSquaredPointTri3(c, tri)); abs(d-tc.sphere.Radius) > 1e-4 && toi > 0 {
+				t.Errorf("sphere is %v from the triangle at the time of impact", d)
+			}
+		})
+	}
+}
+
+func TestTOICapsuleTri3(t *testing.T) {
+	tri := Tri3{A: Point3{0, 0, 0}, B: Point3{4, 0, 0}, C: Point3{0, 0, 4}}
+	testCases := []struct {
+		name    string
+		capsule Capsule
+		vel     Vec3
+		hit     bool
+		time    float32
+		point   Point3
+	}{
+		{
+			name:    "end on face",
+			capsule: Capsule{Start: Point3{1, 3, 1}, End: Point3{1, 5, 1}, Radius: 1},
+			vel:     Vec3{0, -1, 0},
+			hit:     true,
+			time:    2,
+			point:   Point3{1, 0, 1},
+		},
+		{
+			name:    "side against edge",
+			capsule: Capsule{Start: Point3{2, -2, -3}, End: Point3{2, 2, -1}, Radius: 0.5},
+			vel:     Vec3{0, 0, 1},
+			hit:     true,
+			time:    2 - sqrt(5)/4,
+			point:   Point3{2, 0, 0},
+		},
+		{
+			name:    "side against corner diagonally",
+			capsule: Capsule{Start: Point3{-2, 1, -2}, End: Point3{-2, -1, -2}, Radius: 1},
+			vel:     Vec3{1, 0, 1},
+			hit:     true,
+			time:    2 - 1/sqrt(2),
+			point:   Point3{0, 0, 0},
+		},
+		{
+			name:    "side against corner",
+			capsule: Capsule{Start: Point3{6, -2, 0}, End: Point3{6, 2, 0}, Radius: 1},
+			vel:     Vec3{-1, 0, 0},
+			hit:     true,
+			time:    1,
+			point:   Point3{4, 0, 0},
+		},
+		{
+			name:    "touching",
+			capsule: Capsule{Start: Point3{1, 0.5, 1}, End: Point3{1, 3, 1}, Radius: 1},
+			vel:     Vec3{5, 5, 5},
+			hit:     true,
+			time:    0,
+			point:   Point3{1, 0, 1},
+		},
+		{
+			name:    "passes beside",
+			capsule: Capsule{Start: Point3{6, 3, 6}, End: Point3{6, 5, 6}, Radius: 1},
+			vel:     Vec3{0, -1, 0},
+		},
+		{
+			name:    "moving away",
+			capsule: Capsule{Start: Point3{1, 3, 1}, End: Point3{1, 5, 1}, Radius: 1},
+			vel:     Vec3{0, 1, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			toi, pt, ok := TOICapsuleTri3(tc.capsule, tc.vel, tri)
+			if ok != tc.hit {
+				t.Fatalf("got hit %v, wanted %v", ok, tc.hit)
+			}
+			if !ok {
+				return
+			}
+			if abs(toi-tc.time) > 1e-5 || !nearVec3(pt, tc.point, 1e-5) {
+				t.Errorf("got time %v at %v, wanted %v at %v", toi, pt, tc.time, tc.point)
+			}
+		})
+	}
+}
+
+func TestTOICapsuleTri3Random(t *testing.T) {
+	// At the time of impact the capsule must just touch the triangle, and before it the two
+	// must be apart
+	rng := rand.New(rand.NewSource(1))
+	point := func(s float32) Point3 {
+		return Point3{(rng.Float32()*2 - 1) * s, (rng.Float32()*2 - 1) * s, (rng.Float32()*2 - 1) * s}
+	}
+	dist := func(c Capsule, tri Tri3) float32 {
+		a, b := closestPointsLine3Tri3(c.Line(), tri)
+		return a.Sub(b).Len()
+	}
+	hits := 0
+	for i := 0; i < 2000; i++ {
+		tri := Tri3{A: point(2), B: point(2), C: point(2)}
+		c := Capsule{Start: point(6), Radius: 0.2 + rng.Float32()}
+		c.End = c.Start.Add(point(2))
+		if dist(c, tri) <= c.Radius {
+			continue
+		}
+		vel := tri.Centroid().Add(point(2)).Sub(c.Start)
+
+		toi, _, ok := TOICapsuleTri3(c, vel, tri)
+		if !ok {
+			continue
+		}
+		hits++
+		at := func(f float32) Capsule {
+			return Capsule{Start: c.Start.Add(vel.Mul(f)), End: c.End.Add(vel.Mul(f)), Radius: c.Radius}
+		}
+		if d := dist(at(toi), tri); abs(d-c.Radius) > 1e-3 {
+			t.Errorf("case %d: distance at time %v is %v, wanted %v", i, toi, d, c.Radius)
+		}
+		for k := 0; k < 20; k++ {
+			f := toi * float32(k) / 20
+			if d := dist(at(f), tri); d < c.Radius-1e-3 {
+				t.Errorf("case %d: distance at time %v is %v, before the time of impact %v", i, f, d, toi)
+				break
+			}
+		}
+	}
+	if hits < 500 {
+		t.Errorf("only %d of the random cases hit", hits)
+	}
+}
@@ -1,50 +1,109 @@
 package geom
 
 import (
+	"runtime"
+	"sync"
+
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// AxisConvention specifies which of an object's local axes is treated as its front. All
+// conventions use the local Y axis as the top and a right handed coordinate system.
+type AxisConvention int
+
+const (
+	// ForwardPositiveZ treats the local Z axis as the front and the local X axis as the left.
+	// This is the default.
+	ForwardPositiveZ AxisConvention = iota
+
+	// ForwardNegativeZ treats the local negative Z axis as the front and the local X axis as
+	// the right, matching the OpenGL camera convention.
+	ForwardNegativeZ
+)
+
+func (c AxisConvention) String() string {
+	switch c {
+	case ForwardPositiveZ:
+		return "forward +z"
+	case ForwardNegativeZ:
+		return "forward -z"
+	default:
+		return "unknown"
+	}
+}
+
+// front returns the local axis that points to the front of an object.
+func (c AxisConvention) front() Vec3 {
+	if c == ForwardNegativeZ {
+		return Vec3{0, 0, -1}
+	}
+	return Vec3{0, 0, 1}
+}
+
+// left returns the local axis that points to the left of an object.
+func (c AxisConvention) left() Vec3 {
+	if c == ForwardNegativeZ {
+		return Vec3{-1, 0, 0}
+	}
+	return Vec3{1, 0, 0}
+}
+
 type Transform struct {
 	position    Vec3
 	scale       Vec3
 	orientation Quat
-	matrix      *Mat4
+	convention  AxisConvention
+	matrix      Mat4 // kept up to date by the setters so that reads never modify the transform
+	matrixValid bool
 }
 
 func NewTransform() Transform {
-	return Transform{
+	t := Transform{
 		position:    Vec3{0, 0, 0},
 		scale:       Vec3{1, 1, 1},
 		orientation: mgl32.QuatIdent(),
 	}
+	t.updateMatrix()
+	return t
 }
 
 // SetPosition sets the position of the object.
 func (t *Transform) SetPosition(v Vec3) {
 	t.position = clampZeroVec3(v)
-	t.matrix = nil
+	t.updateMatrix()
 }
 
 func (t *Transform) SetScale(v Vec3) {
 	t.scale = clampZeroVec3(v)
-	t.matrix = nil
+	t.updateMatrix()
 }
 
 func (t *Transform) SetOrientation(q Quat) {
 	t.orientation = q.Normalize()
-	t.matrix = nil
+	t.updateMatrix()
 }
 
+// Matrix returns the model matrix for the transform. It does not modify the transform so it is
+// safe to call from multiple goroutines.
 func (t *Transform) Matrix() Mat4 {
-	if t.matrix == nil {
-		trans := mgl32.Translate3D(t.position[0], t.position[1], t.position[2])
-		scale := mgl32.Scale3D(t.scale[0], t.scale[1], t.scale[2])
-		rot := t.orientation.Mat4()
-
-		m := trans.Mul4(rot).Mul4(scale)
-		t.matrix = &m
+	if !t.matrixValid {
+		// The transform was not created by NewTransform and has not been modified since
+		return t.computeMatrix()
 	}
-	return *t.matrix
+	return t.matrix
+}
+
+func (t *Transform) updateMatrix() {
+	t.matrix = t.computeMatrix()
+	t.matrixValid = true
+}
+
+func (t *Transform) computeMatrix() Mat4 {
+	trans := mgl32.Translate3D(t.position[0], t.position[1], t.position[2])
+	scale := mgl32.Scale3D(t.scale[0], t.scale[1], t.scale[2])
+	rot := t.orientation.Mat4()
+
+	return trans.Mul4(rot).Mul4(scale)
 }
 
 func (t *Transform) SetMatrix(m Mat4) {
@@ -75,7 +134,50 @@ func (t *Transform) SetMatrix(m Mat4) {
 	}
 
 	t.orientation = mgl32.Mat4ToQuat(rot)
-	t.matrix = nil
+	t.updateMatrix()
+}
+
+// Inverse returns the transform that reverses the effect of t. Scale is applied after rotation
+// in the inverse so the result is only exact when t has a uniform scale. The inverse keeps the
+// axis convention of t.
+func (t *Transform) Inverse() Transform {
+	inv := Transform{
+		scale:       Vec3{1 / t.scale[0], 1 / t.scale[1], 1 / t.scale[2]},
+		orientation: t.orientation.Inverse(),
+		convention:  t.convention,
+	}
+	pos := inv.orientation.Rotate(t.position.Mul(-1))
+	inv.position = clampZeroVec3(Vec3{pos[0] * inv.scale[0], pos[1] * inv.scale[1], pos[2] * inv.scale[2]})
+	inv.updateMatrix()
+	return inv
+}
+
+// TransformPoint converts p from the object's local space into world space.
+func (t *Transform) TransformPoint(p Point3) Point3 {
+	return t.Matrix().Mul4x1(p.Vec4(1)).Vec3()
+}
+
+// TransformPoints converts each point in src from the object's local space into world space,
+// writing the results to dst. dst must be at least as long as src and may be the same slice.
+func (t *Transform) TransformPoints(dst, src []Point3) {
+	TransformPoints(t.Matrix(), dst, src)
+}
+
+// InverseTransformPoint converts p from world space into the object's local space.
+func (t *Transform) InverseTransformPoint(p Point3) Point3 {
+	local := t.orientation.Inverse().Rotate(p.Sub(t.position))
+	return Point3{local[0] / t.scale[0], local[1] / t.scale[1], local[2] / t.scale[2]}
+}
+
+// Convention returns the axis convention used to determine the object's front, left and right vectors.
+func (t *Transform) Convention() AxisConvention {
+	return t.convention
+}
+
+// SetConvention sets the axis convention used to determine the object's front, left and right
+// vectors. It does not change the object's orientation.
+func (t *Transform) SetConvention(c AxisConvention) {
+	t.convention = c
 }
 
 // Scale returns the scale of the object
@@ -141,10 +243,59 @@ func (t *Transform) RotateToward(target Vec3) {
 	t.Rotate(rotation)
 }
 
+// LookAt rotates the object so that its Front vector points toward target and its Top vector
+// lies in the plane formed by the Front vector and up. Unlike RotateToward the resulting
+// orientation does not depend on the object's previous orientation so it has no unwanted roll.
+// If up is parallel to the direction of target then the object's current Top vector is used instead.
+func (t *Transform) LookAt(target, up Vec3) {
+	front := target.Sub(t.position)
+	if front.LenSqr() < epsilon32 {
+		return
+	}
+	if t.convention == ForwardNegativeZ {
+		// The local Z axis points away from the target
+		front = front.Mul(-1)
+	}
+	t.SetOrientation(lookRotation(front, up, t.Top()))
+}
+
+// LookAtQuat returns the orientation of an object at eye that faces target with its top toward
+// up, for an object using the ForwardPositiveZ convention. The local Z axis points toward target
+// and the local Y axis lies in the plane formed by that direction and up. It returns the
+// identity if eye and target are the same point.
+func LookAtQuat(eye, target, up Vec3) Quat {
+	front := target.Sub(eye)
+	if front.LenSqr() < epsilon32 {
+		return mgl32.QuatIdent()
+	}
+	return lookRotation(front, up, up)
+}
+
+// lookRotation returns the rotation that orients the local Z axis along front and the local Y
+// axis toward up. fallbackUp is used when front and up are parallel.
+func lookRotation(front, up, fallbackUp Vec3) Quat {
+	front = front.Normalize()
+	left := up.Cross(front)
+	if left.LenSqr() < epsilon32 {
+		left = fallbackUp.Cross(front)
+		if left.LenSqr() < epsilon32 {
+			// Pick any axis that is not parallel to front
+			left = Y3.Cross(front)
+			if left.LenSqr() < epsilon32 {
+				left = X3.Cross(front)
+			}
+		}
+	}
+	left = left.Normalize()
+	top := front.Cross(left)
+
+	return mgl32.Mat4ToQuat(mgl32.Mat3FromCols(left, top, front).Mat4()).Normalize()
+}
+
 // Front returns the direction the front of the object is facing. The vector will point along
-// the object's local Z axis.
+// the object's local Z axis, or negative Z axis when using the ForwardNegativeZ convention.
 func (t *Transform) Front() Vec3 {
-	return clampZeroVec3(t.orientation.Rotate(Vec3{0, 0, 1}).Normalize())
+	return clampZeroVec3(t.orientation.Rotate(t.convention.front()).Normalize())
 }
 
 // Top returns the direction the top of the object is facing. The vector will point along
@@ -153,16 +304,16 @@ func (t *Transform) Top() Vec3 {
 	return clampZeroVec3(t.orientation.Rotate(Vec3{0, 1, 0}).Normalize())
 }
 
-// // Right returns the direction the right of the object is facing. The vector will point along
-// // the object's local negative X axis.
-// func (t *Transform) Right() Vec3 {
-// 	return ClampZeroVec3(t.orientation.Rotate(Vec3{-1, 0, 0}).Normalize())
-// }
+// Right returns the direction the right of the object is facing. The vector will point along
+// the object's local negative X axis, or X axis when using the ForwardNegativeZ convention.
+func (t *Transform) Right() Vec3 {
+	return clampZeroVec3(t.orientation.Rotate(t.convention.left().Mul(-1)).Normalize())
+}
 
 // Left returns the direction the left of the object is facing. The vector will point along
-// the object's local X axis.
+// the object's local X axis, or negative X axis when using the ForwardNegativeZ convention.
 func (t *Transform) Left() Vec3 {
-	return clampZeroVec3(t.orientation.Rotate(Vec3{1, 0, 0}).Normalize())
+	return clampZeroVec3(t.orientation.Rotate(t.convention.left()).Normalize())
 }
 
 // Advance moves the object along the direction it is facing without rotating.
@@ -195,3 +346,52 @@ func (t *Transform) Yaw(angle float32) {
 func (t *Transform) Roll(angle float32) {
 	t.RotateAbout(t.Front(), angle)
 }
+
+// TransformPoints applies the affine transformation m to each point in src, writing the results
+// to dst. dst must be at least as long as src and may be the same slice. The projective row of m
+// is ignored.
+func TransformPoints(m Mat4, dst, src []Point3) {
+	dst = dst[:len(src)]
+	for i, p := range src {
+		dst[i] = Point3{
+			m[0]*p[0] + m[4]*p[1] + m[8]*p[2] + m[12],
+			m[1]*p[0] + m[5]*p[1] + m[9]*p[2] + m[13],
+			m[2]*p[0] + m[6]*p[1] + m[10]*p[2] + m[14],
+		}
+	}
+}
+
+// parallelTransformMin is the minimum number of points that will be handled by each goroutine
+// in TransformPointsParallel.
+const parallelTransformMin = 4096
+
+// TransformPointsParallel is like TransformPoints but divides large slices between multiple
+// goroutines. It returns once all points have been transformed. Small slices are transformed
+// on the calling goroutine.
+func TransformPointsParallel(m Mat4, dst, src []Point3) {
+	dst = dst[:len(src)]
+
+	workers := len(src) / parallelTransformMin
+	if procs := runtime.GOMAXPROCS(0); workers > procs {
+		workers = procs
+	}
+	if workers < 2 {
+		TransformPoints(m, dst, src)
+		return
+	}
+
+	chunk := (len(src) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(src); start += chunk {
+		end := start + chunk
+		if end > len(src) {
+			end = len(src)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			TransformPoints(m, dst[start:end], src[start:end])
+		}(start, end)
+	}
+	wg.Wait()
+}
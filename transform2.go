@@ -0,0 +1,151 @@
+package geom
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Transform2 is the position, rotation and scale of an object in 2 dimensions.
+type Transform2 struct {
+	position    Vec2
+	scale       Vec2
+	rotation    float32 // radians, counter clockwise
+	matrix      Mat3    // kept up to date by the setters so that reads never modify the transform
+	matrixValid bool
+}
+
+func NewTransform2() Transform2 {
+	t := Transform2{
+		position: Vec2{0, 0},
+		scale:    Vec2{1, 1},
+	}
+	t.updateMatrix()
+	return t
+}
+
+// SetPosition sets the position of the object.
+func (t *Transform2) SetPosition(v Vec2) {
+	t.position = ClampZeroVec2(v)
+	t.updateMatrix()
+}
+
+func (t *Transform2) SetScale(v Vec2) {
+	t.scale = ClampZeroVec2(v)
+	t.updateMatrix()
+}
+
+// SetRotation sets the rotation of the object to the angle in radians.
+func (t *Transform2) SetRotation(angle float32) {
+	t.rotation = angle
+	t.updateMatrix()
+}
+
+// Matrix returns the model matrix for the transform. It does not modify the transform so it is
+// safe to call from multiple goroutines.
+func (t *Transform2) Matrix() Mat3 {
+	if !t.matrixValid {
+		// The transform was not created by NewTransform2 and has not been modified since
+		return t.computeMatrix()
+	}
+	return t.matrix
+}
+
+func (t *Transform2) updateMatrix() {
+	t.matrix = t.computeMatrix()
+	t.matrixValid = true
+}
+
+func (t *Transform2) computeMatrix() Mat3 {
+	trans := mgl32.Translate2D(t.position[0], t.position[1])
+	scale := mgl32.Scale2D(t.scale[0], t.scale[1])
+	rot := mgl32.HomogRotate2D(t.rotation)
+
+	return trans.Mul3(rot).Mul3(scale)
+}
+
+// Scale returns the scale of the object
+func (t *Transform2) Scale() Vec2 {
+	return t.scale
+}
+
+// SetScaleUniform sets the scale of the object to v along both axes
+func (t *Transform2) SetScaleUniform(v float32) {
+	t.SetScale(Vec2{v, v})
+}
+
+// ScaleBy changes the scale of the object by x and y along two axes.
+func (t *Transform2) ScaleBy(x, y float32) {
+	t.SetScale(Vec2{t.scale[0] * x, t.scale[1] * y})
+}
+
+// ScaleUniformBy changes the scale of the object by v along both axes.
+func (t *Transform2) ScaleUniformBy(v float32) {
+	t.SetScale(t.scale.Mul(v))
+}
+
+// Pos returns the position of the object.
+func (t *Transform2) Pos() Vec2 {
+	return t.position
+}
+
+// Translate translates the object by v.
+func (t *Transform2) Translate(v Vec2) {
+	t.SetPosition(t.position.Add(v))
+}
+
+// TranslateAlong translates the object by v along axis.
+func (t *Transform2) TranslateAlong(v float32, axis Vec2) {
+	t.Translate(axis.Mul(v))
+}
+
+// Rotation returns the rotation of the object in radians.
+func (t *Transform2) Rotation() float32 {
+	return t.rotation
+}
+
+// Rotate rotates the object counter clockwise by the angle given in radians.
+func (t *Transform2) Rotate(angle float32) {
+	t.SetRotation(t.rotation + angle)
+}
+
+// RotateToward rotates the object so that it faces the target. Its Front vector
+// will point toward target.
+func (t *Transform2) RotateToward(target Vec2) {
+	d := target.Sub(t.position)
+	if d.LenSqr() < epsilon32 {
+		return
+	}
+	t.SetRotation(atan2(d[1], d[0]))
+}
+
+// Front returns the direction the front of the object is facing. The vector will point along
+// the object's local X axis.
+func (t *Transform2) Front() Vec2 {
+	return ClampZeroVec2(mgl32.Rotate2D(t.rotation).Mul2x1(X2))
+}
+
+// Left returns the direction the left of the object is facing. The vector will point along
+// the object's local Y axis.
+func (t *Transform2) Left() Vec2 {
+	return ClampZeroVec2(mgl32.Rotate2D(t.rotation).Mul2x1(Y2))
+}
+
+// Advance moves the object along the direction it is facing without rotating.
+func (t *Transform2) Advance(s float32) {
+	t.Translate(t.Front().Mul(s))
+}
+
+// Strafe moves the object along its left pointing vector without rotating.
+func (t *Transform2) Strafe(s float32) {
+	t.Translate(t.Left().Mul(s))
+}
+
+// TransformPoint converts p from the object's local space into world space.
+func (t *Transform2) TransformPoint(p Point2) Point2 {
+	return t.Matrix().Mul3x1(p.Vec3(1)).Vec2()
+}
+
+// InverseTransformPoint converts p from world space into the object's local space.
+func (t *Transform2) InverseTransformPoint(p Point2) Point2 {
+	local := mgl32.Rotate2D(-t.rotation).Mul2x1(p.Sub(t.position))
+	return Point2{local[0] / t.scale[0], local[1] / t.scale[1]}
+}
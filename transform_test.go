@@ -0,0 +1,339 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestTransformInverse(t *testing.T) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 2, 3})
+	tx.SetAngleAbout(Y3, pi/3)
+	tx.SetScaleUniform(2)
+
+	inv := tx.Inverse()
+
+	points := []Point3{
+		{0, 0, 0},
+		{1, 0, 0},
+		{-4, 5, 2},
+	}
+
+	for _, p := range points {
+		world := tx.TransformPoint(p)
+		if got := inv.TransformPoint(world); !nearVec3(got, p, 1e-4) {
+			t.Errorf("inverse transform of %v: got %v, wanted %v", world, got, p)
+		}
+		if got := tx.InverseTransformPoint(world); !nearVec3(got, p, 1e-4) {
+			t.Errorf("InverseTransformPoint of %v: got %v, wanted %v", world, got, p)
+		}
+	}
+}
+
+func TestTransformInverseConvention(t *testing.T) {
+	tx := NewTransform()
+	tx.SetConvention(ForwardNegativeZ)
+	tx.SetAngleAbout(Y3, pi/3)
+
+	inv := tx.Inverse()
+	if inv.Convention() != ForwardNegativeZ {
+		t.Fatalf("got convention %v, wanted %v", inv.Convention(), ForwardNegativeZ)
+	}
+	want := NewTransform()
+	want.SetConvention(ForwardNegativeZ)
+	want.SetAngleAbout(Y3, -pi/3)
+	if got := inv.Front(); !nearVec3(got, want.Front(), 1e-5) {
+		t.Errorf("got front %v, wanted %v", got, want.Front())
+	}
+}
+
+func TestTransformPoint(t *testing.T) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{10, 0, 0})
+	tx.SetAngleAbout(Z3, pi/2)
+	tx.SetScale(Vec3{2, 1, 1})
+
+	// local x is scaled by 2, rotated onto the y axis and then translated
+	got := tx.TransformPoint(Point3{1, 0, 0})
+	want := Point3{10, 2, 0}
+	if !nearVec3(got, want, 1e-5) {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+
+	// non-uniform scale is reversed exactly
+	if back := tx.InverseTransformPoint(got); !nearVec3(back, Point3{1, 0, 0}, 1e-5) {
+		t.Errorf("got %v, wanted %v", back, Point3{1, 0, 0})
+	}
+}
+
+func TestTransformLookAt(t *testing.T) {
+	testCases := []struct {
+		name   string
+		pos    Vec3
+		target Vec3
+		up     Vec3
+		front  Vec3
+		top    Vec3
+	}{
+		{name: "along-z", pos: Vec3{0, 0, 0}, target: Vec3{0, 0, 5}, up: Y3, front: Z3, top: Y3},
+		{name: "along-x", pos: Vec3{0, 0, 0}, target: Vec3{5, 0, 0}, up: Y3, front: X3, top: Y3},
+		{name: "along-neg-z", pos: Vec3{1, 1, 1}, target: Vec3{1, 1, -4}, up: Y3, front: Vec3{0, 0, -1}, top: Y3},
+		{name: "z-up", pos: Vec3{0, 0, 0}, target: Vec3{0, 3, 0}, up: Z3, front: Y3, top: Z3},
+		{name: "tilted-up", pos: Vec3{0, 0, 0}, target: Vec3{0, 0, 1}, up: Vec3{0, 1, 1}, front: Z3, top: Y3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := NewTransform()
+			tx.SetPosition(tc.pos)
+			// start from an arbitrary orientation to show the result does not depend on it
+			tx.SetAngleAbout(Vec3{1, 1, 0}.Normalize(), 1)
+			tx.LookAt(tc.target, tc.up)
+
+			if got := tx.Front(); !nearVec3(got, tc.front, 1e-5) {
+				t.Errorf("got front %v, wanted %v", got, tc.front)
+			}
+			if got := tx.Top(); !nearVec3(got, tc.top, 1e-5) {
+				t.Errorf("got top %v, wanted %v", got, tc.top)
+			}
+		})
+	}
+}
+
+func TestTransform2Point(t *testing.T) {
+	tx := NewTransform2()
+	tx.SetPosition(Vec2{5, 0})
+	tx.SetRotation(pi / 2)
+	tx.SetScale(Vec2{2, 1})
+
+	got := tx.TransformPoint(Point2{1, 0})
+	want := Point2{5, 2}
+	if abs(got[0]-want[0]) > 1e-5 || abs(got[1]-want[1]) > 1e-5 {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+
+	back := tx.InverseTransformPoint(got)
+	if abs(back[0]-1) > 1e-5 || abs(back[1]) > 1e-5 {
+		t.Errorf("got %v, wanted %v", back, Point2{1, 0})
+	}
+
+	if front := tx.Front(); front != (Vec2{0, 1}) {
+		t.Errorf("got front %v, wanted %v", front, Vec2{0, 1})
+	}
+	if left := tx.Left(); left != (Vec2{-1, 0}) {
+		t.Errorf("got left %v, wanted %v", left, Vec2{-1, 0})
+	}
+
+	tx.RotateToward(Vec2{5, -3})
+	if front := tx.Front(); front != (Vec2{0, -1}) {
+		t.Errorf("got front %v after RotateToward, wanted %v", front, Vec2{0, -1})
+	}
+}
+
+func TestTransformPoints(t *testing.T) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 2, 3})
+	tx.SetAngleAbout(X3, pi/4)
+	tx.SetScaleUniform(3)
+
+	src := make([]Point3, 3*parallelTransformMin)
+	for i := range src {
+		src[i] = Point3{float32(i % 7), float32(i % 11), float32(i % 13)}
+	}
+
+	dst := make([]Point3, len(src))
+	tx.TransformPoints(dst, src)
+
+	par := make([]Point3, len(src))
+	TransformPointsParallel(tx.Matrix(), par, src)
+
+	for i := range src {
+		want := tx.TransformPoint(src[i])
+		if !nearVec3(dst[i], want, 1e-4) {
+			t.Fatalf("point %d: got %v, wanted %v", i, dst[i], want)
+		}
+		if par[i] != dst[i] {
+			t.Fatalf("point %d: parallel got %v, wanted %v", i, par[i], dst[i])
+		}
+	}
+}
+
+func BenchmarkTransformPoints(b *testing.B) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 2, 3})
+	tx.SetAngleAbout(X3, pi/4)
+
+	src := make([]Point3, 50000)
+	for i := range src {
+		src[i] = Point3{float32(i), float32(i), float32(i)}
+	}
+	dst := make([]Point3, len(src))
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tx.TransformPoints(dst, src)
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		m := tx.Matrix()
+		for i := 0; i < b.N; i++ {
+			TransformPointsParallel(m, dst, src)
+		}
+	})
+}
+
+func TestTransformedShapes(t *testing.T) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{10, 0, 0})
+	tx.SetAngleAbout(Y3, pi/2)
+	tx.SetScale(Vec3{2, 3, 1})
+
+	t.Run("aabb", func(t *testing.T) {
+		a := AABB{Position: Point3{1, 0, 0}, Size: Vec3{1, 1, 1}}
+		o := a.OBB(&tx)
+		if want := tx.TransformPoint(a.Position); !nearVec3(o.Position, want, 1e-5) {
+			t.Errorf("got position %v, wanted %v", o.Position, want)
+		}
+		if want := (Vec3{2, 3, 1}); !nearVec3(o.Size, want, 1e-5) {
+			t.Errorf("got size %v, wanted %v", o.Size, want)
+		}
+	})
+
+	t.Run("sphere", func(t *testing.T) {
+		s := Sphere{Position: Point3{0, 1, 0}, Radius: 2}
+		got := s.Transformed(&tx)
+		if want := (Point3{10, 3, 0}); !nearVec3(got.Position, want, 1e-5) {
+			t.Errorf("got position %v, wanted %v", got.Position, want)
+		}
+		if got.Radius != 6 {
+			t.Errorf("got radius %v, wanted %v", got.Radius, 6)
+		}
+	})
+
+	t.Run("capsule", func(t *testing.T) {
+		c := Capsule{Start: Point3{0, 0, 0}, End: Point3{0, 1, 0}, Radius: 0.5}
+		got := c.Transformed(&tx)
+		if want := (Point3{10, 3, 0}); !nearVec3(got.End, want, 1e-5) {
+			t.Errorf("got end %v, wanted %v", got.End, want)
+		}
+		if got.Radius != 1.5 {
+			t.Errorf("got radius %v, wanted %v", got.Radius, 1.5)
+		}
+	})
+}
+
+func TestRay3Transformed(t *testing.T) {
+	tx := NewTransform()
+	tx.SetPosition(Vec3{10, 0, 0})
+	tx.SetAngleAbout(Z3, pi/2)
+	tx.SetScale(Vec3{2, 1, 1})
+
+	local := Ray3FromPoints(Point3{1, 0, 0}, Point3{1, 5, 0})
+	if want := (Vec3{0, 1, 0}); !nearVec3(local.Direction, want, 1e-5) {
+		t.Fatalf("Ray3FromPoints: got direction %v, wanted %v", local.Direction, want)
+	}
+
+	world := local.Transformed(&tx)
+	if want := (Point3{10, 2, 0}); !nearVec3(world.Origin, want, 1e-5) {
+		t.Errorf("Transformed: got origin %v, wanted %v", world.Origin, want)
+	}
+	if want := (Vec3{-1, 0, 0}); !nearVec3(world.Direction, want, 1e-5) {
+		t.Errorf("Transformed: got direction %v, wanted %v", world.Direction, want)
+	}
+
+	back := world.InverseTransformed(&tx)
+	if !nearVec3(back.Origin, local.Origin, 1e-5) || !nearVec3(back.Direction, local.Direction, 1e-5) {
+		t.Errorf("InverseTransformed: got %v, wanted %v", back, local)
+	}
+
+	// A diagonal direction is skewed by the non-uniform scale
+	diag := Ray3{Origin: Point3{0, 0, 0}, Direction: Vec3{1, 1, 0}.Normalize()}
+	skewed := diag.Transformed(&tx)
+	got := skewed.InverseTransformed(&tx)
+	if !nearVec3(got.Direction, diag.Direction, 1e-5) {
+		t.Errorf("round trip: got direction %v, wanted %v", got.Direction, diag.Direction)
+	}
+	if want := (Vec3{-1, 2, 0}).Normalize(); !nearVec3(skewed.Direction, want, 1e-5) {
+		t.Errorf("Transformed: got direction %v, wanted %v", skewed.Direction, want)
+	}
+}
+
+func TestRay2(t *testing.T) {
+	r := Ray2FromPoints(Point2{1, 0}, Point2{1, 5})
+	if want := (Vec2{0, 1}); !r.Direction.ApproxEqual(want) {
+		t.Fatalf("Ray2FromPoints: got direction %v, wanted %v", r.Direction, want)
+	}
+
+	if got, want := r.ClosestPoint(Point2{4, 3}), (Point2{1, 3}); !got.ApproxEqual(want) {
+		t.Errorf("ClosestPoint: got %v, wanted %v", got, want)
+	}
+	if got, want := r.ClosestPoint(Point2{4, -3}), (Point2{1, 0}); !got.ApproxEqual(want) {
+		t.Errorf("ClosestPoint behind origin: got %v, wanted %v", got, want)
+	}
+
+	inv := r.Inverse()
+	if want := (Ray2{Origin: Point2{1, 0}, Direction: Vec2{0, -1}}); !inv.ApproxEqual(want) {
+		t.Errorf("Inverse: got %v, wanted %v", inv, want)
+	}
+
+	tx := NewTransform2()
+	tx.SetPosition(Vec2{5, 0})
+	tx.SetRotation(pi / 2)
+	tx.SetScale(Vec2{2, 1})
+
+	world := r.Transformed(&tx)
+	if want := (Ray2{Origin: Point2{5, 2}, Direction: Vec2{-1, 0}}); !nearRay2(world, want, 1e-5) {
+		t.Errorf("Transformed: got %v, wanted %v", world, want)
+	}
+	if back := world.InverseTransformed(&tx); !nearRay2(back, r, 1e-5) {
+		t.Errorf("InverseTransformed: got %v, wanted %v", back, r)
+	}
+}
+
+func nearRay2(a, b Ray2, tol float32) bool {
+	return a.Origin.Sub(b.Origin).Len() <= tol && a.Direction.Sub(b.Direction).Len() <= tol
+}
+
+func TestTransformConvention(t *testing.T) {
+	testCases := []struct {
+		convention AxisConvention
+		front      Vec3
+		left       Vec3
+		right      Vec3
+	}{
+		{convention: ForwardPositiveZ, front: Vec3{0, 0, 1}, left: Vec3{1, 0, 0}, right: Vec3{-1, 0, 0}},
+		{convention: ForwardNegativeZ, front: Vec3{0, 0, -1}, left: Vec3{-1, 0, 0}, right: Vec3{1, 0, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.convention.String(), func(t *testing.T) {
+			tx := NewTransform()
+			tx.SetConvention(tc.convention)
+
+			if got := tx.Front(); got != tc.front {
+				t.Errorf("got front %v, wanted %v", got, tc.front)
+			}
+			if got := tx.Left(); got != tc.left {
+				t.Errorf("got left %v, wanted %v", got, tc.left)
+			}
+			if got := tx.Right(); got != tc.right {
+				t.Errorf("got right %v, wanted %v", got, tc.right)
+			}
+			// right handed: right x top = back
+			if got := tx.Right().Cross(tx.Top()); !nearVec3(got, tc.front.Mul(-1), 1e-6) {
+				t.Errorf("got right x top %v, wanted %v", got, tc.front.Mul(-1))
+			}
+
+			target := Vec3{3, 0, 0}
+			tx.LookAt(target, Y3)
+			if got := tx.Front(); !nearVec3(got, X3, 1e-5) {
+				t.Errorf("got front %v after LookAt, wanted %v", got, X3)
+			}
+			if got := tx.Top(); !nearVec3(got, Y3, 1e-5) {
+				t.Errorf("got top %v after LookAt, wanted %v", got, Y3)
+			}
+		})
+	}
+}
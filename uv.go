@@ -0,0 +1,132 @@
+package geom
+
+import "math"
+
+// The UV projections below return one texture coordinate for each corner of each triangle of
+// a mesh, in the same order as its indices, so that a vertex shared by triangles on either
+// side of a seam can have a different coordinate in each. Positions are first converted into
+// the local space of the transform, so its position, orientation and scale place and size the
+// projection.
+
+// PlanarUVs projects the mesh onto the local XY plane of tx, using the local X and Y
+// coordinates of each corner as its U and V.
+func PlanarUVs(m *TriMesh, tx *Transform) []Vec2 {
+	uvs := make([]Vec2, len(m.Indices))
+	for i, idx := range m.Indices {
+		p := tx.InverseTransformPoint(m.Positions[idx])
+		uvs[i] = Vec2{p[0], p[1]}
+	}
+	return uvs
+}
+
+// BoxUVs projects each triangle onto the face of a cube in the local space of tx that it most
+// nearly faces, which is also known as triplanar projection. Each face is mapped so that the
+// texture appears the right way round when the face is seen from outside the cube, with V
+// pointing along local Y on the side faces.
+func BoxUVs(m *TriMesh, tx *Transform) []Vec2 {
+	uvs := make([]Vec2, len(m.Indices))
+	for t := 0; t+2 < len(m.Indices); t += 3 {
+		var corners [3]Point3
+		for j := range corners {
+			corners[j] = tx.InverseTransformPoint(m.Positions[m.Indices[t+j]])
+		}
+		n := corners[1].Sub(corners[0]).Cross(corners[2].Sub(corners[0]))
+
+		axis := 0
+		if abs(n[1]) > abs(n[axis]) {
+			axis = 1
+		}
+		if abs(n[2]) > abs(n[axis]) {
+			axis = 2
+		}
+		positive := n[axis] >= 0
+
+		for j, p := range corners {
+			var uv Vec2
+			switch axis {
+			case 0:
+				uv = Vec2{p[2], p[1]}
+				if positive {
+					uv[0] = -p[2]
+				}
+			case 1:
+				uv = Vec2{p[0], p[2]}
+				if positive {
+					uv[1] = -p[2]
+				}
+			default:
+				uv = Vec2{-p[0], p[1]}
+				if positive {
+					uv[0] = p[0]
+				}
+			}
+			uvs[t+j] = uv
+		}
+	}
+	return uvs
+}
+
+// SphericalUVs projects the mesh onto a sphere around the local origin of tx. U is the
+// longitude about the local Y axis, from 0 to 1 starting and ending on the local negative Z
+// axis, and V is the latitude from 0 at the bottom to 1 at the top. Triangles that cross the
+// seam have U continue past 1 so that they do not stretch across the whole texture.
+func SphericalUVs(m *TriMesh, tx *Transform) []Vec2 {
+	return wrappedUVs(m, tx, func(p Point3) float32 {
+		l := p.Len()
+		if l == 0 {
+			return 0.5
+		}
+		return float32(math.Asin(float64(Clamp(p[1]/l, -1, 1))))/pi + 0.5
+	})
+}
+
+// CylindricalUVs projects the mesh onto a cylinder around the local Y axis of tx. U is the
+// angle about the axis as for SphericalUVs and V is the local Y coordinate.
+func CylindricalUVs(m *TriMesh, tx *Transform) []Vec2 {
+	return wrappedUVs(m, tx, func(p Point3) float32 { return p[1] })
+}
+
+// wrappedUVs returns UVs whose U is the angle about the local Y axis of tx and whose V is given
+// by v, fixing up the seam and the poles where the angle is undefined.
+func wrappedUVs(m *TriMesh, tx *Transform, v func(p Point3) float32) []Vec2 {
+	uvs := make([]Vec2, len(m.Indices))
+	for t := 0; t+2 < len(m.Indices); t += 3 {
+		var onAxis [3]bool
+		umin, umax := float32(math.Inf(1)), float32(math.Inf(-1))
+		for j := 0; j < 3; j++ {
+			p := tx.InverseTransformPoint(m.Positions[m.Indices[t+j]])
+			uvs[t+j][1] = v(p)
+			if p[0] == 0 && p[2] == 0 {
+				onAxis[j] = true
+				continue
+			}
+			u := float32(math.Atan2(float64(p[0]), float64(p[2])))/(2*pi) + 0.5
+			uvs[t+j][0] = u
+			umin, umax = min(umin, u), max(umax, u)
+		}
+
+		// A triangle that spans more than half a turn crosses the seam
+		if umax-umin > 0.5 {
+			umin, umax = float32(math.Inf(1)), float32(math.Inf(-1))
+			for j := 0; j < 3; j++ {
+				if onAxis[j] {
+					continue
+				}
+				if uvs[t+j][0] < 0.5 {
+					uvs[t+j][0]++
+				}
+				umin, umax = min(umin, uvs[t+j][0]), max(umax, uvs[t+j][0])
+			}
+		}
+
+		// Corners on the axis take the middle of the angles of the others
+		if umin <= umax {
+			for j := 0; j < 3; j++ {
+				if onAxis[j] {
+					uvs[t+j][0] = (umin + umax) / 2
+				}
+			}
+		}
+	}
+	return uvs
+}
@@ -0,0 +1,109 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// uvArea returns twice the signed area of the triangle of texture coordinates starting at corner i.
+func uvArea(uvs []Vec2, i int) float32 {
+	a, b, c := uvs[i], uvs[i+1], uvs[i+2]
+	return (b[0]-a[0])*(c[1]-a[1]) - (c[0]-a[0])*(b[1]-a[1])
+}
+
+func TestPlanarUVs(t *testing.T) {
+	m := &TriMesh{
+		Positions: []Point3{{1, 1, 5}, {5, 1, 5}, {5, 3, 7}},
+		Indices:   []uint32{0, 1, 2, 2, 1, 0},
+	}
+	tx := NewTransform()
+	tx.SetPosition(Vec3{1, 1, 0})
+	tx.SetScaleUniform(2)
+
+	uvs := PlanarUVs(m, &tx)
+	want := []Vec2{{0, 0}, {2, 0}, {2, 1}, {2, 1}, {2, 0}, {0, 0}}
+	if len(uvs) != len(want) {
+		t.Fatalf("got %d uvs, wanted %d", len(uvs), len(want))
+	}
+	for i := range want {
+		if !uvs[i].ApproxEqualThreshold(want[i], 1e-6) {
+			t.Errorf("corner %d: got %v, wanted %v", i, uvs[i], want[i])
+		}
+	}
+}
+
+func TestBoxUVs(t *testing.T) {
+	m := boxMesh(AABB{Position: Point3{0, 0, 0}, Size: Vec3{1, 1, 1}})
+	tx := NewTransform()
+	uvs := BoxUVs(m, &tx)
+	if len(uvs) != len(m.Indices) {
+		t.Fatalf("got %d uvs, wanted %d", len(uvs), len(m.Indices))
+	}
+	for i := 0; i < len(uvs); i += 3 {
+		// Each face of the cube covers a 2 by 2 square without being mirrored
+		if a := uvArea(uvs, i) / 2; abs(a-2) > 1e-5 {
+			t.Errorf("triangle %d: got uv area %v, wanted 2", i/3, a)
+		}
+		for j := i; j < i+3; j++ {
+			if abs(uvs[j][0]) != 1 || abs(uvs[j][1]) != 1 {
+				t.Errorf("corner %d: got %v, wanted a corner of the square", j, uvs[j])
+			}
+		}
+	}
+
+	// The side faces have V pointing up
+	for i := 0; i < len(uvs); i++ {
+		p := m.Positions[m.Indices[i]]
+		n := m.Tri(i / 3).Normal()
+		if abs(n[1]) < 0.5 && uvs[i][1] != p[1] {
+			t.Errorf("corner %d at %v: got v %v, wanted %v", i, p, uvs[i][1], p[1])
+		}
+	}
+
+	// Turning the projection turns which face each triangle is mapped from
+	tx.SetOrientation(mgl32.QuatRotate(pi/2, Y3))
+	for i, uv := range BoxUVs(m, &tx) {
+		if abs(abs(uv[0])-1) > 1e-5 || abs(abs(uv[1])-1) > 1e-5 {
+			t.Fatalf("rotated corner %d: got %v, wanted a corner of the square", i, uv)
+		}
+	}
+}
+
+func TestSphericalUVs(t *testing.T) {
+	// An octahedron with corners on the axes, and with its poles on the Y axis
+	m := &TriMesh{
+		Positions: []Point3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}},
+	}
+	for _, x := range []uint32{0, 1} {
+		for _, y := range []uint32{2, 3} {
+			for _, z := range []uint32{4, 5} {
+				m.Indices = append(m.Indices, x, y, z)
+			}
+		}
+	}
+	tx := NewTransform()
+	uvs := SphericalUVs(m, &tx)
+
+	for i := 0; i < len(uvs); i += 3 {
+		var umin, umax float32 = 2, -1
+		for j := i; j < i+3; j++ {
+			p := m.Positions[m.Indices[j]]
+			if want := (p[1] + 1) / 2; abs(uvs[j][1]-want) > 1e-6 {
+				t.Errorf("corner %d at %v: got v %v, wanted %v", j, p, uvs[j][1], want)
+			}
+			umin, umax = min(umin, uvs[j][0]), max(umax, uvs[j][0])
+		}
+		// Each face covers a quarter turn, including those on the seam
+		if umax-umin > 0.25+1e-6 {
+			t.Errorf("triangle %d: got u from %v to %v, wanted a quarter turn", i/3, umin, umax)
+		}
+	}
+
+	cyl := CylindricalUVs(m, &tx)
+	for i, uv := range cyl {
+		if p := m.Positions[m.Indices[i]]; uv[1] != p[1] || uv[0] != uvs[i][0] {
+			t.Errorf("cylindrical corner %d at %v: got %v", i, p, uv)
+		}
+	}
+}
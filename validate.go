@@ -0,0 +1,254 @@
+package geom
+
+import (
+	"errors"
+	"math"
+)
+
+// Errors wrapped by a ValidationError to describe what is wrong with a value. Use errors.Is to
+// test for them.
+var (
+	ErrNotFinite     = errors.New("not finite")
+	ErrNotNormalized = errors.New("not normalized")
+	ErrNegative      = errors.New("negative")
+	ErrDegenerate    = errors.New("degenerate")
+)
+
+// normalizedTolerance is how far the squared length of a vector may be from one before it is
+// reported as not normalized.
+const normalizedTolerance = 1e-4
+
+// ValidationError is returned by the Validate methods. It names the type and field that failed
+// and wraps one of the Err errors.
+type ValidationError struct {
+	Type  string // the name of the type being validated, such as "Ray3"
+	Field string // the name of the field that is invalid, empty when the value as a whole is invalid
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return "geom: invalid " + e.Type + ": " + e.Err.Error()
+	}
+	return "geom: invalid " + e.Type + " " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func invalid(typ, field string, err error) error {
+	return &ValidationError{Type: typ, Field: field, Err: err}
+}
+
+func finite(v ...float32) bool {
+	for _, f := range v {
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalized(lenSqr float32) bool {
+	return abs(lenSqr-1) <= normalizedTolerance
+}
+
+// Validate reports whether the ray has finite components and a normalized direction.
+func (r Ray2) Validate() error {
+	if !finite(r.Origin[:]...) {
+		return invalid("Ray2", "Origin", ErrNotFinite)
+	}
+	if !finite(r.Direction[:]...) {
+		return invalid("Ray2", "Direction", ErrNotFinite)
+	}
+	if !normalized(r.Direction.LenSqr()) {
+		return invalid("Ray2", "Direction", ErrNotNormalized)
+	}
+	return nil
+}
+
+// Validate reports whether the ray has finite components and a normalized direction.
+func (r Ray3) Validate() error {
+	if !finite(r.Origin[:]...) {
+		return invalid("Ray3", "Origin", ErrNotFinite)
+	}
+	if !finite(r.Direction[:]...) {
+		return invalid("Ray3", "Direction", ErrNotFinite)
+	}
+	if !normalized(r.Direction.LenSqr()) {
+		return invalid("Ray3", "Direction", ErrNotNormalized)
+	}
+	return nil
+}
+
+// Validate reports whether the plane has finite components and a normalized normal.
+func (p Plane3) Validate() error {
+	if !finite(p.Normal[:]...) {
+		return invalid("Plane3", "Normal", ErrNotFinite)
+	}
+	if !finite(p.Distance) {
+		return invalid("Plane3", "Distance", ErrNotFinite)
+	}
+	if !normalized(p.Normal.LenSqr()) {
+		return invalid("Plane3", "Normal", ErrNotNormalized)
+	}
+	return nil
+}
+
+// Validate reports whether the rectangle has finite components and no negative size.
+func (r Rect) Validate() error {
+	if !finite(r.Position[:]...) {
+		return invalid("Rect", "Position", ErrNotFinite)
+	}
+	if !finite(r.Size[:]...) {
+		return invalid("Rect", "Size", ErrNotFinite)
+	}
+	if r.Size[0] < 0 || r.Size[1] < 0 {
+		return invalid("Rect", "Size", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the circle has finite components and a radius that is not negative.
+func (c Circle) Validate() error {
+	if !finite(c.Centre[:]...) {
+		return invalid("Circle", "Centre", ErrNotFinite)
+	}
+	if !finite(c.Radius) {
+		return invalid("Circle", "Radius", ErrNotFinite)
+	}
+	if c.Radius < 0 {
+		return invalid("Circle", "Radius", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the box has finite components and no negative size.
+func (a *AABB) Validate() error {
+	if !finite(a.Position[:]...) {
+		return invalid("AABB", "Position", ErrNotFinite)
+	}
+	if !finite(a.Size[:]...) {
+		return invalid("AABB", "Size", ErrNotFinite)
+	}
+	if a.Size[0] < 0 || a.Size[1] < 0 || a.Size[2] < 0 {
+		return invalid("AABB", "Size", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the box has finite components, no negative size and an orientation
+// that is a unit quaternion.
+func (o *OBB) Validate() error {
+	if !finite(o.Position[:]...) {
+		return invalid("OBB", "Position", ErrNotFinite)
+	}
+	if !finite(o.Size[:]...) {
+		return invalid("OBB", "Size", ErrNotFinite)
+	}
+	if o.Size[0] < 0 || o.Size[1] < 0 || o.Size[2] < 0 {
+		return invalid("OBB", "Size", ErrNegative)
+	}
+	q := o.Orientation
+	if !finite(q.W, q.V[0], q.V[1], q.V[2]) {
+		return invalid("OBB", "Orientation", ErrNotFinite)
+	}
+	if !normalized(q.Dot(q)) {
+		return invalid("OBB", "Orientation", ErrNotNormalized)
+	}
+	return nil
+}
+
+// Validate reports whether the sphere has finite components and a radius that is not negative.
+func (s *Sphere) Validate() error {
+	if !finite(s.Position[:]...) {
+		return invalid("Sphere", "Position", ErrNotFinite)
+	}
+	if !finite(s.Radius) {
+		return invalid("Sphere", "Radius", ErrNotFinite)
+	}
+	if s.Radius < 0 {
+		return invalid("Sphere", "Radius", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the capsule has finite components and a radius that is not negative.
+func (c *Capsule) Validate() error {
+	if !finite(c.Start[:]...) {
+		return invalid("Capsule", "Start", ErrNotFinite)
+	}
+	if !finite(c.End[:]...) {
+		return invalid("Capsule", "End", ErrNotFinite)
+	}
+	if !finite(c.Radius) {
+		return invalid("Capsule", "Radius", ErrNotFinite)
+	}
+	if c.Radius < 0 {
+		return invalid("Capsule", "Radius", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the cone has finite components, a normalized direction and an angle
+// and range that are not negative.
+func (c *Cone) Validate() error {
+	if !finite(c.Apex[:]...) {
+		return invalid("Cone", "Apex", ErrNotFinite)
+	}
+	if !finite(c.Direction[:]...) {
+		return invalid("Cone", "Direction", ErrNotFinite)
+	}
+	if !normalized(c.Direction.LenSqr()) {
+		return invalid("Cone", "Direction", ErrNotNormalized)
+	}
+	if !finite(c.Angle) {
+		return invalid("Cone", "Angle", ErrNotFinite)
+	}
+	if c.Angle < 0 {
+		return invalid("Cone", "Angle", ErrNegative)
+	}
+	if !finite(c.Range) {
+		return invalid("Cone", "Range", ErrNotFinite)
+	}
+	if c.Range < 0 {
+		return invalid("Cone", "Range", ErrNegative)
+	}
+	return nil
+}
+
+// Validate reports whether the triangle has finite corners and is not degenerate.
+func (t Tri2) Validate() error {
+	for _, c := range [...]struct {
+		name string
+		p    Point2
+	}{{"A", t.A}, {"B", t.B}, {"C", t.C}} {
+		if !finite(c.p[:]...) {
+			return invalid("Tri2", c.name, ErrNotFinite)
+		}
+	}
+	ab := t.B.Sub(t.A)
+	ac := t.C.Sub(t.A)
+	longest := max(max(ab.LenSqr(), ac.LenSqr()), t.C.Sub(t.B).LenSqr())
+	if longest == 0 || abs(ab[0]*ac[1]-ab[1]*ac[0]) <= degenerateTolerance*longest {
+		return invalid("Tri2", "", ErrDegenerate)
+	}
+	return nil
+}
+
+// Validate reports whether the triangle has finite corners and is not degenerate.
+func (t Tri3) Validate() error {
+	for _, c := range [...]struct {
+		name string
+		p    Point3
+	}{{"A", t.A}, {"B", t.B}, {"C", t.C}} {
+		if !finite(c.p[:]...) {
+			return invalid("Tri3", c.name, ErrNotFinite)
+		}
+	}
+	if t.IsDegenerate() {
+		return invalid("Tri3", "", ErrDegenerate)
+	}
+	return nil
+}
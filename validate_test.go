@@ -0,0 +1,86 @@
+package geom
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestValidate(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+
+	testCases := []struct {
+		name  string
+		v     interface{ Validate() error }
+		err   error
+		field string
+	}{
+		{name: "ray2", v: Ray2{Direction: Vec2{0, 1}}},
+		{name: "ray2 nan origin", v: Ray2{Origin: Point2{nan, 0}, Direction: Vec2{0, 1}}, err: ErrNotFinite, field: "Origin"},
+		{name: "ray2 unnormalized", v: Ray2{Direction: Vec2{0, 2}}, err: ErrNotNormalized, field: "Direction"},
+		{name: "ray3", v: Ray3{Direction: Vec3{0, 0, 1}}},
+		{name: "ray3 inf direction", v: Ray3{Direction: Vec3{inf, 0, 0}}, err: ErrNotFinite, field: "Direction"},
+		{name: "ray3 zero direction", v: Ray3{}, err: ErrNotNormalized, field: "Direction"},
+		{name: "plane", v: Plane3{Normal: Vec3{0, 1, 0}, Distance: 3}},
+		{name: "plane unnormalized", v: Plane3{Normal: Vec3{0, 3, 0}}, err: ErrNotNormalized, field: "Normal"},
+		{name: "plane nan distance", v: Plane3{Normal: Vec3{0, 1, 0}, Distance: nan}, err: ErrNotFinite, field: "Distance"},
+		{name: "rect", v: Rect{Size: Vec2{1, 1}}},
+		{name: "rect negative", v: Rect{Size: Vec2{1, -1}}, err: ErrNegative, field: "Size"},
+		{name: "circle negative", v: Circle{Radius: -1}, err: ErrNegative, field: "Radius"},
+		{name: "aabb", v: &AABB{Size: Vec3{1, 2, 3}}},
+		{name: "aabb empty", v: &AABB{}},
+		{name: "aabb negative", v: &AABB{Size: Vec3{1, -2, 3}}, err: ErrNegative, field: "Size"},
+		{name: "aabb inf position", v: &AABB{Position: Point3{0, 0, -inf}}, err: ErrNotFinite, field: "Position"},
+		{name: "obb", v: &OBB{Size: Vec3{1, 1, 1}, Orientation: mgl32.QuatRotate(1, Vec3{0, 1, 0})}},
+		{name: "obb zero orientation", v: &OBB{Size: Vec3{1, 1, 1}}, err: ErrNotNormalized, field: "Orientation"},
+		{name: "sphere", v: &Sphere{Radius: 1}},
+		{name: "sphere nan radius", v: &Sphere{Radius: nan}, err: ErrNotFinite, field: "Radius"},
+		{name: "sphere negative", v: &Sphere{Radius: -1}, err: ErrNegative, field: "Radius"},
+		{name: "capsule negative", v: &Capsule{End: Point3{1, 0, 0}, Radius: -1}, err: ErrNegative, field: "Radius"},
+		{name: "cone", v: &Cone{Direction: Vec3{1, 0, 0}, Angle: 0.5, Range: 2}},
+		{name: "cone unnormalized", v: &Cone{Direction: Vec3{1, 1, 0}, Angle: 0.5, Range: 2}, err: ErrNotNormalized, field: "Direction"},
+		{name: "cone negative range", v: &Cone{Direction: Vec3{1, 0, 0}, Angle: 0.5, Range: -2}, err: ErrNegative, field: "Range"},
+		{name: "tri2", v: Tri2{Point2{0, 0}, Point2{1, 0}, Point2{0, 1}}},
+		{name: "tri2 collinear", v: Tri2{Point2{0, 0}, Point2{1, 1}, Point2{2, 2}}, err: ErrDegenerate},
+		{name: "tri2 point", v: Tri2{}, err: ErrDegenerate},
+		{name: "tri3", v: Tri3{Point3{0, 0, 0}, Point3{1, 0, 0}, Point3{0, 0, 1}}},
+		{name: "tri3 collinear", v: Tri3{Point3{0, 0, 0}, Point3{1, 1, 1}, Point3{3, 3, 3}}, err: ErrDegenerate},
+		{name: "tri3 nan", v: Tri3{Point3{0, 0, 0}, Point3{1, 0, 0}, Point3{0, nan, 1}}, err: ErrNotFinite, field: "C"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.v.Validate()
+			if tc.err == nil {
+				if err != nil {
+					t.Errorf("got error %v, wanted nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, wanted %v", err, tc.err)
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("got error of type %T, wanted *ValidationError", err)
+			}
+			if verr.Field != tc.field {
+				t.Errorf("got field %q, wanted %q", verr.Field, tc.field)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := (&Sphere{Radius: -1}).Validate()
+	if got, want := err.Error(), "geom: invalid Sphere Radius: negative"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	err = Tri3{}.Validate()
+	if got, want := err.Error(), "geom: invalid Tri3: degenerate"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
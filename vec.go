@@ -0,0 +1,89 @@
+package geom
+
+import (
+	"math"
+)
+
+// RotateVec2 rotates v counter clockwise by angle radians.
+func RotateVec2(v Vec2, angle float32) Vec2 {
+	s, c := sincos(angle)
+	return Vec2{
+		v[0]*c - v[1]*s,
+		v[0]*s + v[1]*c,
+	}
+}
+
+// Perp returns v rotated counter clockwise by 90 degrees.
+func Perp(v Vec2) Vec2 {
+	return Vec2{-v[1], v[0]}
+}
+
+// Cross2 returns the z component of the cross product of a and b, treating them as 3 dimensional
+// vectors in the xy plane. It is positive when b is counter clockwise from a and its magnitude is
+// the area of the parallelogram formed by a and b.
+func Cross2(a, b Vec2) float32 {
+	return a[0]*b[1] - a[1]*b[0]
+}
+
+// RoundingMode specifies how a value is rounded to a whole number.
+type RoundingMode int
+
+const (
+	RoundNearest RoundingMode = iota // round to the nearest whole number, with halves rounded away from zero
+	RoundFloor                       // round toward negative infinity
+	RoundCeil                        // round toward positive infinity
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundNearest:
+		return "nearest"
+	case RoundFloor:
+		return "floor"
+	case RoundCeil:
+		return "ceil"
+	default:
+		return "unknown"
+	}
+}
+
+// round rounds v to a whole number using the rounding mode.
+func (m RoundingMode) round(v float32) float32 {
+	switch m {
+	case RoundFloor:
+		return float32(math.Floor(float64(v)))
+	case RoundCeil:
+		return float32(math.Ceil(float64(v)))
+	default:
+		return float32(math.Round(float64(v)))
+	}
+}
+
+// Snap returns the multiple of step that is nearest to v, with halves rounded away from zero.
+// v is returned unchanged if step is not positive.
+func Snap(v, step float32) float32 {
+	if step <= 0 {
+		return v
+	}
+	return RoundNearest.round(v/step) * step
+}
+
+// SnapVec2 snaps each component of v to the nearest multiple of step.
+func SnapVec2(v Vec2, step float32) Vec2 {
+	return Vec2{Snap(v[0], step), Snap(v[1], step)}
+}
+
+// SnapVec3 snaps each component of v to the nearest multiple of step.
+func SnapVec3(v Vec3, step float32) Vec3 {
+	return Vec3{Snap(v[0], step), Snap(v[1], step), Snap(v[2], step)}
+}
+
+// Vec2iFromVec2 converts v to an integer vector, rounding each component using mode.
+func Vec2iFromVec2(v Vec2, mode RoundingMode) Vec2i {
+	return Vec2i{int32(mode.round(v[0])), int32(mode.round(v[1]))}
+}
+
+// Vec3iFromVec3 converts v to an integer vector, rounding each component using mode.
+func Vec3iFromVec3(v Vec3, mode RoundingMode) Vec3i {
+	return Vec3i{int32(mode.round(v[0])), int32(mode.round(v[1])), int32(mode.round(v[2]))}
+}
@@ -0,0 +1,112 @@
+package geom
+
+import (
+	"testing"
+)
+
+func TestSnap(t *testing.T) {
+	testCases := []struct {
+		v, step float32
+		want    float32
+	}{
+		{v: 1.2, step: 0.5, want: 1},
+		{v: 1.25, step: 0.5, want: 1.5},
+		{v: -1.25, step: 0.5, want: -1.5},
+		{v: 7, step: 5, want: 5},
+		{v: 8, step: 5, want: 10},
+		{v: 3.3, step: 0, want: 3.3},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			if got := Snap(tc.v, tc.step); got != tc.want {
+				t.Errorf("Snap(%v, %v): got %v, wanted %v", tc.v, tc.step, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVec3iFromVec3(t *testing.T) {
+	v := Vec3{1.5, -1.5, 2.2}
+
+	testCases := []struct {
+		mode RoundingMode
+		want Vec3i
+	}{
+		{mode: RoundNearest, want: Vec3i{2, -2, 2}},
+		{mode: RoundFloor, want: Vec3i{1, -2, 2}},
+		{mode: RoundCeil, want: Vec3i{2, -1, 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode.String(), func(t *testing.T) {
+			if got := Vec3iFromVec3(v, tc.mode); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			want2 := Vec2i{tc.want[0], tc.want[1]}
+			if got := Vec2iFromVec2(v.Vec2(), tc.mode); got != want2 {
+				t.Errorf("got %v, wanted %v", got, want2)
+			}
+		})
+	}
+}
+
+func TestVec3iArithmetic(t *testing.T) {
+	a := Vec3i{1, -2, 3}
+	b := Vec3i{4, 5, -6}
+
+	if got := a.Dot(b); got != 4-10-18 {
+		t.Errorf("Dot: got %v, wanted %v", got, 4-10-18)
+	}
+	if got := (Vec3i{2, 3, 6}).Len(); got != 7 {
+		t.Errorf("Len: got %v, wanted %v", got, 7)
+	}
+	if got, want := b.Div(2), (Vec3i{2, 2, -3}); got != want {
+		t.Errorf("Div: got %v, wanted %v", got, want)
+	}
+	if got, want := a.Neg(), (Vec3i{-1, 2, -3}); got != want {
+		t.Errorf("Neg: got %v, wanted %v", got, want)
+	}
+	if got, want := a.Abs(), (Vec3i{1, 2, 3}); got != want {
+		t.Errorf("Abs: got %v, wanted %v", got, want)
+	}
+	if got, want := a.Min(b), (Vec3i{1, -2, -6}); got != want {
+		t.Errorf("Min: got %v, wanted %v", got, want)
+	}
+	if got, want := a.Max(b), (Vec3i{4, 5, 3}); got != want {
+		t.Errorf("Max: got %v, wanted %v", got, want)
+	}
+	if got := a.ManhattanDistance(b); got != 3+7+9 {
+		t.Errorf("ManhattanDistance: got %v, wanted %v", got, 3+7+9)
+	}
+	if got := a.ChebyshevDistance(b); got != 9 {
+		t.Errorf("ChebyshevDistance: got %v, wanted %v", got, 9)
+	}
+	if got, want := a.Vec3(), (Vec3{1, -2, 3}); got != want {
+		t.Errorf("Vec3: got %v, wanted %v", got, want)
+	}
+	if got := Vec3iFromVec3(a.Vec3(), RoundNearest); got != a {
+		t.Errorf("round trip: got %v, wanted %v", got, a)
+	}
+}
+
+func TestVec2iArithmetic(t *testing.T) {
+	a := Vec2i{1, -2}
+	b := Vec2i{4, 5}
+
+	if got := a.Dot(b); got != -6 {
+		t.Errorf("Dot: got %v, wanted %v", got, -6)
+	}
+	if got := (Vec2i{3, 4}).Len(); got != 5 {
+		t.Errorf("Len: got %v, wanted %v", got, 5)
+	}
+	if got := a.ManhattanDistance(b); got != 10 {
+		t.Errorf("ManhattanDistance: got %v, wanted %v", got, 10)
+	}
+	if got := a.ChebyshevDistance(b); got != 7 {
+		t.Errorf("ChebyshevDistance: got %v, wanted %v", got, 7)
+	}
+	if got, want := a.Vec2(), (Vec2{1, -2}); got != want {
+		t.Errorf("Vec2: got %v, wanted %v", got, want)
+	}
+}
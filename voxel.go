@@ -0,0 +1,147 @@
+package geom
+
+import "sort"
+
+// VoxelizeMode selects which cells Voxelize marks.
+type VoxelizeMode int
+
+const (
+	// VoxelizeSurface marks the cells that touch a triangle of the mesh.
+	VoxelizeSurface VoxelizeMode = iota
+
+	// VoxelizeSolid marks the cells that touch a triangle or whose centre lies inside the mesh.
+	// The mesh must be closed for the inside to be well defined.
+	VoxelizeSolid
+)
+
+// Voxelize returns a grid with cells of the given size, aligned to multiples of cellSize,
+// that covers the mesh. Cells are set to true when they are occupied by the mesh according to
+// mode.
+func Voxelize(m *TriMesh, cellSize float32, mode VoxelizeMode) *Grid3[bool] {
+	b := m.Bounds()
+	g := newGrid3Covering[bool](b.Min(), b.Max(), cellSize)
+	if m.Len() == 0 {
+		return g
+	}
+
+	for i := 0; i < m.Len(); i++ {
+		t := m.Tri(i)
+		tb := AABBFromPoints([]Point3{t.A, t.B, t.C})
+		x0, y0, z0 := g.clampedCell(tb.Min())
+		x1, y1, z1 := g.clampedCell(tb.Max())
+		for z := z0; z <= z1; z++ {
+			for y := y0; y <= y1; y++ {
+				for x := x0; x <= x1; x++ {
+					idx := g.Index(x, y, z)
+					if g.Cells[idx] {
+						continue
+					}
+					cb := g.CellBounds(x, y, z)
+					g.Cells[idx] = t.IntersectsAABB(&cb)
+				}
+			}
+		}
+	}
+
+	if mode == VoxelizeSolid {
+		voxelizeInterior(m, g)
+	}
+	return g
+}
+
+// clampedCell returns the cell containing p, clamped to the grid.
+func (g *Grid3[T]) clampedCell(p Point3) (int, int, int) {
+	x, y, z := g.Cell(p)
+	clamp := func(v, n int) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= n {
+			return n - 1
+		}
+		return v
+	}
+	return clamp(x, g.Dims[0]), clamp(y, g.Dims[1]), clamp(z, g.Dims[2])
+}
+
+// voxelizeInterior marks the cells whose centres lie inside the mesh. A ray is cast along z
+// through the centre of each column of cells and the cells between each pair of crossings of
+// the surface are filled.
+func voxelizeInterior(m *TriMesh, g *Grid3[bool]) {
+	nx, ny := g.Dims[0], g.Dims[1]
+	crossings := make([][]float64, nx*ny)
+
+	for i := 0; i < m.Len(); i++ {
+		t := m.Tri(i)
+		a := [2]float64{float64(t.A[0]), float64(t.A[1])}
+		b := [2]float64{float64(t.B[0]), float64(t.B[1])}
+		c := [2]float64{float64(t.C[0]), float64(t.C[1])}
+		za, zb, zc := float64(t.A[2]), float64(t.B[2]), float64(t.C[2])
+
+		area := edgeFunction(a, b, c)
+		if area == 0 {
+			// Edge on to the rays
+			continue
+		}
+		if area < 0 {
+			// Use a consistent winding so that the fill rule applies the same way to every edge
+			b, c = c, b
+			zb, zc = zc, zb
+			area = -area
+		}
+
+		tb := AABBFromPoints([]Point3{t.A, t.B, t.C})
+		x0, y0, _ := g.clampedCell(tb.Min())
+		x1, y1, _ := g.clampedCell(tb.Max())
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				centre := g.CellCentre(x, y, 0)
+				p := [2]float64{float64(centre[0]), float64(centre[1])}
+				w0 := edgeFunction(b, c, p)
+				w1 := edgeFunction(c, a, p)
+				w2 := edgeFunction(a, b, p)
+				if !fillRuleInside(w0, b, c) || !fillRuleInside(w1, c, a) || !fillRuleInside(w2, a, b) {
+					continue
+				}
+				z := (w0*za + w1*zb + w2*zc) / area
+				crossings[x+nx*y] = append(crossings[x+nx*y], z)
+			}
+		}
+	}
+
+	for y := 0; y < ny; y++ {
+		for x := 0; x < nx; x++ {
+			zs := crossings[x+nx*y]
+			sort.Float64s(zs)
+			for i := 0; i+1 < len(zs); i += 2 {
+				// Fill the cells whose centres lie between the two crossings
+				_, _, z0 := g.clampedCell(Point3{0, 0, float32(zs[i])})
+				_, _, z1 := g.clampedCell(Point3{0, 0, float32(zs[i+1])})
+				for z := z0; z <= z1; z++ {
+					cz := float64(g.CellCentre(x, y, z)[2])
+					if cz >= zs[i] && cz <= zs[i+1] {
+						g.Set(x, y, z, true)
+					}
+				}
+			}
+		}
+	}
+}
+
+// edgeFunction returns twice the signed area of the triangle a, b, p, which is positive when p
+// lies to the left of the edge from a to b.
+func edgeFunction(a, b, p [2]float64) float64 {
+	return (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+}
+
+// fillRuleInside applies a fill rule to a point whose edge function for the edge from a to b of
+// a counter clockwise triangle is w. Points exactly on an edge are inside only for left and
+// bottom edges. Triangles that share an edge run along it in opposite directions, so a point on
+// the edge is inside exactly one of them.
+func fillRuleInside(w float64, a, b [2]float64) bool {
+	if w != 0 {
+		return w > 0
+	}
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	return dy < 0 || (dy == 0 && dx > 0)
+}
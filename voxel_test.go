@@ -0,0 +1,115 @@
+package geom
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func countCells(g *Grid3[bool]) int {
+	n := 0
+	for _, c := range g.Cells {
+		if c {
+			n++
+		}
+	}
+	return n
+}
+
+func TestVoxelizeBox(t *testing.T) {
+	testCases := []struct {
+		name    string
+		box     AABB
+		dims    [3]int
+		surface int
+		solid   int
+	}{
+		{
+			name:    "aligned to cells",
+			box:     AABBFromCorners(Point3{0, 0, 0}, Point3{4, 4, 4}),
+			dims:    [3]int{4, 4, 4},
+			surface: 4*4*4 - 2*2*2,
+			solid:   4 * 4 * 4,
+		},
+		{
+			name:    "inside cells",
+			box:     AABBFromCorners(Point3{0.25, 0.25, 0.25}, Point3{5.75, 5.75, 3.75}),
+			dims:    [3]int{6, 6, 4},
+			surface: 6*6*4 - 4*4*2,
+			solid:   6 * 6 * 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := boxMesh(tc.box)
+			g := Voxelize(m, 1, VoxelizeSurface)
+			if g.Dims != tc.dims || g.Origin != (Point3{}) {
+				t.Fatalf("got dims %v origin %v, wanted dims %v at the origin", g.Dims, g.Origin, tc.dims)
+			}
+			if n := countCells(g); n != tc.surface {
+				t.Errorf("surface: got %d cells, wanted %d", n, tc.surface)
+			}
+			if n := countCells(Voxelize(m, 1, VoxelizeSolid)); n != tc.solid {
+				t.Errorf("solid: got %d cells, wanted %d", n, tc.solid)
+			}
+		})
+	}
+}
+
+func TestVoxelizeRotated(t *testing.T) {
+	// A box turned so that its faces cut across the cells
+	q := mgl32.QuatRotate(0.6, Vec3{1, 2, 0.5}.Normalize())
+	obb := NewOBB(Point3{0.3, -0.2, 0.1}, Vec3{3, 2, 1.5}, q)
+	m := boxMesh(AABB{Size: obb.Size})
+	m.TransformMat4(mgl32.Translate3D(obb.Position[0], obb.Position[1], obb.Position[2]).Mul4(q.Mat4()))
+
+	const cell = 0.25
+	surface := Voxelize(m, cell, VoxelizeSurface)
+	solid := Voxelize(m, cell, VoxelizeSolid)
+
+	for z := 0; z < solid.Dims[2]; z++ {
+		for y := 0; y < solid.Dims[1]; y++ {
+			for x := 0; x < solid.Dims[0]; x++ {
+				inside := obb.ContainsPoint3(solid.CellCentre(x, y, z))
+				cb := solid.CellBounds(x, y, z)
+				touches := IntersectsBox3(&obb, &cb)
+
+				if got := surface.At(x, y, z); got && !touches {
+					t.Fatalf("surface cell %d,%d,%d does not touch the box", x, y, z)
+				}
+				if got := solid.At(x, y, z); got != (inside || surface.At(x, y, z)) {
+					t.Fatalf("solid cell %d,%d,%d: got %v, centre inside %v, on surface %v", x, y, z, got, inside, surface.At(x, y, z))
+				}
+			}
+		}
+	}
+
+	vol := obb.Volume()
+	if n := float32(countCells(solid)) * cell * cell * cell; n < vol {
+		t.Errorf("solid cells cover %v, less than the volume %v", n, vol)
+	}
+}
+
+func TestGrid3(t *testing.T) {
+	g := NewGrid3[int](Point3{-1, 0, 0}, 0.5, 4, 3, 2)
+	if g.Len() != 24 {
+		t.Fatalf("got %d cells, wanted 24", g.Len())
+	}
+	g.Set(3, 2, 1, 7)
+	if g.Cells[len(g.Cells)-1] != 7 || g.At(3, 2, 1) != 7 {
+		t.Errorf("Set did not store the last cell")
+	}
+	if x, y, z := g.Cell(Point3{0.9, 1.2, 0.1}); x != 3 || y != 2 || z != 0 {
+		t.Errorf("Cell: got %d,%d,%d, wanted 3,2,0", x, y, z)
+	}
+	if x, y, z := g.Cell(Point3{-1.1, 0, 0}); g.InBounds(x, y, z) {
+		t.Errorf("Cell outside grid reported in bounds: %d,%d,%d", x, y, z)
+	}
+	if c := g.CellCentre(0, 0, 0); c != (Point3{-0.75, 0.25, 0.25}) {
+		t.Errorf("CellCentre: got %v", c)
+	}
+	if b := g.Bounds(); b.Min() != (Point3{-1, 0, 0}) || b.Max() != (Point3{1, 1.5, 1}) {
+		t.Errorf("Bounds: got %v", b)
+	}
+}